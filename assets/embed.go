@@ -4,3 +4,15 @@ import "embed"
 
 //go:embed neetcode250.csv
 var Neetcode250CSV embed.FS
+
+// PayloadSchemas holds the JSON Schema of each ingest provider's payload, as
+// schemas/<provider>.json.
+//
+//go:embed schemas/*.json
+var PayloadSchemas embed.FS
+
+// MessageTemplates holds the text/template sources of system messages, one
+// templates/messages/<locale>.json per locale, each mapping an event to its template.
+//
+//go:embed templates/messages/*.json
+var MessageTemplates embed.FS
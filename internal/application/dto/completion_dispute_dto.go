@@ -0,0 +1,51 @@
+package dto
+
+import "time"
+
+// Input DTOs
+
+// FlagCompletionDTO opens a dispute over a completion event on behalf of one of its
+// grind's partners.
+type FlagCompletionDTO struct {
+	UserID            string `json:"-"`
+	CompletionEventID string `json:"-"`
+	Reason            string `json:"reason"`
+}
+
+// VoteOnDisputeDTO is a reviewer's vote: Uphold keeps the completion, otherwise the vote
+// is to revert it.
+type VoteOnDisputeDTO struct {
+	UserID    string `json:"-"`
+	DisputeID string `json:"-"`
+	Uphold    *bool  `json:"uphold" binding:"required"`
+}
+
+type GetGrindDisputesDTO struct {
+	UserID  string
+	GrindID string
+}
+
+// Output DTOs
+
+// CompletionDisputeDTO is a partner's challenge of a completion event and the votes on it.
+type CompletionDisputeDTO struct {
+	ID                string    `json:"id"`
+	CompletionEventID string    `json:"completionEventID"`
+	HabitTaskID       string    `json:"habitTaskID"`
+	GrindID           string    `json:"grindID"`
+	SubjectID         string    `json:"subjectID"`
+	FlaggedBy         string    `json:"flaggedBy"`
+	Reason            string    `json:"reason,omitempty"`
+	Quorum            int       `json:"quorum"`
+	RevertVotes       []string  `json:"revertVotes"`
+	UpholdVotes       []string  `json:"upholdVotes"`
+	Status            string    `json:"status"`
+	ExpiresAt         time.Time `json:"expiresAt"`
+	CreatedAt         time.Time `json:"createdAt"`
+	UpdatedAt         time.Time `json:"updatedAt"`
+}
+
+// DisputeSettlementResultDTO summarises one run of the expired dispute job.
+type DisputeSettlementResultDTO struct {
+	SettledDisputeIDs []string `json:"settledDisputeIDs"`
+}
@@ -5,6 +5,7 @@ import "time"
 // LeetCodeIngestPayload is the request body for leetcode ingestion.
 type LeetCodeIngestPayload struct {
 	GrindID           string    `json:"grindID"`
+	SubmissionID      string    `json:"submissionId"` // optional; deduplicates retries, otherwise the payload hash does
 	ProblemTitle      string    `json:"problemTitle"`
 	ProblemURL        string    `json:"problemURL"`
 	ProblemDifficulty string    `json:"problemDifficulty"`
@@ -17,18 +18,29 @@ type LeetCodeIngestPayload struct {
 // DuolingoIngestPayload is the request body for duolingo ingestion.
 type DuolingoIngestPayload struct {
 	GrindID          string    `json:"grindID"`
+	EventID          string    `json:"eventId"` // optional; deduplicates retries, otherwise the payload hash does
 	StreakCount      int       `json:"streakCount"`
 	LessonsCompleted int       `json:"lessonsCompleted"`
 	XPEarned         int       `json:"xpEarned"`
 	OccurredAt       time.Time `json:"occurredAt"`
 }
 
+// FieldErrorDTO is one way an ingest payload violates its provider's schema. Field is the
+// dotted path of the offending field, e.g. "problemSlug", or "" for the payload as a whole.
+type FieldErrorDTO struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
 // CompletionEventDTO is the response DTO for a CompletionEvent entity.
 type CompletionEventDTO struct {
-	ID          string      `json:"id"`
-	HabitTaskID string      `json:"habitTaskID"`
-	UserID      string      `json:"userID"`
-	Provider    string      `json:"provider"`
-	OccurredAt  time.Time   `json:"occurredAt"`
-	Metadata    interface{} `json:"metadata,omitempty"`
+	ID            string       `json:"id"`
+	HabitTaskID   string       `json:"habitTaskID"`
+	UserID        string       `json:"userID"`
+	Provider      string       `json:"provider"`
+	OccurredAt    time.Time    `json:"occurredAt"`
+	Metadata      interface{}  `json:"metadata,omitempty"`
+	DedupKey      string       `json:"dedupKey,omitempty"`
+	LateBySeconds int64        `json:"lateBySeconds,omitempty"` // how long after the end of its day the event arrived
+	Evidence      *EvidenceDTO `json:"evidence,omitempty"`
 }
@@ -0,0 +1,40 @@
+package dto
+
+import "time"
+
+// Input DTOs
+
+type GetUserDeadLettersDTO struct {
+	UserID string
+}
+
+// RedriveDeadLetterDTO ingests a dead letter's payload again. HabitTaskID records it for
+// that task; otherwise GrindID, or the grind the payload named, is searched for the task of
+// the day the completion occurred on.
+type RedriveDeadLetterDTO struct {
+	UserID       string
+	DeadLetterID string
+	GrindID      string `json:"grindID"`
+	HabitTaskID  string `json:"habitTaskID"`
+}
+
+type DiscardDeadLetterDTO struct {
+	UserID       string
+	DeadLetterID string
+}
+
+// Output DTOs
+
+// DeadLetterDTO describes a completion signal ingest could not record. Payload is the body
+// as received.
+type DeadLetterDTO struct {
+	ID            string    `json:"id"`
+	UserID        string    `json:"userID"`
+	Provider      string    `json:"provider"`
+	IntegrationID string    `json:"integrationID,omitempty"`
+	GrindID       string    `json:"grindID,omitempty"`
+	Payload       string    `json:"payload"`
+	Reason        string    `json:"reason"`
+	Error         string    `json:"error"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
@@ -0,0 +1,28 @@
+package dto
+
+// Input DTOs
+
+// EvidenceUploadDTO is a file sent with a completion as proof. ContentType is what the
+// client declared; the stored type is sniffed from Content.
+type EvidenceUploadDTO struct {
+	FileName    string
+	ContentType string
+	Content     []byte
+}
+
+type GetEvidenceDTO struct {
+	UserID            string
+	CompletionEventID string
+}
+
+// Output DTOs
+
+// EvidenceDTO describes the file attached to a completion event. URL downloads it with the
+// caller's bearer token; only participants of the event's grind may.
+type EvidenceDTO struct {
+	FileName    string `json:"fileName"`
+	ContentType string `json:"contentType"`
+	Size        int64  `json:"size"`
+	SHA256      string `json:"sha256"`
+	URL         string `json:"url"`
+}
@@ -6,10 +6,39 @@ import (
 
 // Input DTOs
 type CreateGrindDTO struct {
-	CreatorID string    `json:"creator_id" validate:"required"`
-	Duration  int       `json:"duration" validate:"min=1"`
-	Budget    int       `json:"budget" validate:"min=0"`
-	StartDate time.Time `json:"start_date"`
+	CreatorID     string            `json:"creator_id" validate:"required"`
+	Duration      int               `json:"duration" validate:"min=1"`
+	Budget        int               `json:"budget" validate:"min=0"`
+	StartDate     time.Time         `json:"start_date"`
+	PenaltyPolicy *PenaltyPolicyDTO `json:"penaltyPolicy,omitempty"` // nil picks the default flat policy
+	Schedule      *ScheduleDTO      `json:"schedule,omitempty"`      // nil means a task every day
+	Roadmap       *RoadmapDTO       `json:"roadmap,omitempty"`       // nil for a free-form grind
+	Draft         bool              `json:"draft,omitempty"`         // keep the grind private until it is published
+}
+
+// ScheduleDTO is used both to pick a grind's schedule and to display it.
+type ScheduleDTO struct {
+	Kind         string   `json:"kind"`                   // daily | weekdays | weekly_target | dates
+	TimesPerWeek int      `json:"timesPerWeek,omitempty"` // weekly_target only
+	Dates        []string `json:"dates,omitempty"`        // dates only, as YYYY-MM-DD
+}
+
+// RoadmapDTO is used both to pick the LeetCode study plan of a grind and to display it.
+type RoadmapDTO struct {
+	Name  string   `json:"name"`            // neetcode250
+	Order string   `json:"order,omitempty"` // tag (default) | custom
+	Tags  []string `json:"tags,omitempty"`  // tag order only: topics to include, in order
+	Slugs []string `json:"slugs,omitempty"` // custom order only: problems to solve, in order
+}
+
+// PenaltyPolicyDTO is used both to pick a policy on creation and to display it.
+type PenaltyPolicyDTO struct {
+	Kind         string `json:"kind"`
+	Amount       int    `json:"amount"`
+	Increment    int    `json:"increment"`
+	GraceDays    int    `json:"graceDays"`
+	FreezeTokens int    `json:"freezeTokens"`
+	CapAtBudget  bool   `json:"capAtBudget"`
 }
 
 type GetGrindDTO struct {
@@ -25,17 +54,41 @@ type GetOngoingGrindDTO struct {
 	UserID string
 }
 
+// GetAllUserGrindsDTO pages through a user's grinds, newest first. Cursor is the NextCursor
+// of the previous page; a Limit <= 0 returns every grind.
 type GetAllUserGrindsDTO struct {
 	UserID string
+	States []string // only grinds in one of these states; empty returns every grind
+	Cursor string
+	Limit  int
 }
 
+// TransitionGrindDTO asks to move a grind to another lifecycle state (publish or cancel).
+type TransitionGrindDTO struct {
+	GrindID string
+	UserID  string
+}
+
+// UpdateGrindDTO changes a grind before it starts; nil fields are left as they are.
+// Duration, StartDate and Schedule apply immediately, Budget and PenaltyPolicy (the stake) need
+// the other participants' consent.
 type UpdateGrindDTO struct {
 	// We usually get the ID from the URL path, not the body,
 	// but keeping it here for batch updates is fine.
-	GrindID  string `json:"-"`
-	UserID   string `json:"-"`
-	Duration int    `json:"duration,omitempty"`
-	Budget   int    `json:"budget,omitempty"`
+	GrindID       string            `json:"-"`
+	UserID        string            `json:"-"`
+	Duration      *int              `json:"duration,omitempty"`
+	StartDate     *time.Time        `json:"startDate,omitempty"`
+	Budget        *int              `json:"budget,omitempty"`
+	PenaltyPolicy *PenaltyPolicyDTO `json:"penaltyPolicy,omitempty"`
+	Schedule      *ScheduleDTO      `json:"schedule,omitempty"`
+}
+
+// RespondStakeChangeDTO approves or rejects a pending stake change.
+type RespondStakeChangeDTO struct {
+	GrindID       string
+	StakeChangeID string
+	UserID        string
 }
 
 type QuitGrindDTO struct {
@@ -43,17 +96,101 @@ type QuitGrindDTO struct {
 	GrindID string
 }
 
+// GetLeaderboardDTO asks for a grind's participants ranked by one statistic.
+type GetLeaderboardDTO struct {
+	GrindID string
+	UserID  string
+	SortBy  string // completionRate (default) | currentStreak | longestStreak | missedDays | penalty
+}
+
 // Output DTOs
+// GroupGrindPageDTO is a page of grinds; NextCursor is empty on the last page.
+type GroupGrindPageDTO struct {
+	Grinds     []*GroupGrindDTO
+	NextCursor string
+}
+
 type GroupGrindDTO struct {
-	ID           string                 `json:"id"`
-	Duration     int32                  `json:"duration"`
-	Participants []UserDTO              `json:"participants"`
-	Budget       int32                  `json:"budget"`
-	Progress     []HabitTaskProgressDTO `json:"progress"`
-	StartDate    time.Time              `json:"startDate"`
-	CreatedAt    time.Time              `json:"created_at"`
-	UpdatedAt    time.Time              `json:"updated_at,omitempty"`
-	TodayTask    *HabitTaskDTO          `json:"taskToday,omitempty"`
+	ID           string                  `json:"id"`
+	Duration     int32                   `json:"duration"`
+	Participants []UserDTO               `json:"participants"`
+	Budget       int32                   `json:"budget"`
+	Progress     []HabitTaskProgressDTO  `json:"progress"`
+	StartDate    time.Time               `json:"startDate"`
+	CreatedAt    time.Time               `json:"created_at"`
+	UpdatedAt    time.Time               `json:"updated_at,omitempty"`
+	TodayTask    *HabitTaskDTO           `json:"taskToday,omitempty"`
+	NextTaskDate *time.Time              `json:"nextTaskDate,omitempty"` // the viewer's next habit day from today on, when there is one
+	Schedule     ScheduleDTO             `json:"schedule"`
+	RequiredDays int                     `json:"requiredDays"` // habit days each participant must complete
+	Roadmap      *RoadmapDTO             `json:"roadmap,omitempty"`
+	Policy       PenaltyPolicyDTO        `json:"penaltyPolicy"`
+	Penalties    []ParticipantPenaltyDTO `json:"penalties"`
+	State        string                  `json:"state"`
+	PublishedAt  *time.Time              `json:"publishedAt,omitempty"`
+	ActivatedAt  *time.Time              `json:"activatedAt,omitempty"`
+	SettlingAt   *time.Time              `json:"settlingAt,omitempty"`
+	SettledAt    *time.Time              `json:"settledAt,omitempty"`
+	CancelledAt  *time.Time              `json:"cancelledAt,omitempty"`
+}
+
+// UpdateGrindResultDTO is the grind after an update, plus the stake change still waiting
+// for the other participants when the update proposed one.
+type UpdateGrindResultDTO struct {
+	Grind              *GroupGrindDTO  `json:"grind"`
+	PendingStakeChange *StakeChangeDTO `json:"pendingStakeChange,omitempty"`
+}
+
+// StakeChangeDTO is a proposed change to a grind's budget and penalty policy.
+type StakeChangeDTO struct {
+	ID         string           `json:"id"`
+	GrindID    string           `json:"grindID"`
+	ProposerID string           `json:"proposerID"`
+	Budget     int32            `json:"budget"`
+	Policy     PenaltyPolicyDTO `json:"penaltyPolicy"`
+	ApprovedBy []string         `json:"approvedBy"`
+	RejectedBy string           `json:"rejectedBy,omitempty"`
+	Status     string           `json:"status"`
+	CreatedAt  time.Time        `json:"createdAt"`
+}
+
+// GrindLeaderboardDTO ranks every participant of a grind. Participants who quit are
+// ranked after everyone still in the grind.
+type GrindLeaderboardDTO struct {
+	GrindID     string                `json:"grindID"`
+	SortBy      string                `json:"sortBy"`
+	GeneratedAt time.Time             `json:"generatedAt"` // when the statistics were computed; they may be cached briefly
+	Entries     []LeaderboardEntryDTO `json:"entries"`
+}
+
+// LeaderboardEntryDTO is one participant's rank and statistics; tied participants share a rank.
+type LeaderboardEntryDTO struct {
+	Rank           int     `json:"rank"`
+	UserID         string  `json:"userID"`
+	Username       string  `json:"username"`
+	CompletedDays  int     `json:"completedDays"`
+	CompletionRate float64 `json:"completionRate"` // completed / (completed + missed) habit days, 0 to 1
+	CurrentStreak  int     `json:"currentStreak"`
+	LongestStreak  int     `json:"longestStreak"`
+	MissedDays     int     `json:"missedDays"`
+	FrozenDays     int     `json:"frozenDays"`
+	TotalPenalty   int     `json:"totalPenalty"`
+	Quitted        bool    `json:"quitted"`
+}
+
+// GrindActivationResultDTO summarises one run of the grind activation job.
+type GrindActivationResultDTO struct {
+	ActivatedGrindIDs []string `json:"activatedGrindIDs"`
+}
+
+// ParticipantPenaltyDTO is one participant's penalty accrued so far in a grind.
+type ParticipantPenaltyDTO struct {
+	UserID           string `json:"userID"`
+	MissedDays       int    `json:"missedDays"`
+	FrozenDays       int    `json:"frozenDays"`
+	FreezeTokensLeft int    `json:"freezeTokensLeft"`
+	TotalPenalty     int    `json:"totalPenalty"`
+	Quitted          bool   `json:"quitted"`
 }
 
 // What is MessageGrindDTO?
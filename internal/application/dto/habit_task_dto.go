@@ -4,14 +4,17 @@ import "time"
 
 // HabitTaskDTO is the output DTO for a HabitTask entity.
 type HabitTaskDTO struct {
-	ID           string      `json:"id"`
-	TaskType     string      `json:"taskType"`
-	UserID       string      `json:"userID"`
-	GrindID      string      `json:"grindID"`
-	Date         time.Time   `json:"date"`
-	FinishedTime *time.Time  `json:"finishedTime,omitempty"`
-	Completed    bool        `json:"completed"`
-	Metadata     interface{} `json:"metadata,omitempty"`
+	ID             string      `json:"id"`
+	TaskType       string      `json:"taskType"`
+	UserID         string      `json:"userID"`
+	GrindID        string      `json:"grindID"`
+	Date           time.Time   `json:"date"`
+	FinishedTime   *time.Time  `json:"finishedTime,omitempty"`
+	Completed      bool        `json:"completed"`
+	Missed         bool        `json:"missed"`
+	Excused        bool        `json:"excused"`
+	RequiredEvents int         `json:"requiredEvents"`
+	Metadata       interface{} `json:"metadata,omitempty"`
 }
 
 // HabitTaskProgressDTO is a compact view of a HabitTask for progress tracking.
@@ -19,5 +22,18 @@ type HabitTaskProgressDTO struct {
 	ID           string     `json:"id"`
 	Date         time.Time  `json:"date"`
 	FinishedTime *time.Time `json:"finishedTime,omitempty"`
-	Status       string     `json:"status"` // "pending" | "completed" | "missed"
+	Status       string     `json:"status"` // "pending" | "completed" | "missed" | "excused"
+}
+
+// EvaluateDayDTO is the input DTO for running the missed-day evaluator over one calendar day.
+type EvaluateDayDTO struct {
+	Day time.Time `json:"day"`
+}
+
+// DayEvaluationResultDTO summarises one missed-day evaluation run.
+type DayEvaluationResultDTO struct {
+	Day               time.Time `json:"day"`
+	GrindsEvaluated   int       `json:"grindsEvaluated"`
+	TasksMarkedMissed int       `json:"tasksMarkedMissed"`
+	PenaltyAccrued    int       `json:"penaltyAccrued"`
 }
@@ -0,0 +1,45 @@
+package dto
+
+import "time"
+
+// Input DTOs
+
+// CreateIntegrationDTO configures a custom provider integration. With a GroupID it ingests
+// for every member of that partner group; only the group's owner can create one.
+type CreateIntegrationDTO struct {
+	UserID  string
+	Name    string
+	GroupID string
+	Mapping IntegrationFieldMappingDTO
+}
+
+// IntegrationFieldMappingDTO is used both to configure where payloads keep their fields and
+// to display it. Each value is a dot-separated path into the JSON body.
+type IntegrationFieldMappingDTO struct {
+	OccurredAt string `json:"occurredAt,omitempty"`
+	Evidence   string `json:"evidence,omitempty"`
+	EventID    string `json:"eventID,omitempty"`
+}
+
+type GetUserIntegrationsDTO struct {
+	UserID string
+}
+
+type DeleteIntegrationDTO struct {
+	UserID        string
+	IntegrationID string
+}
+
+// Output DTOs
+
+// IntegrationDTO describes an integration. Secret is only filled in the response that
+// creates the integration; it is never shown again.
+type IntegrationDTO struct {
+	ID        string                     `json:"id"`
+	Name      string                     `json:"name"`
+	OwnerID   string                     `json:"ownerID"`
+	GroupID   string                     `json:"groupID,omitempty"`
+	Mapping   IntegrationFieldMappingDTO `json:"mapping"`
+	Secret    string                     `json:"secret,omitempty"`
+	CreatedAt time.Time                  `json:"createdAt"`
+}
@@ -21,16 +21,46 @@ type CreateInvitationRejectedMessageDTO struct {
 	GrindID    string
 }
 
+type RevokeInvitationDTO struct {
+	MessageID string
+	SenderID  string
+}
+
 type GetMessageDTO struct {
 	MessageID string
 }
 
+// MessageFilterDTO narrows a listing of messages; empty fields match every message.
+type MessageFilterDTO struct {
+	Type    string
+	Read    *bool
+	GrindID string
+}
+
+// GetAllMessagesForReceiverDTO pages through a user's inbox, newest first. Cursor is the
+// NextCursor of the previous page; a Limit <= 0 returns every message.
 type GetAllMessagesForReceiverDTO struct {
 	ReceiverID string
-	Offset     int
+	Filter     MessageFilterDTO
+	Cursor     string
 	Limit      int
 }
 
+// GetAllMessagesFromSenderDTO is GetAllMessagesForReceiverDTO for the messages a user sent.
+type GetAllMessagesFromSenderDTO struct {
+	SenderID string
+	Filter   MessageFilterDTO
+	Cursor   string
+	Limit    int
+}
+
+// CountUnreadMessagesDTO counts the unread messages in a user's inbox that match Filter;
+// Filter.Read is ignored.
+type CountUnreadMessagesDTO struct {
+	ReceiverID string
+	Filter     MessageFilterDTO
+}
+
 type UpdateMessageReadStatusDTO struct {
 	MessageID string
 	Read      bool
@@ -41,7 +71,52 @@ type UpdateMessageInvitationAcceptedStatusDTO struct {
 	Accepted  bool
 }
 
+// SendGeneralMessageDTO is a plain message to the user named by ReceiverID or, when it is
+// empty, ReceiverEmail.
+type SendGeneralMessageDTO struct {
+	SenderID      string `json:"-"`
+	ReceiverID    string `json:"receiverID"`
+	ReceiverEmail string `json:"receiverEmail"`
+	Content       string `json:"content"`
+}
+
+// GetConversationsDTO pages through a user's conversations, the most recently active
+// first. Cursor is the NextCursor of the previous page; a Limit <= 0 returns every one.
+type GetConversationsDTO struct {
+	UserID string
+	Cursor string
+	Limit  int
+}
+
+// GetConversationMessagesDTO pages through the general messages between UserID and
+// CounterpartID like GetAllMessagesForReceiverDTO.
+type GetConversationMessagesDTO struct {
+	UserID        string
+	CounterpartID string
+	Cursor        string
+	Limit         int
+}
+
+type ReadConversationDTO struct {
+	UserID        string
+	CounterpartID string
+}
+
+// UpdateConversationSettingDTO changes the settings that are given and keeps the others.
+type UpdateConversationSettingDTO struct {
+	UserID        string `json:"-"`
+	CounterpartID string `json:"-"`
+	Blocked       *bool  `json:"blocked"`
+	Muted         *bool  `json:"muted"`
+}
+
 // Output DTOs
+// MessagePageDTO is a page of messages; NextCursor is empty on the last page.
+type MessagePageDTO struct {
+	Messages   []*MessageDTO
+	NextCursor string
+}
+
 type MessageDTO struct {
 	ID                 string           `json:"id"`
 	Sender             *UserDTO         `json:"sender"`
@@ -54,4 +129,29 @@ type MessageDTO struct {
 	Read               bool             `json:"read"`
 	CreatedAt          time.Time        `json:"createdAt"`
 	UpdatedAt          time.Time        `json:"updatedAt"`
+
+	InvitationExpiresAt *time.Time `json:"invitationExpiresAt,omitempty"` // invitations only
+	InvitationRevokedAt *time.Time `json:"invitationRevokedAt,omitempty"` // set once the sender revoked the invitation
+}
+
+// ConversationPageDTO is a page of conversations; NextCursor is empty on the last page.
+type ConversationPageDTO struct {
+	Conversations []*ConversationDTO
+	NextCursor    string
+}
+
+// ConversationDTO is the reader's thread of general messages with Counterpart.
+type ConversationDTO struct {
+	Counterpart *UserDTO    `json:"counterpart"`
+	LastMessage *MessageDTO `json:"lastMessage"`
+	UnreadCount int         `json:"unreadCount"`
+	Muted       bool        `json:"muted"`
+	Blocked     bool        `json:"blocked"`
+}
+
+type ConversationSettingDTO struct {
+	CounterpartID string    `json:"counterpartID"`
+	Blocked       bool      `json:"blocked"`
+	Muted         bool      `json:"muted"`
+	UpdatedAt     time.Time `json:"updatedAt"`
 }
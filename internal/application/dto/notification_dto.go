@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Input DTOs
+
+// SubscribeNotificationsDTO opens a user's notification stream. LastEventID is the ID of
+// the last notification the client received, if it is reconnecting.
+type SubscribeNotificationsDTO struct {
+	UserID      string
+	LastEventID string
+}
+
+// IssueStreamTicketDTO asks for a ticket that opens UserID's notification stream.
+type IssueStreamTicketDTO struct {
+	UserID string
+}
+
+// Output DTOs
+
+// StreamTicketDTO is a single-use ticket for the notification stream, valid until ExpiresAt.
+type StreamTicketDTO struct {
+	Ticket    string    `json:"ticket"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// NotificationDTO is one event of a notification stream. Data depends on Type: a
+// MessageDTO for "message", an InvitationStatusDTO for "invitation_status", a
+// CompletionNotificationDTO for "completion" and a PaymentSettlementDTO for "settlement".
+type NotificationDTO struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// InvitationStatusDTO tells both sides of an invitation to GrindID that UserID answered it.
+type InvitationStatusDTO struct {
+	GrindID string `json:"grindID"`
+	UserID  string `json:"userID"`
+	Status  string `json:"status"` // "accepted" | "rejected"
+}
+
+// CompletionNotificationDTO tells a grind partner about a completion event.
+type CompletionNotificationDTO struct {
+	GrindID         string              `json:"grindID"`
+	CompletionEvent *CompletionEventDTO `json:"completionEvent"`
+}
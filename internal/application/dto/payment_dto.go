@@ -289,6 +289,7 @@ type ChargeWithIdempotencyDTO struct {
 	AmountCents       int64                      `json:"amount_cents"`
 	Operation         string                     `json:"operation"`
 	UserID            string                     `json:"user_id"`
+	GrindID           string                     `json:"grind_id"` // Optional; links the settlement to a grind
 }
 
 // PayBackDTO represents a disbursement request (amount in cents).
@@ -297,6 +298,11 @@ type PayBackDTO struct {
 	AmountCents          int64  `json:"amount_cents" binding:"required"`
 }
 
+// PayBackStatusDTO asks the provider where an earlier disbursement stands.
+type PayBackStatusDTO struct {
+	ProviderReference string `json:"provider_reference" binding:"required"`
+}
+
 type SettlementIntentRequestDTO struct {
 	PaymentMethodInfo entities.PaymentMethodInfo `json:"payment_method_info"`
 	AmountCents       int64                      `json:"amount_cents"`
@@ -328,6 +334,17 @@ type SolanaResolvePledgeResultDTO struct {
 	SettlementProof   string `json:"settlement_proof"`
 }
 
+// SettleGrindDTO asks the settlement orchestrator to settle one ended grind.
+type SettleGrindDTO struct {
+	GrindID string `json:"grind_id" binding:"required"`
+}
+
+// GetGrindSettlementDTO reads the settlement report of a grind on behalf of a participant.
+type GetGrindSettlementDTO struct {
+	GrindID string `json:"grind_id" binding:"required"`
+	UserID  string `json:"user_id" binding:"required"`
+}
+
 /*
 Output DTOs
 */
@@ -397,6 +414,7 @@ type SettlementReferenceDTO struct {
 type PaymentSettlementDTO struct {
 	ID              uint                      `json:"id"`
 	UserID          string                    `json:"user_id"`
+	GrindID         string                    `json:"grind_id"`
 	Operation       string                    `json:"operation"`
 	IdempotencyKey  string                    `json:"idempotency_key"`
 	Provider        entities.PaymentProvider  `json:"provider"`
@@ -419,3 +437,39 @@ type PayBackResultDTO struct {
 	ProviderReference string `json:"provider_reference"`
 	Status            string `json:"status"`
 }
+
+// Grind settlement report statuses.
+const (
+	GrindSettlementCollecting = "collecting" // losers are still being charged
+	GrindSettlementPayingOut  = "paying_out" // the pot is collected and is being paid out
+	GrindSettlementSettled    = "settled"    // every leg has settled
+	GrindSettlementIncomplete = "incomplete" // finished, but some legs failed after every retry or need manual review
+)
+
+// SettlementLegDTO is one participant's charge or payout in a grind settlement.
+// Status is empty when the leg has not been attempted yet.
+type SettlementLegDTO struct {
+	UserID            string                    `json:"user_id"`
+	AmountCents       int64                     `json:"amount_cents"`
+	Status            entities.SettlementStatus `json:"status"`
+	Attempts          int                       `json:"attempts"`
+	ProviderReference string                    `json:"provider_reference"`
+	LastError         string                    `json:"last_error"`
+}
+
+// GrindSettlementReportDTO summarizes where the settlement of a grind stands. Payouts
+// are only listed once collection has finished and the pot is known.
+type GrindSettlementReportDTO struct {
+	GrindID          string             `json:"grind_id"`
+	Status           string             `json:"status"`
+	PotCents         int64              `json:"pot_cents"`         // penalties actually collected
+	PaidOutCents     int64              `json:"paid_out_cents"`    // payouts that have settled
+	UnallocatedCents int64              `json:"unallocated_cents"` // collected but owed to nobody, e.g. when nobody completed
+	Charges          []SettlementLegDTO `json:"charges"`
+	Payouts          []SettlementLegDTO `json:"payouts"`
+}
+
+type SettleDueGrindsResultDTO struct {
+	Reports []GrindSettlementReportDTO `json:"reports"`
+	Errors  map[string]string          `json:"errors"` // grind ID -> error, for grinds that could not be settled
+}
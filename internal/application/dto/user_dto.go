@@ -13,6 +13,9 @@ type UpdateUserDTO struct {
 	Username               *string `json:"username"`
 	Avatar                 *string `json:"avatar"`
 	DefaultPaymentMethodID *string `json:"defaultPaymentMethodID"`
+	Timezone               *string `json:"timezone"`
+	LeetCodeUsername       *string `json:"leetcodeUsername"` // empty unlinks the LeetCode profile
+	Locale                 *string `json:"locale"`
 }
 
 type GetUserDTO struct {
@@ -34,9 +37,12 @@ type VerifyPasswordDTO struct {
 
 // Output DTOs
 type UserDTO struct {
-	ID             string `json:"id"`
-	Username       string `json:"username"`
-	Email          string `json:"email"`
-	Avatar         string `json:"avatar"`
-	HashedPassword string `json:"password"`
+	ID               string `json:"id"`
+	Username         string `json:"username"`
+	Email            string `json:"email"`
+	Avatar           string `json:"avatar"`
+	HashedPassword   string `json:"password"`
+	Timezone         string `json:"timezone"`
+	LeetCodeUsername string `json:"leetcodeUsername,omitempty"`
+	Locale           string `json:"locale"`
 }
@@ -0,0 +1,29 @@
+package mappers
+
+import (
+	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+)
+
+// BuildCompletionDisputeDTO constructs CompletionDisputeDTO from a CompletionDispute; nil yields nil.
+func BuildCompletionDisputeDTO(dispute *entities.CompletionDispute) *dto.CompletionDisputeDTO {
+	if dispute == nil {
+		return nil
+	}
+	return &dto.CompletionDisputeDTO{
+		ID:                dispute.ID,
+		CompletionEventID: dispute.CompletionEventID,
+		HabitTaskID:       dispute.HabitTaskID,
+		GrindID:           dispute.GrindID,
+		SubjectID:         dispute.SubjectID,
+		FlaggedBy:         dispute.FlaggedBy,
+		Reason:            dispute.Reason,
+		Quorum:            dispute.Quorum,
+		RevertVotes:       dispute.RevertVotes,
+		UpholdVotes:       dispute.UpholdVotes,
+		Status:            string(dispute.Status),
+		ExpiresAt:         dispute.ExpiresAt,
+		CreatedAt:         dispute.CreatedAt,
+		UpdatedAt:         dispute.UpdatedAt,
+	}
+}
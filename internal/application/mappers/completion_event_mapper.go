@@ -2,6 +2,7 @@ package mappers
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
 	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
@@ -16,12 +17,26 @@ func BuildCompletionEventDTO(event *entities.CompletionEvent) *dto.CompletionEve
 		}
 	}
 
+	var evidence *dto.EvidenceDTO
+	if event.Evidence != nil {
+		evidence = &dto.EvidenceDTO{
+			FileName:    event.Evidence.FileName,
+			ContentType: event.Evidence.ContentType,
+			Size:        event.Evidence.Size,
+			SHA256:      event.Evidence.SHA256,
+			URL:         "/api/v2/completion-events/" + event.ID + "/evidence",
+		}
+	}
+
 	return &dto.CompletionEventDTO{
-		ID:          event.ID,
-		HabitTaskID: event.HabitTaskID,
-		UserID:      event.UserID,
-		Provider:    string(event.Provider),
-		OccurredAt:  event.OccurredAt,
-		Metadata:    metadata,
+		ID:            event.ID,
+		HabitTaskID:   event.HabitTaskID,
+		UserID:        event.UserID,
+		Provider:      string(event.Provider),
+		OccurredAt:    event.OccurredAt,
+		Metadata:      metadata,
+		DedupKey:      event.DedupKey,
+		LateBySeconds: int64(event.LateBy / time.Second),
+		Evidence:      evidence,
 	}
 }
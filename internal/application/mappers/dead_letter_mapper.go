@@ -0,0 +1,21 @@
+package mappers
+
+import (
+	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+)
+
+// BuildDeadLetterDTO constructs a DeadLetterDTO from a DeadLetter entity.
+func BuildDeadLetterDTO(letter *entities.DeadLetter) *dto.DeadLetterDTO {
+	return &dto.DeadLetterDTO{
+		ID:            letter.ID,
+		UserID:        letter.UserID,
+		Provider:      string(letter.Provider),
+		IntegrationID: letter.IntegrationID,
+		GrindID:       letter.GrindID,
+		Payload:       string(letter.Payload),
+		Reason:        string(letter.Reason),
+		Error:         letter.Error,
+		CreatedAt:     letter.CreatedAt,
+	}
+}
@@ -8,22 +8,33 @@ import (
 )
 
 // BuildGroupGrindDTO constructs GroupGrindDTO from Grind-related entities.
-func BuildGroupGrindDTO(grind *entities.Grind, participants []entities.User) *dto.GroupGrindDTO {
+// loc is the viewer's timezone; it decides which of grind.Tasks is today's task.
+func BuildGroupGrindDTO(
+	grind *entities.Grind,
+	participants []entities.User,
+	participations []*entities.Participation,
+	loc *time.Location,
+) *dto.GroupGrindDTO {
 	tasks := grind.Tasks
 	progressDTOs := make([]dto.HabitTaskProgressDTO, 0, len(tasks))
 	for i := range tasks {
-		p := BuildHabitTaskProgressDTO(&tasks[i])
+		p := BuildHabitTaskProgressDTO(&tasks[i], loc)
 		if p != nil {
 			progressDTOs = append(progressDTOs, *p)
 		}
 	}
 
 	var todayTaskDTO *dto.HabitTaskDTO
-	today := time.Now().UTC().Truncate(24 * time.Hour)
+	var nextTaskDate *time.Time
+	today := entities.LocalDayStart(time.Now(), loc)
 	for i := range tasks {
-		if tasks[i].Date.UTC().Truncate(24 * time.Hour).Equal(today) {
+		day := entities.LocalDayStart(tasks[i].Date, loc)
+		if day.Equal(today) {
 			todayTaskDTO = BuildHabitTaskDTO(&tasks[i])
-			break
+		}
+		// off days of the schedule have no task, so the next one may be days away
+		if !day.Before(today) && (nextTaskDate == nil || day.Before(*nextTaskDate)) {
+			nextTaskDate = &day
 		}
 	}
 
@@ -32,6 +43,18 @@ func BuildGroupGrindDTO(grind *entities.Grind, participants []entities.User) *dt
 		participantDTOs = append(participantDTOs, *BuildUserDTO(&p))
 	}
 
+	penaltyDTOs := make([]dto.ParticipantPenaltyDTO, 0, len(participations))
+	for _, p := range participations {
+		penaltyDTOs = append(penaltyDTOs, dto.ParticipantPenaltyDTO{
+			UserID:           p.UserID,
+			MissedDays:       p.MissedDays,
+			FrozenDays:       p.FrozenDays,
+			FreezeTokensLeft: grind.PenaltyPolicy.FreezeTokensLeft(p),
+			TotalPenalty:     p.TotalPenalty,
+			Quitted:          p.Quitted,
+		})
+	}
+
 	return &dto.GroupGrindDTO{
 		ID:           grind.ID,
 		Duration:     grind.Duration,
@@ -42,12 +65,102 @@ func BuildGroupGrindDTO(grind *entities.Grind, participants []entities.User) *dt
 		Progress:     progressDTOs,
 		Participants: participantDTOs,
 		TodayTask:    todayTaskDTO,
+		NextTaskDate: nextTaskDate,
+		Schedule:     *BuildScheduleDTO(grind.Schedule),
+		RequiredDays: grind.RequiredDays(),
+		Roadmap:      BuildRoadmapDTO(grind.Roadmap),
+		Policy:       *BuildPenaltyPolicyDTO(grind.PenaltyPolicy),
+		Penalties:    penaltyDTOs,
+		State:        string(grind.State),
+		PublishedAt:  grind.PublishedAt,
+		ActivatedAt:  grind.ActivatedAt,
+		SettlingAt:   grind.SettlingAt,
+		SettledAt:    grind.SettledAt,
+		CancelledAt:  grind.CancelledAt,
+	}
+}
+
+// BuildStakeChangeDTO constructs StakeChangeDTO from a StakeChange; nil yields nil.
+func BuildStakeChangeDTO(change *entities.StakeChange) *dto.StakeChangeDTO {
+	if change == nil {
+		return nil
+	}
+	return &dto.StakeChangeDTO{
+		ID:         change.ID,
+		GrindID:    change.GrindID,
+		ProposerID: change.ProposerID,
+		Budget:     change.Budget,
+		Policy:     *BuildPenaltyPolicyDTO(change.PenaltyPolicy),
+		ApprovedBy: change.ApprovedBy,
+		RejectedBy: change.RejectedBy,
+		Status:     string(change.Status),
+		CreatedAt:  change.CreatedAt,
+	}
+}
+
+// BuildPenaltyPolicyDTO constructs PenaltyPolicyDTO from a PenaltyPolicy value.
+func BuildPenaltyPolicyDTO(policy entities.PenaltyPolicy) *dto.PenaltyPolicyDTO {
+	return &dto.PenaltyPolicyDTO{
+		Kind:         string(policy.Kind),
+		Amount:       policy.Amount,
+		Increment:    policy.Increment,
+		GraceDays:    policy.GraceDays,
+		FreezeTokens: policy.FreezeTokens,
+		CapAtBudget:  policy.CapAtBudget,
+	}
+}
+
+// BuildScheduleDTO constructs ScheduleDTO from a Schedule value; an unset kind is daily.
+func BuildScheduleDTO(schedule entities.Schedule) *dto.ScheduleDTO {
+	kind := schedule.Kind
+	if kind == "" {
+		kind = entities.ScheduleKindDaily
+	}
+	dates := make([]string, 0, len(schedule.Dates))
+	for _, date := range schedule.Dates {
+		dates = append(dates, date.Format("2006-01-02"))
+	}
+	return &dto.ScheduleDTO{
+		Kind:         string(kind),
+		TimesPerWeek: schedule.TimesPerWeek,
+		Dates:        dates,
+	}
+}
+
+// BuildRoadmapDTO constructs RoadmapDTO; nil for grinds without a roadmap.
+func BuildRoadmapDTO(roadmap *entities.Roadmap) *dto.RoadmapDTO {
+	if roadmap == nil {
+		return nil
+	}
+	return &dto.RoadmapDTO{
+		Name:  roadmap.Name,
+		Order: string(roadmap.Order),
+		Tags:  roadmap.Tags,
+		Slugs: roadmap.Slugs,
+	}
+}
+
+// BuildLeaderboardEntryDTO constructs LeaderboardEntryDTO from a participant's statistics.
+func BuildLeaderboardEntryDTO(stats *entities.ParticipantStats, rank int) *dto.LeaderboardEntryDTO {
+	return &dto.LeaderboardEntryDTO{
+		Rank:           rank,
+		UserID:         stats.UserID,
+		Username:       stats.Username,
+		CompletedDays:  stats.CompletedDays,
+		CompletionRate: stats.CompletionRate(),
+		CurrentStreak:  stats.CurrentStreak,
+		LongestStreak:  stats.LongestStreak,
+		MissedDays:     stats.MissedDays,
+		FrozenDays:     stats.FrozenDays,
+		TotalPenalty:   stats.TotalPenalty,
+		Quitted:        stats.Quitted,
 	}
 }
 
 // BuildMessageGrindDTO constructs MessageGrindDTO from Grind-related entity.
 func BuildMessageGrindDTO(grind *entities.Grind) *dto.MessageGrindDTO {
 	return &dto.MessageGrindDTO{
+		ID:           grind.ID,
 		Duration:     grind.Duration,
 		StartDate:    grind.StartDate,
 		Budget:       grind.Budget,
@@ -18,28 +18,32 @@ func BuildHabitTaskDTO(task *entities.HabitTask) *dto.HabitTaskDTO {
 	}
 
 	return &dto.HabitTaskDTO{
-		ID:           task.ID,
-		TaskType:     task.TaskType,
-		UserID:       task.UserID,
-		GrindID:      task.GrindID,
-		Date:         task.Date,
-		FinishedTime: task.FinishedTime,
-		Completed:    task.Completed,
-		Metadata:     metadata,
+		ID:             task.ID,
+		TaskType:       task.TaskType,
+		UserID:         task.UserID,
+		GrindID:        task.GrindID,
+		Date:           task.Date,
+		FinishedTime:   task.FinishedTime,
+		Completed:      task.Completed,
+		Missed:         task.Missed,
+		Excused:        task.Excused,
+		RequiredEvents: task.RequiredEvents,
+		Metadata:       metadata,
 	}
 }
 
 // BuildHabitTaskProgressDTO builds a compact progress DTO from a HabitTask entity.
-func BuildHabitTaskProgressDTO(task *entities.HabitTask) *dto.HabitTaskProgressDTO {
+// loc is the task owner's timezone; a task whose local day has ended is reported as missed.
+func BuildHabitTaskProgressDTO(task *entities.HabitTask, loc *time.Location) *dto.HabitTaskProgressDTO {
 	status := "pending"
 	if task.Completed {
 		status = "completed"
-	} else {
-		startOfTaskDay := task.Date.UTC().Truncate(24 * time.Hour).Add(time.Hour)
-		startOfToday := time.Now().UTC().Truncate(24 * time.Hour)
-		if startOfTaskDay.Before(startOfToday) {
-			status = "missed"
-		}
+	} else if task.Missed {
+		status = "missed"
+	} else if task.Excused {
+		status = "excused"
+	} else if !time.Now().Before(task.DayEnd(loc)) {
+		status = "missed"
 	}
 	return &dto.HabitTaskProgressDTO{
 		ID:           task.ID,
@@ -0,0 +1,23 @@
+package mappers
+
+import (
+	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+)
+
+// BuildIntegrationDTO constructs an IntegrationDTO from an Integration entity. The secret is
+// left out; the service adds it to the creation response only.
+func BuildIntegrationDTO(integration *entities.Integration) *dto.IntegrationDTO {
+	return &dto.IntegrationDTO{
+		ID:      integration.ID,
+		Name:    integration.Name,
+		OwnerID: integration.OwnerID,
+		GroupID: integration.GroupID,
+		Mapping: dto.IntegrationFieldMappingDTO{
+			OccurredAt: integration.Mapping.OccurredAt,
+			Evidence:   integration.Mapping.Evidence,
+			EventID:    integration.Mapping.EventID,
+		},
+		CreatedAt: integration.CreatedAt,
+	}
+}
@@ -24,5 +24,33 @@ func BuildMessageDTO(message *entities.Message, sender *entities.User, receiver
 		Read:               message.Read,
 		CreatedAt:          message.CreatedAt,
 		UpdatedAt:          message.UpdatedAt,
+
+		InvitationExpiresAt: message.InvitationExpiresAt,
+		InvitationRevokedAt: message.InvitationRevokedAt,
+	}
+}
+
+// BuildConversationDTO constructs Conversation DTO from a Conversation and its last message's DTO
+func BuildConversationDTO(conversation *entities.Conversation, lastMessage *dto.MessageDTO) *dto.ConversationDTO {
+	counterpart := lastMessage.Sender
+	if lastMessage.Receiver != nil && lastMessage.Receiver.ID == conversation.CounterpartID {
+		counterpart = lastMessage.Receiver
+	}
+	return &dto.ConversationDTO{
+		Counterpart: counterpart,
+		LastMessage: lastMessage,
+		UnreadCount: conversation.UnreadCount,
+		Muted:       conversation.Muted,
+		Blocked:     conversation.Blocked,
+	}
+}
+
+// BuildConversationSettingDTO constructs ConversationSetting DTO from a ConversationSetting
+func BuildConversationSettingDTO(setting *entities.ConversationSetting) *dto.ConversationSettingDTO {
+	return &dto.ConversationSettingDTO{
+		CounterpartID: setting.CounterpartID,
+		Blocked:       setting.Blocked,
+		Muted:         setting.Muted,
+		UpdatedAt:     setting.UpdatedAt,
 	}
 }
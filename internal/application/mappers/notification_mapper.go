@@ -0,0 +1,16 @@
+package mappers
+
+import (
+	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+)
+
+// BuildNotificationDTO constructs a NotificationDTO from a Notification entity.
+func BuildNotificationDTO(notification *entities.Notification) *dto.NotificationDTO {
+	return &dto.NotificationDTO{
+		ID:        notification.ID,
+		Type:      string(notification.Type),
+		Data:      notification.Data,
+		CreatedAt: notification.CreatedAt,
+	}
+}
@@ -8,10 +8,13 @@ import (
 // BuildUserDTO constructs User DTO from User-related entity
 func BuildUserDTO(user *entities.User) *dto.UserDTO {
 	return &dto.UserDTO{
-		ID:             user.ID,
-		Username:       user.Username,
-		Email:          user.Email,
-		Avatar:         user.Avatar,
-		HashedPassword: user.HashedPassword,
+		ID:               user.ID,
+		Username:         user.Username,
+		Email:            user.Email,
+		Avatar:           user.Avatar,
+		HashedPassword:   user.HashedPassword,
+		Timezone:         user.Timezone,
+		LeetCodeUsername: user.LeetCodeUsername,
+		Locale:           user.Locale,
 	}
 }
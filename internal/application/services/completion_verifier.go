@@ -0,0 +1,21 @@
+package services
+
+import (
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+)
+
+// CompletionVerifier judges whether a completion's evidence or metadata plausibly satisfies
+// the habit it claims to complete. Unlike a provider's VerifyCompletion it does not reject
+// anything: its verdict is recorded on the event, and completions it doubts go to the
+// grind's partners for review.
+type CompletionVerifier interface {
+	// Verify returns the verdict on request, or nil when the verifier has no opinion.
+	Verify(request entities.CompletionVerification) (*entities.CompletionVerdict, error)
+}
+
+// NoopVerifier has no opinion on any completion; with it every completion counts as sent.
+type NoopVerifier struct{}
+
+func (NoopVerifier) Verify(request entities.CompletionVerification) (*entities.CompletionVerdict, error) {
+	return nil, nil
+}
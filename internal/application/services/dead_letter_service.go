@@ -0,0 +1,136 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
+	"github.com/daniel0321forever/terriyaki-go/internal/application/mappers"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/repositories"
+)
+
+// DeadLetterService lets users inspect the completion signals ingest could not record and
+// either re-drive them once the cause is fixed or discard them. The evidence a dead letter
+// keeps is deleted through evidenceService once nothing records it.
+type DeadLetterService struct {
+	deadLetterRepo  repositories.DeadLetterRepository
+	integrationRepo repositories.IntegrationRepository
+	ingestService   *IngestService
+	evidenceService *EvidenceService
+}
+
+func NewDeadLetterService(
+	deadLetterRepo repositories.DeadLetterRepository,
+	integrationRepo repositories.IntegrationRepository,
+	ingestService *IngestService,
+	evidenceService *EvidenceService,
+) *DeadLetterService {
+	return &DeadLetterService{
+		deadLetterRepo:  deadLetterRepo,
+		integrationRepo: integrationRepo,
+		ingestService:   ingestService,
+		evidenceService: evidenceService,
+	}
+}
+
+// GetUserDeadLetters lists the user's dead letters, newest first.
+func (s *DeadLetterService) GetUserDeadLetters(request dto.GetUserDeadLettersDTO) ([]*dto.DeadLetterDTO, error) {
+	letters, err := s.deadLetterRepo.FindByUserID(request.UserID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*dto.DeadLetterDTO, 0, len(letters))
+	for _, letter := range letters {
+		result = append(result, mappers.BuildDeadLetterDTO(letter))
+	}
+	return result, nil
+}
+
+// RedriveDeadLetter ingests the dead letter's payload again, for request.HabitTaskID or for
+// the task of the completion's day in request.GrindID, falling back to the grind the
+// payload was sent for. On success the dead letter is removed and the recorded event, or the
+// original event when the signal has since been recorded by a retry, is returned. The dead
+// letter's evidence is recorded on the new event; the original event keeps its own. A
+// failed re-drive returns the ingest error and keeps the dead letter and its evidence.
+// Re-driving is an explicit decision that the signal still counts, so it is not held to the
+// grace window; a task whose day was already evaluated as missed or excused still refuses
+// it, so a re-drive never reprices a penalty.
+func (s *DeadLetterService) RedriveDeadLetter(request dto.RedriveDeadLetterDTO) (*entities.CompletionEvent, error) {
+	letter, err := s.findOwnDeadLetter(request.UserID, request.DeadLetterID)
+	if err != nil {
+		return nil, err
+	}
+
+	grindID := request.GrindID
+	if grindID == "" {
+		grindID = letter.GrindID
+	}
+	if grindID == "" && request.HabitTaskID == "" {
+		return nil, fmt.Errorf("%w: grindID or habitTaskID is required", config.ErrInvalidRedrive)
+	}
+
+	var rawPayload map[string]interface{}
+	if err := json.Unmarshal(letter.Payload, &rawPayload); err != nil || rawPayload == nil {
+		return nil, config.ErrDeadLetterUnreadable
+	}
+
+	provider, err := s.providerFor(letter)
+	if err != nil {
+		return nil, err
+	}
+	event, replayed, err := s.ingestService.ingest(provider, letter.UserID, grindID, request.HabitTaskID, rawPayload, letter.Evidence, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.deadLetterRepo.Delete(letter.ID); err != nil {
+		return nil, fmt.Errorf("failed to remove re-driven dead letter: %w", err)
+	}
+	if replayed {
+		s.evidenceService.DiscardEvidence(letter.Evidence)
+	}
+	return event, nil
+}
+
+// DiscardDeadLetter removes a dead letter, and the evidence it kept, without ingesting it.
+func (s *DeadLetterService) DiscardDeadLetter(request dto.DiscardDeadLetterDTO) error {
+	letter, err := s.findOwnDeadLetter(request.UserID, request.DeadLetterID)
+	if err != nil {
+		return err
+	}
+	if err := s.deadLetterRepo.Delete(letter.ID); err != nil {
+		return err
+	}
+	s.evidenceService.DiscardEvidence(letter.Evidence)
+	return nil
+}
+
+func (s *DeadLetterService) findOwnDeadLetter(userID, deadLetterID string) (*entities.DeadLetter, error) {
+	letter, err := s.deadLetterRepo.FindByID(deadLetterID)
+	if err != nil || letter == nil {
+		return nil, config.ErrDeadLetterNotFound
+	}
+	if letter.UserID != userID {
+		return nil, config.ErrForbidden
+	}
+	return letter, nil
+}
+
+// providerFor returns the provider that reads the dead letter's payload. Custom payloads
+// need the integration they were signed by; it must still exist.
+func (s *DeadLetterService) providerFor(letter *entities.DeadLetter) (IngestionProvider, error) {
+	if letter.Provider != entities.ProviderCustom {
+		provider, ok := s.ingestService.providers[string(letter.Provider)]
+		if !ok {
+			return nil, fmt.Errorf("unsupported provider: %s", letter.Provider)
+		}
+		return provider, nil
+	}
+	integration, err := s.integrationRepo.FindByID(letter.IntegrationID)
+	if err != nil || integration == nil {
+		return nil, config.ErrIntegrationNotFound
+	}
+	return &CustomProvider{integration: integration}, nil
+}
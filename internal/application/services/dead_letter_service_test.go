@@ -0,0 +1,278 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func Test_IngestService_Ingest_KeepsUnmatchedSignalAsDeadLetter(t *testing.T) {
+	t.Parallel()
+
+	repos := newTestRepos()
+	repos.completionEvent = newIngestEventRepo()
+	ingestService := NewIngestService(nil, newIngestUserRepo("UTC"), repos.habitTask, repos.completionEvent, repos.deadLetter, nil, nil, nil)
+	ingestService.now = func() time.Time { return time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC) }
+
+	evidence := &entities.EvidenceAttachment{Key: "evidence/proof", FileName: "proof.png", ContentType: "image/png"}
+	var kept *entities.DeadLetter
+	repos.habitTask.On("FindTaskOnDay", "user-1", "grind-1", mock.Anything, time.UTC).Return(nil, gorm.ErrRecordNotFound)
+	repos.deadLetter.On("Create", mock.MatchedBy(func(letter *entities.DeadLetter) bool {
+		return letter.UserID == "user-1" && letter.GrindID == "grind-1" &&
+			letter.Provider == entities.ProviderDuolingo &&
+			letter.Reason == entities.DeadLetterReasonNoTask &&
+			string(letter.Payload) == `{"occurredAt":"2026-04-10T11:00:00Z"}`
+	})).Run(func(args mock.Arguments) { kept = args.Get(0).(*entities.DeadLetter) }).Return(nil)
+
+	_, _, err := ingestService.Ingest("duolingo", "user-1", "grind-1", map[string]interface{}{"occurredAt": "2026-04-10T11:00:00Z"}, evidence)
+	assert.True(t, errors.Is(err, config.ErrHabitTaskNotFound))
+	repos.deadLetter.AssertExpectations(t)
+	// the dead letter keeps the evidence for its re-drive
+	var deadLettered *DeadLetteredError
+	require.ErrorAs(t, err, &deadLettered)
+	assert.Equal(t, kept.ID, deadLettered.DeadLetterID)
+	assert.Same(t, evidence, kept.Evidence)
+}
+
+func Test_IngestService_Ingest_KeepsInvalidPayloadAsDeadLetter(t *testing.T) {
+	t.Parallel()
+
+	repos := newTestRepos()
+	repos.completionEvent = newIngestEventRepo()
+	ingestService := NewIngestService(nil, newIngestUserRepo("UTC"), repos.habitTask, repos.completionEvent, repos.deadLetter, nil, nil, nil)
+	ingestService.now = func() time.Time { return time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC) }
+
+	repos.deadLetter.On("Create", mock.MatchedBy(func(letter *entities.DeadLetter) bool {
+		return letter.Provider == entities.ProviderLeetCode &&
+			letter.Reason == entities.DeadLetterReasonInvalidPayload &&
+			string(letter.Payload) == `{"problemSlug":"Two Sum"}`
+	})).Return(nil)
+
+	_, _, err := ingestService.Ingest("leetcode", "user-1", "grind-1", map[string]interface{}{"problemSlug": "Two Sum"}, nil)
+	var invalid *PayloadValidationError
+	assert.True(t, errors.As(err, &invalid))
+	assert.True(t, errors.Is(err, config.ErrInvalidPayload))
+	repos.deadLetter.AssertExpectations(t)
+	repos.habitTask.AssertNotCalled(t, "FindTaskOnDay", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func Test_IngestService_Ingest_TransientFailureIsNotDeadLettered(t *testing.T) {
+	t.Parallel()
+
+	repos := newTestRepos()
+	repos.completionEvent = newIngestEventRepo()
+	ingestService := NewIngestService(nil, newIngestUserRepo("UTC"), repos.habitTask, repos.completionEvent, repos.deadLetter, nil, nil, nil)
+	ingestService.now = func() time.Time { return time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC) }
+
+	repos.habitTask.On("FindTaskOnDay", "user-1", "grind-1", mock.Anything, time.UTC).Return(nil, errors.New("connection reset"))
+
+	_, _, err := ingestService.Ingest("duolingo", "user-1", "grind-1", map[string]interface{}{"occurredAt": "2026-04-10T11:00:00Z"}, nil)
+	assert.Error(t, err)
+	var deadLettered *DeadLetteredError
+	assert.False(t, errors.As(err, &deadLettered))
+	repos.deadLetter.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func Test_DeadLetterService_RedriveDeadLetter_ToTask(t *testing.T) {
+	t.Parallel()
+
+	repos := newTestRepos()
+	repos.completionEvent = newIngestEventRepo()
+	ingestService := NewIngestService(nil, newIngestUserRepo("UTC"), repos.habitTask, repos.completionEvent, repos.deadLetter, nil, nil, nil)
+	ingestService.now = func() time.Time { return time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC) }
+	blobStore := newMemoryBlobStore()
+	require.NoError(t, blobStore.Put("evidence/proof", bytes.NewReader(pngEvidence)))
+	svc := NewDeadLetterService(repos.deadLetter, repos.integration, ingestService,
+		NewEvidenceService(blobStore, repos.completionEvent, repos.habitTask, repos.participation, repos.partnerGroup))
+
+	evidence := &entities.EvidenceAttachment{Key: "evidence/proof", FileName: "proof.png", ContentType: "image/png"}
+	letter := &entities.DeadLetter{
+		ID: "dl-1", UserID: "user-1", Provider: entities.ProviderDuolingo, GrindID: "grind-old",
+		Payload: []byte(`{"occurredAt":"2026-04-10T11:00:00Z"}`), Reason: entities.DeadLetterReasonNoTask,
+		Evidence: evidence,
+	}
+	task := &entities.HabitTask{ID: "task-1", UserID: "user-1", GrindID: "grind-1", Date: time.Date(2026, 4, 10, 0, 0, 0, 0, time.UTC)}
+	repos.deadLetter.On("FindByID", "dl-1").Return(letter, nil)
+	repos.deadLetter.On("Delete", "dl-1").Return(nil)
+	repos.habitTask.On("FindByID", "task-1").Return(task, nil)
+	repos.completionEvent.On("CreateIfAbsent", mock.MatchedBy(func(event *entities.CompletionEvent) bool {
+		return event.HabitTaskID == "task-1"
+	})).Return(true, nil)
+	repos.completionEvent.On("FindByHabitTaskID", "task-1").Return([]*entities.CompletionEvent{{ID: "event-1"}}, nil)
+	repos.habitTask.On("Complete", task.ID, mock.Anything).Return(true, nil)
+
+	event, err := svc.RedriveDeadLetter(dto.RedriveDeadLetterDTO{UserID: "user-1", DeadLetterID: "dl-1", HabitTaskID: "task-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "task-1", event.HabitTaskID)
+	assert.True(t, task.Completed)
+	// the dead letter's evidence now belongs to the event
+	assert.Same(t, evidence, event.Evidence)
+	assert.Contains(t, blobStore.blobs, "evidence/proof")
+	repos.deadLetter.AssertExpectations(t)
+	// the retry check looks in the task's grind, not the one the payload was sent for
+	repos.completionEvent.AssertCalled(t, "FindByDedupKey", "user-1", "grind-1", entities.ProviderDuolingo, mock.Anything)
+	// a re-drive never files a new dead letter
+	repos.deadLetter.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func Test_DeadLetterService_RedriveDeadLetter_PastGraceWindow(t *testing.T) {
+	t.Parallel()
+
+	// the letter was filed five days ago, long after the grace window closed
+	repos := newTestRepos()
+	repos.completionEvent = newIngestEventRepo()
+	ingestService := NewIngestService(nil, newIngestUserRepo("UTC"), repos.habitTask, repos.completionEvent, repos.deadLetter, nil, nil, nil)
+	ingestService.now = func() time.Time { return time.Date(2026, 4, 15, 12, 0, 0, 0, time.UTC) }
+	svc := NewDeadLetterService(repos.deadLetter, repos.integration, ingestService, NewEvidenceService(newMemoryBlobStore(), repos.completionEvent, repos.habitTask, repos.participation, repos.partnerGroup))
+
+	letter := &entities.DeadLetter{
+		ID: "dl-1", UserID: "user-1", Provider: entities.ProviderDuolingo, GrindID: "grind-1",
+		Payload: []byte(`{"occurredAt":"2026-04-10T11:00:00Z"}`), Reason: entities.DeadLetterReasonNoTask,
+	}
+	day := time.Date(2026, 4, 10, 0, 0, 0, 0, time.UTC)
+	open := &entities.HabitTask{ID: "task-1", UserID: "user-1", GrindID: "grind-1", Date: day}
+	missed := &entities.HabitTask{ID: "task-2", UserID: "user-1", GrindID: "grind-1", Date: day, Missed: true}
+	repos.deadLetter.On("FindByID", "dl-1").Return(letter, nil)
+	repos.deadLetter.On("Delete", "dl-1").Return(nil)
+	repos.habitTask.On("FindByID", "task-1").Return(open, nil)
+	repos.habitTask.On("FindByID", "task-2").Return(missed, nil)
+	repos.completionEvent.On("CreateIfAbsent", mock.MatchedBy(func(event *entities.CompletionEvent) bool {
+		return event.HabitTaskID == "task-1"
+	})).Return(true, nil)
+	repos.completionEvent.On("FindByHabitTaskID", "task-1").Return([]*entities.CompletionEvent{{ID: "event-1"}}, nil)
+	repos.habitTask.On("Complete", open.ID, mock.Anything).Return(true, nil)
+
+	// a day that was already evaluated keeps its penalty
+	_, err := svc.RedriveDeadLetter(dto.RedriveDeadLetterDTO{UserID: "user-1", DeadLetterID: "dl-1", HabitTaskID: "task-2"})
+	assert.ErrorIs(t, err, config.ErrIngestWindowClosed)
+	assert.False(t, missed.Completed)
+	repos.deadLetter.AssertNotCalled(t, "Delete", mock.Anything)
+
+	event, err := svc.RedriveDeadLetter(dto.RedriveDeadLetterDTO{UserID: "user-1", DeadLetterID: "dl-1", HabitTaskID: "task-1"})
+	require.NoError(t, err)
+	assert.Equal(t, 4*24*time.Hour+12*time.Hour, event.LateBy)
+	assert.True(t, open.Completed)
+	repos.deadLetter.AssertCalled(t, "Delete", "dl-1")
+}
+
+func Test_DeadLetterService_RedriveDeadLetter_FailureKeepsLetter(t *testing.T) {
+	t.Parallel()
+
+	repos := newTestRepos()
+	repos.completionEvent = newIngestEventRepo()
+	ingestService := NewIngestService(nil, newIngestUserRepo("UTC"), repos.habitTask, repos.completionEvent, repos.deadLetter, nil, nil, nil)
+	ingestService.now = func() time.Time { return time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC) }
+	svc := NewDeadLetterService(repos.deadLetter, repos.integration, ingestService, NewEvidenceService(newMemoryBlobStore(), repos.completionEvent, repos.habitTask, repos.participation, repos.partnerGroup))
+
+	letter := &entities.DeadLetter{
+		ID: "dl-1", UserID: "user-1", Provider: entities.ProviderDuolingo, GrindID: "grind-1",
+		Payload: []byte(`{"occurredAt":"2026-04-10T11:00:00Z"}`), Reason: entities.DeadLetterReasonNoTask,
+	}
+	repos.deadLetter.On("FindByID", "dl-1").Return(letter, nil)
+	repos.habitTask.On("FindTaskOnDay", "user-1", "grind-1", mock.Anything, time.UTC).Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := svc.RedriveDeadLetter(dto.RedriveDeadLetterDTO{UserID: "user-1", DeadLetterID: "dl-1"})
+	assert.True(t, errors.Is(err, config.ErrHabitTaskNotFound))
+	repos.deadLetter.AssertNotCalled(t, "Delete", mock.Anything)
+	repos.deadLetter.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func Test_DeadLetterService_RedriveDeadLetter_CustomUsesIntegration(t *testing.T) {
+	t.Parallel()
+
+	repos := newTestRepos()
+	repos.completionEvent = newIngestEventRepo()
+	ingestService := NewIngestService(nil, newIngestUserRepo("UTC"), repos.habitTask, repos.completionEvent, repos.deadLetter, nil, nil, nil)
+	ingestService.now = func() time.Time { return time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC) }
+	svc := NewDeadLetterService(repos.deadLetter, repos.integration, ingestService, NewEvidenceService(newMemoryBlobStore(), repos.completionEvent, repos.habitTask, repos.participation, repos.partnerGroup))
+
+	letter := &entities.DeadLetter{
+		ID: "dl-1", UserID: "user-1", Provider: entities.ProviderCustom, IntegrationID: "int-1", GrindID: "grind-1",
+		Payload: []byte(`{"run":{"end":"2026-04-10T11:00:00Z"}}`), Reason: entities.DeadLetterReasonNoTask,
+	}
+	integration := &entities.Integration{ID: "int-1", OwnerID: "user-1", Mapping: entities.IntegrationFieldMapping{OccurredAt: "run.end"}}
+	task := &entities.HabitTask{ID: "task-1", UserID: "user-1", GrindID: "grind-1", Date: time.Date(2026, 4, 10, 0, 0, 0, 0, time.UTC)}
+	repos.deadLetter.On("FindByID", "dl-1").Return(letter, nil)
+	repos.deadLetter.On("Delete", "dl-1").Return(nil)
+	repos.integration.On("FindByID", "int-1").Return(integration, nil)
+	repos.habitTask.On("FindTaskOnDay", "user-1", "grind-1", time.Date(2026, 4, 10, 11, 0, 0, 0, time.UTC), time.UTC).Return(task, nil)
+	repos.completionEvent.On("CreateIfAbsent", mock.AnythingOfType("*entities.CompletionEvent")).Return(true, nil)
+	repos.completionEvent.On("FindByHabitTaskID", "task-1").Return([]*entities.CompletionEvent{{ID: "event-1"}}, nil)
+	repos.habitTask.On("Complete", task.ID, mock.Anything).Return(true, nil)
+
+	event, err := svc.RedriveDeadLetter(dto.RedriveDeadLetterDTO{UserID: "user-1", DeadLetterID: "dl-1"})
+	require.NoError(t, err)
+	assert.Equal(t, entities.ProviderCustom, event.Provider)
+	assert.Contains(t, string(event.Metadata), `"integrationID":"int-1"`)
+}
+
+func Test_DeadLetterService_DiscardDeadLetter_DeletesEvidence(t *testing.T) {
+	t.Parallel()
+
+	repos := newTestRepos()
+	ingestService := NewIngestService(nil, newIngestUserRepo("UTC"), repos.habitTask, repos.completionEvent, repos.deadLetter, nil, nil, nil)
+	blobStore := newMemoryBlobStore()
+	require.NoError(t, blobStore.Put("evidence/proof", bytes.NewReader(pngEvidence)))
+	svc := NewDeadLetterService(repos.deadLetter, repos.integration, ingestService,
+		NewEvidenceService(blobStore, repos.completionEvent, repos.habitTask, repos.participation, repos.partnerGroup))
+
+	repos.deadLetter.On("FindByID", "dl-1").Return(&entities.DeadLetter{
+		ID: "dl-1", UserID: "user-1", Provider: entities.ProviderDuolingo,
+		Evidence: &entities.EvidenceAttachment{Key: "evidence/proof"},
+	}, nil)
+	repos.deadLetter.On("Delete", "dl-1").Return(nil)
+
+	require.NoError(t, svc.DiscardDeadLetter(dto.DiscardDeadLetterDTO{UserID: "user-1", DeadLetterID: "dl-1"}))
+	repos.deadLetter.AssertExpectations(t)
+	assert.Empty(t, blobStore.blobs)
+}
+
+func Test_DeadLetterService_Errors(t *testing.T) {
+	t.Parallel()
+
+	repos := newTestRepos()
+	repos.completionEvent = newIngestEventRepo()
+	ingestService := NewIngestService(nil, newIngestUserRepo("UTC"), repos.habitTask, repos.completionEvent, repos.deadLetter, nil, nil, nil)
+	ingestService.now = func() time.Time { return time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC) }
+	svc := NewDeadLetterService(repos.deadLetter, repos.integration, ingestService, NewEvidenceService(newMemoryBlobStore(), repos.completionEvent, repos.habitTask, repos.participation, repos.partnerGroup))
+
+	repos.deadLetter.On("FindByID", "missing").Return(nil, gorm.ErrRecordNotFound)
+	repos.deadLetter.On("FindByID", "theirs").Return(&entities.DeadLetter{ID: "theirs", UserID: "user-2"}, nil)
+	repos.deadLetter.On("FindByID", "unreadable").Return(&entities.DeadLetter{
+		ID: "unreadable", UserID: "user-1", Provider: entities.ProviderLeetCode, GrindID: "grind-1", Payload: []byte("not json"),
+	}, nil)
+	repos.deadLetter.On("FindByID", "no-grind").Return(&entities.DeadLetter{
+		ID: "no-grind", UserID: "user-1", Provider: entities.ProviderLeetCode, Payload: []byte(`{}`),
+	}, nil)
+	repos.habitTask.On("FindByID", "their-task").Return(&entities.HabitTask{ID: "their-task", UserID: "user-2"}, nil)
+	repos.deadLetter.On("FindByID", "mine").Return(&entities.DeadLetter{
+		ID: "mine", UserID: "user-1", Provider: entities.ProviderLeetCode, Payload: []byte(`{"problemSlug":"two-sum"}`),
+	}, nil)
+
+	_, err := svc.RedriveDeadLetter(dto.RedriveDeadLetterDTO{UserID: "user-1", DeadLetterID: "missing", GrindID: "grind-1"})
+	assert.ErrorIs(t, err, config.ErrDeadLetterNotFound)
+
+	_, err = svc.RedriveDeadLetter(dto.RedriveDeadLetterDTO{UserID: "user-1", DeadLetterID: "theirs", GrindID: "grind-1"})
+	assert.ErrorIs(t, err, config.ErrForbidden)
+
+	_, err = svc.RedriveDeadLetter(dto.RedriveDeadLetterDTO{UserID: "user-1", DeadLetterID: "unreadable"})
+	assert.ErrorIs(t, err, config.ErrDeadLetterUnreadable)
+
+	_, err = svc.RedriveDeadLetter(dto.RedriveDeadLetterDTO{UserID: "user-1", DeadLetterID: "no-grind"})
+	assert.ErrorIs(t, err, config.ErrInvalidRedrive)
+
+	_, err = svc.RedriveDeadLetter(dto.RedriveDeadLetterDTO{UserID: "user-1", DeadLetterID: "mine", HabitTaskID: "their-task"})
+	assert.ErrorIs(t, err, config.ErrForbidden)
+
+	assert.ErrorIs(t, svc.DiscardDeadLetter(dto.DiscardDeadLetterDTO{UserID: "user-1", DeadLetterID: "theirs"}), config.ErrForbidden)
+	repos.deadLetter.AssertNotCalled(t, "Delete", mock.Anything)
+}
@@ -0,0 +1,513 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
+	"github.com/daniel0321forever/terriyaki-go/internal/application/mappers"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/repositories"
+	domainservices "github.com/daniel0321forever/terriyaki-go/internal/domain/services"
+	"gorm.io/gorm"
+)
+
+// disputeSettlementInterval is how often Run looks for disputes whose voting window ended.
+const disputeSettlementInterval = 5 * time.Minute
+
+// DisputeService lets a grind's partners challenge completions they believe were not
+// earned, and puts the completions the CompletionVerifier doubts before them. The reviewers
+// are the grind's active participants and the members of its partner group, except the user
+// whose completion is disputed; a majority of them decides. A reverted completion turns its
+// task into a missed day priced by the grind's PenaltyPolicy, an upheld one stands. The
+// subject and the flagger are told of the outcome through messages.
+type DisputeService struct {
+	db                  *gorm.DB
+	grindRepo           repositories.GrindRepository
+	userRepo            repositories.UserRepository
+	habitTaskRepo       repositories.HabitTaskRepository
+	participationRepo   repositories.ParticipationRepository
+	partnerGroupRepo    repositories.PartnerGroupRepository
+	completionEventRepo repositories.CompletionEventRepository
+	disputeRepo         repositories.CompletionDisputeRepository
+	messageRepo         repositories.MessageRepository
+	penaltyPolicy       *domainservices.PenaltyPolicyService
+	reviewWindow        time.Duration // completions can be disputed this long after they occurred, and disputes voted on this long after they opened
+	now                 func() time.Time
+}
+
+func NewDisputeService(
+	db *gorm.DB,
+	grindRepo repositories.GrindRepository,
+	userRepo repositories.UserRepository,
+	habitTaskRepo repositories.HabitTaskRepository,
+	participationRepo repositories.ParticipationRepository,
+	partnerGroupRepo repositories.PartnerGroupRepository,
+	completionEventRepo repositories.CompletionEventRepository,
+	disputeRepo repositories.CompletionDisputeRepository,
+	messageRepo repositories.MessageRepository,
+) *DisputeService {
+	return &DisputeService{
+		db:                  db,
+		grindRepo:           grindRepo,
+		userRepo:            userRepo,
+		habitTaskRepo:       habitTaskRepo,
+		participationRepo:   participationRepo,
+		partnerGroupRepo:    partnerGroupRepo,
+		completionEventRepo: completionEventRepo,
+		disputeRepo:         disputeRepo,
+		messageRepo:         messageRepo,
+		penaltyPolicy:       domainservices.NewPenaltyPolicyService(),
+		reviewWindow:        config.CompletionReviewWindow(),
+		now:                 time.Now,
+	}
+}
+
+// FlagCompletion opens a dispute over a completion event. The flagger must be one of the
+// event's reviewers and their flag counts as a vote to revert; the subject and the other
+// reviewers are asked to weigh in. When the flagger alone is a majority, e.g. in a grind of
+// two, the completion is reverted right away.
+// Returns ErrCompletionEventNotFound, ErrUserIsNotParticipant when the caller is not a
+// reviewer, ErrInvalidDispute for the caller's own completion or a task that is not
+// completed, ErrReviewWindowClosed, ErrDisputeExists and ErrParticipationFinalized when the
+// grind has been settled.
+func (s *DisputeService) FlagCompletion(request dto.FlagCompletionDTO) (*dto.CompletionDisputeDTO, error) {
+	event, err := s.completionEventRepo.FindByID(request.CompletionEventID)
+	if err != nil || event == nil {
+		return nil, config.ErrCompletionEventNotFound
+	}
+	if event.UserID == request.UserID {
+		return nil, fmt.Errorf("%w: a completion cannot be disputed by its own user", config.ErrInvalidDispute)
+	}
+	task, err := s.habitTaskRepo.FindByID(event.HabitTaskID)
+	if err != nil || task == nil {
+		return nil, config.ErrCompletionEventNotFound
+	}
+	reviewers, err := s.reviewers(task.GrindID, event.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !containsString(reviewers, request.UserID) {
+		return nil, config.ErrUserIsNotParticipant
+	}
+
+	now := s.now()
+	if now.After(event.OccurredAt.Add(s.reviewWindow)) {
+		return nil, config.ErrReviewWindowClosed
+	}
+	if !task.Completed {
+		return nil, fmt.Errorf("%w: the task is not completed", config.ErrInvalidDispute)
+	}
+	if participation, err := s.participationRepo.FindByUserAndGrind(event.UserID, task.GrindID); err == nil && participation != nil && participation.Finalized {
+		return nil, config.ErrParticipationFinalized
+	}
+	existing, err := s.disputeRepo.FindByCompletionEventID(event.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, config.ErrDisputeExists
+	}
+
+	dispute, err := entities.NewCompletionDispute(event, task.GrindID, request.UserID, strings.TrimSpace(request.Reason), len(reviewers), s.reviewWindow)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", config.ErrInvalidDispute, err)
+	}
+
+	txErr := runInTransaction(s.db, func(tx *gorm.DB) error {
+		msgRepo := getMessageRepo(s.messageRepo, tx)
+
+		opened := CompletionDisputedEvent{
+			Flagger: displayName(s.userRepo, dispute.FlaggedBy),
+			Subject: displayName(s.userRepo, dispute.SubjectID),
+			Day:     s.taskDay(task),
+			Reason:  dispute.Reason,
+		}
+		for _, userID := range append([]string{dispute.SubjectID}, reviewers...) {
+			if userID == dispute.FlaggedBy {
+				continue
+			}
+			if err := s.sendDisputeMessage(msgRepo, dispute.FlaggedBy, userID, dispute.GrindID, opened); err != nil {
+				return err
+			}
+		}
+
+		if err := s.settle(tx, dispute, now); err != nil {
+			return err
+		}
+		return getCompletionDisputeRepo(s.disputeRepo, tx).Create(dispute)
+	})
+	if txErr != nil {
+		return nil, txErr
+	}
+	return mappers.BuildCompletionDisputeDTO(dispute), nil
+}
+
+// RequestReview opens a dispute over a completion the CompletionVerifier doubts, so the
+// event's reviewers decide whether it counts. Nobody has voted yet; when no side reaches the
+// quorum before the voting window ends, the completion stands. The reviewers and the
+// subject are told through messages sent on the subject's behalf. A completion without
+// reviewers, e.g. in a solo grind, or one already disputed is left alone.
+func (s *DisputeService) RequestReview(event *entities.CompletionEvent, task *entities.HabitTask, verdict *entities.CompletionVerdict) error {
+	reviewers, err := s.reviewers(task.GrindID, event.UserID)
+	if err != nil {
+		return err
+	}
+	if len(reviewers) == 0 {
+		return nil
+	}
+	existing, err := s.disputeRepo.FindByCompletionEventID(event.ID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return nil
+	}
+
+	reason := "the completion verifier could not confirm it"
+	if verdict != nil && strings.TrimSpace(verdict.Reason) != "" {
+		reason = strings.TrimSpace(verdict.Reason)
+	}
+	dispute, err := entities.NewReviewDispute(event, task.GrindID, reason, len(reviewers), s.reviewWindow)
+	if err != nil {
+		return fmt.Errorf("%w: %v", config.ErrInvalidDispute, err)
+	}
+
+	return runInTransaction(s.db, func(tx *gorm.DB) error {
+		msgRepo := getMessageRepo(s.messageRepo, tx)
+
+		day := s.taskDay(task)
+		asked := CompletionReviewRequestedEvent{Subject: displayName(s.userRepo, dispute.SubjectID), Day: day, Reason: reason}
+		for _, userID := range reviewers {
+			if err := s.sendDisputeMessage(msgRepo, dispute.SubjectID, userID, dispute.GrindID, asked); err != nil {
+				return err
+			}
+		}
+		told := CompletionReviewStartedEvent{Day: day, Reason: reason}
+		if err := s.sendDisputeMessage(msgRepo, dispute.SubjectID, dispute.SubjectID, dispute.GrindID, told); err != nil {
+			return err
+		}
+		return getCompletionDisputeRepo(s.disputeRepo, tx).Create(dispute)
+	})
+}
+
+// VoteOnDispute records a reviewer's vote and settles the dispute once a side has reached
+// the quorum. The vote is counted on the dispute as locked in the transaction, so
+// concurrent votes are not lost.
+// Returns ErrDisputeNotFound, ErrUserIsNotParticipant when the caller is not a reviewer,
+// ErrInvalidDispute for the subject, ErrAlreadyVoted and ErrDisputeClosed once the dispute
+// has been settled or its voting window has ended.
+func (s *DisputeService) VoteOnDispute(request dto.VoteOnDisputeDTO) (*dto.CompletionDisputeDTO, error) {
+	if request.Uphold == nil {
+		return nil, fmt.Errorf("%w: uphold is required", config.ErrInvalidDispute)
+	}
+	dispute, err := s.disputeRepo.FindByID(request.DisputeID)
+	if err != nil || dispute == nil {
+		return nil, config.ErrDisputeNotFound
+	}
+	if dispute.SubjectID == request.UserID {
+		return nil, fmt.Errorf("%w: a completion cannot be voted on by its own user", config.ErrInvalidDispute)
+	}
+	reviewers, err := s.reviewers(dispute.GrindID, dispute.SubjectID)
+	if err != nil {
+		return nil, err
+	}
+	if !containsString(reviewers, request.UserID) {
+		return nil, config.ErrUserIsNotParticipant
+	}
+
+	now := s.now()
+	txErr := runInTransaction(s.db, func(tx *gorm.DB) error {
+		disputeRepo := getCompletionDisputeRepo(s.disputeRepo, tx)
+
+		dispute, err = disputeRepo.FindByIDForUpdate(dispute.ID)
+		if err != nil {
+			return config.ErrDisputeNotFound
+		}
+		if dispute.HasVoted(request.UserID) {
+			return config.ErrAlreadyVoted
+		}
+		if err := dispute.Vote(request.UserID, *request.Uphold, now); err != nil {
+			return config.ErrDisputeClosed // an expired dispute is settled by SettleExpiredDisputes
+		}
+		if err := s.settle(tx, dispute, now); err != nil {
+			return err
+		}
+		updated, err := disputeRepo.Update(dispute)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			return config.ErrDisputeClosed
+		}
+		return nil
+	})
+	if txErr != nil {
+		return nil, txErr
+	}
+	return mappers.BuildCompletionDisputeDTO(dispute), nil
+}
+
+// SettleExpiredDisputes settles every open dispute whose voting window has ended without a
+// quorum; the completion stands. A dispute that fails to settle is reported and retried on
+// the next run.
+func (s *DisputeService) SettleExpiredDisputes() (*dto.DisputeSettlementResultDTO, error) {
+	now := s.now()
+	disputes, err := s.disputeRepo.FindExpired(now)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.DisputeSettlementResultDTO{SettledDisputeIDs: []string{}}
+	var errs []error
+	for _, dispute := range disputes {
+		settled, err := s.settleExpired(dispute.ID, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("settling dispute %s: %w", dispute.ID, err))
+			continue
+		}
+		if settled {
+			result.SettledDisputeIDs = append(result.SettledDisputeIDs, dispute.ID)
+		}
+	}
+	return result, errors.Join(errs...)
+}
+
+// Run settles expired disputes immediately and then every few minutes until ctx is
+// cancelled. Errors are reported and the loop keeps going.
+func (s *DisputeService) Run(ctx context.Context) {
+	for {
+		if _, err := s.SettleExpiredDisputes(); err != nil {
+			fmt.Println("dispute settlement failed", err)
+		}
+
+		timer := time.NewTimer(disputeSettlementInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// GetGrindDisputes lists a grind's disputes, newest first, for its participants and partner
+// group members. Disputes whose voting window has ended stay open until
+// SettleExpiredDisputes settles them.
+func (s *DisputeService) GetGrindDisputes(request dto.GetGrindDisputesDTO) ([]*dto.CompletionDisputeDTO, error) {
+	partners, err := s.reviewers(request.GrindID, "")
+	if err != nil {
+		return nil, err
+	}
+	if !containsString(partners, request.UserID) {
+		return nil, config.ErrUserIsNotParticipant
+	}
+
+	disputes, err := s.disputeRepo.FindByGrindID(request.GrindID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*dto.CompletionDisputeDTO, 0, len(disputes))
+	for _, dispute := range disputes {
+		result = append(result, mappers.BuildCompletionDisputeDTO(dispute))
+	}
+	return result, nil
+}
+
+// settleExpired persists the outcome of an open dispute whose voting window has ended and
+// reports whether it did. A dispute that a late vote settled in the meantime is left alone.
+func (s *DisputeService) settleExpired(disputeID string, now time.Time) (bool, error) {
+	settled := false
+	err := runInTransaction(s.db, func(tx *gorm.DB) error {
+		disputeRepo := getCompletionDisputeRepo(s.disputeRepo, tx)
+
+		dispute, err := disputeRepo.FindByIDForUpdate(disputeID)
+		if err != nil {
+			return err
+		}
+		if dispute.Status != entities.DisputeOpen || dispute.Outcome(now) == entities.DisputeOpen {
+			return nil
+		}
+		if err := s.settle(tx, dispute, now); err != nil {
+			return err
+		}
+		settled, err = disputeRepo.Update(dispute)
+		return err
+	})
+	return settled, err
+}
+
+// settle closes the dispute once its outcome is decided. A reverted completion's task is
+// flagged as missed and the subject's participation accrues the missed day; the subject and
+// the flagger, if a partner opened the dispute, are then told of the outcome. The caller
+// persists the dispute.
+func (s *DisputeService) settle(tx *gorm.DB, dispute *entities.CompletionDispute, now time.Time) error {
+	outcome := dispute.Outcome(now)
+	if outcome == entities.DisputeOpen {
+		return nil
+	}
+	if outcome == entities.DisputeReverted {
+		if err := s.revert(tx, dispute); err != nil {
+			return err
+		}
+	}
+	dispute.Resolve(outcome, now)
+
+	msgRepo := getMessageRepo(s.messageRepo, tx)
+	subject := displayName(s.userRepo, dispute.SubjectID)
+	var event MessageEvent = CompletionDisputeUpheldEvent{Subject: subject}
+	if outcome == entities.DisputeReverted {
+		event = CompletionDisputeRevertedEvent{Subject: subject}
+	}
+	if dispute.OpenedByVerifier() {
+		return s.sendDisputeMessage(msgRepo, dispute.SubjectID, dispute.SubjectID, dispute.GrindID, event)
+	}
+	if err := s.sendDisputeMessage(msgRepo, dispute.FlaggedBy, dispute.SubjectID, dispute.GrindID, event); err != nil {
+		return err
+	}
+	return s.sendDisputeMessage(msgRepo, dispute.SubjectID, dispute.FlaggedBy, dispute.GrindID, event)
+}
+
+// revert turns the disputed task back into a missed day. A task that is no longer
+// completed has nothing to revert and costs nothing. In a weekly_target grind the task is
+// only a missed day when its week can no longer meet the target without it; otherwise it
+// is excused and the week is priced when it closes.
+func (s *DisputeService) revert(tx *gorm.DB, dispute *entities.CompletionDispute) error {
+	partRepo := getParticipationRepo(s.participationRepo, tx)
+	habitTaskRepo := getHabitTaskRepo(s.habitTaskRepo, tx)
+
+	participation, err := partRepo.FindByUserAndGrindForUpdate(dispute.SubjectID, dispute.GrindID)
+	if err != nil {
+		return err
+	}
+	if participation != nil && participation.Finalized {
+		return config.ErrParticipationFinalized
+	}
+	grind, err := getGrindRepo(s.grindRepo, tx).FindById(dispute.GrindID)
+	if err != nil {
+		return config.ErrGrindNotFound
+	}
+	excuse := false
+	if grind.Schedule.Kind == entities.ScheduleKindWeeklyTarget {
+		if excuse, err = s.weekCoversRevert(habitTaskRepo, grind, dispute); err != nil {
+			return err
+		}
+	}
+	reverted, err := habitTaskRepo.RevertCompletion(dispute.HabitTaskID, excuse)
+	if err != nil {
+		return err
+	}
+	if !reverted || excuse || participation == nil || participation.Quitted {
+		return nil
+	}
+
+	s.penaltyPolicy.RecordMissedDay(grind, participation)
+	if err := partRepo.Update(participation); err != nil {
+		return fmt.Errorf("%w: %v", config.ErrParticipationUpdateFailed, err)
+	}
+	return nil
+}
+
+// userLocation is the user's timezone, or UTC when they cannot be found.
+func (s *DisputeService) userLocation(userID string) *time.Location {
+	if user, err := s.userRepo.FindById(userID); err == nil {
+		return user.Location()
+	}
+	return time.UTC
+}
+
+// taskDay is the task's day as its owner sees it, for messages about the task.
+func (s *DisputeService) taskDay(task *entities.HabitTask) CalendarDate {
+	return calendarDate(task.Date, s.userLocation(task.UserID))
+}
+
+// weekCoversRevert reports whether the weekly_target week of the disputed task can still
+// account for its target once the task's completion is reverted. Every missed task of a
+// week has been charged and the week's open tasks are charged for any shortfall when it
+// closes, so the revert only costs a day when those together fall short of the target.
+func (s *DisputeService) weekCoversRevert(habitTaskRepo repositories.HabitTaskRepository, grind *entities.Grind, dispute *entities.CompletionDispute) (bool, error) {
+	tasks, err := habitTaskRepo.FindByGrindIDAndUserID(grind.ID, dispute.SubjectID)
+	if err != nil {
+		return false, err
+	}
+	var disputed *entities.HabitTask
+	for _, task := range tasks {
+		if task.ID == dispute.HabitTaskID {
+			disputed = task
+		}
+	}
+	if disputed == nil || !disputed.Completed {
+		return false, nil
+	}
+
+	loc := s.userLocation(dispute.SubjectID)
+	first, last, target := grind.WeekWindow(grind.DayIndex(disputed.Date, loc))
+	completed, charged, open := 0, 0, 0
+	for _, task := range tasks {
+		if i := grind.DayIndex(task.Date, loc); task.ID == disputed.ID || i < first || i > last {
+			continue
+		}
+		switch {
+		case task.Completed:
+			completed++
+		case task.Missed:
+			charged++
+		case !task.Excused:
+			open++
+		}
+	}
+	return charged+open >= target-completed, nil
+}
+
+// reviewers returns the grind's active participants and the members of its partner group,
+// without excludeID.
+func (s *DisputeService) reviewers(grindID, excludeID string) ([]string, error) {
+	return grindPartnerIDs(s.participationRepo, s.partnerGroupRepo, grindID, excludeID)
+}
+
+// grindPartnerIDs returns the grind's active participants and the members of its partner
+// group, without excludeID.
+func grindPartnerIDs(
+	participationRepo repositories.ParticipationRepository,
+	partnerGroupRepo repositories.PartnerGroupRepository,
+	grindID, excludeID string,
+) ([]string, error) {
+	participations, err := participationRepo.FindByGrindID(grindID)
+	if err != nil {
+		return nil, err
+	}
+	candidates := activeParticipantIDs(participations)
+
+	group, err := partnerGroupRepo.FindByGrindID(grindID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if group != nil {
+		candidates = append(candidates, group.Members...)
+	}
+
+	partners := make([]string, 0, len(candidates))
+	for _, id := range candidates {
+		if id != excludeID && !containsString(partners, id) {
+			partners = append(partners, id)
+		}
+	}
+	return partners, nil
+}
+
+// sendDisputeMessage tells receiverID about event in their locale.
+func (s *DisputeService) sendDisputeMessage(msgRepo repositories.MessageRepository, senderID, receiverID, grindID string, event MessageEvent) error {
+	content, err := renderMessageFor(s.userRepo, receiverID, event)
+	if err != nil {
+		return err
+	}
+	msg, err := entities.NewMessage(senderID, receiverID, content, config.MESSAGE_TYPE_COMPLETION_DISPUTE, grindID, false, false)
+	if err != nil {
+		return err
+	}
+	return msgRepo.Create(msg)
+}
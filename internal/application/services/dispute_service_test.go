@@ -0,0 +1,429 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// expectDisputeGrind wires a grind "grind-1" whose participants are participantIDs and
+// whose partner group, if any, has groupMembers.
+func expectDisputeGrind(repos testRepos, participantIDs, groupMembers []string) {
+	participations := make([]*entities.Participation, 0, len(participantIDs))
+	for _, id := range participantIDs {
+		participations = append(participations, &entities.Participation{ID: "part-" + id, UserID: id, GrindID: "grind-1"})
+	}
+	repos.participation.On("FindByGrindID", "grind-1").Return(participations, nil)
+	if groupMembers == nil {
+		repos.partnerGroup.On("FindByGrindID", "grind-1").Return(nil, gorm.ErrRecordNotFound)
+	} else {
+		repos.partnerGroup.On("FindByGrindID", "grind-1").Return(&entities.PartnerGroup{ID: "group-1", GrindID: "grind-1", Members: groupMembers}, nil)
+	}
+	repos.message.On("Create", mock.Anything).Return(nil).Maybe()
+	repos.user.On("FindById", mock.Anything).Return(nil, gorm.ErrRecordNotFound).Maybe()
+}
+
+// expectDisputedCompletion registers user-1's completed task-1 and its event-1.
+func expectDisputedCompletion(repos testRepos, occurredAt time.Time) {
+	repos.completionEvent.On("FindByID", "event-1").Return(&entities.CompletionEvent{
+		ID: "event-1", HabitTaskID: "task-1", UserID: "user-1", Provider: entities.ProviderDuolingo, OccurredAt: occurredAt,
+	}, nil)
+	repos.habitTask.On("FindByID", "task-1").Return(&entities.HabitTask{
+		ID: "task-1", UserID: "user-1", GrindID: "grind-1", Date: occurredAt.Truncate(24 * time.Hour), Completed: true,
+	}, nil)
+	repos.dispute.On("FindByCompletionEventID", "event-1").Return(nil, nil)
+}
+
+func Test_DisputeService_FlagCompletion_OpensDispute(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	repos := newTestRepos()
+	expectDisputeGrind(repos, []string{"user-1", "user-2", "user-3"}, []string{"user-1", "user-4"})
+	svc := NewDisputeService(nil, repos.grind, repos.user, repos.habitTask, repos.participation, repos.partnerGroup,
+		repos.completionEvent, repos.dispute, repos.message)
+	svc.now = func() time.Time { return now }
+
+	expectDisputedCompletion(repos, now.Add(-time.Hour))
+	repos.participation.On("FindByUserAndGrind", "user-1", "grind-1").Return(&entities.Participation{UserID: "user-1", GrindID: "grind-1"}, nil)
+	repos.dispute.On("Create", mock.MatchedBy(func(d *entities.CompletionDispute) bool {
+		return d.Status == entities.DisputeOpen && d.Quorum == 2 && d.FlaggedBy == "user-2"
+	})).Return(nil)
+
+	dispute, err := svc.FlagCompletion(dto.FlagCompletionDTO{UserID: "user-2", CompletionEventID: "event-1", Reason: " no screenshot "})
+	require.NoError(t, err)
+	assert.Equal(t, "open", dispute.Status)
+	assert.Equal(t, "no screenshot", dispute.Reason)
+	assert.Equal(t, []string{"user-2"}, dispute.RevertVotes)
+	// the subject and the other reviewers, partner group members included, are asked
+	for _, receiverID := range []string{"user-1", "user-3", "user-4"} {
+		repos.message.AssertCalled(t, "Create", mock.MatchedBy(func(m *entities.Message) bool {
+			return m.ReceiverID == receiverID && m.Type == config.MESSAGE_TYPE_COMPLETION_DISPUTE && m.InvitationGrindID == "grind-1"
+		}))
+	}
+	repos.message.AssertNumberOfCalls(t, "Create", 3)
+	repos.habitTask.AssertNotCalled(t, "RevertCompletion", mock.Anything, mock.Anything)
+}
+
+func Test_DisputeService_FlagCompletion_DatesTheTaskInTheSubjectsZone(t *testing.T) {
+	t.Parallel()
+
+	// 10:00 on 2026-10-17 in Taipei (UTC+8), where the task's day began at 16:00 UTC the
+	// day before
+	now := time.Date(2026, 10, 17, 2, 0, 0, 0, time.UTC)
+	repos := newTestRepos()
+	repos.user.On("FindById", "user-1").Return(&entities.User{ID: "user-1", Username: "bob", Timezone: "Asia/Taipei"}, nil)
+	expectDisputeGrind(repos, []string{"user-1", "user-2", "user-3"}, nil)
+	svc := NewDisputeService(nil, repos.grind, repos.user, repos.habitTask, repos.participation, repos.partnerGroup,
+		repos.completionEvent, repos.dispute, repos.message)
+	svc.now = func() time.Time { return now }
+
+	repos.completionEvent.On("FindByID", "event-1").Return(&entities.CompletionEvent{
+		ID: "event-1", HabitTaskID: "task-1", UserID: "user-1", Provider: entities.ProviderDuolingo, OccurredAt: now.Add(-time.Hour),
+	}, nil)
+	repos.habitTask.On("FindByID", "task-1").Return(&entities.HabitTask{
+		ID: "task-1", UserID: "user-1", GrindID: "grind-1", Date: time.Date(2026, 10, 16, 16, 0, 0, 0, time.UTC), Completed: true,
+	}, nil)
+	repos.dispute.On("FindByCompletionEventID", "event-1").Return(nil, nil)
+	repos.participation.On("FindByUserAndGrind", "user-1", "grind-1").Return(&entities.Participation{UserID: "user-1", GrindID: "grind-1"}, nil)
+	repos.dispute.On("Create", mock.Anything).Return(nil)
+
+	_, err := svc.FlagCompletion(dto.FlagCompletionDTO{UserID: "user-2", CompletionEventID: "event-1"})
+	require.NoError(t, err)
+	repos.message.AssertCalled(t, "Create", mock.MatchedBy(func(m *entities.Message) bool {
+		return m.ReceiverID == "user-3" && m.Content == "user-2 disputed bob's completion of 2026-10-17"
+	}))
+}
+
+func Test_DisputeService_FlagCompletion_SoleReviewerReverts(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	repos := newTestRepos()
+	expectDisputeGrind(repos, []string{"user-1", "user-2"}, nil)
+	svc := NewDisputeService(nil, repos.grind, repos.user, repos.habitTask, repos.participation, repos.partnerGroup,
+		repos.completionEvent, repos.dispute, repos.message)
+	svc.now = func() time.Time { return now }
+
+	expectDisputedCompletion(repos, now.Add(-time.Hour))
+	participation := &entities.Participation{ID: "part-user-1", UserID: "user-1", GrindID: "grind-1"}
+	repos.participation.On("FindByUserAndGrind", "user-1", "grind-1").Return(participation, nil)
+	repos.participation.On("FindByUserAndGrindForUpdate", "user-1", "grind-1").Return(participation, nil)
+	repos.participation.On("Update", participation).Return(nil)
+	repos.habitTask.On("RevertCompletion", "task-1", false).Return(true, nil)
+	repos.grind.On("FindById", "grind-1").Return(&entities.Grind{ID: "grind-1", Budget: 100, PenaltyPolicy: entities.PenaltyPolicy{
+		Kind: entities.PenaltyKindFlat, Amount: 5, CapAtBudget: true,
+	}}, nil)
+	repos.dispute.On("Create", mock.Anything).Return(nil)
+
+	dispute, err := svc.FlagCompletion(dto.FlagCompletionDTO{UserID: "user-2", CompletionEventID: "event-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "reverted", dispute.Status)
+	assert.Equal(t, 1, participation.MissedDays)
+	assert.Equal(t, 5, participation.TotalPenalty)
+	// both parties hear about the outcome
+	repos.message.AssertCalled(t, "Create", mock.MatchedBy(func(m *entities.Message) bool {
+		return m.ReceiverID == "user-2" && m.SenderID == "user-1"
+	}))
+}
+
+func Test_DisputeService_FlagCompletion_WeeklyTargetRevert(t *testing.T) {
+	t.Parallel()
+
+	// Wednesday 2026-04-01 start; the first ISO week runs Wednesday to Sunday and needs 3
+	start := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	now := start.Add(36 * time.Hour)
+	week := func(rest ...entities.HabitTask) []*entities.HabitTask {
+		tasks := []*entities.HabitTask{{ID: "task-1", UserID: "user-1", GrindID: "grind-1", Date: start, Completed: true}}
+		for i := range rest {
+			rest[i].UserID, rest[i].GrindID, rest[i].Date = "user-1", "grind-1", start.AddDate(0, 0, i+1)
+			tasks = append(tasks, &rest[i])
+		}
+		return tasks
+	}
+
+	cases := []struct {
+		name   string
+		tasks  []*entities.HabitTask
+		excuse bool
+	}{
+		{"target still met", week(
+			entities.HabitTask{ID: "thu", Completed: true}, entities.HabitTask{ID: "fri", Completed: true},
+			entities.HabitTask{ID: "sat", Completed: true}, entities.HabitTask{ID: "sun"},
+		), true},
+		{"open days can still cover it", week(
+			entities.HabitTask{ID: "thu", Completed: true}, entities.HabitTask{ID: "fri", Completed: true},
+			entities.HabitTask{ID: "sat"}, entities.HabitTask{ID: "sun"},
+		), true},
+		{"closed week falls short", week(
+			entities.HabitTask{ID: "thu", Completed: true}, entities.HabitTask{ID: "fri", Completed: true},
+			entities.HabitTask{ID: "sat", Excused: true}, entities.HabitTask{ID: "sun", Excused: true},
+		), false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repos := newTestRepos()
+			expectDisputeGrind(repos, []string{"user-1", "user-2"}, nil)
+			svc := NewDisputeService(nil, repos.grind, repos.user, repos.habitTask, repos.participation, repos.partnerGroup,
+				repos.completionEvent, repos.dispute, repos.message)
+			svc.now = func() time.Time { return now }
+
+			expectDisputedCompletion(repos, start.Add(11*time.Hour))
+			participation := &entities.Participation{ID: "part-user-1", UserID: "user-1", GrindID: "grind-1"}
+			repos.participation.On("FindByUserAndGrind", "user-1", "grind-1").Return(participation, nil)
+			repos.participation.On("FindByUserAndGrindForUpdate", "user-1", "grind-1").Return(participation, nil)
+			repos.participation.On("Update", participation).Return(nil).Maybe()
+			repos.grind.On("FindById", "grind-1").Return(&entities.Grind{
+				ID: "grind-1", Duration: 14, Budget: 80, StartDate: start,
+				PenaltyPolicy: entities.PenaltyPolicy{Kind: entities.PenaltyKindFlat, Amount: 5, CapAtBudget: true},
+				Schedule:      entities.Schedule{Kind: entities.ScheduleKindWeeklyTarget, TimesPerWeek: 3},
+			}, nil)
+			repos.habitTask.On("FindByGrindIDAndUserID", "grind-1", "user-1").Return(tc.tasks, nil)
+			repos.habitTask.On("RevertCompletion", "task-1", tc.excuse).Return(true, nil)
+			repos.dispute.On("Create", mock.Anything).Return(nil)
+
+			dispute, err := svc.FlagCompletion(dto.FlagCompletionDTO{UserID: "user-2", CompletionEventID: "event-1"})
+			require.NoError(t, err)
+			assert.Equal(t, "reverted", dispute.Status)
+			repos.habitTask.AssertCalled(t, "RevertCompletion", "task-1", tc.excuse)
+			if tc.excuse {
+				// the week is priced when it closes
+				assert.Equal(t, 0, participation.MissedDays)
+				repos.participation.AssertNotCalled(t, "Update", mock.Anything)
+			} else {
+				assert.Equal(t, 1, participation.MissedDays)
+				assert.Equal(t, 5, participation.TotalPenalty)
+			}
+		})
+	}
+}
+
+func Test_DisputeService_FlagCompletion_Rejected(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	cases := []struct {
+		name       string
+		userID     string
+		occurredAt time.Time
+		want       error
+	}{
+		{"own completion", "user-1", now.Add(-time.Hour), config.ErrInvalidDispute},
+		{"outsider", "user-9", now.Add(-time.Hour), config.ErrUserIsNotParticipant},
+		{"review window closed", "user-2", now.Add(-config.DefaultCompletionReviewWindow - time.Minute), config.ErrReviewWindowClosed},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repos := newTestRepos()
+			expectDisputeGrind(repos, []string{"user-1", "user-2"}, nil)
+			svc := NewDisputeService(nil, repos.grind, repos.user, repos.habitTask, repos.participation, repos.partnerGroup,
+				repos.completionEvent, repos.dispute, repos.message)
+			svc.now = func() time.Time { return now }
+
+			expectDisputedCompletion(repos, tc.occurredAt)
+
+			_, err := svc.FlagCompletion(dto.FlagCompletionDTO{UserID: tc.userID, CompletionEventID: "event-1"})
+			assert.True(t, errors.Is(err, tc.want), "got %v", err)
+			repos.dispute.AssertNotCalled(t, "Create", mock.Anything)
+		})
+	}
+}
+
+func Test_DisputeService_VoteOnDispute_QuorumUpholds(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	repos := newTestRepos()
+	expectDisputeGrind(repos, []string{"user-1", "user-2", "user-3", "user-4"}, nil)
+	svc := NewDisputeService(nil, repos.grind, repos.user, repos.habitTask, repos.participation, repos.partnerGroup,
+		repos.completionEvent, repos.dispute, repos.message)
+	svc.now = func() time.Time { return now }
+
+	dispute, err := entities.NewCompletionDispute(&entities.CompletionEvent{ID: "event-1", HabitTaskID: "task-1", UserID: "user-1"},
+		"grind-1", "user-2", "", 3, time.Hour)
+	require.NoError(t, err)
+	repos.dispute.On("FindByID", dispute.ID).Return(dispute, nil)
+	repos.dispute.On("FindByIDForUpdate", dispute.ID).Return(dispute, nil)
+	repos.dispute.On("Update", dispute).Return(true, nil)
+	uphold := true
+
+	result, err := svc.VoteOnDispute(dto.VoteOnDisputeDTO{UserID: "user-3", DisputeID: dispute.ID, Uphold: &uphold})
+	require.NoError(t, err)
+	assert.Equal(t, "open", result.Status)
+
+	_, err = svc.VoteOnDispute(dto.VoteOnDisputeDTO{UserID: "user-3", DisputeID: dispute.ID, Uphold: &uphold})
+	assert.True(t, errors.Is(err, config.ErrAlreadyVoted))
+	_, err = svc.VoteOnDispute(dto.VoteOnDisputeDTO{UserID: "user-1", DisputeID: dispute.ID, Uphold: &uphold})
+	assert.True(t, errors.Is(err, config.ErrInvalidDispute), "the subject does not vote")
+
+	result, err = svc.VoteOnDispute(dto.VoteOnDisputeDTO{UserID: "user-4", DisputeID: dispute.ID, Uphold: &uphold})
+	require.NoError(t, err)
+	assert.Equal(t, "upheld", result.Status)
+	repos.habitTask.AssertNotCalled(t, "RevertCompletion", mock.Anything, mock.Anything)
+	repos.message.AssertNumberOfCalls(t, "Create", 2)
+}
+
+func Test_DisputeService_VoteOnDispute_KeepsConcurrentVotes(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	repos := newTestRepos()
+	expectDisputeGrind(repos, []string{"user-1", "user-2", "user-3", "user-4"}, nil)
+	svc := NewDisputeService(nil, repos.grind, repos.user, repos.habitTask, repos.participation, repos.partnerGroup,
+		repos.completionEvent, repos.dispute, repos.message)
+	svc.now = func() time.Time { return now }
+
+	// user-4 read the dispute before user-3's vote was committed
+	read, err := entities.NewCompletionDispute(&entities.CompletionEvent{ID: "event-1", HabitTaskID: "task-1", UserID: "user-1"},
+		"grind-1", "user-2", "", 3, time.Hour)
+	require.NoError(t, err)
+	locked := *read
+	require.NoError(t, locked.Vote("user-3", true, now))
+	repos.dispute.On("FindByID", read.ID).Return(read, nil)
+	repos.dispute.On("FindByIDForUpdate", read.ID).Return(&locked, nil)
+	repos.dispute.On("Update", &locked).Return(true, nil)
+	uphold := true
+
+	result, err := svc.VoteOnDispute(dto.VoteOnDisputeDTO{UserID: "user-4", DisputeID: read.ID, Uphold: &uphold})
+	require.NoError(t, err)
+	assert.Equal(t, "upheld", result.Status)
+	assert.Equal(t, []string{"user-3", "user-4"}, result.UpholdVotes)
+}
+
+func Test_DisputeService_VoteOnDispute_ExpiredDisputeIsClosed(t *testing.T) {
+	t.Parallel()
+
+	dispute, err := entities.NewCompletionDispute(&entities.CompletionEvent{ID: "event-1", HabitTaskID: "task-1", UserID: "user-1"},
+		"grind-1", "user-2", "", 3, time.Hour)
+	require.NoError(t, err)
+	now := dispute.ExpiresAt.Add(time.Minute)
+	repos := newTestRepos()
+	expectDisputeGrind(repos, []string{"user-1", "user-2", "user-3", "user-4"}, nil)
+	svc := NewDisputeService(nil, repos.grind, repos.user, repos.habitTask, repos.participation, repos.partnerGroup,
+		repos.completionEvent, repos.dispute, repos.message)
+	svc.now = func() time.Time { return now }
+
+	repos.dispute.On("FindByID", dispute.ID).Return(dispute, nil)
+	repos.dispute.On("FindByIDForUpdate", dispute.ID).Return(dispute, nil)
+	revert := false
+
+	_, err = svc.VoteOnDispute(dto.VoteOnDisputeDTO{UserID: "user-3", DisputeID: dispute.ID, Uphold: &revert})
+	assert.True(t, errors.Is(err, config.ErrDisputeClosed))
+	// the scheduled job settles it
+	assert.Equal(t, entities.DisputeOpen, dispute.Status)
+	repos.dispute.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func Test_DisputeService_SettleExpiredDisputes(t *testing.T) {
+	t.Parallel()
+
+	expired, err := entities.NewCompletionDispute(&entities.CompletionEvent{ID: "event-1", HabitTaskID: "task-1", UserID: "user-1"},
+		"grind-1", "user-2", "", 3, time.Hour)
+	require.NoError(t, err)
+	// a late vote reverted this one between the listing and the lock
+	raced, err := entities.NewCompletionDispute(&entities.CompletionEvent{ID: "event-2", HabitTaskID: "task-2", UserID: "user-1"},
+		"grind-1", "user-2", "", 3, time.Hour)
+	require.NoError(t, err)
+	settledByVote := *raced
+	settledByVote.Resolve(entities.DisputeReverted, raced.CreatedAt)
+
+	now := expired.ExpiresAt.Add(time.Minute)
+	repos := newTestRepos()
+	expectDisputeGrind(repos, []string{"user-1", "user-2", "user-3", "user-4"}, nil)
+	svc := NewDisputeService(nil, repos.grind, repos.user, repos.habitTask, repos.participation, repos.partnerGroup,
+		repos.completionEvent, repos.dispute, repos.message)
+	svc.now = func() time.Time { return now }
+
+	repos.dispute.On("FindExpired", now).Return([]*entities.CompletionDispute{expired, raced}, nil)
+	repos.dispute.On("FindByIDForUpdate", expired.ID).Return(expired, nil)
+	repos.dispute.On("FindByIDForUpdate", raced.ID).Return(&settledByVote, nil)
+	repos.dispute.On("Update", expired).Return(true, nil)
+
+	result, err := svc.SettleExpiredDisputes()
+	require.NoError(t, err)
+	assert.Equal(t, []string{expired.ID}, result.SettledDisputeIDs)
+	assert.Equal(t, entities.DisputeUpheld, expired.Status)
+	repos.dispute.AssertNotCalled(t, "Update", &settledByVote)
+	repos.habitTask.AssertNotCalled(t, "RevertCompletion", mock.Anything, mock.Anything)
+}
+
+func Test_DisputeService_RequestReview_OpensDisputeWithoutVotes(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	repos := newTestRepos()
+	expectDisputeGrind(repos, []string{"user-1", "user-2", "user-3"}, nil)
+	svc := NewDisputeService(nil, repos.grind, repos.user, repos.habitTask, repos.participation, repos.partnerGroup,
+		repos.completionEvent, repos.dispute, repos.message)
+	svc.now = func() time.Time { return now }
+
+	event := &entities.CompletionEvent{ID: "event-1", HabitTaskID: "task-1", UserID: "user-1", OccurredAt: now}
+	task := &entities.HabitTask{ID: "task-1", UserID: "user-1", GrindID: "grind-1", Date: now.Truncate(24 * time.Hour), Completed: true}
+	repos.dispute.On("FindByCompletionEventID", "event-1").Return(nil, nil)
+	repos.dispute.On("Create", mock.MatchedBy(func(d *entities.CompletionDispute) bool {
+		return d.OpenedByVerifier() && d.Quorum == 2 && len(d.RevertVotes) == 0 && d.Reason == "the screenshot shows another app"
+	})).Return(nil)
+
+	err := svc.RequestReview(event, task, &entities.CompletionVerdict{Plausible: false, Confidence: 0.8, Reason: "the screenshot shows another app"})
+	require.NoError(t, err)
+	// the reviewers are asked, and the subject told, on the subject's behalf
+	for _, receiverID := range []string{"user-1", "user-2", "user-3"} {
+		repos.message.AssertCalled(t, "Create", mock.MatchedBy(func(m *entities.Message) bool {
+			return m.ReceiverID == receiverID && m.SenderID == "user-1" && m.Type == config.MESSAGE_TYPE_COMPLETION_DISPUTE
+		}))
+	}
+	repos.dispute.AssertExpectations(t)
+}
+
+func Test_DisputeService_RequestReview_SoloGrindIsLeftAlone(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	repos := newTestRepos()
+	expectDisputeGrind(repos, []string{"user-1"}, nil)
+	svc := NewDisputeService(nil, repos.grind, repos.user, repos.habitTask, repos.participation, repos.partnerGroup,
+		repos.completionEvent, repos.dispute, repos.message)
+	svc.now = func() time.Time { return now }
+
+	event := &entities.CompletionEvent{ID: "event-1", HabitTaskID: "task-1", UserID: "user-1", OccurredAt: now}
+	task := &entities.HabitTask{ID: "task-1", UserID: "user-1", GrindID: "grind-1", Date: now.Truncate(24 * time.Hour)}
+
+	require.NoError(t, svc.RequestReview(event, task, &entities.CompletionVerdict{Confidence: 0.1}))
+	repos.dispute.AssertNotCalled(t, "Create", mock.Anything)
+	repos.message.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func Test_DisputeService_VoteOnDispute_VerifierDisputeTellsSubject(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	repos := newTestRepos()
+	expectDisputeGrind(repos, []string{"user-1", "user-2"}, nil)
+	svc := NewDisputeService(nil, repos.grind, repos.user, repos.habitTask, repos.participation, repos.partnerGroup,
+		repos.completionEvent, repos.dispute, repos.message)
+	svc.now = func() time.Time { return now }
+
+	dispute, err := entities.NewReviewDispute(&entities.CompletionEvent{ID: "event-1", HabitTaskID: "task-1", UserID: "user-1"},
+		"grind-1", "blurry screenshot", 1, time.Hour)
+	require.NoError(t, err)
+	repos.dispute.On("FindByID", dispute.ID).Return(dispute, nil)
+	repos.dispute.On("FindByIDForUpdate", dispute.ID).Return(dispute, nil)
+	repos.dispute.On("Update", dispute).Return(true, nil)
+
+	uphold := true
+	result, err := svc.VoteOnDispute(dto.VoteOnDisputeDTO{UserID: "user-2", DisputeID: dispute.ID, Uphold: &uphold})
+	require.NoError(t, err)
+	assert.Equal(t, "upheld", result.Status)
+	repos.message.AssertCalled(t, "Create", mock.MatchedBy(func(m *entities.Message) bool {
+		return m.ReceiverID == "user-1" && m.SenderID == "user-1"
+	}))
+	repos.message.AssertNumberOfCalls(t, "Create", 1)
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/repositories"
+	"github.com/google/uuid"
+)
+
+// MaxEvidenceSize is the largest evidence file accepted, in bytes.
+const MaxEvidenceSize = 5 << 20
+
+// evidenceContentTypes are the accepted kinds of proof: screenshots, photos and PDFs.
+var evidenceContentTypes = map[string]bool{
+	"image/png":       true,
+	"image/jpeg":      true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+}
+
+// BlobStore keeps binary content by key.
+type BlobStore interface {
+	Put(key string, content io.Reader) error
+	// Open returns the content stored under key; the caller closes it.
+	Open(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+// EvidenceService stores the files users attach to completions as proof and serves them to
+// the completion's owner and to everyone who may review it.
+type EvidenceService struct {
+	blobStore           BlobStore
+	completionEventRepo repositories.CompletionEventRepository
+	habitTaskRepo       repositories.HabitTaskRepository
+	participationRepo   repositories.ParticipationRepository
+	partnerGroupRepo    repositories.PartnerGroupRepository
+}
+
+func NewEvidenceService(
+	blobStore BlobStore,
+	completionEventRepo repositories.CompletionEventRepository,
+	habitTaskRepo repositories.HabitTaskRepository,
+	participationRepo repositories.ParticipationRepository,
+	partnerGroupRepo repositories.PartnerGroupRepository,
+) *EvidenceService {
+	return &EvidenceService{
+		blobStore:           blobStore,
+		completionEventRepo: completionEventRepo,
+		habitTaskRepo:       habitTaskRepo,
+		participationRepo:   participationRepo,
+		partnerGroupRepo:    partnerGroupRepo,
+	}
+}
+
+// StoreEvidence validates an upload and puts it in the blob store. The content type is
+// sniffed from the content and must be an image or a PDF; a declared type that disagrees
+// is rejected. The returned attachment is meant for Ingest; when the completion is not
+// recorded the caller discards it with DiscardEvidence.
+// Returns ErrInvalidEvidence for an empty file, ErrEvidenceTooLarge above MaxEvidenceSize
+// and ErrUnsupportedEvidenceType for other content.
+func (s *EvidenceService) StoreEvidence(upload dto.EvidenceUploadDTO) (*entities.EvidenceAttachment, error) {
+	if len(upload.Content) == 0 {
+		return nil, fmt.Errorf("%w: the file is empty", config.ErrInvalidEvidence)
+	}
+	if len(upload.Content) > MaxEvidenceSize {
+		return nil, fmt.Errorf("%w: %d bytes, at most %d", config.ErrEvidenceTooLarge, len(upload.Content), MaxEvidenceSize)
+	}
+
+	contentType := mediaType(http.DetectContentType(upload.Content))
+	if !evidenceContentTypes[contentType] {
+		return nil, fmt.Errorf("%w: %s", config.ErrUnsupportedEvidenceType, contentType)
+	}
+	if declared := mediaType(upload.ContentType); declared != "" && declared != "application/octet-stream" && declared != contentType {
+		return nil, fmt.Errorf("%w: declared %s but the content is %s", config.ErrUnsupportedEvidenceType, declared, contentType)
+	}
+
+	sum := sha256.Sum256(upload.Content)
+	attachment := &entities.EvidenceAttachment{
+		Key:         uuid.New().String(),
+		FileName:    evidenceFileName(upload.FileName),
+		ContentType: contentType,
+		Size:        int64(len(upload.Content)),
+		SHA256:      hex.EncodeToString(sum[:]),
+	}
+	if err := s.blobStore.Put(attachment.Key, bytes.NewReader(upload.Content)); err != nil {
+		return nil, fmt.Errorf("failed to store evidence: %w", err)
+	}
+	return attachment, nil
+}
+
+// DiscardEvidence removes a stored attachment that no completion event refers to.
+func (s *EvidenceService) DiscardEvidence(attachment *entities.EvidenceAttachment) {
+	if attachment == nil {
+		return
+	}
+	if err := s.blobStore.Delete(attachment.Key); err != nil {
+		fmt.Println("failed to discard evidence", attachment.Key, err)
+	}
+}
+
+// OpenEvidence returns the evidence of a completion event to its owner and to the users who
+// review the event's disputes: the grind's active participants and the members of its
+// partner group. The caller closes the reader.
+// Returns ErrCompletionEventNotFound, ErrEvidenceNotFound when the event has no evidence and
+// ErrUserIsNotParticipant when the caller is none of them.
+func (s *EvidenceService) OpenEvidence(request dto.GetEvidenceDTO) (io.ReadCloser, *entities.EvidenceAttachment, error) {
+	event, err := s.completionEventRepo.FindByID(request.CompletionEventID)
+	if err != nil || event == nil {
+		return nil, nil, config.ErrCompletionEventNotFound
+	}
+	if event.Evidence == nil {
+		return nil, nil, config.ErrEvidenceNotFound
+	}
+
+	task, err := s.habitTaskRepo.FindByID(event.HabitTaskID)
+	if err != nil || task == nil {
+		return nil, nil, config.ErrCompletionEventNotFound
+	}
+	if request.UserID != task.UserID {
+		partners, err := grindPartnerIDs(s.participationRepo, s.partnerGroupRepo, task.GrindID, task.UserID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !containsString(partners, request.UserID) {
+			return nil, nil, config.ErrUserIsNotParticipant
+		}
+	}
+
+	content, err := s.blobStore.Open(event.Evidence.Key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open evidence: %w", err)
+	}
+	return content, event.Evidence, nil
+}
+
+// mediaType drops the parameters of a content type, e.g. "; charset=utf-8".
+func mediaType(contentType string) string {
+	parsed, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(contentType))
+	}
+	return parsed
+}
+
+// evidenceFileName keeps the base name of an uploaded file for display and downloads.
+func evidenceFileName(name string) string {
+	name = strings.TrimSpace(filepath.Base(strings.ReplaceAll(name, "\\", "/")))
+	if name == "" || name == "." || name == "/" {
+		return "evidence"
+	}
+	if len(name) > 255 {
+		name = name[len(name)-255:]
+	}
+	return name
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// memoryBlobStore is an in-memory BlobStore.
+type memoryBlobStore struct {
+	mu    sync.Mutex
+	blobs map[string][]byte
+}
+
+func newMemoryBlobStore() *memoryBlobStore {
+	return &memoryBlobStore{blobs: make(map[string][]byte)}
+}
+
+func (s *memoryBlobStore) Put(key string, content io.Reader) error {
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blobs[key] = data
+	return nil
+}
+
+func (s *memoryBlobStore) Open(key string) (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.blobs[key]
+	if !ok {
+		return nil, errors.New("blob not found")
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *memoryBlobStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.blobs, key)
+	return nil
+}
+
+// pngEvidence is the PNG signature followed by filler, enough for content sniffing.
+var pngEvidence = append([]byte("\x89PNG\x0D\x0A\x1A\x0A"), bytes.Repeat([]byte{0}, 64)...)
+
+func Test_EvidenceService_StoreEvidence_Success(t *testing.T) {
+	t.Parallel()
+
+	store := newMemoryBlobStore()
+	repos := newTestRepos()
+	svc := NewEvidenceService(store, repos.completionEvent, repos.habitTask, repos.participation, repos.partnerGroup)
+	attachment, err := svc.StoreEvidence(dto.EvidenceUploadDTO{
+		FileName:    `C:\Users\me\streak.png`,
+		ContentType: "image/png",
+		Content:     pngEvidence,
+	})
+	require.NoError(t, err)
+
+	sum := sha256.Sum256(pngEvidence)
+	assert.Equal(t, "streak.png", attachment.FileName)
+	assert.Equal(t, "image/png", attachment.ContentType)
+	assert.Equal(t, int64(len(pngEvidence)), attachment.Size)
+	assert.Equal(t, hex.EncodeToString(sum[:]), attachment.SHA256)
+	assert.Equal(t, pngEvidence, store.blobs[attachment.Key])
+}
+
+func Test_EvidenceService_StoreEvidence_Rejected(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name   string
+		upload dto.EvidenceUploadDTO
+		want   error
+	}{
+		{"empty", dto.EvidenceUploadDTO{FileName: "a.png"}, config.ErrInvalidEvidence},
+		{"too large", dto.EvidenceUploadDTO{FileName: "a.png", Content: make([]byte, MaxEvidenceSize+1)}, config.ErrEvidenceTooLarge},
+		{"html", dto.EvidenceUploadDTO{FileName: "a.html", Content: []byte("<html><script>alert(1)</script></html>")}, config.ErrUnsupportedEvidenceType},
+		{"declared type disagrees", dto.EvidenceUploadDTO{FileName: "a.pdf", ContentType: "application/pdf", Content: pngEvidence}, config.ErrUnsupportedEvidenceType},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := newMemoryBlobStore()
+			repos := newTestRepos()
+			svc := NewEvidenceService(store, repos.completionEvent, repos.habitTask, repos.participation, repos.partnerGroup)
+			_, err := svc.StoreEvidence(tc.upload)
+			assert.True(t, errors.Is(err, tc.want), "got %v", err)
+			assert.Empty(t, store.blobs)
+		})
+	}
+}
+
+func Test_EvidenceService_DiscardEvidence(t *testing.T) {
+	t.Parallel()
+
+	store := newMemoryBlobStore()
+	repos := newTestRepos()
+	svc := NewEvidenceService(store, repos.completionEvent, repos.habitTask, repos.participation, repos.partnerGroup)
+	attachment, err := svc.StoreEvidence(dto.EvidenceUploadDTO{FileName: "a.png", Content: pngEvidence})
+	require.NoError(t, err)
+
+	svc.DiscardEvidence(attachment)
+	svc.DiscardEvidence(nil)
+	assert.Empty(t, store.blobs)
+}
+
+func Test_EvidenceService_OpenEvidence_Reviewers(t *testing.T) {
+	t.Parallel()
+
+	// user-1 owns the completion, user-2 is another participant and user-4 a member of the
+	// grind's partner group who does not take part in the grind
+	for _, userID := range []string{"user-1", "user-2", "user-4"} {
+		userID := userID
+		t.Run(userID, func(t *testing.T) {
+			t.Parallel()
+
+			repos := newTestRepos()
+			expectDisputeGrind(repos, []string{"user-1", "user-2"}, []string{"user-1", "user-4"})
+			svc := NewEvidenceService(newMemoryBlobStore(), repos.completionEvent, repos.habitTask, repos.participation, repos.partnerGroup)
+			attachment, err := svc.StoreEvidence(dto.EvidenceUploadDTO{FileName: "a.png", Content: pngEvidence})
+			require.NoError(t, err)
+			repos.completionEvent.On("FindByID", "event-1").Return(&entities.CompletionEvent{ID: "event-1", HabitTaskID: "task-1", Evidence: attachment}, nil)
+			repos.habitTask.On("FindByID", "task-1").Return(&entities.HabitTask{ID: "task-1", UserID: "user-1", GrindID: "grind-1"}, nil)
+
+			content, got, err := svc.OpenEvidence(dto.GetEvidenceDTO{UserID: userID, CompletionEventID: "event-1"})
+			require.NoError(t, err)
+			defer content.Close()
+			data, err := io.ReadAll(content)
+			require.NoError(t, err)
+			assert.Equal(t, pngEvidence, data)
+			assert.Equal(t, attachment, got)
+		})
+	}
+}
+
+func Test_EvidenceService_OpenEvidence_NotParticipant(t *testing.T) {
+	t.Parallel()
+
+	repos := newTestRepos()
+	expectDisputeGrind(repos, []string{"user-1", "user-2"}, []string{"user-1", "user-4"})
+	svc := NewEvidenceService(newMemoryBlobStore(), repos.completionEvent, repos.habitTask, repos.participation, repos.partnerGroup)
+	repos.completionEvent.On("FindByID", "event-1").Return(&entities.CompletionEvent{
+		ID: "event-1", HabitTaskID: "task-1", Evidence: &entities.EvidenceAttachment{Key: "blob-1"},
+	}, nil)
+	repos.habitTask.On("FindByID", "task-1").Return(&entities.HabitTask{ID: "task-1", UserID: "user-1", GrindID: "grind-1"}, nil)
+
+	_, _, err := svc.OpenEvidence(dto.GetEvidenceDTO{UserID: "user-3", CompletionEventID: "event-1"})
+	assert.True(t, errors.Is(err, config.ErrUserIsNotParticipant))
+}
+
+func Test_EvidenceService_OpenEvidence_NoEvidence(t *testing.T) {
+	t.Parallel()
+
+	repos := newTestRepos()
+	svc := NewEvidenceService(newMemoryBlobStore(), repos.completionEvent, repos.habitTask, repos.participation, repos.partnerGroup)
+	repos.completionEvent.On("FindByID", "event-1").Return(&entities.CompletionEvent{ID: "event-1", HabitTaskID: "task-1"}, nil)
+	repos.completionEvent.On("FindByID", "event-2").Return(nil, gorm.ErrRecordNotFound)
+
+	_, _, err := svc.OpenEvidence(dto.GetEvidenceDTO{UserID: "user-1", CompletionEventID: "event-1"})
+	assert.True(t, errors.Is(err, config.ErrEvidenceNotFound))
+	_, _, err = svc.OpenEvidence(dto.GetEvidenceDTO{UserID: "user-1", CompletionEventID: "event-2"})
+	assert.True(t, errors.Is(err, config.ErrCompletionEventNotFound))
+}
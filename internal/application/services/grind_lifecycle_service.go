@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/repositories"
+)
+
+// GrindLifecycleService drives the time-based grind transitions. A recruiting grind
+// becomes active as soon as its first day has begun in the earliest timezone (UTC+14),
+// which closes it for new participants. The end of a grind is handled by the
+// GrindSettlementService, which moves it to settling and settled.
+type GrindLifecycleService struct {
+	grindRepo repositories.GrindRepository
+	now       func() time.Time
+}
+
+func NewGrindLifecycleService(grindRepo repositories.GrindRepository) *GrindLifecycleService {
+	return &GrindLifecycleService{
+		grindRepo: grindRepo,
+		now:       time.Now,
+	}
+}
+
+// ActivateStartedGrinds activates every recruiting grind whose first day has begun somewhere.
+func (s *GrindLifecycleService) ActivateStartedGrinds() (*dto.GrindActivationResultDTO, error) {
+	grinds, err := s.grindRepo.FindAllByStates([]entities.GrindState{entities.GrindStateRecruiting})
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	result := &dto.GrindActivationResultDTO{ActivatedGrindIDs: []string{}}
+	for _, grind := range grinds {
+		if !grind.HasStarted(now) {
+			continue
+		}
+		err := transitionGrind(s.grindRepo, grind, entities.GrindStateActive, now)
+		if errors.Is(err, config.ErrGrindStateChanged) {
+			continue // published, cancelled or activated since we loaded it
+		}
+		if err != nil {
+			return nil, fmt.Errorf("activating grind %s: %w", grind.ID, err)
+		}
+		result.ActivatedGrindIDs = append(result.ActivatedGrindIDs, grind.ID)
+	}
+	return result, nil
+}
+
+// Run activates started grinds immediately and then shortly after every hour until ctx
+// is cancelled. Errors are reported and the loop keeps going.
+func (s *GrindLifecycleService) Run(ctx context.Context) {
+	for {
+		if _, err := s.ActivateStartedGrinds(); err != nil {
+			fmt.Println("grind activation failed", err)
+		}
+
+		next := s.now().UTC().Truncate(time.Hour).Add(time.Hour + time.Minute)
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GrindLifecycleService_ActivateStartedGrinds(t *testing.T) {
+	t.Parallel()
+
+	// 2026-04-01 10:00 UTC is already 2026-04-02 in UTC+14, but not yet 2026-04-03 anywhere.
+	now := time.Date(2026, 4, 1, 10, 0, 0, 0, time.UTC)
+	started := &entities.Grind{ID: "started", Duration: 3, StartDate: time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), State: entities.GrindStateRecruiting}
+	startingInKiribati := &entities.Grind{ID: "kiribati", Duration: 3, StartDate: time.Date(2026, 4, 2, 0, 0, 0, 0, time.UTC), State: entities.GrindStateRecruiting}
+	future := &entities.Grind{ID: "future", Duration: 3, StartDate: time.Date(2026, 4, 3, 0, 0, 0, 0, time.UTC), State: entities.GrindStateRecruiting}
+	raced := &entities.Grind{ID: "raced", Duration: 3, StartDate: time.Date(2026, 3, 30, 0, 0, 0, 0, time.UTC), State: entities.GrindStateRecruiting}
+
+	grindRepo := new(mocks.MockGrindRepository)
+	grindRepo.On("FindAllByStates", []entities.GrindState{entities.GrindStateRecruiting}).
+		Return([]*entities.Grind{started, startingInKiribati, future, raced}, nil)
+	grindRepo.On("UpdateState", started, entities.GrindStateRecruiting).Return(true, nil)
+	grindRepo.On("UpdateState", startingInKiribati, entities.GrindStateRecruiting).Return(true, nil)
+	grindRepo.On("UpdateState", raced, entities.GrindStateRecruiting).Return(false, nil) // cancelled meanwhile
+
+	svc := NewGrindLifecycleService(grindRepo)
+	svc.now = func() time.Time { return now }
+
+	result, err := svc.ActivateStartedGrinds()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"started", "kiribati"}, result.ActivatedGrindIDs)
+	assert.Equal(t, entities.GrindStateActive, started.State)
+	assert.Equal(t, now, *started.ActivatedAt)
+	assert.Equal(t, entities.GrindStateRecruiting, future.State)
+	grindRepo.AssertNotCalled(t, "UpdateState", future, mock.Anything)
+}
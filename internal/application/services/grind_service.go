@@ -19,6 +19,8 @@ type GrindService struct {
 	habitTaskRepo     repositories.HabitTaskRepository
 	participationRepo repositories.ParticipationRepository
 	messageRepo       repositories.MessageRepository
+	stakeChangeRepo   repositories.StakeChangeRepository
+	roadmapRepo       repositories.RoadmapRepository
 }
 
 func NewGrindService(
@@ -28,6 +30,8 @@ func NewGrindService(
 	habitTaskRepo repositories.HabitTaskRepository,
 	participationRepo repositories.ParticipationRepository,
 	messageRepo repositories.MessageRepository,
+	stakeChangeRepo repositories.StakeChangeRepository,
+	roadmapRepo repositories.RoadmapRepository,
 ) *GrindService {
 	return &GrindService{
 		db:                db,
@@ -36,15 +40,29 @@ func NewGrindService(
 		habitTaskRepo:     habitTaskRepo,
 		participationRepo: participationRepo,
 		messageRepo:       messageRepo,
+		stakeChangeRepo:   stakeChangeRepo,
+		roadmapRepo:       roadmapRepo,
 	}
 }
 
-func (s *GrindService) toGroupGrindDTO(grind *entities.Grind) (*dto.GroupGrindDTO, error) {
+// toGroupGrindDTO builds the grind view for viewerID, whose timezone decides which task is "today".
+func (s *GrindService) toGroupGrindDTO(grind *entities.Grind, viewerID string) (*dto.GroupGrindDTO, error) {
 	participants, err := s.userRepo.FindByGrindID(grind.ID)
 	if err != nil {
 		return nil, fmt.Errorf("fetching participants for grind %s: %w", grind.ID, err)
 	}
-	return mappers.BuildGroupGrindDTO(grind, participants), nil
+	participations, err := s.participationRepo.FindByGrindID(grind.ID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching participations for grind %s: %w", grind.ID, err)
+	}
+	loc := time.UTC
+	for i := range participants {
+		if participants[i].ID == viewerID {
+			loc = participants[i].Location()
+			break
+		}
+	}
+	return mappers.BuildGroupGrindDTO(grind, participants, participations, loc), nil
 }
 
 func (s *GrindService) toParticipationDTO(participation *entities.Participation) *dto.ParticipationDTO {
@@ -56,10 +74,59 @@ func (s *GrindService) CreateGroupGrind(request dto.CreateGrindDTO) (*dto.GroupG
 	if err != nil {
 		return nil, err
 	}
+	if request.PenaltyPolicy != nil {
+		policy, err := entities.NewPenaltyPolicy(
+			entities.PenaltyKind(request.PenaltyPolicy.Kind),
+			request.PenaltyPolicy.Amount,
+			request.PenaltyPolicy.Increment,
+			request.PenaltyPolicy.GraceDays,
+			request.PenaltyPolicy.FreezeTokens,
+			request.PenaltyPolicy.CapAtBudget,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", config.ErrInvalidPenaltyPolicy, err)
+		}
+		grind.PenaltyPolicy = policy
+	}
+	if request.Schedule != nil {
+		schedule, err := buildSchedule(request.Schedule)
+		if err != nil {
+			return nil, err
+		}
+		grind.Schedule = schedule
+		if err := grind.ValidateSchedule(); err != nil {
+			return nil, fmt.Errorf("%w: %v", config.ErrInvalidSchedule, err)
+		}
+		if request.PenaltyPolicy == nil {
+			// spread the budget over the days that are actually required
+			grind.PenaltyPolicy = entities.DefaultPenaltyPolicy(grind.RequiredDays(), request.Budget)
+		}
+	}
+	if request.Roadmap != nil {
+		roadmap, err := entities.NewRoadmap(
+			request.Roadmap.Name,
+			entities.RoadmapOrder(request.Roadmap.Order),
+			request.Roadmap.Tags,
+			request.Roadmap.Slugs,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", config.ErrInvalidRoadmap, err)
+		}
+		grind.Roadmap = roadmap
+	}
+	plan, err := s.roadmapPlan(grind)
+	if err != nil {
+		return nil, err
+	}
+	if !request.Draft {
+		if err := grind.TransitionTo(entities.GrindStateRecruiting, time.Now()); err != nil {
+			return nil, err
+		}
+	}
 
 	var result *dto.GroupGrindDTO
 
-	txErr := s.db.Transaction(func(tx *gorm.DB) error {
+	txErr := runInTransaction(s.db, func(tx *gorm.DB) error {
 		grindRepo := getGrindRepo(s.grindRepo, tx)
 		partRepo := getParticipationRepo(s.participationRepo, tx)
 		habitTaskRepo := getHabitTaskRepo(s.habitTaskRepo, tx)
@@ -76,26 +143,19 @@ func (s *GrindService) CreateGroupGrind(request dto.CreateGrindDTO) (*dto.GroupG
 			return err
 		}
 
-		tasks := make([]entities.HabitTask, 0, request.Duration)
-		for i := 0; i < request.Duration; i++ {
-			task, err := entities.NewHabitTask(request.CreatorID, grind.ID, request.StartDate.AddDate(0, 0, i))
-			if err != nil {
-				return err
-			}
-			if err := habitTaskRepo.Create(task); err != nil {
-				return err
-			}
-			tasks = append(tasks, *task)
-		}
-		grind.Tasks = tasks
-
 		creator, err := s.userRepo.FindById(request.CreatorID)
 		if err != nil {
 			return err
 		}
 		grind.Participants = []entities.User{*creator}
 
-		dto, dtoErr := s.toGroupGrindDTO(grind)
+		tasks, err := createParticipantTasks(habitTaskRepo, grind, creator, plan)
+		if err != nil {
+			return err
+		}
+		grind.Tasks = tasks
+
+		dto, dtoErr := s.toGroupGrindDTO(grind, request.CreatorID)
 		if dtoErr != nil {
 			return dtoErr
 		}
@@ -114,9 +174,15 @@ func (s *GrindService) GetOngoingGrindByUserID(request dto.GetOngoingGrindDTO) (
 	if err != nil {
 		return nil, config.ErrGrindNotFound
 	}
+	if !grind.State.IsOngoing() {
+		return nil, config.ErrNoOngoingGrind
+	}
 
-	endDate := grind.StartDate.AddDate(0, 0, int(grind.Duration))
-	if endDate.Before(time.Now().UTC()) {
+	loc := time.UTC
+	if user, err := s.userRepo.FindById(request.UserID); err == nil {
+		loc = user.Location()
+	}
+	if grind.EndDateIn(loc).Before(time.Now()) {
 		return nil, config.ErrNoOngoingGrind
 	}
 
@@ -131,7 +197,7 @@ func (s *GrindService) GetOngoingGrindByUserID(request dto.GetOngoingGrindDTO) (
 	}
 	grind.Tasks = tasks
 
-	return s.toGroupGrindDTO(grind)
+	return s.toGroupGrindDTO(grind, request.UserID)
 }
 
 func (s *GrindService) GetGrind(request dto.GetGrindDTO) (*dto.GroupGrindDTO, error) {
@@ -144,54 +210,332 @@ func (s *GrindService) GetGrind(request dto.GetGrindDTO) (*dto.GroupGrindDTO, er
 		return nil, config.ErrTasksNotFound
 	}
 	grind.Tasks = tasks
-	return s.toGroupGrindDTO(grind)
+	return s.toGroupGrindDTO(grind, request.UserID)
 }
 
-func (s *GrindService) GetAllUserGrinds(request dto.GetAllUserGrindsDTO) (map[string]*dto.GroupGrindDTO, error) {
-	grinds, err := s.grindRepo.FindAllByUserID(request.UserID)
+// GetAllUserGrinds returns a page of the user's grinds, newest first.
+func (s *GrindService) GetAllUserGrinds(request dto.GetAllUserGrindsDTO) (*dto.GroupGrindPageDTO, error) {
+	states := make([]entities.GrindState, 0, len(request.States))
+	for _, state := range request.States {
+		if !entities.GrindState(state).IsValid() {
+			return nil, fmt.Errorf("%w: %q", config.ErrInvalidGrindState, state)
+		}
+		states = append(states, entities.GrindState(state))
+	}
+	after, err := decodePageCursor(request.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	grinds, err := s.grindRepo.FindAllByUserID(request.UserID, states, after, pageFetchLimit(request.Limit))
 	if err != nil {
 		return nil, config.ErrGrindNotFound
 	}
-	output := make(map[string]*dto.GroupGrindDTO)
+	grinds, nextCursor := trimPage(grinds, request.Limit, grindPosition)
+	output := &dto.GroupGrindPageDTO{Grinds: make([]*dto.GroupGrindDTO, 0, len(grinds)), NextCursor: nextCursor}
 	for _, grind := range grinds {
 		tasks, err := s.habitTaskRepo.FindByGrindIDAndParticipantID(grind.ID, request.UserID)
 		if err != nil {
 			return nil, config.ErrTasksNotFound
 		}
 		grind.Tasks = tasks
-		grindDTO, dtoErr := s.toGroupGrindDTO(grind)
+		grindDTO, dtoErr := s.toGroupGrindDTO(grind, request.UserID)
 		if dtoErr != nil {
 			return nil, dtoErr
 		}
-		output[grind.ID] = grindDTO
+		output.Grinds = append(output.Grinds, grindDTO)
 	}
 	return output, nil
 }
 
-func (s *GrindService) UpdateGrind(request dto.UpdateGrindDTO) (*dto.GroupGrindDTO, error) {
+// UpdateGrind changes a grind that has not started anywhere yet. Schedule changes
+// (duration, start date) take effect immediately and every participant's tasks are
+// reconciled in the same transaction. Stake changes (budget, penalty policy) only take
+// effect once every other active participant has approved them; until then they are
+// returned as a pending StakeChange, replacing any earlier proposal.
+func (s *GrindService) UpdateGrind(request dto.UpdateGrindDTO) (*dto.UpdateGrindResultDTO, error) {
 	grind, err := s.grindRepo.FindById(request.GrindID)
 	if err != nil {
 		return nil, config.ErrGrindNotFound
 	}
-	_, err = s.participationRepo.FindByUserAndGrind(request.UserID, request.GrindID)
+	participations, err := s.participationRepo.FindByGrindID(grind.ID)
+	if err != nil {
+		return nil, err
+	}
+	consenters := activeParticipantIDs(participations)
+	if !containsString(consenters, request.UserID) {
+		return nil, config.ErrUserIsNotParticipant
+	}
+	now := time.Now()
+	if err := validateGrindEditable(grind, now); err != nil {
+		return nil, err
+	}
+
+	// Work out the requested schedule and stake before touching anything.
+	schedule := *grind
+	if request.Duration != nil {
+		if *request.Duration < 1 {
+			return nil, fmt.Errorf("%w: duration must be at least 1 day", config.ErrInvalidGrindUpdate)
+		}
+		schedule.Duration = int32(*request.Duration)
+	}
+	if request.StartDate != nil {
+		schedule.StartDate = request.StartDate.UTC()
+		if schedule.HasStarted(now) {
+			return nil, fmt.Errorf("%w: the new start date has already begun somewhere", config.ErrInvalidGrindUpdate)
+		}
+	}
+	if request.Schedule != nil {
+		if schedule.Schedule, err = buildSchedule(request.Schedule); err != nil {
+			return nil, err
+		}
+	}
+	if err := schedule.ValidateSchedule(); err != nil {
+		return nil, fmt.Errorf("%w: %v", config.ErrInvalidSchedule, err)
+	}
+	scheduleChanged := schedule.Duration != grind.Duration ||
+		!schedule.StartDate.Equal(grind.StartDate) ||
+		!schedule.Schedule.Equal(grind.Schedule)
+	var plan []entities.RoadmapProblem
+	if scheduleChanged {
+		// the roadmap's problems are spread over the new habit days
+		if plan, err = s.roadmapPlan(&schedule); err != nil {
+			return nil, err
+		}
+	}
+
+	budget, policy := grind.Budget, grind.PenaltyPolicy
+	if request.Budget != nil {
+		if *request.Budget < 0 {
+			return nil, fmt.Errorf("%w: budget cannot be negative", config.ErrInvalidGrindUpdate)
+		}
+		budget = int32(*request.Budget)
+	}
+	if request.PenaltyPolicy != nil {
+		policy, err = entities.NewPenaltyPolicy(
+			entities.PenaltyKind(request.PenaltyPolicy.Kind),
+			request.PenaltyPolicy.Amount,
+			request.PenaltyPolicy.Increment,
+			request.PenaltyPolicy.GraceDays,
+			request.PenaltyPolicy.FreezeTokens,
+			request.PenaltyPolicy.CapAtBudget,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", config.ErrInvalidPenaltyPolicy, err)
+		}
+	}
+	stakeChanged := budget != grind.Budget || policy != grind.PenaltyPolicy
+
+	var participants []entities.User
+	if scheduleChanged {
+		if participants, err = s.userRepo.FindByGrindID(grind.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	var pending *entities.StakeChange
+	txErr := runInTransaction(s.db, func(tx *gorm.DB) error {
+		grindRepo := getGrindRepo(s.grindRepo, tx)
+		habitTaskRepo := getHabitTaskRepo(s.habitTaskRepo, tx)
+		stakeChangeRepo := getStakeChangeRepo(s.stakeChangeRepo, tx)
+		msgRepo := getMessageRepo(s.messageRepo, tx)
+
+		grindChanged := scheduleChanged
+		if scheduleChanged {
+			grind.Duration = schedule.Duration
+			grind.StartDate = schedule.StartDate
+			grind.Schedule = schedule.Schedule
+			for i := range participants {
+				if err := reconcileParticipantTasks(habitTaskRepo, grind, &participants[i], plan); err != nil {
+					return err
+				}
+			}
+		}
+
+		if stakeChanged {
+			change, err := entities.NewStakeChange(grind.ID, request.UserID, int(budget), policy)
+			if err != nil {
+				return err
+			}
+			if change.IsApprovedByAll(consenters) {
+				// nobody else has a say, e.g. the creator alone in a draft
+				grind.Budget = budget
+				grind.PenaltyPolicy = policy
+				grindChanged = true
+			} else {
+				if err := proposeStakeChange(stakeChangeRepo, msgRepo, s.userRepo, change, consenters, now); err != nil {
+					return err
+				}
+				pending = change
+			}
+		}
+
+		if grindChanged {
+			if err := grindRepo.Update(grind); err != nil {
+				return fmt.Errorf("%w: %v", config.ErrGrindUpdateFailed, err)
+			}
+		}
+		return nil
+	})
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	tasks, err := s.habitTaskRepo.FindByGrindIDAndParticipantID(grind.ID, request.UserID)
+	if err != nil {
+		return nil, config.ErrTasksNotFound
+	}
+	grind.Tasks = tasks
+	grindDTO, err := s.toGroupGrindDTO(grind, request.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.UpdateGrindResultDTO{Grind: grindDTO, PendingStakeChange: mappers.BuildStakeChangeDTO(pending)}, nil
+}
+
+// ApproveStakeChange records the participant's approval of a pending stake change and
+// applies it to the grind once every active participant has approved it.
+func (s *GrindService) ApproveStakeChange(request dto.RespondStakeChangeDTO) (*dto.StakeChangeDTO, error) {
+	change, grind, consenters, err := s.loadStakeChange(request)
 	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := validateGrindEditable(grind, now); err != nil {
+		return nil, err
+	}
+
+	txErr := runInTransaction(s.db, func(tx *gorm.DB) error {
+		grindRepo := getGrindRepo(s.grindRepo, tx)
+		stakeChangeRepo := getStakeChangeRepo(s.stakeChangeRepo, tx)
+
+		// re-read under the lock so a concurrent approval is not overwritten
+		change, err = stakeChangeRepo.FindByIDForUpdate(change.ID)
+		if err != nil {
+			return config.ErrStakeChangeNotFound
+		}
+		if err := change.Approve(request.UserID, now); err != nil {
+			return config.ErrStakeChangeNotPending
+		}
+		if change.IsApprovedByAll(consenters) {
+			grind.Budget = change.Budget
+			grind.PenaltyPolicy = change.PenaltyPolicy
+			if err := grindRepo.Update(grind); err != nil {
+				return fmt.Errorf("%w: %v", config.ErrGrindUpdateFailed, err)
+			}
+			change.Close(entities.StakeChangeApplied, now)
+		}
+		return stakeChangeRepo.Update(change)
+	})
+	if txErr != nil {
+		return nil, txErr
+	}
+	return mappers.BuildStakeChangeDTO(change), nil
+}
+
+// RejectStakeChange closes a pending stake change on behalf of one participant and lets
+// the proposer know. The grind keeps its current stake.
+func (s *GrindService) RejectStakeChange(request dto.RespondStakeChangeDTO) (*dto.StakeChangeDTO, error) {
+	change, _, _, err := s.loadStakeChange(request)
+	if err != nil {
+		return nil, err
+	}
+
+	txErr := runInTransaction(s.db, func(tx *gorm.DB) error {
+		stakeChangeRepo := getStakeChangeRepo(s.stakeChangeRepo, tx)
+		msgRepo := getMessageRepo(s.messageRepo, tx)
+
+		change, err = stakeChangeRepo.FindByIDForUpdate(change.ID)
+		if err != nil {
+			return config.ErrStakeChangeNotFound
+		}
+		if err := change.Reject(request.UserID, time.Now()); err != nil {
+			return config.ErrStakeChangeNotPending
+		}
+		if err := stakeChangeRepo.Update(change); err != nil {
+			return err
+		}
+		if request.UserID == change.ProposerID {
+			return nil // withdrawn by the proposer
+		}
+		content, err := renderMessageFor(s.userRepo, change.ProposerID, StakeChangeRejectedEvent{Rejecter: displayName(s.userRepo, request.UserID)})
+		if err != nil {
+			return err
+		}
+		rejectedMsg, err := entities.NewMessage(
+			request.UserID,
+			change.ProposerID,
+			content,
+			"stake_change",
+			change.GrindID,
+			false,
+			false,
+		)
+		if err != nil {
+			return err
+		}
+		return msgRepo.Create(rejectedMsg)
+	})
+	if txErr != nil {
+		return nil, txErr
+	}
+	return mappers.BuildStakeChangeDTO(change), nil
+}
+
+// loadStakeChange returns the stake change, its grind and the IDs of the grind's active
+// participants, after checking that the requester is one of them.
+func (s *GrindService) loadStakeChange(request dto.RespondStakeChangeDTO) (*entities.StakeChange, *entities.Grind, []string, error) {
+	change, err := s.stakeChangeRepo.FindByID(request.StakeChangeID)
+	if err != nil || change == nil || change.GrindID != request.GrindID {
+		return nil, nil, nil, config.ErrStakeChangeNotFound
+	}
+	grind, err := s.grindRepo.FindById(change.GrindID)
+	if err != nil {
+		return nil, nil, nil, config.ErrGrindNotFound
+	}
+	participations, err := s.participationRepo.FindByGrindID(grind.ID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	consenters := activeParticipantIDs(participations)
+	if !containsString(consenters, request.UserID) {
+		return nil, nil, nil, config.ErrUserIsNotParticipant
+	}
+	return change, grind, consenters, nil
+}
+
+// PublishGrind opens a draft grind for invitations and joins. Only participants can publish it.
+func (s *GrindService) PublishGrind(request dto.TransitionGrindDTO) (*dto.GroupGrindDTO, error) {
+	return s.transitionAsParticipant(request, entities.GrindStateRecruiting)
+}
+
+// CancelGrind abandons a grind that has not started yet. Only participants can cancel it.
+func (s *GrindService) CancelGrind(request dto.TransitionGrindDTO) (*dto.GroupGrindDTO, error) {
+	return s.transitionAsParticipant(request, entities.GrindStateCancelled)
+}
+
+func (s *GrindService) transitionAsParticipant(request dto.TransitionGrindDTO, next entities.GrindState) (*dto.GroupGrindDTO, error) {
+	grind, err := s.grindRepo.FindById(request.GrindID)
+	if err != nil {
+		return nil, config.ErrGrindNotFound
+	}
+	participation, err := s.participationRepo.FindByUserAndGrind(request.UserID, request.GrindID)
+	if err != nil || participation == nil {
 		return nil, config.ErrUserIsNotParticipant
 	}
-	grind.Duration = int32(request.Duration)
-	grind.Budget = int32(request.Budget)
-	if err := s.grindRepo.Update(grind); err != nil {
-		return nil, config.ErrGrindUpdateFailed
+	if err := transitionGrind(s.grindRepo, grind, next, time.Now()); err != nil {
+		return nil, err
 	}
+
 	tasks, err := s.habitTaskRepo.FindByGrindIDAndParticipantID(grind.ID, request.UserID)
 	if err != nil {
 		return nil, config.ErrTasksNotFound
 	}
 	grind.Tasks = tasks
-	return s.toGroupGrindDTO(grind)
+	return s.toGroupGrindDTO(grind, request.UserID)
 }
 
 func (s *GrindService) DeleteGrind(request dto.DeleteGrindDTO) error {
-	return s.db.Transaction(func(tx *gorm.DB) error {
+	return runInTransaction(s.db, func(tx *gorm.DB) error {
 		habitTaskRepo := getHabitTaskRepo(s.habitTaskRepo, tx)
 		partRepo := getParticipationRepo(s.participationRepo, tx)
 		grindRepo := getGrindRepo(s.grindRepo, tx)
@@ -226,9 +570,16 @@ func (s *GrindService) AddParticipation(request dto.AddParticipationDTO) error {
 	if err != nil {
 		return config.ErrGrindNotFound
 	}
+	if err := grind.ValidateJoin(); err != nil {
+		return err
+	}
+	plan, err := s.roadmapPlan(grind)
+	if err != nil {
+		return err
+	}
 
 	// Wrap only the writes in a transaction
-	return s.db.Transaction(func(tx *gorm.DB) error {
+	return runInTransaction(s.db, func(tx *gorm.DB) error {
 		partRepo := getParticipationRepo(s.participationRepo, tx)
 		habitTaskRepo := getHabitTaskRepo(s.habitTaskRepo, tx)
 
@@ -240,16 +591,8 @@ func (s *GrindService) AddParticipation(request dto.AddParticipationDTO) error {
 			return err
 		}
 
-		for i := 0; i < int(grind.Duration); i++ {
-			task, err := entities.NewHabitTask(user.ID, grind.ID, grind.StartDate.AddDate(0, 0, i))
-			if err != nil {
-				return err
-			}
-			if err := habitTaskRepo.Create(task); err != nil {
-				return err
-			}
-		}
-		return nil
+		_, err = createParticipantTasks(habitTaskRepo, grind, user, plan)
+		return err
 	})
 }
 
@@ -277,12 +620,27 @@ func (s *GrindService) AcceptInvitation(
 	if err != nil {
 		return config.ErrGrindNotFound
 	}
+	if err := grind.ValidateJoin(); err != nil {
+		return err
+	}
+	plan, err := s.roadmapPlan(grind)
+	if err != nil {
+		return err
+	}
 
-	return s.db.Transaction(func(tx *gorm.DB) error {
+	return runInTransaction(s.db, func(tx *gorm.DB) error {
 		partRepo := getParticipationRepo(s.participationRepo, tx)
 		habitTaskRepo := getHabitTaskRepo(s.habitTaskRepo, tx)
 		msgRepo := getMessageRepo(messageRepo, tx)
 
+		inviteMsg, err := msgRepo.FindByID(updateMsgReq.MessageID)
+		if err != nil {
+			return config.ErrInvitationNotFound
+		}
+		if err := checkInvitationAnswerable(inviteMsg, user.ID, time.Now().UTC()); err != nil {
+			return err
+		}
+
 		// Create participation
 		participation, err := entities.NewParticipation(user.ID, grind.ID)
 		if err != nil {
@@ -293,31 +651,28 @@ func (s *GrindService) AcceptInvitation(
 		}
 
 		// Create habit tasks for each day of the grind
-		for i := 0; i < int(grind.Duration); i++ {
-			task, err := entities.NewHabitTask(user.ID, grind.ID, grind.StartDate.AddDate(0, 0, i))
-			if err != nil {
-				return err
-			}
-			if err := habitTaskRepo.Create(task); err != nil {
-				return err
-			}
+		if _, err := createParticipantTasks(habitTaskRepo, grind, user, plan); err != nil {
+			return err
 		}
 
 		// Update original invitation message status to accepted
-		inviteMsg, err := msgRepo.FindByID(updateMsgReq.MessageID)
-		if err != nil {
-			return err
-		}
 		inviteMsg.InvitationAccepted = updateMsgReq.Accepted
 		if err := msgRepo.Update(inviteMsg); err != nil {
 			return err
 		}
 
 		// Create accepted notification message to invitor
+		content, err := renderMessageFor(s.userRepo, createAcceptedMsgReq.InvitorID, InvitationAcceptedEvent{
+			Accepter: user.Username,
+			Grind:    grindDetails(grind),
+		})
+		if err != nil {
+			return err
+		}
 		acceptedMsg, err := entities.NewMessage(
 			createAcceptedMsgReq.AccepterID,
 			createAcceptedMsgReq.InvitorID,
-			createAcceptedMsgReq.AccepterID+" accepted your invitation",
+			content,
 			"invitation_accepted",
 			createAcceptedMsgReq.GrindID,
 			true,
@@ -336,19 +691,35 @@ func (s *GrindService) QuitGrind(request dto.QuitGrindDTO) (*dto.ParticipationDT
 		return nil, config.ErrGrindNotFound
 	}
 
-	participation, err := s.participationRepo.FindByUserAndGrind(request.UserID, request.GrindID)
-	if err != nil {
-		return nil, config.ErrParticipationNotFound
-	}
-	if participation == nil {
-		return nil, config.ErrUserIsNotParticipant
-	}
+	var participation *entities.Participation
+	txErr := runInTransaction(s.db, func(tx *gorm.DB) error {
+		partRepo := getParticipationRepo(s.participationRepo, tx)
+		// locked so a missed day or settlement running meanwhile is not overwritten
+		locked, err := partRepo.FindByUserAndGrindForUpdate(request.UserID, request.GrindID)
+		if err != nil {
+			return config.ErrParticipationNotFound
+		}
+		if locked == nil {
+			return config.ErrUserIsNotParticipant
+		}
+		if locked.Finalized {
+			return config.ErrParticipationFinalized
+		}
+		if err := grind.ValidateQuit(); err != nil {
+			return err
+		}
 
-	participation.Quitted = true
-	participation.QuittedAt = time.Now()
-	participation.TotalPenalty = int(grind.Budget)
-	if err := s.participationRepo.Update(participation); err != nil {
-		return nil, config.ErrParticipationUpdateFailed
+		locked.Quitted = true
+		locked.QuittedAt = time.Now()
+		locked.TotalPenalty = int(grind.Budget)
+		if err := partRepo.Update(locked); err != nil {
+			return config.ErrParticipationUpdateFailed
+		}
+		participation = locked
+		return nil
+	})
+	if txErr != nil {
+		return nil, txErr
 	}
 
 	return s.toParticipationDTO(participation), nil
@@ -359,6 +730,273 @@ func (s *GrindService) MessageRepo() repositories.MessageRepository {
 	return s.messageRepo
 }
 
+// validateGrindEditable allows edits only while the grind is draft or recruiting and its
+// first day has not begun in any timezone.
+func validateGrindEditable(grind *entities.Grind, now time.Time) error {
+	if err := grind.ValidateEdit(); err != nil {
+		return err
+	}
+	if grind.HasStarted(now) {
+		return config.ErrGrindAlreadyStarted
+	}
+	return nil
+}
+
+// proposeStakeChange stores change as the grind's only pending proposal and asks every
+// other active participant for consent.
+func proposeStakeChange(
+	stakeChangeRepo repositories.StakeChangeRepository,
+	msgRepo repositories.MessageRepository,
+	userRepo repositories.UserRepository,
+	change *entities.StakeChange,
+	consenters []string,
+	now time.Time,
+) error {
+	previous, err := stakeChangeRepo.FindPendingByGrindID(change.GrindID)
+	if err != nil {
+		return err
+	}
+	if previous != nil {
+		previous.Close(entities.StakeChangeSuperseded, now)
+		if err := stakeChangeRepo.Update(previous); err != nil {
+			return err
+		}
+	}
+	if err := stakeChangeRepo.Create(change); err != nil {
+		return err
+	}
+
+	proposed := StakeChangeProposedEvent{
+		Proposer:      displayName(userRepo, change.ProposerID),
+		Budget:        change.Budget,
+		PenaltyKind:   string(change.PenaltyPolicy.Kind),
+		PenaltyAmount: change.PenaltyPolicy.Amount,
+	}
+	for _, userID := range consenters {
+		if userID == change.ProposerID {
+			continue
+		}
+		content, err := renderMessageFor(userRepo, userID, proposed)
+		if err != nil {
+			return err
+		}
+		msg, err := entities.NewMessage(change.ProposerID, userID, content, "stake_change", change.GrindID, false, false)
+		if err != nil {
+			return err
+		}
+		if err := msgRepo.Create(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// activeParticipantIDs returns the users who are still in the grind.
+func activeParticipantIDs(participations []*entities.Participation) []string {
+	ids := make([]string, 0, len(participations))
+	for _, p := range participations {
+		if !p.Quitted {
+			ids = append(ids, p.UserID)
+		}
+	}
+	return ids
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
+// buildSchedule turns the requested schedule into a Schedule; errors wrap ErrInvalidSchedule.
+func buildSchedule(request *dto.ScheduleDTO) (entities.Schedule, error) {
+	dates := make([]time.Time, 0, len(request.Dates))
+	for _, text := range request.Dates {
+		date, err := time.Parse("2006-01-02", text)
+		if err != nil {
+			return entities.Schedule{}, fmt.Errorf("%w: dates must be YYYY-MM-DD, got %q", config.ErrInvalidSchedule, text)
+		}
+		dates = append(dates, date)
+	}
+	schedule, err := entities.NewSchedule(entities.ScheduleKind(request.Kind), request.TimesPerWeek, dates)
+	if err != nil {
+		return entities.Schedule{}, fmt.Errorf("%w: %v", config.ErrInvalidSchedule, err)
+	}
+	return schedule, nil
+}
+
+// roadmapPlan returns the problems assigned to the grind's habit days, one per scheduled
+// day in order, or nil for a grind without a roadmap. Errors wrap ErrInvalidRoadmap.
+func (s *GrindService) roadmapPlan(grind *entities.Grind) ([]entities.RoadmapProblem, error) {
+	if grind.Roadmap == nil {
+		return nil, nil
+	}
+	catalog, err := s.roadmapRepo.FindProblems(grind.Roadmap.Name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", config.ErrInvalidRoadmap, err)
+	}
+	plan, err := grind.Roadmap.Plan(catalog)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", config.ErrInvalidRoadmap, err)
+	}
+	days := len(grind.ScheduledDays())
+	if len(plan) < days {
+		return nil, fmt.Errorf("%w: the roadmap has %d problems but the grind has %d habit days", config.ErrInvalidRoadmap, len(plan), days)
+	}
+	return plan[:days], nil
+}
+
+// reconcileParticipantTasks makes user's tasks match the grind's schedule: tasks on days
+// that are no longer habit days are deleted and habit days without a task get one. For a
+// roadmap grind, plan holds the problem of each habit day and kept tasks are reassigned
+// when their day's problem moved.
+func reconcileParticipantTasks(
+	habitTaskRepo repositories.HabitTaskRepository,
+	grind *entities.Grind,
+	user *entities.User,
+	plan []entities.RoadmapProblem,
+) error {
+	existing, err := habitTaskRepo.FindByGrindIDAndUserID(grind.ID, user.ID)
+	if err != nil {
+		return err
+	}
+
+	loc := user.Location()
+	scheduled := grind.ScheduledDays()
+	wanted := make(map[int64]bool, len(scheduled))
+	for _, i := range scheduled {
+		wanted[grind.DayStart(i, loc).Unix()] = true
+	}
+	kept := make(map[int64]*entities.HabitTask, len(scheduled))
+	stale := make([]string, 0)
+	for _, task := range existing {
+		day := task.Date.Unix()
+		if wanted[day] && kept[day] == nil {
+			kept[day] = task // keep the first task of each scheduled day
+			continue
+		}
+		stale = append(stale, task.ID)
+	}
+	if err := habitTaskRepo.DeleteByIDs(stale); err != nil {
+		return err
+	}
+
+	created := make([]*entities.HabitTask, 0, len(scheduled)-len(kept))
+	for k, i := range scheduled {
+		day := grind.DayStart(i, loc)
+		task := kept[day.Unix()]
+		if task == nil {
+			var err error
+			if task, err = entities.NewHabitTask(user.ID, grind.ID, day); err != nil {
+				return err
+			}
+			created = append(created, task)
+		}
+		if plan == nil || task.AssignedProblemSlug() == plan[k].Slug {
+			continue
+		}
+		task.AssignProblem(grind.Roadmap.Name, plan[k])
+		if kept[day.Unix()] != nil {
+			if err := habitTaskRepo.Update(task); err != nil {
+				return err
+			}
+		}
+	}
+	return habitTaskRepo.CreateBatch(created)
+}
+
+// createParticipantTasks creates one HabitTask per habit day of the grind's schedule for user,
+// each dated at local midnight in the user's timezone. For a roadmap grind, plan holds the
+// problem assigned to each habit day.
+func createParticipantTasks(
+	habitTaskRepo repositories.HabitTaskRepository,
+	grind *entities.Grind,
+	user *entities.User,
+	plan []entities.RoadmapProblem,
+) ([]entities.HabitTask, error) {
+	loc := user.Location()
+	scheduled := grind.ScheduledDays()
+	created := make([]*entities.HabitTask, 0, len(scheduled))
+	for k, i := range scheduled {
+		task, err := entities.NewHabitTask(user.ID, grind.ID, grind.DayStart(i, loc))
+		if err != nil {
+			return nil, err
+		}
+		if plan != nil {
+			task.AssignProblem(grind.Roadmap.Name, plan[k])
+		}
+		created = append(created, task)
+	}
+	// one multi-row insert instead of a round-trip per day
+	if err := habitTaskRepo.CreateBatch(created); err != nil {
+		return nil, err
+	}
+	tasks := make([]entities.HabitTask, len(created))
+	for i, task := range created {
+		tasks[i] = *task
+	}
+	return tasks, nil
+}
+
+// transitionGrind moves grind to next and persists the change with grindRepo, which may be
+// bound to a transaction. It fails with ErrGrindStateChanged when the stored grind has
+// already left the state it was loaded in.
+func transitionGrind(grindRepo repositories.GrindRepository, grind *entities.Grind, next entities.GrindState, now time.Time) error {
+	from := grind.State
+	if err := grind.TransitionTo(next, now); err != nil {
+		return err
+	}
+	moved, err := grindRepo.UpdateState(grind, from)
+	if err != nil {
+		return err
+	}
+	if !moved {
+		return config.ErrGrindStateChanged
+	}
+	return nil
+}
+
+// runInTransaction executes fn inside a DB transaction. When db is nil (unit tests
+// wired with mocks) fn runs directly with a nil tx and repositories are used as-is.
+// Hooks registered with afterCommit run once the transaction has committed.
+func runInTransaction(db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	if db == nil {
+		return fn(nil)
+	}
+	if _, nested := db.Get(afterCommitKey); nested {
+		return db.Transaction(fn)
+	}
+
+	var hooks []func()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		return fn(tx.Set(afterCommitKey, &hooks).Session(&gorm.Session{}))
+	})
+	if err != nil {
+		return err
+	}
+	for _, hook := range hooks {
+		hook()
+	}
+	return nil
+}
+
+const afterCommitKey = "services:after_commit"
+
+// afterCommit runs hook once tx commits, or right away outside a transaction. Hooks of a
+// transaction that rolls back never run.
+func afterCommit(tx *gorm.DB, hook func()) {
+	if tx != nil {
+		if hooks, ok := tx.Get(afterCommitKey); ok {
+			*hooks.(*[]func()) = append(*hooks.(*[]func()), hook)
+			return
+		}
+	}
+	hook()
+}
+
 func getGrindRepo(r repositories.GrindRepository, tx *gorm.DB) repositories.GrindRepository {
 	if txRepo, ok := r.(interface {
 		WithTx(tx *gorm.DB) repositories.GrindRepository
@@ -368,6 +1006,15 @@ func getGrindRepo(r repositories.GrindRepository, tx *gorm.DB) repositories.Grin
 	return r
 }
 
+func getUserRepo(r repositories.UserRepository, tx *gorm.DB) repositories.UserRepository {
+	if txRepo, ok := r.(interface {
+		WithTx(tx *gorm.DB) repositories.UserRepository
+	}); ok {
+		return txRepo.WithTx(tx)
+	}
+	return r
+}
+
 func getParticipationRepo(r repositories.ParticipationRepository, tx *gorm.DB) repositories.ParticipationRepository {
 	if txRepo, ok := r.(interface {
 		WithTx(tx *gorm.DB) repositories.ParticipationRepository
@@ -394,3 +1041,30 @@ func getMessageRepo(r repositories.MessageRepository, tx *gorm.DB) repositories.
 	}
 	return r
 }
+
+func getStakeChangeRepo(r repositories.StakeChangeRepository, tx *gorm.DB) repositories.StakeChangeRepository {
+	if txRepo, ok := r.(interface {
+		WithTx(tx *gorm.DB) repositories.StakeChangeRepository
+	}); ok {
+		return txRepo.WithTx(tx)
+	}
+	return r
+}
+
+func getCompletionEventRepo(r repositories.CompletionEventRepository, tx *gorm.DB) repositories.CompletionEventRepository {
+	if txRepo, ok := r.(interface {
+		WithTx(tx *gorm.DB) repositories.CompletionEventRepository
+	}); ok {
+		return txRepo.WithTx(tx)
+	}
+	return r
+}
+
+func getCompletionDisputeRepo(r repositories.CompletionDisputeRepository, tx *gorm.DB) repositories.CompletionDisputeRepository {
+	if txRepo, ok := r.(interface {
+		WithTx(tx *gorm.DB) repositories.CompletionDisputeRepository
+	}); ok {
+		return txRepo.WithTx(tx)
+	}
+	return r
+}
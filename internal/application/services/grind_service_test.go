@@ -2,6 +2,7 @@ package services
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
 	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
 	"github.com/daniel0321forever/terriyaki-go/internal/domain/mocks"
+	"github.com/stretchr/testify/mock"
 )
 
 // NOTE: Tests for transactional write methods (CreateGroupGrind, DeleteGrind,
@@ -29,9 +31,11 @@ func TestGrindServiceGetOngoingGrindByUserID_EndedGrind(t *testing.T) {
 		ID:        "g1",
 		Duration:  5,
 		StartDate: time.Now().UTC().AddDate(0, 0, -10),
+		State:     entities.GrindStateActive,
 	}, nil)
+	userRepo.On("FindById", "u1").Return(&entities.User{ID: "u1", Timezone: "UTC"}, nil)
 
-	svc := NewGrindService(nil, grindRepo, userRepo, habitTaskRepo, partRepo, msgRepo)
+	svc := NewGrindService(nil, grindRepo, userRepo, habitTaskRepo, partRepo, msgRepo, new(mocks.MockStakeChangeRepository), new(mocks.MockRoadmapRepository))
 
 	_, err := svc.GetOngoingGrindByUserID(dto.GetOngoingGrindDTO{UserID: "u1"})
 	if !errors.Is(err, config.ErrNoOngoingGrind) {
@@ -50,7 +54,7 @@ func TestGrindServiceGetOngoingGrindByUserID_GrindNotFound(t *testing.T) {
 
 	grindRepo.On("FindLatestByUserID", "u1").Return(nil, errors.New("missing"))
 
-	svc := NewGrindService(nil, grindRepo, userRepo, habitTaskRepo, partRepo, msgRepo)
+	svc := NewGrindService(nil, grindRepo, userRepo, habitTaskRepo, partRepo, msgRepo, new(mocks.MockStakeChangeRepository), new(mocks.MockRoadmapRepository))
 
 	_, err := svc.GetOngoingGrindByUserID(dto.GetOngoingGrindDTO{UserID: "u1"})
 	if !errors.Is(err, config.ErrGrindNotFound) {
@@ -71,10 +75,12 @@ func TestGrindServiceGetOngoingGrindByUserID_UserQuitted(t *testing.T) {
 		ID:        "g1",
 		Duration:  5,
 		StartDate: time.Now().UTC().AddDate(0, 0, -1),
+		State:     entities.GrindStateActive,
 	}, nil)
+	userRepo.On("FindById", "u1").Return(&entities.User{ID: "u1", Timezone: "UTC"}, nil)
 	partRepo.On("FindByUserAndGrind", "u1", "g1").Return(&entities.Participation{UserID: "u1", GrindID: "g1", Quitted: true}, nil)
 
-	svc := NewGrindService(nil, grindRepo, userRepo, habitTaskRepo, partRepo, msgRepo)
+	svc := NewGrindService(nil, grindRepo, userRepo, habitTaskRepo, partRepo, msgRepo, new(mocks.MockStakeChangeRepository), new(mocks.MockRoadmapRepository))
 
 	_, err := svc.GetOngoingGrindByUserID(dto.GetOngoingGrindDTO{UserID: "u1"})
 	if !errors.Is(err, config.ErrUserNotParticipatingOrQuit) {
@@ -95,7 +101,7 @@ func TestGrindServiceAddParticipation_AlreadyExists(t *testing.T) {
 
 	partRepo.On("FindByUserAndGrind", "u1", "g1").Return(&entities.Participation{ID: "p1", UserID: "u1", GrindID: "g1"}, nil)
 
-	svc := NewGrindService(nil, grindRepo, userRepo, habitTaskRepo, partRepo, msgRepo)
+	svc := NewGrindService(nil, grindRepo, userRepo, habitTaskRepo, partRepo, msgRepo, new(mocks.MockStakeChangeRepository), new(mocks.MockRoadmapRepository))
 	err := svc.AddParticipation(dto.AddParticipationDTO{UserID: "u1", GrindID: "g1"})
 	if err == nil {
 		t.Fatalf("expected already exists error, got nil")
@@ -114,7 +120,7 @@ func TestGrindServiceAddParticipation_UserNotFound(t *testing.T) {
 	partRepo.On("FindByUserAndGrind", "u1", "g1").Return(nil, nil)
 	userRepo.On("FindById", "u1").Return(nil, errors.New("missing"))
 
-	svc := NewGrindService(nil, grindRepo, userRepo, habitTaskRepo, partRepo, msgRepo)
+	svc := NewGrindService(nil, grindRepo, userRepo, habitTaskRepo, partRepo, msgRepo, new(mocks.MockStakeChangeRepository), new(mocks.MockRoadmapRepository))
 	err := svc.AddParticipation(dto.AddParticipationDTO{UserID: "u1", GrindID: "g1"})
 	if !errors.Is(err, config.ErrUserNotFound) {
 		t.Fatalf("expected ErrUserNotFound, got %v", err)
@@ -134,9 +140,685 @@ func TestGrindServiceAddParticipation_GrindNotFound(t *testing.T) {
 	userRepo.On("FindById", "u1").Return(&entities.User{ID: "u1"}, nil)
 	grindRepo.On("FindById", "g1").Return(nil, errors.New("missing"))
 
-	svc := NewGrindService(nil, grindRepo, userRepo, habitTaskRepo, partRepo, msgRepo)
+	svc := NewGrindService(nil, grindRepo, userRepo, habitTaskRepo, partRepo, msgRepo, new(mocks.MockStakeChangeRepository), new(mocks.MockRoadmapRepository))
 	err := svc.AddParticipation(dto.AddParticipationDTO{UserID: "u1", GrindID: "g1"})
 	if !errors.Is(err, config.ErrGrindNotFound) {
 		t.Fatalf("expected ErrGrindNotFound, got %v", err)
 	}
 }
+
+func TestGrindServiceGetOngoingGrindByUserID_LastDayStillOpenInUserTimezone(t *testing.T) {
+	t.Parallel()
+
+	grindRepo := new(mocks.MockGrindRepository)
+	userRepo := new(mocks.MockUserRepository)
+	habitTaskRepo := new(mocks.MockHabitTaskRepository)
+	partRepo := new(mocks.MockParticipationRepository)
+	msgRepo := new(mocks.MockMessageRepository)
+
+	// The grind's last calendar day is today in UTC-12, which is at least as late as
+	// every other zone, so the grind must still be ongoing for that user.
+	loc := time.FixedZone("UTC-12", -12*60*60)
+	y, m, d := time.Now().In(loc).Date()
+	grindRepo.On("FindLatestByUserID", "u1").Return(&entities.Grind{
+		ID:        "g1",
+		Duration:  1,
+		StartDate: time.Date(y, m, d, 0, 0, 0, 0, time.UTC),
+		State:     entities.GrindStateActive,
+	}, nil)
+	userRepo.On("FindById", "u1").Return(&entities.User{ID: "u1", Timezone: "Etc/GMT+12"}, nil)
+	partRepo.On("FindByUserAndGrind", "u1", "g1").Return(&entities.Participation{UserID: "u1", GrindID: "g1", Quitted: true}, nil)
+
+	svc := NewGrindService(nil, grindRepo, userRepo, habitTaskRepo, partRepo, msgRepo, new(mocks.MockStakeChangeRepository), new(mocks.MockRoadmapRepository))
+
+	_, err := svc.GetOngoingGrindByUserID(dto.GetOngoingGrindDTO{UserID: "u1"})
+	if errors.Is(err, config.ErrNoOngoingGrind) {
+		t.Fatalf("expected the grind to still be ongoing in the user's timezone")
+	}
+}
+
+func TestGrindServiceCreateGroupGrind_InvalidPenaltyPolicy(t *testing.T) {
+	t.Parallel()
+
+	svc := NewGrindService(nil, new(mocks.MockGrindRepository), new(mocks.MockUserRepository),
+		new(mocks.MockHabitTaskRepository), new(mocks.MockParticipationRepository), new(mocks.MockMessageRepository), new(mocks.MockStakeChangeRepository), new(mocks.MockRoadmapRepository))
+
+	_, err := svc.CreateGroupGrind(dto.CreateGrindDTO{
+		CreatorID:     "u1",
+		Duration:      10,
+		Budget:        100,
+		StartDate:     time.Now(),
+		PenaltyPolicy: &dto.PenaltyPolicyDTO{Kind: "flat", Amount: 10, Increment: 5},
+	})
+	if !errors.Is(err, config.ErrInvalidPenaltyPolicy) {
+		t.Fatalf("expected ErrInvalidPenaltyPolicy, got %v", err)
+	}
+}
+
+func TestGrindServiceGetOngoingGrindByUserID_DraftIsNotOngoing(t *testing.T) {
+	t.Parallel()
+
+	grindRepo := new(mocks.MockGrindRepository)
+	grindRepo.On("FindLatestByUserID", "u1").Return(&entities.Grind{
+		ID:        "g1",
+		Duration:  5,
+		StartDate: time.Now().UTC(),
+		State:     entities.GrindStateDraft,
+	}, nil)
+
+	svc := NewGrindService(nil, grindRepo, new(mocks.MockUserRepository),
+		new(mocks.MockHabitTaskRepository), new(mocks.MockParticipationRepository), new(mocks.MockMessageRepository), new(mocks.MockStakeChangeRepository), new(mocks.MockRoadmapRepository))
+
+	_, err := svc.GetOngoingGrindByUserID(dto.GetOngoingGrindDTO{UserID: "u1"})
+	if !errors.Is(err, config.ErrNoOngoingGrind) {
+		t.Fatalf("expected ErrNoOngoingGrind, got %v", err)
+	}
+}
+
+func TestGrindServiceAddParticipation_RejectsGrindPastRecruiting(t *testing.T) {
+	t.Parallel()
+
+	for _, state := range []entities.GrindState{entities.GrindStateActive, entities.GrindStateSettled, entities.GrindStateCancelled} {
+		grindRepo := new(mocks.MockGrindRepository)
+		userRepo := new(mocks.MockUserRepository)
+		partRepo := new(mocks.MockParticipationRepository)
+
+		partRepo.On("FindByUserAndGrind", "u1", "g1").Return(nil, nil)
+		userRepo.On("FindById", "u1").Return(&entities.User{ID: "u1"}, nil)
+		grindRepo.On("FindById", "g1").Return(&entities.Grind{ID: "g1", Duration: 3, State: state}, nil)
+
+		svc := NewGrindService(nil, grindRepo, userRepo, new(mocks.MockHabitTaskRepository), partRepo, new(mocks.MockMessageRepository), new(mocks.MockStakeChangeRepository), new(mocks.MockRoadmapRepository))
+		err := svc.AddParticipation(dto.AddParticipationDTO{UserID: "u1", GrindID: "g1"})
+
+		var stateErr *entities.GrindStateError
+		if !errors.As(err, &stateErr) || stateErr.Op != "join" || stateErr.State != state {
+			t.Fatalf("expected a join GrindStateError for a %s grind, got %v", state, err)
+		}
+		partRepo.AssertNotCalled(t, "Create", mock.Anything)
+	}
+}
+
+func TestGrindServiceUpdateGrind_RejectsActiveGrind(t *testing.T) {
+	t.Parallel()
+
+	grindRepo := new(mocks.MockGrindRepository)
+	partRepo := new(mocks.MockParticipationRepository)
+	grindRepo.On("FindById", "g1").Return(&entities.Grind{ID: "g1", Duration: 3, State: entities.GrindStateActive}, nil)
+	partRepo.On("FindByGrindID", "g1").Return([]*entities.Participation{{UserID: "u1", GrindID: "g1"}}, nil)
+
+	svc := NewGrindService(nil, grindRepo, new(mocks.MockUserRepository),
+		new(mocks.MockHabitTaskRepository), partRepo, new(mocks.MockMessageRepository), new(mocks.MockStakeChangeRepository), new(mocks.MockRoadmapRepository))
+
+	duration := 10
+	_, err := svc.UpdateGrind(dto.UpdateGrindDTO{GrindID: "g1", UserID: "u1", Duration: &duration})
+	var stateErr *entities.GrindStateError
+	if !errors.As(err, &stateErr) || stateErr.Op != "edit" {
+		t.Fatalf("expected an edit GrindStateError, got %v", err)
+	}
+	grindRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestGrindServiceUpdateGrind_RejectsRecruitingGrindThatHasBegun(t *testing.T) {
+	t.Parallel()
+
+	grindRepo := new(mocks.MockGrindRepository)
+	partRepo := new(mocks.MockParticipationRepository)
+	// still recruiting because the lifecycle job has not run yet, but day 0 began in UTC+14
+	grindRepo.On("FindById", "g1").Return(&entities.Grind{
+		ID:        "g1",
+		Duration:  3,
+		StartDate: time.Now().UTC().Truncate(24 * time.Hour),
+		State:     entities.GrindStateRecruiting,
+	}, nil)
+	partRepo.On("FindByGrindID", "g1").Return([]*entities.Participation{{UserID: "u1", GrindID: "g1"}}, nil)
+
+	svc := NewGrindService(nil, grindRepo, new(mocks.MockUserRepository),
+		new(mocks.MockHabitTaskRepository), partRepo, new(mocks.MockMessageRepository), new(mocks.MockStakeChangeRepository), new(mocks.MockRoadmapRepository))
+
+	duration := 10
+	_, err := svc.UpdateGrind(dto.UpdateGrindDTO{GrindID: "g1", UserID: "u1", Duration: &duration})
+	if !errors.Is(err, config.ErrGrindAlreadyStarted) {
+		t.Fatalf("expected ErrGrindAlreadyStarted, got %v", err)
+	}
+}
+
+func TestGrindServiceUpdateGrind_ReconcilesTasks(t *testing.T) {
+	t.Parallel()
+
+	start := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 7)
+	grind := &entities.Grind{
+		ID:            "g1",
+		Duration:      3,
+		StartDate:     start,
+		State:         entities.GrindStateRecruiting,
+		PenaltyPolicy: entities.DefaultPenaltyPolicy(3, 50),
+	}
+	grindRepo := new(mocks.MockGrindRepository)
+	userRepo := new(mocks.MockUserRepository)
+	habitTaskRepo := new(mocks.MockHabitTaskRepository)
+	partRepo := new(mocks.MockParticipationRepository)
+
+	grindRepo.On("FindById", "g1").Return(grind, nil)
+	grindRepo.On("Update", grind).Return(nil)
+	partRepo.On("FindByGrindID", "g1").Return([]*entities.Participation{
+		{UserID: "u1", GrindID: "g1"},
+		{UserID: "u2", GrindID: "g1", Quitted: true},
+	}, nil)
+	userRepo.On("FindByGrindID", "g1").Return([]entities.User{{ID: "u1", Timezone: "UTC"}}, nil)
+	// the grind moves one day later and shrinks to two days: day 0 goes, day 1 stays, the old day 2 stays
+	habitTaskRepo.On("FindByGrindIDAndUserID", "g1", "u1").Return([]*entities.HabitTask{
+		{ID: "t0", Date: start},
+		{ID: "t1", Date: start.AddDate(0, 0, 1)},
+		{ID: "t2", Date: start.AddDate(0, 0, 2)},
+	}, nil)
+	habitTaskRepo.On("DeleteByIDs", []string{"t0"}).Return(nil)
+	habitTaskRepo.On("CreateBatch", []*entities.HabitTask{}).Return(nil)
+	habitTaskRepo.On("FindByGrindIDAndParticipantID", "g1", "u1").Return([]entities.HabitTask{}, nil)
+
+	svc := NewGrindService(nil, grindRepo, userRepo, habitTaskRepo, partRepo, new(mocks.MockMessageRepository), new(mocks.MockStakeChangeRepository), new(mocks.MockRoadmapRepository))
+
+	duration := 2
+	newStart := start.AddDate(0, 0, 1)
+	result, err := svc.UpdateGrind(dto.UpdateGrindDTO{GrindID: "g1", UserID: "u1", Duration: &duration, StartDate: &newStart})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.PendingStakeChange != nil {
+		t.Fatalf("expected no pending stake change, got %+v", result.PendingStakeChange)
+	}
+	if grind.Duration != 2 || !grind.StartDate.Equal(newStart) {
+		t.Fatalf("expected the grind to run 2 days from %v, got %d days from %v", newStart, grind.Duration, grind.StartDate)
+	}
+	habitTaskRepo.AssertCalled(t, "DeleteByIDs", []string{"t0"})
+	habitTaskRepo.AssertCalled(t, "CreateBatch", []*entities.HabitTask{})
+	grindRepo.AssertCalled(t, "Update", grind)
+}
+
+func TestGrindServiceUpdateGrind_ExtendingCreatesMissingTasks(t *testing.T) {
+	t.Parallel()
+
+	start := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 7)
+	grind := &entities.Grind{ID: "g1", Duration: 1, StartDate: start, State: entities.GrindStateDraft, PenaltyPolicy: entities.DefaultPenaltyPolicy(3, 50)}
+	grindRepo := new(mocks.MockGrindRepository)
+	userRepo := new(mocks.MockUserRepository)
+	habitTaskRepo := new(mocks.MockHabitTaskRepository)
+	partRepo := new(mocks.MockParticipationRepository)
+
+	grindRepo.On("FindById", "g1").Return(grind, nil)
+	grindRepo.On("Update", grind).Return(nil)
+	partRepo.On("FindByGrindID", "g1").Return([]*entities.Participation{{UserID: "u1", GrindID: "g1"}}, nil)
+	userRepo.On("FindByGrindID", "g1").Return([]entities.User{{ID: "u1", Timezone: "UTC"}}, nil)
+	habitTaskRepo.On("FindByGrindIDAndUserID", "g1", "u1").Return([]*entities.HabitTask{{ID: "t0", Date: start}}, nil)
+	habitTaskRepo.On("DeleteByIDs", []string{}).Return(nil)
+	habitTaskRepo.On("CreateBatch", mock.AnythingOfType("[]*entities.HabitTask")).Return(nil)
+	habitTaskRepo.On("FindByGrindIDAndParticipantID", "g1", "u1").Return([]entities.HabitTask{}, nil)
+
+	svc := NewGrindService(nil, grindRepo, userRepo, habitTaskRepo, partRepo, new(mocks.MockMessageRepository), new(mocks.MockStakeChangeRepository), new(mocks.MockRoadmapRepository))
+
+	duration := 3
+	if _, err := svc.UpdateGrind(dto.UpdateGrindDTO{GrindID: "g1", UserID: "u1", Duration: &duration}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	habitTaskRepo.AssertNumberOfCalls(t, "CreateBatch", 1)
+	for _, call := range habitTaskRepo.Calls {
+		if call.Method != "CreateBatch" {
+			continue
+		}
+		created := call.Arguments.Get(0).([]*entities.HabitTask)
+		if len(created) != 2 {
+			t.Fatalf("expected 2 new tasks, got %d", len(created))
+		}
+		for _, task := range created {
+			if task.Date.Equal(start) {
+				t.Fatal("recreated the existing day 0 task")
+			}
+		}
+	}
+}
+
+func TestGrindServiceUpdateGrind_SwitchingToWeekdaysDropsWeekendTasks(t *testing.T) {
+	t.Parallel()
+
+	// the Monday at least a week from now, so the grind has not started anywhere
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	start := today.AddDate(0, 0, 7+(8-int(today.Weekday()))%7)
+	grind := &entities.Grind{ID: "g1", Duration: 7, StartDate: start, State: entities.GrindStateRecruiting, PenaltyPolicy: entities.DefaultPenaltyPolicy(7, 70)}
+	tasks := make([]*entities.HabitTask, 0, 7)
+	for i := 0; i < 7; i++ {
+		tasks = append(tasks, &entities.HabitTask{ID: fmt.Sprintf("t%d", i), Date: start.AddDate(0, 0, i)})
+	}
+
+	grindRepo := new(mocks.MockGrindRepository)
+	userRepo := new(mocks.MockUserRepository)
+	habitTaskRepo := new(mocks.MockHabitTaskRepository)
+	partRepo := new(mocks.MockParticipationRepository)
+
+	grindRepo.On("FindById", "g1").Return(grind, nil)
+	grindRepo.On("Update", grind).Return(nil)
+	partRepo.On("FindByGrindID", "g1").Return([]*entities.Participation{{UserID: "u1", GrindID: "g1"}}, nil)
+	userRepo.On("FindByGrindID", "g1").Return([]entities.User{{ID: "u1", Timezone: "UTC"}}, nil)
+	habitTaskRepo.On("FindByGrindIDAndUserID", "g1", "u1").Return(tasks, nil)
+	habitTaskRepo.On("DeleteByIDs", []string{"t5", "t6"}).Return(nil)
+	habitTaskRepo.On("CreateBatch", []*entities.HabitTask{}).Return(nil)
+	habitTaskRepo.On("FindByGrindIDAndParticipantID", "g1", "u1").Return([]entities.HabitTask{}, nil)
+
+	svc := NewGrindService(nil, grindRepo, userRepo, habitTaskRepo, partRepo, new(mocks.MockMessageRepository), new(mocks.MockStakeChangeRepository), new(mocks.MockRoadmapRepository))
+
+	result, err := svc.UpdateGrind(dto.UpdateGrindDTO{GrindID: "g1", UserID: "u1", Schedule: &dto.ScheduleDTO{Kind: "weekdays"}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Grind.Schedule.Kind != "weekdays" || result.Grind.RequiredDays != 5 {
+		t.Fatalf("expected a weekdays grind with 5 required days, got %+v %d", result.Grind.Schedule, result.Grind.RequiredDays)
+	}
+	habitTaskRepo.AssertExpectations(t)
+}
+
+func TestGrindServiceUpdateGrind_RejectsInvalidSchedule(t *testing.T) {
+	t.Parallel()
+
+	grindRepo := new(mocks.MockGrindRepository)
+	partRepo := new(mocks.MockParticipationRepository)
+	grindRepo.On("FindById", "g1").Return(&entities.Grind{
+		ID:        "g1",
+		Duration:  7,
+		StartDate: time.Now().UTC().AddDate(0, 0, 7),
+		State:     entities.GrindStateRecruiting,
+	}, nil)
+	partRepo.On("FindByGrindID", "g1").Return([]*entities.Participation{{UserID: "u1", GrindID: "g1"}}, nil)
+
+	svc := NewGrindService(nil, grindRepo, new(mocks.MockUserRepository),
+		new(mocks.MockHabitTaskRepository), partRepo, new(mocks.MockMessageRepository), new(mocks.MockStakeChangeRepository), new(mocks.MockRoadmapRepository))
+
+	for _, schedule := range []*dto.ScheduleDTO{
+		{Kind: "weekly_target", TimesPerWeek: 9},
+		{Kind: "dates", Dates: []string{"next tuesday"}},
+		{Kind: "dates", Dates: []string{"2001-01-01"}},
+	} {
+		_, err := svc.UpdateGrind(dto.UpdateGrindDTO{GrindID: "g1", UserID: "u1", Schedule: schedule})
+		if !errors.Is(err, config.ErrInvalidSchedule) {
+			t.Fatalf("expected ErrInvalidSchedule for %+v, got %v", schedule, err)
+		}
+	}
+	grindRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func roadmapTestCatalog() []entities.RoadmapProblem {
+	return []entities.RoadmapProblem{
+		{ID: 1, Slug: "two-sum", Tag: "Arrays & Hashing"},
+		{ID: 49, Slug: "group-anagrams", Tag: "Arrays & Hashing"},
+		{ID: 125, Slug: "valid-palindrome", Tag: "Two Pointers"},
+		{ID: 15, Slug: "3sum", Tag: "Two Pointers"},
+		{ID: 20, Slug: "valid-parentheses", Tag: "Stack"},
+		{ID: 155, Slug: "min-stack", Tag: "Stack"},
+		{ID: 704, Slug: "binary-search", Tag: "Binary Search"},
+	}
+}
+
+func TestGrindServiceUpdateGrind_RoadmapProblemsFollowSchedule(t *testing.T) {
+	t.Parallel()
+
+	// the Saturday at least a week from now, so the grind has not started anywhere
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	start := today.AddDate(0, 0, 7+(13-int(today.Weekday()))%7)
+	catalog := roadmapTestCatalog()
+	grind := &entities.Grind{
+		ID: "g1", Duration: 7, StartDate: start, State: entities.GrindStateRecruiting,
+		PenaltyPolicy: entities.DefaultPenaltyPolicy(7, 70),
+		Roadmap:       &entities.Roadmap{Name: entities.RoadmapNeetcode250, Order: entities.RoadmapOrderTag},
+	}
+	tasks := make([]*entities.HabitTask, 0, 7)
+	for i := 0; i < 7; i++ {
+		task := &entities.HabitTask{ID: fmt.Sprintf("t%d", i), Date: start.AddDate(0, 0, i)}
+		task.AssignProblem(entities.RoadmapNeetcode250, catalog[i])
+		tasks = append(tasks, task)
+	}
+
+	grindRepo := new(mocks.MockGrindRepository)
+	userRepo := new(mocks.MockUserRepository)
+	habitTaskRepo := new(mocks.MockHabitTaskRepository)
+	partRepo := new(mocks.MockParticipationRepository)
+	roadmapRepo := new(mocks.MockRoadmapRepository)
+
+	grindRepo.On("FindById", "g1").Return(grind, nil)
+	grindRepo.On("Update", grind).Return(nil)
+	partRepo.On("FindByGrindID", "g1").Return([]*entities.Participation{{UserID: "u1", GrindID: "g1"}}, nil)
+	userRepo.On("FindByGrindID", "g1").Return([]entities.User{{ID: "u1", Timezone: "UTC"}}, nil)
+	roadmapRepo.On("FindProblems", entities.RoadmapNeetcode250).Return(catalog, nil)
+	habitTaskRepo.On("FindByGrindIDAndUserID", "g1", "u1").Return(tasks, nil)
+	habitTaskRepo.On("DeleteByIDs", []string{"t0", "t1"}).Return(nil)
+	habitTaskRepo.On("Update", mock.AnythingOfType("*entities.HabitTask")).Return(nil)
+	habitTaskRepo.On("CreateBatch", []*entities.HabitTask{}).Return(nil)
+	habitTaskRepo.On("FindByGrindIDAndParticipantID", "g1", "u1").Return([]entities.HabitTask{}, nil)
+
+	svc := NewGrindService(nil, grindRepo, userRepo, habitTaskRepo, partRepo, new(mocks.MockMessageRepository), new(mocks.MockStakeChangeRepository), roadmapRepo)
+
+	_, err := svc.UpdateGrind(dto.UpdateGrindDTO{GrindID: "g1", UserID: "u1", Schedule: &dto.ScheduleDTO{Kind: "weekdays"}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	// the weekend tasks are gone and Monday to Friday now start from the first problem
+	for i, task := range tasks[2:] {
+		if got := task.AssignedProblemSlug(); got != catalog[i].Slug {
+			t.Fatalf("expected %s to be assigned %s, got %s", task.ID, catalog[i].Slug, got)
+		}
+	}
+	habitTaskRepo.AssertNumberOfCalls(t, "Update", 5)
+}
+
+func TestGrindServiceUpdateGrind_RejectsMoreHabitDaysThanRoadmapProblems(t *testing.T) {
+	t.Parallel()
+
+	grindRepo := new(mocks.MockGrindRepository)
+	partRepo := new(mocks.MockParticipationRepository)
+	roadmapRepo := new(mocks.MockRoadmapRepository)
+	grindRepo.On("FindById", "g1").Return(&entities.Grind{
+		ID:        "g1",
+		Duration:  2,
+		StartDate: time.Now().UTC().AddDate(0, 0, 7),
+		State:     entities.GrindStateRecruiting,
+		Roadmap:   &entities.Roadmap{Name: entities.RoadmapNeetcode250, Order: entities.RoadmapOrderCustom, Slugs: []string{"two-sum", "3sum"}},
+	}, nil)
+	partRepo.On("FindByGrindID", "g1").Return([]*entities.Participation{{UserID: "u1", GrindID: "g1"}}, nil)
+	roadmapRepo.On("FindProblems", entities.RoadmapNeetcode250).Return(roadmapTestCatalog(), nil)
+
+	svc := NewGrindService(nil, grindRepo, new(mocks.MockUserRepository),
+		new(mocks.MockHabitTaskRepository), partRepo, new(mocks.MockMessageRepository), new(mocks.MockStakeChangeRepository), roadmapRepo)
+
+	duration := 3
+	_, err := svc.UpdateGrind(dto.UpdateGrindDTO{GrindID: "g1", UserID: "u1", Duration: &duration})
+	if !errors.Is(err, config.ErrInvalidRoadmap) {
+		t.Fatalf("expected ErrInvalidRoadmap, got %v", err)
+	}
+	grindRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestGrindServiceUpdateGrind_SoloStakeChangeAppliesDirectly(t *testing.T) {
+	t.Parallel()
+
+	grind := &entities.Grind{
+		ID:            "g1",
+		Duration:      3,
+		Budget:        50,
+		StartDate:     time.Now().UTC().AddDate(0, 0, 7),
+		State:         entities.GrindStateDraft,
+		PenaltyPolicy: entities.DefaultPenaltyPolicy(3, 50),
+	}
+	grindRepo := new(mocks.MockGrindRepository)
+	userRepo := new(mocks.MockUserRepository)
+	habitTaskRepo := new(mocks.MockHabitTaskRepository)
+	partRepo := new(mocks.MockParticipationRepository)
+	stakeChangeRepo := new(mocks.MockStakeChangeRepository)
+
+	grindRepo.On("FindById", "g1").Return(grind, nil)
+	grindRepo.On("Update", grind).Return(nil)
+	partRepo.On("FindByGrindID", "g1").Return([]*entities.Participation{{UserID: "u1", GrindID: "g1"}}, nil)
+	userRepo.On("FindByGrindID", "g1").Return([]entities.User{{ID: "u1", Timezone: "UTC"}}, nil)
+	habitTaskRepo.On("FindByGrindIDAndParticipantID", "g1", "u1").Return([]entities.HabitTask{}, nil)
+
+	svc := NewGrindService(nil, grindRepo, userRepo, habitTaskRepo, partRepo, new(mocks.MockMessageRepository), stakeChangeRepo, new(mocks.MockRoadmapRepository))
+
+	budget := 80
+	result, err := svc.UpdateGrind(dto.UpdateGrindDTO{GrindID: "g1", UserID: "u1", Budget: &budget})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.PendingStakeChange != nil || grind.Budget != 80 {
+		t.Fatalf("expected budget 80 applied directly, got budget %d pending %+v", grind.Budget, result.PendingStakeChange)
+	}
+	stakeChangeRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestGrindServiceUpdateGrind_GroupStakeChangeNeedsConsent(t *testing.T) {
+	t.Parallel()
+
+	grind := &entities.Grind{
+		ID:            "g1",
+		Duration:      3,
+		Budget:        50,
+		StartDate:     time.Now().UTC().AddDate(0, 0, 7),
+		State:         entities.GrindStateRecruiting,
+		PenaltyPolicy: entities.DefaultPenaltyPolicy(3, 50),
+	}
+	grindRepo := new(mocks.MockGrindRepository)
+	userRepo := new(mocks.MockUserRepository)
+	habitTaskRepo := new(mocks.MockHabitTaskRepository)
+	partRepo := new(mocks.MockParticipationRepository)
+	msgRepo := new(mocks.MockMessageRepository)
+	stakeChangeRepo := new(mocks.MockStakeChangeRepository)
+
+	previous, _ := entities.NewStakeChange("g1", "u2", 60, entities.DefaultPenaltyPolicy(3, 50))
+	grindRepo.On("FindById", "g1").Return(grind, nil)
+	partRepo.On("FindByGrindID", "g1").Return([]*entities.Participation{
+		{UserID: "u1", GrindID: "g1"},
+		{UserID: "u2", GrindID: "g1"},
+		{UserID: "u3", GrindID: "g1", Quitted: true},
+	}, nil)
+	userRepo.On("FindByGrindID", "g1").Return([]entities.User{{ID: "u1", Timezone: "UTC"}, {ID: "u2", Timezone: "UTC"}}, nil)
+	userRepo.On("FindById", "u1").Return(&entities.User{ID: "u1", Username: "amy"}, nil)
+	userRepo.On("FindById", "u2").Return(&entities.User{ID: "u2", Username: "bob", Locale: "en"}, nil)
+	habitTaskRepo.On("FindByGrindIDAndParticipantID", "g1", "u1").Return([]entities.HabitTask{}, nil)
+	stakeChangeRepo.On("FindPendingByGrindID", "g1").Return(previous, nil)
+	stakeChangeRepo.On("Update", previous).Return(nil)
+	stakeChangeRepo.On("Create", mock.AnythingOfType("*entities.StakeChange")).Return(nil)
+	msgRepo.On("Create", mock.AnythingOfType("*entities.Message")).Return(nil)
+
+	svc := NewGrindService(nil, grindRepo, userRepo, habitTaskRepo, partRepo, msgRepo, stakeChangeRepo, new(mocks.MockRoadmapRepository))
+
+	budget := 100
+	result, err := svc.UpdateGrind(dto.UpdateGrindDTO{GrindID: "g1", UserID: "u1", Budget: &budget})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if grind.Budget != 50 {
+		t.Fatalf("expected the budget to wait for consent, got %d", grind.Budget)
+	}
+	if result.PendingStakeChange == nil || result.PendingStakeChange.Budget != 100 || result.PendingStakeChange.Status != "pending" {
+		t.Fatalf("expected a pending change to budget 100, got %+v", result.PendingStakeChange)
+	}
+	if previous.Status != entities.StakeChangeSuperseded {
+		t.Fatalf("expected the earlier proposal to be superseded, got %s", previous.Status)
+	}
+	grindRepo.AssertNotCalled(t, "Update", mock.Anything)
+	// only u2 is asked; u1 proposed it and u3 quit
+	msgRepo.AssertNumberOfCalls(t, "Create", 1)
+	msg := msgRepo.Calls[0].Arguments.Get(0).(*entities.Message)
+	if msg.ReceiverID != "u2" || msg.Type != "stake_change" {
+		t.Fatalf("expected a stake_change message to u2, got %+v", msg)
+	}
+	if msg.Content != "amy proposed a new stake: budget 100, flat penalty of 16" {
+		t.Fatalf("expected the proposal to name the proposer, got %q", msg.Content)
+	}
+}
+
+func TestGrindServiceApproveStakeChange_LastApprovalApplies(t *testing.T) {
+	t.Parallel()
+
+	grind := &entities.Grind{
+		ID:            "g1",
+		Duration:      3,
+		Budget:        50,
+		StartDate:     time.Now().UTC().AddDate(0, 0, 7),
+		State:         entities.GrindStateRecruiting,
+		PenaltyPolicy: entities.DefaultPenaltyPolicy(3, 50),
+	}
+	change, _ := entities.NewStakeChange("g1", "u1", 100, entities.DefaultPenaltyPolicy(3, 50))
+	grindRepo := new(mocks.MockGrindRepository)
+	partRepo := new(mocks.MockParticipationRepository)
+	stakeChangeRepo := new(mocks.MockStakeChangeRepository)
+
+	grindRepo.On("FindById", "g1").Return(grind, nil)
+	grindRepo.On("Update", grind).Return(nil)
+	partRepo.On("FindByGrindID", "g1").Return([]*entities.Participation{
+		{UserID: "u1", GrindID: "g1"},
+		{UserID: "u2", GrindID: "g1"},
+	}, nil)
+	stakeChangeRepo.On("FindByID", change.ID).Return(change, nil)
+	stakeChangeRepo.On("FindByIDForUpdate", change.ID).Return(change, nil)
+	stakeChangeRepo.On("Update", change).Return(nil)
+
+	svc := NewGrindService(nil, grindRepo, new(mocks.MockUserRepository),
+		new(mocks.MockHabitTaskRepository), partRepo, new(mocks.MockMessageRepository), stakeChangeRepo, new(mocks.MockRoadmapRepository))
+
+	changeDTO, err := svc.ApproveStakeChange(dto.RespondStakeChangeDTO{GrindID: "g1", StakeChangeID: change.ID, UserID: "u2"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if changeDTO.Status != string(entities.StakeChangeApplied) || grind.Budget != 100 {
+		t.Fatalf("expected the change applied, got status %s budget %d", changeDTO.Status, grind.Budget)
+	}
+
+	_, err = svc.ApproveStakeChange(dto.RespondStakeChangeDTO{GrindID: "g1", StakeChangeID: change.ID, UserID: "u2"})
+	if !errors.Is(err, config.ErrStakeChangeNotPending) {
+		t.Fatalf("expected ErrStakeChangeNotPending, got %v", err)
+	}
+}
+
+func TestGrindServiceApproveStakeChange_KeepsConcurrentApprovals(t *testing.T) {
+	t.Parallel()
+
+	grind := &entities.Grind{
+		ID:            "g1",
+		Duration:      3,
+		Budget:        50,
+		StartDate:     time.Now().UTC().AddDate(0, 0, 7),
+		State:         entities.GrindStateRecruiting,
+		PenaltyPolicy: entities.DefaultPenaltyPolicy(3, 50),
+	}
+	// u2 read the change before u3's approval was committed
+	read, _ := entities.NewStakeChange("g1", "u1", 100, entities.DefaultPenaltyPolicy(3, 50))
+	locked := *read
+	locked.ApprovedBy = append([]string{}, read.ApprovedBy...)
+	locked.ApprovedBy = append(locked.ApprovedBy, "u3")
+	grindRepo := new(mocks.MockGrindRepository)
+	partRepo := new(mocks.MockParticipationRepository)
+	stakeChangeRepo := new(mocks.MockStakeChangeRepository)
+
+	grindRepo.On("FindById", "g1").Return(grind, nil)
+	grindRepo.On("Update", grind).Return(nil)
+	partRepo.On("FindByGrindID", "g1").Return([]*entities.Participation{
+		{UserID: "u1", GrindID: "g1"},
+		{UserID: "u2", GrindID: "g1"},
+		{UserID: "u3", GrindID: "g1"},
+	}, nil)
+	stakeChangeRepo.On("FindByID", read.ID).Return(read, nil)
+	stakeChangeRepo.On("FindByIDForUpdate", read.ID).Return(&locked, nil)
+	stakeChangeRepo.On("Update", &locked).Return(nil)
+
+	svc := NewGrindService(nil, grindRepo, new(mocks.MockUserRepository),
+		new(mocks.MockHabitTaskRepository), partRepo, new(mocks.MockMessageRepository), stakeChangeRepo, new(mocks.MockRoadmapRepository))
+
+	changeDTO, err := svc.ApproveStakeChange(dto.RespondStakeChangeDTO{GrindID: "g1", StakeChangeID: read.ID, UserID: "u2"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if changeDTO.Status != string(entities.StakeChangeApplied) || grind.Budget != 100 {
+		t.Fatalf("expected u3's approval kept and the change applied, got status %s approved by %v", changeDTO.Status, locked.ApprovedBy)
+	}
+}
+
+func TestGrindServiceRejectStakeChange_NotifiesProposer(t *testing.T) {
+	t.Parallel()
+
+	grind := &entities.Grind{ID: "g1", Duration: 3, Budget: 50, State: entities.GrindStateRecruiting}
+	change, _ := entities.NewStakeChange("g1", "u1", 100, entities.DefaultPenaltyPolicy(3, 50))
+	grindRepo := new(mocks.MockGrindRepository)
+	partRepo := new(mocks.MockParticipationRepository)
+	msgRepo := new(mocks.MockMessageRepository)
+	stakeChangeRepo := new(mocks.MockStakeChangeRepository)
+
+	grindRepo.On("FindById", "g1").Return(grind, nil)
+	partRepo.On("FindByGrindID", "g1").Return([]*entities.Participation{
+		{UserID: "u1", GrindID: "g1"},
+		{UserID: "u2", GrindID: "g1"},
+	}, nil)
+	stakeChangeRepo.On("FindByID", change.ID).Return(change, nil)
+	stakeChangeRepo.On("FindByIDForUpdate", change.ID).Return(change, nil)
+	stakeChangeRepo.On("Update", change).Return(nil)
+	msgRepo.On("Create", mock.AnythingOfType("*entities.Message")).Return(nil)
+	userRepo := new(mocks.MockUserRepository)
+	userRepo.On("FindById", "u1").Return(&entities.User{ID: "u1", Username: "amy", Locale: "zh-TW"}, nil)
+	userRepo.On("FindById", "u2").Return(&entities.User{ID: "u2", Username: "bob", Locale: "en"}, nil)
+
+	svc := NewGrindService(nil, grindRepo, userRepo,
+		new(mocks.MockHabitTaskRepository), partRepo, msgRepo, stakeChangeRepo, new(mocks.MockRoadmapRepository))
+
+	changeDTO, err := svc.RejectStakeChange(dto.RespondStakeChangeDTO{GrindID: "g1", StakeChangeID: change.ID, UserID: "u2"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if changeDTO.Status != string(entities.StakeChangeRejected) || changeDTO.RejectedBy != "u2" || grind.Budget != 50 {
+		t.Fatalf("expected a rejected change and unchanged budget, got %+v budget %d", changeDTO, grind.Budget)
+	}
+	msg := msgRepo.Calls[0].Arguments.Get(0).(*entities.Message)
+	if msg.ReceiverID != "u1" {
+		t.Fatalf("expected the proposer to be notified, got %s", msg.ReceiverID)
+	}
+	if msg.Content != "bob 拒絕了你的賭注變更" {
+		t.Fatalf("expected the rejection in the proposer's locale, got %q", msg.Content)
+	}
+
+	_, err = svc.RejectStakeChange(dto.RespondStakeChangeDTO{GrindID: "other", StakeChangeID: change.ID, UserID: "u2"})
+	if !errors.Is(err, config.ErrStakeChangeNotFound) {
+		t.Fatalf("expected ErrStakeChangeNotFound for a mismatched grind, got %v", err)
+	}
+}
+
+func TestGrindServicePublishGrind(t *testing.T) {
+	t.Parallel()
+
+	grind := &entities.Grind{ID: "g1", Duration: 3, State: entities.GrindStateDraft}
+	grindRepo := new(mocks.MockGrindRepository)
+	userRepo := new(mocks.MockUserRepository)
+	habitTaskRepo := new(mocks.MockHabitTaskRepository)
+	partRepo := new(mocks.MockParticipationRepository)
+
+	grindRepo.On("FindById", "g1").Return(grind, nil)
+	grindRepo.On("UpdateState", grind, entities.GrindStateDraft).Return(true, nil)
+	partRepo.On("FindByUserAndGrind", "u1", "g1").Return(&entities.Participation{UserID: "u1", GrindID: "g1"}, nil)
+	partRepo.On("FindByGrindID", "g1").Return([]*entities.Participation{}, nil)
+	habitTaskRepo.On("FindByGrindIDAndParticipantID", "g1", "u1").Return([]entities.HabitTask{}, nil)
+	userRepo.On("FindByGrindID", "g1").Return([]entities.User{{ID: "u1"}}, nil)
+
+	svc := NewGrindService(nil, grindRepo, userRepo, habitTaskRepo, partRepo, new(mocks.MockMessageRepository), new(mocks.MockStakeChangeRepository), new(mocks.MockRoadmapRepository))
+
+	grindDTO, err := svc.PublishGrind(dto.TransitionGrindDTO{GrindID: "g1", UserID: "u1"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if grindDTO.State != string(entities.GrindStateRecruiting) || grindDTO.PublishedAt == nil {
+		t.Fatalf("expected a published recruiting grind, got state %q published at %v", grindDTO.State, grindDTO.PublishedAt)
+	}
+}
+
+func TestGrindServiceCancelGrind_LostRace(t *testing.T) {
+	t.Parallel()
+
+	grind := &entities.Grind{ID: "g1", Duration: 3, State: entities.GrindStateRecruiting}
+	grindRepo := new(mocks.MockGrindRepository)
+	partRepo := new(mocks.MockParticipationRepository)
+
+	grindRepo.On("FindById", "g1").Return(grind, nil)
+	grindRepo.On("UpdateState", grind, entities.GrindStateRecruiting).Return(false, nil)
+	partRepo.On("FindByUserAndGrind", "u1", "g1").Return(&entities.Participation{UserID: "u1", GrindID: "g1"}, nil)
+
+	svc := NewGrindService(nil, grindRepo, new(mocks.MockUserRepository),
+		new(mocks.MockHabitTaskRepository), partRepo, new(mocks.MockMessageRepository), new(mocks.MockStakeChangeRepository), new(mocks.MockRoadmapRepository))
+
+	_, err := svc.CancelGrind(dto.TransitionGrindDTO{GrindID: "g1", UserID: "u1"})
+	if !errors.Is(err, config.ErrGrindStateChanged) {
+		t.Fatalf("expected ErrGrindStateChanged, got %v", err)
+	}
+}
+
+func TestGrindServiceGetAllUserGrinds_InvalidState(t *testing.T) {
+	t.Parallel()
+
+	svc := NewGrindService(nil, new(mocks.MockGrindRepository), new(mocks.MockUserRepository),
+		new(mocks.MockHabitTaskRepository), new(mocks.MockParticipationRepository), new(mocks.MockMessageRepository), new(mocks.MockStakeChangeRepository), new(mocks.MockRoadmapRepository))
+
+	_, err := svc.GetAllUserGrinds(dto.GetAllUserGrindsDTO{UserID: "u1", States: []string{"active", "paused"}})
+	if !errors.Is(err, config.ErrInvalidGrindState) {
+		t.Fatalf("expected ErrInvalidGrindState, got %v", err)
+	}
+}
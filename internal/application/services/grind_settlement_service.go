@@ -0,0 +1,640 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/repositories"
+	"gorm.io/gorm"
+)
+
+const (
+	settlementChargeOperation = "grind_settlement_charge"
+	settlementPayoutOperation = "grind_settlement_payout"
+
+	// maxSettlementAttempts bounds how often one charge or payout is retried before the
+	// settlement gives up on it and moves on.
+	maxSettlementAttempts = 3
+	// settlementInFlightTimeout matches ReconcileSettlements: a charge still pending after
+	// this long is treated as a failed attempt, and a payout is reconciled with the provider.
+	settlementInFlightTimeout = 10 * time.Minute
+)
+
+// GrindSettlementService settles a grind once it has ended for every participant:
+//  1. every Participation is finalized, so its penalty can no longer change;
+//  2. each loser (a participant with a penalty) is charged via ChargeWithIdempotency;
+//  3. once collection is over, the collected pot is split evenly among the completers
+//     (participants who stayed in with no penalty) via PayBack.
+//
+// Every charge and payout attempt is a PaymentSettlement linked to the grind, and the
+// next step is always derived from those rows, so SettleGrind can be re-run after a
+// partial failure: settled legs are skipped, failed legs are retried under a fresh
+// idempotency key and legs still in flight are left alone. PayBack takes no idempotency
+// key, so a payout still pending after settlementInFlightTimeout is never retried: it is
+// reconciled with the provider, and one whose outcome cannot be learned is marked
+// SettlementStatusNeedsReview for an operator.
+type GrindSettlementService struct {
+	db                    *gorm.DB
+	grindRepo             repositories.GrindRepository
+	userRepo              repositories.UserRepository
+	participationRepo     repositories.ParticipationRepository
+	habitTaskRepo         repositories.HabitTaskRepository
+	paymentMethodInfoRepo repositories.PaymentMethodInfoRepository
+	settlementRepo        repositories.PaymentSettlementRepository
+	payments              PaymentServiceCore
+	now                   func() time.Time
+}
+
+func NewGrindSettlementService(
+	db *gorm.DB,
+	grindRepo repositories.GrindRepository,
+	userRepo repositories.UserRepository,
+	participationRepo repositories.ParticipationRepository,
+	habitTaskRepo repositories.HabitTaskRepository,
+	paymentMethodInfoRepo repositories.PaymentMethodInfoRepository,
+	settlementRepo repositories.PaymentSettlementRepository,
+	payments PaymentServiceCore,
+) *GrindSettlementService {
+	return &GrindSettlementService{
+		db:                    db,
+		grindRepo:             grindRepo,
+		userRepo:              userRepo,
+		participationRepo:     participationRepo,
+		habitTaskRepo:         habitTaskRepo,
+		paymentMethodInfoRepo: paymentMethodInfoRepo,
+		settlementRepo:        settlementRepo,
+		payments:              payments,
+		now:                   time.Now,
+	}
+}
+
+// SettleDueGrinds settles every grind returned by FindDuedGrinds. Grinds that have not
+// ended everywhere yet, or still wait for the missed-day evaluation, are skipped silently
+// and picked up by a later run; other failures are reported per grind.
+func (s *GrindSettlementService) SettleDueGrinds() (*dto.SettleDueGrindsResultDTO, error) {
+	grinds, err := s.grindRepo.FindDuedGrinds()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.SettleDueGrindsResultDTO{
+		Reports: []dto.GrindSettlementReportDTO{},
+		Errors:  map[string]string{},
+	}
+	for _, grind := range grinds {
+		report, err := s.SettleGrind(dto.SettleGrindDTO{GrindID: grind.ID})
+		if errors.Is(err, config.ErrGrindNotEnded) || errors.Is(err, config.ErrSettlementNotReady) {
+			continue
+		}
+		if err != nil {
+			result.Errors[grind.ID] = err.Error()
+			continue
+		}
+		result.Reports = append(result.Reports, *report)
+	}
+	return result, nil
+}
+
+// SettleGrind runs (or resumes) the settlement of one grind and returns where it stands.
+// Provider failures do not fail the call; they are recorded on the leg and show up in the
+// report, and the leg is retried by the next call.
+func (s *GrindSettlementService) SettleGrind(request dto.SettleGrindDTO) (*dto.GrindSettlementReportDTO, error) {
+	grind, err := s.grindRepo.FindById(request.GrindID)
+	if err != nil || grind == nil {
+		return nil, config.ErrGrindNotFound
+	}
+	switch grind.State {
+	case entities.GrindStateActive, entities.GrindStateSettling:
+	case entities.GrindStateSettled:
+		participations, err := s.participationRepo.FindByGrindID(grind.ID)
+		if err != nil {
+			return nil, err
+		}
+		return s.loadReport(grind.ID, participations)
+	default:
+		return nil, &entities.GrindStateError{Op: "settle", State: grind.State}
+	}
+
+	participants, err := s.userRepo.FindByGrindID(grind.ID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	users := make(map[string]*entities.User, len(participants))
+	for i := range participants {
+		if now.Before(grind.EndDateIn(participants[i].Location())) {
+			return nil, config.ErrGrindNotEnded
+		}
+		users[participants[i].ID] = &participants[i]
+	}
+
+	participations, err := s.participationRepo.FindByGrindID(grind.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 1: freeze the final participation state.
+	if err := s.finalizeParticipations(grind, participations, now); err != nil {
+		return nil, err
+	}
+
+	// Step 2: charge the losers.
+	plan, err := s.loadSettlementPlan(grind.ID, participations, now)
+	if err != nil {
+		return nil, err
+	}
+	for _, leg := range plan.charges {
+		if !leg.history.due(now) {
+			continue
+		}
+		if err := s.chargeLoser(grind.ID, users[leg.userID], leg); err != nil {
+			return nil, fmt.Errorf("charging %s: %w", leg.userID, err)
+		}
+	}
+
+	// Step 3: pay the pot out to the completers once nothing is left to collect.
+	plan, err = s.loadSettlementPlan(grind.ID, participations, now)
+	if err != nil {
+		return nil, err
+	}
+	if plan.collecting {
+		return plan.report(grind.ID, now), nil
+	}
+	if err := s.reconcileStalePayouts(plan, now); err != nil {
+		return nil, err
+	}
+	plan, err = s.loadSettlementPlan(grind.ID, participations, now)
+	if err != nil {
+		return nil, err
+	}
+	for _, leg := range plan.payouts {
+		if leg.amount <= 0 || !leg.history.due(now) {
+			continue
+		}
+		if err := s.payOutCompleter(grind.ID, users[leg.userID], leg); err != nil {
+			return nil, fmt.Errorf("paying out %s: %w", leg.userID, err)
+		}
+	}
+
+	plan, err = s.loadSettlementPlan(grind.ID, participations, now)
+	if err != nil {
+		return nil, err
+	}
+	report := plan.report(grind.ID, now)
+
+	// Step 4: close the grind once every leg has been resolved, one way or the other.
+	if report.Status == dto.GrindSettlementSettled || report.Status == dto.GrindSettlementIncomplete {
+		if err := transitionGrind(s.grindRepo, grind, entities.GrindStateSettled, now); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// GetSettlementReport returns the settlement report of a grind without moving any money.
+// Only participants of the grind can read it.
+func (s *GrindSettlementService) GetSettlementReport(request dto.GetGrindSettlementDTO) (*dto.GrindSettlementReportDTO, error) {
+	grind, err := s.grindRepo.FindById(request.GrindID)
+	if err != nil || grind == nil {
+		return nil, config.ErrGrindNotFound
+	}
+
+	participations, err := s.participationRepo.FindByGrindID(grind.ID)
+	if err != nil {
+		return nil, err
+	}
+	isParticipant := false
+	for _, p := range participations {
+		if p.UserID == request.UserID {
+			isParticipant = true
+			break
+		}
+	}
+	if !isParticipant {
+		return nil, config.ErrUserIsNotParticipant
+	}
+
+	return s.loadReport(grind.ID, participations)
+}
+
+// loadReport builds the current report from the persisted settlement legs.
+func (s *GrindSettlementService) loadReport(grindID string, participations []*entities.Participation) (*dto.GrindSettlementReportDTO, error) {
+	now := s.now()
+	plan, err := s.loadSettlementPlan(grindID, participations, now)
+	if err != nil {
+		return nil, err
+	}
+	return plan.report(grindID, now), nil
+}
+
+// finalizeParticipations freezes every participation of the grind and moves the grind
+// from active to settling in one transaction. It refuses while a non-quitter still has a
+// task that is neither completed nor marked missed, because the penalty would then be
+// settled before the last day was counted.
+func (s *GrindSettlementService) finalizeParticipations(grind *entities.Grind, participations []*entities.Participation, now time.Time) error {
+	open := make([]*entities.Participation, 0, len(participations))
+	quitted := make(map[string]bool, len(participations))
+	for _, p := range participations {
+		quitted[p.UserID] = p.Quitted
+		if !p.Finalized {
+			open = append(open, p)
+		}
+	}
+	if len(open) == 0 && grind.State == entities.GrindStateSettling {
+		return nil
+	}
+
+	// Local midnights of the grind's days lie between day-14h (UTC+14) and day+12h (UTC-12).
+	tasks, err := s.habitTaskRepo.FindByGrindIDInRange(grind.ID, grind.DayStart(0, time.UTC).Add(-14*time.Hour), grind.EndDate().Add(13*time.Hour))
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		if task.IsOpen() && !quitted[task.UserID] {
+			return config.ErrSettlementNotReady
+		}
+	}
+
+	return runInTransaction(s.db, func(tx *gorm.DB) error {
+		partRepo := getParticipationRepo(s.participationRepo, tx)
+		for _, p := range open {
+			// re-read under the lock so a penalty or quit recorded since is frozen too
+			locked, err := partRepo.FindByUserAndGrindForUpdate(p.UserID, grind.ID)
+			if err != nil {
+				return err
+			}
+			if locked.Finalize(now) {
+				if err := partRepo.Update(locked); err != nil {
+					return err
+				}
+			}
+			*p = *locked
+		}
+		if grind.State == entities.GrindStateSettling {
+			return nil
+		}
+		return transitionGrind(getGrindRepo(s.grindRepo, tx), grind, entities.GrindStateSettling, now)
+	})
+}
+
+// chargeLoser makes the next charge attempt for a loser. A provider failure is recorded on
+// the settlement by ChargeWithIdempotency and is not returned.
+func (s *GrindSettlementService) chargeLoser(grindID string, user *entities.User, leg plannedSettlementLeg) error {
+	key := settlementIdempotencyKey(grindID, "charge", leg.userID, len(leg.history)+1)
+
+	paymentMethod, err := s.paymentMethodFor(leg.userID, user)
+	if err != nil {
+		return s.recordFailedLeg(grindID, leg, settlementChargeOperation, key, err)
+	}
+
+	chargeReq, err := dto.NewChargeWithIdempotencyDTO(paymentMethod, leg.amount, settlementChargeOperation, leg.userID)
+	if err != nil {
+		return err
+	}
+	chargeReq.GrindID = grindID
+	if _, err := s.payments.ChargeWithIdempotency(chargeReq, key); err != nil {
+		fmt.Println("settlement charge failed for grind", grindID, "user", leg.userID, err)
+	}
+	return nil
+}
+
+// payOutCompleter makes the next payout attempt for a completer. PayBack does not record
+// settlements itself, so the leg is written here before the provider is called.
+func (s *GrindSettlementService) payOutCompleter(grindID string, user *entities.User, leg plannedSettlementLeg) error {
+	key := settlementIdempotencyKey(grindID, "payout", leg.userID, len(leg.history)+1)
+
+	paymentMethod, err := s.paymentMethodFor(leg.userID, user)
+	if err != nil {
+		return s.recordFailedLeg(grindID, leg, settlementPayoutOperation, key, err)
+	}
+
+	settlement := entities.NewPaymentSettlement(leg.userID, settlementPayoutOperation, key, paymentMethod.Provider, paymentMethod.ProviderPaymentMethodID, leg.amount)
+	settlement.GrindID = grindID
+	settlement.Reference.Network = paymentMethod.Network
+	settlement, err = s.settlementRepo.Create(settlement)
+	if err != nil {
+		// most likely a concurrent run created the same attempt; it owns the payout
+		fmt.Println("settlement payout skipped for grind", grindID, "user", leg.userID, err)
+		return nil
+	}
+
+	payBackReq, err := dto.NewPayBackDTO(payoutDestination(paymentMethod), leg.amount)
+	if err == nil {
+		var result *dto.PayBackResultDTO
+		result, err = s.payments.PayBack(payBackReq)
+		if err == nil {
+			settlement.Status = entities.SettlementStatus(result.Status)
+			if settlement.Status == "" {
+				settlement.Status = entities.SettlementStatusCaptured
+			}
+			settlement.Reference.ProviderReference = result.ProviderReference
+		}
+	}
+	if err != nil {
+		settlement.Status = entities.SettlementStatusFailed
+		settlement.LastError = err.Error()
+	}
+	_, err = s.settlementRepo.Update(settlement)
+	return err
+}
+
+// reconcileStalePayouts asks the provider about every payout attempt still pending after
+// settlementInFlightTimeout. A known outcome is recorded on the attempt, so a failed
+// payout may be retried; an attempt that never got a provider reference, e.g. because the
+// process stopped between PayBack and recording its result, or whose status cannot be
+// read is marked for manual review, since paying again could pay the completer twice.
+func (s *GrindSettlementService) reconcileStalePayouts(plan *settlementPlan, now time.Time) error {
+	for _, leg := range plan.payouts {
+		for i := range leg.history {
+			attempt := &leg.history[i]
+			if attempt.Status != entities.SettlementStatusPending || now.Sub(attempt.CreatedAt) < settlementInFlightTimeout {
+				continue
+			}
+
+			switch status, err := s.payoutStatus(attempt); {
+			case err != nil:
+				attempt.Status = entities.SettlementStatusNeedsReview
+				attempt.LastError = err.Error()
+			case status == entities.SettlementStatusPending || status == entities.SettlementStatusAuthorized:
+				continue // still moving at the provider
+			default:
+				attempt.Status = status
+			}
+			if _, err := s.settlementRepo.Update(attempt); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// payoutStatus asks the provider where a payout attempt stands.
+func (s *GrindSettlementService) payoutStatus(attempt *entities.PaymentSettlement) (entities.SettlementStatus, error) {
+	if attempt.Reference.ProviderReference == "" {
+		return "", errors.New("payout outcome unknown: no provider reference was recorded")
+	}
+	result, err := s.payments.PayBackStatus(dto.PayBackStatusDTO{ProviderReference: attempt.Reference.ProviderReference})
+	if err != nil {
+		return "", fmt.Errorf("payout outcome unknown: %w", err)
+	}
+	return entities.SettlementStatus(result.Status), nil
+}
+
+// recordFailedLeg stores an attempt that could not reach the provider, so it counts
+// towards the leg's retries and shows up in the report.
+func (s *GrindSettlementService) recordFailedLeg(grindID string, leg plannedSettlementLeg, operation string, key string, cause error) error {
+	settlement := entities.NewPaymentSettlement(leg.userID, operation, key, "", "", leg.amount)
+	settlement.GrindID = grindID
+	settlement.Status = entities.SettlementStatusFailed
+	settlement.LastError = cause.Error()
+	_, err := s.settlementRepo.Create(settlement)
+	return err
+}
+
+// paymentMethodFor returns the user's default payment method, or the first one on file.
+func (s *GrindSettlementService) paymentMethodFor(userID string, user *entities.User) (entities.PaymentMethodInfo, error) {
+	paymentMethods, err := s.paymentMethodInfoRepo.FindByUserID(userID)
+	if err != nil {
+		return entities.PaymentMethodInfo{}, err
+	}
+	if len(paymentMethods) == 0 {
+		return entities.PaymentMethodInfo{}, config.ErrNoPaymentMethod
+	}
+	if user != nil {
+		for _, paymentMethod := range paymentMethods {
+			if paymentMethod.ProviderPaymentMethodID == user.DefaultPaymentMethodID {
+				return paymentMethod, nil
+			}
+		}
+	}
+	return paymentMethods[0], nil
+}
+
+func (s *GrindSettlementService) loadSettlementPlan(grindID string, participations []*entities.Participation, now time.Time) (*settlementPlan, error) {
+	settlements, err := s.settlementRepo.FindByGrindID(grindID)
+	if err != nil {
+		return nil, err
+	}
+	return newSettlementPlan(participations, settlements, now), nil
+}
+
+// payoutDestination is where a completer's share is sent: the wallet for wallet methods,
+// the provider's payer reference otherwise.
+func payoutDestination(paymentMethod entities.PaymentMethodInfo) string {
+	if paymentMethod.WalletAddress != "" {
+		return paymentMethod.WalletAddress
+	}
+	return paymentMethod.ProviderCustomerID
+}
+
+// settlementIdempotencyKey is deterministic per attempt, so concurrent runs making the
+// same attempt collide on the idempotency claim instead of moving money twice.
+func settlementIdempotencyKey(grindID string, leg string, userID string, attempt int) string {
+	return fmt.Sprintf("grind:%s:%s:%s:%d", grindID, leg, userID, attempt)
+}
+
+// settlementAttempts is every attempt at one user's charge or payout, oldest first.
+type settlementAttempts []entities.PaymentSettlement
+
+func (a settlementAttempts) latest() *entities.PaymentSettlement {
+	if len(a) == 0 {
+		return nil
+	}
+	return &a[len(a)-1]
+}
+
+// needsReview returns the attempt an operator must check before the leg can move on.
+func (a settlementAttempts) needsReview() *entities.PaymentSettlement {
+	for i := range a {
+		if a[i].Status == entities.SettlementStatusNeedsReview {
+			return &a[i]
+		}
+	}
+	return nil
+}
+
+func (a settlementAttempts) settled() *entities.PaymentSettlement {
+	for i := range a {
+		if a[i].Status.IsSettled() {
+			return &a[i]
+		}
+	}
+	return nil
+}
+
+// inFlight reports whether an attempt may still be moving money at the provider. A
+// pending payout stays in flight until reconcileStalePayouts learns its outcome.
+func (a settlementAttempts) inFlight(now time.Time) bool {
+	for _, attempt := range a {
+		switch attempt.Status {
+		case entities.SettlementStatusPending, entities.SettlementStatusAuthorized:
+			if attempt.Operation == settlementPayoutOperation || now.Sub(attempt.CreatedAt) < settlementInFlightTimeout {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// due reports whether another attempt should be made now.
+func (a settlementAttempts) due(now time.Time) bool {
+	return a.settled() == nil && a.needsReview() == nil && !a.inFlight(now) && len(a) < maxSettlementAttempts
+}
+
+// resolved reports whether the leg is finished: settled, out of retries or handed to an
+// operator for review.
+func (a settlementAttempts) resolved(now time.Time) bool {
+	if a.settled() != nil || a.needsReview() != nil {
+		return true
+	}
+	return !a.inFlight(now) && len(a) >= maxSettlementAttempts
+}
+
+type plannedSettlementLeg struct {
+	userID  string
+	amount  int64
+	history settlementAttempts
+}
+
+func (l plannedSettlementLeg) toDTO() dto.SettlementLegDTO {
+	leg := dto.SettlementLegDTO{UserID: l.userID, AmountCents: l.amount, Attempts: len(l.history)}
+	attempt := l.history.settled()
+	if attempt == nil {
+		attempt = l.history.needsReview()
+	}
+	if attempt == nil {
+		attempt = l.history.latest()
+	}
+	if attempt != nil {
+		leg.Status = attempt.Status
+		leg.ProviderReference = attempt.Reference.ProviderReference
+		leg.LastError = attempt.LastError
+	}
+	return leg
+}
+
+// settlementPlan is the state of a grind's settlement derived from its participations
+// and the PaymentSettlement rows recorded so far.
+type settlementPlan struct {
+	charges    []plannedSettlementLeg
+	payouts    []plannedSettlementLeg // empty while collecting
+	collecting bool
+	potCents   int64
+}
+
+func newSettlementPlan(participations []*entities.Participation, settlements []entities.PaymentSettlement, now time.Time) *settlementPlan {
+	charged := map[string]settlementAttempts{}
+	paid := map[string]settlementAttempts{}
+	for _, settlement := range settlements {
+		switch settlement.Operation {
+		case settlementChargeOperation:
+			charged[settlement.UserID] = append(charged[settlement.UserID], settlement)
+		case settlementPayoutOperation:
+			paid[settlement.UserID] = append(paid[settlement.UserID], settlement)
+		}
+	}
+
+	sorted := make([]*entities.Participation, len(participations))
+	copy(sorted, participations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].UserID < sorted[j].UserID })
+
+	plan := &settlementPlan{}
+	completers := []string{}
+	for _, p := range sorted {
+		if p.TotalPenalty > 0 {
+			leg := plannedSettlementLeg{userID: p.UserID, amount: penaltyCents(p), history: charged[p.UserID]}
+			plan.charges = append(plan.charges, leg)
+			if !leg.history.resolved(now) {
+				plan.collecting = true
+			}
+			if settled := leg.history.settled(); settled != nil {
+				plan.potCents += settled.Amount
+			}
+		} else if !p.Quitted {
+			completers = append(completers, p.UserID)
+		}
+	}
+	if plan.collecting {
+		return plan
+	}
+
+	for i, share := range splitPot(plan.potCents, len(completers)) {
+		plan.payouts = append(plan.payouts, plannedSettlementLeg{userID: completers[i], amount: share, history: paid[completers[i]]})
+	}
+	return plan
+}
+
+func (p *settlementPlan) report(grindID string, now time.Time) *dto.GrindSettlementReportDTO {
+	report := &dto.GrindSettlementReportDTO{
+		GrindID:  grindID,
+		Status:   dto.GrindSettlementSettled,
+		PotCents: p.potCents,
+		Charges:  make([]dto.SettlementLegDTO, 0, len(p.charges)),
+		Payouts:  make([]dto.SettlementLegDTO, 0, len(p.payouts)),
+	}
+
+	failed := false
+	for _, leg := range p.charges {
+		report.Charges = append(report.Charges, leg.toDTO())
+		if leg.history.settled() == nil {
+			failed = true
+		}
+	}
+	if p.collecting {
+		report.Status = dto.GrindSettlementCollecting
+		return report
+	}
+
+	owed := int64(0)
+	payingOut := false
+	for _, leg := range p.payouts {
+		if leg.amount <= 0 {
+			continue
+		}
+		report.Payouts = append(report.Payouts, leg.toDTO())
+		owed += leg.amount
+		if settled := leg.history.settled(); settled != nil {
+			report.PaidOutCents += settled.Amount
+		} else if leg.history.resolved(now) {
+			failed = true
+		} else {
+			payingOut = true
+		}
+	}
+	report.UnallocatedCents = p.potCents - owed
+
+	switch {
+	case payingOut:
+		report.Status = dto.GrindSettlementPayingOut
+	case failed:
+		report.Status = dto.GrindSettlementIncomplete
+	}
+	return report
+}
+
+// penaltyCents converts a participation's penalty, kept in dollars like the grind budget,
+// to the cents the payment providers charge.
+func penaltyCents(p *entities.Participation) int64 {
+	return int64(p.TotalPenalty) * 100
+}
+
+// splitPot divides pot evenly among n completers; the first pot%n shares get one extra
+// cent so nothing is left over. It returns nil when nobody completed.
+func splitPot(pot int64, n int) []int64 {
+	if n == 0 {
+		return nil
+	}
+	shares := make([]int64, n)
+	for i := range shares {
+		shares[i] = pot / int64(n)
+		if int64(i) < pot%int64(n) {
+			shares[i]++
+		}
+	}
+	return shares
+}
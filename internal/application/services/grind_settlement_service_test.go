@@ -0,0 +1,357 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type settlementTestEnv struct {
+	svc           *GrindSettlementService
+	grindRecord   *entities.Grind
+	grind         *mocks.MockGrindRepository
+	user          *mocks.MockUserRepository
+	participation *mocks.MockParticipationRepository
+	habitTask     *mocks.MockHabitTaskRepository
+	paymentInfo   *mocks.MockStripePaymentInfoRepository
+	settlements   *inMemorySettlementRepo
+	adapter       *fakePaymentAdapter
+}
+
+// newSettlementTestEnv wires a grind that ended on 2026-04-04 (UTC) to a real Stripe
+// PaymentService backed by a fake adapter, with one card on file per participant.
+func newSettlementTestEnv(participations []*entities.Participation, tasks []*entities.HabitTask) settlementTestEnv {
+	env := settlementTestEnv{
+		grind:         new(mocks.MockGrindRepository),
+		user:          new(mocks.MockUserRepository),
+		participation: new(mocks.MockParticipationRepository),
+		habitTask:     new(mocks.MockHabitTaskRepository),
+		paymentInfo:   new(mocks.MockStripePaymentInfoRepository),
+		settlements:   newInMemorySettlementRepo(),
+		adapter:       &fakePaymentAdapter{},
+	}
+	payments := newPaymentService(nil, nil, nil, nil, newInMemoryIdempotencyRepo(), env.settlements, entities.PaymentProviderStripe, env.adapter)
+	env.svc = NewGrindSettlementService(nil, env.grind, env.user, env.participation, env.habitTask, env.paymentInfo, env.settlements, payments)
+	env.svc.now = func() time.Time { return time.Date(2026, 4, 5, 12, 0, 0, 0, time.UTC) }
+
+	grind := &entities.Grind{ID: "grind-1", Duration: 3, Budget: 30, StartDate: time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), State: entities.GrindStateActive}
+	env.grindRecord = grind
+	users := make([]entities.User, 0, len(participations))
+	for _, p := range participations {
+		users = append(users, entities.User{ID: p.UserID, Timezone: "UTC"})
+		env.participation.On("FindByUserAndGrindForUpdate", p.UserID, "grind-1").Return(p, nil).Maybe()
+		env.paymentInfo.On("FindByUserID", p.UserID).Return([]entities.PaymentMethodInfo{{
+			UserID:                  p.UserID,
+			Provider:                entities.PaymentProviderStripe,
+			ProviderCustomerID:      "cus_" + p.UserID,
+			ProviderPaymentMethodID: "pm_" + p.UserID,
+		}}, nil).Maybe()
+	}
+	env.grind.On("FindById", "grind-1").Return(grind, nil)
+	env.grind.On("UpdateState", grind, mock.Anything).Return(true, nil).Maybe()
+	env.user.On("FindByGrindID", "grind-1").Return(users, nil)
+	env.participation.On("FindByGrindID", "grind-1").Return(participations, nil)
+	env.participation.On("Update", mock.Anything).Return(nil).Maybe()
+	env.habitTask.On("FindByGrindIDInRange", "grind-1", mock.Anything, mock.Anything).Return(tasks, nil).Maybe()
+	return env
+}
+
+func (env settlementTestEnv) legs(t *testing.T) []entities.PaymentSettlement {
+	t.Helper()
+	legs, err := env.settlements.FindByGrindID("grind-1")
+	require.NoError(t, err)
+	return legs
+}
+
+func Test_GrindSettlementService_SettleGrind_ChargesLosersAndSplitsPot(t *testing.T) {
+	t.Parallel()
+
+	participations := []*entities.Participation{
+		{ID: "p-a", UserID: "a", GrindID: "grind-1"},
+		{ID: "p-b", UserID: "b", GrindID: "grind-1"},
+		{ID: "p-c", UserID: "c", GrindID: "grind-1", MissedDays: 1, FrozenDays: 1},
+		{ID: "p-loser", UserID: "loser", GrindID: "grind-1", MissedDays: 1, TotalPenalty: 10},
+		{ID: "p-quitter", UserID: "quitter", GrindID: "grind-1", Quitted: true, TotalPenalty: 1},
+	}
+	env := newSettlementTestEnv(participations, []*entities.HabitTask{
+		{ID: "t-a", UserID: "a", Completed: true},
+		{ID: "t-loser", UserID: "loser", Missed: true},
+		{ID: "t-quitter", UserID: "quitter"}, // quitters' tasks are never evaluated
+	})
+
+	report, err := env.svc.SettleGrind(dto.SettleGrindDTO{GrindID: "grind-1"})
+	require.NoError(t, err)
+
+	assert.Equal(t, dto.GrindSettlementSettled, report.Status)
+	assert.Equal(t, int64(1100), report.PotCents)
+	assert.Equal(t, int64(1100), report.PaidOutCents)
+	assert.Equal(t, int64(0), report.UnallocatedCents)
+	require.Len(t, report.Charges, 2)
+	assert.Equal(t, dto.SettlementLegDTO{UserID: "loser", AmountCents: 1000, Status: entities.SettlementStatusCaptured, Attempts: 1, ProviderReference: "pi_charge_test"}, report.Charges[0])
+	assert.Equal(t, "quitter", report.Charges[1].UserID)
+	assert.Equal(t, int64(100), report.Charges[1].AmountCents)
+
+	// 1100 cents over three completers: the first one gets the leftover cent.
+	require.Len(t, report.Payouts, 3)
+	for i, want := range []struct {
+		userID string
+		amount int64
+	}{{"a", 367}, {"b", 367}, {"c", 366}} {
+		assert.Equal(t, want.userID, report.Payouts[i].UserID)
+		assert.Equal(t, want.amount, report.Payouts[i].AmountCents)
+		assert.Equal(t, entities.SettlementStatusCaptured, report.Payouts[i].Status)
+	}
+
+	legs := env.legs(t)
+	assert.Len(t, legs, 5)
+	for _, leg := range legs {
+		assert.Equal(t, "grind-1", leg.GrindID)
+	}
+	for _, p := range participations {
+		assert.True(t, p.Finalized, "participation %s should be finalized", p.ID)
+	}
+	env.participation.AssertNumberOfCalls(t, "Update", len(participations))
+	assert.Equal(t, entities.GrindStateSettled, env.grindRecord.State)
+	assert.NotNil(t, env.grindRecord.SettlingAt)
+	assert.NotNil(t, env.grindRecord.SettledAt)
+	env.grind.AssertCalled(t, "UpdateState", env.grindRecord, entities.GrindStateActive)
+	env.grind.AssertCalled(t, "UpdateState", env.grindRecord, entities.GrindStateSettling)
+}
+
+func Test_GrindSettlementService_SettleGrind_ResumesAfterChargeFailure(t *testing.T) {
+	t.Parallel()
+
+	participations := []*entities.Participation{
+		{ID: "p-winner", UserID: "winner", GrindID: "grind-1"},
+		{ID: "p-loser", UserID: "loser", GrindID: "grind-1", TotalPenalty: 5},
+	}
+	env := newSettlementTestEnv(participations, nil)
+	env.adapter.chargeErr = errors.New("card declined")
+
+	report, err := env.svc.SettleGrind(dto.SettleGrindDTO{GrindID: "grind-1"})
+	require.NoError(t, err)
+	assert.Equal(t, dto.GrindSettlementCollecting, report.Status)
+	assert.Equal(t, entities.SettlementStatusFailed, report.Charges[0].Status)
+	assert.Equal(t, "card declined", report.Charges[0].LastError)
+	assert.Empty(t, report.Payouts, "nothing is paid out before collection has finished")
+	assert.Equal(t, entities.GrindStateSettling, env.grindRecord.State)
+
+	env.adapter.chargeErr = nil
+	report, err = env.svc.SettleGrind(dto.SettleGrindDTO{GrindID: "grind-1"})
+	require.NoError(t, err)
+	assert.Equal(t, dto.GrindSettlementSettled, report.Status)
+	assert.Equal(t, 2, report.Charges[0].Attempts)
+	assert.Equal(t, entities.SettlementStatusCaptured, report.Charges[0].Status)
+	require.Len(t, report.Payouts, 1)
+	assert.Equal(t, int64(500), report.Payouts[0].AmountCents)
+
+	// A settled grind is left alone: no new legs and no second freeze.
+	_, err = env.svc.SettleGrind(dto.SettleGrindDTO{GrindID: "grind-1"})
+	require.NoError(t, err)
+	assert.Len(t, env.legs(t), 3)
+	env.participation.AssertNumberOfCalls(t, "Update", len(participations))
+}
+
+func Test_GrindSettlementService_SettleGrind_GivesUpAfterMaxAttempts(t *testing.T) {
+	t.Parallel()
+
+	participations := []*entities.Participation{
+		{ID: "p-winner", UserID: "winner", GrindID: "grind-1"},
+		{ID: "p-loser", UserID: "loser", GrindID: "grind-1", TotalPenalty: 5},
+	}
+	env := newSettlementTestEnv(participations, nil)
+	env.adapter.chargeErr = errors.New("card declined")
+
+	var report *dto.GrindSettlementReportDTO
+	for i := 0; i < maxSettlementAttempts+1; i++ {
+		var err error
+		report, err = env.svc.SettleGrind(dto.SettleGrindDTO{GrindID: "grind-1"})
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, dto.GrindSettlementIncomplete, report.Status)
+	assert.Equal(t, maxSettlementAttempts, report.Charges[0].Attempts)
+	assert.Equal(t, int64(0), report.PotCents)
+	assert.Empty(t, report.Payouts)
+	assert.Len(t, env.legs(t), maxSettlementAttempts)
+	assert.Equal(t, entities.GrindStateSettled, env.grindRecord.State, "a grind that gave up on a leg is still closed")
+}
+
+func Test_GrindSettlementService_SettleGrind_ReconcilesStalePayouts(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		reference   string
+		queryStatus entities.SettlementStatus
+		queryErr    error
+		status      entities.SettlementStatus // of the stale attempt afterwards
+		attempts    int
+		report      string
+	}{
+		{name: "crashed before recording the result", status: entities.SettlementStatusNeedsReview, attempts: 1, report: dto.GrindSettlementIncomplete},
+		{name: "provider unreachable", reference: "tr_1", queryErr: errors.New("timeout"), status: entities.SettlementStatusNeedsReview, attempts: 1, report: dto.GrindSettlementIncomplete},
+		{name: "paid at the provider", reference: "tr_1", status: entities.SettlementStatusCaptured, attempts: 1, report: dto.GrindSettlementSettled},
+		{name: "still moving at the provider", reference: "tr_1", queryStatus: entities.SettlementStatusPending, status: entities.SettlementStatusPending, attempts: 1, report: dto.GrindSettlementPayingOut},
+		{name: "failed at the provider", reference: "tr_1", queryStatus: entities.SettlementStatusFailed, status: entities.SettlementStatusFailed, attempts: 2, report: dto.GrindSettlementSettled},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			participations := []*entities.Participation{
+				{ID: "p-winner", UserID: "winner", GrindID: "grind-1"},
+				{ID: "p-loser", UserID: "loser", GrindID: "grind-1", TotalPenalty: 5},
+			}
+			env := newSettlementTestEnv(participations, nil)
+			env.adapter.queryStatus = test.queryStatus
+			env.adapter.queryErr = test.queryErr
+
+			// the pot is collected and the first payout attempt has been pending for 20 minutes
+			stale := env.svc.now().Add(-2 * settlementInFlightTimeout)
+			charge := entities.NewPaymentSettlement("loser", settlementChargeOperation, settlementIdempotencyKey("grind-1", "charge", "loser", 1), entities.PaymentProviderStripe, "pm_loser", 500)
+			charge.GrindID = "grind-1"
+			charge.Status = entities.SettlementStatusCaptured
+			payout := entities.NewPaymentSettlement("winner", settlementPayoutOperation, settlementIdempotencyKey("grind-1", "payout", "winner", 1), entities.PaymentProviderStripe, "pm_winner", 500)
+			payout.GrindID = "grind-1"
+			payout.Reference.ProviderReference = test.reference
+			for _, leg := range []*entities.PaymentSettlement{charge, payout} {
+				created, err := env.settlements.Create(leg)
+				require.NoError(t, err)
+				created.CreatedAt = stale
+				_, err = env.settlements.Update(created)
+				require.NoError(t, err)
+			}
+
+			report, err := env.svc.SettleGrind(dto.SettleGrindDTO{GrindID: "grind-1"})
+			require.NoError(t, err)
+			assert.Equal(t, test.report, report.Status)
+
+			stored, err := env.settlements.FindByOperationAndKey(settlementPayoutOperation, payout.IdempotencyKey)
+			require.NoError(t, err)
+			assert.Equal(t, test.status, stored.Status)
+			require.Len(t, report.Payouts, 1)
+			assert.Equal(t, test.attempts, report.Payouts[0].Attempts, "a payout is only paid again once the provider says the earlier one failed")
+		})
+	}
+}
+
+func Test_GrindSettlementService_SettleGrind_RecordsMissingPaymentMethod(t *testing.T) {
+	t.Parallel()
+
+	participations := []*entities.Participation{
+		{ID: "p-loser", UserID: "loser", GrindID: "grind-1", TotalPenalty: 5},
+	}
+	env := newSettlementTestEnv(participations, nil)
+	env.paymentInfo.ExpectedCalls = nil
+	env.paymentInfo.On("FindByUserID", "loser").Return([]entities.PaymentMethodInfo{}, nil)
+
+	report, err := env.svc.SettleGrind(dto.SettleGrindDTO{GrindID: "grind-1"})
+	require.NoError(t, err)
+	require.Len(t, report.Charges, 1)
+	assert.Equal(t, entities.SettlementStatusFailed, report.Charges[0].Status)
+	assert.Equal(t, config.ErrNoPaymentMethod.Error(), report.Charges[0].LastError)
+}
+
+func Test_GrindSettlementService_SettleGrind_KeepsPotWithoutCompleters(t *testing.T) {
+	t.Parallel()
+
+	participations := []*entities.Participation{
+		{ID: "p-loser", UserID: "loser", GrindID: "grind-1", TotalPenalty: 5},
+		{ID: "p-quitter", UserID: "quitter", GrindID: "grind-1", Quitted: true, TotalPenalty: 30},
+	}
+	env := newSettlementTestEnv(participations, nil)
+
+	report, err := env.svc.SettleGrind(dto.SettleGrindDTO{GrindID: "grind-1"})
+	require.NoError(t, err)
+	assert.Equal(t, dto.GrindSettlementSettled, report.Status)
+	assert.Equal(t, int64(3500), report.PotCents)
+	assert.Equal(t, int64(3500), report.UnallocatedCents)
+	assert.Empty(t, report.Payouts)
+}
+
+func Test_GrindSettlementService_SettleGrind_WaitsForOpenTasks(t *testing.T) {
+	t.Parallel()
+
+	participations := []*entities.Participation{
+		{ID: "p-a", UserID: "a", GrindID: "grind-1"},
+	}
+	env := newSettlementTestEnv(participations, []*entities.HabitTask{{ID: "t-a", UserID: "a"}})
+
+	report, err := env.svc.SettleGrind(dto.SettleGrindDTO{GrindID: "grind-1"})
+	assert.ErrorIs(t, err, config.ErrSettlementNotReady)
+	assert.Nil(t, report)
+	assert.False(t, participations[0].Finalized)
+	env.participation.AssertNotCalled(t, "Update", mock.Anything)
+	assert.Equal(t, entities.GrindStateActive, env.grindRecord.State)
+}
+
+func Test_GrindSettlementService_SettleGrind_RejectsGrindThatNeverStarted(t *testing.T) {
+	t.Parallel()
+
+	participations := []*entities.Participation{
+		{ID: "p-a", UserID: "a", GrindID: "grind-1"},
+	}
+	env := newSettlementTestEnv(participations, nil)
+	env.grindRecord.State = entities.GrindStateCancelled
+
+	_, err := env.svc.SettleGrind(dto.SettleGrindDTO{GrindID: "grind-1"})
+	var stateErr *entities.GrindStateError
+	require.ErrorAs(t, err, &stateErr)
+	assert.Equal(t, entities.GrindStateCancelled, stateErr.State)
+	assert.False(t, participations[0].Finalized)
+	assert.Empty(t, env.legs(t))
+}
+
+func Test_GrindSettlementService_SettleGrind_RejectsGrindNotEndedEverywhere(t *testing.T) {
+	t.Parallel()
+
+	participations := []*entities.Participation{
+		{ID: "p-a", UserID: "a", GrindID: "grind-1"},
+	}
+	env := newSettlementTestEnv(participations, nil)
+	env.user.ExpectedCalls = nil
+	// 2026-04-04 09:00 UTC: the grind has ended in UTC but not yet in Honolulu (UTC-10).
+	env.svc.now = func() time.Time { return time.Date(2026, 4, 4, 9, 0, 0, 0, time.UTC) }
+	env.user.On("FindByGrindID", "grind-1").Return([]entities.User{{ID: "a", Timezone: "Pacific/Honolulu"}}, nil)
+
+	_, err := env.svc.SettleGrind(dto.SettleGrindDTO{GrindID: "grind-1"})
+	assert.ErrorIs(t, err, config.ErrGrindNotEnded)
+	assert.Empty(t, env.legs(t))
+}
+
+func Test_GrindSettlementService_GetSettlementReport_ParticipantsOnly(t *testing.T) {
+	t.Parallel()
+
+	participations := []*entities.Participation{
+		{ID: "p-a", UserID: "a", GrindID: "grind-1"},
+	}
+	env := newSettlementTestEnv(participations, nil)
+
+	_, err := env.svc.GetSettlementReport(dto.GetGrindSettlementDTO{GrindID: "grind-1", UserID: "outsider"})
+	assert.ErrorIs(t, err, config.ErrUserIsNotParticipant)
+
+	report, err := env.svc.GetSettlementReport(dto.GetGrindSettlementDTO{GrindID: "grind-1", UserID: "a"})
+	require.NoError(t, err)
+	assert.Equal(t, "grind-1", report.GrindID)
+	assert.Empty(t, env.legs(t), "reading the report must not move money")
+}
+
+func Test_splitPot(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, splitPot(1000, 0))
+	assert.Equal(t, []int64{500, 500}, splitPot(1000, 2))
+	assert.Equal(t, []int64{334, 333, 333}, splitPot(1000, 3))
+	assert.Equal(t, []int64{1, 1, 0}, splitPot(2, 3))
+}
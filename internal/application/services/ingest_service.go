@@ -1,9 +1,14 @@
 package services
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
@@ -17,6 +22,43 @@ import (
 type IngestionProvider interface {
 	ParsePayload(raw map[string]interface{}) (*ingestResult, error)
 	ProviderName() string
+	// MatchesTask checks that the payload is a completion of what task asks for. It fails
+	// with ErrSubmissionDoesNotMatchTask when the task wants something else.
+	MatchesTask(task *entities.HabitTask, raw map[string]interface{}) error
+	// VerifyCompletion checks the claimed completion with the provider itself before it
+	// counts. Providers without a way to check trust the payload.
+	VerifyCompletion(user *entities.User, raw map[string]interface{}, occurredAt time.Time) error
+	// DedupKey identifies the signal at its source so a retried delivery is recorded once:
+	// the provider's own event ID when the payload carries one, otherwise payloadHash(raw).
+	DedupKey(raw map[string]interface{}) string
+	// DescribeHabit says in plain words what task asks for, for the CompletionVerifier.
+	DescribeHabit(task *entities.HabitTask) string
+	// PayloadSchema is the JSON Schema document every payload must match before it is
+	// parsed, see assets/schemas.
+	PayloadSchema() []byte
+}
+
+// payloadHash is the DedupKey of payloads without an event ID: the SHA-256 of their
+// canonical JSON, in which object keys are sorted.
+func payloadHash(raw map[string]interface{}) string {
+	canonical, err := json.Marshal(raw)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(canonical)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// payloadString returns a top-level string or number of the payload as text, or "".
+func payloadString(raw map[string]interface{}, key string) string {
+	switch v := raw[key].(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return ""
+	}
 }
 
 // ingestResult holds the parsed output from an IngestionProvider before entity creation.
@@ -26,11 +68,16 @@ type ingestResult struct {
 	Metadata   datatypes.JSON
 }
 
-// LeetCodeProvider parses LeetCode Chrome-extension payloads.
-type LeetCodeProvider struct{}
+// LeetCodeProvider parses LeetCode Chrome-extension payloads. With a verifier, every
+// payload is checked against the user's accepted submissions on LeetCode.
+type LeetCodeProvider struct {
+	verifier *LeetCodeVerifier
+}
 
 func (p *LeetCodeProvider) ProviderName() string { return "leetcode" }
 
+func (p *LeetCodeProvider) PayloadSchema() []byte { return embeddedPayloadSchema("leetcode") }
+
 func (p *LeetCodeProvider) ParsePayload(raw map[string]interface{}) (*ingestResult, error) {
 	metaBytes, err := json.Marshal(raw)
 	if err != nil {
@@ -51,11 +98,76 @@ func (p *LeetCodeProvider) ParsePayload(raw map[string]interface{}) (*ingestResu
 	}, nil
 }
 
+// MatchesTask only accepts the assigned problem for tasks a roadmap assigned one to; any
+// submission completes a free-form task. The problem is read from problemSlug, falling
+// back to the slug in problemURL.
+func (p *LeetCodeProvider) MatchesTask(task *entities.HabitTask, raw map[string]interface{}) error {
+	assigned := task.AssignedProblemSlug()
+	if assigned == "" {
+		return nil
+	}
+	submitted := leetCodeSlug(raw)
+	if submitted != assigned {
+		return fmt.Errorf("%w: submitted %q, assigned %q", config.ErrSubmissionDoesNotMatchTask, submitted, assigned)
+	}
+	return nil
+}
+
+// VerifyCompletion requires an accepted submission of the payload's problem on the
+// user's LeetCode profile around occurredAt. Without a verifier the payload is trusted.
+func (p *LeetCodeProvider) VerifyCompletion(user *entities.User, raw map[string]interface{}, occurredAt time.Time) error {
+	if p.verifier == nil {
+		return nil
+	}
+	return p.verifier.Verify(user, leetCodeSlug(raw), occurredAt)
+}
+
+// DedupKey uses the LeetCode submission ID when the extension sends one.
+func (p *LeetCodeProvider) DedupKey(raw map[string]interface{}) string {
+	if id := payloadString(raw, "submissionId"); id != "" {
+		return "submission:" + id
+	}
+	return payloadHash(raw)
+}
+
+// DescribeHabit names the assigned problem when the task has one.
+func (p *LeetCodeProvider) DescribeHabit(task *entities.HabitTask) string {
+	if slug := task.AssignedProblemSlug(); slug != "" {
+		return fmt.Sprintf("Solve the LeetCode problem %q and get the solution accepted", slug)
+	}
+	return "Solve any LeetCode problem and get the solution accepted"
+}
+
+// leetCodeSlug returns the problem slug of a LeetCode payload, or "" when it has none.
+func leetCodeSlug(raw map[string]interface{}) string {
+	if slug, ok := raw["problemSlug"].(string); ok && slug != "" {
+		return slug
+	}
+	rawURL, ok := raw["problemURL"].(string)
+	if !ok {
+		return ""
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	// https://leetcode.com/problems/two-sum/description/ -> two-sum
+	segments := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	for i := 0; i+1 < len(segments); i++ {
+		if segments[i] == "problems" {
+			return segments[i+1]
+		}
+	}
+	return ""
+}
+
 // DuolingoProvider parses Duolingo webhook payloads.
 type DuolingoProvider struct{}
 
 func (p *DuolingoProvider) ProviderName() string { return "duolingo" }
 
+func (p *DuolingoProvider) PayloadSchema() []byte { return embeddedPayloadSchema("duolingo") }
+
 func (p *DuolingoProvider) ParsePayload(raw map[string]interface{}) (*ingestResult, error) {
 	metaBytes, err := json.Marshal(raw)
 	if err != nil {
@@ -76,63 +188,488 @@ func (p *DuolingoProvider) ParsePayload(raw map[string]interface{}) (*ingestResu
 	}, nil
 }
 
+// MatchesTask accepts every lesson; Duolingo tasks are never assigned specific content.
+func (p *DuolingoProvider) MatchesTask(task *entities.HabitTask, raw map[string]interface{}) error {
+	return nil
+}
+
+// VerifyCompletion trusts the payload; Duolingo's webhooks are the only source of lessons.
+func (p *DuolingoProvider) VerifyCompletion(user *entities.User, raw map[string]interface{}, occurredAt time.Time) error {
+	return nil
+}
+
+// DedupKey uses the webhook's eventId when it has one.
+func (p *DuolingoProvider) DedupKey(raw map[string]interface{}) string {
+	if id := payloadString(raw, "eventId"); id != "" {
+		return "event:" + id
+	}
+	return payloadHash(raw)
+}
+
+func (p *DuolingoProvider) DescribeHabit(task *entities.HabitTask) string {
+	return "Complete a Duolingo lesson"
+}
+
+// CustomProvider parses payloads sent through a user's or a group's own integration. The
+// integration's field mapping says where occurredAt and the evidence live.
+type CustomProvider struct {
+	integration *entities.Integration
+}
+
+func (p *CustomProvider) ProviderName() string { return "custom" }
+
+// PayloadSchema only constrains the fields ingest itself reads; the integration's field
+// mapping checks the rest.
+func (p *CustomProvider) PayloadSchema() []byte { return embeddedPayloadSchema("custom") }
+
+func (p *CustomProvider) ParsePayload(raw map[string]interface{}) (*ingestResult, error) {
+	occurredAt, evidence, err := p.integration.Mapping.Extract(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", config.ErrInvalidCustomPayload, err)
+	}
+	if occurredAt.IsZero() {
+		occurredAt = time.Now().UTC()
+	}
+
+	metaBytes, err := json.Marshal(map[string]interface{}{
+		"integrationID":   p.integration.ID,
+		"integrationName": p.integration.Name,
+		"evidence":        evidence,
+		"payload":         raw,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal custom payload: %w", err)
+	}
+
+	return &ingestResult{
+		Provider:   entities.ProviderCustom,
+		OccurredAt: occurredAt,
+		Metadata:   datatypes.JSON(metaBytes),
+	}, nil
+}
+
+// MatchesTask accepts every payload; custom tasks are never assigned specific content.
+func (p *CustomProvider) MatchesTask(task *entities.HabitTask, raw map[string]interface{}) error {
+	return nil
+}
+
+// VerifyCompletion trusts the payload; the integration's signature already vouches for it.
+func (p *CustomProvider) VerifyCompletion(user *entities.User, raw map[string]interface{}, occurredAt time.Time) error {
+	return nil
+}
+
+// DedupKey uses the event ID the integration's mapping points at, scoped to the integration
+// so two integrations of the same user cannot collide.
+func (p *CustomProvider) DedupKey(raw map[string]interface{}) string {
+	if id := p.integration.Mapping.ExtractEventID(raw); id != "" {
+		return p.integration.ID + ":event:" + id
+	}
+	return p.integration.ID + ":" + payloadHash(raw)
+}
+
+// DescribeHabit only knows the integration's name; what it tracks is up to its owner.
+func (p *CustomProvider) DescribeHabit(task *entities.HabitTask) string {
+	return fmt.Sprintf("Complete the habit tracked by the custom integration %q", p.integration.Name)
+}
+
 // IngestService orchestrates ingestion of completion events from external providers.
 type IngestService struct {
+	db                  *gorm.DB
+	userRepo            repositories.UserRepository
 	habitTaskRepo       repositories.HabitTaskRepository
 	completionEventRepo repositories.CompletionEventRepository
+	deadLetterRepo      repositories.DeadLetterRepository
 	providers           map[string]IngestionProvider
+	verifier            CompletionVerifier
+	reviews             *DisputeService
+	graceWindow         time.Duration // how long after the end of a day its completions are still accepted
+	minConfidence       float64       // completions the verifier is less sure of go to peer review
+	now                 func() time.Time
 }
 
 // NewIngestService constructs an IngestService with LeetCode and Duolingo providers registered.
+// Signals that cannot be recorded are kept in deadLetterRepo; nil drops them.
+// LeetCode completions are verified through leetCodeClient; nil trusts the extension's payloads.
+// Every completion is judged by verifier; nil judges none. Completions it doubts are sent to
+// peer review through reviews; nil only records the verdict.
 func NewIngestService(
+	db *gorm.DB,
+	userRepo repositories.UserRepository,
 	habitTaskRepo repositories.HabitTaskRepository,
 	completionEventRepo repositories.CompletionEventRepository,
+	deadLetterRepo repositories.DeadLetterRepository,
+	leetCodeClient LeetCodeClient,
+	verifier CompletionVerifier,
+	reviews *DisputeService,
 ) *IngestService {
+	leetCodeProvider := &LeetCodeProvider{}
+	if leetCodeClient != nil {
+		leetCodeProvider.verifier = NewLeetCodeVerifier(leetCodeClient)
+	}
+	if verifier == nil {
+		verifier = NoopVerifier{}
+	}
 	return &IngestService{
+		db:                  db,
+		userRepo:            userRepo,
 		habitTaskRepo:       habitTaskRepo,
 		completionEventRepo: completionEventRepo,
+		deadLetterRepo:      deadLetterRepo,
 		providers: map[string]IngestionProvider{
-			"leetcode": &LeetCodeProvider{},
+			"leetcode": leetCodeProvider,
 			"duolingo": &DuolingoProvider{},
 		},
+		verifier:      verifier,
+		reviews:       reviews,
+		graceWindow:   config.IngestGraceWindow(),
+		minConfidence: config.CompletionVerifierMinConfidence(),
+		now:           time.Now,
 	}
 }
 
-// Ingest validates the provider, finds today's habit task, parses the payload, and
-// persists a CompletionEvent. Returns ErrHabitTaskNotFound when no task exists today.
-func (s *IngestService) Ingest(providerName, userID, grindID string, rawPayload map[string]interface{}) (*entities.CompletionEvent, error) {
+// ingestClockSkew is how far in the future occurredAt may lie, to absorb the sender's
+// clock running ahead of ours.
+const ingestClockSkew = 5 * time.Minute
+
+// errCompletionEventExists rolls back an ingest whose event lost the race against a
+// concurrent delivery of the same signal.
+var errCompletionEventExists = errors.New("completion event already exists")
+
+// Ingest validates the provider, parses the payload, finds the habit task of the day the
+// completion occurred on (in the user's timezone), and persists a CompletionEvent. When
+// the task's CompletionRule is satisfied the task is marked completed in the same
+// transaction as the event insert.
+// A completion of a day that already ended is accepted until the grace window after its
+// midnight closes and records how late it arrived in LateBy; this covers offline devices
+// and delayed webhooks.
+// A signal the user already delivered, identified by the provider's DedupKey, is not
+// recorded again: the original event is returned and replayed is true.
+// evidence, when not nil, is a file already put in the blob store by EvidenceService; it
+// is recorded on the new event. A replayed or rejected signal does not keep it, except a
+// dead-lettered one: the dead letter keeps it for its re-drive.
+// A new completion is judged by the CompletionVerifier, whose verdict is recorded in the
+// event's Metadata under "verification". The completion counts either way; one the
+// verifier doubts is sent to the grind's partners for review.
+// A signal rejected for one of the reasons a DeadLetter names is kept as a dead letter
+// before the error is returned, so it can be re-driven later; the error is then a
+// *DeadLetteredError wrapping the ingest error.
+// Returns a *PayloadValidationError, matching ErrInvalidPayload, when the payload does not
+// match the provider's PayloadSchema, ErrIngestWindowClosed when occurredAt is past the
+// grace window, in the future, or on a day that was already evaluated,
+// ErrHabitTaskNotFound when no task exists that day, ErrSubmissionDoesNotMatchTask when
+// the task asks for something else, e.g. another roadmap problem, and the provider's
+// verification error when the completion cannot be confirmed.
+// Custom completions go through IngestCustom instead; they need an integration.
+func (s *IngestService) Ingest(providerName, userID, grindID string, rawPayload map[string]interface{}, evidence *entities.EvidenceAttachment) (event *entities.CompletionEvent, replayed bool, err error) {
+	if providerName == string(entities.ProviderCustom) {
+		return nil, false, config.ErrIntegrationRequired
+	}
 	provider, ok := s.providers[providerName]
 	if !ok {
-		return nil, fmt.Errorf("unsupported provider: %s", providerName)
+		return nil, false, fmt.Errorf("unsupported provider: %s", providerName)
 	}
+	event, replayed, err = s.ingest(provider, userID, grindID, "", rawPayload, evidence, false)
+	if err != nil {
+		err = s.deadLetter(provider, userID, "", grindID, rawPayload, evidence, err)
+	}
+	return event, replayed, err
+}
 
-	todayTask, err := s.habitTaskRepo.FindTodayTask(userID, grindID)
+// IngestCustom records a completion sent through integration, which the caller has
+// already authenticated and checked may ingest for userID. Returns ErrInvalidCustomPayload
+// when the payload lacks a field the integration's mapping names. Retries and rejected
+// signals are handled like in Ingest.
+func (s *IngestService) IngestCustom(integration *entities.Integration, userID, grindID string, rawPayload map[string]interface{}, evidence *entities.EvidenceAttachment) (event *entities.CompletionEvent, replayed bool, err error) {
+	provider := &CustomProvider{integration: integration}
+	event, replayed, err = s.ingest(provider, userID, grindID, "", rawPayload, evidence, false)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, config.ErrHabitTaskNotFound
+		err = s.deadLetter(provider, userID, integration.ID, grindID, rawPayload, evidence, err)
+	}
+	return event, replayed, err
+}
+
+// RecordUnreadable keeps a body the caller could not read as a completion signal, e.g.
+// invalid JSON or one without a grindID, as a dead letter of userID.
+func (s *IngestService) RecordUnreadable(providerName, userID, integrationID string, body []byte, cause error) {
+	if s.deadLetterRepo == nil || userID == "" {
+		return
+	}
+	if _, ok := s.providers[providerName]; !ok && providerName != string(entities.ProviderCustom) {
+		return
+	}
+	letter := entities.NewDeadLetter(userID, entities.CompletionProvider(providerName), integrationID, "", body,
+		entities.DeadLetterReasonInvalidPayload, cause)
+	if err := s.deadLetterRepo.Create(letter); err != nil {
+		fmt.Println("failed to record dead letter for", userID, err)
+	}
+}
+
+// DeadLetteredError is returned by Ingest and IngestCustom when the rejected signal was
+// kept as a dead letter. The dead letter owns the signal's evidence from then on. It
+// matches the ingest error it wraps.
+type DeadLetteredError struct {
+	DeadLetterID string
+	Err          error
+}
+
+func (e *DeadLetteredError) Error() string { return e.Err.Error() }
+
+func (e *DeadLetteredError) Unwrap() error { return e.Err }
+
+// deadLetter keeps a rejected signal, with its evidence, when err is one a DeadLetter has a
+// reason for, and returns err wrapped in a *DeadLetteredError. Failures to record are only
+// reported; the caller then gets err back unchanged.
+func (s *IngestService) deadLetter(provider IngestionProvider, userID, integrationID, grindID string, rawPayload map[string]interface{}, evidence *entities.EvidenceAttachment, err error) error {
+	reason := deadLetterReason(err)
+	if s.deadLetterRepo == nil || reason == "" {
+		return err
+	}
+	payload, marshalErr := json.Marshal(rawPayload)
+	if marshalErr != nil {
+		return err
+	}
+	letter := entities.NewDeadLetter(userID, entities.CompletionProvider(provider.ProviderName()), integrationID, grindID, payload, reason, err)
+	letter.Evidence = evidence
+	if createErr := s.deadLetterRepo.Create(letter); createErr != nil {
+		fmt.Println("failed to record dead letter for", userID, createErr)
+		return err
+	}
+	return &DeadLetteredError{DeadLetterID: letter.ID, Err: err}
+}
+
+// deadLetterReason maps an ingest error to the reason it is kept for, or "" for errors a
+// retry or a fix on our side resolves, such as LeetCode being unreachable.
+func deadLetterReason(err error) entities.DeadLetterReason {
+	switch {
+	case errors.Is(err, config.ErrHabitTaskNotFound):
+		return entities.DeadLetterReasonNoTask
+	case errors.Is(err, config.ErrSubmissionDoesNotMatchTask):
+		return entities.DeadLetterReasonTaskMismatch
+	case errors.Is(err, config.ErrSubmissionNotVerified), errors.Is(err, config.ErrLeetCodeUsernameMissing):
+		return entities.DeadLetterReasonNotVerified
+	case errors.Is(err, config.ErrIngestWindowClosed):
+		return entities.DeadLetterReasonWindowClosed
+	case errors.Is(err, config.ErrInvalidCustomPayload), errors.Is(err, config.ErrInvalidPayload):
+		return entities.DeadLetterReasonInvalidPayload
+	default:
+		return ""
+	}
+}
+
+// ingest records rawPayload for the task identified by habitTaskID or, when it is empty,
+// for the user's task in grindID on the day the completion occurred. A redrive is not held
+// to the grace window, but a task whose day was already evaluated is still refused.
+func (s *IngestService) ingest(provider IngestionProvider, userID, grindID, habitTaskID string, rawPayload map[string]interface{}, evidence *entities.EvidenceAttachment, redrive bool) (*entities.CompletionEvent, bool, error) {
+	if err := validatePayload(provider, rawPayload); err != nil {
+		return nil, false, err
+	}
+	user, err := s.userRepo.FindById(userID)
+	if err != nil {
+		return nil, false, config.ErrUserNotFound
+	}
+	var explicitTask *entities.HabitTask
+	if habitTaskID != "" {
+		explicitTask, err = s.findOwnTask(userID, habitTaskID)
+		if err != nil {
+			return nil, false, err
+		}
+		grindID = explicitTask.GrindID // dedup keys are scoped to the task's grind
+	}
+
+	// a retry is answered with the original event even once its day is over
+	providerName := entities.CompletionProvider(provider.ProviderName())
+	dedupKey := provider.DedupKey(rawPayload)
+	if dedupKey != "" {
+		original, err := s.completionEventRepo.FindByDedupKey(userID, grindID, providerName, dedupKey)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to look up completion event: %w", err)
+		}
+		if original != nil {
+			return original, true, nil
 		}
-		return nil, fmt.Errorf("failed to find today's habit task: %w", err)
 	}
 
 	result, err := provider.ParsePayload(rawPayload)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse payload: %w", err)
+		return nil, false, fmt.Errorf("failed to parse payload: %w", err)
+	}
+	task, lateBy, err := s.findTask(userID, grindID, explicitTask, result.OccurredAt, user.Location(), redrive)
+	if err != nil {
+		return nil, false, err
+	}
+	if task.Missed || task.Excused {
+		return nil, false, fmt.Errorf("%w: the day of %s was already evaluated",
+			config.ErrIngestWindowClosed, result.OccurredAt.UTC().Format(time.RFC3339))
+	}
+	if err := provider.MatchesTask(task, rawPayload); err != nil {
+		return nil, false, err
+	}
+	if err := provider.VerifyCompletion(user, rawPayload, result.OccurredAt); err != nil {
+		return nil, false, err
 	}
 
 	event, err := entities.NewCompletionEvent(
-		todayTask.ID,
+		task.ID,
 		userID,
 		string(result.Provider),
 		result.OccurredAt,
 		result.Metadata,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build completion event: %w", err)
+		return nil, false, fmt.Errorf("failed to build completion event: %w", err)
+	}
+	event.GrindID = task.GrindID
+	event.DedupKey = dedupKey
+	event.LateBy = lateBy
+	event.Evidence = evidence
+	verdict := s.judge(provider, task, event)
+
+	txErr := runInTransaction(s.db, func(tx *gorm.DB) error {
+		eventRepo := getCompletionEventRepo(s.completionEventRepo, tx)
+		habitTaskRepo := getHabitTaskRepo(s.habitTaskRepo, tx)
+
+		created, err := eventRepo.CreateIfAbsent(event)
+		if err != nil {
+			return fmt.Errorf("failed to persist completion event: %w", err)
+		}
+		if !created {
+			return errCompletionEventExists
+		}
+		return completeTaskIfSatisfied(habitTaskRepo, eventRepo, task, event)
+	})
+	if errors.Is(txErr, errCompletionEventExists) {
+		original, err := s.completionEventRepo.FindByDedupKey(userID, grindID, providerName, dedupKey)
+		if err != nil || original == nil {
+			return nil, false, fmt.Errorf("failed to load the original completion event: %v", err)
+		}
+		return original, true, nil
+	}
+	if txErr != nil {
+		return nil, false, txErr
+	}
+
+	if s.reviews != nil && verdict != nil && verdict.NeedsReview(s.minConfidence) {
+		if err := s.reviews.RequestReview(event, task, verdict); err != nil {
+			fmt.Println("failed to request review of completion", event.ID, err)
+		}
+	}
+	return event, false, nil
+}
+
+// judge asks the CompletionVerifier about event and records its verdict in the event's
+// Metadata. A failing verifier is only reported; it never holds a completion back.
+func (s *IngestService) judge(provider IngestionProvider, task *entities.HabitTask, event *entities.CompletionEvent) *entities.CompletionVerdict {
+	verdict, err := s.verifier.Verify(entities.CompletionVerification{
+		UserID:     event.UserID,
+		Provider:   event.Provider,
+		Habit:      provider.DescribeHabit(task),
+		OccurredAt: event.OccurredAt,
+		Metadata:   event.Metadata,
+		Evidence:   event.Evidence,
+	})
+	if err != nil {
+		fmt.Println("failed to verify completion for", event.UserID, err)
+		return nil
+	}
+	if verdict == nil {
+		return nil
+	}
+	if err := event.RecordVerdict(verdict); err != nil {
+		fmt.Println("failed to record verdict for", event.UserID, err)
+	}
+	return verdict
+}
+
+// findOwnTask returns the user's task habitTaskID.
+func (s *IngestService) findOwnTask(userID, habitTaskID string) (*entities.HabitTask, error) {
+	task, err := s.habitTaskRepo.FindByID(habitTaskID)
+	if err != nil || task == nil {
+		return nil, config.ErrHabitTaskNotFound
+	}
+	if task.UserID != userID {
+		return nil, config.ErrForbidden
+	}
+	return task, nil
+}
+
+// findTask resolves the task a completion at occurredAt counts for and how late it is.
+// An explicit task, already checked to be the user's, has its own day decide the lateness
+// instead of occurredAt's.
+func (s *IngestService) findTask(userID, grindID string, explicit *entities.HabitTask, occurredAt time.Time, loc *time.Location, redrive bool) (*entities.HabitTask, time.Duration, error) {
+	if explicit != nil {
+		lateBy, err := s.lateness(explicit.Date, loc, redrive)
+		if err != nil {
+			return nil, 0, err
+		}
+		return explicit, lateBy, nil
+	}
+
+	lateBy, err := s.lateness(occurredAt, loc, redrive)
+	if err != nil {
+		return nil, 0, err
+	}
+	task, err := s.habitTaskRepo.FindTaskOnDay(userID, grindID, occurredAt, loc)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, 0, config.ErrHabitTaskNotFound
+		}
+		return nil, 0, fmt.Errorf("failed to find the habit task: %w", err)
 	}
+	return task, lateBy, nil
+}
 
-	if err := s.completionEventRepo.Create(event); err != nil {
-		return nil, fmt.Errorf("failed to persist completion event: %w", err)
+// lateness returns how long after the end of occurredAt's day in loc the completion is
+// being ingested, or ErrIngestWindowClosed when that is more than the grace window or
+// occurredAt lies in the future. A redrive may be later than the grace window.
+func (s *IngestService) lateness(occurredAt time.Time, loc *time.Location, redrive bool) (time.Duration, error) {
+	now := s.now()
+	if occurredAt.After(now.Add(ingestClockSkew)) {
+		return 0, fmt.Errorf("%w: occurredAt %s is in the future",
+			config.ErrIngestWindowClosed, occurredAt.UTC().Format(time.RFC3339))
+	}
+	dayEnd := entities.LocalDayStart(occurredAt, loc).AddDate(0, 0, 1)
+	lateBy := now.Sub(dayEnd)
+	if lateBy <= 0 {
+		return 0, nil
+	}
+	if lateBy > s.graceWindow && !redrive {
+		return 0, fmt.Errorf("%w: the day of %s ended more than %s ago",
+			config.ErrIngestWindowClosed, occurredAt.UTC().Format(time.RFC3339), s.graceWindow)
 	}
+	return lateBy, nil
+}
 
-	return event, nil
+// completeTaskIfSatisfied evaluates the task's CompletionRule against every event
+// recorded for it and, when satisfied, stamps the task with the triggering event's time.
+// task was read before the transaction, so the completion only lands if the task is still
+// neither missed nor excused; otherwise it returns ErrIngestWindowClosed, which rolls the
+// event back.
+func completeTaskIfSatisfied(
+	habitTaskRepo repositories.HabitTaskRepository,
+	eventRepo repositories.CompletionEventRepository,
+	task *entities.HabitTask,
+	event *entities.CompletionEvent,
+) error {
+	if task.Completed {
+		return nil
+	}
+
+	events, err := eventRepo.FindByHabitTaskID(task.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load completion events: %w", err)
+	}
+	if !task.CompletionRule().IsSatisfied(events) {
+		return nil
+	}
+
+	task.MarkCompleted(event.OccurredAt)
+	completed, err := habitTaskRepo.Complete(task.ID, *task.FinishedTime)
+	if err != nil {
+		return fmt.Errorf("failed to complete habit task: %w", err)
+	}
+	if !completed {
+		return fmt.Errorf("%w: the day of %s was evaluated meanwhile",
+			config.ErrIngestWindowClosed, event.OccurredAt.UTC().Format(time.RFC3339))
+	}
+	return nil
 }
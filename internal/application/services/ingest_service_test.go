@@ -8,16 +8,31 @@ import (
 	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
 	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
 	"github.com/daniel0321forever/terriyaki-go/internal/domain/mocks"
+	"github.com/daniel0321forever/terriyaki-go/internal/infrastructure/verifier"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"gorm.io/gorm"
 )
 
+// newIngestEventRepo returns a completion event repository where no signal was delivered before.
+func newIngestEventRepo() *mocks.MockCompletionEventRepository {
+	completionEventRepo := new(mocks.MockCompletionEventRepository)
+	completionEventRepo.On("FindByDedupKey", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+	return completionEventRepo
+}
+
+// newIngestUserRepo returns a user repository that resolves "user-1" in the given timezone.
+func newIngestUserRepo(timezone string) *mocks.MockUserRepository {
+	userRepo := new(mocks.MockUserRepository)
+	userRepo.On("FindById", "user-1").Return(&entities.User{ID: "user-1", Timezone: timezone}, nil)
+	return userRepo
+}
+
 func Test_IngestService_Ingest_LeetCode_Success(t *testing.T) {
 	t.Parallel()
 
 	habitTaskRepo := new(mocks.MockHabitTaskRepository)
-	completionEventRepo := new(mocks.MockCompletionEventRepository)
+	completionEventRepo := newIngestEventRepo()
 
 	todayTask := &entities.HabitTask{
 		ID:      "task-1",
@@ -25,14 +40,16 @@ func Test_IngestService_Ingest_LeetCode_Success(t *testing.T) {
 		GrindID: "grind-1",
 		Date:    time.Now(),
 	}
-	habitTaskRepo.On("FindTodayTask", "user-1", "grind-1").Return(todayTask, nil)
-	completionEventRepo.On("Create", mock.MatchedBy(func(e *entities.CompletionEvent) bool {
+	habitTaskRepo.On("FindTaskOnDay", "user-1", "grind-1", mock.Anything, time.UTC).Return(todayTask, nil)
+	completionEventRepo.On("CreateIfAbsent", mock.MatchedBy(func(e *entities.CompletionEvent) bool {
 		return e.HabitTaskID == "task-1" &&
 			e.UserID == "user-1" &&
 			e.Provider == entities.ProviderLeetCode
-	})).Return(nil)
+	})).Return(true, nil)
+	completionEventRepo.On("FindByHabitTaskID", "task-1").Return([]*entities.CompletionEvent{{ID: "event-1"}}, nil)
+	habitTaskRepo.On("Complete", "task-1", mock.Anything).Return(true, nil)
 
-	svc := NewIngestService(habitTaskRepo, completionEventRepo)
+	svc := NewIngestService(nil, newIngestUserRepo("UTC"), habitTaskRepo, completionEventRepo, nil, nil, nil, nil)
 
 	rawPayload := map[string]interface{}{
 		"grindID":           "grind-1",
@@ -42,12 +59,14 @@ func Test_IngestService_Ingest_LeetCode_Success(t *testing.T) {
 		"occurredAt":        time.Now().Format(time.RFC3339),
 	}
 
-	event, err := svc.Ingest("leetcode", "user-1", "grind-1", rawPayload)
+	event, _, err := svc.Ingest("leetcode", "user-1", "grind-1", rawPayload, nil)
 	assert.NoError(t, err)
 	assert.NotNil(t, event)
 	assert.Equal(t, entities.ProviderLeetCode, event.Provider)
 	assert.Equal(t, "task-1", event.HabitTaskID)
 	assert.Equal(t, "user-1", event.UserID)
+	assert.True(t, todayTask.Completed)
+	assert.True(t, todayTask.FinishedTime.Equal(event.OccurredAt))
 
 	habitTaskRepo.AssertExpectations(t)
 	completionEventRepo.AssertExpectations(t)
@@ -57,7 +76,7 @@ func Test_IngestService_Ingest_Duolingo_Success(t *testing.T) {
 	t.Parallel()
 
 	habitTaskRepo := new(mocks.MockHabitTaskRepository)
-	completionEventRepo := new(mocks.MockCompletionEventRepository)
+	completionEventRepo := newIngestEventRepo()
 
 	todayTask := &entities.HabitTask{
 		ID:      "task-2",
@@ -65,14 +84,16 @@ func Test_IngestService_Ingest_Duolingo_Success(t *testing.T) {
 		GrindID: "grind-1",
 		Date:    time.Now(),
 	}
-	habitTaskRepo.On("FindTodayTask", "user-1", "grind-1").Return(todayTask, nil)
-	completionEventRepo.On("Create", mock.MatchedBy(func(e *entities.CompletionEvent) bool {
+	habitTaskRepo.On("FindTaskOnDay", "user-1", "grind-1", mock.Anything, time.UTC).Return(todayTask, nil)
+	completionEventRepo.On("CreateIfAbsent", mock.MatchedBy(func(e *entities.CompletionEvent) bool {
 		return e.HabitTaskID == "task-2" &&
 			e.UserID == "user-1" &&
 			e.Provider == entities.ProviderDuolingo
-	})).Return(nil)
+	})).Return(true, nil)
+	completionEventRepo.On("FindByHabitTaskID", "task-2").Return([]*entities.CompletionEvent{{ID: "event-1"}}, nil)
+	habitTaskRepo.On("Complete", "task-2", mock.Anything).Return(true, nil)
 
-	svc := NewIngestService(habitTaskRepo, completionEventRepo)
+	svc := NewIngestService(nil, newIngestUserRepo("UTC"), habitTaskRepo, completionEventRepo, nil, nil, nil, nil)
 
 	rawPayload := map[string]interface{}{
 		"grindID":          "grind-1",
@@ -82,7 +103,7 @@ func Test_IngestService_Ingest_Duolingo_Success(t *testing.T) {
 		"occurredAt":       time.Now().Format(time.RFC3339),
 	}
 
-	event, err := svc.Ingest("duolingo", "user-1", "grind-1", rawPayload)
+	event, _, err := svc.Ingest("duolingo", "user-1", "grind-1", rawPayload, nil)
 	assert.NoError(t, err)
 	assert.NotNil(t, event)
 	assert.Equal(t, entities.ProviderDuolingo, event.Provider)
@@ -96,11 +117,11 @@ func Test_IngestService_Ingest_UnsupportedProvider(t *testing.T) {
 	t.Parallel()
 
 	habitTaskRepo := new(mocks.MockHabitTaskRepository)
-	completionEventRepo := new(mocks.MockCompletionEventRepository)
+	completionEventRepo := newIngestEventRepo()
 
-	svc := NewIngestService(habitTaskRepo, completionEventRepo)
+	svc := NewIngestService(nil, newIngestUserRepo("UTC"), habitTaskRepo, completionEventRepo, nil, nil, nil, nil)
 
-	_, err := svc.Ingest("unknown", "user-1", "grind-1", map[string]interface{}{})
+	_, _, err := svc.Ingest("unknown", "user-1", "grind-1", map[string]interface{}{}, nil)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "unsupported provider: unknown")
 
@@ -113,16 +134,503 @@ func Test_IngestService_Ingest_HabitTaskNotFound(t *testing.T) {
 	t.Parallel()
 
 	habitTaskRepo := new(mocks.MockHabitTaskRepository)
-	completionEventRepo := new(mocks.MockCompletionEventRepository)
+	completionEventRepo := newIngestEventRepo()
 
-	habitTaskRepo.On("FindTodayTask", "user-1", "grind-1").Return(nil, gorm.ErrRecordNotFound)
+	habitTaskRepo.On("FindTaskOnDay", "user-1", "grind-1", mock.Anything, time.UTC).Return(nil, gorm.ErrRecordNotFound)
 
-	svc := NewIngestService(habitTaskRepo, completionEventRepo)
+	svc := NewIngestService(nil, newIngestUserRepo("UTC"), habitTaskRepo, completionEventRepo, nil, nil, nil, nil)
 
-	_, err := svc.Ingest("leetcode", "user-1", "grind-1", map[string]interface{}{})
+	_, _, err := svc.Ingest("leetcode", "user-1", "grind-1", map[string]interface{}{"problemSlug": "two-sum"}, nil)
 	assert.Error(t, err)
 	assert.True(t, errors.Is(err, config.ErrHabitTaskNotFound))
 
 	habitTaskRepo.AssertExpectations(t)
 	completionEventRepo.AssertExpectations(t)
 }
+
+func Test_IngestService_Ingest_RequiredEventsNotYetMet(t *testing.T) {
+	t.Parallel()
+
+	habitTaskRepo := new(mocks.MockHabitTaskRepository)
+	completionEventRepo := newIngestEventRepo()
+
+	todayTask := &entities.HabitTask{
+		ID:             "task-3",
+		UserID:         "user-1",
+		GrindID:        "grind-1",
+		Date:           time.Now(),
+		RequiredEvents: 2,
+	}
+	habitTaskRepo.On("FindTaskOnDay", "user-1", "grind-1", mock.Anything, time.UTC).Return(todayTask, nil)
+	completionEventRepo.On("CreateIfAbsent", mock.AnythingOfType("*entities.CompletionEvent")).Return(true, nil)
+	completionEventRepo.On("FindByHabitTaskID", "task-3").Return([]*entities.CompletionEvent{{ID: "event-1"}}, nil)
+
+	svc := NewIngestService(nil, newIngestUserRepo("UTC"), habitTaskRepo, completionEventRepo, nil, nil, nil, nil)
+
+	_, _, err := svc.Ingest("leetcode", "user-1", "grind-1", map[string]interface{}{"problemSlug": "two-sum"}, nil)
+	assert.NoError(t, err)
+	assert.False(t, todayTask.Completed)
+	assert.Nil(t, todayTask.FinishedTime)
+
+	habitTaskRepo.AssertNotCalled(t, "Complete", mock.Anything, mock.Anything)
+	completionEventRepo.AssertExpectations(t)
+}
+
+func Test_IngestService_Ingest_TaskEvaluatedMeanwhile(t *testing.T) {
+	t.Parallel()
+
+	habitTaskRepo := new(mocks.MockHabitTaskRepository)
+	completionEventRepo := newIngestEventRepo()
+
+	// the task was open when read, but the missed-day evaluator closes it before the
+	// completion is written
+	todayTask := &entities.HabitTask{ID: "task-5", UserID: "user-1", GrindID: "grind-1", Date: time.Now()}
+	habitTaskRepo.On("FindTaskOnDay", "user-1", "grind-1", mock.Anything, time.UTC).Return(todayTask, nil)
+	completionEventRepo.On("CreateIfAbsent", mock.AnythingOfType("*entities.CompletionEvent")).Return(true, nil)
+	completionEventRepo.On("FindByHabitTaskID", "task-5").Return([]*entities.CompletionEvent{{ID: "event-1"}}, nil)
+	habitTaskRepo.On("Complete", "task-5", mock.Anything).Return(false, nil)
+
+	svc := NewIngestService(nil, newIngestUserRepo("UTC"), habitTaskRepo, completionEventRepo, nil, nil, nil, nil)
+
+	event, _, err := svc.Ingest("duolingo", "user-1", "grind-1", map[string]interface{}{"lessonsCompleted": float64(1)}, nil)
+	assert.True(t, errors.Is(err, config.ErrIngestWindowClosed), "got %v", err)
+	assert.Nil(t, event)
+	habitTaskRepo.AssertExpectations(t)
+}
+
+func Test_IngestService_Ingest_AlreadyCompletedTaskKeepsFinishedTime(t *testing.T) {
+	t.Parallel()
+
+	habitTaskRepo := new(mocks.MockHabitTaskRepository)
+	completionEventRepo := newIngestEventRepo()
+
+	finished := time.Now().Add(-time.Hour).UTC()
+	todayTask := &entities.HabitTask{
+		ID:           "task-4",
+		UserID:       "user-1",
+		GrindID:      "grind-1",
+		Date:         time.Now(),
+		Completed:    true,
+		FinishedTime: &finished,
+	}
+	habitTaskRepo.On("FindTaskOnDay", "user-1", "grind-1", mock.Anything, time.UTC).Return(todayTask, nil)
+	completionEventRepo.On("CreateIfAbsent", mock.AnythingOfType("*entities.CompletionEvent")).Return(true, nil)
+
+	svc := NewIngestService(nil, newIngestUserRepo("UTC"), habitTaskRepo, completionEventRepo, nil, nil, nil, nil)
+
+	_, _, err := svc.Ingest("duolingo", "user-1", "grind-1", map[string]interface{}{"lessonsCompleted": float64(1)}, nil)
+	assert.NoError(t, err)
+	assert.True(t, todayTask.FinishedTime.Equal(finished))
+
+	habitTaskRepo.AssertNotCalled(t, "Complete", mock.Anything, mock.Anything)
+	completionEventRepo.AssertNotCalled(t, "FindByHabitTaskID", mock.Anything)
+}
+
+func Test_IngestService_Ingest_LooksUpDayInUserTimezone(t *testing.T) {
+	t.Parallel()
+
+	habitTaskRepo := new(mocks.MockHabitTaskRepository)
+	completionEventRepo := newIngestEventRepo()
+
+	habitTaskRepo.On("FindTaskOnDay", "user-1", "grind-1", mock.Anything, mock.MatchedBy(func(loc *time.Location) bool {
+		return loc.String() == "Asia/Taipei"
+	})).Return(nil, gorm.ErrRecordNotFound)
+
+	svc := NewIngestService(nil, newIngestUserRepo("Asia/Taipei"), habitTaskRepo, completionEventRepo, nil, nil, nil, nil)
+
+	_, _, err := svc.Ingest("leetcode", "user-1", "grind-1", map[string]interface{}{"problemSlug": "two-sum"}, nil)
+	assert.True(t, errors.Is(err, config.ErrHabitTaskNotFound))
+	habitTaskRepo.AssertExpectations(t)
+}
+
+func Test_IngestService_Ingest_LeetCodeRoadmapTaskNeedsAssignedProblem(t *testing.T) {
+	t.Parallel()
+
+	todayTask := &entities.HabitTask{ID: "task-5", UserID: "user-1", GrindID: "grind-1", Date: time.Now()}
+	todayTask.AssignProblem(entities.RoadmapNeetcode250, entities.RoadmapProblem{ID: 1, Slug: "two-sum", Tag: "Arrays & Hashing"})
+
+	habitTaskRepo := new(mocks.MockHabitTaskRepository)
+	completionEventRepo := newIngestEventRepo()
+	habitTaskRepo.On("FindTaskOnDay", "user-1", "grind-1", mock.Anything, time.UTC).Return(todayTask, nil)
+	completionEventRepo.On("CreateIfAbsent", mock.AnythingOfType("*entities.CompletionEvent")).Return(true, nil)
+	completionEventRepo.On("FindByHabitTaskID", "task-5").Return([]*entities.CompletionEvent{{ID: "event-1"}}, nil)
+	habitTaskRepo.On("Complete", todayTask.ID, mock.Anything).Return(true, nil)
+
+	svc := NewIngestService(nil, newIngestUserRepo("UTC"), habitTaskRepo, completionEventRepo, nil, nil, nil, nil)
+
+	_, _, err := svc.Ingest("leetcode", "user-1", "grind-1", map[string]interface{}{
+		"problemURL": "https://leetcode.com/problems/3sum/description/",
+	}, nil)
+	assert.True(t, errors.Is(err, config.ErrSubmissionDoesNotMatchTask))
+	completionEventRepo.AssertNotCalled(t, "CreateIfAbsent", mock.Anything)
+	assert.False(t, todayTask.Completed)
+
+	_, _, err = svc.Ingest("leetcode", "user-1", "grind-1", map[string]interface{}{
+		"problemURL": "https://leetcode.com/problems/two-sum/description/",
+	}, nil)
+	assert.NoError(t, err)
+	assert.True(t, todayTask.Completed)
+}
+
+func Test_IngestService_Ingest_LeetCodeVerifiesSubmission(t *testing.T) {
+	t.Parallel()
+
+	solvedAt := time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC)
+	todayTask := &entities.HabitTask{ID: "task-6", UserID: "user-1", GrindID: "grind-1", Date: solvedAt}
+
+	userRepo := new(mocks.MockUserRepository)
+	userRepo.On("FindById", "user-1").Return(&entities.User{ID: "user-1", Timezone: "UTC", LeetCodeUsername: "neo"}, nil)
+	habitTaskRepo := new(mocks.MockHabitTaskRepository)
+	completionEventRepo := newIngestEventRepo()
+	habitTaskRepo.On("FindTaskOnDay", "user-1", "grind-1", mock.Anything, time.UTC).Return(todayTask, nil)
+	completionEventRepo.On("CreateIfAbsent", mock.AnythingOfType("*entities.CompletionEvent")).Return(true, nil)
+	completionEventRepo.On("FindByHabitTaskID", "task-6").Return([]*entities.CompletionEvent{{ID: "event-1"}}, nil)
+	habitTaskRepo.On("Complete", todayTask.ID, mock.Anything).Return(true, nil)
+
+	client := &fakeLeetCodeClient{submissions: []entities.LeetCodeSubmission{{ID: "1", Slug: "two-sum", SubmittedAt: solvedAt}}}
+	svc := NewIngestService(nil, userRepo, habitTaskRepo, completionEventRepo, nil, client, nil, nil)
+	svc.now = func() time.Time { return solvedAt.Add(time.Hour) }
+
+	_, _, err := svc.Ingest("leetcode", "user-1", "grind-1", map[string]interface{}{
+		"problemSlug": "3sum",
+		"occurredAt":  solvedAt.Format(time.RFC3339),
+	}, nil)
+	assert.True(t, errors.Is(err, config.ErrSubmissionNotVerified))
+	completionEventRepo.AssertNotCalled(t, "CreateIfAbsent", mock.Anything)
+
+	event, _, err := svc.Ingest("leetcode", "user-1", "grind-1", map[string]interface{}{
+		"problemSlug": "two-sum",
+		"occurredAt":  solvedAt.Add(time.Minute).Format(time.RFC3339),
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "task-6", event.HabitTaskID)
+	assert.True(t, todayTask.Completed)
+	assert.Equal(t, []string{"neo", "neo"}, client.usernames)
+}
+
+func Test_IngestService_IngestCustom_UsesFieldMapping(t *testing.T) {
+	t.Parallel()
+
+	todayTask := &entities.HabitTask{ID: "task-7", UserID: "user-1", GrindID: "grind-1", Date: time.Now()}
+	habitTaskRepo := new(mocks.MockHabitTaskRepository)
+	completionEventRepo := newIngestEventRepo()
+	habitTaskRepo.On("FindTaskOnDay", "user-1", "grind-1", mock.Anything, time.UTC).Return(todayTask, nil)
+	completionEventRepo.On("CreateIfAbsent", mock.AnythingOfType("*entities.CompletionEvent")).Return(true, nil)
+	completionEventRepo.On("FindByHabitTaskID", "task-7").Return([]*entities.CompletionEvent{{ID: "event-1"}}, nil)
+	habitTaskRepo.On("Complete", todayTask.ID, mock.Anything).Return(true, nil)
+
+	svc := NewIngestService(nil, newIngestUserRepo("UTC"), habitTaskRepo, completionEventRepo, nil, nil, nil, nil)
+	svc.now = func() time.Time { return time.Date(2026, 4, 10, 13, 0, 0, 0, time.UTC) }
+	integration := &entities.Integration{
+		ID:      "int-1",
+		Name:    "Strava",
+		OwnerID: "user-1",
+		Mapping: entities.IntegrationFieldMapping{OccurredAt: "run.end", Evidence: "run.url"},
+	}
+
+	_, _, err := svc.IngestCustom(integration, "user-1", "grind-1", map[string]interface{}{
+		"run": map[string]interface{}{"end": "2026-04-10T12:00:00Z"},
+	}, nil)
+	assert.True(t, errors.Is(err, config.ErrInvalidCustomPayload))
+	completionEventRepo.AssertNotCalled(t, "CreateIfAbsent", mock.Anything)
+
+	event, _, err := svc.IngestCustom(integration, "user-1", "grind-1", map[string]interface{}{
+		"run": map[string]interface{}{"end": "2026-04-10T12:00:00Z", "url": "https://example.com/run/1"},
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.ProviderCustom, event.Provider)
+	assert.True(t, event.OccurredAt.Equal(time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC)))
+	assert.Contains(t, string(event.Metadata), `"evidence":"https://example.com/run/1"`)
+	assert.Contains(t, string(event.Metadata), `"integrationID":"int-1"`)
+
+	// the provider endpoint alone cannot ingest custom completions
+	_, _, err = svc.Ingest("custom", "user-1", "grind-1", map[string]interface{}{}, nil)
+	assert.True(t, errors.Is(err, config.ErrIntegrationRequired))
+}
+
+func Test_IngestService_Ingest_RetryReturnsOriginalEvent(t *testing.T) {
+	t.Parallel()
+
+	original := &entities.CompletionEvent{ID: "event-1", HabitTaskID: "task-8", UserID: "user-1", Provider: entities.ProviderLeetCode, DedupKey: "submission:1234"}
+	habitTaskRepo := new(mocks.MockHabitTaskRepository)
+	completionEventRepo := new(mocks.MockCompletionEventRepository)
+	completionEventRepo.On("FindByDedupKey", "user-1", "grind-1", entities.ProviderLeetCode, "submission:1234").Return(original, nil)
+
+	svc := NewIngestService(nil, newIngestUserRepo("UTC"), habitTaskRepo, completionEventRepo, nil, nil, nil, nil)
+
+	event, replayed, err := svc.Ingest("leetcode", "user-1", "grind-1", map[string]interface{}{
+		"submissionId": float64(1234),
+		"problemSlug":  "two-sum",
+	}, nil)
+	assert.NoError(t, err)
+	assert.True(t, replayed)
+	assert.Same(t, original, event)
+	// the original is returned even when its day is over and today has no task
+	habitTaskRepo.AssertNotCalled(t, "FindTaskOnDay", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	completionEventRepo.AssertNotCalled(t, "CreateIfAbsent", mock.Anything)
+}
+
+func Test_IngestService_Ingest_SameSignalCountsInEachGrind(t *testing.T) {
+	t.Parallel()
+
+	payload := map[string]interface{}{"eventId": "evt-1", "lessonsCompleted": float64(1)}
+	habitTaskRepo := new(mocks.MockHabitTaskRepository)
+	completionEventRepo := new(mocks.MockCompletionEventRepository)
+	// the lesson was already recorded for grind-1, not for grind-2
+	completionEventRepo.On("FindByDedupKey", "user-1", "grind-2", entities.ProviderDuolingo, "event:evt-1").Return(nil, nil)
+	habitTaskRepo.On("FindTaskOnDay", "user-1", "grind-2", mock.Anything, time.UTC).
+		Return(&entities.HabitTask{ID: "task-2", UserID: "user-1", GrindID: "grind-2", Date: time.Now()}, nil)
+	completionEventRepo.On("CreateIfAbsent", mock.MatchedBy(func(e *entities.CompletionEvent) bool {
+		return e.GrindID == "grind-2" && e.DedupKey == "event:evt-1"
+	})).Return(true, nil)
+	completionEventRepo.On("FindByHabitTaskID", "task-2").Return([]*entities.CompletionEvent{{ID: "event-2"}}, nil)
+	habitTaskRepo.On("Complete", "task-2", mock.Anything).Return(true, nil)
+
+	svc := NewIngestService(nil, newIngestUserRepo("UTC"), habitTaskRepo, completionEventRepo, nil, nil, nil, nil)
+
+	event, replayed, err := svc.Ingest("duolingo", "user-1", "grind-2", payload, nil)
+	assert.NoError(t, err)
+	assert.False(t, replayed)
+	assert.Equal(t, "task-2", event.HabitTaskID)
+	completionEventRepo.AssertExpectations(t)
+}
+
+func Test_IngestService_Ingest_ConcurrentRetryLosesInsertRace(t *testing.T) {
+	t.Parallel()
+
+	todayTask := &entities.HabitTask{ID: "task-9", UserID: "user-1", GrindID: "grind-1", Date: time.Now()}
+	payload := map[string]interface{}{"lessonsCompleted": float64(2)}
+	dedupKey := payloadHash(payload)
+	winner := &entities.CompletionEvent{ID: "event-winner", HabitTaskID: "task-9", UserID: "user-1", Provider: entities.ProviderDuolingo, DedupKey: dedupKey}
+
+	habitTaskRepo := new(mocks.MockHabitTaskRepository)
+	completionEventRepo := new(mocks.MockCompletionEventRepository)
+	completionEventRepo.On("FindByDedupKey", "user-1", "grind-1", entities.ProviderDuolingo, dedupKey).Return(nil, nil).Once()
+	completionEventRepo.On("FindByDedupKey", "user-1", "grind-1", entities.ProviderDuolingo, dedupKey).Return(winner, nil).Once()
+	habitTaskRepo.On("FindTaskOnDay", "user-1", "grind-1", mock.Anything, time.UTC).Return(todayTask, nil)
+	completionEventRepo.On("CreateIfAbsent", mock.MatchedBy(func(e *entities.CompletionEvent) bool {
+		return e.DedupKey == dedupKey
+	})).Return(false, nil)
+
+	svc := NewIngestService(nil, newIngestUserRepo("UTC"), habitTaskRepo, completionEventRepo, nil, nil, nil, nil)
+
+	event, replayed, err := svc.Ingest("duolingo", "user-1", "grind-1", payload, nil)
+	assert.NoError(t, err)
+	assert.True(t, replayed)
+	assert.Equal(t, "event-winner", event.ID)
+	assert.False(t, todayTask.Completed)
+	habitTaskRepo.AssertNotCalled(t, "Complete", mock.Anything, mock.Anything)
+	completionEventRepo.AssertExpectations(t)
+}
+
+func Test_IngestionProviders_DedupKey(t *testing.T) {
+	t.Parallel()
+
+	leetCode := &LeetCodeProvider{}
+	assert.Equal(t, "submission:1234", leetCode.DedupKey(map[string]interface{}{"submissionId": "1234"}))
+	assert.Equal(t, "submission:1234", leetCode.DedupKey(map[string]interface{}{"submissionId": float64(1234)}))
+
+	// without an event ID the payload hash ignores key order and changes with any value
+	a := leetCode.DedupKey(map[string]interface{}{"problemSlug": "two-sum", "occurredAt": "2026-04-10T12:00:00Z"})
+	b := leetCode.DedupKey(map[string]interface{}{"occurredAt": "2026-04-10T12:00:00Z", "problemSlug": "two-sum"})
+	c := leetCode.DedupKey(map[string]interface{}{"occurredAt": "2026-04-10T12:05:00Z", "problemSlug": "two-sum"})
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a, c)
+	assert.Contains(t, a, "sha256:")
+
+	assert.Equal(t, "event:evt-1", (&DuolingoProvider{}).DedupKey(map[string]interface{}{"eventId": "evt-1"}))
+
+	custom := &CustomProvider{integration: &entities.Integration{ID: "int-1", Mapping: entities.IntegrationFieldMapping{EventID: "run.id"}}}
+	assert.Equal(t, "int-1:event:42", custom.DedupKey(map[string]interface{}{"run": map[string]interface{}{"id": float64(42)}}))
+	assert.Equal(t, "int-1:"+payloadHash(map[string]interface{}{}), custom.DedupKey(map[string]interface{}{}))
+}
+
+func Test_IngestService_Ingest_BackdatedCompletionWithinGraceWindow(t *testing.T) {
+	t.Parallel()
+
+	occurredAt := time.Date(2026, 4, 10, 23, 30, 0, 0, time.UTC)
+	yesterdayTask := &entities.HabitTask{ID: "task-10", UserID: "user-1", GrindID: "grind-1", Date: time.Date(2026, 4, 10, 0, 0, 0, 0, time.UTC)}
+
+	habitTaskRepo := new(mocks.MockHabitTaskRepository)
+	completionEventRepo := newIngestEventRepo()
+	habitTaskRepo.On("FindTaskOnDay", "user-1", "grind-1", occurredAt, time.UTC).Return(yesterdayTask, nil)
+	completionEventRepo.On("CreateIfAbsent", mock.AnythingOfType("*entities.CompletionEvent")).Return(true, nil)
+	completionEventRepo.On("FindByHabitTaskID", "task-10").Return([]*entities.CompletionEvent{{ID: "event-1"}}, nil)
+	habitTaskRepo.On("Complete", yesterdayTask.ID, mock.Anything).Return(true, nil)
+
+	svc := NewIngestService(nil, newIngestUserRepo("UTC"), habitTaskRepo, completionEventRepo, nil, nil, nil, nil)
+	svc.graceWindow = 2 * time.Hour
+	svc.now = func() time.Time { return time.Date(2026, 4, 11, 1, 15, 0, 0, time.UTC) }
+
+	event, _, err := svc.Ingest("duolingo", "user-1", "grind-1", map[string]interface{}{
+		"occurredAt": occurredAt.Format(time.RFC3339),
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "task-10", event.HabitTaskID)
+	assert.Equal(t, 75*time.Minute, event.LateBy)
+	assert.True(t, yesterdayTask.Completed)
+}
+
+func Test_IngestService_Ingest_RejectsCompletionsOutsideGraceWindow(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 4, 11, 3, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		occurredAt time.Time
+	}{
+		{name: "day ended over the window ago", occurredAt: time.Date(2026, 4, 10, 23, 30, 0, 0, time.UTC)},
+		{name: "in the future", occurredAt: now.Add(time.Hour)},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			habitTaskRepo := new(mocks.MockHabitTaskRepository)
+			completionEventRepo := newIngestEventRepo()
+			svc := NewIngestService(nil, newIngestUserRepo("UTC"), habitTaskRepo, completionEventRepo, nil, nil, nil, nil)
+			svc.graceWindow = 2 * time.Hour
+			svc.now = func() time.Time { return now }
+
+			_, _, err := svc.Ingest("duolingo", "user-1", "grind-1", map[string]interface{}{
+				"occurredAt": tt.occurredAt.Format(time.RFC3339),
+			}, nil)
+			assert.True(t, errors.Is(err, config.ErrIngestWindowClosed))
+			habitTaskRepo.AssertNotCalled(t, "FindTaskOnDay", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			completionEventRepo.AssertNotCalled(t, "CreateIfAbsent", mock.Anything)
+		})
+	}
+}
+
+func Test_IngestService_Ingest_RejectsAlreadyEvaluatedDay(t *testing.T) {
+	t.Parallel()
+
+	occurredAt := time.Date(2026, 4, 10, 23, 30, 0, 0, time.UTC)
+	missedTask := &entities.HabitTask{ID: "task-11", UserID: "user-1", GrindID: "grind-1", Date: time.Date(2026, 4, 10, 0, 0, 0, 0, time.UTC), Missed: true}
+
+	habitTaskRepo := new(mocks.MockHabitTaskRepository)
+	completionEventRepo := newIngestEventRepo()
+	habitTaskRepo.On("FindTaskOnDay", "user-1", "grind-1", occurredAt, time.UTC).Return(missedTask, nil)
+
+	svc := NewIngestService(nil, newIngestUserRepo("UTC"), habitTaskRepo, completionEventRepo, nil, nil, nil, nil)
+	svc.graceWindow = 2 * time.Hour
+	svc.now = func() time.Time { return time.Date(2026, 4, 11, 0, 30, 0, 0, time.UTC) }
+
+	_, _, err := svc.Ingest("duolingo", "user-1", "grind-1", map[string]interface{}{
+		"occurredAt": occurredAt.Format(time.RFC3339),
+	}, nil)
+	assert.True(t, errors.Is(err, config.ErrIngestWindowClosed))
+	completionEventRepo.AssertNotCalled(t, "CreateIfAbsent", mock.Anything)
+	assert.False(t, missedTask.Completed)
+}
+
+func Test_IngestService_Ingest_RecordsEvidence(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC)
+	evidence := &entities.EvidenceAttachment{Key: "blob-1", FileName: "streak.png", ContentType: "image/png", Size: 72, SHA256: "ab12"}
+	habitTaskRepo := new(mocks.MockHabitTaskRepository)
+	completionEventRepo := newIngestEventRepo()
+	task := &entities.HabitTask{ID: "task-9", UserID: "user-1", GrindID: "grind-1", Date: now}
+	habitTaskRepo.On("FindTaskOnDay", "user-1", "grind-1", mock.Anything, time.UTC).Return(task, nil)
+	completionEventRepo.On("CreateIfAbsent", mock.MatchedBy(func(e *entities.CompletionEvent) bool {
+		return e.HabitTaskID == "task-9" && e.Evidence == evidence
+	})).Return(true, nil)
+	completionEventRepo.On("FindByHabitTaskID", "task-9").Return([]*entities.CompletionEvent{{ID: "event-1"}}, nil)
+	habitTaskRepo.On("Complete", "task-9", mock.Anything).Return(true, nil)
+
+	svc := NewIngestService(nil, newIngestUserRepo("UTC"), habitTaskRepo, completionEventRepo, nil, nil, nil, nil)
+	svc.now = func() time.Time { return now }
+
+	event, _, err := svc.Ingest("duolingo", "user-1", "grind-1", map[string]interface{}{
+		"occurredAt": now.Add(-time.Hour).Format(time.RFC3339),
+	}, evidence)
+	assert.NoError(t, err)
+	assert.Same(t, evidence, event.Evidence)
+	completionEventRepo.AssertExpectations(t)
+}
+
+// expectVerifiedIngest registers user-1's task-9 on now's day in grind-1, completed by the first event.
+func expectVerifiedIngest(now time.Time) (*mocks.MockHabitTaskRepository, *mocks.MockCompletionEventRepository) {
+	habitTaskRepo := new(mocks.MockHabitTaskRepository)
+	completionEventRepo := newIngestEventRepo()
+	task := &entities.HabitTask{ID: "task-9", UserID: "user-1", GrindID: "grind-1", Date: now}
+	habitTaskRepo.On("FindTaskOnDay", "user-1", "grind-1", mock.Anything, time.UTC).Return(task, nil)
+	completionEventRepo.On("CreateIfAbsent", mock.Anything).Return(true, nil)
+	completionEventRepo.On("FindByHabitTaskID", "task-9").Return([]*entities.CompletionEvent{{ID: "event-1"}}, nil)
+	habitTaskRepo.On("Complete", "task-9", mock.Anything).Return(true, nil)
+	return habitTaskRepo, completionEventRepo
+}
+
+func Test_IngestService_Ingest_RecordsVerdict(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC)
+	habitTaskRepo, completionEventRepo := expectVerifiedIngest(now)
+	fake := &verifier.FakeVerifier{}
+	evidence := &entities.EvidenceAttachment{Key: "blob-1", FileName: "streak.png", ContentType: "image/png"}
+
+	svc := NewIngestService(nil, newIngestUserRepo("UTC"), habitTaskRepo, completionEventRepo, nil, nil, fake, nil)
+	svc.now = func() time.Time { return now }
+
+	event, _, err := svc.Ingest("duolingo", "user-1", "grind-1", map[string]interface{}{
+		"lesson":     "Spanish 1",
+		"occurredAt": now.Add(-time.Hour).Format(time.RFC3339),
+	}, evidence)
+	assert.NoError(t, err)
+	assert.Equal(t, &entities.CompletionVerdict{Plausible: true, Confidence: 0.9, Reason: "evidence attached", Verifier: "fake"}, event.Verdict())
+	assert.Contains(t, string(event.Metadata), `"lesson":"Spanish 1"`)
+
+	requests := fake.Requests()
+	if assert.Len(t, requests, 1) {
+		assert.Equal(t, "Complete a Duolingo lesson", requests[0].Habit)
+		assert.Same(t, evidence, requests[0].Evidence)
+	}
+}
+
+func Test_IngestService_Ingest_LowConfidenceGoesToReview(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC)
+	habitTaskRepo, completionEventRepo := expectVerifiedIngest(now)
+	disputeRepos := newTestRepos()
+	expectDisputeGrind(disputeRepos, []string{"user-1", "user-2"}, nil)
+	reviews := NewDisputeService(nil, disputeRepos.grind, disputeRepos.user, disputeRepos.habitTask, disputeRepos.participation,
+		disputeRepos.partnerGroup, disputeRepos.completionEvent, disputeRepos.dispute, disputeRepos.message)
+	reviews.now = func() time.Time { return now }
+	disputeRepos.dispute.On("FindByCompletionEventID", mock.Anything).Return(nil, nil)
+	disputeRepos.dispute.On("Create", mock.MatchedBy(func(d *entities.CompletionDispute) bool {
+		return d.OpenedByVerifier() && d.SubjectID == "user-1" && d.HabitTaskID == "task-9" && d.Reason == "no evidence attached"
+	})).Return(nil)
+
+	// without evidence the fake is only 0.5 sure, below the default 0.6
+	svc := NewIngestService(nil, newIngestUserRepo("UTC"), habitTaskRepo, completionEventRepo, nil, nil, &verifier.FakeVerifier{}, reviews)
+	svc.now = func() time.Time { return now }
+
+	event, _, err := svc.Ingest("duolingo", "user-1", "grind-1", map[string]interface{}{
+		"occurredAt": now.Add(-time.Hour).Format(time.RFC3339),
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.5, event.Verdict().Confidence)
+	disputeRepos.dispute.AssertExpectations(t)
+}
+
+func Test_IngestService_Ingest_VerifierFailureDoesNotBlock(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC)
+	habitTaskRepo, completionEventRepo := expectVerifiedIngest(now)
+	fake := &verifier.FakeVerifier{Err: errors.New("model unavailable")}
+
+	svc := NewIngestService(nil, newIngestUserRepo("UTC"), habitTaskRepo, completionEventRepo, nil, nil, fake, nil)
+	svc.now = func() time.Time { return now }
+
+	event, _, err := svc.Ingest("duolingo", "user-1", "grind-1", map[string]interface{}{
+		"occurredAt": now.Add(-time.Hour).Format(time.RFC3339),
+	}, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, event.Verdict())
+	completionEventRepo.AssertExpectations(t)
+}
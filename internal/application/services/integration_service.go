@@ -0,0 +1,144 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
+	"github.com/daniel0321forever/terriyaki-go/internal/application/mappers"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/repositories"
+)
+
+// integrationSignatureTolerance is how far a signed request's timestamp may be from our
+// clock. A captured request can only be replayed this long, and a replay within it is
+// recorded once like any other retry.
+const integrationSignatureTolerance = 5 * time.Minute
+
+// IntegrationService manages custom provider integrations and authenticates the requests
+// they sign.
+type IntegrationService struct {
+	integrationRepo  repositories.IntegrationRepository
+	partnerGroupRepo repositories.PartnerGroupRepository
+	now              func() time.Time
+}
+
+func NewIntegrationService(
+	integrationRepo repositories.IntegrationRepository,
+	partnerGroupRepo repositories.PartnerGroupRepository,
+) *IntegrationService {
+	return &IntegrationService{
+		integrationRepo:  integrationRepo,
+		partnerGroupRepo: partnerGroupRepo,
+		now:              time.Now,
+	}
+}
+
+// CreateIntegration configures a new integration and returns it with its secret, which is
+// not shown again. A group integration can only be created by the group's owner.
+func (s *IntegrationService) CreateIntegration(request dto.CreateIntegrationDTO) (*dto.IntegrationDTO, error) {
+	if request.GroupID != "" {
+		group, err := s.partnerGroupRepo.FindByID(request.GroupID)
+		if err != nil || group == nil {
+			return nil, fmt.Errorf("%w: partner group %s not found", config.ErrInvalidIntegration, request.GroupID)
+		}
+		if group.OwnerID != request.UserID {
+			return nil, config.ErrForbidden
+		}
+	}
+
+	integration, err := entities.NewIntegration(request.UserID, request.GroupID, request.Name, entities.IntegrationFieldMapping{
+		OccurredAt: request.Mapping.OccurredAt,
+		Evidence:   request.Mapping.Evidence,
+		EventID:    request.Mapping.EventID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", config.ErrInvalidIntegration, err)
+	}
+	if err := s.integrationRepo.Create(integration); err != nil {
+		return nil, fmt.Errorf("failed to persist integration: %w", err)
+	}
+
+	result := mappers.BuildIntegrationDTO(integration)
+	result.Secret = integration.Secret
+	return result, nil
+}
+
+// GetUserIntegrations lists the integrations the user created, without their secrets.
+func (s *IntegrationService) GetUserIntegrations(request dto.GetUserIntegrationsDTO) ([]*dto.IntegrationDTO, error) {
+	integrations, err := s.integrationRepo.FindByOwnerID(request.UserID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*dto.IntegrationDTO, 0, len(integrations))
+	for _, integration := range integrations {
+		result = append(result, mappers.BuildIntegrationDTO(integration))
+	}
+	return result, nil
+}
+
+// DeleteIntegration removes an integration; its secret stops working immediately.
+func (s *IntegrationService) DeleteIntegration(request dto.DeleteIntegrationDTO) error {
+	integration, err := s.integrationRepo.FindByID(request.IntegrationID)
+	if err != nil || integration == nil {
+		return config.ErrIntegrationNotFound
+	}
+	if integration.OwnerID != request.UserID {
+		return config.ErrForbidden
+	}
+	return s.integrationRepo.Delete(integration.ID)
+}
+
+// FindIntegration loads an integration for callers that are trusted without a signature,
+// such as the MCP server.
+func (s *IntegrationService) FindIntegration(integrationID string) (*entities.Integration, error) {
+	integration, err := s.integrationRepo.FindByID(integrationID)
+	if err != nil || integration == nil {
+		return nil, config.ErrIntegrationNotFound
+	}
+	return integration, nil
+}
+
+// Authenticate checks that signature is the integration's HMAC of timestamp and body, and
+// that timestamp, in unix seconds, is within integrationSignatureTolerance of now. An
+// unknown integration fails the same way as a wrong signature so IDs cannot be probed.
+func (s *IntegrationService) Authenticate(integrationID, timestamp string, body []byte, signature string) (*entities.Integration, error) {
+	integration, err := s.integrationRepo.FindByID(integrationID)
+	if err != nil || integration == nil {
+		return nil, config.ErrInvalidSignature
+	}
+	if !integration.VerifySignature(timestamp, body, signature) {
+		return nil, config.ErrInvalidSignature
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: the timestamp is not in unix seconds", config.ErrInvalidSignature)
+	}
+	skew := s.now().Sub(time.Unix(seconds, 0))
+	if skew > integrationSignatureTolerance || skew < -integrationSignatureTolerance {
+		return nil, fmt.Errorf("%w: the request was signed more than %s from now", config.ErrInvalidSignature, integrationSignatureTolerance)
+	}
+	return integration, nil
+}
+
+// ResolveUser returns who a completion sent through the integration is for: claimedUserID,
+// or the owner when it is empty. Returns ErrForbidden when the integration cannot ingest
+// for that user.
+func (s *IntegrationService) ResolveUser(integration *entities.Integration, claimedUserID string) (string, error) {
+	if claimedUserID == "" {
+		return integration.OwnerID, nil
+	}
+	var group *entities.PartnerGroup
+	if integration.GroupID != "" && claimedUserID != integration.OwnerID {
+		found, err := s.partnerGroupRepo.FindByID(integration.GroupID)
+		if err == nil {
+			group = found
+		}
+	}
+	if !integration.CanIngestFor(claimedUserID, group) {
+		return "", config.ErrForbidden
+	}
+	return claimedUserID, nil
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_IntegrationService_CreateIntegration(t *testing.T) {
+	t.Parallel()
+
+	integrationRepo := new(mocks.MockIntegrationRepository)
+	partnerGroupRepo := new(mocks.MockPartnerGroupRepository)
+	partnerGroupRepo.On("FindByID", "group-1").Return(&entities.PartnerGroup{ID: "group-1", OwnerID: "owner"}, nil)
+	integrationRepo.On("Create", mock.MatchedBy(func(i *entities.Integration) bool {
+		return i.OwnerID == "owner" && i.GroupID == "group-1" && i.Mapping.Evidence == "run.url"
+	})).Return(nil)
+
+	svc := NewIntegrationService(integrationRepo, partnerGroupRepo)
+
+	created, err := svc.CreateIntegration(dto.CreateIntegrationDTO{
+		UserID:  "owner",
+		Name:    "Team runs",
+		GroupID: "group-1",
+		Mapping: dto.IntegrationFieldMappingDTO{Evidence: "run.url"},
+	})
+	require.NoError(t, err)
+	assert.Len(t, created.Secret, 64)
+	assert.Equal(t, "run.url", created.Mapping.Evidence)
+
+	_, err = svc.CreateIntegration(dto.CreateIntegrationDTO{UserID: "member", Name: "Team runs", GroupID: "group-1"})
+	assert.ErrorIs(t, err, config.ErrForbidden)
+
+	_, err = svc.CreateIntegration(dto.CreateIntegrationDTO{UserID: "owner", Name: ""})
+	assert.ErrorIs(t, err, config.ErrInvalidIntegration)
+
+	integrationRepo.AssertNumberOfCalls(t, "Create", 1)
+}
+
+func Test_IntegrationService_Authenticate(t *testing.T) {
+	t.Parallel()
+
+	integration := &entities.Integration{ID: "int-1", OwnerID: "owner", Secret: "s3cret"}
+	integrationRepo := new(mocks.MockIntegrationRepository)
+	integrationRepo.On("FindByID", "int-1").Return(integration, nil)
+	integrationRepo.On("FindByID", "missing").Return(nil, errors.New("record not found"))
+
+	svc := NewIntegrationService(integrationRepo, new(mocks.MockPartnerGroupRepository))
+	svc.now = func() time.Time { return time.Unix(1775822400, 0) }
+	body := []byte(`{"grindID":"grind-1"}`)
+
+	found, err := svc.Authenticate("int-1", "1775822400", body, integration.Sign("1775822400", body))
+	require.NoError(t, err)
+	assert.Equal(t, "int-1", found.ID)
+
+	// a little clock skew is fine
+	_, err = svc.Authenticate("int-1", "1775822640", body, integration.Sign("1775822640", body))
+	require.NoError(t, err)
+
+	_, err = svc.Authenticate("int-1", "1775822400", []byte(`{"grindID":"grind-2"}`), integration.Sign("1775822400", body))
+	assert.ErrorIs(t, err, config.ErrInvalidSignature)
+
+	_, err = svc.Authenticate("missing", "1775822400", body, integration.Sign("1775822400", body))
+	assert.ErrorIs(t, err, config.ErrInvalidSignature)
+
+	// a request captured ten minutes ago cannot be replayed
+	_, err = svc.Authenticate("int-1", "1775821800", body, integration.Sign("1775821800", body))
+	assert.ErrorIs(t, err, config.ErrInvalidSignature)
+
+	_, err = svc.Authenticate("int-1", "yesterday", body, integration.Sign("yesterday", body))
+	assert.ErrorIs(t, err, config.ErrInvalidSignature)
+}
+
+func Test_IntegrationService_ResolveUser(t *testing.T) {
+	t.Parallel()
+
+	partnerGroupRepo := new(mocks.MockPartnerGroupRepository)
+	partnerGroupRepo.On("FindByID", "group-1").Return(&entities.PartnerGroup{ID: "group-1", Members: []string{"owner", "member"}}, nil)
+	svc := NewIntegrationService(new(mocks.MockIntegrationRepository), partnerGroupRepo)
+
+	personal := &entities.Integration{ID: "int-1", OwnerID: "owner"}
+	team := &entities.Integration{ID: "int-2", OwnerID: "owner", GroupID: "group-1"}
+
+	userID, err := svc.ResolveUser(personal, "")
+	require.NoError(t, err)
+	assert.Equal(t, "owner", userID)
+
+	_, err = svc.ResolveUser(personal, "member")
+	assert.ErrorIs(t, err, config.ErrForbidden)
+
+	userID, err = svc.ResolveUser(team, "member")
+	require.NoError(t, err)
+	assert.Equal(t, "member", userID)
+
+	_, err = svc.ResolveUser(team, "stranger")
+	assert.ErrorIs(t, err, config.ErrForbidden)
+}
+
+func Test_IntegrationService_DeleteIntegration(t *testing.T) {
+	t.Parallel()
+
+	integrationRepo := new(mocks.MockIntegrationRepository)
+	integrationRepo.On("FindByID", "int-1").Return(&entities.Integration{ID: "int-1", OwnerID: "owner"}, nil)
+	integrationRepo.On("FindByID", "missing").Return(nil, errors.New("record not found"))
+	integrationRepo.On("Delete", "int-1").Return(nil)
+
+	svc := NewIntegrationService(integrationRepo, new(mocks.MockPartnerGroupRepository))
+
+	assert.ErrorIs(t, svc.DeleteIntegration(dto.DeleteIntegrationDTO{UserID: "stranger", IntegrationID: "int-1"}), config.ErrForbidden)
+	assert.ErrorIs(t, svc.DeleteIntegration(dto.DeleteIntegrationDTO{UserID: "owner", IntegrationID: "missing"}), config.ErrIntegrationNotFound)
+	assert.NoError(t, svc.DeleteIntegration(dto.DeleteIntegrationDTO{UserID: "owner", IntegrationID: "int-1"}))
+	integrationRepo.AssertNumberOfCalls(t, "Delete", 1)
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
+	"github.com/daniel0321forever/terriyaki-go/internal/application/mappers"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/repositories"
+)
+
+const (
+	LeaderboardSortCompletionRate = "completionRate"
+	LeaderboardSortCurrentStreak  = "currentStreak"
+	LeaderboardSortLongestStreak  = "longestStreak"
+	LeaderboardSortMissedDays     = "missedDays"
+	LeaderboardSortPenalty        = "penalty"
+)
+
+// leaderboardCacheTTL bounds how stale a leaderboard can be. Statistics only move when a
+// task is completed or a day is evaluated, so a minute is plenty.
+const leaderboardCacheTTL = time.Minute
+
+// LeaderboardCache stores a grind's computed statistics between requests. Implementations
+// may drop values at any time; a miss recomputes them from the database.
+type LeaderboardCache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte, ttl time.Duration)
+}
+
+// cachedLeaderboard is what LeaderboardService keeps in the cache per grind. It holds the
+// unsorted statistics so every sort order is served from the same entry.
+type cachedLeaderboard struct {
+	GeneratedAt time.Time                    `json:"generatedAt"`
+	Stats       []*entities.ParticipantStats `json:"stats"`
+}
+
+// LeaderboardService ranks the participants of a grind by their completion statistics.
+type LeaderboardService struct {
+	grindRepo         repositories.GrindRepository
+	participationRepo repositories.ParticipationRepository
+	statsRepo         repositories.GrindStatsRepository
+	cache             LeaderboardCache
+	now               func() time.Time
+}
+
+func NewLeaderboardService(
+	grindRepo repositories.GrindRepository,
+	participationRepo repositories.ParticipationRepository,
+	statsRepo repositories.GrindStatsRepository,
+	cache LeaderboardCache,
+) *LeaderboardService {
+	return &LeaderboardService{
+		grindRepo:         grindRepo,
+		participationRepo: participationRepo,
+		statsRepo:         statsRepo,
+		cache:             cache,
+		now:               time.Now,
+	}
+}
+
+// GetLeaderboard ranks the grind's participants by request.SortBy. Only participants,
+// including those who quit, can see a grind's leaderboard.
+func (s *LeaderboardService) GetLeaderboard(request dto.GetLeaderboardDTO) (*dto.GrindLeaderboardDTO, error) {
+	sortBy := request.SortBy
+	if sortBy == "" {
+		sortBy = LeaderboardSortCompletionRate
+	}
+	less, ok := leaderboardOrders[sortBy]
+	if !ok {
+		return nil, fmt.Errorf("%w: sortBy must be one of completionRate, currentStreak, longestStreak, missedDays or penalty", config.ErrInvalidLeaderboardSort)
+	}
+
+	grind, err := s.grindRepo.FindById(request.GrindID)
+	if err != nil || grind == nil {
+		return nil, config.ErrGrindNotFound
+	}
+	participation, err := s.participationRepo.FindByUserAndGrind(request.UserID, grind.ID)
+	if err != nil || participation == nil {
+		return nil, config.ErrUserIsNotParticipant
+	}
+
+	board, err := s.loadStats(grind.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := append([]*entities.ParticipantStats(nil), board.Stats...)
+	sort.SliceStable(stats, func(i, j int) bool {
+		if stats[i].Quitted != stats[j].Quitted {
+			return !stats[i].Quitted
+		}
+		if less(stats[i], stats[j]) {
+			return true
+		}
+		if less(stats[j], stats[i]) {
+			return false
+		}
+		return stats[i].Username < stats[j].Username
+	})
+
+	result := &dto.GrindLeaderboardDTO{
+		GrindID:     grind.ID,
+		SortBy:      sortBy,
+		GeneratedAt: board.GeneratedAt,
+		Entries:     make([]dto.LeaderboardEntryDTO, 0, len(stats)),
+	}
+	rank := 0
+	for i, entry := range stats {
+		// tied participants share a rank; the next one skips ahead (1, 2, 2, 4)
+		if i == 0 || stats[i-1].Quitted != entry.Quitted || less(stats[i-1], entry) {
+			rank = i + 1
+		}
+		result.Entries = append(result.Entries, *mappers.BuildLeaderboardEntryDTO(entry, rank))
+	}
+	return result, nil
+}
+
+// loadStats returns the grind's statistics from the cache, computing and caching them on a miss.
+func (s *LeaderboardService) loadStats(grindID string) (*cachedLeaderboard, error) {
+	if s.cache != nil {
+		if raw, ok := s.cache.Get(grindID); ok {
+			var board cachedLeaderboard
+			if err := json.Unmarshal(raw, &board); err == nil {
+				return &board, nil
+			}
+		}
+	}
+
+	stats, err := s.statsRepo.FindParticipantStats(grindID)
+	if err != nil {
+		return nil, err
+	}
+	board := &cachedLeaderboard{GeneratedAt: s.now().UTC(), Stats: stats}
+	if s.cache != nil {
+		if raw, err := json.Marshal(board); err == nil {
+			s.cache.Set(grindID, raw, leaderboardCacheTTL)
+		}
+	}
+	return board, nil
+}
+
+// leaderboardOrders reports whether a ranks strictly above b for each sort key. Ties on
+// the key fall back to the completion rate, or to the current streak when sorting by rate.
+var leaderboardOrders = map[string]func(a, b *entities.ParticipantStats) bool{
+	LeaderboardSortCompletionRate: func(a, b *entities.ParticipantStats) bool {
+		if a.CompletionRate() != b.CompletionRate() {
+			return a.CompletionRate() > b.CompletionRate()
+		}
+		return a.CurrentStreak > b.CurrentStreak
+	},
+	LeaderboardSortCurrentStreak: func(a, b *entities.ParticipantStats) bool {
+		if a.CurrentStreak != b.CurrentStreak {
+			return a.CurrentStreak > b.CurrentStreak
+		}
+		return a.CompletionRate() > b.CompletionRate()
+	},
+	LeaderboardSortLongestStreak: func(a, b *entities.ParticipantStats) bool {
+		if a.LongestStreak != b.LongestStreak {
+			return a.LongestStreak > b.LongestStreak
+		}
+		return a.CompletionRate() > b.CompletionRate()
+	},
+	LeaderboardSortMissedDays: func(a, b *entities.ParticipantStats) bool {
+		if a.MissedDays != b.MissedDays {
+			return a.MissedDays < b.MissedDays
+		}
+		return a.CompletionRate() > b.CompletionRate()
+	},
+	LeaderboardSortPenalty: func(a, b *entities.ParticipantStats) bool {
+		if a.TotalPenalty != b.TotalPenalty {
+			return a.TotalPenalty < b.TotalPenalty
+		}
+		return a.CompletionRate() > b.CompletionRate()
+	},
+}
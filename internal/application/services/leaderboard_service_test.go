@@ -0,0 +1,141 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLeaderboardCache is an in-memory LeaderboardCache that records the TTLs it was given.
+type fakeLeaderboardCache struct {
+	values map[string][]byte
+	ttls   map[string]time.Duration
+}
+
+func newFakeLeaderboardCache() *fakeLeaderboardCache {
+	return &fakeLeaderboardCache{values: map[string][]byte{}, ttls: map[string]time.Duration{}}
+}
+
+func (c *fakeLeaderboardCache) Get(key string) ([]byte, bool) {
+	value, ok := c.values[key]
+	return value, ok
+}
+
+func (c *fakeLeaderboardCache) Set(key string, value []byte, ttl time.Duration) {
+	c.values[key] = value
+	c.ttls[key] = ttl
+}
+
+func leaderboardFixture() []*entities.ParticipantStats {
+	return []*entities.ParticipantStats{
+		{UserID: "u-amy", Username: "amy", CompletedDays: 8, ClosedDays: 10, CurrentStreak: 1, LongestStreak: 6, MissedDays: 2, TotalPenalty: 20},
+		{UserID: "u-bob", Username: "bob", CompletedDays: 9, ClosedDays: 10, CurrentStreak: 9, LongestStreak: 9, MissedDays: 1, TotalPenalty: 10},
+		{UserID: "u-cat", Username: "cat", CompletedDays: 8, ClosedDays: 10, CurrentStreak: 1, LongestStreak: 4, MissedDays: 2, TotalPenalty: 20},
+		{UserID: "u-dan", Username: "dan", CompletedDays: 10, ClosedDays: 10, CurrentStreak: 10, LongestStreak: 10, Quitted: true},
+	}
+}
+
+func newLeaderboardTestService(t *testing.T, cache LeaderboardCache) (*LeaderboardService, *mocks.MockGrindStatsRepository) {
+	t.Helper()
+	grindRepo := new(mocks.MockGrindRepository)
+	participationRepo := new(mocks.MockParticipationRepository)
+	statsRepo := new(mocks.MockGrindStatsRepository)
+
+	grindRepo.On("FindById", "grind-1").Return(&entities.Grind{ID: "grind-1"}, nil)
+	grindRepo.On("FindById", "missing").Return(nil, errors.New("record not found"))
+	participationRepo.On("FindByUserAndGrind", "u-amy", "grind-1").Return(&entities.Participation{UserID: "u-amy", GrindID: "grind-1"}, nil)
+	participationRepo.On("FindByUserAndGrind", "stranger", "grind-1").Return(nil, errors.New("record not found"))
+
+	svc := NewLeaderboardService(grindRepo, participationRepo, statsRepo, cache)
+	svc.now = func() time.Time { return time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC) }
+	return svc, statsRepo
+}
+
+func leaderboardRanks(board *dto.GrindLeaderboardDTO) map[string]int {
+	ranks := map[string]int{}
+	for _, entry := range board.Entries {
+		ranks[entry.Username] = entry.Rank
+	}
+	return ranks
+}
+
+func leaderboardOrder(board *dto.GrindLeaderboardDTO) []string {
+	order := make([]string, 0, len(board.Entries))
+	for _, entry := range board.Entries {
+		order = append(order, entry.Username)
+	}
+	return order
+}
+
+func Test_LeaderboardService_GetLeaderboard_Ranking(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		sortBy string
+		order  []string
+		ranks  map[string]int
+	}{
+		{sortBy: "", order: []string{"bob", "amy", "cat", "dan"}, ranks: map[string]int{"bob": 1, "amy": 2, "cat": 2, "dan": 4}},
+		{sortBy: LeaderboardSortLongestStreak, order: []string{"bob", "amy", "cat", "dan"}, ranks: map[string]int{"bob": 1, "amy": 2, "cat": 3, "dan": 4}},
+		{sortBy: LeaderboardSortPenalty, order: []string{"bob", "amy", "cat", "dan"}, ranks: map[string]int{"bob": 1, "amy": 2, "cat": 2, "dan": 4}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run("sortBy="+tt.sortBy, func(t *testing.T) {
+			t.Parallel()
+
+			svc, statsRepo := newLeaderboardTestService(t, nil)
+			statsRepo.On("FindParticipantStats", "grind-1").Return(leaderboardFixture(), nil)
+
+			board, err := svc.GetLeaderboard(dto.GetLeaderboardDTO{GrindID: "grind-1", UserID: "u-amy", SortBy: tt.sortBy})
+			require.NoError(t, err)
+			assert.Equal(t, tt.order, leaderboardOrder(board))
+			assert.Equal(t, tt.ranks, leaderboardRanks(board))
+			assert.InDelta(t, 0.9, board.Entries[0].CompletionRate, 1e-9)
+		})
+	}
+}
+
+func Test_LeaderboardService_GetLeaderboard_ServesFromCache(t *testing.T) {
+	t.Parallel()
+
+	cache := newFakeLeaderboardCache()
+	svc, statsRepo := newLeaderboardTestService(t, cache)
+	statsRepo.On("FindParticipantStats", "grind-1").Return(leaderboardFixture(), nil).Once()
+
+	first, err := svc.GetLeaderboard(dto.GetLeaderboardDTO{GrindID: "grind-1", UserID: "u-amy"})
+	require.NoError(t, err)
+	assert.Equal(t, leaderboardCacheTTL, cache.ttls["grind-1"])
+
+	// a different sort order is served from the same cache entry
+	second, err := svc.GetLeaderboard(dto.GetLeaderboardDTO{GrindID: "grind-1", UserID: "u-amy", SortBy: LeaderboardSortCurrentStreak})
+	require.NoError(t, err)
+	assert.Equal(t, first.GeneratedAt, second.GeneratedAt)
+	assert.Equal(t, []string{"bob", "amy", "cat", "dan"}, leaderboardOrder(second))
+	statsRepo.AssertNumberOfCalls(t, "FindParticipantStats", 1)
+}
+
+func Test_LeaderboardService_GetLeaderboard_Errors(t *testing.T) {
+	t.Parallel()
+
+	svc, statsRepo := newLeaderboardTestService(t, nil)
+
+	_, err := svc.GetLeaderboard(dto.GetLeaderboardDTO{GrindID: "grind-1", UserID: "u-amy", SortBy: "karma"})
+	assert.ErrorIs(t, err, config.ErrInvalidLeaderboardSort)
+
+	_, err = svc.GetLeaderboard(dto.GetLeaderboardDTO{GrindID: "missing", UserID: "u-amy"})
+	assert.ErrorIs(t, err, config.ErrGrindNotFound)
+
+	_, err = svc.GetLeaderboard(dto.GetLeaderboardDTO{GrindID: "grind-1", UserID: "stranger"})
+	assert.ErrorIs(t, err, config.ErrUserIsNotParticipant)
+
+	statsRepo.AssertNotCalled(t, "FindParticipantStats", "grind-1")
+}
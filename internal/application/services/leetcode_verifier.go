@@ -0,0 +1,73 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+)
+
+// leetCodeSubmissionLimit is how many recent accepted submissions are fetched per check.
+// LeetCode caps the public list at 20.
+const leetCodeSubmissionLimit = 20
+
+// leetCodeVerifyTolerance is how far a submission's timestamp may be from the claimed
+// occurredAt. It absorbs clock skew and the delay between submitting and the extension
+// reporting the completion.
+const leetCodeVerifyTolerance = 15 * time.Minute
+
+// LeetCodeClient reads a user's public submission history from LeetCode.
+type LeetCodeClient interface {
+	// RecentAcceptedSubmissions returns up to limit of the user's most recent accepted
+	// submissions, newest first.
+	RecentAcceptedSubmissions(username string, limit int) ([]entities.LeetCodeSubmission, error)
+}
+
+// LeetCodeVerifier checks a claimed LeetCode completion against the accepted submissions
+// on the user's public profile.
+type LeetCodeVerifier struct {
+	client    LeetCodeClient
+	limit     int
+	tolerance time.Duration
+}
+
+func NewLeetCodeVerifier(client LeetCodeClient) *LeetCodeVerifier {
+	return &LeetCodeVerifier{
+		client:    client,
+		limit:     leetCodeSubmissionLimit,
+		tolerance: leetCodeVerifyTolerance,
+	}
+}
+
+// Verify succeeds when the user has an accepted submission of slug within the tolerance
+// of occurredAt. It fails with ErrLeetCodeUsernameMissing when the user has not linked a
+// LeetCode profile, ErrSubmissionNotVerified when no submission matches and
+// ErrLeetCodeUnavailable when LeetCode cannot be queried.
+func (v *LeetCodeVerifier) Verify(user *entities.User, slug string, occurredAt time.Time) error {
+	if user.LeetCodeUsername == "" {
+		return config.ErrLeetCodeUsernameMissing
+	}
+	if slug == "" {
+		return fmt.Errorf("%w: the payload names no problem", config.ErrSubmissionNotVerified)
+	}
+
+	submissions, err := v.client.RecentAcceptedSubmissions(user.LeetCodeUsername, v.limit)
+	if err != nil {
+		return fmt.Errorf("%w: %v", config.ErrLeetCodeUnavailable, err)
+	}
+	for _, submission := range submissions {
+		if submission.Slug != slug {
+			continue
+		}
+		diff := submission.SubmittedAt.Sub(occurredAt)
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff <= v.tolerance {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: no accepted %q submission by %s around %s",
+		config.ErrSubmissionNotVerified, slug, user.LeetCodeUsername, occurredAt.UTC().Format(time.RFC3339))
+}
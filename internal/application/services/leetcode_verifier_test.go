@@ -0,0 +1,71 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeLeetCodeClient serves a fixed submission list and records the usernames it was asked for.
+type fakeLeetCodeClient struct {
+	submissions []entities.LeetCodeSubmission
+	err         error
+	usernames   []string
+}
+
+func (c *fakeLeetCodeClient) RecentAcceptedSubmissions(username string, limit int) ([]entities.LeetCodeSubmission, error) {
+	c.usernames = append(c.usernames, username)
+	return c.submissions, c.err
+}
+
+func Test_LeetCodeVerifier_Verify(t *testing.T) {
+	t.Parallel()
+
+	solvedAt := time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC)
+	client := &fakeLeetCodeClient{submissions: []entities.LeetCodeSubmission{
+		{ID: "2", Slug: "two-sum", SubmittedAt: solvedAt},
+		{ID: "1", Slug: "valid-anagram", SubmittedAt: solvedAt.Add(-24 * time.Hour)},
+	}}
+	verifier := NewLeetCodeVerifier(client)
+	user := &entities.User{ID: "user-1", LeetCodeUsername: "neo"}
+
+	tests := []struct {
+		name       string
+		slug       string
+		occurredAt time.Time
+		wantErr    error
+	}{
+		{name: "matching submission", slug: "two-sum", occurredAt: solvedAt.Add(2 * time.Minute)},
+		{name: "older submission inside the tolerance", slug: "two-sum", occurredAt: solvedAt.Add(-14 * time.Minute)},
+		{name: "other problem", slug: "3sum", occurredAt: solvedAt, wantErr: config.ErrSubmissionNotVerified},
+		{name: "outside the tolerance", slug: "valid-anagram", occurredAt: solvedAt, wantErr: config.ErrSubmissionNotVerified},
+		{name: "no slug", slug: "", occurredAt: solvedAt, wantErr: config.ErrSubmissionNotVerified},
+	}
+	for _, tt := range tests {
+		err := verifier.Verify(user, tt.slug, tt.occurredAt)
+		if tt.wantErr == nil {
+			assert.NoError(t, err, tt.name)
+		} else {
+			assert.ErrorIs(t, err, tt.wantErr, tt.name)
+		}
+	}
+	assert.Contains(t, client.usernames, "neo")
+}
+
+func Test_LeetCodeVerifier_Verify_Errors(t *testing.T) {
+	t.Parallel()
+
+	client := &fakeLeetCodeClient{err: errors.New("connection refused")}
+	verifier := NewLeetCodeVerifier(client)
+
+	err := verifier.Verify(&entities.User{ID: "user-1"}, "two-sum", time.Now())
+	assert.ErrorIs(t, err, config.ErrLeetCodeUsernameMissing)
+	assert.Empty(t, client.usernames)
+
+	err = verifier.Verify(&entities.User{ID: "user-1", LeetCodeUsername: "neo"}, "two-sum", time.Now())
+	assert.ErrorIs(t, err, config.ErrLeetCodeUnavailable)
+}
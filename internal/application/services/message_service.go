@@ -2,10 +2,14 @@ package services
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
 	"github.com/daniel0321forever/terriyaki-go/internal/application/mappers"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
 	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
 	"github.com/daniel0321forever/terriyaki-go/internal/domain/repositories"
 	"gorm.io/gorm"
@@ -29,19 +33,23 @@ func NewMessageService(db *gorm.DB, messageRepo repositories.MessageRepository,
 
 // Convert Message entity to Message DTO (including related entity fetching from DB)
 func (s *MessageService) toMessageDTO(message *entities.Message) (*dto.MessageDTO, error) {
-	sender, err := s.userRepo.FindById(message.SenderID)
+	return buildMessageDTO(s.userRepo, s.grindRepo, message)
+}
+
+func buildMessageDTO(userRepo repositories.UserRepository, grindRepo repositories.GrindRepository, message *entities.Message) (*dto.MessageDTO, error) {
+	sender, err := userRepo.FindById(message.SenderID)
 	if err != nil {
 		return nil, err
 	}
 
-	receiver, err := s.userRepo.FindById(message.ReceiverID)
+	receiver, err := userRepo.FindById(message.ReceiverID)
 	if err != nil {
 		return nil, err
 	}
 
 	var invitationGrind *entities.Grind
 	if message.InvitationGrindID != "" {
-		grind, err := s.grindRepo.FindById(message.InvitationGrindID)
+		grind, err := grindRepo.FindById(message.InvitationGrindID)
 		if err == nil {
 			invitationGrind = grind
 		}
@@ -50,9 +58,26 @@ func (s *MessageService) toMessageDTO(message *entities.Message) (*dto.MessageDT
 	return mappers.BuildMessageDTO(message, sender, receiver, invitationGrind), nil
 }
 
+// CreateInvitationMessage invites the user with the request's email to the grind. The
+// invitation expires after entities.InvitationTTL; until it expires, is answered or is
+// revoked, the receiver cannot be invited to the same grind again (ErrInvitationPending).
 func (s *MessageService) CreateInvitationMessage(request dto.CreateInvitationMessageDTO) (*dto.MessageDTO, error) {
 	// get receiver
 	receiver, err := s.userRepo.FindByEmail(request.ReceiverEmail)
+	if err != nil {
+		return nil, config.ErrUserNotFound
+	}
+	grind, err := s.grindRepo.FindById(request.GrindID)
+	if err != nil {
+		return nil, config.ErrGrindNotFound
+	}
+	if err := grind.ValidateInvite(); err != nil {
+		return nil, err
+	}
+	content, err := RenderMessage(receiver.Locale, InvitationSentEvent{
+		Inviter: displayName(s.userRepo, request.SenderID),
+		Grind:   grindDetails(grind),
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -61,9 +86,9 @@ func (s *MessageService) CreateInvitationMessage(request dto.CreateInvitationMes
 	message, err := entities.NewMessage(
 		request.SenderID,
 		receiver.ID,
-		request.SenderID+" invited you to join a grind",
-		"invitation",
-		"",
+		content,
+		config.MESSAGE_TYPE_INVITATION,
+		request.GrindID,
 		false, // invitationAccepted
 		false, // invitationRejected
 	)
@@ -71,7 +96,33 @@ func (s *MessageService) CreateInvitationMessage(request dto.CreateInvitationMes
 		return nil, err
 	}
 
-	err = s.messageRepo.Create(message)
+	err = runInTransaction(s.db, func(tx *gorm.DB) error {
+		msgRepo := getMessageRepo(s.messageRepo, tx)
+
+		open, err := msgRepo.FindOpenInvitation(request.GrindID, receiver.ID)
+		if err != nil {
+			return err
+		}
+		if open != nil {
+			if !open.InvitationExpired(message.CreatedAt) {
+				return config.ErrInvitationPending
+			}
+			// an expired invitation gives way to the new one
+			open.RevokeInvitation(message.CreatedAt)
+			if err := msgRepo.Update(open); err != nil {
+				return err
+			}
+		}
+
+		created, err := msgRepo.CreateInvitationIfAbsent(message)
+		if err != nil {
+			return err
+		}
+		if !created {
+			return config.ErrInvitationPending
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -79,13 +130,77 @@ func (s *MessageService) CreateInvitationMessage(request dto.CreateInvitationMes
 	return s.toMessageDTO(message)
 }
 
+// RevokeInvitation withdraws an invitation its sender no longer wants answered.
+func (s *MessageService) RevokeInvitation(request dto.RevokeInvitationDTO) (*dto.MessageDTO, error) {
+	var invitation *entities.Message
+	err := runInTransaction(s.db, func(tx *gorm.DB) error {
+		msgRepo := getMessageRepo(s.messageRepo, tx)
+
+		var err error
+		invitation, err = msgRepo.FindByID(request.MessageID)
+		if err != nil || invitation.Type != config.MESSAGE_TYPE_INVITATION {
+			return config.ErrInvitationNotFound
+		}
+		if invitation.SenderID != request.SenderID {
+			return config.ErrNotInvitationSender
+		}
+		now := time.Now().UTC()
+		if err := checkInvitationOpen(invitation, now); err != nil {
+			return err
+		}
+
+		invitation.RevokeInvitation(now)
+		return msgRepo.Update(invitation)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toMessageDTO(invitation)
+}
+
+// checkInvitationAnswerable tells why receiverID cannot accept or reject invitation at the
+// given time, or returns nil when they can.
+func checkInvitationAnswerable(invitation *entities.Message, receiverID string, at time.Time) error {
+	if invitation.Type != config.MESSAGE_TYPE_INVITATION {
+		return config.ErrInvitationNotFound
+	}
+	if invitation.ReceiverID != receiverID {
+		return config.ErrNotInvitationReceiver
+	}
+	return checkInvitationOpen(invitation, at)
+}
+
+func checkInvitationOpen(invitation *entities.Message, at time.Time) error {
+	switch {
+	case invitation.InvitationAccepted || invitation.InvitationRejected:
+		return config.ErrInvitationAnswered
+	case invitation.InvitationRevoked():
+		return config.ErrInvitationRevoked
+	case invitation.InvitationExpired(at):
+		return config.ErrInvitationExpired
+	}
+	return nil
+}
+
 func (s *MessageService) CreateInvitationAcceptedMessage(request dto.CreateInvitationAcceptedMessageDTO) (*dto.MessageDTO, error) {
+	grind, err := s.grindRepo.FindById(request.GrindID)
+	if err != nil {
+		return nil, config.ErrGrindNotFound
+	}
+	content, err := renderMessageFor(s.userRepo, request.InvitorID, InvitationAcceptedEvent{
+		Accepter: displayName(s.userRepo, request.AccepterID),
+		Grind:    grindDetails(grind),
+	})
+	if err != nil {
+		return nil, err
+	}
 
 	// Create message entity using constructor
 	message, err := entities.NewMessage(
 		request.AccepterID,
 		request.InvitorID,
-		request.AccepterID+" accepted your invitation",
+		content,
 		"invitation_accepted",
 		request.GrindID,
 		true,  // invitationAccepted
@@ -104,11 +219,23 @@ func (s *MessageService) CreateInvitationAcceptedMessage(request dto.CreateInvit
 }
 
 func (s *MessageService) CreateInvitationRejectedMessage(request dto.CreateInvitationRejectedMessageDTO) (*dto.MessageDTO, error) {
+	grind, err := s.grindRepo.FindById(request.GrindID)
+	if err != nil {
+		return nil, config.ErrGrindNotFound
+	}
+	content, err := renderMessageFor(s.userRepo, request.InvitorID, InvitationRejectedEvent{
+		Rejecter: displayName(s.userRepo, request.RejecterID),
+		Grind:    grindDetails(grind),
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	// Create message entity using constructor
 	message, err := entities.NewMessage(
 		request.RejecterID,
 		request.InvitorID,
-		request.RejecterID+" rejected your invitation",
+		content,
 		"invitation_rejected",
 		request.GrindID,
 		false, // invitationAccepted
@@ -134,20 +261,32 @@ func (s *MessageService) GetMessageByID(request dto.GetMessageDTO) (*dto.Message
 	return s.toMessageDTO(message)
 }
 
-func (s *MessageService) GetAllMessagesForReceiver(request dto.GetAllMessagesForReceiverDTO) ([]*dto.MessageDTO, error) {
-	messages, err := s.messageRepo.FindAllForReceiver(request.ReceiverID, request.Offset, request.Limit)
+// GetAllMessagesForReceiver returns a page of the receiver's messages, newest first.
+func (s *MessageService) GetAllMessagesForReceiver(request dto.GetAllMessagesForReceiverDTO) (*dto.MessagePageDTO, error) {
+	filter, err := toMessageFilter(request.Filter)
+	if err != nil {
+		return nil, err
+	}
+	after, err := decodePageCursor(request.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := s.messageRepo.FindAllForReceiver(request.ReceiverID, filter, after, pageFetchLimit(request.Limit))
 	if err != nil {
 		return nil, errors.New("message not found")
 	}
-	var output []*dto.MessageDTO
-	for _, message := range messages {
-		messageDTO, dtoErr := s.toMessageDTO(message)
-		if dtoErr != nil {
-			return nil, dtoErr
-		}
-		output = append(output, messageDTO)
+	return s.toMessagePageDTO(messages, request.Limit)
+}
+
+// CountUnreadMessages counts the receiver's unread messages that match the filter.
+func (s *MessageService) CountUnreadMessages(request dto.CountUnreadMessagesDTO) (int64, error) {
+	filter, err := toMessageFilter(request.Filter)
+	if err != nil {
+		return 0, err
 	}
-	return output, nil
+	unread := false
+	filter.Read = &unread
+	return s.messageRepo.CountForReceiver(request.ReceiverID, filter)
 }
 
 func (s *MessageService) UpdateMessageReadStatus(request dto.UpdateMessageReadStatusDTO) (*dto.MessageDTO, error) {
@@ -184,34 +323,62 @@ func (s *MessageService) UpdateMessageInvitationAcceptedStatus(request dto.Updat
 	return s.toMessageDTO(message)
 }
 
-func (s *MessageService) GetAllMessageFromSender(senderID string, offset, limit int) ([]*dto.MessageDTO, error) {
-	messages, err := s.messageRepo.FindAllFromSender(senderID, offset, limit)
+// GetAllMessagesFromSender returns a page of the messages the sender sent, newest first.
+func (s *MessageService) GetAllMessagesFromSender(request dto.GetAllMessagesFromSenderDTO) (*dto.MessagePageDTO, error) {
+	filter, err := toMessageFilter(request.Filter)
+	if err != nil {
+		return nil, err
+	}
+	after, err := decodePageCursor(request.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := s.messageRepo.FindAllFromSender(request.SenderID, filter, after, pageFetchLimit(request.Limit))
 	if err != nil {
 		return nil, errors.New("message not found")
 	}
-	var output []*dto.MessageDTO
+	return s.toMessagePageDTO(messages, request.Limit)
+}
+
+func (s *MessageService) toMessagePageDTO(messages []*entities.Message, limit int) (*dto.MessagePageDTO, error) {
+	messages, nextCursor := trimPage(messages, limit, messagePosition)
+	output := &dto.MessagePageDTO{Messages: make([]*dto.MessageDTO, 0, len(messages)), NextCursor: nextCursor}
 	for _, message := range messages {
-		messageDTO, dtoErr := s.toMessageDTO(message)
-		if dtoErr != nil {
-			return nil, dtoErr
+		messageDTO, err := s.toMessageDTO(message)
+		if err != nil {
+			return nil, err
 		}
-		output = append(output, messageDTO)
+		output.Messages = append(output.Messages, messageDTO)
 	}
 	return output, nil
 }
 
+func toMessageFilter(filter dto.MessageFilterDTO) (entities.MessageFilter, error) {
+	if filter.Type != "" && !entities.IsValidMessageType(filter.Type) {
+		return entities.MessageFilter{}, fmt.Errorf("%w: unknown type %q", config.ErrInvalidMessageFilter, filter.Type)
+	}
+	return entities.MessageFilter{
+		Type:    filter.Type,
+		Read:    filter.Read,
+		GrindID: strings.TrimSpace(filter.GrindID),
+	}, nil
+}
+
 // RejectInvitationTx executes UpdateMessageInvitationAcceptedStatus and CreateInvitationRejectedMessage
 // atomically in a single DB transaction.
 func (s *MessageService) RejectInvitationTx(
 	updateReq dto.UpdateMessageInvitationAcceptedStatusDTO,
 	createReq dto.CreateInvitationRejectedMessageDTO,
 ) error {
-	return s.db.Transaction(func(tx *gorm.DB) error {
+	return runInTransaction(s.db, func(tx *gorm.DB) error {
 		msgRepo := getMessageRepo(s.messageRepo, tx)
 
 		// Update original invitation message to rejected
 		msg, err := msgRepo.FindByID(updateReq.MessageID)
 		if err != nil {
+			return config.ErrInvitationNotFound
+		}
+		if err := checkInvitationAnswerable(msg, createReq.RejecterID, time.Now().UTC()); err != nil {
 			return err
 		}
 		msg.InvitationAccepted = false
@@ -222,10 +389,21 @@ func (s *MessageService) RejectInvitationTx(
 		}
 
 		// Create rejection notification message to invitor
+		grind, err := getGrindRepo(s.grindRepo, tx).FindById(createReq.GrindID)
+		if err != nil {
+			return config.ErrGrindNotFound
+		}
+		content, err := renderMessageFor(s.userRepo, createReq.InvitorID, InvitationRejectedEvent{
+			Rejecter: displayName(s.userRepo, createReq.RejecterID),
+			Grind:    grindDetails(grind),
+		})
+		if err != nil {
+			return err
+		}
 		rejectedMsg, err := entities.NewMessage(
 			createReq.RejecterID,
 			createReq.InvitorID,
-			createReq.RejecterID+" rejected your invitation",
+			content,
 			"invitation_rejected",
 			createReq.GrindID,
 			false,
@@ -237,3 +415,152 @@ func (s *MessageService) RejectInvitationTx(
 		return msgRepo.Create(rejectedMsg)
 	})
 }
+
+// SendGeneralMessage sends a plain message to the user named by the request. It fails
+// with ErrMessagingBlocked when either user has blocked the other.
+func (s *MessageService) SendGeneralMessage(request dto.SendGeneralMessageDTO) (*dto.MessageDTO, error) {
+	content := strings.TrimSpace(request.Content)
+	if content == "" {
+		return nil, fmt.Errorf("%w: content is required", config.ErrInvalidMessage)
+	}
+	if utf8.RuneCountInString(content) > entities.MaxGeneralMessageLength {
+		return nil, fmt.Errorf("%w: content is longer than %d characters", config.ErrInvalidMessage, entities.MaxGeneralMessageLength)
+	}
+
+	var receiver *entities.User
+	var err error
+	switch {
+	case strings.TrimSpace(request.ReceiverID) != "":
+		receiver, err = s.userRepo.FindById(strings.TrimSpace(request.ReceiverID))
+	case strings.TrimSpace(request.ReceiverEmail) != "":
+		receiver, err = s.userRepo.FindByEmail(strings.TrimSpace(request.ReceiverEmail))
+	default:
+		return nil, fmt.Errorf("%w: receiverID or receiverEmail is required", config.ErrInvalidMessage)
+	}
+	if err != nil {
+		return nil, config.ErrUserNotFound
+	}
+	if receiver.ID == request.SenderID {
+		return nil, config.ErrSameRecipientAndSender
+	}
+
+	blocked, err := s.isBlocked(request.SenderID, receiver.ID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, config.ErrMessagingBlocked
+	}
+
+	message, err := entities.NewMessage(request.SenderID, receiver.ID, content, "general", "", false, false)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", config.ErrInvalidMessage, err)
+	}
+	if err := s.messageRepo.Create(message); err != nil {
+		return nil, err
+	}
+	return s.toMessageDTO(message)
+}
+
+// isBlocked reports whether either user has blocked the other.
+func (s *MessageService) isBlocked(userID, counterpartID string) (bool, error) {
+	for _, pair := range [][2]string{{userID, counterpartID}, {counterpartID, userID}} {
+		setting, err := s.messageRepo.FindConversationSetting(pair[0], pair[1])
+		if err != nil {
+			return false, err
+		}
+		if setting != nil && setting.Blocked {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetConversations returns a page of the user's threads of general messages, the most
+// recently active first, each with the number of the counterpart's messages the user has
+// not read.
+func (s *MessageService) GetConversations(request dto.GetConversationsDTO) (*dto.ConversationPageDTO, error) {
+	after, err := decodePageCursor(request.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	conversations, err := s.messageRepo.FindConversations(request.UserID, after, pageFetchLimit(request.Limit))
+	if err != nil {
+		return nil, err
+	}
+	conversations, nextCursor := trimPage(conversations, request.Limit, conversationPosition)
+	output := &dto.ConversationPageDTO{Conversations: make([]*dto.ConversationDTO, 0, len(conversations)), NextCursor: nextCursor}
+	for _, conversation := range conversations {
+		lastMessage, err := s.toMessageDTO(conversation.LastMessage)
+		if err != nil {
+			return nil, err
+		}
+		output.Conversations = append(output.Conversations, mappers.BuildConversationDTO(conversation, lastMessage))
+	}
+	return output, nil
+}
+
+// GetConversationMessages returns a page of the general messages between the user and
+// the counterpart, newest first.
+func (s *MessageService) GetConversationMessages(request dto.GetConversationMessagesDTO) (*dto.MessagePageDTO, error) {
+	if _, err := s.userRepo.FindById(request.CounterpartID); err != nil {
+		return nil, config.ErrUserNotFound
+	}
+	after, err := decodePageCursor(request.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := s.messageRepo.FindConversationMessages(request.UserID, request.CounterpartID, after, pageFetchLimit(request.Limit))
+	if err != nil {
+		return nil, err
+	}
+	return s.toMessagePageDTO(messages, request.Limit)
+}
+
+// ReadConversation marks every general message the counterpart sent the user as read and
+// returns how many were unread.
+func (s *MessageService) ReadConversation(request dto.ReadConversationDTO) (int64, error) {
+	return s.messageRepo.MarkConversationRead(request.UserID, request.CounterpartID)
+}
+
+// UpdateConversationSetting blocks, unblocks, mutes or unmutes the user's conversation
+// with the counterpart, whether or not they have exchanged messages yet.
+func (s *MessageService) UpdateConversationSetting(request dto.UpdateConversationSettingDTO) (*dto.ConversationSettingDTO, error) {
+	if request.Blocked == nil && request.Muted == nil {
+		return nil, fmt.Errorf("%w: blocked or muted is required", config.ErrInvalidConversationSetting)
+	}
+	if request.UserID == request.CounterpartID {
+		return nil, config.ErrSameRecipientAndSender
+	}
+	if _, err := s.userRepo.FindById(request.CounterpartID); err != nil {
+		return nil, config.ErrUserNotFound
+	}
+
+	setting, err := s.messageRepo.FindConversationSetting(request.UserID, request.CounterpartID)
+	if err != nil {
+		return nil, err
+	}
+	create := setting == nil
+	if create {
+		if setting, err = entities.NewConversationSetting(request.UserID, request.CounterpartID); err != nil {
+			return nil, fmt.Errorf("%w: %v", config.ErrInvalidConversationSetting, err)
+		}
+	}
+	if request.Blocked != nil {
+		setting.Blocked = *request.Blocked
+	}
+	if request.Muted != nil {
+		setting.Muted = *request.Muted
+	}
+	setting.UpdatedAt = time.Now().UTC()
+
+	if create {
+		err = s.messageRepo.CreateConversationSetting(setting)
+	} else {
+		err = s.messageRepo.UpdateConversationSetting(setting)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return mappers.BuildConversationSettingDTO(setting), nil
+}
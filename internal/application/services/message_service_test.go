@@ -0,0 +1,455 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// expectMessageParties wires users "amy" and "bob", recruiting grind "grind-1" and draft
+// grind "grind-draft".
+func expectMessageParties(repos testRepos) {
+	repos.grind.On("FindById", "grind-1").Return(&entities.Grind{ID: "grind-1", State: entities.GrindStateRecruiting}, nil).Maybe()
+	repos.grind.On("FindById", "grind-draft").Return(&entities.Grind{ID: "grind-draft", State: entities.GrindStateDraft}, nil).Maybe()
+	repos.grind.On("FindById", mock.Anything).Return(nil, gorm.ErrRecordNotFound).Maybe()
+	for _, user := range []*entities.User{
+		{ID: "amy", Username: "amy", Email: "amy@example.com"},
+		{ID: "bob", Username: "bob", Email: "bob@example.com"},
+	} {
+		repos.user.On("FindById", user.ID).Return(user, nil).Maybe()
+		repos.user.On("FindByEmail", user.Email).Return(user, nil).Maybe()
+	}
+	repos.user.On("FindById", mock.Anything).Return(nil, gorm.ErrRecordNotFound).Maybe()
+	repos.user.On("FindByEmail", mock.Anything).Return(nil, gorm.ErrRecordNotFound).Maybe()
+}
+
+func Test_MessageService_SendGeneralMessage(t *testing.T) {
+	t.Parallel()
+
+	repos := newTestRepos()
+	expectMessageParties(repos)
+	svc := NewMessageService(nil, repos.message, repos.user, repos.grind)
+	repos.message.On("FindConversationSetting", "amy", "bob").Return(nil, nil)
+	repos.message.On("FindConversationSetting", "bob", "amy").Return(&entities.ConversationSetting{UserID: "bob", CounterpartID: "amy", Muted: true}, nil)
+	repos.message.On("Create", mock.MatchedBy(func(message *entities.Message) bool {
+		return message.SenderID == "amy" && message.ReceiverID == "bob" &&
+			message.Type == "general" && message.Content == "see you at 8?"
+	})).Return(nil)
+
+	message, err := svc.SendGeneralMessage(dto.SendGeneralMessageDTO{SenderID: "amy", ReceiverEmail: "bob@example.com", Content: " see you at 8? "})
+	require.NoError(t, err)
+	assert.Equal(t, "bob", message.Receiver.ID)
+	assert.Equal(t, "general", message.Type)
+	repos.message.AssertExpectations(t)
+}
+
+func Test_MessageService_SendGeneralMessage_Rejected(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		request dto.SendGeneralMessageDTO
+		blocker string
+		wantErr error
+	}{
+		{name: "blocked by receiver", request: dto.SendGeneralMessageDTO{ReceiverID: "bob", Content: "hi"}, blocker: "bob", wantErr: config.ErrMessagingBlocked},
+		{name: "sender blocked receiver", request: dto.SendGeneralMessageDTO{ReceiverID: "bob", Content: "hi"}, blocker: "amy", wantErr: config.ErrMessagingBlocked},
+		{name: "to self", request: dto.SendGeneralMessageDTO{ReceiverID: "amy", Content: "hi"}, wantErr: config.ErrSameRecipientAndSender},
+		{name: "unknown receiver", request: dto.SendGeneralMessageDTO{ReceiverID: "carl", Content: "hi"}, wantErr: config.ErrUserNotFound},
+		{name: "no receiver", request: dto.SendGeneralMessageDTO{Content: "hi"}, wantErr: config.ErrInvalidMessage},
+		{name: "empty content", request: dto.SendGeneralMessageDTO{ReceiverID: "bob", Content: "  "}, wantErr: config.ErrInvalidMessage},
+		{name: "content too long", request: dto.SendGeneralMessageDTO{ReceiverID: "bob", Content: string(make([]rune, entities.MaxGeneralMessageLength+1))}, wantErr: config.ErrInvalidMessage},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			repos := newTestRepos()
+			expectMessageParties(repos)
+			svc := NewMessageService(nil, repos.message, repos.user, repos.grind)
+			for _, pair := range [][2]string{{"amy", "bob"}, {"bob", "amy"}} {
+				var setting *entities.ConversationSetting
+				if pair[0] == tt.blocker {
+					setting = &entities.ConversationSetting{UserID: pair[0], CounterpartID: pair[1], Blocked: true}
+				}
+				repos.message.On("FindConversationSetting", pair[0], pair[1]).Return(setting, nil).Maybe()
+			}
+
+			tt.request.SenderID = "amy"
+			_, err := svc.SendGeneralMessage(tt.request)
+			assert.True(t, errors.Is(err, tt.wantErr), "got %v", err)
+			repos.message.AssertNotCalled(t, "Create", mock.Anything)
+		})
+	}
+}
+
+func Test_MessageService_GetConversations(t *testing.T) {
+	t.Parallel()
+
+	repos := newTestRepos()
+	repos.user.On("FindById", "carl").Return(&entities.User{ID: "carl", Username: "carl"}, nil)
+	expectMessageParties(repos)
+	svc := NewMessageService(nil, repos.message, repos.user, repos.grind)
+	sentAt := time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC)
+	conversations := []*entities.Conversation{{
+		CounterpartID: "bob",
+		LastMessage:   &entities.Message{ID: "msg-2", SenderID: "amy", ReceiverID: "bob", Content: "hi", Type: "general", CreatedAt: sentAt.Add(time.Minute)},
+		UnreadCount:   2,
+		Muted:         true,
+	}, {
+		CounterpartID: "carl",
+		LastMessage:   &entities.Message{ID: "msg-1", SenderID: "carl", ReceiverID: "amy", Content: "yo", Type: "general", CreatedAt: sentAt},
+	}}
+	repos.message.On("FindConversations", "amy", entities.PageCursor{}, 2).Return(conversations, nil)
+	repos.message.On("FindConversations", "amy", messagePosition(conversations[0].LastMessage), 2).Return(conversations[1:], nil)
+
+	first, err := svc.GetConversations(dto.GetConversationsDTO{UserID: "amy", Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, first.Conversations, 1)
+	assert.Equal(t, "bob", first.Conversations[0].Counterpart.ID)
+	assert.Equal(t, "msg-2", first.Conversations[0].LastMessage.ID)
+	assert.Equal(t, 2, first.Conversations[0].UnreadCount)
+	assert.True(t, first.Conversations[0].Muted)
+	assert.False(t, first.Conversations[0].Blocked)
+	require.NotEmpty(t, first.NextCursor)
+
+	last, err := svc.GetConversations(dto.GetConversationsDTO{UserID: "amy", Cursor: first.NextCursor, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, last.Conversations, 1)
+	assert.Equal(t, "msg-1", last.Conversations[0].LastMessage.ID)
+	assert.Empty(t, last.NextCursor)
+
+	_, err = svc.GetConversations(dto.GetConversationsDTO{UserID: "amy", Cursor: "not a cursor"})
+	assert.True(t, errors.Is(err, config.ErrInvalidCursor), "got %v", err)
+}
+
+func Test_MessageService_GetConversationMessages_Pages(t *testing.T) {
+	t.Parallel()
+
+	repos := newTestRepos()
+	expectMessageParties(repos)
+	svc := NewMessageService(nil, repos.message, repos.user, repos.grind)
+	sentAt := time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC)
+	messages := []*entities.Message{
+		{ID: "msg-3", SenderID: "amy", ReceiverID: "bob", Type: "general", CreatedAt: sentAt.Add(2 * time.Minute)},
+		{ID: "msg-2", SenderID: "bob", ReceiverID: "amy", Type: "general", CreatedAt: sentAt.Add(time.Minute)},
+		{ID: "msg-1", SenderID: "bob", ReceiverID: "amy", Type: "general", CreatedAt: sentAt},
+	}
+	repos.message.On("FindConversationMessages", "amy", "bob", entities.PageCursor{}, 3).Return(messages, nil)
+	repos.message.On("FindConversationMessages", "amy", "bob", messagePosition(messages[1]), 3).Return(messages[2:], nil)
+
+	request := dto.GetConversationMessagesDTO{UserID: "amy", CounterpartID: "bob", Limit: 2}
+	first, err := svc.GetConversationMessages(request)
+	require.NoError(t, err)
+	require.Len(t, first.Messages, 2)
+	assert.Equal(t, "msg-2", first.Messages[1].ID)
+	require.NotEmpty(t, first.NextCursor)
+
+	request.Cursor = first.NextCursor
+	last, err := svc.GetConversationMessages(request)
+	require.NoError(t, err)
+	require.Len(t, last.Messages, 1)
+	assert.Equal(t, "msg-1", last.Messages[0].ID)
+	assert.Empty(t, last.NextCursor)
+}
+
+func Test_MessageService_UpdateConversationSetting(t *testing.T) {
+	t.Parallel()
+
+	blocked, muted := true, false
+
+	t.Run("creates the first setting", func(t *testing.T) {
+		t.Parallel()
+
+		repos := newTestRepos()
+		expectMessageParties(repos)
+		svc := NewMessageService(nil, repos.message, repos.user, repos.grind)
+		repos.message.On("FindConversationSetting", "amy", "bob").Return(nil, nil)
+		repos.message.On("CreateConversationSetting", mock.MatchedBy(func(setting *entities.ConversationSetting) bool {
+			return setting.UserID == "amy" && setting.CounterpartID == "bob" && setting.Blocked && !setting.Muted
+		})).Return(nil)
+
+		setting, err := svc.UpdateConversationSetting(dto.UpdateConversationSettingDTO{UserID: "amy", CounterpartID: "bob", Blocked: &blocked})
+		require.NoError(t, err)
+		assert.True(t, setting.Blocked)
+		repos.message.AssertExpectations(t)
+	})
+
+	t.Run("keeps settings left out", func(t *testing.T) {
+		t.Parallel()
+
+		repos := newTestRepos()
+		expectMessageParties(repos)
+		svc := NewMessageService(nil, repos.message, repos.user, repos.grind)
+		repos.message.On("FindConversationSetting", "amy", "bob").Return(&entities.ConversationSetting{ID: "setting-1", UserID: "amy", CounterpartID: "bob", Blocked: true, Muted: true}, nil)
+		repos.message.On("UpdateConversationSetting", mock.MatchedBy(func(setting *entities.ConversationSetting) bool {
+			return setting.ID == "setting-1" && setting.Blocked && !setting.Muted
+		})).Return(nil)
+
+		setting, err := svc.UpdateConversationSetting(dto.UpdateConversationSettingDTO{UserID: "amy", CounterpartID: "bob", Muted: &muted})
+		require.NoError(t, err)
+		assert.True(t, setting.Blocked)
+		assert.False(t, setting.Muted)
+		repos.message.AssertExpectations(t)
+	})
+
+	t.Run("rejects", func(t *testing.T) {
+		t.Parallel()
+
+		repos := newTestRepos()
+		expectMessageParties(repos)
+		svc := NewMessageService(nil, repos.message, repos.user, repos.grind)
+		_, err := svc.UpdateConversationSetting(dto.UpdateConversationSettingDTO{UserID: "amy", CounterpartID: "bob"})
+		assert.True(t, errors.Is(err, config.ErrInvalidConversationSetting))
+		_, err = svc.UpdateConversationSetting(dto.UpdateConversationSettingDTO{UserID: "amy", CounterpartID: "amy", Blocked: &blocked})
+		assert.True(t, errors.Is(err, config.ErrSameRecipientAndSender))
+		_, err = svc.UpdateConversationSetting(dto.UpdateConversationSettingDTO{UserID: "amy", CounterpartID: "carl", Blocked: &blocked})
+		assert.True(t, errors.Is(err, config.ErrUserNotFound))
+	})
+}
+
+func Test_MessageService_GetAllMessagesForReceiver_Pages(t *testing.T) {
+	t.Parallel()
+
+	repos := newTestRepos()
+	expectMessageParties(repos)
+	svc := NewMessageService(nil, repos.message, repos.user, repos.grind)
+	sentAt := time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC)
+	messages := []*entities.Message{
+		{ID: "msg-3", SenderID: "bob", ReceiverID: "amy", Type: "general", CreatedAt: sentAt.Add(2 * time.Minute)},
+		{ID: "msg-2", SenderID: "bob", ReceiverID: "amy", Type: "general", CreatedAt: sentAt.Add(time.Minute)},
+		{ID: "msg-1", SenderID: "bob", ReceiverID: "amy", Type: "general", CreatedAt: sentAt},
+	}
+	unread := false
+	filter := entities.MessageFilter{Type: "general", Read: &unread}
+	repos.message.On("FindAllForReceiver", "amy", filter, entities.PageCursor{}, 3).Return(messages, nil)
+	repos.message.On("FindAllForReceiver", "amy", filter, messagePosition(messages[1]), 3).Return(messages[2:], nil)
+
+	request := dto.GetAllMessagesForReceiverDTO{ReceiverID: "amy", Filter: dto.MessageFilterDTO{Type: "general", Read: &unread}, Limit: 2}
+	first, err := svc.GetAllMessagesForReceiver(request)
+	require.NoError(t, err)
+	require.Len(t, first.Messages, 2)
+	assert.Equal(t, "msg-2", first.Messages[1].ID)
+	require.NotEmpty(t, first.NextCursor)
+
+	request.Cursor = first.NextCursor
+	last, err := svc.GetAllMessagesForReceiver(request)
+	require.NoError(t, err)
+	require.Len(t, last.Messages, 1)
+	assert.Equal(t, "msg-1", last.Messages[0].ID)
+	assert.Empty(t, last.NextCursor)
+}
+
+func Test_MessageService_GetAllMessagesForReceiver_Rejected(t *testing.T) {
+	t.Parallel()
+
+	repos := newTestRepos()
+	expectMessageParties(repos)
+	svc := NewMessageService(nil, repos.message, repos.user, repos.grind)
+	_, err := svc.GetAllMessagesForReceiver(dto.GetAllMessagesForReceiverDTO{ReceiverID: "amy", Filter: dto.MessageFilterDTO{Type: "memo"}})
+	assert.True(t, errors.Is(err, config.ErrInvalidMessageFilter), "got %v", err)
+	_, err = svc.GetAllMessagesForReceiver(dto.GetAllMessagesForReceiverDTO{ReceiverID: "amy", Cursor: "not a cursor"})
+	assert.True(t, errors.Is(err, config.ErrInvalidCursor), "got %v", err)
+	repos.message.AssertNotCalled(t, "FindAllForReceiver", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func Test_MessageService_CountUnreadMessages(t *testing.T) {
+	t.Parallel()
+
+	repos := newTestRepos()
+	expectMessageParties(repos)
+	svc := NewMessageService(nil, repos.message, repos.user, repos.grind)
+	repos.message.On("CountForReceiver", "amy", mock.MatchedBy(func(filter entities.MessageFilter) bool {
+		return filter.Type == "invitation" && filter.GrindID == "grind-1" && filter.Read != nil && !*filter.Read
+	})).Return(int64(4), nil)
+
+	read := true
+	count, err := svc.CountUnreadMessages(dto.CountUnreadMessagesDTO{
+		ReceiverID: "amy",
+		Filter:     dto.MessageFilterDTO{Type: "invitation", GrindID: "grind-1", Read: &read},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), count)
+}
+
+func Test_MessageService_CreateInvitationMessage(t *testing.T) {
+	t.Parallel()
+
+	request := dto.CreateInvitationMessageDTO{SenderID: "amy", ReceiverEmail: "bob@example.com", GrindID: "grind-1"}
+	isInvitation := mock.MatchedBy(func(message *entities.Message) bool {
+		return message.Type == config.MESSAGE_TYPE_INVITATION && message.ReceiverID == "bob" &&
+			message.InvitationGrindID == "grind-1" && message.InvitationExpiresAt != nil
+	})
+
+	t.Run("invites", func(t *testing.T) {
+		t.Parallel()
+		repos := newTestRepos()
+		expectMessageParties(repos)
+		svc := NewMessageService(nil, repos.message, repos.user, repos.grind)
+		repos.message.On("FindOpenInvitation", "grind-1", "bob").Return(nil, nil)
+		repos.message.On("CreateInvitationIfAbsent", isInvitation).Return(true, nil)
+
+		message, err := svc.CreateInvitationMessage(request)
+		require.NoError(t, err)
+		assert.Equal(t, "grind-1", message.InvitationGrind.ID)
+		assert.NotNil(t, message.InvitationExpiresAt)
+	})
+
+	t.Run("refuses while an invitation is pending", func(t *testing.T) {
+		t.Parallel()
+		repos := newTestRepos()
+		expectMessageParties(repos)
+		svc := NewMessageService(nil, repos.message, repos.user, repos.grind)
+		pending, _ := entities.NewMessage("amy", "bob", "join", config.MESSAGE_TYPE_INVITATION, "grind-1", false, false)
+		repos.message.On("FindOpenInvitation", "grind-1", "bob").Return(pending, nil)
+
+		_, err := svc.CreateInvitationMessage(request)
+		assert.True(t, errors.Is(err, config.ErrInvitationPending), "got %v", err)
+		repos.message.AssertNotCalled(t, "CreateInvitationIfAbsent", mock.Anything)
+	})
+
+	t.Run("replaces an expired invitation", func(t *testing.T) {
+		t.Parallel()
+		repos := newTestRepos()
+		expectMessageParties(repos)
+		svc := NewMessageService(nil, repos.message, repos.user, repos.grind)
+		expired, _ := entities.NewMessage("amy", "bob", "join", config.MESSAGE_TYPE_INVITATION, "grind-1", false, false)
+		expiredAt := time.Now().UTC().Add(-time.Hour)
+		expired.InvitationExpiresAt = &expiredAt
+		repos.message.On("FindOpenInvitation", "grind-1", "bob").Return(expired, nil)
+		repos.message.On("Update", expired).Return(nil)
+		repos.message.On("CreateInvitationIfAbsent", isInvitation).Return(true, nil)
+
+		_, err := svc.CreateInvitationMessage(request)
+		require.NoError(t, err)
+		assert.NotNil(t, expired.InvitationRevokedAt)
+	})
+
+	t.Run("loses a race to another invitation", func(t *testing.T) {
+		t.Parallel()
+		repos := newTestRepos()
+		expectMessageParties(repos)
+		svc := NewMessageService(nil, repos.message, repos.user, repos.grind)
+		repos.message.On("FindOpenInvitation", "grind-1", "bob").Return(nil, nil)
+		repos.message.On("CreateInvitationIfAbsent", isInvitation).Return(false, nil)
+
+		_, err := svc.CreateInvitationMessage(request)
+		assert.True(t, errors.Is(err, config.ErrInvitationPending), "got %v", err)
+	})
+
+	t.Run("needs the grind", func(t *testing.T) {
+		t.Parallel()
+		repos := newTestRepos()
+		expectMessageParties(repos)
+		svc := NewMessageService(nil, repos.message, repos.user, repos.grind)
+		_, err := svc.CreateInvitationMessage(dto.CreateInvitationMessageDTO{SenderID: "amy", ReceiverEmail: "bob@example.com", GrindID: "grind-9"})
+		assert.True(t, errors.Is(err, config.ErrGrindNotFound), "got %v", err)
+	})
+
+	t.Run("refuses while the grind is a draft", func(t *testing.T) {
+		t.Parallel()
+		repos := newTestRepos()
+		expectMessageParties(repos)
+		svc := NewMessageService(nil, repos.message, repos.user, repos.grind)
+		_, err := svc.CreateInvitationMessage(dto.CreateInvitationMessageDTO{SenderID: "amy", ReceiverEmail: "bob@example.com", GrindID: "grind-draft"})
+		var stateErr *entities.GrindStateError
+		require.ErrorAs(t, err, &stateErr)
+		assert.Equal(t, entities.GrindStateDraft, stateErr.State)
+		repos.message.AssertNotCalled(t, "CreateInvitationIfAbsent", mock.Anything)
+	})
+}
+
+func Test_MessageService_RevokeInvitation(t *testing.T) {
+	t.Parallel()
+
+	newInvitation := func() *entities.Message {
+		invitation, _ := entities.NewMessage("amy", "bob", "join", config.MESSAGE_TYPE_INVITATION, "grind-1", false, false)
+		return invitation
+	}
+
+	t.Run("revokes", func(t *testing.T) {
+		t.Parallel()
+		repos := newTestRepos()
+		expectMessageParties(repos)
+		svc := NewMessageService(nil, repos.message, repos.user, repos.grind)
+		invitation := newInvitation()
+		repos.message.On("FindByID", invitation.ID).Return(invitation, nil)
+		repos.message.On("Update", invitation).Return(nil)
+
+		message, err := svc.RevokeInvitation(dto.RevokeInvitationDTO{MessageID: invitation.ID, SenderID: "amy"})
+		require.NoError(t, err)
+		assert.NotNil(t, message.InvitationRevokedAt)
+	})
+
+	t.Run("rejects", func(t *testing.T) {
+		t.Parallel()
+		accepted := newInvitation()
+		accepted.InvitationAccepted = true
+		revoked := newInvitation()
+		revoked.RevokeInvitation(revoked.CreatedAt)
+		general, _ := entities.NewMessage("amy", "bob", "hi", config.MESSAGE_TYPE_GENERAL, "", false, false)
+
+		for _, tt := range []struct {
+			message  *entities.Message
+			senderID string
+			wantErr  error
+		}{
+			{message: newInvitation(), senderID: "bob", wantErr: config.ErrNotInvitationSender},
+			{message: accepted, senderID: "amy", wantErr: config.ErrInvitationAnswered},
+			{message: revoked, senderID: "amy", wantErr: config.ErrInvitationRevoked},
+			{message: general, senderID: "amy", wantErr: config.ErrInvitationNotFound},
+		} {
+			repos := newTestRepos()
+			expectMessageParties(repos)
+			svc := NewMessageService(nil, repos.message, repos.user, repos.grind)
+			repos.message.On("FindByID", tt.message.ID).Return(tt.message, nil)
+
+			_, err := svc.RevokeInvitation(dto.RevokeInvitationDTO{MessageID: tt.message.ID, SenderID: tt.senderID})
+			assert.True(t, errors.Is(err, tt.wantErr), "want %v, got %v", tt.wantErr, err)
+			repos.message.AssertNotCalled(t, "Update", mock.Anything)
+		}
+	})
+}
+
+func Test_MessageService_RejectInvitationTx_Unanswerable(t *testing.T) {
+	t.Parallel()
+
+	expired, _ := entities.NewMessage("amy", "bob", "join", config.MESSAGE_TYPE_INVITATION, "grind-1", false, false)
+	expiredAt := expired.CreatedAt.Add(-time.Minute)
+	expired.InvitationExpiresAt = &expiredAt
+	revoked, _ := entities.NewMessage("amy", "bob", "join", config.MESSAGE_TYPE_INVITATION, "grind-1", false, false)
+	revoked.RevokeInvitation(revoked.CreatedAt)
+
+	for _, tt := range []struct {
+		message    *entities.Message
+		rejecterID string
+		wantErr    error
+	}{
+		{message: expired, rejecterID: "bob", wantErr: config.ErrInvitationExpired},
+		{message: revoked, rejecterID: "bob", wantErr: config.ErrInvitationRevoked},
+		{message: revoked, rejecterID: "amy", wantErr: config.ErrNotInvitationReceiver},
+	} {
+		repos := newTestRepos()
+		expectMessageParties(repos)
+		svc := NewMessageService(nil, repos.message, repos.user, repos.grind)
+		repos.message.On("FindByID", tt.message.ID).Return(tt.message, nil)
+
+		err := svc.RejectInvitationTx(
+			dto.UpdateMessageInvitationAcceptedStatusDTO{MessageID: tt.message.ID},
+			dto.CreateInvitationRejectedMessageDTO{RejecterID: tt.rejecterID, InvitorID: "amy", GrindID: "grind-1"},
+		)
+		assert.True(t, errors.Is(err, tt.wantErr), "want %v, got %v", tt.wantErr, err)
+		repos.message.AssertNotCalled(t, "Update", mock.Anything)
+		repos.message.AssertNotCalled(t, "Create", mock.Anything)
+	}
+}
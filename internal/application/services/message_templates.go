@@ -0,0 +1,245 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/assets"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/repositories"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// MessageEvent is something a system message tells its receiver about. The event names
+// its template in assets/templates/messages; its exported fields are the template's data.
+type MessageEvent interface {
+	MessageTemplate() string
+}
+
+// CalendarDate is a day as the user it belongs to sees it. Events carry days this way
+// because a stored instant, e.g. a task's local midnight read back in UTC, would render
+// as the day before in any zone west of the user's.
+type CalendarDate struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// calendarDate is the day containing t in loc.
+func calendarDate(t time.Time, loc *time.Location) CalendarDate {
+	y, m, d := t.In(loc).Date()
+	return CalendarDate{Year: y, Month: m, Day: d}
+}
+
+func (d CalendarDate) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, int(d.Month), d.Day)
+}
+
+// GrindDetails describes the grind a message is about.
+type GrindDetails struct {
+	Duration  int32
+	Budget    int32
+	StartDate CalendarDate
+}
+
+type InvitationSentEvent struct {
+	Inviter string
+	Grind   GrindDetails
+}
+
+type InvitationAcceptedEvent struct {
+	Accepter string
+	Grind    GrindDetails
+}
+
+type InvitationRejectedEvent struct {
+	Rejecter string
+	Grind    GrindDetails
+}
+
+type StakeChangeProposedEvent struct {
+	Proposer      string
+	Budget        int32
+	PenaltyKind   string
+	PenaltyAmount int
+}
+
+type StakeChangeRejectedEvent struct {
+	Rejecter string
+}
+
+// CompletionDisputedEvent tells the subject and the reviewers that a partner flagged a
+// completion; Reason may be empty.
+type CompletionDisputedEvent struct {
+	Flagger string
+	Subject string
+	Day     CalendarDate
+	Reason  string
+}
+
+// CompletionReviewRequestedEvent asks a reviewer to weigh in on a completion the
+// CompletionVerifier doubts.
+type CompletionReviewRequestedEvent struct {
+	Subject string
+	Day     CalendarDate
+	Reason  string
+}
+
+// CompletionReviewStartedEvent tells the subject their completion was sent for review.
+type CompletionReviewStartedEvent struct {
+	Day    CalendarDate
+	Reason string
+}
+
+type CompletionDisputeRevertedEvent struct {
+	Subject string
+}
+
+type CompletionDisputeUpheldEvent struct {
+	Subject string
+}
+
+func (InvitationSentEvent) MessageTemplate() string            { return "invitation_sent" }
+func (InvitationAcceptedEvent) MessageTemplate() string        { return "invitation_accepted" }
+func (InvitationRejectedEvent) MessageTemplate() string        { return "invitation_rejected" }
+func (StakeChangeProposedEvent) MessageTemplate() string       { return "stake_change_proposed" }
+func (StakeChangeRejectedEvent) MessageTemplate() string       { return "stake_change_rejected" }
+func (CompletionDisputedEvent) MessageTemplate() string        { return "completion_disputed" }
+func (CompletionReviewRequestedEvent) MessageTemplate() string { return "completion_review_requested" }
+func (CompletionReviewStartedEvent) MessageTemplate() string   { return "completion_review_started" }
+func (CompletionDisputeRevertedEvent) MessageTemplate() string { return "completion_dispute_reverted" }
+func (CompletionDisputeUpheldEvent) MessageTemplate() string   { return "completion_dispute_upheld" }
+
+// grindDetails describes grind; its StartDate's UTC date is the first day for everyone.
+func grindDetails(grind *entities.Grind) GrindDetails {
+	return GrindDetails{Duration: grind.Duration, Budget: grind.Budget, StartDate: calendarDate(grind.StartDate, time.UTC)}
+}
+
+// messageCatalog holds the parsed templates of every embedded locale. locales[0] is the
+// default locale, which the matcher falls back to and whose templates stand in for ones a
+// locale lacks.
+type messageCatalog struct {
+	locales   []language.Tag
+	templates []*template.Template
+	matcher   language.Matcher
+}
+
+var (
+	messageCatalogOnce sync.Once
+	loadedCatalog      *messageCatalog
+	messageCatalogErr  error
+)
+
+// loadMessageCatalog parses the embedded templates on first use.
+func loadMessageCatalog() (*messageCatalog, error) {
+	messageCatalogOnce.Do(func() {
+		loadedCatalog, messageCatalogErr = parseMessageCatalog()
+	})
+	return loadedCatalog, messageCatalogErr
+}
+
+func parseMessageCatalog() (*messageCatalog, error) {
+	files, err := assets.MessageTemplates.ReadDir("templates/messages")
+	if err != nil {
+		return nil, err
+	}
+	catalog := &messageCatalog{}
+	for _, file := range files {
+		name := strings.TrimSuffix(file.Name(), ".json")
+		tag, err := language.Parse(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid message template locale %s: %w", name, err)
+		}
+		templates, err := parseMessageTemplates(path.Join("templates/messages", file.Name()), tag)
+		if err != nil {
+			return nil, err
+		}
+		if name == entities.DefaultLocale {
+			catalog.locales = append([]language.Tag{tag}, catalog.locales...)
+			catalog.templates = append([]*template.Template{templates}, catalog.templates...)
+		} else {
+			catalog.locales = append(catalog.locales, tag)
+			catalog.templates = append(catalog.templates, templates)
+		}
+	}
+	if len(catalog.locales) == 0 || catalog.locales[0].String() != entities.DefaultLocale {
+		return nil, fmt.Errorf("no message templates for the default locale %s", entities.DefaultLocale)
+	}
+	catalog.matcher = language.NewMatcher(catalog.locales)
+	return catalog, nil
+}
+
+// parseMessageTemplates parses one locale's file into a template set with one template
+// per event.
+func parseMessageTemplates(file string, tag language.Tag) (*template.Template, error) {
+	raw, err := assets.MessageTemplates.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var sources map[string]string
+	if err := json.Unmarshal(raw, &sources); err != nil {
+		return nil, fmt.Errorf("invalid message templates %s: %w", file, err)
+	}
+
+	printer := message.NewPrinter(tag)
+	set := template.New(tag.String()).Option("missingkey=error").Funcs(template.FuncMap{
+		"date":   func(d CalendarDate) string { return d.String() },
+		"number": func(n interface{}) string { return printer.Sprint(n) },
+	})
+	for name, source := range sources {
+		if _, err := set.New(name).Parse(source); err != nil {
+			return nil, fmt.Errorf("invalid message template %s in %s: %w", name, file, err)
+		}
+	}
+	return set, nil
+}
+
+// RenderMessage renders event in the embedded locale that best matches locale, e.g. zh-TW
+// for "zh-Hant"; a locale without a match, or empty, gets the default locale.
+func RenderMessage(locale string, event MessageEvent) (string, error) {
+	catalog, err := loadMessageCatalog()
+	if err != nil {
+		return "", err
+	}
+	want, _ := language.Parse(locale)
+	_, index, _ := catalog.matcher.Match(want)
+
+	name := event.MessageTemplate()
+	tmpl := catalog.templates[index].Lookup(name)
+	if tmpl == nil {
+		tmpl = catalog.templates[0].Lookup(name)
+	}
+	if tmpl == nil {
+		return "", fmt.Errorf("no message template %s", name)
+	}
+	var content strings.Builder
+	if err := tmpl.Execute(&content, event); err != nil {
+		return "", fmt.Errorf("failed to render message template %s: %w", name, err)
+	}
+	return content.String(), nil
+}
+
+// renderMessageFor renders event in the receiver's locale; a receiver who cannot be found
+// gets the default locale, so a lookup failure never holds a message back.
+func renderMessageFor(userRepo repositories.UserRepository, receiverID string, event MessageEvent) (string, error) {
+	locale := entities.DefaultLocale
+	if receiver, err := userRepo.FindById(receiverID); err == nil && receiver.Locale != "" {
+		locale = receiver.Locale
+	}
+	return RenderMessage(locale, event)
+}
+
+// displayName is how messages name a user: their username, or their ID when they cannot
+// be found.
+func displayName(userRepo repositories.UserRepository, userID string) string {
+	if user, err := userRepo.FindById(userID); err == nil && user.Username != "" {
+		return user.Username
+	}
+	return userID
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+var testGrindDetails = GrindDetails{Duration: 30, Budget: 1500, StartDate: CalendarDate{Year: 2026, Month: time.May, Day: 1}}
+
+func Test_RenderMessage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		locale  string
+		event   MessageEvent
+		content string
+	}{
+		{
+			name:    "invitation",
+			locale:  "en",
+			event:   InvitationSentEvent{Inviter: "amy", Grind: testGrindDetails},
+			content: "amy invited you to join a 30-day grind starting 2026-05-01 with a 1,500 budget",
+		},
+		{
+			name:    "accepted invitation",
+			locale:  "en",
+			event:   InvitationAcceptedEvent{Accepter: "bob", Grind: testGrindDetails},
+			content: "bob accepted your invitation to the 30-day grind starting 2026-05-01",
+		},
+		{
+			name:    "traditional chinese",
+			locale:  "zh-TW",
+			event:   InvitationRejectedEvent{Rejecter: "bob", Grind: testGrindDetails},
+			content: "bob 婉拒了你對從 2026-05-01 開始、為期 30 天的挑戰的邀請",
+		},
+		{
+			name:    "script matches region",
+			locale:  "zh-Hant",
+			event:   StakeChangeRejectedEvent{Rejecter: "bob"},
+			content: "bob 拒絕了你的賭注變更",
+		},
+		{
+			name:    "japanese with region",
+			locale:  "ja-JP",
+			event:   StakeChangeProposedEvent{Proposer: "amy", Budget: 2000, PenaltyKind: "escalating", PenaltyAmount: 10},
+			content: "amy さんが新しいステークを提案しました：予算 2,000、累進ペナルティ 10",
+		},
+		{
+			name:    "unsupported locale",
+			locale:  "fr",
+			event:   CompletionDisputeUpheldEvent{Subject: "bob"},
+			content: "bob's disputed completion was upheld",
+		},
+		{
+			name:    "empty locale",
+			locale:  "",
+			event:   CompletionDisputedEvent{Flagger: "amy", Subject: "bob", Day: testGrindDetails.StartDate},
+			content: "amy disputed bob's completion of 2026-05-01",
+		},
+		{
+			name:    "dispute with reason",
+			locale:  "en",
+			event:   CompletionDisputedEvent{Flagger: "amy", Subject: "bob", Day: testGrindDetails.StartDate, Reason: "no submission"},
+			content: "amy disputed bob's completion of 2026-05-01: no submission",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			content, err := RenderMessage(test.locale, test.event)
+			require.NoError(t, err)
+			assert.Equal(t, test.content, content)
+		})
+	}
+}
+
+// Test_MessageTemplates_CoverEveryEvent renders every event in every embedded locale, so a
+// template that is missing or refers to a field its event lacks fails here.
+func Test_MessageTemplates_CoverEveryEvent(t *testing.T) {
+	t.Parallel()
+
+	day := testGrindDetails.StartDate
+	events := []MessageEvent{
+		InvitationSentEvent{Inviter: "amy", Grind: testGrindDetails},
+		InvitationAcceptedEvent{Accepter: "bob", Grind: testGrindDetails},
+		InvitationRejectedEvent{Rejecter: "bob", Grind: testGrindDetails},
+		StakeChangeProposedEvent{Proposer: "amy", Budget: 100, PenaltyKind: "flat", PenaltyAmount: 5},
+		StakeChangeRejectedEvent{Rejecter: "bob"},
+		CompletionDisputedEvent{Flagger: "amy", Subject: "bob", Day: day, Reason: "no submission"},
+		CompletionReviewRequestedEvent{Subject: "bob", Day: day, Reason: "no submission"},
+		CompletionReviewStartedEvent{Day: day, Reason: "no submission"},
+		CompletionDisputeRevertedEvent{Subject: "bob"},
+		CompletionDisputeUpheldEvent{Subject: "bob"},
+	}
+
+	catalog, err := loadMessageCatalog()
+	require.NoError(t, err)
+	require.Len(t, catalog.locales, 3)
+	for i, locale := range catalog.locales {
+		for _, event := range events {
+			tmpl := catalog.templates[i].Lookup(event.MessageTemplate())
+			require.NotNil(t, tmpl, "%s has no %s template", locale, event.MessageTemplate())
+			content, err := RenderMessage(locale.String(), event)
+			require.NoError(t, err, "%s %s", locale, event.MessageTemplate())
+			assert.NotEmpty(t, content)
+		}
+	}
+}
+
+func Test_RenderMessageFor(t *testing.T) {
+	t.Parallel()
+
+	userRepo := new(mocks.MockUserRepository)
+	userRepo.On("FindById", "amy").Return(&entities.User{ID: "amy", Username: "amy", Locale: "ja"}, nil)
+	userRepo.On("FindById", "ghost").Return(nil, gorm.ErrRecordNotFound)
+
+	content, err := renderMessageFor(userRepo, "amy", CompletionDisputeUpheldEvent{Subject: displayName(userRepo, "amy")})
+	require.NoError(t, err)
+	assert.Equal(t, "amy さんの異議のある達成は認められました", content)
+
+	// a receiver who cannot be found gets the default locale, and a missing user their ID
+	content, err = renderMessageFor(userRepo, "ghost", CompletionDisputeUpheldEvent{Subject: displayName(userRepo, "ghost")})
+	require.NoError(t, err)
+	assert.Equal(t, "ghost's disputed completion was upheld", content)
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/repositories"
+	domainservices "github.com/daniel0321forever/terriyaki-go/internal/domain/services"
+	"gorm.io/gorm"
+)
+
+// MissedDayService closes out a calendar day: every uncompleted HabitTask on that day is
+// flagged as missed and the owner's Participation accrues one missed day, priced by the
+// grind's PenaltyPolicy (or covered by a streak-freeze token). Days are the owner's local
+// days, so a task is only evaluated once its day has ended in the owner's timezone and the
+// ingest grace window after it has passed. Grinds with a weekly_target schedule are
+// evaluated per ISO week instead: when the week's last day closes, only the shortfall
+// against the target is missed and the remaining open days are excused. Running it twice
+// for the same day is a no-op because HabitTaskRepository.MarkMissed and MarkExcused only
+// succeed for tasks that have not been counted yet.
+type MissedDayService struct {
+	db                *gorm.DB
+	grindRepo         repositories.GrindRepository
+	userRepo          repositories.UserRepository
+	habitTaskRepo     repositories.HabitTaskRepository
+	participationRepo repositories.ParticipationRepository
+	penaltyPolicy     *domainservices.PenaltyPolicyService
+	graceWindow       time.Duration // late completions of a day are still ingested this long after it ends
+	now               func() time.Time
+}
+
+func NewMissedDayService(
+	db *gorm.DB,
+	grindRepo repositories.GrindRepository,
+	userRepo repositories.UserRepository,
+	habitTaskRepo repositories.HabitTaskRepository,
+	participationRepo repositories.ParticipationRepository,
+) *MissedDayService {
+	return &MissedDayService{
+		db:                db,
+		grindRepo:         grindRepo,
+		userRepo:          userRepo,
+		habitTaskRepo:     habitTaskRepo,
+		participationRepo: participationRepo,
+		penaltyPolicy:     domainservices.NewPenaltyPolicyService(),
+		graceWindow:       config.IngestGraceWindow(),
+		now:               time.Now,
+	}
+}
+
+// EvaluateDay evaluates every grind active on the calendar date of request.Day (read in UTC).
+// Tasks whose day has not ended yet in their owner's timezone, or whose grace window is
+// still open, are left for a later run; ErrDayNotClosed is returned when the day has not
+// ended anywhere. Past days can be passed explicitly to backfill runs the scheduler missed.
+func (s *MissedDayService) EvaluateDay(request dto.EvaluateDayDTO) (*dto.DayEvaluationResultDTO, error) {
+	y, m, d := request.Day.UTC().Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	// The earliest zone (UTC+14) closes the day 14 hours before UTC does.
+	if s.now().Before(day.AddDate(0, 0, 1).Add(-14 * time.Hour)) {
+		return nil, config.ErrDayNotClosed
+	}
+
+	grinds, err := s.grindRepo.FindActiveGrinds(day)
+	if err != nil {
+		return nil, fmt.Errorf("finding grinds active on %s: %w", day.Format("2006-01-02"), err)
+	}
+
+	result := &dto.DayEvaluationResultDTO{Day: day}
+	for _, grind := range grinds {
+		marked, penalty, err := s.evaluateGrindDay(grind, day)
+		if err != nil {
+			return nil, fmt.Errorf("evaluating grind %s: %w", grind.ID, err)
+		}
+		result.GrindsEvaluated++
+		result.TasksMarkedMissed += marked
+		result.PenaltyAccrued += penalty
+	}
+	return result, nil
+}
+
+// evaluateGrindDay marks one grind's open tasks for the day as missed inside a single
+// transaction, so a participation is never charged for a task that was not flagged.
+func (s *MissedDayService) evaluateGrindDay(grind *entities.Grind, day time.Time) (int, int, error) {
+	participants, err := s.userRepo.FindByGrindID(grind.ID)
+	if err != nil {
+		return 0, 0, err
+	}
+	locations := make(map[string]*time.Location, len(participants))
+	for i := range participants {
+		locations[participants[i].ID] = participants[i].Location()
+	}
+
+	marked, accrued := 0, 0
+	now := s.now()
+
+	txErr := runInTransaction(s.db, func(tx *gorm.DB) error {
+		habitTaskRepo := getHabitTaskRepo(s.habitTaskRepo, tx)
+		partRepo := getParticipationRepo(s.participationRepo, tx)
+
+		// Local midnight of the day lies between day-14h (UTC+14) and day+12h (UTC-12).
+		tasks, err := habitTaskRepo.FindByGrindIDInRange(grind.ID, day.Add(-14*time.Hour), day.Add(13*time.Hour))
+		if err != nil {
+			return err
+		}
+
+		weekly := grind.Schedule.Kind == entities.ScheduleKindWeeklyTarget
+		for _, task := range tasks {
+			if !weekly && !task.IsOpen() {
+				continue
+			}
+
+			loc, ok := locations[task.UserID]
+			if !ok {
+				loc = time.UTC
+			}
+			if y, m, d := task.Date.In(loc).Date(); !time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Equal(day) {
+				continue // a neighbouring day's task in another zone
+			}
+			if now.Before(task.DayEnd(loc).Add(s.graceWindow)) {
+				continue // the owner's day, or its grace window for late completions, is still running
+			}
+			dayIndex := grind.DayIndex(task.Date, loc)
+			if !grind.IsScheduledDay(dayIndex) {
+				continue // left over from an earlier schedule
+			}
+			if weekly {
+				if _, last, _ := grind.WeekWindow(dayIndex); dayIndex != last {
+					continue // weeks are evaluated on their last day
+				}
+			}
+
+			participation, err := partRepo.FindByUserAndGrindForUpdate(task.UserID, grind.ID)
+			if err != nil {
+				return err
+			}
+			if participation == nil || participation.Quitted || participation.Finalized {
+				continue
+			}
+
+			missed := []*entities.HabitTask{task}
+			if weekly {
+				if missed, err = closeWeek(habitTaskRepo, grind, task.UserID, dayIndex, loc); err != nil {
+					return err
+				}
+			}
+
+			for _, missedTask := range missed {
+				flagged, err := habitTaskRepo.MarkMissed(missedTask.ID)
+				if err != nil {
+					return err
+				}
+				if !flagged {
+					// completed or counted concurrently since we loaded it
+					continue
+				}
+
+				added, _ := s.penaltyPolicy.RecordMissedDay(grind, participation)
+				if err := partRepo.Update(participation); err != nil {
+					return err
+				}
+
+				marked++
+				accrued += added
+			}
+		}
+		return nil
+	})
+	if txErr != nil {
+		return 0, 0, txErr
+	}
+	return marked, accrued, nil
+}
+
+// closeWeek settles the weekly_target week ending on lastDay for one participant. It
+// returns the open tasks that make up the shortfall against the week's target, earliest
+// first, and excuses the other open tasks of the week.
+func closeWeek(
+	habitTaskRepo repositories.HabitTaskRepository,
+	grind *entities.Grind,
+	userID string,
+	lastDay int,
+	loc *time.Location,
+) ([]*entities.HabitTask, error) {
+	first, last, target := grind.WeekWindow(lastDay)
+	all, err := habitTaskRepo.FindByGrindIDAndUserID(grind.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	open := make([]*entities.HabitTask, 0, last-first+1)
+	shortfall := target
+	for _, task := range all {
+		if i := grind.DayIndex(task.Date, loc); i < first || i > last {
+			continue
+		}
+		switch {
+		case task.Completed, task.Missed:
+			// a missed task has been charged, by an earlier run or a reverted completion;
+			// an excused revert has not and leaves its share of the shortfall to this run
+			shortfall--
+		case !task.Excused:
+			open = append(open, task)
+		}
+	}
+	sort.Slice(open, func(i, j int) bool { return open[i].Date.Before(open[j].Date) })
+	if shortfall < 0 {
+		shortfall = 0
+	}
+	if shortfall > len(open) {
+		shortfall = len(open)
+	}
+
+	for _, task := range open[shortfall:] {
+		if _, err := habitTaskRepo.MarkExcused(task.ID); err != nil {
+			return nil, err
+		}
+	}
+	return open[:shortfall], nil
+}
+
+// Run evaluates the last few calendar days immediately and then shortly after every hour
+// until ctx is cancelled. Hourly runs are needed because local days end at different UTC
+// hours; already-counted tasks are skipped. Errors are reported and the loop keeps going;
+// an evaluation that failed can be re-run with the evaluate_day command.
+func (s *MissedDayService) Run(ctx context.Context) {
+	for {
+		today := s.now().UTC()
+		for offset := -2; offset <= 0; offset++ {
+			day := today.AddDate(0, 0, offset)
+			_, err := s.EvaluateDay(dto.EvaluateDayDTO{Day: day})
+			if err != nil && !errors.Is(err, config.ErrDayNotClosed) {
+				fmt.Println("missed-day evaluation failed for", day.Format("2006-01-02"), err)
+			}
+		}
+
+		next := s.now().UTC().Truncate(time.Hour).Add(time.Hour + time.Minute)
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
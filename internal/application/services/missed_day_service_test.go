@@ -0,0 +1,285 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// expectDayTasks wires the grind lookup and the task window the evaluator reads for day.
+func expectDayTasks(repos testRepos, day time.Time, grind *entities.Grind, users []entities.User, tasks []*entities.HabitTask) {
+	repos.grind.On("FindActiveGrinds", day).Return([]*entities.Grind{grind}, nil)
+	repos.user.On("FindByGrindID", grind.ID).Return(users, nil)
+	repos.habitTask.On("FindByGrindIDInRange", grind.ID, day.Add(-14*time.Hour), day.Add(13*time.Hour)).Return(tasks, nil)
+}
+
+func Test_MissedDayService_EvaluateDay_MarksMissedAndAccruesPenalty(t *testing.T) {
+	t.Parallel()
+
+	day := time.Date(2026, 4, 10, 0, 0, 0, 0, time.UTC)
+	repos := newTestRepos()
+	svc := NewMissedDayService(nil, repos.grind, repos.user, repos.habitTask, repos.participation)
+	svc.now = func() time.Time { return day.Add(36 * time.Hour) }
+
+	grind := &entities.Grind{ID: "grind-1", Duration: 30, Budget: 300, PenaltyPolicy: entities.DefaultPenaltyPolicy(30, 300), StartDate: day.AddDate(0, 0, -5)}
+	expectDayTasks(repos, day, grind,
+		[]entities.User{{ID: "user-1", Timezone: "UTC"}, {ID: "user-2", Timezone: "UTC"}},
+		[]*entities.HabitTask{
+			{ID: "task-done", UserID: "user-1", GrindID: "grind-1", Date: day, Completed: true},
+			{ID: "task-open", UserID: "user-2", GrindID: "grind-1", Date: day},
+		})
+
+	participation := &entities.Participation{ID: "part-2", UserID: "user-2", GrindID: "grind-1", MissedDays: 1, TotalPenalty: 10}
+	repos.participation.On("FindByUserAndGrindForUpdate", "user-2", "grind-1").Return(participation, nil)
+	repos.habitTask.On("MarkMissed", "task-open").Return(true, nil)
+	repos.participation.On("Update", mock.MatchedBy(func(p *entities.Participation) bool {
+		return p.ID == "part-2" && p.MissedDays == 2 && p.TotalPenalty == 20
+	})).Return(nil)
+
+	result, err := svc.EvaluateDay(dto.EvaluateDayDTO{Day: day.Add(15 * time.Hour)})
+	assert.NoError(t, err)
+	assert.Equal(t, day, result.Day)
+	assert.Equal(t, 1, result.GrindsEvaluated)
+	assert.Equal(t, 1, result.TasksMarkedMissed)
+	assert.Equal(t, 10, result.PenaltyAccrued)
+
+	repos.habitTask.AssertNotCalled(t, "MarkMissed", "task-done")
+	repos.grind.AssertExpectations(t)
+	repos.habitTask.AssertExpectations(t)
+	repos.participation.AssertExpectations(t)
+}
+
+func Test_MissedDayService_EvaluateDay_RerunDoesNotDoubleCount(t *testing.T) {
+	t.Parallel()
+
+	day := time.Date(2026, 4, 10, 0, 0, 0, 0, time.UTC)
+	repos := newTestRepos()
+	svc := NewMissedDayService(nil, repos.grind, repos.user, repos.habitTask, repos.participation)
+	svc.now = func() time.Time { return day.Add(48 * time.Hour) }
+
+	grind := &entities.Grind{ID: "grind-1", Duration: 30, Budget: 300, PenaltyPolicy: entities.DefaultPenaltyPolicy(30, 300), StartDate: day}
+	// Another run flagged the task between our read and the conditional update.
+	expectDayTasks(repos, day, grind,
+		[]entities.User{{ID: "user-1", Timezone: "UTC"}},
+		[]*entities.HabitTask{{ID: "task-open", UserID: "user-1", GrindID: "grind-1", Date: day}})
+	repos.participation.On("FindByUserAndGrindForUpdate", "user-1", "grind-1").Return(&entities.Participation{ID: "part-1"}, nil)
+	repos.habitTask.On("MarkMissed", "task-open").Return(false, nil)
+
+	result, err := svc.EvaluateDay(dto.EvaluateDayDTO{Day: day})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.TasksMarkedMissed)
+	assert.Equal(t, 0, result.PenaltyAccrued)
+	repos.participation.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func Test_MissedDayService_EvaluateDay_WaitsForGraceWindow(t *testing.T) {
+	t.Parallel()
+
+	day := time.Date(2026, 4, 10, 0, 0, 0, 0, time.UTC)
+	repos := newTestRepos()
+	svc := NewMissedDayService(nil, repos.grind, repos.user, repos.habitTask, repos.participation)
+	svc.now = func() time.Time { return day.Add(25 * time.Hour) }
+	svc.graceWindow = 2 * time.Hour
+
+	grind := &entities.Grind{ID: "grind-1", Duration: 30, Budget: 300, PenaltyPolicy: entities.DefaultPenaltyPolicy(30, 300), StartDate: day}
+	expectDayTasks(repos, day, grind,
+		[]entities.User{{ID: "user-1", Timezone: "UTC"}},
+		[]*entities.HabitTask{{ID: "task-open", UserID: "user-1", GrindID: "grind-1", Date: day}})
+
+	// a late completion of the day can still arrive until 02:00
+	result, err := svc.EvaluateDay(dto.EvaluateDayDTO{Day: day})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.TasksMarkedMissed)
+	repos.habitTask.AssertNotCalled(t, "MarkMissed", mock.Anything)
+}
+
+func Test_MissedDayService_EvaluateDay_SkipsAlreadyMissedQuittedAndFinalized(t *testing.T) {
+	t.Parallel()
+
+	day := time.Date(2026, 4, 10, 0, 0, 0, 0, time.UTC)
+	repos := newTestRepos()
+	svc := NewMissedDayService(nil, repos.grind, repos.user, repos.habitTask, repos.participation)
+	svc.now = func() time.Time { return day.Add(48 * time.Hour) }
+
+	grind := &entities.Grind{ID: "grind-1", Duration: 30, Budget: 300, PenaltyPolicy: entities.DefaultPenaltyPolicy(30, 300), StartDate: day}
+	expectDayTasks(repos, day, grind,
+		[]entities.User{{ID: "user-1", Timezone: "UTC"}, {ID: "user-2", Timezone: "UTC"}, {ID: "user-3", Timezone: "UTC"}},
+		[]*entities.HabitTask{
+			{ID: "task-missed", UserID: "user-1", GrindID: "grind-1", Date: day, Missed: true},
+			{ID: "task-quit", UserID: "user-2", GrindID: "grind-1", Date: day},
+			{ID: "task-settled", UserID: "user-3", GrindID: "grind-1", Date: day},
+		})
+	repos.participation.On("FindByUserAndGrindForUpdate", "user-2", "grind-1").Return(&entities.Participation{ID: "part-2", Quitted: true}, nil)
+	repos.participation.On("FindByUserAndGrindForUpdate", "user-3", "grind-1").Return(&entities.Participation{ID: "part-3", Finalized: true}, nil)
+
+	result, err := svc.EvaluateDay(dto.EvaluateDayDTO{Day: day})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.GrindsEvaluated)
+	assert.Equal(t, 0, result.TasksMarkedMissed)
+	repos.habitTask.AssertNotCalled(t, "MarkMissed", mock.Anything)
+	repos.participation.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func Test_MissedDayService_EvaluateDay_FreezeTokenCoversTheDay(t *testing.T) {
+	t.Parallel()
+
+	day := time.Date(2026, 4, 10, 0, 0, 0, 0, time.UTC)
+	repos := newTestRepos()
+	svc := NewMissedDayService(nil, repos.grind, repos.user, repos.habitTask, repos.participation)
+	svc.now = func() time.Time { return day.Add(48 * time.Hour) }
+
+	policy := entities.PenaltyPolicy{Kind: entities.PenaltyKindFlat, Amount: 10, FreezeTokens: 1, CapAtBudget: true}
+	grind := &entities.Grind{ID: "grind-1", Duration: 30, Budget: 300, PenaltyPolicy: policy, StartDate: day}
+	expectDayTasks(repos, day, grind,
+		[]entities.User{{ID: "user-1", Timezone: "UTC"}},
+		[]*entities.HabitTask{{ID: "task-open", UserID: "user-1", GrindID: "grind-1", Date: day}})
+	repos.participation.On("FindByUserAndGrindForUpdate", "user-1", "grind-1").Return(&entities.Participation{ID: "part-1"}, nil)
+	repos.habitTask.On("MarkMissed", "task-open").Return(true, nil)
+	repos.participation.On("Update", mock.MatchedBy(func(p *entities.Participation) bool {
+		return p.ID == "part-1" && p.FrozenDays == 1 && p.MissedDays == 0 && p.TotalPenalty == 0
+	})).Return(nil)
+
+	result, err := svc.EvaluateDay(dto.EvaluateDayDTO{Day: day})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.TasksMarkedMissed)
+	assert.Equal(t, 0, result.PenaltyAccrued)
+	repos.participation.AssertExpectations(t)
+}
+
+func Test_MissedDayService_EvaluateDay_UsesOwnersLocalDay(t *testing.T) {
+	t.Parallel()
+
+	taipei, _ := time.LoadLocation("Asia/Taipei")       // UTC+8
+	newYork, _ := time.LoadLocation("America/New_York") // UTC-4 in April
+	day := time.Date(2026, 4, 10, 0, 0, 0, 0, time.UTC)
+	// 2026-04-10 20:00 UTC: Taipei's April 10 ended at 16:00 UTC, New York's is still running.
+	repos := newTestRepos()
+	svc := NewMissedDayService(nil, repos.grind, repos.user, repos.habitTask, repos.participation)
+	svc.now = func() time.Time { return day.Add(20 * time.Hour) }
+
+	grind := &entities.Grind{ID: "grind-1", Duration: 30, Budget: 300, PenaltyPolicy: entities.DefaultPenaltyPolicy(30, 300), StartDate: day}
+	expectDayTasks(repos, day, grind,
+		[]entities.User{{ID: "tw", Timezone: "Asia/Taipei"}, {ID: "ny", Timezone: "America/New_York"}},
+		[]*entities.HabitTask{
+			{ID: "tw-apr-10", UserID: "tw", GrindID: "grind-1", Date: time.Date(2026, 4, 10, 0, 0, 0, 0, taipei)},
+			{ID: "tw-apr-11", UserID: "tw", GrindID: "grind-1", Date: time.Date(2026, 4, 11, 0, 0, 0, 0, taipei)},
+			{ID: "ny-apr-10", UserID: "ny", GrindID: "grind-1", Date: time.Date(2026, 4, 10, 0, 0, 0, 0, newYork)},
+		})
+	repos.participation.On("FindByUserAndGrindForUpdate", "tw", "grind-1").Return(&entities.Participation{ID: "part-tw"}, nil)
+	repos.participation.On("FindByUserAndGrindForUpdate", "ny", "grind-1").Return(&entities.Participation{ID: "part-ny"}, nil)
+	repos.habitTask.On("MarkMissed", "tw-apr-10").Return(true, nil)
+	repos.participation.On("Update", mock.MatchedBy(func(p *entities.Participation) bool {
+		return p.ID == "part-tw" && p.MissedDays == 1
+	})).Return(nil)
+
+	result, err := svc.EvaluateDay(dto.EvaluateDayDTO{Day: day})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.TasksMarkedMissed)
+	repos.habitTask.AssertNotCalled(t, "MarkMissed", "tw-apr-11")
+	repos.habitTask.AssertNotCalled(t, "MarkMissed", "ny-apr-10")
+	repos.habitTask.AssertExpectations(t)
+}
+
+func Test_MissedDayService_EvaluateDay_RejectsDayNotClosedAnywhere(t *testing.T) {
+	t.Parallel()
+
+	day := time.Date(2026, 4, 10, 0, 0, 0, 0, time.UTC)
+	// 09:00 UTC: even UTC+14 is still on April 10 (23:00).
+	repos := newTestRepos()
+	svc := NewMissedDayService(nil, repos.grind, repos.user, repos.habitTask, repos.participation)
+	svc.now = func() time.Time { return day.Add(9 * time.Hour) }
+
+	result, err := svc.EvaluateDay(dto.EvaluateDayDTO{Day: day})
+	assert.ErrorIs(t, err, config.ErrDayNotClosed)
+	assert.Nil(t, result)
+	repos.grind.AssertNotCalled(t, "FindActiveGrinds", mock.Anything)
+}
+
+func Test_MissedDayService_EvaluateDay_WeeklyTargetMissesOnlyTheShortfall(t *testing.T) {
+	t.Parallel()
+
+	// Wednesday 2026-04-01 start; the first ISO week ends on Sunday 2026-04-05 (day 4)
+	start := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	grind := &entities.Grind{
+		ID:            "grind-1",
+		Duration:      14,
+		Budget:        80,
+		StartDate:     start,
+		PenaltyPolicy: entities.DefaultPenaltyPolicy(8, 80),
+		Schedule:      entities.Schedule{Kind: entities.ScheduleKindWeeklyTarget, TimesPerWeek: 3},
+	}
+	week := []*entities.HabitTask{
+		{ID: "wed", UserID: "user-1", GrindID: "grind-1", Date: start, Completed: true},
+		{ID: "thu", UserID: "user-1", GrindID: "grind-1", Date: start.AddDate(0, 0, 1)},
+		{ID: "fri", UserID: "user-1", GrindID: "grind-1", Date: start.AddDate(0, 0, 2)},
+		{ID: "sat", UserID: "user-1", GrindID: "grind-1", Date: start.AddDate(0, 0, 3)},
+		{ID: "sun", UserID: "user-1", GrindID: "grind-1", Date: start.AddDate(0, 0, 4)},
+		{ID: "next-mon", UserID: "user-1", GrindID: "grind-1", Date: start.AddDate(0, 0, 5)},
+	}
+
+	// Mid-week days are left alone.
+	thursday := start.AddDate(0, 0, 1)
+	repos := newTestRepos()
+	svc := NewMissedDayService(nil, repos.grind, repos.user, repos.habitTask, repos.participation)
+	svc.now = func() time.Time { return thursday.Add(48 * time.Hour) }
+	expectDayTasks(repos, thursday, grind, []entities.User{{ID: "user-1", Timezone: "UTC"}}, []*entities.HabitTask{week[1]})
+
+	result, err := svc.EvaluateDay(dto.EvaluateDayDTO{Day: thursday})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.TasksMarkedMissed)
+	repos.habitTask.AssertNotCalled(t, "MarkMissed", mock.Anything)
+
+	// Sunday closes the week: one completion against a target of 3 misses two days.
+	sunday := start.AddDate(0, 0, 4)
+	repos = newTestRepos()
+	svc = NewMissedDayService(nil, repos.grind, repos.user, repos.habitTask, repos.participation)
+	svc.now = func() time.Time { return sunday.Add(48 * time.Hour) }
+	expectDayTasks(repos, sunday, grind, []entities.User{{ID: "user-1", Timezone: "UTC"}}, []*entities.HabitTask{week[4]})
+	participation := &entities.Participation{ID: "part-1", UserID: "user-1", GrindID: "grind-1"}
+	repos.participation.On("FindByUserAndGrindForUpdate", "user-1", "grind-1").Return(participation, nil)
+	repos.habitTask.On("FindByGrindIDAndUserID", "grind-1", "user-1").Return(week, nil)
+	repos.habitTask.On("MarkMissed", "thu").Return(true, nil)
+	repos.habitTask.On("MarkMissed", "fri").Return(true, nil)
+	repos.habitTask.On("MarkExcused", "sat").Return(true, nil)
+	repos.habitTask.On("MarkExcused", "sun").Return(true, nil)
+	repos.participation.On("Update", participation).Return(nil)
+
+	result, err = svc.EvaluateDay(dto.EvaluateDayDTO{Day: sunday})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.TasksMarkedMissed)
+	assert.Equal(t, 20, result.PenaltyAccrued)
+	assert.Equal(t, 2, participation.MissedDays)
+	repos.habitTask.AssertNotCalled(t, "MarkExcused", "next-mon")
+	repos.habitTask.AssertExpectations(t)
+}
+
+func Test_MissedDayService_EvaluateDay_SkipsUnscheduledDays(t *testing.T) {
+	t.Parallel()
+
+	// Saturday 2026-04-11 is not a habit day of a weekdays grind
+	saturday := time.Date(2026, 4, 11, 0, 0, 0, 0, time.UTC)
+	repos := newTestRepos()
+	svc := NewMissedDayService(nil, repos.grind, repos.user, repos.habitTask, repos.participation)
+	svc.now = func() time.Time { return saturday.Add(48 * time.Hour) }
+
+	grind := &entities.Grind{
+		ID:            "grind-1",
+		Duration:      14,
+		StartDate:     time.Date(2026, 4, 6, 0, 0, 0, 0, time.UTC),
+		PenaltyPolicy: entities.DefaultPenaltyPolicy(10, 100),
+		Schedule:      entities.Schedule{Kind: entities.ScheduleKindWeekdays},
+	}
+	expectDayTasks(repos, saturday, grind,
+		[]entities.User{{ID: "user-1", Timezone: "UTC"}},
+		[]*entities.HabitTask{{ID: "stale", UserID: "user-1", GrindID: "grind-1", Date: saturday}})
+
+	result, err := svc.EvaluateDay(dto.EvaluateDayDTO{Day: saturday})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.TasksMarkedMissed)
+	repos.habitTask.AssertNotCalled(t, "MarkMissed", mock.Anything)
+}
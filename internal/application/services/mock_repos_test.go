@@ -0,0 +1,33 @@
+package services
+
+import "github.com/daniel0321forever/terriyaki-go/internal/domain/mocks"
+
+// testRepos holds a fresh mock of every repository the services take. A test registers
+// the expectations it relies on and passes the fields it needs to the service's constructor.
+type testRepos struct {
+	grind           *mocks.MockGrindRepository
+	user            *mocks.MockUserRepository
+	habitTask       *mocks.MockHabitTaskRepository
+	participation   *mocks.MockParticipationRepository
+	partnerGroup    *mocks.MockPartnerGroupRepository
+	completionEvent *mocks.MockCompletionEventRepository
+	dispute         *mocks.MockCompletionDisputeRepository
+	deadLetter      *mocks.MockDeadLetterRepository
+	integration     *mocks.MockIntegrationRepository
+	message         *mocks.MockMessageRepository
+}
+
+func newTestRepos() testRepos {
+	return testRepos{
+		grind:           new(mocks.MockGrindRepository),
+		user:            new(mocks.MockUserRepository),
+		habitTask:       new(mocks.MockHabitTaskRepository),
+		participation:   new(mocks.MockParticipationRepository),
+		partnerGroup:    new(mocks.MockPartnerGroupRepository),
+		completionEvent: new(mocks.MockCompletionEventRepository),
+		dispute:         new(mocks.MockCompletionDisputeRepository),
+		deadLetter:      new(mocks.MockDeadLetterRepository),
+		integration:     new(mocks.MockIntegrationRepository),
+		message:         new(mocks.MockMessageRepository),
+	}
+}
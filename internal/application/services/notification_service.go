@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
+	"github.com/daniel0321forever/terriyaki-go/internal/application/mappers"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/repositories"
+	"gorm.io/gorm"
+)
+
+// NotificationBroker delivers notifications to the streams users have open, on any API
+// replica. Publish assigns the notification's ID. Subscribe replays the user's
+// notifications published after lastEventID, when it is set, then delivers new ones
+// until ctx is done. The channel is closed when ctx is done, or early when the
+// subscriber falls too far behind; the client then reconnects with the last ID it saw.
+type NotificationBroker interface {
+	Publish(notification *entities.Notification) error
+	Subscribe(ctx context.Context, userID, lastEventID string) (<-chan *entities.Notification, error)
+}
+
+// NotificationService pushes new messages, invitation answers, grind partners'
+// completions and settlement updates to the users they concern. It hooks into the
+// repositories those writes go through, so the services making them are unchanged, and
+// publishes once the write's transaction has committed. Publishing is best effort: a
+// failure is logged and never fails the write.
+type NotificationService struct {
+	broker            NotificationBroker
+	userRepo          repositories.UserRepository
+	grindRepo         repositories.GrindRepository
+	messageRepo       repositories.MessageRepository
+	habitTaskRepo     repositories.HabitTaskRepository
+	participationRepo repositories.ParticipationRepository
+	partnerGroupRepo  repositories.PartnerGroupRepository
+}
+
+func NewNotificationService(
+	broker NotificationBroker,
+	userRepo repositories.UserRepository,
+	grindRepo repositories.GrindRepository,
+	messageRepo repositories.MessageRepository,
+	habitTaskRepo repositories.HabitTaskRepository,
+	participationRepo repositories.ParticipationRepository,
+	partnerGroupRepo repositories.PartnerGroupRepository,
+) *NotificationService {
+	return &NotificationService{
+		broker:            broker,
+		userRepo:          userRepo,
+		grindRepo:         grindRepo,
+		messageRepo:       messageRepo,
+		habitTaskRepo:     habitTaskRepo,
+		participationRepo: participationRepo,
+		partnerGroupRepo:  partnerGroupRepo,
+	}
+}
+
+// Subscribe opens the user's notification stream. See NotificationBroker.Subscribe.
+func (s *NotificationService) Subscribe(ctx context.Context, request dto.SubscribeNotificationsDTO) (<-chan *dto.NotificationDTO, error) {
+	notifications, err := s.broker.Subscribe(ctx, request.UserID, request.LastEventID)
+	if err != nil {
+		return nil, err
+	}
+
+	stream := make(chan *dto.NotificationDTO)
+	go func() {
+		defer close(stream)
+		for notification := range notifications {
+			select {
+			case stream <- mappers.BuildNotificationDTO(notification):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return stream, nil
+}
+
+// MessageRepository wraps repo so that every message created through it notifies its
+// receiver. A nil service returns repo unchanged.
+func (s *NotificationService) MessageRepository(repo repositories.MessageRepository) repositories.MessageRepository {
+	if s == nil {
+		return repo
+	}
+	return &notifyingMessageRepository{MessageRepository: repo, notifications: s}
+}
+
+// CompletionEventRepository wraps repo so that every completion event created through it
+// notifies the user's grind partners. A nil service returns repo unchanged.
+func (s *NotificationService) CompletionEventRepository(repo repositories.CompletionEventRepository) repositories.CompletionEventRepository {
+	if s == nil {
+		return repo
+	}
+	return &notifyingCompletionEventRepository{CompletionEventRepository: repo, notifications: s}
+}
+
+// PaymentSettlementRepository wraps repo so that every grind settlement leg created or
+// updated through it notifies the leg's user. A nil service returns repo unchanged.
+func (s *NotificationService) PaymentSettlementRepository(repo repositories.PaymentSettlementRepository) repositories.PaymentSettlementRepository {
+	if s == nil {
+		return repo
+	}
+	return &notifyingPaymentSettlementRepository{PaymentSettlementRepository: repo, notifications: s}
+}
+
+// messageCreated notifies the receiver of message, unless it is a general message from a
+// counterpart the receiver muted. Answers to an invitation also tell both sides the
+// invitation's new status.
+func (s *NotificationService) messageCreated(message *entities.Message) {
+	if message.Type == config.MESSAGE_TYPE_GENERAL {
+		setting, err := s.messageRepo.FindConversationSetting(message.ReceiverID, message.SenderID)
+		if err != nil {
+			fmt.Println("notification skipped for message", message.ID, err)
+			return
+		}
+		if setting != nil && setting.Muted {
+			return
+		}
+	}
+
+	messageDTO, err := buildMessageDTO(s.userRepo, s.grindRepo, message)
+	if err != nil {
+		fmt.Println("notification skipped for message", message.ID, err)
+		return
+	}
+	s.publish(message.ReceiverID, entities.NotificationTypeMessage, messageDTO)
+
+	var status string
+	switch message.Type {
+	case config.MESSAGE_TYPE_INVITATION_ACCEPTED:
+		status = "accepted"
+	case config.MESSAGE_TYPE_INVITATION_REJECTED:
+		status = "rejected"
+	default:
+		return
+	}
+	invitationStatus := dto.InvitationStatusDTO{GrindID: message.InvitationGrindID, UserID: message.SenderID, Status: status}
+	for _, userID := range []string{message.ReceiverID, message.SenderID} {
+		s.publish(userID, entities.NotificationTypeInvitationStatus, invitationStatus)
+	}
+}
+
+// completionEventCreated notifies the partners of the grind the event's habit task
+// belongs to.
+func (s *NotificationService) completionEventCreated(event *entities.CompletionEvent) {
+	task, err := s.habitTaskRepo.FindByID(event.HabitTaskID)
+	if err != nil {
+		fmt.Println("notification skipped for completion event", event.ID, err)
+		return
+	}
+	partners, err := grindPartnerIDs(s.participationRepo, s.partnerGroupRepo, task.GrindID, event.UserID)
+	if err != nil {
+		fmt.Println("notification skipped for completion event", event.ID, err)
+		return
+	}
+
+	completion := dto.CompletionNotificationDTO{GrindID: task.GrindID, CompletionEvent: mappers.BuildCompletionEventDTO(event)}
+	for _, partnerID := range partners {
+		s.publish(partnerID, entities.NotificationTypeCompletion, completion)
+	}
+}
+
+// settlementChanged notifies the user of a grind settlement leg. Settlements outside a
+// grind answer a request of the user and are not pushed.
+func (s *NotificationService) settlementChanged(settlement *entities.PaymentSettlement) {
+	if settlement == nil || settlement.GrindID == "" {
+		return
+	}
+	s.publish(settlement.UserID, entities.NotificationTypeSettlement, buildPaymentSettlementDTO(settlement))
+}
+
+func (s *NotificationService) publish(userID string, notificationType entities.NotificationType, data interface{}) {
+	notification, err := entities.NewNotification(userID, notificationType, data)
+	if err == nil {
+		err = s.broker.Publish(notification)
+	}
+	if err != nil {
+		fmt.Println("notification publish failed", userID, notificationType, err)
+	}
+}
+
+type notifyingMessageRepository struct {
+	repositories.MessageRepository
+	notifications *NotificationService
+	tx            *gorm.DB
+}
+
+func (r *notifyingMessageRepository) WithTx(tx *gorm.DB) repositories.MessageRepository {
+	return &notifyingMessageRepository{
+		MessageRepository: getMessageRepo(r.MessageRepository, tx),
+		notifications:     r.notifications,
+		tx:                tx,
+	}
+}
+
+func (r *notifyingMessageRepository) Create(message *entities.Message) error {
+	if err := r.MessageRepository.Create(message); err != nil {
+		return err
+	}
+	afterCommit(r.tx, func() { r.notifications.messageCreated(message) })
+	return nil
+}
+
+func (r *notifyingMessageRepository) CreateInvitationIfAbsent(invitation *entities.Message) (bool, error) {
+	created, err := r.MessageRepository.CreateInvitationIfAbsent(invitation)
+	if err == nil && created {
+		afterCommit(r.tx, func() { r.notifications.messageCreated(invitation) })
+	}
+	return created, err
+}
+
+type notifyingCompletionEventRepository struct {
+	repositories.CompletionEventRepository
+	notifications *NotificationService
+	tx            *gorm.DB
+}
+
+func (r *notifyingCompletionEventRepository) WithTx(tx *gorm.DB) repositories.CompletionEventRepository {
+	return &notifyingCompletionEventRepository{
+		CompletionEventRepository: getCompletionEventRepo(r.CompletionEventRepository, tx),
+		notifications:             r.notifications,
+		tx:                        tx,
+	}
+}
+
+func (r *notifyingCompletionEventRepository) Create(event *entities.CompletionEvent) error {
+	if err := r.CompletionEventRepository.Create(event); err != nil {
+		return err
+	}
+	afterCommit(r.tx, func() { r.notifications.completionEventCreated(event) })
+	return nil
+}
+
+func (r *notifyingCompletionEventRepository) CreateIfAbsent(event *entities.CompletionEvent) (bool, error) {
+	created, err := r.CompletionEventRepository.CreateIfAbsent(event)
+	if err == nil && created {
+		afterCommit(r.tx, func() { r.notifications.completionEventCreated(event) })
+	}
+	return created, err
+}
+
+type notifyingPaymentSettlementRepository struct {
+	repositories.PaymentSettlementRepository
+	notifications *NotificationService
+}
+
+func (r *notifyingPaymentSettlementRepository) Create(settlement *entities.PaymentSettlement) (*entities.PaymentSettlement, error) {
+	created, err := r.PaymentSettlementRepository.Create(settlement)
+	if err == nil {
+		r.notifications.settlementChanged(created)
+	}
+	return created, err
+}
+
+func (r *notifyingPaymentSettlementRepository) Update(settlement *entities.PaymentSettlement) (*entities.PaymentSettlement, error) {
+	updated, err := r.PaymentSettlementRepository.Update(settlement)
+	if err == nil {
+		r.notifications.settlementChanged(updated)
+	}
+	return updated, err
+}
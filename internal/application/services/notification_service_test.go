@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// recordingBroker keeps what is published; Subscribe replays it all.
+type recordingBroker struct {
+	mu        sync.Mutex
+	published []*entities.Notification
+}
+
+func (b *recordingBroker) Publish(notification *entities.Notification) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.published = append(b.published, notification)
+	notification.ID = fmt.Sprintf("0-%d", len(b.published))
+	return nil
+}
+
+func (b *recordingBroker) Subscribe(ctx context.Context, userID, lastEventID string) (<-chan *entities.Notification, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	stream := make(chan *entities.Notification, len(b.published))
+	for _, notification := range b.published {
+		if notification.UserID == userID {
+			stream <- notification
+		}
+	}
+	close(stream)
+	return stream, nil
+}
+
+// sent returns the recipients and types of the published notifications.
+func (b *recordingBroker) sent() [][2]string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	sent := make([][2]string, 0, len(b.published))
+	for _, notification := range b.published {
+		sent = append(sent, [2]string{notification.UserID, string(notification.Type)})
+	}
+	return sent
+}
+
+// expectNotificationGrind wires users "amy", "bob" and "cat", all in grind "grind-1" with
+// no partner group.
+func expectNotificationGrind(repos testRepos) {
+	participations := []*entities.Participation{}
+	for _, id := range []string{"amy", "bob", "cat"} {
+		repos.user.On("FindById", id).Return(&entities.User{ID: id, Username: id}, nil).Maybe()
+		participations = append(participations, &entities.Participation{ID: "part-" + id, UserID: id, GrindID: "grind-1"})
+	}
+	repos.grind.On("FindById", "grind-1").Return(&entities.Grind{ID: "grind-1"}, nil).Maybe()
+	repos.participation.On("FindByGrindID", "grind-1").Return(participations, nil).Maybe()
+	repos.partnerGroup.On("FindByGrindID", "grind-1").Return(nil, gorm.ErrRecordNotFound).Maybe()
+}
+
+func Test_NotificationService_MessageRepository(t *testing.T) {
+	t.Parallel()
+
+	send := func(t *testing.T, mutedByReceiver bool, message *entities.Message) [][2]string {
+		repos := newTestRepos()
+		expectNotificationGrind(repos)
+		broker := &recordingBroker{}
+		svc := NewNotificationService(broker, repos.user, repos.grind, repos.message, repos.habitTask, repos.participation, repos.partnerGroup)
+		var setting *entities.ConversationSetting
+		if mutedByReceiver {
+			setting = &entities.ConversationSetting{UserID: message.ReceiverID, CounterpartID: message.SenderID, Muted: true}
+		}
+		repos.message.On("FindConversationSetting", message.ReceiverID, message.SenderID).Return(setting, nil).Maybe()
+		repos.message.On("Create", message).Return(nil)
+
+		require.NoError(t, svc.MessageRepository(repos.message).Create(message))
+		return broker.sent()
+	}
+
+	t.Run("notifies the receiver", func(t *testing.T) {
+		t.Parallel()
+		message, _ := entities.NewMessage("amy", "bob", "hi", "general", "", false, false)
+		assert.Equal(t, [][2]string{{"bob", "message"}}, send(t, false, message))
+	})
+
+	t.Run("skips a muted conversation", func(t *testing.T) {
+		t.Parallel()
+		message, _ := entities.NewMessage("amy", "bob", "hi", "general", "", false, false)
+		assert.Empty(t, send(t, true, message))
+	})
+
+	t.Run("tells both sides an invitation was answered", func(t *testing.T) {
+		t.Parallel()
+		message, _ := entities.NewMessage("bob", "amy", "bob accepted your invitation", "invitation_accepted", "grind-1", true, false)
+		assert.Equal(t, [][2]string{{"amy", "message"}, {"amy", "invitation_status"}, {"bob", "invitation_status"}}, send(t, false, message))
+	})
+
+	t.Run("a failed write notifies nobody", func(t *testing.T) {
+		t.Parallel()
+		repos := newTestRepos()
+		expectNotificationGrind(repos)
+		broker := &recordingBroker{}
+		svc := NewNotificationService(broker, repos.user, repos.grind, repos.message, repos.habitTask, repos.participation, repos.partnerGroup)
+		message, _ := entities.NewMessage("amy", "bob", "hi", "general", "", false, false)
+		repos.message.On("Create", message).Return(errors.New("db down"))
+
+		assert.Error(t, svc.MessageRepository(repos.message).Create(message))
+		assert.Empty(t, broker.sent())
+	})
+}
+
+func Test_NotificationService_InvitationStatusPayload(t *testing.T) {
+	t.Parallel()
+
+	repos := newTestRepos()
+	expectNotificationGrind(repos)
+	svc := NewNotificationService(&recordingBroker{}, repos.user, repos.grind, repos.message, repos.habitTask, repos.participation, repos.partnerGroup)
+	message, _ := entities.NewMessage("bob", "amy", "bob rejected your invitation", "invitation_rejected", "grind-1", false, true)
+	repos.message.On("Create", message).Return(nil)
+	require.NoError(t, svc.MessageRepository(repos.message).Create(message))
+
+	stream, err := svc.Subscribe(context.Background(), dto.SubscribeNotificationsDTO{UserID: "amy"})
+	require.NoError(t, err)
+	var received []*dto.NotificationDTO
+	for notification := range stream {
+		received = append(received, notification)
+	}
+	require.Len(t, received, 2)
+	assert.Equal(t, "invitation_status", received[1].Type)
+	var status dto.InvitationStatusDTO
+	require.NoError(t, json.Unmarshal(received[1].Data, &status))
+	assert.Equal(t, dto.InvitationStatusDTO{GrindID: "grind-1", UserID: "bob", Status: "rejected"}, status)
+	assert.NotEmpty(t, received[1].ID)
+}
+
+func Test_NotificationService_CompletionEventRepository(t *testing.T) {
+	t.Parallel()
+
+	repos := newTestRepos()
+	expectNotificationGrind(repos)
+	broker := &recordingBroker{}
+	svc := NewNotificationService(broker, repos.user, repos.grind, repos.message, repos.habitTask, repos.participation, repos.partnerGroup)
+	repos.habitTask.On("FindByID", "task-1").Return(&entities.HabitTask{ID: "task-1", UserID: "amy", GrindID: "grind-1"}, nil)
+	event := &entities.CompletionEvent{ID: "event-1", HabitTaskID: "task-1", UserID: "amy", Provider: entities.ProviderLeetCode}
+	repos.completionEvent.On("CreateIfAbsent", event).Return(true, nil).Once()
+	repos.completionEvent.On("CreateIfAbsent", event).Return(false, nil).Once()
+
+	repo := svc.CompletionEventRepository(repos.completionEvent)
+	_, err := repo.CreateIfAbsent(event)
+	require.NoError(t, err)
+	// a retried delivery records nothing and notifies nobody
+	_, err = repo.CreateIfAbsent(event)
+	require.NoError(t, err)
+
+	assert.Equal(t, [][2]string{{"bob", "completion"}, {"cat", "completion"}}, broker.sent())
+}
+
+func Test_NotificationService_PaymentSettlementRepository(t *testing.T) {
+	t.Parallel()
+
+	repos := newTestRepos()
+	expectNotificationGrind(repos)
+	broker := &recordingBroker{}
+	svc := NewNotificationService(broker, repos.user, repos.grind, repos.message, repos.habitTask, repos.participation, repos.partnerGroup)
+	repo := svc.PaymentSettlementRepository(newInMemorySettlementRepo())
+
+	leg := entities.NewPaymentSettlement("amy", settlementPayoutOperation, "grind-1:payout:amy:1", entities.PaymentProviderStripe, "pm_1", 500)
+	leg.GrindID = "grind-1"
+	leg, err := repo.Create(leg)
+	require.NoError(t, err)
+	leg.Status = entities.SettlementStatusCaptured
+	_, err = repo.Update(leg)
+	require.NoError(t, err)
+	_, err = repo.Create(entities.NewPaymentSettlement("bob", "payment_intent", "intent-1", entities.PaymentProviderStripe, "pm_2", 100))
+	require.NoError(t, err)
+
+	assert.Equal(t, [][2]string{{"amy", "settlement"}, {"amy", "settlement"}}, broker.sent())
+}
+
+func Test_NotificationService_NilServiceLeavesRepositories(t *testing.T) {
+	t.Parallel()
+
+	var svc *NotificationService
+	messageRepo := new(mocks.MockMessageRepository)
+	assert.Same(t, messageRepo, svc.MessageRepository(messageRepo))
+	messageRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
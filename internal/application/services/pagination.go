@@ -0,0 +1,72 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+)
+
+// pageCursorPayload is what an opaque cursor holds; clients only ever echo it back.
+type pageCursorPayload struct {
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"i"`
+}
+
+// encodePageCursor turns a position into the opaque cursor handed to clients.
+func encodePageCursor(cursor entities.PageCursor) string {
+	payload, _ := json.Marshal(pageCursorPayload{CreatedAt: cursor.CreatedAt.UTC(), ID: cursor.ID})
+	return base64.RawURLEncoding.EncodeToString(payload)
+}
+
+// decodePageCursor reads a cursor from encodePageCursor; an empty cursor starts at the newest row.
+func decodePageCursor(cursor string) (entities.PageCursor, error) {
+	if cursor == "" {
+		return entities.PageCursor{}, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return entities.PageCursor{}, fmt.Errorf("%w: %q", config.ErrInvalidCursor, cursor)
+	}
+	var payload pageCursorPayload
+	if err := json.Unmarshal(raw, &payload); err != nil || payload.ID == "" || payload.CreatedAt.IsZero() {
+		return entities.PageCursor{}, fmt.Errorf("%w: %q", config.ErrInvalidCursor, cursor)
+	}
+	return entities.PageCursor{CreatedAt: payload.CreatedAt, ID: payload.ID}, nil
+}
+
+// pageFetchLimit is how many rows to ask a repository for: one more than the page holds,
+// so that the extra row tells whether another page follows. A limit <= 0 fetches every row.
+func pageFetchLimit(limit int) int {
+	if limit <= 0 {
+		return 0
+	}
+	return limit + 1
+}
+
+// trimPage cuts rows fetched with pageFetchLimit down to the page and returns the cursor of
+// its last row, or "" when no page follows.
+func trimPage[T any](rows []T, limit int, position func(T) entities.PageCursor) ([]T, string) {
+	if limit <= 0 || len(rows) <= limit {
+		return rows, ""
+	}
+	rows = rows[:limit]
+	return rows, encodePageCursor(position(rows[limit-1]))
+}
+
+func messagePosition(message *entities.Message) entities.PageCursor {
+	return entities.PageCursor{CreatedAt: message.CreatedAt, ID: message.ID}
+}
+
+// conversationPosition is the position of the conversation's last message, by which
+// conversations are ordered.
+func conversationPosition(conversation *entities.Conversation) entities.PageCursor {
+	return messagePosition(conversation.LastMessage)
+}
+
+func grindPosition(grind *entities.Grind) entities.PageCursor {
+	return entities.PageCursor{CreatedAt: grind.CreatedAt, ID: grind.ID}
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PageCursor_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	position := entities.PageCursor{CreatedAt: time.Date(2026, 4, 10, 12, 0, 0, 123456000, time.UTC), ID: "msg-1"}
+	decoded, err := decodePageCursor(encodePageCursor(position))
+	require.NoError(t, err)
+	assert.True(t, position.CreatedAt.Equal(decoded.CreatedAt))
+	assert.Equal(t, position.ID, decoded.ID)
+
+	start, err := decodePageCursor("")
+	require.NoError(t, err)
+	assert.True(t, start.IsZero())
+}
+
+func Test_PageCursor_RejectsTamperedCursors(t *testing.T) {
+	t.Parallel()
+
+	for _, cursor := range []string{"%%%", "bm90IGpzb24", "e30"} { // not base64, not JSON, {}
+		_, err := decodePageCursor(cursor)
+		assert.True(t, errors.Is(err, config.ErrInvalidCursor), "cursor %q: got %v", cursor, err)
+	}
+}
+
+func Test_TrimPage(t *testing.T) {
+	t.Parallel()
+
+	createdAt := time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC)
+	grinds := []*entities.Grind{{ID: "g3", CreatedAt: createdAt}, {ID: "g2", CreatedAt: createdAt}, {ID: "g1", CreatedAt: createdAt}}
+
+	page, next := trimPage(grinds, 2, grindPosition)
+	assert.Equal(t, grinds[:2], page)
+	assert.Equal(t, encodePageCursor(entities.PageCursor{CreatedAt: createdAt, ID: "g2"}), next)
+
+	page, next = trimPage(grinds, 3, grindPosition)
+	assert.Len(t, page, 3)
+	assert.Empty(t, next)
+
+	page, next = trimPage(grinds, 0, grindPosition)
+	assert.Len(t, page, 3)
+	assert.Empty(t, next)
+}
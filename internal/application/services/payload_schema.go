@@ -0,0 +1,176 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/daniel0321forever/terriyaki-go/assets"
+	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// PayloadValidationError lists every way a payload violates its provider's schema. It
+// matches config.ErrInvalidPayload.
+type PayloadValidationError struct {
+	Provider string
+	Fields   []dto.FieldErrorDTO
+}
+
+func (e *PayloadValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		if field.Field == "" {
+			messages = append(messages, field.Message)
+		} else {
+			messages = append(messages, field.Field+": "+field.Message)
+		}
+	}
+	return fmt.Sprintf("%v: %s", config.ErrInvalidPayload, strings.Join(messages, "; "))
+}
+
+func (e *PayloadValidationError) Unwrap() error { return config.ErrInvalidPayload }
+
+// PayloadSchema returns the JSON Schema document of a provider's payload, as published in
+// openapi.yaml.
+func PayloadSchema(provider string) ([]byte, error) {
+	return assets.PayloadSchemas.ReadFile("schemas/" + provider + ".json")
+}
+
+// embeddedPayloadSchema is PayloadSchema for the providers shipped with the binary; their
+// schemas are always embedded.
+func embeddedPayloadSchema(provider string) []byte {
+	document, _ := PayloadSchema(provider)
+	return document
+}
+
+var (
+	payloadSchemasMu sync.Mutex
+	payloadSchemas   = map[string]*jsonschema.Schema{}
+	schemaPrinter    = message.NewPrinter(language.English)
+)
+
+// compiledPayloadSchema compiles a provider's schema on first use. Formats such as
+// date-time are asserted, not only annotated.
+func compiledPayloadSchema(provider IngestionProvider) (*jsonschema.Schema, error) {
+	payloadSchemasMu.Lock()
+	defer payloadSchemasMu.Unlock()
+	name := provider.ProviderName()
+	if schema, ok := payloadSchemas[name]; ok {
+		return schema, nil
+	}
+
+	document := provider.PayloadSchema()
+	if len(document) == 0 {
+		return nil, fmt.Errorf("no payload schema for provider %s", name)
+	}
+	parsed, err := jsonschema.UnmarshalJSON(bytes.NewReader(document))
+	if err != nil {
+		return nil, fmt.Errorf("invalid payload schema for provider %s: %w", name, err)
+	}
+	compiler := jsonschema.NewCompiler()
+	compiler.AssertFormat()
+	location := name + ".json"
+	if err := compiler.AddResource(location, parsed); err != nil {
+		return nil, fmt.Errorf("invalid payload schema for provider %s: %w", name, err)
+	}
+	schema, err := compiler.Compile(location)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payload schema for provider %s: %w", name, err)
+	}
+	payloadSchemas[name] = schema
+	return schema, nil
+}
+
+// validatePayload checks raw against the provider's schema and returns a
+// *PayloadValidationError naming each offending field.
+func validatePayload(provider IngestionProvider, raw map[string]interface{}) error {
+	schema, err := compiledPayloadSchema(provider)
+	if err != nil {
+		return err
+	}
+	if raw == nil {
+		return &PayloadValidationError{Provider: provider.ProviderName(), Fields: []dto.FieldErrorDTO{{Message: "the payload must be a JSON object"}}}
+	}
+	err = schema.Validate(map[string]interface{}(raw))
+	if err == nil {
+		return nil
+	}
+	validationErr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return fmt.Errorf("failed to validate payload: %w", err)
+	}
+
+	fields := fieldErrors(validationErr)
+	sort.SliceStable(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+	return &PayloadValidationError{Provider: provider.ProviderName(), Fields: fields}
+}
+
+// fieldErrors flattens a validation error into one entry per offending field. A missing
+// property is reported on the property itself, a malformed value by the format it misses,
+// and alternatives that are all missing properties, e.g. problemSlug or problemURL, are
+// reported together.
+func fieldErrors(err *jsonschema.ValidationError) []dto.FieldErrorDTO {
+	switch k := err.ErrorKind.(type) {
+	case *kind.Required:
+		fields := make([]dto.FieldErrorDTO, 0, len(k.Missing))
+		for _, name := range k.Missing {
+			field := name
+			if parent := fieldPath(err.InstanceLocation); parent != "" {
+				field = parent + "." + name
+			}
+			fields = append(fields, dto.FieldErrorDTO{Field: field, Message: "is required"})
+		}
+		return fields
+	case *kind.Format:
+		return []dto.FieldErrorDTO{{Field: fieldPath(err.InstanceLocation), Message: "must be a valid " + k.Want}}
+	case *kind.AnyOf, *kind.OneOf:
+		if missing, ok := missingAlternatives(err); ok {
+			return []dto.FieldErrorDTO{{
+				Field:   fieldPath(err.InstanceLocation),
+				Message: "one of " + strings.Join(missing, ", ") + " is required",
+			}}
+		}
+		if _, ok := k.(*kind.AnyOf); ok {
+			return []dto.FieldErrorDTO{{Field: fieldPath(err.InstanceLocation), Message: "does not match any of the allowed types"}}
+		}
+	}
+
+	if len(err.Causes) == 0 {
+		return []dto.FieldErrorDTO{{Field: fieldPath(err.InstanceLocation), Message: err.ErrorKind.LocalizedString(schemaPrinter)}}
+	}
+	var fields []dto.FieldErrorDTO
+	for _, cause := range err.Causes {
+		fields = append(fields, fieldErrors(cause)...)
+	}
+	return fields
+}
+
+// missingAlternatives returns the properties an anyOf or oneOf of required lists wants,
+// when each alternative failed only for a missing property.
+func missingAlternatives(err *jsonschema.ValidationError) ([]string, bool) {
+	var missing []string
+	for _, cause := range err.Causes {
+		for len(cause.Causes) == 1 {
+			cause = cause.Causes[0]
+		}
+		required, ok := cause.ErrorKind.(*kind.Required)
+		if !ok || len(cause.Causes) > 0 {
+			return nil, false
+		}
+		missing = append(missing, required.Missing...)
+	}
+	return missing, len(missing) > 0
+}
+
+// fieldPath joins a JSON instance location into a dotted path, like the paths of an
+// integration's field mapping.
+func fieldPath(location []string) string {
+	return strings.Join(location, ".")
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func Test_ValidatePayload(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		provider IngestionProvider
+		payload  map[string]interface{}
+		fields   []dto.FieldErrorDTO
+	}{
+		{
+			name:     "leetcode slug",
+			provider: &LeetCodeProvider{},
+			payload:  map[string]interface{}{"problemSlug": "two-sum", "submissionId": float64(1234)},
+		},
+		{
+			name:     "leetcode url",
+			provider: &LeetCodeProvider{},
+			payload:  map[string]interface{}{"problemURL": "https://leetcode.com/problems/two-sum/"},
+		},
+		{
+			name:     "leetcode without a problem",
+			provider: &LeetCodeProvider{},
+			payload:  map[string]interface{}{"occurredAt": "2026-04-10T11:00:00Z"},
+			fields:   []dto.FieldErrorDTO{{Field: "", Message: "one of problemSlug, problemURL is required"}},
+		},
+		{
+			name:     "leetcode bad fields",
+			provider: &LeetCodeProvider{},
+			payload:  map[string]interface{}{"problemSlug": float64(1), "occurredAt": "yesterday"},
+			fields: []dto.FieldErrorDTO{
+				{Field: "occurredAt", Message: "must be a valid date-time"},
+				{Field: "problemSlug", Message: "got number, want string"},
+			},
+		},
+		{
+			name:     "duolingo",
+			provider: &DuolingoProvider{},
+			payload:  map[string]interface{}{"eventId": "evt-1", "xpEarned": float64(20)},
+		},
+		{
+			name:     "duolingo empty",
+			provider: &DuolingoProvider{},
+			payload:  map[string]interface{}{},
+			fields:   []dto.FieldErrorDTO{{Field: "", Message: "one of occurredAt, eventId, lessonsCompleted, xpEarned, streakCount is required"}},
+		},
+		{
+			name:     "duolingo negative xp",
+			provider: &DuolingoProvider{},
+			payload:  map[string]interface{}{"xpEarned": float64(-5)},
+			fields:   []dto.FieldErrorDTO{{Field: "xpEarned", Message: "minimum: got -5, want 0"}},
+		},
+		{
+			name:     "not an object",
+			provider: &DuolingoProvider{},
+			payload:  nil,
+			fields:   []dto.FieldErrorDTO{{Field: "", Message: "the payload must be a JSON object"}},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validatePayload(tt.provider, tt.payload)
+			if tt.fields == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var invalid *PayloadValidationError
+			require.True(t, errors.As(err, &invalid), "got %v", err)
+			assert.True(t, errors.Is(err, config.ErrInvalidPayload))
+			assert.Equal(t, tt.provider.ProviderName(), invalid.Provider)
+			assert.Equal(t, tt.fields, invalid.Fields)
+		})
+	}
+}
+
+// openapi.yaml publishes each provider's payload schema under components/schemas; the
+// copies must not drift from the schemas ingest validates against.
+func Test_PayloadSchemas_MatchOpenAPI(t *testing.T) {
+	t.Parallel()
+
+	spec, err := os.ReadFile("../../../openapi.yaml")
+	require.NoError(t, err)
+	var document struct {
+		Components struct {
+			Schemas map[string]interface{} `yaml:"schemas"`
+		} `yaml:"components"`
+	}
+	require.NoError(t, yaml.Unmarshal(spec, &document))
+
+	for _, provider := range []IngestionProvider{&LeetCodeProvider{}, &DuolingoProvider{}, &CustomProvider{}} {
+		var embedded map[string]interface{}
+		require.NoError(t, json.Unmarshal(provider.PayloadSchema(), &embedded))
+		delete(embedded, "$schema")
+		delete(embedded, "$id")
+
+		published, ok := document.Components.Schemas[embedded["title"].(string)]
+		require.True(t, ok, "openapi.yaml does not publish %s", embedded["title"])
+		assert.Equal(t, normalizeJSON(t, embedded), normalizeJSON(t, published), provider.ProviderName())
+	}
+}
+
+// normalizeJSON round-trips value through JSON so YAML and JSON decodings compare equal.
+func normalizeJSON(t *testing.T, value interface{}) interface{} {
+	encoded, err := json.Marshal(value)
+	require.NoError(t, err)
+	var normalized interface{}
+	require.NoError(t, json.Unmarshal(encoded, &normalized))
+	return normalized
+}
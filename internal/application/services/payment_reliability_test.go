@@ -3,6 +3,7 @@ package services
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"testing"
 	"time"
@@ -12,8 +13,11 @@ import (
 )
 
 type fakePaymentAdapter struct {
-	chargeResponse string
-	chargeErr      error
+	chargeResponse  string
+	chargeErr       error
+	disbursementErr error
+	queryStatus     entities.SettlementStatus // defaults to captured
+	queryErr        error
 }
 
 func (f *fakePaymentAdapter) CreateCollectionIntent(req_ CollectionIntentRequestPayload) (CollectionIntentResultPayload, error) {
@@ -80,7 +84,14 @@ func (f *fakePaymentAdapter) QuerySettlementStatus(req_ QuerySettlementStatusReq
 	if !ok {
 		return nil, fmt.Errorf("fakePaymentAdapter expects StripeQuerySettlementStatusRequest, got %T", req_)
 	}
-	return &StripeSettlementResolutionResult{ProviderReference: req.ProviderReference, Status: entities.SettlementStatusCaptured}, nil
+	if f.queryErr != nil {
+		return nil, f.queryErr
+	}
+	status := f.queryStatus
+	if status == "" {
+		status = entities.SettlementStatusCaptured
+	}
+	return &StripeSettlementResolutionResult{ProviderReference: req.ProviderReference, Status: status}, nil
 }
 
 func (f *fakePaymentAdapter) CreateDisbursement(req_ DisbursementRequestPayload) (DisbursementResultPayload, error) {
@@ -88,6 +99,9 @@ func (f *fakePaymentAdapter) CreateDisbursement(req_ DisbursementRequestPayload)
 	if !ok {
 		return nil, fmt.Errorf("fakePaymentAdapter expects StripeDisbursementRequest, got %T", req_)
 	}
+	if f.disbursementErr != nil {
+		return nil, f.disbursementErr
+	}
 	return &StripeDisbursementResult{ProviderReference: req.DestinationReference, Status: entities.SettlementStatusCaptured}, nil
 }
 
@@ -208,6 +222,19 @@ func (r *inMemorySettlementRepo) FindByStatuses(statuses []entities.SettlementSt
 	return result, nil
 }
 
+func (r *inMemorySettlementRepo) FindByGrindID(grindID string) ([]entities.PaymentSettlement, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := make([]entities.PaymentSettlement, 0)
+	for _, settlement := range r.data {
+		if settlement.GrindID == grindID {
+			result = append(result, *settlement)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
 func TestPaymentIntentIdempotency(t *testing.T) {
 	t.Parallel()
 
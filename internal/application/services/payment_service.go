@@ -21,6 +21,7 @@ type PaymentServiceCore interface {
 	AddPaymentMethod(request dto.AddPaymentMethodDTO) (*dto.AddPaymentMethodResultDTO, error)
 	ChargeWithIdempotency(request dto.ChargeWithIdempotencyDTO, idempotencyKey string) (*dto.ChargeWithIdempotencyResultDTO, error)
 	PayBack(request dto.PayBackDTO) (*dto.PayBackResultDTO, error)
+	PayBackStatus(request dto.PayBackStatusDTO) (*dto.PayBackResultDTO, error)
 	FindDuedPayments() (*dto.PendingPaymentsResultDTO, error)
 	ReconcileSettlements(request dto.ReconcileSettlementsDTO) (*dto.ReconcileSettlementsResultDTO, error)
 	GetAvailablePaymentMethods(request dto.GetAvailablePaymentMethodsDTO) (*dto.AvailablePaymentMethodsDTO, error)
@@ -152,6 +153,7 @@ func (s *PaymentService) ChargeWithIdempotency(request dto.ChargeWithIdempotency
 	var settlement *entities.PaymentSettlement
 	if s.settlementRepo != nil {
 		settlement = entities.NewPaymentSettlement(userID, operation, idempotencyKey, provider, paymentInfo.ProviderPaymentMethodID, amount)
+		settlement.GrindID = request.GrindID
 		settlement.Reference.Network = paymentInfo.Network
 		settlement, err = s.settlementRepo.Create(settlement)
 		if err != nil {
@@ -224,6 +226,29 @@ func (s *PaymentService) PayBack(request dto.PayBackDTO) (*dto.PayBackResultDTO,
 	}, nil
 }
 
+// PayBackStatus asks the provider where an earlier disbursement stands.
+func (s *PaymentService) PayBackStatus(request dto.PayBackStatusDTO) (*dto.PayBackResultDTO, error) {
+	queryReq, reqErr := s.buildQuerySettlementStatusRequest(request.ProviderReference)
+	if reqErr != nil {
+		return nil, reqErr
+	}
+
+	result, err := s.adapter.QuerySettlementStatus(queryReq)
+	if err != nil {
+		return nil, err
+	}
+
+	providerRef, status, extractErr := extractReferenceAndStatusFromResolution(result)
+	if extractErr != nil {
+		return nil, extractErr
+	}
+
+	return &dto.PayBackResultDTO{
+		ProviderReference: providerRef,
+		Status:            string(status),
+	}, nil
+}
+
 func (s *PaymentService) GetAvailablePaymentMethods(request dto.GetAvailablePaymentMethodsDTO) (*dto.AvailablePaymentMethodsDTO, error) {
 	user, err := s.userRepo.FindById(request.UserID)
 	if err != nil {
@@ -254,7 +279,7 @@ func (s *PaymentService) GetAvailablePaymentMethods(request dto.GetAvailablePaym
 func (s *PaymentService) FindDuedPayments() (*dto.PendingPaymentsResultDTO, error) {
 	var grinds []*entities.Grind
 
-	// Find all grinds where StartDate + Duration (in days) is the current date (today, UTC)
+	// Find all grinds whose end date (StartDate + Duration days) is today in some timezone
 	grinds, err := s.grindRepo.FindDuedGrinds()
 	if err != nil {
 		return nil, err
@@ -262,9 +287,15 @@ func (s *PaymentService) FindDuedPayments() (*dto.PendingPaymentsResultDTO, erro
 
 	// get the punishment for each grind
 	var pendingPayments []dto.PendingPaymentDTO
+	now := time.Now()
 
 	for _, g := range grinds {
 		for _, p := range g.Participants {
+			// the grind is only due once the participant's own last day has ended
+			if !g.IsDueOn(now, p.Location()) {
+				continue
+			}
+
 			// get the stripe payment info for the user
 			var paymentMethodInfos []entities.PaymentMethodInfo
 			paymentMethodInfos, err := s.paymentMethodInfoRepo.FindByUserID(p.ID)
@@ -305,6 +336,11 @@ func (s *PaymentService) ReconcileSettlements(request dto.ReconcileSettlementsDT
 
 	for i := range settlements {
 		settlement := settlements[i]
+		if settlement.Operation == settlementPayoutOperation {
+			// PayBack takes no idempotency key, so a grind payout is never failed and retried
+			// blindly; GrindSettlementService reconciles it with the provider instead
+			continue
+		}
 		shouldUpdate := false
 
 		switch settlement.Status {
@@ -526,6 +562,20 @@ func (s *PaymentService) buildDisbursementRequest(request dto.PayBackDTO) (Disbu
 	}
 }
 
+func (s *PaymentService) buildQuerySettlementStatusRequest(providerReference string) (QuerySettlementStatusRequestPayload, error) {
+	if strings.TrimSpace(providerReference) == "" {
+		return nil, fmt.Errorf("provider reference is required to query a settlement")
+	}
+	switch s.provider {
+	case entities.PaymentProviderStripe:
+		return StripeQuerySettlementStatusRequest{ProviderReference: providerReference}, nil
+	case entities.PaymentProviderSolana:
+		return SolanaQuerySettlementStatusRequest{ProviderReference: providerReference}, nil
+	default:
+		return nil, fmt.Errorf("unsupported provider for settlement status: %s", s.provider)
+	}
+}
+
 func (s *PaymentService) buildSolanaCollectionIntentRequest(request dto.SolanaCreateIntentDTO) (CollectionIntentRequestPayload, error) {
 	if s.provider != entities.PaymentProviderSolana {
 		return nil, fmt.Errorf("solana collection intent requires Solana provider")
@@ -604,6 +654,17 @@ func extractReferenceAndStatusFromDisbursement(payload DisbursementResultPayload
 	}
 }
 
+func extractReferenceAndStatusFromResolution(payload SettlementResolutionResultPayload) (string, entities.SettlementStatus, error) {
+	switch v := payload.(type) {
+	case *StripeSettlementResolutionResult:
+		return v.ProviderReference, v.Status, nil
+	case *SolanaSettlementResolutionResult:
+		return v.ProviderReference, v.Status, nil
+	default:
+		return "", "", fmt.Errorf("unsupported settlement resolution result payload type %T", payload)
+	}
+}
+
 func buildPaymentSettlementDTO(settlement *entities.PaymentSettlement) dto.PaymentSettlementDTO {
 	if settlement == nil {
 		return dto.PaymentSettlementDTO{}
@@ -612,6 +673,7 @@ func buildPaymentSettlementDTO(settlement *entities.PaymentSettlement) dto.Payme
 	return dto.PaymentSettlementDTO{
 		ID:              settlement.ID,
 		UserID:          settlement.UserID,
+		GrindID:         settlement.GrindID,
 		Operation:       settlement.Operation,
 		IdempotencyKey:  settlement.IdempotencyKey,
 		Provider:        settlement.Provider,
@@ -2,6 +2,7 @@ package services
 
 import (
 	"testing"
+	"time"
 
 	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
 	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
@@ -43,9 +44,12 @@ func TestPaymentServiceFindDuedPayments(t *testing.T) {
 	t.Parallel()
 
 	grindRepo := new(mocks.MockGrindRepository)
+	today := entities.LocalDayStart(time.Now(), time.UTC)
 	grindRepo.On("FindDuedGrinds").Return([]*entities.Grind{{
 		ID:           "g1",
-		Participants: []entities.User{{ID: "u1"}},
+		Duration:     5,
+		StartDate:    today.AddDate(0, 0, -5),
+		Participants: []entities.User{{ID: "u1", Timezone: "UTC"}},
 	}}, nil)
 
 	partRepo := new(mocks.MockParticipationRepository)
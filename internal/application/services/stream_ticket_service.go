@@ -0,0 +1,65 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
+)
+
+// streamTicketTTL is how long a client has to open its stream after asking for a ticket.
+const streamTicketTTL = 30 * time.Second
+
+// StreamTicketStore keeps issued tickets until they are redeemed or expire. Take forgets
+// the ticket it returns.
+type StreamTicketStore interface {
+	Put(ticket, userID string, ttl time.Duration) error
+	Take(ticket string) (string, bool, error)
+}
+
+// StreamTicketService lets a browser open a notification stream without putting its JWT
+// in the URL, where access logs and proxies would keep it. The client trades its token
+// for a short-lived ticket and passes the ticket instead; a ticket opens one stream.
+type StreamTicketService struct {
+	store StreamTicketStore
+	now   func() time.Time
+}
+
+func NewStreamTicketService(store StreamTicketStore) *StreamTicketService {
+	return &StreamTicketService{store: store, now: time.Now}
+}
+
+// IssueTicket returns a new ticket for request.UserID.
+func (s *StreamTicketService) IssueTicket(request dto.IssueStreamTicketDTO) (*dto.StreamTicketDTO, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate stream ticket: %w", err)
+	}
+	ticket := hex.EncodeToString(secret)
+	if err := s.store.Put(ticket, request.UserID, streamTicketTTL); err != nil {
+		return nil, fmt.Errorf("failed to store stream ticket: %w", err)
+	}
+	return &dto.StreamTicketDTO{
+		Ticket:    ticket,
+		ExpiresAt: s.now().Add(streamTicketTTL),
+	}, nil
+}
+
+// RedeemTicket returns the user the ticket was issued to and invalidates it. Unknown,
+// expired and already used tickets are ErrInvalidStreamTicket.
+func (s *StreamTicketService) RedeemTicket(ticket string) (string, error) {
+	if ticket == "" {
+		return "", config.ErrInvalidStreamTicket
+	}
+	userID, ok, err := s.store.Take(ticket)
+	if err != nil {
+		return "", fmt.Errorf("failed to redeem stream ticket: %w", err)
+	}
+	if !ok {
+		return "", config.ErrInvalidStreamTicket
+	}
+	return userID, nil
+}
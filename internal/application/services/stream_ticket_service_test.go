@@ -0,0 +1,96 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mapTicketStore keeps tickets in a map and ignores their TTL.
+type mapTicketStore struct {
+	tickets map[string]string
+	ttl     time.Duration
+	err     error
+}
+
+func (s *mapTicketStore) Put(ticket, userID string, ttl time.Duration) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.tickets[ticket] = userID
+	s.ttl = ttl
+	return nil
+}
+
+func (s *mapTicketStore) Take(ticket string) (string, bool, error) {
+	if s.err != nil {
+		return "", false, s.err
+	}
+	userID, ok := s.tickets[ticket]
+	delete(s.tickets, ticket)
+	return userID, ok, nil
+}
+
+func Test_StreamTicketService(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC)
+	newService := func(store *mapTicketStore) *StreamTicketService {
+		svc := NewStreamTicketService(store)
+		svc.now = func() time.Time { return now }
+		return svc
+	}
+
+	t.Run("a ticket opens one stream", func(t *testing.T) {
+		t.Parallel()
+		store := &mapTicketStore{tickets: map[string]string{}}
+		svc := newService(store)
+
+		issued, err := svc.IssueTicket(dto.IssueStreamTicketDTO{UserID: "amy"})
+		require.NoError(t, err)
+		assert.Len(t, issued.Ticket, 64)
+		assert.Equal(t, now.Add(streamTicketTTL), issued.ExpiresAt)
+		assert.Equal(t, streamTicketTTL, store.ttl)
+
+		userID, err := svc.RedeemTicket(issued.Ticket)
+		require.NoError(t, err)
+		assert.Equal(t, "amy", userID)
+
+		_, err = svc.RedeemTicket(issued.Ticket)
+		assert.ErrorIs(t, err, config.ErrInvalidStreamTicket)
+	})
+
+	t.Run("tickets differ", func(t *testing.T) {
+		t.Parallel()
+		svc := newService(&mapTicketStore{tickets: map[string]string{}})
+		first, err := svc.IssueTicket(dto.IssueStreamTicketDTO{UserID: "amy"})
+		require.NoError(t, err)
+		second, err := svc.IssueTicket(dto.IssueStreamTicketDTO{UserID: "amy"})
+		require.NoError(t, err)
+		assert.NotEqual(t, first.Ticket, second.Ticket)
+	})
+
+	t.Run("unknown or empty ticket", func(t *testing.T) {
+		t.Parallel()
+		svc := newService(&mapTicketStore{tickets: map[string]string{}})
+		_, err := svc.RedeemTicket("nope")
+		assert.ErrorIs(t, err, config.ErrInvalidStreamTicket)
+		_, err = svc.RedeemTicket("")
+		assert.ErrorIs(t, err, config.ErrInvalidStreamTicket)
+	})
+
+	t.Run("store failure is not an invalid ticket", func(t *testing.T) {
+		t.Parallel()
+		svc := newService(&mapTicketStore{tickets: map[string]string{}, err: errors.New("redis down")})
+		_, err := svc.IssueTicket(dto.IssueStreamTicketDTO{UserID: "amy"})
+		assert.Error(t, err)
+		_, err = svc.RedeemTicket("abc")
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, config.ErrInvalidStreamTicket)
+	})
+}
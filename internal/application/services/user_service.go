@@ -7,15 +7,24 @@ import (
 	"github.com/daniel0321forever/terriyaki-go/internal/cores/utils"
 	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
 	"github.com/daniel0321forever/terriyaki-go/internal/domain/repositories"
+	"gorm.io/gorm"
 )
 
 type UserService struct {
-	userRepo repositories.UserRepository
+	db            *gorm.DB
+	userRepo      repositories.UserRepository
+	habitTaskRepo repositories.HabitTaskRepository
 }
 
-func NewUserService(userRepo repositories.UserRepository) *UserService {
+func NewUserService(
+	db *gorm.DB,
+	userRepo repositories.UserRepository,
+	habitTaskRepo repositories.HabitTaskRepository,
+) *UserService {
 	return &UserService{
-		userRepo: userRepo,
+		db:            db,
+		userRepo:      userRepo,
+		habitTaskRepo: habitTaskRepo,
 	}
 }
 
@@ -97,8 +106,40 @@ func (s *UserService) UpdateUser(request dto.UpdateUserDTO) (*dto.UserDTO, error
 	if request.DefaultPaymentMethodID != nil {
 		user.DefaultPaymentMethodID = *request.DefaultPaymentMethodID
 	}
+	if request.LeetCodeUsername != nil {
+		if err := entities.ValidateLeetCodeUsername(*request.LeetCodeUsername); err != nil {
+			return nil, config.ErrInvalidLeetCodeUsername
+		}
+		user.LeetCodeUsername = *request.LeetCodeUsername
+	}
+	if request.Locale != nil {
+		if err := entities.ValidateLocale(*request.Locale); err != nil {
+			return nil, config.ErrInvalidLocale
+		}
+		user.Locale = *request.Locale
+	}
+
+	previousTimezone := user.Location().String()
+	timezoneChanged := false
+	if request.Timezone != nil {
+		if err := entities.ValidateTimezone(*request.Timezone); err != nil {
+			return nil, config.ErrInvalidTimezone
+		}
+		user.Timezone = *request.Timezone
+		timezoneChanged = user.Timezone != previousTimezone
+	}
 
-	err = s.userRepo.Update(user)
+	// A timezone change moves every habit task to midnight of the same calendar day in the
+	// new zone, so today lookups and missed-day evaluation keep matching the user's days.
+	err = runInTransaction(s.db, func(tx *gorm.DB) error {
+		if err := getUserRepo(s.userRepo, tx).Update(user); err != nil {
+			return err
+		}
+		if !timezoneChanged {
+			return nil
+		}
+		return getHabitTaskRepo(s.habitTaskRepo, tx).RebaseDates(user.ID, previousTimezone, user.Timezone)
+	})
 	if err != nil {
 		return nil, err
 	}
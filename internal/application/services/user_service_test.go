@@ -17,7 +17,7 @@ func TestUserServiceCreateUser_DuplicateEmail(t *testing.T) {
 	po := new(mocks.MockUserRepository)
 	po.On("FindByEmail", "alice@example.com").Return(&entities.User{ID: "u1", Email: "alice@example.com"}, nil)
 
-	svc := NewUserService(po)
+	svc := NewUserService(nil, po, nil)
 
 	_, err := svc.CreateUser(dto.CreateUserDTO{
 		Username: "alice",
@@ -38,7 +38,7 @@ func TestUserServiceCreateUser_Success(t *testing.T) {
 		return u.Username == "alice" && u.Email == "alice@example.com"
 	})).Return(nil)
 
-	svc := NewUserService(po)
+	svc := NewUserService(nil, po, nil)
 
 	res, err := svc.CreateUser(dto.CreateUserDTO{
 		Username: "alice",
@@ -61,7 +61,7 @@ func TestUserServiceGetUser_NotFound(t *testing.T) {
 	po := new(mocks.MockUserRepository)
 	po.On("FindById", "missing").Return(nil, errors.New("db error"))
 
-	svc := NewUserService(po)
+	svc := NewUserService(nil, po, nil)
 
 	_, err := svc.GetUser(dto.GetUserDTO{UserID: "missing"})
 	if !errors.Is(err, config.ErrUserNotFound) {
@@ -79,7 +79,7 @@ func TestUserServiceUpdateUser_UpdatesSelectedFields(t *testing.T) {
 		return u.ID == "u1" && u.Username == "new"
 	})).Return(nil)
 
-	svc := NewUserService(po)
+	svc := NewUserService(nil, po, nil)
 
 	newName := "new"
 	newAvatar := "new.png"
@@ -100,3 +100,88 @@ func TestUserServiceUpdateUser_UpdatesSelectedFields(t *testing.T) {
 		t.Fatalf("expected default payment method to be updated")
 	}
 }
+
+func TestUserServiceUpdateUser_TimezoneChangeRebasesTasks(t *testing.T) {
+	t.Parallel()
+
+	stored := &entities.User{ID: "u1", Username: "alice", Timezone: "UTC"}
+	po := new(mocks.MockUserRepository)
+	po.On("FindById", "u1").Return(stored, nil)
+	po.On("Update", mock.MatchedBy(func(u *entities.User) bool {
+		return u.ID == "u1" && u.Timezone == "Asia/Taipei"
+	})).Return(nil)
+	taskRepo := new(mocks.MockHabitTaskRepository)
+	taskRepo.On("RebaseDates", "u1", "UTC", "Asia/Taipei").Return(nil)
+
+	svc := NewUserService(nil, po, taskRepo)
+
+	tz := "Asia/Taipei"
+	res, err := svc.UpdateUser(dto.UpdateUserDTO{UserID: "u1", Timezone: &tz})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if res.Timezone != tz {
+		t.Fatalf("expected timezone %q, got %q", tz, res.Timezone)
+	}
+	po.AssertExpectations(t)
+	taskRepo.AssertExpectations(t)
+}
+
+func TestUserServiceUpdateUser_SameTimezoneSkipsRebase(t *testing.T) {
+	t.Parallel()
+
+	stored := &entities.User{ID: "u1", Username: "alice", Timezone: "Asia/Taipei"}
+	po := new(mocks.MockUserRepository)
+	po.On("FindById", "u1").Return(stored, nil)
+	po.On("Update", mock.Anything).Return(nil)
+	taskRepo := new(mocks.MockHabitTaskRepository)
+
+	svc := NewUserService(nil, po, taskRepo)
+
+	tz := "Asia/Taipei"
+	if _, err := svc.UpdateUser(dto.UpdateUserDTO{UserID: "u1", Timezone: &tz}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	taskRepo.AssertNotCalled(t, "RebaseDates", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUserServiceUpdateUser_InvalidTimezone(t *testing.T) {
+	t.Parallel()
+
+	po := new(mocks.MockUserRepository)
+	po.On("FindById", "u1").Return(&entities.User{ID: "u1", Timezone: "UTC"}, nil)
+
+	svc := NewUserService(nil, po, nil)
+
+	tz := "Mars/Olympus_Mons"
+	_, err := svc.UpdateUser(dto.UpdateUserDTO{UserID: "u1", Timezone: &tz})
+	if !errors.Is(err, config.ErrInvalidTimezone) {
+		t.Fatalf("expected ErrInvalidTimezone, got %v", err)
+	}
+	po.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestUserServiceUpdateUser_Locale(t *testing.T) {
+	t.Parallel()
+
+	po := new(mocks.MockUserRepository)
+	po.On("FindById", "u1").Return(&entities.User{ID: "u1", Timezone: "UTC", Locale: "en"}, nil)
+	po.On("Update", mock.MatchedBy(func(u *entities.User) bool { return u.Locale == "ja" })).Return(nil).Once()
+
+	svc := NewUserService(nil, po, nil)
+
+	locale := "ja"
+	res, err := svc.UpdateUser(dto.UpdateUserDTO{UserID: "u1", Locale: &locale})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if res.Locale != locale {
+		t.Fatalf("expected locale %q, got %q", locale, res.Locale)
+	}
+
+	invalid := "not a locale"
+	if _, err := svc.UpdateUser(dto.UpdateUserDTO{UserID: "u1", Locale: &invalid}); !errors.Is(err, config.ErrInvalidLocale) {
+		t.Fatalf("expected ErrInvalidLocale, got %v", err)
+	}
+	po.AssertNumberOfCalls(t, "Update", 1)
+}
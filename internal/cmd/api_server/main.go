@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"os"
 
+	"github.com/daniel0321forever/terriyaki-go/internal/application/services"
 	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
 	"github.com/daniel0321forever/terriyaki-go/internal/cores/container"
 	"github.com/daniel0321forever/terriyaki-go/internal/infrastructure/db/postgres"
@@ -37,6 +39,20 @@ func main() {
 		panic(err)
 	}
 
+	// Close out each local day's missed tasks in the background; backfills go through internal/cmd/evaluate_day.
+	missedDayService := services.NewMissedDayService(
+		db,
+		container.Repos.GrindRepository,
+		container.Repos.UserRepository,
+		container.Repos.HabitTaskRepository,
+		container.Repos.ParticipationRepository,
+	)
+	go missedDayService.Run(context.Background())
+
+	// Close recruiting grinds once their first day has begun; ending them is up to the settlement.
+	grindLifecycleService := services.NewGrindLifecycleService(container.Repos.GrindRepository)
+	go grindLifecycleService.Run(context.Background())
+
 	// Initialize Redis client. Credentials come from environment (T-03-08: never hardcode).
 	// Do NOT close rdb in a defer — the connection pool lives for the full process lifetime.
 	rdb := redis.NewClient(&redis.Options{
@@ -0,0 +1,56 @@
+// Package main is a one-shot command that runs the missed-day evaluator for a single day.
+// It is used to backfill days the API server's evaluation loop did not reach (e.g. downtime
+// spanning several days). The day is a calendar date; each task is judged against its
+// owner's local day. Re-running it for a day that was already evaluated is a no-op.
+//
+// Usage:
+//
+//	go run ./internal/cmd/evaluate_day -day 2026-04-10
+//
+// Without -day it evaluates yesterday (UTC).
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
+	"github.com/daniel0321forever/terriyaki-go/internal/application/services"
+	"github.com/daniel0321forever/terriyaki-go/internal/infrastructure/db/postgres"
+)
+
+func main() {
+	yesterday := time.Now().UTC().AddDate(0, 0, -1).Format("2006-01-02")
+	dayFlag := flag.String("day", yesterday, "calendar day to evaluate, as YYYY-MM-DD")
+	flag.Parse()
+
+	day, err := time.Parse("2006-01-02", *dayFlag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "invalid -day:", err)
+		os.Exit(2)
+	}
+
+	db, err := postgres.Connect()
+	if err != nil {
+		panic(err)
+	}
+
+	missedDayService := services.NewMissedDayService(
+		db,
+		postgres.NewGormGrindRepository(db),
+		postgres.NewGormUserRepository(db),
+		postgres.NewGormHabitTaskRepository(db),
+		postgres.NewGormParticipationRepository(db),
+	)
+
+	result, err := missedDayService.EvaluateDay(dto.EvaluateDayDTO{Day: day})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "evaluation failed:", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("evaluated %s: %d grinds, %d tasks marked missed, %d penalty accrued\n",
+		result.Day.Format("2006-01-02"), result.GrindsEvaluated, result.TasksMarkedMissed, result.PenaltyAccrued)
+}
@@ -13,7 +13,8 @@
 //
 // The binary reads the same environment variables as the API server:
 // POSTGRES_DSN (or equivalent consumed by postgres.Connect), REDIS_ADDR,
-// REDIS_PASSWORD.
+// REDIS_PASSWORD, and LEETCODE_GRAPHQL_URL to point LeetCode verification at
+// another endpoint.
 package main
 
 import (
@@ -22,6 +23,9 @@ import (
 	"github.com/daniel0321forever/terriyaki-go/internal/application/services"
 	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
 	"github.com/daniel0321forever/terriyaki-go/internal/infrastructure/db/postgres"
+	"github.com/daniel0321forever/terriyaki-go/internal/infrastructure/leetcode"
+	"github.com/daniel0321forever/terriyaki-go/internal/infrastructure/notification"
+	"github.com/daniel0321forever/terriyaki-go/internal/infrastructure/verifier"
 	mcpmux "github.com/daniel0321forever/terriyaki-go/internal/interface/mcp"
 	mcpserver "github.com/mark3labs/mcp-go/server"
 	"github.com/redis/go-redis/v9"
@@ -42,18 +46,41 @@ func main() {
 		DB:       0,
 		Protocol: 2,
 	})
-	_ = rdb // retained for future MCP tools that need Redis; not used by current handlers
 
 	// Construct only the repositories required by the two MCP tools.
+	userRepo := postgres.NewGormUserRepository(db)
 	habitTaskRepo := postgres.NewGormHabitTaskRepository(db)
-	completionEventRepo := postgres.NewGormCompletionEventRepository(db)
+	integrationRepo := postgres.NewGormIntegrationRepository(db)
+	deadLetterRepo := postgres.NewGormDeadLetterRepository(db)
+	partnerGroupRepo := postgres.NewGormPartnerGroupRepository(db)
+	grindRepo := postgres.NewGormGrindRepository(db)
+	participationRepo := postgres.NewGormParticipationRepository(db)
+	gormMessageRepo := postgres.NewGormMessageRepository(db)
+	disputeRepo := postgres.NewGormCompletionDisputeRepository(db)
 
-	// Build the application service.
-	ingestService := services.NewIngestService(habitTaskRepo, completionEventRepo)
+	// Completions ingested here reach the partners' notification streams through Redis,
+	// like those ingested by the API.
+	notificationService := services.NewNotificationService(notification.New(rdb), userRepo, grindRepo, gormMessageRepo,
+		habitTaskRepo, participationRepo, partnerGroupRepo)
+	completionEventRepo := notificationService.CompletionEventRepository(postgres.NewGormCompletionEventRepository(db))
+	messageRepo := notificationService.MessageRepository(gormMessageRepo)
+
+	// The MCP tools take no evidence files, so the verifier judges metadata only.
+	completionVerifier, err := verifier.FromEnv(nil)
+	if err != nil {
+		panic(err)
+	}
+
+	// Build the application services.
+	disputeService := services.NewDisputeService(db, grindRepo, userRepo, habitTaskRepo, participationRepo, partnerGroupRepo,
+		completionEventRepo, disputeRepo, messageRepo)
+	ingestService := services.NewIngestService(db, userRepo, habitTaskRepo, completionEventRepo, deadLetterRepo,
+		leetcode.NewGraphQLClient(os.Getenv(config.LEETCODE_GRAPHQL_URL), nil), completionVerifier, disputeService)
+	integrationService := services.NewIntegrationService(integrationRepo, partnerGroupRepo)
 
 	// Register tools with the MCP server.
 	s := mcpserver.NewMCPServer("habitat-mcp", "0.1.0")
-	s.AddTool(mcpmux.IngestCompletionEventTool, mcpmux.HandleIngestCompletionEvent(ingestService))
+	s.AddTool(mcpmux.IngestCompletionEventTool, mcpmux.HandleIngestCompletionEvent(ingestService, integrationService))
 	s.AddTool(mcpmux.VerifyHabitTaskTool, mcpmux.HandleVerifyHabitTask(habitTaskRepo, completionEventRepo))
 
 	// Serve on stdio transport — blocks until the process exits.
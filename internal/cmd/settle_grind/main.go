@@ -0,0 +1,89 @@
+// Package main is a one-shot command that runs or resumes the settlement of one grind.
+// It is used for grinds the force-charging endpoint no longer lists, e.g. when a charge
+// or payout was still failing after the grind left the due window. Settled legs are
+// never repeated, so it is safe to run as often as needed.
+//
+// Usage:
+//
+//	go run ./internal/cmd/settle_grind -grind <grind id>
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
+	"github.com/daniel0321forever/terriyaki-go/internal/application/services"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/daniel0321forever/terriyaki-go/internal/infrastructure/db/postgres"
+	"github.com/daniel0321forever/terriyaki-go/internal/infrastructure/notification"
+	"github.com/redis/go-redis/v9"
+)
+
+func main() {
+	grindFlag := flag.String("grind", "", "ID of the grind to settle")
+	flag.Parse()
+
+	if *grindFlag == "" {
+		fmt.Fprintln(os.Stderr, "missing -grind")
+		os.Exit(2)
+	}
+
+	db, err := postgres.Connect()
+	if err != nil {
+		panic(err)
+	}
+
+	// Settlement updates reach the users' notification streams through the API's Redis.
+	rdb := redis.NewClient(&redis.Options{
+		Addr:     os.Getenv(config.REDIS_ADDR),
+		Password: os.Getenv(config.REDIS_PASSWORD),
+		DB:       0,
+		Protocol: 2,
+	})
+
+	userRepo := postgres.NewGormUserRepository(db)
+	grindRepo := postgres.NewGormGrindRepository(db)
+	participationRepo := postgres.NewGormParticipationRepository(db)
+	paymentInfoRepo := postgres.NewGormStripePaymentInfoRepository(db)
+	habitTaskRepo := postgres.NewGormHabitTaskRepository(db)
+	messageRepo := postgres.NewGormMessageRepository(db)
+	notificationService := services.NewNotificationService(notification.New(rdb), userRepo, grindRepo, messageRepo,
+		habitTaskRepo, participationRepo, postgres.NewGormPartnerGroupRepository(db))
+	settlementRepo := notificationService.PaymentSettlementRepository(postgres.NewGormPaymentSettlementRepository(db))
+
+	stripePaymentService, err := services.NewPaymentServiceFactory(
+		userRepo,
+		grindRepo,
+		participationRepo,
+		paymentInfoRepo,
+		postgres.NewGormPaymentIdempotencyRepository(db),
+		settlementRepo,
+	).BuildForProvider(entities.PaymentProviderStripe)
+	if err != nil {
+		panic(err)
+	}
+
+	settlementService := services.NewGrindSettlementService(
+		db,
+		grindRepo,
+		userRepo,
+		participationRepo,
+		habitTaskRepo,
+		paymentInfoRepo,
+		settlementRepo,
+		stripePaymentService,
+	)
+
+	report, err := settlementService.SettleGrind(dto.SettleGrindDTO{GrindID: *grindFlag})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "settlement failed:", err)
+		os.Exit(1)
+	}
+
+	encoded, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Println(string(encoded))
+}
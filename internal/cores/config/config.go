@@ -1,3 +1,53 @@
 package config
 
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
 var RepoType = "postgres"
+
+// DefaultIngestGraceWindow is used when INGEST_GRACE_WINDOW is unset or invalid.
+const DefaultIngestGraceWindow = 2 * time.Hour
+
+// IngestGraceWindow is how long after local midnight a completion of the day that just
+// ended is still accepted, e.g. from an offline device or a delayed webhook. Missed-day
+// evaluation waits for it so such a completion still counts. Read from INGEST_GRACE_WINDOW
+// as a Go duration such as "90m".
+func IngestGraceWindow() time.Duration {
+	window, err := time.ParseDuration(os.Getenv(INGEST_GRACE_WINDOW))
+	if err != nil || window < 0 {
+		return DefaultIngestGraceWindow
+	}
+	return window
+}
+
+// DefaultCompletionReviewWindow is used when COMPLETION_REVIEW_WINDOW is unset or invalid.
+const DefaultCompletionReviewWindow = 48 * time.Hour
+
+// CompletionReviewWindow is how long after a completion occurred its grind's partners may
+// dispute it, and how long they then have to vote. Read from COMPLETION_REVIEW_WINDOW as a
+// Go duration such as "24h".
+func CompletionReviewWindow() time.Duration {
+	window, err := time.ParseDuration(os.Getenv(COMPLETION_REVIEW_WINDOW))
+	if err != nil || window <= 0 {
+		return DefaultCompletionReviewWindow
+	}
+	return window
+}
+
+// DefaultCompletionVerifierMinConfidence is used when COMPLETION_VERIFIER_MIN_CONFIDENCE is
+// unset or invalid.
+const DefaultCompletionVerifierMinConfidence = 0.6
+
+// CompletionVerifierMinConfidence is how sure the completion verifier must be that a
+// completion is real for it to skip peer review. Read from COMPLETION_VERIFIER_MIN_CONFIDENCE
+// as a number between 0 and 1.
+func CompletionVerifierMinConfidence() float64 {
+	confidence, err := strconv.ParseFloat(os.Getenv(COMPLETION_VERIFIER_MIN_CONFIDENCE), 64)
+	if err != nil || confidence < 0 || confidence > 1 {
+		return DefaultCompletionVerifierMinConfidence
+	}
+	return confidence
+}
@@ -22,6 +22,17 @@ var (
 	ERROR_CODE_INVITATION_MESSAGE_NOT_FOUND string = "INVITATION_MESSAGE_NOT_FOUND"
 	ERROR_CODE_PARTICIPANT_EXISTS           string = "PARTICIPANT_EXISTS"
 	ERROR_CODE_SAME_RECIPIENT_AND_SENDER    string = "SAME_RECIPIENT_AND_SENDER"
+	ERROR_CODE_INVALID_GRIND_STATE          string = "INVALID_GRIND_STATE"
+	ERROR_CODE_LEETCODE_UNAVAILABLE         string = "LEETCODE_UNAVAILABLE"
+	ERROR_CODE_INGEST_WINDOW_CLOSED         string = "INGEST_WINDOW_CLOSED"
+	ERROR_CODE_EVIDENCE_TOO_LARGE           string = "EVIDENCE_TOO_LARGE"
+	ERROR_CODE_UNSUPPORTED_EVIDENCE_TYPE    string = "UNSUPPORTED_EVIDENCE_TYPE"
+	ERROR_CODE_INVALID_PAYLOAD              string = "INVALID_PAYLOAD"
+	ERROR_CODE_MESSAGING_BLOCKED            string = "MESSAGING_BLOCKED"
+	ERROR_CODE_INVITATION_PENDING           string = "INVITATION_PENDING"
+	ERROR_CODE_INVITATION_ANSWERED          string = "INVITATION_ANSWERED"
+	ERROR_CODE_INVITATION_EXPIRED           string = "INVITATION_EXPIRED"
+	ERROR_CODE_INVITATION_REVOKED           string = "INVITATION_REVOKED"
 )
 
 // Service-level Sentinel Errors (used for business logic error handling)
@@ -36,12 +47,25 @@ var (
 	ErrParticipationNotFound      = errors.New("participation not found")
 	ErrUserIsNotParticipant       = errors.New("user is not a participant of the grind")
 	ErrParticipationUpdateFailed  = errors.New("participation update failed")
+	ErrInvalidPenaltyPolicy       = errors.New("invalid penalty policy")
+	ErrInvalidGrindState          = errors.New("invalid grind state")
+	ErrGrindStateChanged          = errors.New("grind state was changed concurrently")
+	ErrGrindAlreadyStarted        = errors.New("grind has already started")
+	ErrInvalidGrindUpdate         = errors.New("invalid grind update")
+	ErrInvalidSchedule            = errors.New("invalid grind schedule")
+	ErrInvalidRoadmap             = errors.New("invalid grind roadmap")
+	ErrInvalidLeaderboardSort     = errors.New("invalid leaderboard sort")
+	ErrStakeChangeNotFound        = errors.New("stake change not found")
+	ErrStakeChangeNotPending      = errors.New("stake change is no longer pending")
 )
 
 // User service errors
 var (
-	ErrUserNotFound      = errors.New("user not found")
-	ErrUserAlreadyExists = errors.New("user with this email already exists")
+	ErrUserNotFound            = errors.New("user not found")
+	ErrUserAlreadyExists       = errors.New("user with this email already exists")
+	ErrInvalidTimezone         = errors.New("invalid timezone")
+	ErrInvalidLeetCodeUsername = errors.New("invalid LeetCode username")
+	ErrInvalidLocale           = errors.New("invalid locale")
 )
 
 // Task service errors
@@ -51,7 +75,26 @@ var (
 
 // HabitTask service errors
 var (
-	ErrHabitTaskNotFound = errors.New("habit task not found")
+	ErrHabitTaskNotFound          = errors.New("habit task not found")
+	ErrSubmissionDoesNotMatchTask = errors.New("submission does not match the assigned problem")
+	ErrLeetCodeUsernameMissing    = errors.New("user has not linked a LeetCode username")
+	ErrSubmissionNotVerified      = errors.New("no matching accepted submission on LeetCode")
+	ErrLeetCodeUnavailable        = errors.New("LeetCode could not be reached")
+	ErrIngestWindowClosed         = errors.New("completion is outside the ingest window")
+	ErrInvalidPayload             = errors.New("payload does not match the provider's schema")
+)
+
+// Missed-day evaluator errors
+var (
+	ErrDayNotClosed = errors.New("day has not closed yet")
+)
+
+// Grind settlement errors
+var (
+	ErrGrindNotEnded          = errors.New("grind has not ended for every participant")
+	ErrSettlementNotReady     = errors.New("grind still has tasks waiting for the missed-day evaluation")
+	ErrParticipationFinalized = errors.New("participation is finalized")
+	ErrNoPaymentMethod        = errors.New("no payment method on file")
 )
 
 // Partner group service errors
@@ -59,6 +102,72 @@ var (
 	ErrForbidden = errors.New("forbidden")
 )
 
+// Integration service errors
+var (
+	ErrIntegrationNotFound  = errors.New("integration not found")
+	ErrInvalidIntegration   = errors.New("invalid integration")
+	ErrInvalidSignature     = errors.New("invalid signature")
+	ErrIntegrationRequired  = errors.New("custom completions must be sent through an integration")
+	ErrInvalidCustomPayload = errors.New("payload does not match the integration's field mapping")
+)
+
+// Evidence service errors
+var (
+	ErrCompletionEventNotFound = errors.New("completion event not found")
+	ErrEvidenceNotFound        = errors.New("completion event has no evidence")
+	ErrInvalidEvidence         = errors.New("invalid evidence")
+	ErrEvidenceTooLarge        = errors.New("evidence file is too large")
+	ErrUnsupportedEvidenceType = errors.New("unsupported evidence content type")
+)
+
+// Dispute service errors
+var (
+	ErrDisputeNotFound    = errors.New("dispute not found")
+	ErrDisputeExists      = errors.New("completion event has already been disputed")
+	ErrDisputeClosed      = errors.New("dispute is closed")
+	ErrAlreadyVoted       = errors.New("user has already voted on the dispute")
+	ErrInvalidDispute     = errors.New("invalid dispute")
+	ErrReviewWindowClosed = errors.New("completion is outside the review window")
+)
+
+// Message service errors
+var (
+	ErrSameRecipientAndSender     = errors.New("sender and recipient are the same user")
+	ErrInvalidMessage             = errors.New("invalid message")
+	ErrMessagingBlocked           = errors.New("messaging between these users is blocked")
+	ErrInvalidConversationSetting = errors.New("invalid conversation setting")
+	ErrInvalidMessageFilter       = errors.New("invalid message filter")
+)
+
+// Invitation errors
+var (
+	ErrInvitationNotFound    = errors.New("invitation not found")
+	ErrNotInvitationSender   = errors.New("user did not send the invitation")
+	ErrNotInvitationReceiver = errors.New("user did not receive the invitation")
+	ErrInvitationPending     = errors.New("receiver already has a pending invitation to the grind")
+	ErrInvitationAnswered    = errors.New("invitation has already been answered")
+	ErrInvitationExpired     = errors.New("invitation has expired")
+	ErrInvitationRevoked     = errors.New("invitation has been revoked")
+)
+
+// Pagination errors
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// Notification service errors
+var (
+	ErrInvalidLastEventID  = errors.New("invalid Last-Event-ID")
+	ErrInvalidStreamTicket = errors.New("invalid or expired stream ticket")
+)
+
+// Dead letter service errors
+var (
+	ErrDeadLetterNotFound   = errors.New("dead letter not found")
+	ErrInvalidRedrive       = errors.New("invalid re-drive")
+	ErrDeadLetterUnreadable = errors.New("dead letter payload is not a JSON object")
+)
+
 // Helper function for dynamic errors
 func ErrParticipationAlreadyExists(userID, grindID string) error {
 	return fmt.Errorf("already exists participation record for %s and %s", userID, grindID)
@@ -8,6 +8,8 @@ var (
 	MESSAGE_TYPE_INVITATION          string = "invitation"
 	MESSAGE_TYPE_INVITATION_ACCEPTED string = "invitation_accepted"
 	MESSAGE_TYPE_INVITATION_REJECTED string = "invitation_rejected"
+	MESSAGE_TYPE_STAKE_CHANGE        string = "stake_change"
+	MESSAGE_TYPE_COMPLETION_DISPUTE  string = "completion_dispute"
 
 	REDIS_PAYMENT_INFOS_KEY string = "redis:paymentInfos:"
 
@@ -19,4 +21,15 @@ var (
 
 	REDIS_ADDR     string = "REDIS_ADDR"
 	REDIS_PASSWORD string = "REDIS_PASSWORD"
+
+	LEETCODE_GRAPHQL_URL string = "LEETCODE_GRAPHQL_URL"
+	INGEST_GRACE_WINDOW  string = "INGEST_GRACE_WINDOW"
+	EVIDENCE_DIR         string = "EVIDENCE_DIR"
+
+	COMPLETION_REVIEW_WINDOW string = "COMPLETION_REVIEW_WINDOW"
+
+	GEMINI_API_KEY                     string = "GEMINI_API_KEY"
+	COMPLETION_VERIFIER                string = "COMPLETION_VERIFIER"
+	COMPLETION_VERIFIER_MODEL          string = "COMPLETION_VERIFIER_MODEL"
+	COMPLETION_VERIFIER_MIN_CONFIDENCE string = "COMPLETION_VERIFIER_MIN_CONFIDENCE"
 )
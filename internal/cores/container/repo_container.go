@@ -19,6 +19,7 @@ type ReposContainer struct {
 	PaymentIdempotencyRepository repositories.PaymentIdempotencyRepository
 	PaymentSettlementRepository  repositories.PaymentSettlementRepository
 	ParticipationRepository      repositories.ParticipationRepository
+	HabitTaskRepository          repositories.HabitTaskRepository
 }
 
 func InitializeReposContainer(db *gorm.DB) error {
@@ -31,6 +32,7 @@ func InitializeReposContainer(db *gorm.DB) error {
 			PaymentIdempotencyRepository: postgres.NewGormPaymentIdempotencyRepository(db),
 			PaymentSettlementRepository:  postgres.NewGormPaymentSettlementRepository(db),
 			ParticipationRepository:      postgres.NewGormParticipationRepository(db),
+			HabitTaskRepository:          postgres.NewGormHabitTaskRepository(db),
 		}
 		return nil
 	}
@@ -0,0 +1,187 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+/** Lifecycle of a dispute over a completion */
+type CompletionDisputeStatus string
+
+const (
+	DisputeOpen     CompletionDisputeStatus = "open"     // waiting for the reviewers' votes
+	DisputeUpheld   CompletionDisputeStatus = "upheld"   // the completion stands
+	DisputeReverted CompletionDisputeStatus = "reverted" // the task was reverted to missed
+)
+
+/** A partner's challenge of a CompletionEvent they believe was not earned.
+ * The reviewers are the grind's active participants and the members of its partner
+ * group, except the subject whose completion is disputed. Quorum is a majority of them,
+ * fixed when the dispute is opened; the flagger's vote counts towards reverting. The first
+ * side to reach the quorum decides. A dispute still open when its voting window ends is
+ * upheld: without a majority against it, a completion stands.
+ * A dispute the completion verifier opened for review has no flagger and starts without
+ * votes.
+ */
+type CompletionDispute struct {
+	ID                string
+	CompletionEventID string
+	HabitTaskID       string
+	GrindID           string
+	SubjectID         string // the user whose completion is disputed
+	FlaggedBy         string // empty when the completion verifier opened the dispute
+	Reason            string
+	Quorum            int
+	RevertVotes       []string // user IDs, starting with the flagger's
+	UpholdVotes       []string
+	Status            CompletionDisputeStatus
+	ExpiresAt         time.Time // votes are no longer taken after this
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+/** Constructor in factory pattern
+ * @param event - the disputed completion
+ * @param grindID - the grind of the event's task
+ * @param flaggedBy - the reviewer opening the dispute
+ * @param reason - why the completion is disputed
+ * @param reviewers - the number of users who may vote, the flagger included
+ * @param votingWindow - how long the reviewers have to vote
+ */
+func NewCompletionDispute(
+	event *CompletionEvent,
+	grindID, flaggedBy, reason string,
+	reviewers int,
+	votingWindow time.Duration,
+) (*CompletionDispute, error) {
+	if event == nil {
+		return nil, errors.New("event cannot be nil")
+	}
+	if flaggedBy == "" {
+		return nil, errors.New("flaggedBy cannot be empty")
+	}
+	if flaggedBy == event.UserID {
+		return nil, errors.New("a completion cannot be disputed by its own user")
+	}
+	if reviewers < 1 {
+		return nil, errors.New("a dispute needs at least one reviewer")
+	}
+
+	now := time.Now().UTC()
+	return &CompletionDispute{
+		ID:                uuid.New().String(),
+		CompletionEventID: event.ID,
+		HabitTaskID:       event.HabitTaskID,
+		GrindID:           grindID,
+		SubjectID:         event.UserID,
+		FlaggedBy:         flaggedBy,
+		Reason:            reason,
+		Quorum:            reviewers/2 + 1,
+		RevertVotes:       []string{flaggedBy},
+		UpholdVotes:       []string{},
+		Status:            DisputeOpen,
+		ExpiresAt:         now.Add(votingWindow),
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}, nil
+}
+
+/** Constructor for a review the completion verifier asks for when it doubts a completion
+ * @param event - the completion under review
+ * @param grindID - the grind of the event's task
+ * @param reason - why the verifier doubts the completion
+ * @param reviewers - the number of users who may vote
+ * @param votingWindow - how long the reviewers have to vote
+ */
+func NewReviewDispute(
+	event *CompletionEvent,
+	grindID, reason string,
+	reviewers int,
+	votingWindow time.Duration,
+) (*CompletionDispute, error) {
+	if event == nil {
+		return nil, errors.New("event cannot be nil")
+	}
+	if reviewers < 1 {
+		return nil, errors.New("a dispute needs at least one reviewer")
+	}
+
+	now := time.Now().UTC()
+	return &CompletionDispute{
+		ID:                uuid.New().String(),
+		CompletionEventID: event.ID,
+		HabitTaskID:       event.HabitTaskID,
+		GrindID:           grindID,
+		SubjectID:         event.UserID,
+		Reason:            reason,
+		Quorum:            reviewers/2 + 1,
+		RevertVotes:       []string{},
+		UpholdVotes:       []string{},
+		Status:            DisputeOpen,
+		ExpiresAt:         now.Add(votingWindow),
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}, nil
+}
+
+/** Reports whether the completion verifier, rather than a partner, opened the dispute */
+func (d *CompletionDispute) OpenedByVerifier() bool {
+	return d.FlaggedBy == ""
+}
+
+/** Records userID's vote to uphold or revert the completion. Each reviewer votes once */
+func (d *CompletionDispute) Vote(userID string, uphold bool, at time.Time) error {
+	if d.Status != DisputeOpen || !at.Before(d.ExpiresAt) {
+		return errors.New("dispute is closed")
+	}
+	if d.HasVoted(userID) {
+		return errors.New("user has already voted")
+	}
+	if uphold {
+		d.UpholdVotes = append(d.UpholdVotes, userID)
+	} else {
+		d.RevertVotes = append(d.RevertVotes, userID)
+	}
+	d.UpdatedAt = at.UTC()
+	return nil
+}
+
+/** Reports whether userID has voted on the dispute */
+func (d *CompletionDispute) HasVoted(userID string) bool {
+	for _, id := range d.RevertVotes {
+		if id == userID {
+			return true
+		}
+	}
+	for _, id := range d.UpholdVotes {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+/** Returns the outcome the votes decide at, or DisputeOpen while neither side has a quorum
+ * and the voting window is still running
+ */
+func (d *CompletionDispute) Outcome(at time.Time) CompletionDisputeStatus {
+	switch {
+	case d.Status != DisputeOpen:
+		return d.Status
+	case len(d.RevertVotes) >= d.Quorum:
+		return DisputeReverted
+	case len(d.UpholdVotes) >= d.Quorum, !at.Before(d.ExpiresAt):
+		return DisputeUpheld
+	}
+	return DisputeOpen
+}
+
+/** Closes an open dispute with the given outcome */
+func (d *CompletionDispute) Resolve(status CompletionDisputeStatus, at time.Time) {
+	if d.Status == DisputeOpen && status != DisputeOpen {
+		d.Status = status
+		d.UpdatedAt = at.UTC()
+	}
+}
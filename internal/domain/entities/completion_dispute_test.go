@@ -0,0 +1,73 @@
+package entities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDispute(t *testing.T, reviewers int) *CompletionDispute {
+	t.Helper()
+	event := &CompletionEvent{ID: "event-1", HabitTaskID: "task-1", UserID: "user-1"}
+	dispute, err := NewCompletionDispute(event, "grind-1", "user-2", "no proof", reviewers, 48*time.Hour)
+	require.NoError(t, err)
+	return dispute
+}
+
+func Test_NewCompletionDispute_FlaggerVotesToRevert(t *testing.T) {
+	dispute := newTestDispute(t, 4)
+
+	assert.NotEmpty(t, dispute.ID)
+	assert.Equal(t, "user-1", dispute.SubjectID)
+	assert.Equal(t, DisputeOpen, dispute.Status)
+	assert.Equal(t, 3, dispute.Quorum)
+	assert.Equal(t, []string{"user-2"}, dispute.RevertVotes)
+	assert.True(t, dispute.HasVoted("user-2"))
+}
+
+func Test_NewCompletionDispute_OwnCompletion(t *testing.T) {
+	event := &CompletionEvent{ID: "event-1", HabitTaskID: "task-1", UserID: "user-1"}
+	dispute, err := NewCompletionDispute(event, "grind-1", "user-1", "", 2, time.Hour)
+	require.Error(t, err)
+	assert.Nil(t, dispute)
+}
+
+func Test_CompletionDispute_Outcome(t *testing.T) {
+	dispute := newTestDispute(t, 3)
+	at := dispute.CreatedAt.Add(time.Hour)
+
+	assert.Equal(t, DisputeOpen, dispute.Outcome(at))
+	require.NoError(t, dispute.Vote("user-3", true, at))
+	assert.Error(t, dispute.Vote("user-3", false, at), "each reviewer votes once")
+	assert.Equal(t, DisputeOpen, dispute.Outcome(at))
+
+	require.NoError(t, dispute.Vote("user-4", false, at))
+	assert.Equal(t, DisputeReverted, dispute.Outcome(at))
+
+	dispute.Resolve(DisputeReverted, at)
+	assert.Equal(t, DisputeReverted, dispute.Status)
+	assert.Error(t, dispute.Vote("user-5", true, at), "a resolved dispute takes no votes")
+	dispute.Resolve(DisputeUpheld, at)
+	assert.Equal(t, DisputeReverted, dispute.Status, "Resolve only affects open disputes")
+}
+
+func Test_CompletionDispute_UpheldWhenVotingWindowEnds(t *testing.T) {
+	dispute := newTestDispute(t, 4)
+
+	assert.Equal(t, DisputeOpen, dispute.Outcome(dispute.ExpiresAt.Add(-time.Second)))
+	assert.Equal(t, DisputeUpheld, dispute.Outcome(dispute.ExpiresAt))
+	assert.Error(t, dispute.Vote("user-3", false, dispute.ExpiresAt))
+}
+
+func Test_NewReviewDispute_StartsWithoutVotes(t *testing.T) {
+	event := &CompletionEvent{ID: "event-1", HabitTaskID: "task-1", UserID: "user-1"}
+	dispute, err := NewReviewDispute(event, "grind-1", "the screenshot shows another app", 3, 48*time.Hour)
+	require.NoError(t, err)
+
+	assert.True(t, dispute.OpenedByVerifier())
+	assert.Equal(t, 2, dispute.Quorum)
+	assert.Empty(t, dispute.RevertVotes)
+	assert.Equal(t, DisputeOpen, dispute.Outcome(dispute.CreatedAt))
+}
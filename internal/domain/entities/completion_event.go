@@ -25,13 +25,35 @@ var validProviders = map[string]CompletionProvider{
 
 // CompletionEvent records a single completion of a HabitTask by a user.
 // Raw provider payloads are stored in Metadata as JSONB (per D-01, D-02).
+// GrindID is the grind of the task.
+// DedupKey identifies the signal at its source: the provider's event ID when it sends
+// one, otherwise a hash of the payload. A user has at most one event per grind, provider
+// and key, so a retried delivery is recorded once while the same signal can still count in
+// each of the user's grinds.
+// LateBy is how long after the end of its day, in the user's timezone, the event was
+// ingested; zero for events that arrived on their day.
+// Evidence is the file the user attached as proof, or nil.
 type CompletionEvent struct {
 	ID          string
 	HabitTaskID string
+	GrindID     string
 	UserID      string
 	Provider    CompletionProvider
 	OccurredAt  time.Time
 	Metadata    datatypes.JSON
+	DedupKey    string
+	LateBy      time.Duration
+	Evidence    *EvidenceAttachment
+}
+
+// EvidenceAttachment describes a file attached to a CompletionEvent. The content lives in
+// a blob store under Key; SHA256 is the hex digest of that content.
+type EvidenceAttachment struct {
+	Key         string
+	FileName    string
+	ContentType string
+	Size        int64
+	SHA256      string
 }
 
 // NewCompletionEvent creates a validated CompletionEvent. provider must be one of
@@ -28,3 +28,19 @@ func Test_NewCompletionEvent_UnknownProvider(t *testing.T) {
 	assert.Nil(t, event)
 	assert.Equal(t, "unsupported provider: unknown", err.Error())
 }
+
+func Test_CompletionEvent_RecordVerdict(t *testing.T) {
+	event, err := NewCompletionEvent("habit-1", "user-1", "duolingo", time.Now(), []byte(`{"lesson":"Spanish 1","verification":"spoofed"}`))
+	require.NoError(t, err)
+
+	verdict := &CompletionVerdict{Plausible: true, Confidence: 0.4, Reason: "no lesson name in the screenshot", Verifier: "fake"}
+	require.NoError(t, event.RecordVerdict(verdict))
+
+	assert.Equal(t, verdict, event.Verdict())
+	assert.Contains(t, string(event.Metadata), `"lesson":"Spanish 1"`)
+	assert.True(t, verdict.NeedsReview(0.6))
+	assert.False(t, verdict.NeedsReview(0.4))
+
+	event.Metadata = []byte(`[1,2]`)
+	assert.Error(t, event.RecordVerdict(verdict))
+}
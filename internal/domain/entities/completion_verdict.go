@@ -0,0 +1,73 @@
+package entities
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// VerdictMetadataKey is the key of a CompletionEvent's Metadata under which the verdict
+// of the completion verifier is recorded.
+const VerdictMetadataKey = "verification"
+
+// CompletionVerification is what a completion verifier judges: a plain-language
+// description of the habit the completion claims to satisfy, and what was sent as proof.
+type CompletionVerification struct {
+	UserID     string
+	Provider   CompletionProvider
+	Habit      string
+	OccurredAt time.Time
+	Metadata   datatypes.JSON
+	Evidence   *EvidenceAttachment // nil when only metadata was sent
+}
+
+// CompletionVerdict is a verifier's judgement of a completion. Confidence runs from 0 to
+// 1 and says how sure the verifier is that the completion is Plausible, or not.
+// Verifier names the implementation that judged, e.g. the model.
+type CompletionVerdict struct {
+	Plausible  bool    `json:"plausible"`
+	Confidence float64 `json:"confidence"`
+	Reason     string  `json:"reason,omitempty"`
+	Verifier   string  `json:"verifier"`
+}
+
+// NeedsReview reports whether a completion with this verdict should go to peer review:
+// the verifier doubts it, or is less than minConfidence sure it is real.
+func (v *CompletionVerdict) NeedsReview(minConfidence float64) bool {
+	return !v.Plausible || v.Confidence < minConfidence
+}
+
+// RecordVerdict stores verdict in the event's Metadata under VerdictMetadataKey, replacing
+// a field of that name the payload may have had. Metadata that is not a JSON object is
+// left alone and an error returned.
+func (e *CompletionEvent) RecordVerdict(verdict *CompletionVerdict) error {
+	if verdict == nil {
+		return nil
+	}
+	metadata := map[string]interface{}{}
+	if len(e.Metadata) > 0 {
+		if err := json.Unmarshal(e.Metadata, &metadata); err != nil || metadata == nil {
+			return errors.New("metadata is not a JSON object")
+		}
+	}
+	metadata[VerdictMetadataKey] = verdict
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	e.Metadata = datatypes.JSON(encoded)
+	return nil
+}
+
+// Verdict returns the verdict recorded in the event's Metadata, or nil when it has none.
+func (e *CompletionEvent) Verdict() *CompletionVerdict {
+	var metadata struct {
+		Verdict *CompletionVerdict `json:"verification"`
+	}
+	if len(e.Metadata) == 0 || json.Unmarshal(e.Metadata, &metadata) != nil {
+		return nil
+	}
+	return metadata.Verdict
+}
@@ -0,0 +1,74 @@
+package entities
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxGeneralMessageLength is the longest content, in characters, of a general message.
+const MaxGeneralMessageLength = 2000
+
+/** Conversation is the thread of general messages between a user and one counterpart,
+ * as the user sees it: the latest message, how many of the counterpart's messages the
+ * user has not read, and the user's own settings for the conversation.
+ */
+type Conversation struct {
+	CounterpartID string
+	LastMessage   *Message
+	UnreadCount   int
+	Muted         bool
+	Blocked       bool
+}
+
+/** ConversationSetting is what a user chose for their conversation with a counterpart.
+ * Blocked stops messages in either direction; Muted keeps delivering and counting the
+ * counterpart's messages but does not notify the user of them.
+ */
+type ConversationSetting struct {
+	ID            string    `json:"id"`
+	UserID        string    `json:"user_id"`
+	CounterpartID string    `json:"counterpart_id"`
+	Blocked       bool      `json:"blocked"`
+	Muted         bool      `json:"muted"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+/** Constructor in factory pattern
+ * @param userID - the ID of the user the setting belongs to
+ * @param counterpartID - the ID of the other user in the conversation
+ * @return the created setting, neither blocked nor muted
+ */
+func NewConversationSetting(userID, counterpartID string) (*ConversationSetting, error) {
+	userID = strings.TrimSpace(userID)
+	counterpartID = strings.TrimSpace(counterpartID)
+	if userID == "" {
+		return nil, errors.New("userID cannot be empty")
+	}
+	if counterpartID == "" {
+		return nil, errors.New("counterpartID cannot be empty")
+	}
+	if userID == counterpartID {
+		return nil, errors.New("a user has no conversation with themselves")
+	}
+
+	now := time.Now().UTC()
+	return &ConversationSetting{
+		ID:            uuid.New().String(),
+		UserID:        userID,
+		CounterpartID: counterpartID,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}, nil
+}
+
+/** Returns the other user of a message userID sent or received */
+func (m *Message) Counterpart(userID string) string {
+	if m.SenderID == userID {
+		return m.ReceiverID
+	}
+	return m.SenderID
+}
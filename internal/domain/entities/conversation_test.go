@@ -0,0 +1,33 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConversationSetting(t *testing.T) {
+	t.Parallel()
+
+	setting, err := NewConversationSetting(" user-1 ", "user-2")
+	require.NoError(t, err)
+	assert.NotEmpty(t, setting.ID)
+	assert.Equal(t, "user-1", setting.UserID)
+	assert.Equal(t, "user-2", setting.CounterpartID)
+	assert.False(t, setting.Blocked)
+	assert.False(t, setting.Muted)
+
+	_, err = NewConversationSetting("user-1", " ")
+	assert.EqualError(t, err, "counterpartID cannot be empty")
+	_, err = NewConversationSetting("user-1", "user-1")
+	assert.Error(t, err)
+}
+
+func TestMessage_Counterpart(t *testing.T) {
+	t.Parallel()
+
+	message := &Message{SenderID: "user-1", ReceiverID: "user-2"}
+	assert.Equal(t, "user-2", message.Counterpart("user-1"))
+	assert.Equal(t, "user-1", message.Counterpart("user-2"))
+}
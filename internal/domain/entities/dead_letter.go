@@ -0,0 +1,54 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeadLetterReason says why a completion signal could not be recorded.
+type DeadLetterReason string
+
+const (
+	DeadLetterReasonNoTask         DeadLetterReason = "no_task"         // the user has no task that day in the grind
+	DeadLetterReasonTaskMismatch   DeadLetterReason = "task_mismatch"   // the task asks for something else, e.g. another roadmap problem
+	DeadLetterReasonNotVerified    DeadLetterReason = "not_verified"    // the provider could not confirm the completion
+	DeadLetterReasonWindowClosed   DeadLetterReason = "window_closed"   // the completion's day is past the grace window or already evaluated
+	DeadLetterReasonInvalidPayload DeadLetterReason = "invalid_payload" // the body is not JSON or lacks a required field
+)
+
+// DeadLetter keeps a completion signal that ingest could not record, with the reason, so
+// it can be inspected and re-driven once the cause is fixed instead of being lost.
+// Payload is the body as received and is not necessarily valid JSON. Evidence, when the
+// signal came with a file, stays in the blob store until the letter is re-driven or discarded.
+type DeadLetter struct {
+	ID            string
+	UserID        string
+	Provider      CompletionProvider
+	IntegrationID string // custom only: the integration that signed the payload
+	GrindID       string // as sent; empty when the payload named none
+	Payload       []byte
+	Reason        DeadLetterReason
+	Error         string // what ingest failed with
+	Evidence      *EvidenceAttachment
+	CreatedAt     time.Time
+}
+
+// NewDeadLetter records why the completion signal payload, sent for userID, was rejected.
+func NewDeadLetter(userID string, provider CompletionProvider, integrationID, grindID string, payload []byte, reason DeadLetterReason, cause error) *DeadLetter {
+	message := ""
+	if cause != nil {
+		message = cause.Error()
+	}
+	return &DeadLetter{
+		ID:            uuid.New().String(),
+		UserID:        userID,
+		Provider:      provider,
+		IntegrationID: integrationID,
+		GrindID:       grindID,
+		Payload:       payload,
+		Reason:        reason,
+		Error:         message,
+		CreatedAt:     time.Now().UTC(),
+	}
+}
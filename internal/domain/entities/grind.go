@@ -18,6 +18,15 @@ type Grind struct {
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
 	PartnerGroupID string // references PartnerGroup.ID; empty when no group is attached (per D-04)
+	PenaltyPolicy  PenaltyPolicy
+	Schedule       Schedule // which days inside StartDate + Duration are habit days
+	Roadmap        *Roadmap // the LeetCode study plan assigning each habit day a problem; nil for free-form grinds
+	State          GrindState
+	PublishedAt    *time.Time // set on the draft -> recruiting transition
+	ActivatedAt    *time.Time
+	SettlingAt     *time.Time
+	SettledAt      *time.Time
+	CancelledAt    *time.Time
 }
 
 /** Constructor in factory pattern
@@ -38,15 +47,49 @@ func NewGrind(duration int, budget int, startDate time.Time) (*Grind, error) {
 	now := time.Now().UTC()
 
 	return &Grind{
-		ID:           uuid.New().String(),
-		Duration:     int32(duration),
-		Participants: []User{},
-		Budget:       int32(budget),
-		Tasks:        []HabitTask{},
-		StartDate:    startDate.UTC(),
-		CreatedAt:    now,
-		UpdatedAt:    now,
+		ID:            uuid.New().String(),
+		Duration:      int32(duration),
+		Participants:  []User{},
+		Budget:        int32(budget),
+		Tasks:         []HabitTask{},
+		StartDate:     startDate.UTC(),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		PenaltyPolicy: DefaultPenaltyPolicy(duration, budget),
+		Schedule:      DailySchedule(),
+		State:         GrindStateDraft,
 		// Notice: We do NOT initialize Participants or Tasks here
 		// if they require further database lookups.
 	}, nil
 }
+
+/** The grind's start as a calendar day; StartDate's UTC date is the first day for every participant */
+func (g *Grind) startDay() (int, time.Month, int) {
+	return g.StartDate.UTC().Date()
+}
+
+/** Local midnight, in loc, of the grind's day-th day (0-based) */
+func (g *Grind) DayStart(day int, loc *time.Location) time.Time {
+	y, m, d := g.startDay()
+	return time.Date(y, m, d+day, 0, 0, 0, 0, loc)
+}
+
+/** The first instant after the grind's last day, for a participant in loc */
+func (g *Grind) EndDateIn(loc *time.Location) time.Time {
+	return g.DayStart(int(g.Duration), loc)
+}
+
+/** The first instant after the grind's last day, in UTC */
+func (g *Grind) EndDate() time.Time {
+	return g.EndDateIn(time.UTC)
+}
+
+/** Reports whether the grind's first day has begun in the earliest timezone (UTC+14) */
+func (g *Grind) HasStarted(now time.Time) bool {
+	return !now.Before(g.DayStart(0, time.UTC).Add(-14 * time.Hour))
+}
+
+/** Reports whether the grind's end falls on the current local day of a participant in loc */
+func (g *Grind) IsDueOn(now time.Time, loc *time.Location) bool {
+	return LocalDayStart(now, loc).Equal(g.EndDateIn(loc))
+}
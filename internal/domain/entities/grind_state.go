@@ -0,0 +1,126 @@
+package entities
+
+import (
+	"fmt"
+	"time"
+)
+
+/** Lifecycle state of a grind */
+type GrindState string
+
+const (
+	GrindStateDraft      GrindState = "draft"      // created but not yet open to other users
+	GrindStateRecruiting GrindState = "recruiting" // open for invitations and joins until it starts
+	GrindStateActive     GrindState = "active"     // running; tasks are due and penalties accrue
+	GrindStateSettling   GrindState = "settling"   // ended; the pot is being collected and paid out
+	GrindStateSettled    GrindState = "settled"    // every charge and payout has been resolved
+	GrindStateCancelled  GrindState = "cancelled"  // abandoned before it started
+)
+
+/** The operation that moves a grind into each state, used in error messages */
+var grindStateOps = map[GrindState]string{
+	GrindStateRecruiting: "publish",
+	GrindStateActive:     "activate",
+	GrindStateSettling:   "settle",
+	GrindStateSettled:    "close",
+	GrindStateCancelled:  "cancel",
+}
+
+/** Allowed transitions; every state not listed as a key is terminal */
+var grindStateTransitions = map[GrindState][]GrindState{
+	GrindStateDraft:      {GrindStateRecruiting, GrindStateCancelled},
+	GrindStateRecruiting: {GrindStateActive, GrindStateCancelled},
+	GrindStateActive:     {GrindStateSettling},
+	GrindStateSettling:   {GrindStateSettled},
+}
+
+/** Reports whether s is one of the known grind states */
+func (s GrindState) IsValid() bool {
+	switch s {
+	case GrindStateDraft, GrindStateRecruiting, GrindStateActive,
+		GrindStateSettling, GrindStateSettled, GrindStateCancelled:
+		return true
+	}
+	return false
+}
+
+/** Reports whether a grind in state s may move to next */
+func (s GrindState) CanTransitionTo(next GrindState) bool {
+	for _, allowed := range grindStateTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+/** Reports whether a grind in state s can still be joined or run, i.e. it has not ended or been cancelled */
+func (s GrindState) IsOngoing() bool {
+	return s == GrindStateRecruiting || s == GrindStateActive
+}
+
+/** Returned when an operation is not allowed in the grind's current state */
+type GrindStateError struct {
+	Op    string     // the rejected operation, e.g. "join" or "edit"
+	State GrindState // the state the grind was in
+}
+
+func (e *GrindStateError) Error() string {
+	return fmt.Sprintf("cannot %s a grind that is %s", e.Op, e.State)
+}
+
+/** Moves the grind to next and stamps the matching timestamp
+ * @param next - the target state
+ * @param at - when the transition happened
+ * @return a *GrindStateError when the transition is not allowed
+ */
+func (g *Grind) TransitionTo(next GrindState, at time.Time) error {
+	if !g.State.CanTransitionTo(next) {
+		return &GrindStateError{Op: grindStateOps[next], State: g.State}
+	}
+	stamp := at.UTC()
+	switch next {
+	case GrindStateRecruiting:
+		g.PublishedAt = &stamp
+	case GrindStateActive:
+		g.ActivatedAt = &stamp
+	case GrindStateSettling:
+		g.SettlingAt = &stamp
+	case GrindStateSettled:
+		g.SettledAt = &stamp
+	case GrindStateCancelled:
+		g.CancelledAt = &stamp
+	}
+	g.State = next
+	g.UpdatedAt = stamp
+	return nil
+}
+
+/** Users may only join a grind once it is published and before it starts */
+func (g *Grind) ValidateJoin() error {
+	return g.requireState("join", GrindStateRecruiting)
+}
+
+/** A draft stays private, so invitations can only be sent while the grind is recruiting */
+func (g *Grind) ValidateInvite() error {
+	return g.requireState("invite users to", GrindStateRecruiting)
+}
+
+/** The grind's schedule and stakes can only be edited before it starts */
+func (g *Grind) ValidateEdit() error {
+	return g.requireState("edit", GrindStateDraft, GrindStateRecruiting)
+}
+
+/** Participants may quit until the grind ends */
+func (g *Grind) ValidateQuit() error {
+	return g.requireState("quit", GrindStateDraft, GrindStateRecruiting, GrindStateActive)
+}
+
+func (g *Grind) requireState(op string, allowed ...GrindState) error {
+	for _, s := range allowed {
+		if g.State == s {
+			return nil
+		}
+	}
+	return &GrindStateError{Op: op, State: g.State}
+}
@@ -94,3 +94,113 @@ func TestNewGrind(t *testing.T) {
 		})
 	}
 }
+
+func TestGrindDefaultPenaltyPolicy(t *testing.T) {
+	t.Parallel()
+
+	grind, err := NewGrind(30, 300, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	want := PenaltyPolicy{Kind: PenaltyKindFlat, Amount: 10, CapAtBudget: true}
+	if grind.PenaltyPolicy != want {
+		t.Fatalf("expected default policy %+v, got %+v", want, grind.PenaltyPolicy)
+	}
+	if want := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC); !grind.EndDate().Equal(want) {
+		t.Fatalf("expected end date %v, got %v", want, grind.EndDate())
+	}
+}
+
+func TestGrindDayBoundariesInParticipantTimezone(t *testing.T) {
+	t.Parallel()
+
+	taipei, err := time.LoadLocation("Asia/Taipei")
+	require.NoError(t, err)
+	grind, err := NewGrind(3, 30, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	if want := time.Date(2026, 4, 2, 0, 0, 0, 0, taipei); !grind.DayStart(1, taipei).Equal(want) {
+		t.Fatalf("expected day 1 to start at %v, got %v", want, grind.DayStart(1, taipei))
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		loc  *time.Location
+		want bool
+	}{
+		{name: "due on the end date in UTC", now: time.Date(2026, 4, 4, 1, 0, 0, 0, time.UTC), loc: time.UTC, want: true},
+		{name: "already the end date in Taipei", now: time.Date(2026, 4, 3, 17, 0, 0, 0, time.UTC), loc: taipei, want: true},
+		{name: "last day still running in UTC", now: time.Date(2026, 4, 3, 17, 0, 0, 0, time.UTC), loc: time.UTC, want: false},
+		{name: "end date already over in Taipei", now: time.Date(2026, 4, 4, 17, 0, 0, 0, time.UTC), loc: taipei, want: false},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if got := grind.IsDueOn(tc.now, tc.loc); got != tc.want {
+				t.Fatalf("IsDueOn(%v, %v) = %v, want %v", tc.now, tc.loc, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGrindStateTransitions(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2026, 4, 1, 8, 0, 0, 0, time.FixedZone("UTC+8", 8*60*60))
+	grind, err := NewGrind(3, 30, at)
+	require.NoError(t, err)
+	require.Equal(t, GrindStateDraft, grind.State)
+
+	require.NoError(t, grind.TransitionTo(GrindStateRecruiting, at))
+	require.NotNil(t, grind.PublishedAt)
+	require.Equal(t, time.UTC, grind.PublishedAt.Location())
+	require.NoError(t, grind.TransitionTo(GrindStateActive, at))
+	require.NotNil(t, grind.ActivatedAt)
+
+	err = grind.TransitionTo(GrindStateCancelled, at)
+	var stateErr *GrindStateError
+	require.ErrorAs(t, err, &stateErr)
+	require.Equal(t, GrindStateActive, stateErr.State)
+	require.Equal(t, "cancel", stateErr.Op)
+	require.Nil(t, grind.CancelledAt)
+
+	require.NoError(t, grind.TransitionTo(GrindStateSettling, at))
+	require.NoError(t, grind.TransitionTo(GrindStateSettled, at))
+	require.NotNil(t, grind.SettlingAt)
+	require.NotNil(t, grind.SettledAt)
+	require.Error(t, grind.TransitionTo(GrindStateActive, at))
+}
+
+func TestGrindStateGuards(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		state                     GrindState
+		canJoin, canEdit, canQuit bool
+	}{
+		{state: GrindStateDraft, canEdit: true, canQuit: true},
+		{state: GrindStateRecruiting, canJoin: true, canEdit: true, canQuit: true},
+		{state: GrindStateActive, canQuit: true},
+		{state: GrindStateSettling},
+		{state: GrindStateSettled},
+		{state: GrindStateCancelled},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(string(tc.state), func(t *testing.T) {
+			t.Parallel()
+			grind := &Grind{State: tc.state}
+			require.Equal(t, tc.canJoin, grind.ValidateJoin() == nil)
+			require.Equal(t, tc.canJoin, grind.ValidateInvite() == nil)
+			require.Equal(t, tc.canEdit, grind.ValidateEdit() == nil)
+			require.Equal(t, tc.canQuit, grind.ValidateQuit() == nil)
+		})
+	}
+
+	err := (&Grind{State: GrindStateSettled}).ValidateJoin()
+	require.EqualError(t, err, "cannot join a grind that is settled")
+	err = (&Grind{State: GrindStateDraft}).ValidateInvite()
+	require.EqualError(t, err, "cannot invite users to a grind that is draft")
+	require.False(t, GrindState("paused").IsValid())
+}
@@ -1,6 +1,7 @@
 package entities
 
 import (
+	"encoding/json"
 	"errors"
 	"time"
 
@@ -8,22 +9,29 @@ import (
 	"gorm.io/datatypes"
 )
 
-// HabitTask is the generic habit activity entity.
+// HabitTaskTypeLeetCode marks tasks that were assigned a specific LeetCode problem by a roadmap.
+const HabitTaskTypeLeetCode = "leetcode"
+
+// HabitTask is the generic habit activity entity. Date is local midnight of the task's
+// day in the owner's timezone, so the day runs from Date to DayEnd.
 // Provider-specific fields (e.g. LeetCode problem title, Duolingo lesson) are
 // stored in Metadata as JSONB rather than as typed struct fields (per D-01).
 type HabitTask struct {
-	ID           string
-	TaskType     string
-	UserID       string
-	GrindID      string
-	Date         time.Time
-	FinishedTime *time.Time
-	Completed    bool
-	Metadata     datatypes.JSON
+	ID             string
+	TaskType       string
+	UserID         string
+	GrindID        string
+	Date           time.Time
+	FinishedTime   *time.Time
+	Completed      bool
+	Missed         bool // set by the daily evaluator once the task's day has closed uncompleted
+	Excused        bool // closed without penalty: its week's target was already met, or still can be after a reverted completion (weekly_target schedules)
+	RequiredEvents int  // number of CompletionEvents needed to complete the task; <= 1 means the first event wins
+	Metadata       datatypes.JSON
 }
 
 // NewHabitTask creates a new HabitTask with a generated UUID, TaskType="generic",
-// and Completed=false. userID and grindID must be non-empty.
+// RequiredEvents=1 and Completed=false. userID and grindID must be non-empty.
 func NewHabitTask(userID, grindID string, date time.Time) (*HabitTask, error) {
 	if userID == "" {
 		return nil, errors.New("userID cannot be empty")
@@ -32,12 +40,100 @@ func NewHabitTask(userID, grindID string, date time.Time) (*HabitTask, error) {
 		return nil, errors.New("grindID cannot be empty")
 	}
 	return &HabitTask{
-		ID:        uuid.New().String(),
-		TaskType:  "generic",
-		UserID:    userID,
-		GrindID:   grindID,
-		Date:      date,
-		Completed: false,
-		Metadata:  nil,
+		ID:             uuid.New().String(),
+		TaskType:       "generic",
+		UserID:         userID,
+		GrindID:        grindID,
+		Date:           date,
+		Completed:      false,
+		RequiredEvents: 1,
+		Metadata:       nil,
 	}, nil
 }
+
+// CompletionRule decides whether the CompletionEvents recorded against a HabitTask
+// are enough to consider the task done.
+type CompletionRule interface {
+	IsSatisfied(events []*CompletionEvent) bool
+}
+
+// FirstEventRule completes a task as soon as one CompletionEvent is recorded.
+type FirstEventRule struct{}
+
+func (FirstEventRule) IsSatisfied(events []*CompletionEvent) bool {
+	return len(events) > 0
+}
+
+// MinEventsRule completes a task once at least Required CompletionEvents are recorded.
+type MinEventsRule struct {
+	Required int
+}
+
+func (r MinEventsRule) IsSatisfied(events []*CompletionEvent) bool {
+	return len(events) >= r.Required
+}
+
+// CompletionRule returns the rule configured for this task.
+func (t *HabitTask) CompletionRule() CompletionRule {
+	if t.RequiredEvents <= 1 {
+		return FirstEventRule{}
+	}
+	return MinEventsRule{Required: t.RequiredEvents}
+}
+
+// MarkCompleted sets Completed and FinishedTime. It returns false without
+// touching the task when it has already been completed.
+func (t *HabitTask) MarkCompleted(finishedAt time.Time) bool {
+	if t.Completed {
+		return false
+	}
+	finished := finishedAt.UTC()
+	t.Completed = true
+	t.FinishedTime = &finished
+	return true
+}
+
+// IsOpen reports whether the task still waits for a completion or an evaluation.
+func (t *HabitTask) IsOpen() bool {
+	return !t.Completed && !t.Missed && !t.Excused
+}
+
+// DayEnd returns the first instant after the task's day for an owner in loc.
+func (t *HabitTask) DayEnd(loc *time.Location) time.Time {
+	y, m, d := t.Date.In(loc).Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+}
+
+// roadmapTaskMetadata is the Metadata of a task a roadmap assigned a problem to.
+type roadmapTaskMetadata struct {
+	Roadmap   string `json:"roadmap"`
+	ProblemID int    `json:"problemId"`
+	Slug      string `json:"slug"`
+	Tag       string `json:"tag"`
+}
+
+// AssignProblem turns the task into a LeetCode task for problem of the named roadmap.
+// The slug and tag are kept in Metadata so providers can check submissions against them.
+func (t *HabitTask) AssignProblem(roadmap string, problem RoadmapProblem) {
+	metadata, _ := json.Marshal(roadmapTaskMetadata{
+		Roadmap:   roadmap,
+		ProblemID: problem.ID,
+		Slug:      problem.Slug,
+		Tag:       problem.Tag,
+	})
+	t.TaskType = HabitTaskTypeLeetCode
+	t.Metadata = datatypes.JSON(metadata)
+}
+
+// AssignedProblemSlug returns the slug of the problem assigned to the task, or "" when
+// any submission counts.
+func (t *HabitTask) AssignedProblemSlug() string {
+	if t.TaskType != HabitTaskTypeLeetCode || len(t.Metadata) == 0 {
+		return ""
+	}
+	var metadata roadmapTaskMetadata
+	if err := json.Unmarshal(t.Metadata, &metadata); err != nil {
+		return ""
+	}
+	return metadata.Slug
+}
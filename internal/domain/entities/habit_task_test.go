@@ -37,3 +37,65 @@ func Test_NewHabitTask_EmptyGrindID(t *testing.T) {
 	assert.Nil(t, task)
 	assert.Equal(t, "grindID cannot be empty", err.Error())
 }
+
+func Test_NewHabitTask_DefaultsToFirstEventRule(t *testing.T) {
+	task, err := NewHabitTask("user-1", "grind-1", time.Now())
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, task.RequiredEvents)
+	assert.IsType(t, FirstEventRule{}, task.CompletionRule())
+}
+
+func Test_HabitTask_CompletionRule(t *testing.T) {
+	t.Parallel()
+
+	oneEvent := []*CompletionEvent{{ID: "e1"}}
+	twoEvents := []*CompletionEvent{{ID: "e1"}, {ID: "e2"}}
+
+	tests := []struct {
+		name           string
+		requiredEvents int
+		events         []*CompletionEvent
+		want           bool
+	}{
+		{name: "first event wins with no events", requiredEvents: 1, events: nil, want: false},
+		{name: "first event wins with one event", requiredEvents: 1, events: oneEvent, want: true},
+		{name: "zero required falls back to first event", requiredEvents: 0, events: oneEvent, want: true},
+		{name: "two required with one event", requiredEvents: 2, events: oneEvent, want: false},
+		{name: "two required with two events", requiredEvents: 2, events: twoEvents, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &HabitTask{RequiredEvents: tt.requiredEvents}
+			assert.Equal(t, tt.want, task.CompletionRule().IsSatisfied(tt.events))
+		})
+	}
+}
+
+func Test_HabitTask_MarkCompleted(t *testing.T) {
+	task, err := NewHabitTask("user-1", "grind-1", time.Now())
+	require.NoError(t, err)
+
+	first := time.Date(2026, 4, 1, 10, 0, 0, 0, time.UTC)
+	assert.True(t, task.MarkCompleted(first))
+	assert.True(t, task.Completed)
+	require.NotNil(t, task.FinishedTime)
+	assert.True(t, task.FinishedTime.Equal(first))
+
+	// A second completion must not move FinishedTime.
+	assert.False(t, task.MarkCompleted(first.Add(time.Hour)))
+	assert.True(t, task.FinishedTime.Equal(first))
+}
+
+func TestHabitTaskDayEnd(t *testing.T) {
+	t.Parallel()
+
+	taipei, err := time.LoadLocation("Asia/Taipei")
+	require.NoError(t, err)
+	task := &HabitTask{Date: time.Date(2026, 4, 10, 0, 0, 0, 0, taipei)}
+
+	assert.True(t, task.DayEnd(taipei).Equal(time.Date(2026, 4, 11, 0, 0, 0, 0, taipei)))
+	// the same instant read from UTC belongs to April 9 (16:00 UTC), which ends at April 10 00:00 UTC
+	assert.True(t, task.DayEnd(time.UTC).Equal(time.Date(2026, 4, 10, 0, 0, 0, 0, time.UTC)))
+}
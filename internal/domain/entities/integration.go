@@ -0,0 +1,210 @@
+package entities
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+/** The prefix of an X-Signature value; the rest is the hex HMAC-SHA256 of the signed timestamp and body */
+const SignaturePrefix = "sha256="
+
+/** Where a custom integration's payloads keep the fields a completion needs.
+ * Each value is a dot-separated path into the JSON body, e.g. "data.finishedAt".
+ */
+type IntegrationFieldMapping struct {
+	OccurredAt string // RFC 3339 string or unix seconds; empty reads the top-level "occurredAt" if present
+	Evidence   string // any JSON value, stored with the completion event; empty stores none
+	EventID    string // the sender's ID for the event; retries with the same ID are recorded once
+}
+
+/** A user's or a partner group's own completion source for the custom provider.
+ * Every request is signed with the integration's shared secret. A personal integration
+ * ingests for its owner; a group integration ingests for any member of the group.
+ */
+type Integration struct {
+	ID        string
+	Name      string
+	OwnerID   string
+	GroupID   string // empty for a personal integration
+	Secret    string // hex; kept in plain text because the server has to compute the same HMAC
+	Mapping   IntegrationFieldMapping
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+/** Constructor in factory pattern
+ * @param ownerID - the user who configures the integration
+ * @param groupID - the partner group it ingests for; empty for a personal integration
+ * @param name - shown to the owner, at most 100 characters
+ * @param mapping - where payloads keep occurredAt, the evidence and the event ID
+ */
+func NewIntegration(ownerID, groupID, name string, mapping IntegrationFieldMapping) (*Integration, error) {
+	if ownerID == "" {
+		return nil, errors.New("ownerID cannot be empty")
+	}
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 100 {
+		return nil, errors.New("name must be between 1 and 100 characters")
+	}
+	for _, path := range []string{mapping.OccurredAt, mapping.Evidence, mapping.EventID} {
+		if path != "" && !validFieldPath(path) {
+			return nil, errors.New("invalid field path: " + path)
+		}
+	}
+
+	secret, err := newIntegrationSecret()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	return &Integration{
+		ID:        uuid.New().String(),
+		Name:      name,
+		OwnerID:   ownerID,
+		GroupID:   groupID,
+		Secret:    secret,
+		Mapping:   mapping,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+func newIntegrationSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", errors.New("failed to generate integration secret")
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+func validFieldPath(path string) bool {
+	for _, segment := range strings.Split(path, ".") {
+		if segment == "" {
+			return false
+		}
+	}
+	return true
+}
+
+/** The X-Signature value for body sent at timestamp, the X-Signature-Timestamp value:
+ * "sha256=" and the hex HMAC-SHA256 of "<timestamp>.<body>" under the integration's secret
+ */
+func (i *Integration) Sign(timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(i.Secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return SignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+/** Reports whether signature is the integration's signature of body sent at timestamp; the comparison is constant-time */
+func (i *Integration) VerifySignature(timestamp string, body []byte, signature string) bool {
+	if !strings.HasPrefix(signature, SignaturePrefix) {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(i.Sign(timestamp, body)))
+}
+
+/** Reports whether the integration may ingest completions for userID */
+func (i *Integration) CanIngestFor(userID string, group *PartnerGroup) bool {
+	if userID == i.OwnerID {
+		return true
+	}
+	if i.GroupID == "" || group == nil || group.ID != i.GroupID {
+		return false
+	}
+	for _, member := range group.Members {
+		if member == userID {
+			return true
+		}
+	}
+	return false
+}
+
+/** Reads the mapped fields of a payload.
+ * occurredAt is zero when the payload has none and the mapping does not name a path;
+ * a field the mapping names but the payload lacks is an error.
+ */
+func (m IntegrationFieldMapping) Extract(raw map[string]interface{}) (occurredAt time.Time, evidence interface{}, err error) {
+	occurredAtPath := m.OccurredAt
+	if occurredAtPath == "" {
+		occurredAtPath = "occurredAt"
+	}
+	if value, ok := lookupFieldPath(raw, occurredAtPath); ok {
+		occurredAt, err = parseOccurredAt(value)
+		if err != nil {
+			return time.Time{}, nil, errors.New(occurredAtPath + ": " + err.Error())
+		}
+	} else if m.OccurredAt != "" {
+		return time.Time{}, nil, errors.New(occurredAtPath + " is missing")
+	}
+
+	if m.Evidence != "" {
+		value, ok := lookupFieldPath(raw, m.Evidence)
+		if !ok {
+			return time.Time{}, nil, errors.New(m.Evidence + " is missing")
+		}
+		evidence = value
+	}
+	return occurredAt, evidence, nil
+}
+
+/** The payload's event ID under the mapping, or "" when the mapping names none or the payload lacks it */
+func (m IntegrationFieldMapping) ExtractEventID(raw map[string]interface{}) string {
+	if m.EventID == "" {
+		return ""
+	}
+	value, ok := lookupFieldPath(raw, m.EventID)
+	if !ok {
+		return ""
+	}
+	return scalarString(value)
+}
+
+func scalarString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
+
+func lookupFieldPath(raw map[string]interface{}, path string) (interface{}, bool) {
+	var current interface{} = raw
+	for _, segment := range strings.Split(path, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = object[segment]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func parseOccurredAt(value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case string:
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return time.Time{}, errors.New("must be an RFC 3339 timestamp")
+		}
+		return t.UTC(), nil
+	case float64:
+		return time.Unix(int64(v), 0).UTC(), nil
+	default:
+		return time.Time{}, errors.New("must be an RFC 3339 string or unix seconds")
+	}
+}
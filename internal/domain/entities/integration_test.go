@@ -0,0 +1,99 @@
+package entities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewIntegration(t *testing.T) {
+	t.Parallel()
+
+	integration, err := NewIntegration("user-1", "", "  Strava runs ", IntegrationFieldMapping{OccurredAt: "activity.end", Evidence: "activity.url"})
+	require.NoError(t, err)
+	require.Equal(t, "Strava runs", integration.Name)
+	require.Len(t, integration.Secret, 64)
+
+	other, err := NewIntegration("user-1", "", "Strava runs", IntegrationFieldMapping{})
+	require.NoError(t, err)
+	require.NotEqual(t, integration.Secret, other.Secret)
+
+	_, err = NewIntegration("", "", "Strava", IntegrationFieldMapping{})
+	require.ErrorContains(t, err, "ownerID")
+	_, err = NewIntegration("user-1", "", " ", IntegrationFieldMapping{})
+	require.ErrorContains(t, err, "name")
+	_, err = NewIntegration("user-1", "", "Strava", IntegrationFieldMapping{Evidence: "activity..url"})
+	require.ErrorContains(t, err, "invalid field path")
+}
+
+func TestIntegrationVerifySignature(t *testing.T) {
+	t.Parallel()
+
+	integration := &Integration{Secret: "s3cret"}
+	body := []byte(`{"grindID":"grind-1"}`)
+	signature := integration.Sign("1775822400", body)
+
+	// echo -n '1775822400.{"grindID":"grind-1"}' | openssl dgst -sha256 -hmac s3cret
+	require.Equal(t, "sha256=9e5d92c14ce3389f5a923b9eaa1b7a07d12db556ebcc13ed8678f4e9f214dfd6", signature)
+	require.True(t, integration.VerifySignature("1775822400", body, signature))
+	require.False(t, integration.VerifySignature("1775822400", []byte(`{"grindID":"grind-2"}`), signature))
+	require.False(t, integration.VerifySignature("1775822401", body, signature), "the timestamp is signed too")
+	require.False(t, integration.VerifySignature("1775822400", body, signature[len(SignaturePrefix):]))
+	require.False(t, (&Integration{Secret: "other"}).VerifySignature("1775822400", body, signature))
+}
+
+func TestIntegrationCanIngestFor(t *testing.T) {
+	t.Parallel()
+
+	personal := &Integration{OwnerID: "owner"}
+	require.True(t, personal.CanIngestFor("owner", nil))
+	require.False(t, personal.CanIngestFor("member", nil))
+
+	group := &PartnerGroup{ID: "group-1", Members: []string{"owner", "member"}}
+	team := &Integration{OwnerID: "owner", GroupID: "group-1"}
+	require.True(t, team.CanIngestFor("member", group))
+	require.False(t, team.CanIngestFor("stranger", group))
+	require.False(t, team.CanIngestFor("member", &PartnerGroup{ID: "group-2", Members: []string{"member"}}))
+}
+
+func TestIntegrationFieldMappingExtract(t *testing.T) {
+	t.Parallel()
+
+	raw := map[string]interface{}{
+		"occurredAt": "2026-04-10T12:00:00+08:00",
+		"activity": map[string]interface{}{
+			"end": float64(1775822400),
+			"url": "https://example.com/run/1",
+		},
+	}
+
+	occurredAt, evidence, err := IntegrationFieldMapping{OccurredAt: "activity.end", Evidence: "activity.url"}.Extract(raw)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC), occurredAt)
+	require.Equal(t, "https://example.com/run/1", evidence)
+
+	occurredAt, evidence, err = IntegrationFieldMapping{}.Extract(raw)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2026, 4, 10, 4, 0, 0, 0, time.UTC), occurredAt)
+	require.Nil(t, evidence)
+
+	occurredAt, _, err = IntegrationFieldMapping{}.Extract(map[string]interface{}{})
+	require.NoError(t, err)
+	require.True(t, occurredAt.IsZero())
+
+	_, _, err = IntegrationFieldMapping{Evidence: "activity.photo"}.Extract(raw)
+	require.ErrorContains(t, err, "activity.photo is missing")
+	_, _, err = IntegrationFieldMapping{OccurredAt: "activity.url"}.Extract(raw)
+	require.ErrorContains(t, err, "RFC 3339")
+}
+
+func TestIntegrationFieldMappingExtractEventID(t *testing.T) {
+	t.Parallel()
+
+	raw := map[string]interface{}{"run": map[string]interface{}{"id": float64(42), "ref": "abc"}}
+	require.Equal(t, "42", IntegrationFieldMapping{EventID: "run.id"}.ExtractEventID(raw))
+	require.Equal(t, "abc", IntegrationFieldMapping{EventID: "run.ref"}.ExtractEventID(raw))
+	require.Equal(t, "", IntegrationFieldMapping{EventID: "run.missing"}.ExtractEventID(raw))
+	require.Equal(t, "", IntegrationFieldMapping{}.ExtractEventID(raw))
+}
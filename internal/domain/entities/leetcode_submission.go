@@ -0,0 +1,11 @@
+package entities
+
+import "time"
+
+/** An accepted submission as reported by LeetCode for a public profile */
+type LeetCodeSubmission struct {
+	ID          string
+	Slug        string // the problem's title slug, e.g. "two-sum"
+	Title       string
+	SubmittedAt time.Time
+}
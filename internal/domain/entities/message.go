@@ -13,20 +13,49 @@ type Message struct {
 	SenderID           string    `json:"sender_id" gorm:"not null"`
 	ReceiverID         string    `json:"receiver_id" gorm:"not null"`
 	Content            string    `json:"content" gorm:"not null"`
-	Type               string    `json:"type" gorm:"not null"`               // 'general' | 'invitation' | invitation_accepted' | 'invitation_rejected' |
+	Type               string    `json:"type" gorm:"not null"`               // 'general' | 'invitation' | invitation_accepted' | 'invitation_rejected' | 'stake_change' | 'completion_dispute'
 	InvitationGrindID  string    `json:"invitation_grind_id" gorm:""`        // the id of the grind that the invitation is for
 	InvitationAccepted bool      `json:"invitation_accepted" gorm:""`        // whether the invitation has been accepted by the receiver
 	InvitationRejected bool      `json:"invitation_rejected" gorm:""`        // whether the invitation has been rejected by the receiver
 	Read               bool      `json:"read" gorm:"not null;default:false"` // whether the message has been read by the receiver
 	CreatedAt          time.Time `json:"created_at" gorm:"not null"`
 	UpdatedAt          time.Time `json:"updated_at" gorm:"not null"`
+
+	InvitationExpiresAt *time.Time `json:"invitation_expires_at"` // invitations only: when the receiver can no longer answer
+	InvitationRevokedAt *time.Time `json:"invitation_revoked_at"` // invitations only: when the sender withdrew it
+}
+
+/** How long the receiver of an invitation has to accept or reject it */
+const InvitationTTL = 7 * 24 * time.Hour
+
+var validMessageTypes = map[string]bool{
+	"general":             true,
+	"invitation":          true,
+	"invitation_accepted": true,
+	"invitation_rejected": true,
+	"stake_change":        true,
+	"completion_dispute":  true,
+}
+
+/** Reports whether messageType is one of the types a message can have */
+func IsValidMessageType(messageType string) bool {
+	return validMessageTypes[messageType]
+}
+
+/** MessageFilter narrows a listing of messages. Empty fields match every message.
+ * Read, when set, keeps only messages the receiver has or has not read.
+ */
+type MessageFilter struct {
+	Type    string
+	Read    *bool
+	GrindID string
 }
 
 /** Constructor in factory pattern
  * @param senderID - the ID of the message sender
  * @param receiverID - the ID of the message receiver
  * @param content - the message content
- * @param messageType - the type of message: "general", "invitation", "invitation_accepted", "invitation_rejected"
+ * @param messageType - the type of message: "general", "invitation", "invitation_accepted", "invitation_rejected", "stake_change", "completion_dispute"
  * @param invitationGrindID - optional: the grind ID for invitation-related messages
  * @param invitationAccepted - optional: whether invitation is accepted (for invitation_accepted type)
  * @param invitationRejected - optional: whether invitation is rejected (for invitation_rejected type)
@@ -52,24 +81,23 @@ func NewMessage(
 	}
 
 	// Validate message type
-	validTypes := map[string]bool{
-		"general":             true,
-		"invitation":          true,
-		"invitation_accepted": true,
-		"invitation_rejected": true,
-	}
-	if !validTypes[messageType] {
-		return nil, errors.New("invalid message type: must be 'general', 'invitation', 'invitation_accepted', or 'invitation_rejected'")
+	if !IsValidMessageType(messageType) {
+		return nil, errors.New("invalid message type: must be 'general', 'invitation', 'invitation_accepted', 'invitation_rejected', 'stake_change' or 'completion_dispute'")
 	}
 
 	// Validate invitation-related fields based on type
-	if messageType == "invitation" || messageType == "invitation_accepted" || messageType == "invitation_rejected" {
+	if messageType == "invitation" || messageType == "invitation_accepted" || messageType == "invitation_rejected" || messageType == "stake_change" || messageType == "completion_dispute" {
 		if strings.TrimSpace(invitationGrindID) == "" {
-			return nil, errors.New("invitationGrindID is required for invitation-related messages")
+			return nil, errors.New("invitationGrindID is required for invitation-related, stake_change and completion_dispute messages")
 		}
 	}
 
 	now := time.Now().UTC()
+	var invitationExpiresAt *time.Time
+	if messageType == "invitation" {
+		expiresAt := now.Add(InvitationTTL)
+		invitationExpiresAt = &expiresAt
+	}
 
 	return &Message{
 		ID:                 uuid.New().String(),
@@ -83,5 +111,31 @@ func NewMessage(
 		Read:               false,
 		CreatedAt:          now,
 		UpdatedAt:          now,
+
+		InvitationExpiresAt: invitationExpiresAt,
 	}, nil
 }
+
+/** Reports whether the message is an invitation that has been neither answered nor revoked.
+ * An open invitation may still have expired; see InvitationExpired.
+ */
+func (m *Message) IsOpenInvitation() bool {
+	return m.Type == "invitation" && !m.InvitationAccepted && !m.InvitationRejected && m.InvitationRevokedAt == nil
+}
+
+/** Reports whether the invitation's expiry had passed at the given time */
+func (m *Message) InvitationExpired(at time.Time) bool {
+	return m.InvitationExpiresAt != nil && !at.Before(*m.InvitationExpiresAt)
+}
+
+/** Reports whether the sender withdrew the invitation before it expired */
+func (m *Message) InvitationRevoked() bool {
+	return m.InvitationRevokedAt != nil && !m.InvitationExpired(*m.InvitationRevokedAt)
+}
+
+/** Withdraws the invitation so that the receiver can no longer answer it */
+func (m *Message) RevokeInvitation(at time.Time) {
+	revokedAt := at.UTC()
+	m.InvitationRevokedAt = &revokedAt
+	m.UpdatedAt = revokedAt
+}
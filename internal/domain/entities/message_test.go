@@ -142,3 +142,30 @@ func TestNewMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestMessageInvitationLifecycle(t *testing.T) {
+	t.Parallel()
+
+	invitation, err := NewMessage("sender-1", "receiver-1", "join my grind", "invitation", "grind-1", false, false)
+	require.NoError(t, err)
+	require.NotNil(t, invitation.InvitationExpiresAt)
+	require.Equal(t, invitation.CreatedAt.Add(InvitationTTL), *invitation.InvitationExpiresAt)
+	require.True(t, invitation.IsOpenInvitation())
+	require.False(t, invitation.InvitationExpired(invitation.CreatedAt))
+	require.True(t, invitation.InvitationExpired(*invitation.InvitationExpiresAt))
+
+	general, err := NewMessage("sender-1", "receiver-1", "hello", "general", "", false, false)
+	require.NoError(t, err)
+	require.Nil(t, general.InvitationExpiresAt)
+	require.False(t, general.IsOpenInvitation())
+
+	invitation.RevokeInvitation(invitation.CreatedAt.Add(time.Hour))
+	require.False(t, invitation.IsOpenInvitation())
+	require.True(t, invitation.InvitationRevoked())
+
+	// an invitation replaced after it expired reads as expired, not revoked
+	expired, err := NewMessage("sender-1", "receiver-1", "join my grind", "invitation", "grind-1", false, false)
+	require.NoError(t, err)
+	expired.RevokeInvitation(expired.InvitationExpiresAt.Add(time.Minute))
+	require.False(t, expired.InvitationRevoked())
+}
@@ -0,0 +1,60 @@
+package entities
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// NotificationType names what a Notification tells its user about. It is sent as the SSE
+// event name, so clients can subscribe to the types they render.
+type NotificationType string
+
+const (
+	NotificationTypeMessage          NotificationType = "message"
+	NotificationTypeInvitationStatus NotificationType = "invitation_status"
+	NotificationTypeCompletion       NotificationType = "completion"
+	NotificationTypeSettlement       NotificationType = "settlement"
+)
+
+var validNotificationTypes = map[NotificationType]bool{
+	NotificationTypeMessage:          true,
+	NotificationTypeInvitationStatus: true,
+	NotificationTypeCompletion:       true,
+	NotificationTypeSettlement:       true,
+}
+
+// Notification is a real-time event pushed to one user. Notifications are not stored in
+// the database: the broker keeps a short backlog per user so a reconnecting client can
+// replay what it missed. ID is assigned by the broker when the notification is published
+// and only orders notifications of the same user.
+type Notification struct {
+	ID        string
+	UserID    string
+	Type      NotificationType
+	Data      json.RawMessage
+	CreatedAt time.Time
+}
+
+// NewNotification encodes data as the notification's JSON payload.
+func NewNotification(userID string, notificationType NotificationType, data interface{}) (*Notification, error) {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return nil, errors.New("userID cannot be empty")
+	}
+	if !validNotificationTypes[notificationType] {
+		return nil, errors.New("invalid notification type")
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Notification{
+		UserID:    userID,
+		Type:      notificationType,
+		Data:      encoded,
+		CreatedAt: time.Now().UTC(),
+	}, nil
+}
@@ -0,0 +1,27 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewNotification(t *testing.T) {
+	t.Parallel()
+
+	notification, err := NewNotification(" user-1 ", NotificationTypeCompletion, map[string]string{"grindID": "grind-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", notification.UserID)
+	assert.Equal(t, NotificationTypeCompletion, notification.Type)
+	assert.JSONEq(t, `{"grindID":"grind-1"}`, string(notification.Data))
+	assert.Empty(t, notification.ID)
+	assert.False(t, notification.CreatedAt.IsZero())
+
+	_, err = NewNotification("", NotificationTypeMessage, nil)
+	assert.EqualError(t, err, "userID cannot be empty")
+	_, err = NewNotification("user-1", NotificationType("digest"), nil)
+	assert.EqualError(t, err, "invalid notification type")
+	_, err = NewNotification("user-1", NotificationTypeMessage, func() {})
+	assert.Error(t, err)
+}
@@ -0,0 +1,17 @@
+package entities
+
+import "time"
+
+// PageCursor is a position in a listing ordered newest first by creation time, with ties
+// broken by descending ID. The next page holds the rows strictly before it, so rows
+// created while a client pages through the listing neither shift nor repeat its pages.
+// The zero PageCursor starts at the newest row.
+type PageCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// IsZero reports whether c starts at the newest row.
+func (c PageCursor) IsZero() bool {
+	return c.ID == "" && c.CreatedAt.IsZero()
+}
@@ -0,0 +1,26 @@
+package entities
+
+/** A participant's standing in a grind, aggregated from their habit tasks and participation.
+ * Streaks count consecutive completed habit days in schedule order, so the off days of a
+ * weekdays or dates schedule never break them. Excused weekly-target days are ignored.
+ */
+type ParticipantStats struct {
+	UserID        string
+	Username      string
+	CompletedDays int // completed habit tasks
+	ClosedDays    int // completed or missed habit tasks, i.e. the days that have been decided
+	CurrentStreak int // completed tasks since the most recent missed one
+	LongestStreak int
+	MissedDays    int // missed days that were priced, from the participation
+	FrozenDays    int // missed days covered by a streak-freeze token
+	TotalPenalty  int
+	Quitted       bool
+}
+
+/** Share of the decided habit days that were completed, between 0 and 1; 0 before any day is decided */
+func (s *ParticipantStats) CompletionRate() float64 {
+	if s.ClosedDays == 0 {
+		return 0
+	}
+	return float64(s.CompletedDays) / float64(s.ClosedDays)
+}
@@ -12,9 +12,12 @@ type Participation struct {
 	UserID       string
 	GrindID      string
 	MissedDays   int
+	FrozenDays   int // missed days covered by a streak-freeze token instead of counting as missed
 	TotalPenalty int
 	Quitted      bool
 	QuittedAt    time.Time
+	Finalized    bool // set when the grind is settled; the counters and penalty no longer change
+	FinalizedAt  time.Time
 }
 
 /** Constructor in factory pattern
@@ -32,3 +35,15 @@ func NewParticipation(userID string, grindID string) (*Participation, error) {
 		QuittedAt:    time.Time{},
 	}, nil
 }
+
+/** Freezes the participation for settlement. Returns false when it was already finalized
+ * @param at - the time the grind was settled
+ */
+func (p *Participation) Finalize(at time.Time) bool {
+	if p.Finalized {
+		return false
+	}
+	p.Finalized = true
+	p.FinalizedAt = at.UTC()
+	return true
+}
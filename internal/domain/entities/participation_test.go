@@ -2,6 +2,7 @@ package entities
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -35,3 +36,24 @@ func TestNewParticipation(t *testing.T) {
 		t.Fatalf("expected zero QuittedAt for new participation")
 	}
 }
+
+func TestParticipationFinalize(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewParticipation("user-1", "grind-1")
+	require.NoError(t, err)
+
+	at := time.Date(2026, 5, 1, 9, 0, 0, 0, time.FixedZone("UTC+2", 2*60*60))
+	if !p.Finalize(at) {
+		t.Fatalf("expected first Finalize to succeed")
+	}
+	if !p.Finalized || !p.FinalizedAt.Equal(at) {
+		t.Fatalf("expected participation finalized at %v, got %v (%v)", at, p.FinalizedAt, p.Finalized)
+	}
+	if p.Finalize(at.Add(time.Hour)) {
+		t.Fatalf("expected second Finalize to be a no-op")
+	}
+	if !p.FinalizedAt.Equal(at) {
+		t.Fatalf("expected FinalizedAt to keep the first settlement time, got %v", p.FinalizedAt)
+	}
+}
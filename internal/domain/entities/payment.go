@@ -72,8 +72,14 @@ const (
 	SettlementStatusFailed         SettlementStatus = "failed"
 	SettlementStatusRefunded       SettlementStatus = "refunded"
 	SettlementStatusSettledOnChain SettlementStatus = "settled_onchain"
+	SettlementStatusNeedsReview    SettlementStatus = "needs_review" // the outcome is unknown; an operator must check the provider
 )
 
+// IsSettled reports whether money actually moved for a settlement in this status.
+func (s SettlementStatus) IsSettled() bool {
+	return s == SettlementStatusCaptured || s == SettlementStatusSettledOnChain
+}
+
 // SettlementReference captures provider-neutral references used to reconcile settlements.
 type SettlementReference struct {
 	ProviderReference string `json:"provider_reference" gorm:""`
@@ -87,6 +93,7 @@ type SettlementReference struct {
 type PaymentSettlement struct {
 	ID              uint                `json:"id"`
 	UserID          string              `json:"user_id"`
+	GrindID         string              `json:"grind_id"` // set for legs of an end-of-grind settlement
 	Operation       string              `json:"operation"`
 	IdempotencyKey  string              `json:"idempotency_key"`
 	Provider        PaymentProvider     `json:"provider"`
@@ -106,3 +106,21 @@ func TestPaymentProviderConstants(t *testing.T) {
 		t.Fatalf("unexpected solana provider constant")
 	}
 }
+
+func TestSettlementStatusIsSettled(t *testing.T) {
+	t.Parallel()
+
+	settled := map[SettlementStatus]bool{
+		SettlementStatusPending:        false,
+		SettlementStatusAuthorized:     false,
+		SettlementStatusCaptured:       true,
+		SettlementStatusFailed:         false,
+		SettlementStatusRefunded:       false,
+		SettlementStatusSettledOnChain: true,
+	}
+	for status, want := range settled {
+		if got := status.IsSettled(); got != want {
+			t.Fatalf("expected %q IsSettled() = %v, got %v", status, want, got)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package entities
+
+import "errors"
+
+/** How each penalized missed day is priced */
+type PenaltyKind string
+
+const (
+	PenaltyKindFlat       PenaltyKind = "flat"       // every penalized day costs Amount
+	PenaltyKindEscalating PenaltyKind = "escalating" // the n-th penalized day costs Amount + (n-1)*Increment
+)
+
+/** Per-grind rules for turning missed days into a penalty.
+ * Kind prices each penalized day; GraceDays, FreezeTokens and CapAtBudget decide which
+ * days are penalized and how much can accrue. Applied by the PenaltyPolicyService.
+ */
+type PenaltyPolicy struct {
+	Kind         PenaltyKind
+	Amount       int  // flat: cost of every penalized day; escalating: cost of the first one
+	Increment    int  // escalating only: extra cost for each further penalized day
+	GraceDays    int  // the first GraceDays missed days are free
+	FreezeTokens int  // missed days each participant may freeze; frozen days are neither missed nor penalized
+	CapAtBudget  bool // TotalPenalty never exceeds the grind budget
+}
+
+/** Constructor in factory pattern
+ * @param kind - flat or escalating
+ * @param amount - cost of a penalized day (the first one, when escalating)
+ * @param increment - escalating step; must be 0 for flat policies
+ * @param graceDays - number of free missed days
+ * @param freezeTokens - number of streak-freeze tokens per participant
+ * @param capAtBudget - whether the accrued penalty is capped at the grind budget
+ */
+func NewPenaltyPolicy(kind PenaltyKind, amount, increment, graceDays, freezeTokens int, capAtBudget bool) (PenaltyPolicy, error) {
+	switch kind {
+	case PenaltyKindFlat:
+		if increment != 0 {
+			return PenaltyPolicy{}, errors.New("increment is only allowed for escalating policies")
+		}
+	case PenaltyKindEscalating:
+	default:
+		return PenaltyPolicy{}, errors.New("unknown penalty kind: " + string(kind))
+	}
+	if amount < 0 || increment < 0 {
+		return PenaltyPolicy{}, errors.New("penalty amounts cannot be negative")
+	}
+	if graceDays < 0 || freezeTokens < 0 {
+		return PenaltyPolicy{}, errors.New("grace days and freeze tokens cannot be negative")
+	}
+
+	return PenaltyPolicy{
+		Kind:         kind,
+		Amount:       amount,
+		Increment:    increment,
+		GraceDays:    graceDays,
+		FreezeTokens: freezeTokens,
+		CapAtBudget:  capAtBudget,
+	}, nil
+}
+
+/** The policy grinds get when the creator does not pick one: the budget spread evenly
+ * over the duration, charged flat per missed day and capped at the budget.
+ */
+func DefaultPenaltyPolicy(duration int, budget int) PenaltyPolicy {
+	amount := 0
+	if duration > 0 {
+		amount = budget / duration
+	}
+	return PenaltyPolicy{
+		Kind:        PenaltyKindFlat,
+		Amount:      amount,
+		CapAtBudget: true,
+	}
+}
+
+/** Streak-freeze tokens the participation can still spend under this policy */
+func (p PenaltyPolicy) FreezeTokensLeft(participation *Participation) int {
+	left := p.FreezeTokens - participation.FrozenDays
+	if left < 0 {
+		return 0
+	}
+	return left
+}
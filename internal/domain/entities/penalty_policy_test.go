@@ -0,0 +1,66 @@
+package entities
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPenaltyPolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		kind         PenaltyKind
+		amount       int
+		increment    int
+		graceDays    int
+		freezeTokens int
+		wantErr      bool
+		errContains  string
+	}{
+		{name: "flat policy", kind: PenaltyKindFlat, amount: 10},
+		{name: "escalating policy with grace and freezes", kind: PenaltyKindEscalating, amount: 5, increment: 5, graceDays: 1, freezeTokens: 2},
+		{name: "rejects unknown kind", kind: "lottery", amount: 10, wantErr: true, errContains: "unknown penalty kind"},
+		{name: "rejects increment on flat policy", kind: PenaltyKindFlat, amount: 10, increment: 5, wantErr: true, errContains: "only allowed for escalating"},
+		{name: "rejects negative amount", kind: PenaltyKindFlat, amount: -1, wantErr: true, errContains: "cannot be negative"},
+		{name: "rejects negative grace days", kind: PenaltyKindFlat, amount: 10, graceDays: -1, wantErr: true, errContains: "cannot be negative"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			policy, err := NewPenaltyPolicy(tt.kind, tt.amount, tt.increment, tt.graceDays, tt.freezeTokens, true)
+			if tt.wantErr {
+				require.Error(t, err)
+				require.True(t, strings.Contains(err.Error(), tt.errContains), "unexpected error: %v", err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.kind, policy.Kind)
+			require.Equal(t, tt.amount, policy.Amount)
+			require.Equal(t, tt.freezeTokens, policy.FreezeTokens)
+			require.True(t, policy.CapAtBudget)
+		})
+	}
+}
+
+func TestDefaultPenaltyPolicy(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, PenaltyPolicy{Kind: PenaltyKindFlat, Amount: 10, CapAtBudget: true}, DefaultPenaltyPolicy(30, 300))
+	require.Equal(t, 0, DefaultPenaltyPolicy(0, 300).Amount)
+}
+
+func TestPenaltyPolicyFreezeTokensLeft(t *testing.T) {
+	t.Parallel()
+
+	policy := PenaltyPolicy{Kind: PenaltyKindFlat, FreezeTokens: 2}
+
+	require.Equal(t, 2, policy.FreezeTokensLeft(&Participation{}))
+	require.Equal(t, 1, policy.FreezeTokensLeft(&Participation{FrozenDays: 1}))
+	require.Equal(t, 0, policy.FreezeTokensLeft(&Participation{FrozenDays: 3}))
+}
@@ -0,0 +1,112 @@
+package entities
+
+import (
+	"errors"
+)
+
+/** The study plans a roadmap grind can follow */
+const (
+	RoadmapNeetcode250 = "neetcode250"
+)
+
+/** How a roadmap's problems are spread over the grind's habit days */
+type RoadmapOrder string
+
+const (
+	RoadmapOrderTag    RoadmapOrder = "tag"    // the roadmap's own order, topic by topic; Tags can pick and reorder topics
+	RoadmapOrderCustom RoadmapOrder = "custom" // exactly the problems listed in Slugs, in that order
+)
+
+/** One problem of a study plan */
+type RoadmapProblem struct {
+	ID   int    // LeetCode problem number
+	Slug string // LeetCode title slug, e.g. "two-sum"
+	Tag  string // topic, e.g. "Arrays & Hashing"
+}
+
+/** A LeetCode study plan a grind follows: every habit day is assigned the next problem of the plan */
+type Roadmap struct {
+	Name  string
+	Order RoadmapOrder
+	Tags  []string // tag order only: the topics to include, in order; empty keeps every topic
+	Slugs []string // custom order only: the problems to solve, in order
+}
+
+/** Constructor in factory pattern
+ * @param name - the study plan, e.g. neetcode250
+ * @param order - tag or custom; empty means tag
+ * @param tags - tag order only; the topics to include, in order
+ * @param slugs - custom order only; the problems to solve, in order
+ */
+func NewRoadmap(name string, order RoadmapOrder, tags, slugs []string) (*Roadmap, error) {
+	if name != RoadmapNeetcode250 {
+		return nil, errors.New("roadmap must be " + RoadmapNeetcode250)
+	}
+	if order == "" {
+		order = RoadmapOrderTag
+	}
+	switch order {
+	case RoadmapOrderTag:
+		if len(slugs) != 0 {
+			return nil, errors.New("slugs are only allowed for custom order")
+		}
+	case RoadmapOrderCustom:
+		if len(slugs) == 0 {
+			return nil, errors.New("a custom order needs at least one slug")
+		}
+		if len(tags) != 0 {
+			return nil, errors.New("tags are only allowed for tag order")
+		}
+	default:
+		return nil, errors.New("roadmap order must be tag or custom")
+	}
+	return &Roadmap{Name: name, Order: order, Tags: tags, Slugs: slugs}, nil
+}
+
+/** Picks and orders the problems of catalog, the roadmap's full problem list, that this roadmap assigns.
+ * Fails when a listed tag or slug is not part of the catalog or is listed twice.
+ */
+func (r *Roadmap) Plan(catalog []RoadmapProblem) ([]RoadmapProblem, error) {
+	if r.Order == RoadmapOrderCustom {
+		bySlug := make(map[string]RoadmapProblem, len(catalog))
+		for _, problem := range catalog {
+			bySlug[problem.Slug] = problem
+		}
+		plan := make([]RoadmapProblem, 0, len(r.Slugs))
+		seen := make(map[string]bool, len(r.Slugs))
+		for _, slug := range r.Slugs {
+			problem, ok := bySlug[slug]
+			if !ok {
+				return nil, errors.New("problem " + slug + " is not part of " + r.Name)
+			}
+			if seen[slug] {
+				return nil, errors.New("problem " + slug + " is listed twice")
+			}
+			seen[slug] = true
+			plan = append(plan, problem)
+		}
+		return plan, nil
+	}
+
+	if len(r.Tags) == 0 {
+		return append([]RoadmapProblem(nil), catalog...), nil
+	}
+	byTag := make(map[string][]RoadmapProblem)
+	for _, problem := range catalog {
+		byTag[problem.Tag] = append(byTag[problem.Tag], problem)
+	}
+	plan := make([]RoadmapProblem, 0, len(catalog))
+	seen := make(map[string]bool, len(r.Tags))
+	for _, tag := range r.Tags {
+		problems, ok := byTag[tag]
+		if !ok {
+			return nil, errors.New("tag " + tag + " is not part of " + r.Name)
+		}
+		if seen[tag] {
+			return nil, errors.New("tag " + tag + " is listed twice")
+		}
+		seen[tag] = true
+		plan = append(plan, problems...)
+	}
+	return plan, nil
+}
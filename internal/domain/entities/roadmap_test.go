@@ -0,0 +1,76 @@
+package entities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func roadmapCatalog() []RoadmapProblem {
+	return []RoadmapProblem{
+		{ID: 1, Slug: "two-sum", Tag: "Arrays & Hashing"},
+		{ID: 49, Slug: "group-anagrams", Tag: "Arrays & Hashing"},
+		{ID: 125, Slug: "valid-palindrome", Tag: "Two Pointers"},
+		{ID: 20, Slug: "valid-parentheses", Tag: "Stack"},
+	}
+}
+
+func TestNewRoadmap(t *testing.T) {
+	t.Parallel()
+
+	roadmap, err := NewRoadmap(RoadmapNeetcode250, "", nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, RoadmapOrderTag, roadmap.Order)
+
+	_, err = NewRoadmap("blind75", RoadmapOrderTag, nil, nil)
+	require.ErrorContains(t, err, "roadmap must be")
+	_, err = NewRoadmap(RoadmapNeetcode250, RoadmapOrderCustom, nil, nil)
+	require.ErrorContains(t, err, "at least one slug")
+	_, err = NewRoadmap(RoadmapNeetcode250, RoadmapOrderTag, nil, []string{"two-sum"})
+	require.ErrorContains(t, err, "only allowed for custom order")
+	_, err = NewRoadmap(RoadmapNeetcode250, "random", nil, nil)
+	require.ErrorContains(t, err, "tag or custom")
+}
+
+func TestRoadmapPlan(t *testing.T) {
+	t.Parallel()
+
+	slugs := func(plan []RoadmapProblem) []string {
+		out := make([]string, len(plan))
+		for i, p := range plan {
+			out[i] = p.Slug
+		}
+		return out
+	}
+
+	plan, err := (&Roadmap{Name: RoadmapNeetcode250, Order: RoadmapOrderTag}).Plan(roadmapCatalog())
+	require.NoError(t, err)
+	require.Equal(t, []string{"two-sum", "group-anagrams", "valid-palindrome", "valid-parentheses"}, slugs(plan))
+
+	plan, err = (&Roadmap{Name: RoadmapNeetcode250, Order: RoadmapOrderTag, Tags: []string{"Stack", "Arrays & Hashing"}}).Plan(roadmapCatalog())
+	require.NoError(t, err)
+	require.Equal(t, []string{"valid-parentheses", "two-sum", "group-anagrams"}, slugs(plan))
+
+	plan, err = (&Roadmap{Name: RoadmapNeetcode250, Order: RoadmapOrderCustom, Slugs: []string{"valid-palindrome", "two-sum"}}).Plan(roadmapCatalog())
+	require.NoError(t, err)
+	require.Equal(t, []string{"valid-palindrome", "two-sum"}, slugs(plan))
+
+	_, err = (&Roadmap{Name: RoadmapNeetcode250, Order: RoadmapOrderTag, Tags: []string{"Graphs"}}).Plan(roadmapCatalog())
+	require.ErrorContains(t, err, "is not part of")
+	_, err = (&Roadmap{Name: RoadmapNeetcode250, Order: RoadmapOrderCustom, Slugs: []string{"two-sum", "two-sum"}}).Plan(roadmapCatalog())
+	require.ErrorContains(t, err, "listed twice")
+}
+
+func TestHabitTaskAssignProblem(t *testing.T) {
+	t.Parallel()
+
+	task, err := NewHabitTask("user-1", "grind-1", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Empty(t, task.AssignedProblemSlug())
+
+	task.AssignProblem(RoadmapNeetcode250, roadmapCatalog()[1])
+	require.Equal(t, HabitTaskTypeLeetCode, task.TaskType)
+	require.Equal(t, "group-anagrams", task.AssignedProblemSlug())
+	require.JSONEq(t, `{"roadmap":"neetcode250","problemId":49,"slug":"group-anagrams","tag":"Arrays & Hashing"}`, string(task.Metadata))
+}
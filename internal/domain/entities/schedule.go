@@ -0,0 +1,183 @@
+package entities
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+/** Which days of a grind are habit days */
+type ScheduleKind string
+
+const (
+	ScheduleKindDaily        ScheduleKind = "daily"         // every day of the grind
+	ScheduleKindWeekdays     ScheduleKind = "weekdays"      // Monday to Friday
+	ScheduleKindWeeklyTarget ScheduleKind = "weekly_target" // any TimesPerWeek days of each ISO week
+	ScheduleKindDates        ScheduleKind = "dates"         // only the listed calendar days
+)
+
+/** The shape of a grind's habit days inside its StartDate + Duration window.
+ * Daily, weekdays and dates schedules get one task per scheduled day and every open task
+ * is missed when its day ends. A weekly target gets a task every day; each ISO week is
+ * evaluated once it ends and only the shortfall against TimesPerWeek is missed.
+ */
+type Schedule struct {
+	Kind         ScheduleKind
+	TimesPerWeek int         // weekly_target only: completions required per ISO week
+	Dates        []time.Time // dates only: calendar days at UTC midnight, sorted and unique
+}
+
+/** The schedule of grinds that do not pick one: a task every day */
+func DailySchedule() Schedule {
+	return Schedule{Kind: ScheduleKindDaily}
+}
+
+/** Constructor in factory pattern
+ * @param kind - daily, weekdays, weekly_target or dates; empty means daily
+ * @param timesPerWeek - weekly_target only, between 1 and 7
+ * @param dates - dates only; the calendar day of each value is kept, duplicates are dropped
+ */
+func NewSchedule(kind ScheduleKind, timesPerWeek int, dates []time.Time) (Schedule, error) {
+	if kind == "" {
+		kind = ScheduleKindDaily
+	}
+	switch kind {
+	case ScheduleKindDaily, ScheduleKindWeekdays:
+	case ScheduleKindWeeklyTarget:
+		if timesPerWeek < 1 || timesPerWeek > 7 {
+			return Schedule{}, errors.New("timesPerWeek must be between 1 and 7")
+		}
+	case ScheduleKindDates:
+		if len(dates) == 0 {
+			return Schedule{}, errors.New("a dates schedule needs at least one date")
+		}
+	default:
+		return Schedule{}, errors.New("schedule kind must be daily, weekdays, weekly_target or dates")
+	}
+	if kind != ScheduleKindWeeklyTarget && timesPerWeek != 0 {
+		return Schedule{}, errors.New("timesPerWeek is only allowed for weekly_target schedules")
+	}
+	if kind != ScheduleKindDates && len(dates) != 0 {
+		return Schedule{}, errors.New("dates are only allowed for dates schedules")
+	}
+
+	return Schedule{Kind: kind, TimesPerWeek: timesPerWeek, Dates: normalizeScheduleDates(dates)}, nil
+}
+
+func normalizeScheduleDates(dates []time.Time) []time.Time {
+	if len(dates) == 0 {
+		return nil
+	}
+	seen := make(map[time.Time]bool, len(dates))
+	days := make([]time.Time, 0, len(dates))
+	for _, date := range dates {
+		y, m, d := date.Date()
+		day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+		if !seen[day] {
+			seen[day] = true
+			days = append(days, day)
+		}
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	return days
+}
+
+/** Reports whether two schedules describe the same days */
+func (s Schedule) Equal(other Schedule) bool {
+	if s.Kind != other.Kind || s.TimesPerWeek != other.TimesPerWeek || len(s.Dates) != len(other.Dates) {
+		return false
+	}
+	for i := range s.Dates {
+		if !s.Dates[i].Equal(other.Dates[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+/** Reports whether the grind's day-th day (0-based) gets a habit task */
+func (g *Grind) IsScheduledDay(day int) bool {
+	if day < 0 || day >= int(g.Duration) {
+		return false
+	}
+	switch g.Schedule.Kind {
+	case ScheduleKindWeekdays:
+		weekday := g.DayStart(day, time.UTC).Weekday()
+		return weekday != time.Saturday && weekday != time.Sunday
+	case ScheduleKindDates:
+		date := g.DayStart(day, time.UTC)
+		i := sort.Search(len(g.Schedule.Dates), func(i int) bool { return !g.Schedule.Dates[i].Before(date) })
+		return i < len(g.Schedule.Dates) && g.Schedule.Dates[i].Equal(date)
+	default:
+		return true
+	}
+}
+
+/** The 0-based days of the grind that get a habit task, in order */
+func (g *Grind) ScheduledDays() []int {
+	days := make([]int, 0, g.Duration)
+	for i := 0; i < int(g.Duration); i++ {
+		if g.IsScheduledDay(i) {
+			days = append(days, i)
+		}
+	}
+	return days
+}
+
+/** The 0-based grind day of t's calendar date in loc; negative before the first day */
+func (g *Grind) DayIndex(t time.Time, loc *time.Location) int {
+	y, m, d := t.In(loc).Date()
+	return int(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Sub(g.DayStart(0, time.UTC)).Hours() / 24)
+}
+
+/** For weekly targets: the first and last grind day of the ISO week containing day,
+ * clipped to the grind, and the completions required in it. A partial week at either
+ * end of the grind never requires more completions than it has days.
+ */
+func (g *Grind) WeekWindow(day int) (first, last, target int) {
+	// days since Monday, with Sunday as the 7th day of the ISO week
+	offset := (int(g.DayStart(day, time.UTC).Weekday()) + 6) % 7
+	first = day - offset
+	last = first + 6
+	if first < 0 {
+		first = 0
+	}
+	if last > int(g.Duration)-1 {
+		last = int(g.Duration) - 1
+	}
+	target = g.Schedule.TimesPerWeek
+	if days := last - first + 1; target > days {
+		target = days
+	}
+	return first, last, target
+}
+
+/** The number of habit days a participant must complete to finish the grind clean */
+func (g *Grind) RequiredDays() int {
+	if g.Schedule.Kind != ScheduleKindWeeklyTarget {
+		return len(g.ScheduledDays())
+	}
+	required := 0
+	for day := 0; day < int(g.Duration); {
+		_, last, target := g.WeekWindow(day)
+		required += target
+		day = last + 1
+	}
+	return required
+}
+
+/** Checks that the schedule leaves at least one habit day inside the grind and that every listed date falls within it */
+func (g *Grind) ValidateSchedule() error {
+	if g.Schedule.Kind == ScheduleKindDates {
+		first, end := g.DayStart(0, time.UTC), g.EndDate()
+		for _, date := range g.Schedule.Dates {
+			if date.Before(first) || !date.Before(end) {
+				return errors.New("schedule date " + date.Format("2006-01-02") + " is outside the grind")
+			}
+		}
+	}
+	if g.RequiredDays() == 0 {
+		return errors.New("the schedule has no habit days inside the grind")
+	}
+	return nil
+}
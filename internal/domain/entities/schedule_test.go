@@ -0,0 +1,134 @@
+package entities
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSchedule(t *testing.T) {
+	t.Parallel()
+
+	apr6 := time.Date(2026, 4, 6, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name         string
+		kind         ScheduleKind
+		timesPerWeek int
+		dates        []time.Time
+		errContains  string
+	}{
+		{name: "empty kind is daily"},
+		{name: "weekdays", kind: ScheduleKindWeekdays},
+		{name: "three times per week", kind: ScheduleKindWeeklyTarget, timesPerWeek: 3},
+		{name: "explicit dates", kind: ScheduleKindDates, dates: []time.Time{apr6}},
+		{name: "rejects unknown kind", kind: "fortnightly", errContains: "schedule kind must be"},
+		{name: "rejects weekly target above 7", kind: ScheduleKindWeeklyTarget, timesPerWeek: 8, errContains: "between 1 and 7"},
+		{name: "rejects timesPerWeek on daily", kind: ScheduleKindDaily, timesPerWeek: 3, errContains: "only allowed for weekly_target"},
+		{name: "rejects empty dates", kind: ScheduleKindDates, errContains: "at least one date"},
+		{name: "rejects dates on weekdays", kind: ScheduleKindWeekdays, dates: []time.Time{apr6}, errContains: "only allowed for dates"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			schedule, err := NewSchedule(tt.kind, tt.timesPerWeek, tt.dates)
+			if tt.errContains != "" {
+				require.Error(t, err)
+				require.True(t, strings.Contains(err.Error(), tt.errContains), "unexpected error: %v", err)
+				return
+			}
+			require.NoError(t, err)
+			if tt.kind == "" {
+				require.Equal(t, ScheduleKindDaily, schedule.Kind)
+			}
+		})
+	}
+}
+
+func TestNewScheduleNormalizesDates(t *testing.T) {
+	t.Parallel()
+
+	taipei := time.FixedZone("UTC+8", 8*60*60)
+	schedule, err := NewSchedule(ScheduleKindDates, 0, []time.Time{
+		time.Date(2026, 4, 9, 23, 0, 0, 0, taipei),
+		time.Date(2026, 4, 7, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 4, 9, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	require.Equal(t, []time.Time{
+		time.Date(2026, 4, 7, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 4, 9, 0, 0, 0, 0, time.UTC),
+	}, schedule.Dates)
+}
+
+func TestGrindScheduledDays(t *testing.T) {
+	t.Parallel()
+
+	// Wednesday 2026-04-01 to Tuesday 2026-04-14
+	start := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	daily := &Grind{Duration: 14, StartDate: start, Schedule: DailySchedule()}
+	require.Len(t, daily.ScheduledDays(), 14)
+	require.Equal(t, 14, daily.RequiredDays())
+
+	weekdays := &Grind{Duration: 14, StartDate: start, Schedule: Schedule{Kind: ScheduleKindWeekdays}}
+	// skips Apr 4-5 and Apr 11-12
+	require.Equal(t, []int{0, 1, 2, 5, 6, 7, 8, 9, 12, 13}, weekdays.ScheduledDays())
+
+	dates := &Grind{Duration: 14, StartDate: start, Schedule: Schedule{
+		Kind:  ScheduleKindDates,
+		Dates: []time.Time{start.AddDate(0, 0, 2), start.AddDate(0, 0, 9)},
+	}}
+	require.Equal(t, []int{2, 9}, dates.ScheduledDays())
+	require.NoError(t, dates.ValidateSchedule())
+
+	dates.Schedule.Dates = append(dates.Schedule.Dates, start.AddDate(0, 0, 14))
+	require.ErrorContains(t, dates.ValidateSchedule(), "outside the grind")
+}
+
+func TestGrindWeekWindow(t *testing.T) {
+	t.Parallel()
+
+	// Wednesday 2026-04-01 to Tuesday 2026-04-14: a 5-day, a 7-day and a 2-day ISO week
+	grind := &Grind{
+		Duration:  14,
+		StartDate: time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+		Schedule:  Schedule{Kind: ScheduleKindWeeklyTarget, TimesPerWeek: 3},
+	}
+
+	tests := []struct {
+		day                   int
+		first, last, required int
+	}{
+		{day: 0, first: 0, last: 4, required: 3},
+		{day: 4, first: 0, last: 4, required: 3},
+		{day: 5, first: 5, last: 11, required: 3},
+		{day: 11, first: 5, last: 11, required: 3},
+		{day: 13, first: 12, last: 13, required: 2},
+	}
+	for _, tt := range tests {
+		first, last, target := grind.WeekWindow(tt.day)
+		require.Equal(t, tt.first, first, "first day of the week containing day %d", tt.day)
+		require.Equal(t, tt.last, last, "last day of the week containing day %d", tt.day)
+		require.Equal(t, tt.required, target, "target of the week containing day %d", tt.day)
+	}
+
+	require.Len(t, grind.ScheduledDays(), 14)
+	require.Equal(t, 8, grind.RequiredDays())
+}
+
+func TestGrindDayIndex(t *testing.T) {
+	t.Parallel()
+
+	taipei, err := time.LoadLocation("Asia/Taipei")
+	require.NoError(t, err)
+	grind := &Grind{Duration: 7, StartDate: time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)}
+
+	require.Equal(t, 0, grind.DayIndex(time.Date(2026, 4, 1, 0, 0, 0, 0, taipei), taipei))
+	require.Equal(t, 3, grind.DayIndex(grind.DayStart(3, taipei), taipei))
+	require.Equal(t, -1, grind.DayIndex(time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC), time.UTC))
+}
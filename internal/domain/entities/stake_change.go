@@ -0,0 +1,117 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+/** Lifecycle of a proposed change to a grind's stake */
+type StakeChangeStatus string
+
+const (
+	StakeChangePending    StakeChangeStatus = "pending"    // waiting for the other participants
+	StakeChangeApplied    StakeChangeStatus = "applied"    // everyone agreed; the grind was updated
+	StakeChangeRejected   StakeChangeStatus = "rejected"   // a participant said no
+	StakeChangeSuperseded StakeChangeStatus = "superseded" // replaced by a newer proposal
+)
+
+/** A proposed change to what is at stake in a grind (budget and penalty policy).
+ * The stake is money every participant agreed to, so a change only takes effect once
+ * every active participant has approved it. The proposer approves implicitly.
+ */
+type StakeChange struct {
+	ID            string
+	GrindID       string
+	ProposerID    string
+	Budget        int32
+	PenaltyPolicy PenaltyPolicy
+	ApprovedBy    []string // user IDs, starting with the proposer
+	RejectedBy    string
+	Status        StakeChangeStatus
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+/** Constructor in factory pattern
+ * @param grindID - the grind whose stake changes
+ * @param proposerID - the participant proposing the change
+ * @param budget - the proposed budget in dollars
+ * @param policy - the proposed penalty policy
+ */
+func NewStakeChange(grindID, proposerID string, budget int, policy PenaltyPolicy) (*StakeChange, error) {
+	if grindID == "" {
+		return nil, errors.New("grindID cannot be empty")
+	}
+	if proposerID == "" {
+		return nil, errors.New("proposerID cannot be empty")
+	}
+	if budget < 0 {
+		return nil, errors.New("budget cannot be negative")
+	}
+
+	now := time.Now().UTC()
+	return &StakeChange{
+		ID:            uuid.New().String(),
+		GrindID:       grindID,
+		ProposerID:    proposerID,
+		Budget:        int32(budget),
+		PenaltyPolicy: policy,
+		ApprovedBy:    []string{proposerID},
+		Status:        StakeChangePending,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}, nil
+}
+
+/** Records userID's approval. Approving twice is a no-op */
+func (c *StakeChange) Approve(userID string, at time.Time) error {
+	if c.Status != StakeChangePending {
+		return errors.New("stake change is " + string(c.Status))
+	}
+	if !c.IsApprovedBy(userID) {
+		c.ApprovedBy = append(c.ApprovedBy, userID)
+		c.UpdatedAt = at.UTC()
+	}
+	return nil
+}
+
+/** Closes the proposal because userID declined it */
+func (c *StakeChange) Reject(userID string, at time.Time) error {
+	if c.Status != StakeChangePending {
+		return errors.New("stake change is " + string(c.Status))
+	}
+	c.Status = StakeChangeRejected
+	c.RejectedBy = userID
+	c.UpdatedAt = at.UTC()
+	return nil
+}
+
+/** Moves a pending proposal to status; used for applied and superseded */
+func (c *StakeChange) Close(status StakeChangeStatus, at time.Time) {
+	if c.Status == StakeChangePending {
+		c.Status = status
+		c.UpdatedAt = at.UTC()
+	}
+}
+
+/** Reports whether userID has approved the change */
+func (c *StakeChange) IsApprovedBy(userID string) bool {
+	for _, id := range c.ApprovedBy {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+/** Reports whether every one of participantIDs has approved the change */
+func (c *StakeChange) IsApprovedByAll(participantIDs []string) bool {
+	for _, id := range participantIDs {
+		if !c.IsApprovedBy(id) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,45 @@
+package entities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewStakeChange_ProposerApprovesImplicitly(t *testing.T) {
+	change, err := NewStakeChange("grind-1", "user-1", 50, DefaultPenaltyPolicy(5, 50))
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, change.ID)
+	assert.Equal(t, StakeChangePending, change.Status)
+	assert.Equal(t, []string{"user-1"}, change.ApprovedBy)
+	assert.True(t, change.IsApprovedByAll([]string{"user-1"}))
+	assert.False(t, change.IsApprovedByAll([]string{"user-1", "user-2"}))
+}
+
+func Test_NewStakeChange_NegativeBudget(t *testing.T) {
+	change, err := NewStakeChange("grind-1", "user-1", -1, PenaltyPolicy{})
+	require.Error(t, err)
+	assert.Nil(t, change)
+}
+
+func Test_StakeChange_ApproveAndReject(t *testing.T) {
+	at := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	change, err := NewStakeChange("grind-1", "user-1", 50, PenaltyPolicy{})
+	require.NoError(t, err)
+
+	require.NoError(t, change.Approve("user-2", at))
+	require.NoError(t, change.Approve("user-2", at))
+	assert.Equal(t, []string{"user-1", "user-2"}, change.ApprovedBy)
+	assert.True(t, change.IsApprovedByAll([]string{"user-1", "user-2"}))
+
+	require.NoError(t, change.Reject("user-3", at))
+	assert.Equal(t, StakeChangeRejected, change.Status)
+	assert.Equal(t, "user-3", change.RejectedBy)
+	assert.Error(t, change.Approve("user-3", at), "a closed proposal cannot be approved")
+
+	change.Close(StakeChangeApplied, at)
+	assert.Equal(t, StakeChangeRejected, change.Status, "Close only affects pending proposals")
+}
@@ -2,9 +2,13 @@ package entities
 
 import (
 	"errors"
+	"regexp"
 	"strings"
+	"time"
+	_ "time/tzdata" // timezone names must resolve even on images without a zoneinfo database
 
 	"github.com/google/uuid"
+	"golang.org/x/text/language"
 )
 
 /** Represents a user account **/
@@ -16,8 +20,17 @@ type User struct {
 	HashedPassword         string
 	StripeCustomerID       string
 	DefaultPaymentMethodID string
+	Timezone               string // IANA name, e.g. "Asia/Taipei"; habit days run midnight-to-midnight here
+	LeetCodeUsername       string // public LeetCode profile whose accepted submissions back leetcode completions
+	Locale                 string // BCP 47 tag, e.g. "zh-TW"; system messages sent to the user are rendered in it
 }
 
+// DefaultTimezone is assigned to users who have not picked a timezone.
+const DefaultTimezone = "UTC"
+
+// DefaultLocale is assigned to users who have not picked a locale.
+const DefaultLocale = "en"
+
 /** Constructor in factory pattern
  * @param username - the username
  * @param email - the email address
@@ -46,5 +59,67 @@ func NewUser(username, email, hashedPassword, avatar string) (*User, error) {
 		Email:          strings.ToLower(strings.TrimSpace(email)),
 		Avatar:         strings.TrimSpace(avatar),
 		HashedPassword: hashedPassword,
+		Timezone:       DefaultTimezone,
+		Locale:         DefaultLocale,
 	}, nil
 }
+
+/** Validates an IANA timezone name
+ * @param name - the timezone name, e.g. "America/New_York"
+ */
+func ValidateTimezone(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("timezone cannot be empty")
+	}
+	// time.LoadLocation resolves "Local" to the server's zone, which Postgres does not know
+	if name == "Local" {
+		return errors.New("invalid timezone: " + name)
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		return errors.New("invalid timezone: " + name)
+	}
+	return nil
+}
+
+/** Validates a BCP 47 locale tag
+ * @param tag - the locale, e.g. "en" or "zh-TW"
+ */
+func ValidateLocale(tag string) error {
+	if strings.TrimSpace(tag) == "" {
+		return errors.New("locale cannot be empty")
+	}
+	if _, err := language.Parse(tag); err != nil {
+		return errors.New("invalid locale: " + tag)
+	}
+	return nil
+}
+
+var leetCodeUsernamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,30}$`)
+
+/** Validates a LeetCode username; empty unlinks the profile
+ * @param name - the username as shown in the profile URL, e.g. leetcode.com/u/<name>
+ */
+func ValidateLeetCodeUsername(name string) error {
+	if name != "" && !leetCodeUsernamePattern.MatchString(name) {
+		return errors.New("invalid LeetCode username: " + name)
+	}
+	return nil
+}
+
+/** The user's location for day boundaries; falls back to UTC when unset or unknown */
+func (u *User) Location() *time.Location {
+	if u.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(u.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+/** Local midnight of the calendar day containing t, in loc */
+func LocalDayStart(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
@@ -3,6 +3,7 @@ package entities
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -95,3 +96,51 @@ func TestNewUser(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateTimezone(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, ValidateTimezone("Asia/Taipei"))
+	require.NoError(t, ValidateTimezone("UTC"))
+	require.Error(t, ValidateTimezone(""))
+	require.Error(t, ValidateTimezone("Mars/Olympus_Mons"))
+	require.Error(t, ValidateTimezone("Local"))
+}
+
+func TestValidateLocale(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, ValidateLocale("en"))
+	require.NoError(t, ValidateLocale("zh-TW"))
+	require.Error(t, ValidateLocale(""))
+	require.Error(t, ValidateLocale("not a locale"))
+
+	user, err := NewUser("alice", "alice@example.com", "hashed-secret", "")
+	require.NoError(t, err)
+	require.Equal(t, DefaultLocale, user.Locale)
+}
+
+func TestValidateLeetCodeUsername(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, ValidateLeetCodeUsername("neetcode"))
+	require.NoError(t, ValidateLeetCodeUsername("lee_215-x"))
+	require.NoError(t, ValidateLeetCodeUsername(""))
+	require.Error(t, ValidateLeetCodeUsername("has space"))
+	require.Error(t, ValidateLeetCodeUsername("../admin"))
+}
+
+func TestUserLocation(t *testing.T) {
+	t.Parallel()
+
+	user, err := NewUser("alice", "alice@example.com", "hashed-secret", "")
+	require.NoError(t, err)
+	require.Equal(t, DefaultTimezone, user.Timezone)
+	require.Equal(t, time.UTC, user.Location())
+
+	user.Timezone = "Asia/Taipei"
+	require.Equal(t, "Asia/Taipei", user.Location().String())
+
+	user.Timezone = "not/a-zone"
+	require.Equal(t, time.UTC, user.Location())
+}
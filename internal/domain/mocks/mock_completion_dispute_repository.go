@@ -0,0 +1,63 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockCompletionDisputeRepository is a testify mock implementation of repositories.CompletionDisputeRepository.
+type MockCompletionDisputeRepository struct {
+	mock.Mock
+}
+
+func (m *MockCompletionDisputeRepository) Create(dispute *entities.CompletionDispute) error {
+	args := m.Called(dispute)
+	return args.Error(0)
+}
+
+func (m *MockCompletionDisputeRepository) FindByID(id string) (*entities.CompletionDispute, error) {
+	args := m.Called(id)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entities.CompletionDispute), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockCompletionDisputeRepository) FindByIDForUpdate(id string) (*entities.CompletionDispute, error) {
+	args := m.Called(id)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entities.CompletionDispute), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockCompletionDisputeRepository) FindByCompletionEventID(completionEventID string) (*entities.CompletionDispute, error) {
+	args := m.Called(completionEventID)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entities.CompletionDispute), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockCompletionDisputeRepository) FindByGrindID(grindID string) ([]*entities.CompletionDispute, error) {
+	args := m.Called(grindID)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entities.CompletionDispute), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockCompletionDisputeRepository) FindExpired(at time.Time) ([]*entities.CompletionDispute, error) {
+	args := m.Called(at)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entities.CompletionDispute), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockCompletionDisputeRepository) Update(dispute *entities.CompletionDispute) (bool, error) {
+	args := m.Called(dispute)
+	return args.Bool(0), args.Error(1)
+}
@@ -15,6 +15,27 @@ func (m *MockCompletionEventRepository) Create(event *entities.CompletionEvent)
 	return args.Error(0)
 }
 
+func (m *MockCompletionEventRepository) CreateIfAbsent(event *entities.CompletionEvent) (bool, error) {
+	args := m.Called(event)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockCompletionEventRepository) FindByDedupKey(userID, grindID string, provider entities.CompletionProvider, dedupKey string) (*entities.CompletionEvent, error) {
+	args := m.Called(userID, grindID, provider, dedupKey)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entities.CompletionEvent), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockCompletionEventRepository) FindByID(id string) (*entities.CompletionEvent, error) {
+	args := m.Called(id)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entities.CompletionEvent), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func (m *MockCompletionEventRepository) FindByHabitTaskID(habitTaskID string) ([]*entities.CompletionEvent, error) {
 	args := m.Called(habitTaskID)
 	if args.Get(0) != nil {
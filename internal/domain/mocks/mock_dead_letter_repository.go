@@ -0,0 +1,37 @@
+package mocks
+
+import (
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockDeadLetterRepository is a testify mock implementation of repositories.DeadLetterRepository.
+type MockDeadLetterRepository struct {
+	mock.Mock
+}
+
+func (m *MockDeadLetterRepository) Create(letter *entities.DeadLetter) error {
+	args := m.Called(letter)
+	return args.Error(0)
+}
+
+func (m *MockDeadLetterRepository) FindByID(id string) (*entities.DeadLetter, error) {
+	args := m.Called(id)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entities.DeadLetter), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockDeadLetterRepository) FindByUserID(userID string) ([]*entities.DeadLetter, error) {
+	args := m.Called(userID)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entities.DeadLetter), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockDeadLetterRepository) Delete(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
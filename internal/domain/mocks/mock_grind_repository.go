@@ -1,6 +1,8 @@
 package mocks
 
 import (
+	"time"
+
 	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
 	"github.com/stretchr/testify/mock"
 )
@@ -22,8 +24,16 @@ func (m *MockGrindRepository) FindById(id string) (*entities.Grind, error) {
 	return nil, args.Error(1)
 }
 
-func (m *MockGrindRepository) FindAllByUserID(userID string) ([]*entities.Grind, error) {
-	args := m.Called(userID)
+func (m *MockGrindRepository) FindAllByUserID(userID string, states []entities.GrindState, after entities.PageCursor, limit int) ([]*entities.Grind, error) {
+	args := m.Called(userID, states, after, limit)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entities.Grind), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockGrindRepository) FindAllByStates(states []entities.GrindState) ([]*entities.Grind, error) {
+	args := m.Called(states)
 	if args.Get(0) != nil {
 		return args.Get(0).([]*entities.Grind), args.Error(1)
 	}
@@ -43,6 +53,11 @@ func (m *MockGrindRepository) Update(grind *entities.Grind) error {
 	return args.Error(0)
 }
 
+func (m *MockGrindRepository) UpdateState(grind *entities.Grind, from entities.GrindState) (bool, error) {
+	args := m.Called(grind, from)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockGrindRepository) Delete(id string) error {
 	args := m.Called(id)
 	return args.Error(0)
@@ -60,3 +75,11 @@ func (m *MockGrindRepository) FindDuedGrinds() ([]*entities.Grind, error) {
 	}
 	return nil, args.Error(1)
 }
+
+func (m *MockGrindRepository) FindActiveGrinds(day time.Time) ([]*entities.Grind, error) {
+	args := m.Called(day)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entities.Grind), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -0,0 +1,19 @@
+package mocks
+
+import (
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockGrindStatsRepository is a testify mock implementation of repositories.GrindStatsRepository.
+type MockGrindStatsRepository struct {
+	mock.Mock
+}
+
+func (m *MockGrindStatsRepository) FindParticipantStats(grindID string) ([]*entities.ParticipantStats, error) {
+	args := m.Called(grindID)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entities.ParticipantStats), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
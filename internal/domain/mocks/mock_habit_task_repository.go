@@ -1,6 +1,8 @@
 package mocks
 
 import (
+	"time"
+
 	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
 	"github.com/stretchr/testify/mock"
 )
@@ -15,6 +17,11 @@ func (m *MockHabitTaskRepository) Create(task *entities.HabitTask) error {
 	return args.Error(0)
 }
 
+func (m *MockHabitTaskRepository) CreateBatch(tasks []*entities.HabitTask) error {
+	args := m.Called(tasks)
+	return args.Error(0)
+}
+
 func (m *MockHabitTaskRepository) FindByID(id string) (*entities.HabitTask, error) {
 	args := m.Called(id)
 	if args.Get(0) != nil {
@@ -31,14 +38,50 @@ func (m *MockHabitTaskRepository) FindByGrindIDAndUserID(grindID, userID string)
 	return nil, args.Error(1)
 }
 
-func (m *MockHabitTaskRepository) FindTodayTask(userID, grindID string) (*entities.HabitTask, error) {
-	args := m.Called(userID, grindID)
+func (m *MockHabitTaskRepository) FindTodayTask(userID, grindID string, loc *time.Location) (*entities.HabitTask, error) {
+	args := m.Called(userID, grindID, loc)
 	if args.Get(0) != nil {
 		return args.Get(0).(*entities.HabitTask), args.Error(1)
 	}
 	return nil, args.Error(1)
 }
 
+func (m *MockHabitTaskRepository) FindTaskOnDay(userID, grindID string, at time.Time, loc *time.Location) (*entities.HabitTask, error) {
+	args := m.Called(userID, grindID, at, loc)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entities.HabitTask), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockHabitTaskRepository) FindByGrindIDInRange(grindID string, from, to time.Time) ([]*entities.HabitTask, error) {
+	args := m.Called(grindID, from, to)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entities.HabitTask), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockHabitTaskRepository) MarkMissed(taskID string) (bool, error) {
+	args := m.Called(taskID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockHabitTaskRepository) MarkExcused(taskID string) (bool, error) {
+	args := m.Called(taskID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockHabitTaskRepository) Complete(taskID string, finishedAt time.Time) (bool, error) {
+	args := m.Called(taskID, finishedAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockHabitTaskRepository) RevertCompletion(taskID string, excuse bool) (bool, error) {
+	args := m.Called(taskID, excuse)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockHabitTaskRepository) Update(task *entities.HabitTask) error {
 	args := m.Called(task)
 	return args.Error(0)
@@ -56,3 +99,13 @@ func (m *MockHabitTaskRepository) DeleteByGrindID(grindID string) error {
 	args := m.Called(grindID)
 	return args.Error(0)
 }
+
+func (m *MockHabitTaskRepository) DeleteByIDs(ids []string) error {
+	args := m.Called(ids)
+	return args.Error(0)
+}
+
+func (m *MockHabitTaskRepository) RebaseDates(userID, fromTimezone, toTimezone string) error {
+	args := m.Called(userID, fromTimezone, toTimezone)
+	return args.Error(0)
+}
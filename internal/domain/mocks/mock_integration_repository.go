@@ -0,0 +1,37 @@
+package mocks
+
+import (
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockIntegrationRepository is a testify mock implementation of repositories.IntegrationRepository.
+type MockIntegrationRepository struct {
+	mock.Mock
+}
+
+func (m *MockIntegrationRepository) Create(integration *entities.Integration) error {
+	args := m.Called(integration)
+	return args.Error(0)
+}
+
+func (m *MockIntegrationRepository) FindByID(id string) (*entities.Integration, error) {
+	args := m.Called(id)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entities.Integration), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockIntegrationRepository) FindByOwnerID(ownerID string) ([]*entities.Integration, error) {
+	args := m.Called(ownerID)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entities.Integration), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockIntegrationRepository) Delete(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
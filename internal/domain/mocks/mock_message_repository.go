@@ -22,23 +22,80 @@ func (m *MockMessageRepository) FindByID(id string) (*entities.Message, error) {
 	return nil, args.Error(1)
 }
 
-func (m *MockMessageRepository) FindAllForReceiver(receiverID string, offset, limit int) ([]*entities.Message, error) {
-	args := m.Called(receiverID, offset, limit)
+func (m *MockMessageRepository) FindAllForReceiver(receiverID string, filter entities.MessageFilter, after entities.PageCursor, limit int) ([]*entities.Message, error) {
+	args := m.Called(receiverID, filter, after, limit)
 	if args.Get(0) != nil {
 		return args.Get(0).([]*entities.Message), args.Error(1)
 	}
 	return nil, args.Error(1)
 }
 
-func (m *MockMessageRepository) FindAllFromSender(senderID string, offset, limit int) ([]*entities.Message, error) {
-	args := m.Called(senderID, offset, limit)
+func (m *MockMessageRepository) FindAllFromSender(senderID string, filter entities.MessageFilter, after entities.PageCursor, limit int) ([]*entities.Message, error) {
+	args := m.Called(senderID, filter, after, limit)
 	if args.Get(0) != nil {
 		return args.Get(0).([]*entities.Message), args.Error(1)
 	}
 	return nil, args.Error(1)
 }
 
+func (m *MockMessageRepository) CountForReceiver(receiverID string, filter entities.MessageFilter) (int64, error) {
+	args := m.Called(receiverID, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockMessageRepository) CreateInvitationIfAbsent(invitation *entities.Message) (bool, error) {
+	args := m.Called(invitation)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMessageRepository) FindOpenInvitation(grindID, receiverID string) (*entities.Message, error) {
+	args := m.Called(grindID, receiverID)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entities.Message), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func (m *MockMessageRepository) Update(message *entities.Message) error {
 	args := m.Called(message)
 	return args.Error(0)
 }
+
+func (m *MockMessageRepository) FindConversations(userID string, after entities.PageCursor, limit int) ([]*entities.Conversation, error) {
+	args := m.Called(userID, after, limit)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entities.Conversation), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockMessageRepository) FindConversationMessages(userID, counterpartID string, after entities.PageCursor, limit int) ([]*entities.Message, error) {
+	args := m.Called(userID, counterpartID, after, limit)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entities.Message), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockMessageRepository) MarkConversationRead(userID, counterpartID string) (int64, error) {
+	args := m.Called(userID, counterpartID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockMessageRepository) FindConversationSetting(userID, counterpartID string) (*entities.ConversationSetting, error) {
+	args := m.Called(userID, counterpartID)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entities.ConversationSetting), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockMessageRepository) CreateConversationSetting(setting *entities.ConversationSetting) error {
+	args := m.Called(setting)
+	return args.Error(0)
+}
+
+func (m *MockMessageRepository) UpdateConversationSetting(setting *entities.ConversationSetting) error {
+	args := m.Called(setting)
+	return args.Error(0)
+}
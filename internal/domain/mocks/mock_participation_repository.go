@@ -30,6 +30,22 @@ func (m *MockParticipationRepository) FindByUserAndGrind(userID, grindID string)
 	return nil, args.Error(1)
 }
 
+func (m *MockParticipationRepository) FindByUserAndGrindForUpdate(userID, grindID string) (*entities.Participation, error) {
+	args := m.Called(userID, grindID)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entities.Participation), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockParticipationRepository) FindByGrindID(grindID string) ([]*entities.Participation, error) {
+	args := m.Called(grindID)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entities.Participation), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func (m *MockParticipationRepository) Update(participation *entities.Participation) error {
 	args := m.Called(participation)
 	return args.Error(0)
@@ -0,0 +1,19 @@
+package mocks
+
+import (
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockRoadmapRepository is a testify mock implementation of repositories.RoadmapRepository.
+type MockRoadmapRepository struct {
+	mock.Mock
+}
+
+func (m *MockRoadmapRepository) FindProblems(name string) ([]entities.RoadmapProblem, error) {
+	args := m.Called(name)
+	if args.Get(0) != nil {
+		return args.Get(0).([]entities.RoadmapProblem), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
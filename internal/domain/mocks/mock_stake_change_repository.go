@@ -0,0 +1,45 @@
+package mocks
+
+import (
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockStakeChangeRepository is a testify mock implementation of repositories.StakeChangeRepository.
+type MockStakeChangeRepository struct {
+	mock.Mock
+}
+
+func (m *MockStakeChangeRepository) Create(change *entities.StakeChange) error {
+	args := m.Called(change)
+	return args.Error(0)
+}
+
+func (m *MockStakeChangeRepository) FindByID(id string) (*entities.StakeChange, error) {
+	args := m.Called(id)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entities.StakeChange), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockStakeChangeRepository) FindByIDForUpdate(id string) (*entities.StakeChange, error) {
+	args := m.Called(id)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entities.StakeChange), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockStakeChangeRepository) FindPendingByGrindID(grindID string) (*entities.StakeChange, error) {
+	args := m.Called(grindID)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entities.StakeChange), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockStakeChangeRepository) Update(change *entities.StakeChange) error {
+	args := m.Called(change)
+	return args.Error(0)
+}
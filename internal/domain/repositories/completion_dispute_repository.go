@@ -0,0 +1,23 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+)
+
+// CompletionDisputeRepository defines persistence operations for CompletionDispute domain entities.
+type CompletionDisputeRepository interface {
+	Create(dispute *entities.CompletionDispute) error
+	FindByID(id string) (*entities.CompletionDispute, error)
+	// FindByIDForUpdate is FindByID that also locks the row until the transaction ends, so
+	// concurrent votes on the same dispute are counted one after the other.
+	FindByIDForUpdate(id string) (*entities.CompletionDispute, error)
+	// FindByCompletionEventID returns the event's dispute, or nil when it was never disputed.
+	FindByCompletionEventID(completionEventID string) (*entities.CompletionDispute, error)
+	// FindByGrindID returns the grind's disputes, newest first.
+	FindByGrindID(grindID string) ([]*entities.CompletionDispute, error)
+	// FindExpired returns the open disputes whose voting window ended at or before at.
+	FindExpired(at time.Time) ([]*entities.CompletionDispute, error)
+	Update(dispute *entities.CompletionDispute) (bool, error) // false when the stored dispute is no longer open
+}
@@ -7,6 +7,13 @@ import (
 // CompletionEventRepository defines persistence operations for CompletionEvent domain entities.
 type CompletionEventRepository interface {
 	Create(event *entities.CompletionEvent) error
+	// CreateIfAbsent creates the event and reports false, without an error, when the user
+	// already has an event in the same grind with the same provider and DedupKey.
+	CreateIfAbsent(event *entities.CompletionEvent) (bool, error)
+	// FindByDedupKey returns the user's event in the grind with the provider and key, or nil
+	// when there is none.
+	FindByDedupKey(userID, grindID string, provider entities.CompletionProvider, dedupKey string) (*entities.CompletionEvent, error)
+	FindByID(id string) (*entities.CompletionEvent, error)
 	FindByHabitTaskID(habitTaskID string) ([]*entities.CompletionEvent, error)
 	FindByUserIDAndProvider(userID string, provider entities.CompletionProvider) ([]*entities.CompletionEvent, error)
 }
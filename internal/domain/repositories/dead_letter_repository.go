@@ -0,0 +1,15 @@
+package repositories
+
+import (
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+)
+
+// DeadLetterRepository defines persistence operations for completion signals ingest could
+// not record.
+type DeadLetterRepository interface {
+	Create(letter *entities.DeadLetter) error
+	FindByID(id string) (*entities.DeadLetter, error)
+	// FindByUserID returns the user's dead letters, newest first.
+	FindByUserID(userID string) ([]*entities.DeadLetter, error)
+	Delete(id string) error
+}
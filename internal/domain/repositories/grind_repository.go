@@ -1,6 +1,8 @@
 package repositories
 
 import (
+	"time"
+
 	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
 )
 
@@ -8,10 +10,15 @@ import (
 type GrindRepository interface {
 	Create(grind *entities.Grind) error
 	FindById(id string) (*entities.Grind, error)
-	FindAllByUserID(userID string) ([]*entities.Grind, error)
+	// FindAllByUserID returns the user's grinds in any of states, newest first, starting after
+	// the cursor. Empty states matches every state; a limit <= 0 returns every remaining grind.
+	FindAllByUserID(userID string, states []entities.GrindState, after entities.PageCursor, limit int) ([]*entities.Grind, error)
+	FindAllByStates(states []entities.GrindState) ([]*entities.Grind, error)
 	FindLatestByUserID(userID string) (*entities.Grind, error)
 	Update(grind *entities.Grind) error
+	UpdateState(grind *entities.Grind, from entities.GrindState) (bool, error) // false when the stored state is no longer from
 	Delete(id string) error
 	DeleteAll() error
 	FindDuedGrinds() ([]*entities.Grind, error)
+	FindActiveGrinds(day time.Time) ([]*entities.Grind, error)
 }
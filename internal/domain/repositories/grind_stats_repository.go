@@ -0,0 +1,11 @@
+package repositories
+
+import (
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+)
+
+// GrindStatsRepository computes read-only statistics over a grind's tasks and participations.
+type GrindStatsRepository interface {
+	// FindParticipantStats returns one entry per participant of the grind, quitters included.
+	FindParticipantStats(grindID string) ([]*entities.ParticipantStats, error)
+}
@@ -1,18 +1,45 @@
 package repositories
 
 import (
+	"time"
+
 	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
 )
 
 // HabitTaskRepository defines persistence operations for HabitTask domain entities.
 type HabitTaskRepository interface {
 	Create(task *entities.HabitTask) error
+	// CreateBatch inserts all tasks with as few round-trips as possible; an empty slice is a no-op.
+	CreateBatch(tasks []*entities.HabitTask) error
 	FindByID(id string) (*entities.HabitTask, error)
 	FindByGrindIDAndUserID(grindID, userID string) ([]*entities.HabitTask, error)
 	// FindByGrindIDAndParticipantID is an alias for FindByGrindIDAndUserID that returns
 	// a value slice ([]entities.HabitTask) for compatibility with the GrindService.
 	FindByGrindIDAndParticipantID(grindID, participantID string) ([]entities.HabitTask, error)
-	FindTodayTask(userID, grindID string) (*entities.HabitTask, error)
+	// FindTodayTask returns the user's task for the current day as seen in loc.
+	FindTodayTask(userID, grindID string, loc *time.Location) (*entities.HabitTask, error)
+	// FindTaskOnDay returns the user's task for the day that contains at, as seen in loc.
+	FindTaskOnDay(userID, grindID string, at time.Time, loc *time.Location) (*entities.HabitTask, error)
+	// FindByGrindIDInRange returns every participant's task in the grind dated in [from, to).
+	FindByGrindIDInRange(grindID string, from, to time.Time) ([]*entities.HabitTask, error)
 	Update(task *entities.HabitTask) error
+	// Complete marks a task completed at finishedAt unless it was missed or excused in the
+	// meantime, e.g. by the missed-day evaluator, in which case it reports false. A task
+	// that is already completed keeps its finish time.
+	Complete(taskID string, finishedAt time.Time) (bool, error)
+	// MarkMissed flags an uncompleted, not-yet-missed task as missed. It reports false when
+	// the task was already missed or completed so callers can avoid double-counting.
+	MarkMissed(taskID string) (bool, error)
+	// MarkExcused closes an open task without a penalty, e.g. a day beyond its week's
+	// target. It reports false when the task was already completed, missed or excused.
+	MarkExcused(taskID string) (bool, error)
+	// RevertCompletion clears a completed task's completion, e.g. when partners overturn it,
+	// and flags it as missed, or as excused when excuse is set. It reports false when the
+	// task was not completed.
+	RevertCompletion(taskID string, excuse bool) (bool, error)
 	DeleteByGrindID(grindID string) error
+	DeleteByIDs(ids []string) error
+	// RebaseDates moves every task of the user to local midnight in toTimezone, keeping
+	// the calendar day each task had in fromTimezone.
+	RebaseDates(userID, fromTimezone, toTimezone string) error
 }
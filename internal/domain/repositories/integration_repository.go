@@ -0,0 +1,13 @@
+package repositories
+
+import (
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+)
+
+// IntegrationRepository defines persistence operations for custom provider integrations.
+type IntegrationRepository interface {
+	Create(integration *entities.Integration) error
+	FindByID(id string) (*entities.Integration, error)
+	FindByOwnerID(ownerID string) ([]*entities.Integration, error)
+	Delete(id string) error
+}
@@ -8,7 +8,36 @@ import (
 type MessageRepository interface {
 	Create(message *entities.Message) error
 	FindByID(id string) (*entities.Message, error)
-	FindAllForReceiver(receiverID string, offset, limit int) ([]*entities.Message, error)
-	FindAllFromSender(senderID string, offset, limit int) ([]*entities.Message, error)
+	// FindAllForReceiver returns the messages receiverID received that match filter, newest
+	// first, starting after the cursor. A limit <= 0 returns every remaining message.
+	FindAllForReceiver(receiverID string, filter entities.MessageFilter, after entities.PageCursor, limit int) ([]*entities.Message, error)
+	// FindAllFromSender is FindAllForReceiver for the messages senderID sent.
+	FindAllFromSender(senderID string, filter entities.MessageFilter, after entities.PageCursor, limit int) ([]*entities.Message, error)
+	// CountForReceiver counts the messages receiverID received that match filter.
+	CountForReceiver(receiverID string, filter entities.MessageFilter) (int64, error)
 	Update(message *entities.Message) error
+
+	// CreateInvitationIfAbsent creates the invitation and reports false, without an error,
+	// when its receiver already holds an open invitation to the same grind.
+	CreateInvitationIfAbsent(invitation *entities.Message) (bool, error)
+	// FindOpenInvitation returns the invitation to grindID that receiverID has neither
+	// answered nor seen revoked, expired or not, or nil when there is none.
+	FindOpenInvitation(grindID, receiverID string) (*entities.Message, error)
+
+	// FindConversations returns userID's conversations of general messages, the most
+	// recently active first, starting after the cursor, which is the position of a
+	// conversation's last message. A limit <= 0 returns every remaining conversation.
+	FindConversations(userID string, after entities.PageCursor, limit int) ([]*entities.Conversation, error)
+	// FindConversationMessages returns the general messages between userID and
+	// counterpartID, newest first, starting after the cursor. A limit <= 0 returns every
+	// remaining message.
+	FindConversationMessages(userID, counterpartID string, after entities.PageCursor, limit int) ([]*entities.Message, error)
+	// MarkConversationRead marks the general messages counterpartID sent userID as read and
+	// returns how many were unread.
+	MarkConversationRead(userID, counterpartID string) (int64, error)
+	// FindConversationSetting returns userID's setting for their conversation with
+	// counterpartID, or nil when they never changed it.
+	FindConversationSetting(userID, counterpartID string) (*entities.ConversationSetting, error)
+	CreateConversationSetting(setting *entities.ConversationSetting) error
+	UpdateConversationSetting(setting *entities.ConversationSetting) error
 }
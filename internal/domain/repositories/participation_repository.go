@@ -8,6 +8,11 @@ type ParticipationRepository interface {
 	Create(*entities.Participation) error
 	FindByParticipationId(ParticipationID string) (*entities.Participation, error)
 	FindByUserAndGrind(userID string, grindID string) (*entities.Participation, error)
+	// FindByUserAndGrindForUpdate is FindByUserAndGrind that also locks the row until the
+	// transaction ends, so concurrent penalties, quits and settlement are applied one after
+	// the other instead of overwriting each other's counters.
+	FindByUserAndGrindForUpdate(userID string, grindID string) (*entities.Participation, error)
+	FindByGrindID(grindID string) ([]*entities.Participation, error)
 	Update(participation *entities.Participation) error
 	DeleteByGrindID(grindID string) error
 }
@@ -14,4 +14,5 @@ type PaymentSettlementRepository interface {
 	Update(settlement *entities.PaymentSettlement) (*entities.PaymentSettlement, error)
 	FindByOperationAndKey(operation string, idempotencyKey string) (*entities.PaymentSettlement, error)
 	FindByStatuses(statuses []entities.SettlementStatus, limit int) ([]entities.PaymentSettlement, error)
+	FindByGrindID(grindID string) ([]entities.PaymentSettlement, error)
 }
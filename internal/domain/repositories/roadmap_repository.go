@@ -0,0 +1,11 @@
+package repositories
+
+import (
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+)
+
+// RoadmapRepository looks up the problem lists of the study plans roadmap grinds follow.
+type RoadmapRepository interface {
+	// FindProblems returns every problem of the named roadmap in the roadmap's own order.
+	FindProblems(name string) ([]entities.RoadmapProblem, error)
+}
@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+)
+
+// StakeChangeRepository defines persistence operations for StakeChange domain entities.
+type StakeChangeRepository interface {
+	Create(change *entities.StakeChange) error
+	FindByID(id string) (*entities.StakeChange, error)
+	// FindByIDForUpdate is FindByID that also locks the row until the transaction ends, so
+	// concurrent answers to the same proposal are applied one after the other.
+	FindByIDForUpdate(id string) (*entities.StakeChange, error)
+	// FindPendingByGrindID returns the grind's open proposal, or nil when there is none.
+	FindPendingByGrindID(grindID string) (*entities.StakeChange, error)
+	// Update stores the answers and status of a pending proposal; a closed one is final.
+	Update(change *entities.StakeChange) error
+}
@@ -0,0 +1,58 @@
+// Package services holds domain services: stateless business rules that span several
+// entities and therefore belong to none of them.
+package services
+
+import "github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+
+// PenaltyPolicyService prices missed days under a grind's PenaltyPolicy.
+// The accrued penalty is always recomputed from the participation's counters, so
+// replaying or correcting a day can never drift from what the policy says.
+type PenaltyPolicyService struct{}
+
+func NewPenaltyPolicyService() *PenaltyPolicyService {
+	return &PenaltyPolicyService{}
+}
+
+// DayCost returns what the n-th penalized missed day (1-based) costs under policy.
+func (s *PenaltyPolicyService) DayCost(policy entities.PenaltyPolicy, n int) int {
+	if n <= 0 {
+		return 0
+	}
+	if policy.Kind == entities.PenaltyKindEscalating {
+		return policy.Amount + (n-1)*policy.Increment
+	}
+	return policy.Amount
+}
+
+// Accrued returns the total penalty for missedDays missed (not frozen) days.
+func (s *PenaltyPolicyService) Accrued(policy entities.PenaltyPolicy, missedDays int, budget int) int {
+	penalized := missedDays - policy.GraceDays
+	if penalized <= 0 {
+		return 0
+	}
+
+	total := penalized * policy.Amount
+	if policy.Kind == entities.PenaltyKindEscalating {
+		total += policy.Increment * penalized * (penalized - 1) / 2
+	}
+	if policy.CapAtBudget && total > budget {
+		return budget
+	}
+	return total
+}
+
+// RecordMissedDay applies one missed day to participation. A remaining streak-freeze
+// token is spent first and the day costs nothing; otherwise the day counts as missed and
+// TotalPenalty is recomputed. It returns the penalty added and whether the day was frozen.
+func (s *PenaltyPolicyService) RecordMissedDay(grind *entities.Grind, participation *entities.Participation) (int, bool) {
+	policy := grind.PenaltyPolicy
+	if policy.FreezeTokensLeft(participation) > 0 {
+		participation.FrozenDays++
+		return 0, true
+	}
+
+	before := participation.TotalPenalty
+	participation.MissedDays++
+	participation.TotalPenalty = s.Accrued(policy, participation.MissedDays, int(grind.Budget))
+	return participation.TotalPenalty - before, false
+}
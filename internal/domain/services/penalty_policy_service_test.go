@@ -0,0 +1,185 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPenaltyPolicyServiceAccrued(t *testing.T) {
+	t.Parallel()
+
+	svc := NewPenaltyPolicyService()
+
+	tests := []struct {
+		name       string
+		policy     entities.PenaltyPolicy
+		missedDays int
+		budget     int
+		want       int
+	}{
+		{
+			name:       "flat charges every missed day",
+			policy:     entities.PenaltyPolicy{Kind: entities.PenaltyKindFlat, Amount: 10},
+			missedDays: 3,
+			budget:     100,
+			want:       30,
+		},
+		{
+			name:       "no missed days accrue nothing",
+			policy:     entities.PenaltyPolicy{Kind: entities.PenaltyKindFlat, Amount: 10},
+			missedDays: 0,
+			budget:     100,
+			want:       0,
+		},
+		{
+			name:       "escalating adds the increment for each further day",
+			policy:     entities.PenaltyPolicy{Kind: entities.PenaltyKindEscalating, Amount: 5, Increment: 5},
+			missedDays: 3,
+			budget:     100,
+			want:       5 + 10 + 15,
+		},
+		{
+			name:       "uncapped flat may exceed the budget",
+			policy:     entities.PenaltyPolicy{Kind: entities.PenaltyKindFlat, Amount: 40},
+			missedDays: 3,
+			budget:     100,
+			want:       120,
+		},
+		{
+			name:       "capped flat stops at the budget",
+			policy:     entities.PenaltyPolicy{Kind: entities.PenaltyKindFlat, Amount: 40, CapAtBudget: true},
+			missedDays: 3,
+			budget:     100,
+			want:       100,
+		},
+		{
+			name:       "capped escalating stops at the budget",
+			policy:     entities.PenaltyPolicy{Kind: entities.PenaltyKindEscalating, Amount: 10, Increment: 20, CapAtBudget: true},
+			missedDays: 4,
+			budget:     100,
+			want:       100,
+		},
+		{
+			name:       "grace days are free",
+			policy:     entities.PenaltyPolicy{Kind: entities.PenaltyKindFlat, Amount: 10, GraceDays: 2},
+			missedDays: 2,
+			budget:     100,
+			want:       0,
+		},
+		{
+			name:       "grace days are skipped before pricing",
+			policy:     entities.PenaltyPolicy{Kind: entities.PenaltyKindFlat, Amount: 10, GraceDays: 2},
+			missedDays: 5,
+			budget:     100,
+			want:       30,
+		},
+		{
+			name:       "escalation starts after the grace days",
+			policy:     entities.PenaltyPolicy{Kind: entities.PenaltyKindEscalating, Amount: 10, Increment: 10, GraceDays: 1},
+			missedDays: 3,
+			budget:     100,
+			want:       10 + 20,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, svc.Accrued(tt.policy, tt.missedDays, tt.budget))
+		})
+	}
+}
+
+func TestPenaltyPolicyServiceRecordMissedDay(t *testing.T) {
+	t.Parallel()
+
+	svc := NewPenaltyPolicyService()
+
+	tests := []struct {
+		name             string
+		policy           entities.PenaltyPolicy
+		budget           int32
+		start            entities.Participation
+		wantAdded        int
+		wantFrozen       bool
+		wantMissedDays   int
+		wantFrozenDays   int
+		wantTotalPenalty int
+	}{
+		{
+			name:             "flat day accrues the amount",
+			policy:           entities.PenaltyPolicy{Kind: entities.PenaltyKindFlat, Amount: 10, CapAtBudget: true},
+			budget:           100,
+			start:            entities.Participation{MissedDays: 1, TotalPenalty: 10},
+			wantAdded:        10,
+			wantMissedDays:   2,
+			wantTotalPenalty: 20,
+		},
+		{
+			name:             "escalating day costs more than the last",
+			policy:           entities.PenaltyPolicy{Kind: entities.PenaltyKindEscalating, Amount: 10, Increment: 5},
+			budget:           100,
+			start:            entities.Participation{MissedDays: 2, TotalPenalty: 25},
+			wantAdded:        20,
+			wantMissedDays:   3,
+			wantTotalPenalty: 45,
+		},
+		{
+			name:             "cap limits the added amount",
+			policy:           entities.PenaltyPolicy{Kind: entities.PenaltyKindFlat, Amount: 30, CapAtBudget: true},
+			budget:           100,
+			start:            entities.Participation{MissedDays: 3, TotalPenalty: 90},
+			wantAdded:        10,
+			wantMissedDays:   4,
+			wantTotalPenalty: 100,
+		},
+		{
+			name:             "grace day counts as missed but costs nothing",
+			policy:           entities.PenaltyPolicy{Kind: entities.PenaltyKindFlat, Amount: 10, GraceDays: 1},
+			budget:           100,
+			start:            entities.Participation{},
+			wantAdded:        0,
+			wantMissedDays:   1,
+			wantTotalPenalty: 0,
+		},
+		{
+			name:           "freeze token is spent before counting the day",
+			policy:         entities.PenaltyPolicy{Kind: entities.PenaltyKindFlat, Amount: 10, FreezeTokens: 2},
+			budget:         100,
+			start:          entities.Participation{FrozenDays: 1},
+			wantFrozen:     true,
+			wantFrozenDays: 2,
+		},
+		{
+			name:             "exhausted freeze tokens fall back to the penalty",
+			policy:           entities.PenaltyPolicy{Kind: entities.PenaltyKindFlat, Amount: 10, FreezeTokens: 2},
+			budget:           100,
+			start:            entities.Participation{FrozenDays: 2},
+			wantAdded:        10,
+			wantMissedDays:   1,
+			wantFrozenDays:   2,
+			wantTotalPenalty: 10,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			grind := &entities.Grind{ID: "grind-1", Budget: tt.budget, PenaltyPolicy: tt.policy}
+			participation := tt.start
+
+			added, frozen := svc.RecordMissedDay(grind, &participation)
+
+			require.Equal(t, tt.wantAdded, added)
+			require.Equal(t, tt.wantFrozen, frozen)
+			require.Equal(t, tt.wantMissedDays, participation.MissedDays)
+			require.Equal(t, tt.wantFrozenDays, participation.FrozenDays)
+			require.Equal(t, tt.wantTotalPenalty, participation.TotalPenalty)
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package blob
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// DefaultDir is where blobs are kept when no directory is configured.
+const DefaultDir = "data/blobs"
+
+// validKey keeps keys to a single path segment so they cannot escape the store's root.
+var validKey = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,199}$`)
+
+// FilesystemStore keeps each blob as a file named after its key in one directory. Writes go
+// through a temporary file that is renamed into place, so readers never see partial content.
+type FilesystemStore struct {
+	root string
+}
+
+// NewFilesystemStore creates root if needed; an empty root uses DefaultDir.
+func NewFilesystemStore(root string) (*FilesystemStore, error) {
+	if root == "" {
+		root = DefaultDir
+	}
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create blob directory: %w", err)
+	}
+	return &FilesystemStore{root: root}, nil
+}
+
+func (s *FilesystemStore) path(key string) (string, error) {
+	if !validKey.MatchString(key) {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.root, key), nil
+}
+
+func (s *FilesystemStore) Put(key string, content io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.root, ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	if _, err := io.Copy(tmp, content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *FilesystemStore) Open(key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+// Delete removes the blob; deleting a missing blob is not an error.
+func (s *FilesystemStore) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package blob
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilesystemStore_PutOpenDelete(t *testing.T) {
+	t.Parallel()
+
+	store, err := NewFilesystemStore(t.TempDir())
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("evidence-1", strings.NewReader("proof")))
+	reader, err := store.Open("evidence-1")
+	require.NoError(t, err)
+	content, err := io.ReadAll(reader)
+	require.NoError(t, reader.Close())
+	require.NoError(t, err)
+	assert.Equal(t, "proof", string(content))
+
+	// a second Put replaces the content
+	require.NoError(t, store.Put("evidence-1", strings.NewReader("better proof")))
+	reader, err = store.Open("evidence-1")
+	require.NoError(t, err)
+	content, _ = io.ReadAll(reader)
+	reader.Close()
+	assert.Equal(t, "better proof", string(content))
+
+	require.NoError(t, store.Delete("evidence-1"))
+	require.NoError(t, store.Delete("evidence-1"))
+	_, err = store.Open("evidence-1")
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+
+	entries, err := os.ReadDir(store.root)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "no temporary files are left behind")
+}
+
+func TestFilesystemStore_RejectsKeysOutsideRoot(t *testing.T) {
+	t.Parallel()
+
+	store, err := NewFilesystemStore(t.TempDir())
+	require.NoError(t, err)
+
+	for _, key := range []string{"", "../escape", "a/b", ".hidden", "/etc/passwd"} {
+		assert.Error(t, store.Put(key, strings.NewReader("x")), key)
+		_, err := store.Open(key)
+		assert.Error(t, err, key)
+	}
+}
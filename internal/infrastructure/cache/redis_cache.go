@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisCache is a small byte cache on top of the shared Redis client. Like the rate
+// limiter it fails open: when Redis is missing or unavailable every Get is a miss and
+// every Set is dropped, so callers fall back to computing the value.
+type RedisCache struct {
+	rdb    *redis.Client
+	prefix string
+}
+
+// NewRedisCache namespaces every key under prefix, e.g. "leaderboard".
+func NewRedisCache(rdb *redis.Client, prefix string) *RedisCache {
+	return &RedisCache{rdb: rdb, prefix: prefix}
+}
+
+func (c *RedisCache) key(key string) string {
+	return fmt.Sprintf("%s:%s", c.prefix, key)
+}
+
+func (c *RedisCache) Get(key string) ([]byte, bool) {
+	if c.rdb == nil {
+		return nil, false
+	}
+	value, err := c.rdb.Get(context.Background(), c.key(key)).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			fmt.Println("cache get failed", c.key(key), err)
+		}
+		return nil, false
+	}
+	return value, true
+}
+
+func (c *RedisCache) Set(key string, value []byte, ttl time.Duration) {
+	if c.rdb == nil {
+		return
+	}
+	if err := c.rdb.Set(context.Background(), c.key(key), value, ttl).Err(); err != nil {
+		fmt.Println("cache set failed", c.key(key), err)
+	}
+}
+
+func (c *RedisCache) Delete(key string) {
+	if c.rdb == nil {
+		return
+	}
+	if err := c.rdb.Del(context.Background(), c.key(key)).Err(); err != nil {
+		fmt.Println("cache delete failed", c.key(key), err)
+	}
+}
@@ -0,0 +1,200 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/repositories"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CompletionDisputeSchema is the GORM mapping for a partner's challenge of a completion event
+type CompletionDisputeSchema struct {
+	gorm.Model
+	ID                string         `json:"id" gorm:"primaryKey"`
+	CompletionEventID string         `json:"completion_event_id" gorm:"not null;uniqueIndex"`
+	HabitTaskID       string         `json:"habit_task_id" gorm:"not null"`
+	GrindID           string         `json:"grind_id" gorm:"not null;index"`
+	SubjectID         string         `json:"subject_id" gorm:"not null"`
+	FlaggedBy         string         `json:"flagged_by" gorm:"not null"`
+	Reason            string         `json:"reason" gorm:"not null;default:''"`
+	Quorum            int            `json:"quorum" gorm:"not null"`
+	RevertVotes       datatypes.JSON `json:"revert_votes"` // JSON array of user IDs
+	UpholdVotes       datatypes.JSON `json:"uphold_votes"` // JSON array of user IDs
+	Status            string         `json:"status" gorm:"not null"`
+	ExpiresAt         time.Time      `json:"expires_at" gorm:"not null"`
+	CreatedAt         time.Time      `json:"created_at" gorm:"not null"`
+	UpdatedAt         time.Time      `json:"updated_at" gorm:"not null"`
+}
+
+func (CompletionDisputeSchema) TableName() string { return "completion_disputes" }
+
+type GormCompletionDisputeRepository struct {
+	db *gorm.DB
+}
+
+func NewGormCompletionDisputeRepository(db *gorm.DB) *GormCompletionDisputeRepository {
+	return &GormCompletionDisputeRepository{db: db}
+}
+
+func (r *GormCompletionDisputeRepository) WithTx(tx *gorm.DB) repositories.CompletionDisputeRepository {
+	return &GormCompletionDisputeRepository{db: tx}
+}
+
+func completionDisputeToSchema(dispute *entities.CompletionDispute) (CompletionDisputeSchema, error) {
+	revertVotes, err := json.Marshal(dispute.RevertVotes)
+	if err != nil {
+		return CompletionDisputeSchema{}, err
+	}
+	upholdVotes, err := json.Marshal(dispute.UpholdVotes)
+	if err != nil {
+		return CompletionDisputeSchema{}, err
+	}
+	return CompletionDisputeSchema{
+		ID:                dispute.ID,
+		CompletionEventID: dispute.CompletionEventID,
+		HabitTaskID:       dispute.HabitTaskID,
+		GrindID:           dispute.GrindID,
+		SubjectID:         dispute.SubjectID,
+		FlaggedBy:         dispute.FlaggedBy,
+		Reason:            dispute.Reason,
+		Quorum:            dispute.Quorum,
+		RevertVotes:       datatypes.JSON(revertVotes),
+		UpholdVotes:       datatypes.JSON(upholdVotes),
+		Status:            string(dispute.Status),
+		ExpiresAt:         dispute.ExpiresAt,
+		CreatedAt:         dispute.CreatedAt,
+		UpdatedAt:         dispute.UpdatedAt,
+	}, nil
+}
+
+func completionDisputeSchemaToEntity(model *CompletionDisputeSchema) (*entities.CompletionDispute, error) {
+	revertVotes := []string{}
+	if len(model.RevertVotes) > 0 {
+		if err := json.Unmarshal(model.RevertVotes, &revertVotes); err != nil {
+			return nil, err
+		}
+	}
+	upholdVotes := []string{}
+	if len(model.UpholdVotes) > 0 {
+		if err := json.Unmarshal(model.UpholdVotes, &upholdVotes); err != nil {
+			return nil, err
+		}
+	}
+	return &entities.CompletionDispute{
+		ID:                model.ID,
+		CompletionEventID: model.CompletionEventID,
+		HabitTaskID:       model.HabitTaskID,
+		GrindID:           model.GrindID,
+		SubjectID:         model.SubjectID,
+		FlaggedBy:         model.FlaggedBy,
+		Reason:            model.Reason,
+		Quorum:            model.Quorum,
+		RevertVotes:       revertVotes,
+		UpholdVotes:       upholdVotes,
+		Status:            entities.CompletionDisputeStatus(model.Status),
+		ExpiresAt:         model.ExpiresAt,
+		CreatedAt:         model.CreatedAt,
+		UpdatedAt:         model.UpdatedAt,
+	}, nil
+}
+
+func (r *GormCompletionDisputeRepository) Create(dispute *entities.CompletionDispute) error {
+	ctx := context.Background()
+	model, err := completionDisputeToSchema(dispute)
+	if err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Create(&model).Error
+}
+
+func (r *GormCompletionDisputeRepository) FindByID(id string) (*entities.CompletionDispute, error) {
+	ctx := context.Background()
+	var model CompletionDisputeSchema
+	if err := r.db.WithContext(ctx).First(&model, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return completionDisputeSchemaToEntity(&model)
+}
+
+func (r *GormCompletionDisputeRepository) FindByIDForUpdate(id string) (*entities.CompletionDispute, error) {
+	ctx := context.Background()
+	var model CompletionDisputeSchema
+	err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&model, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return completionDisputeSchemaToEntity(&model)
+}
+
+func (r *GormCompletionDisputeRepository) FindByCompletionEventID(completionEventID string) (*entities.CompletionDispute, error) {
+	ctx := context.Background()
+	var model CompletionDisputeSchema
+	err := r.db.WithContext(ctx).First(&model, "completion_event_id = ?", completionEventID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return completionDisputeSchemaToEntity(&model)
+}
+
+func (r *GormCompletionDisputeRepository) FindByGrindID(grindID string) ([]*entities.CompletionDispute, error) {
+	ctx := context.Background()
+	var models []CompletionDisputeSchema
+	if err := r.db.WithContext(ctx).Where("grind_id = ?", grindID).Order("created_at DESC").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	return completionDisputeModelsToEntities(models)
+}
+
+func (r *GormCompletionDisputeRepository) FindExpired(at time.Time) ([]*entities.CompletionDispute, error) {
+	ctx := context.Background()
+	var models []CompletionDisputeSchema
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND expires_at <= ?", string(entities.DisputeOpen), at).
+		Order("expires_at").
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+	return completionDisputeModelsToEntities(models)
+}
+
+func completionDisputeModelsToEntities(models []CompletionDisputeSchema) ([]*entities.CompletionDispute, error) {
+	disputes := make([]*entities.CompletionDispute, 0, len(models))
+	for i := range models {
+		dispute, err := completionDisputeSchemaToEntity(&models[i])
+		if err != nil {
+			return nil, err
+		}
+		disputes = append(disputes, dispute)
+	}
+	return disputes, nil
+}
+
+// Update only writes an open dispute, so a settled outcome is never overwritten.
+func (r *GormCompletionDisputeRepository) Update(dispute *entities.CompletionDispute) (bool, error) {
+	ctx := context.Background()
+	model, err := completionDisputeToSchema(dispute)
+	if err != nil {
+		return false, err
+	}
+	result := r.db.WithContext(ctx).
+		Model(&CompletionDisputeSchema{}).
+		Where("id = ? AND status = ?", dispute.ID, string(entities.DisputeOpen)).
+		Select("revert_votes", "uphold_votes", "status", "updated_at").
+		Updates(&model)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
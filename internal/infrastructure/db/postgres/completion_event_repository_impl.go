@@ -2,21 +2,34 @@ package postgres
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"time"
 
 	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/repositories"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
 type CompletionEventSchema struct {
 	gorm.Model
-	ID          string         `json:"id" gorm:"primaryKey"`
-	HabitTaskID string         `json:"habit_task_id" gorm:"not null"`
-	UserID      string         `json:"user_id" gorm:"not null"`
-	Provider    string         `json:"provider" gorm:"not null"`
-	OccurredAt  time.Time      `json:"occurred_at" gorm:"not null"`
-	Metadata    datatypes.JSON `json:"metadata"`
+	ID            string         `json:"id" gorm:"primaryKey"`
+	HabitTaskID   string         `json:"habit_task_id" gorm:"not null"`
+	GrindID       string         `json:"grind_id" gorm:"not null;default:''"`
+	UserID        string         `json:"user_id" gorm:"not null"`
+	Provider      string         `json:"provider" gorm:"not null"`
+	OccurredAt    time.Time      `json:"occurred_at" gorm:"not null"`
+	Metadata      datatypes.JSON `json:"metadata"`
+	DedupKey      string         `json:"dedup_key" gorm:"not null;default:''"` // unique per user, grind and provider when set
+	LateBySeconds int64          `json:"late_by_seconds" gorm:"not null;default:0"`
+
+	// the attached evidence file; EvidenceKey is empty when there is none
+	EvidenceKey         string `json:"evidence_key" gorm:"not null;default:''"`
+	EvidenceName        string `json:"evidence_name" gorm:"not null;default:''"`
+	EvidenceContentType string `json:"evidence_content_type" gorm:"not null;default:''"`
+	EvidenceSize        int64  `json:"evidence_size" gorm:"not null;default:0"`
+	EvidenceSHA256      string `json:"evidence_sha256" gorm:"not null;default:''"`
 }
 
 func (CompletionEventSchema) TableName() string { return "completion_events" }
@@ -29,30 +42,98 @@ func NewGormCompletionEventRepository(db *gorm.DB) *GormCompletionEventRepositor
 	return &GormCompletionEventRepository{db: db}
 }
 
+func (r *GormCompletionEventRepository) WithTx(tx *gorm.DB) repositories.CompletionEventRepository {
+	return &GormCompletionEventRepository{db: tx}
+}
+
 func completionEventSchemaToEntity(s *CompletionEventSchema) *entities.CompletionEvent {
+	var evidence *entities.EvidenceAttachment
+	if s.EvidenceKey != "" {
+		evidence = &entities.EvidenceAttachment{
+			Key:         s.EvidenceKey,
+			FileName:    s.EvidenceName,
+			ContentType: s.EvidenceContentType,
+			Size:        s.EvidenceSize,
+			SHA256:      s.EvidenceSHA256,
+		}
+	}
 	return &entities.CompletionEvent{
 		ID:          s.ID,
 		HabitTaskID: s.HabitTaskID,
+		GrindID:     s.GrindID,
 		UserID:      s.UserID,
 		Provider:    entities.CompletionProvider(s.Provider),
 		OccurredAt:  s.OccurredAt,
 		Metadata:    s.Metadata,
+		DedupKey:    s.DedupKey,
+		LateBy:      time.Duration(s.LateBySeconds) * time.Second,
+		Evidence:    evidence,
 	}
 }
 
 func (r *GormCompletionEventRepository) Create(event *entities.CompletionEvent) error {
 	ctx := context.Background()
 	model := CompletionEventSchema{
-		ID:          event.ID,
-		HabitTaskID: event.HabitTaskID,
-		UserID:      event.UserID,
-		Provider:    string(event.Provider),
-		OccurredAt:  event.OccurredAt,
-		Metadata:    event.Metadata,
+		ID:            event.ID,
+		HabitTaskID:   event.HabitTaskID,
+		GrindID:       event.GrindID,
+		UserID:        event.UserID,
+		Provider:      string(event.Provider),
+		OccurredAt:    event.OccurredAt,
+		Metadata:      event.Metadata,
+		DedupKey:      event.DedupKey,
+		LateBySeconds: int64(event.LateBy / time.Second),
+	}
+	if event.Evidence != nil {
+		model.EvidenceKey = event.Evidence.Key
+		model.EvidenceName = event.Evidence.FileName
+		model.EvidenceContentType = event.Evidence.ContentType
+		model.EvidenceSize = event.Evidence.Size
+		model.EvidenceSHA256 = event.Evidence.SHA256
 	}
 	return r.db.WithContext(ctx).Create(&model).Error
 }
 
+// CreateIfAbsent inserts the event unless the unique (user_id, grind_id, provider, dedup_key) index
+// already holds one, like GormPaymentIdempotencyRepository.Claim.
+func (r *GormCompletionEventRepository) CreateIfAbsent(event *entities.CompletionEvent) (bool, error) {
+	err := r.Create(event)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return false, nil
+	}
+	if strings.Contains(strings.ToLower(err.Error()), "duplicate key") {
+		return false, nil
+	}
+	return false, err
+}
+
+func (r *GormCompletionEventRepository) FindByDedupKey(userID, grindID string, provider entities.CompletionProvider, dedupKey string) (*entities.CompletionEvent, error) {
+	ctx := context.Background()
+	var model CompletionEventSchema
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND grind_id = ? AND provider = ? AND dedup_key = ?", userID, grindID, string(provider), dedupKey).
+		First(&model).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return completionEventSchemaToEntity(&model), nil
+}
+
+func (r *GormCompletionEventRepository) FindByID(id string) (*entities.CompletionEvent, error) {
+	ctx := context.Background()
+	var model CompletionEventSchema
+	if err := r.db.WithContext(ctx).First(&model, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return completionEventSchemaToEntity(&model), nil
+}
+
 func (r *GormCompletionEventRepository) FindByHabitTaskID(habitTaskID string) ([]*entities.CompletionEvent, error) {
 	ctx := context.Background()
 	var models []CompletionEventSchema
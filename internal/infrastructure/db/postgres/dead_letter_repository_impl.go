@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"gorm.io/gorm"
+)
+
+// DeadLetterSchema is the GORM mapping for a completion signal ingest could not record
+type DeadLetterSchema struct {
+	gorm.Model
+	ID            string    `json:"id" gorm:"primaryKey"`
+	UserID        string    `json:"user_id" gorm:"not null;index"`
+	Provider      string    `json:"provider" gorm:"not null"`
+	IntegrationID string    `json:"integration_id" gorm:"not null;default:''"`
+	GrindID       string    `json:"grind_id" gorm:"not null;default:''"`
+	Payload       string    `json:"payload" gorm:"type:text;not null"` // the body as received, not necessarily JSON
+	Reason        string    `json:"reason" gorm:"not null"`
+	Error         string    `json:"error" gorm:"not null;default:''"`
+	CreatedAt     time.Time `json:"created_at" gorm:"not null"`
+
+	// the evidence file sent with the signal; EvidenceKey is empty when there is none
+	EvidenceKey         string `json:"evidence_key" gorm:"not null;default:''"`
+	EvidenceName        string `json:"evidence_name" gorm:"not null;default:''"`
+	EvidenceContentType string `json:"evidence_content_type" gorm:"not null;default:''"`
+	EvidenceSize        int64  `json:"evidence_size" gorm:"not null;default:0"`
+	EvidenceSHA256      string `json:"evidence_sha256" gorm:"not null;default:''"`
+}
+
+func (DeadLetterSchema) TableName() string { return "ingest_dead_letters" }
+
+type GormDeadLetterRepository struct {
+	db *gorm.DB
+}
+
+func NewGormDeadLetterRepository(db *gorm.DB) *GormDeadLetterRepository {
+	return &GormDeadLetterRepository{db: db}
+}
+
+func deadLetterSchemaToEntity(model *DeadLetterSchema) *entities.DeadLetter {
+	var evidence *entities.EvidenceAttachment
+	if model.EvidenceKey != "" {
+		evidence = &entities.EvidenceAttachment{
+			Key:         model.EvidenceKey,
+			FileName:    model.EvidenceName,
+			ContentType: model.EvidenceContentType,
+			Size:        model.EvidenceSize,
+			SHA256:      model.EvidenceSHA256,
+		}
+	}
+	return &entities.DeadLetter{
+		ID:            model.ID,
+		UserID:        model.UserID,
+		Provider:      entities.CompletionProvider(model.Provider),
+		IntegrationID: model.IntegrationID,
+		GrindID:       model.GrindID,
+		Payload:       []byte(model.Payload),
+		Reason:        entities.DeadLetterReason(model.Reason),
+		Error:         model.Error,
+		Evidence:      evidence,
+		CreatedAt:     model.CreatedAt,
+	}
+}
+
+func (r *GormDeadLetterRepository) Create(letter *entities.DeadLetter) error {
+	ctx := context.Background()
+	model := DeadLetterSchema{
+		ID:            letter.ID,
+		UserID:        letter.UserID,
+		Provider:      string(letter.Provider),
+		IntegrationID: letter.IntegrationID,
+		GrindID:       letter.GrindID,
+		Payload:       string(letter.Payload),
+		Reason:        string(letter.Reason),
+		Error:         letter.Error,
+		CreatedAt:     letter.CreatedAt,
+	}
+	if letter.Evidence != nil {
+		model.EvidenceKey = letter.Evidence.Key
+		model.EvidenceName = letter.Evidence.FileName
+		model.EvidenceContentType = letter.Evidence.ContentType
+		model.EvidenceSize = letter.Evidence.Size
+		model.EvidenceSHA256 = letter.Evidence.SHA256
+	}
+	return r.db.WithContext(ctx).Create(&model).Error
+}
+
+func (r *GormDeadLetterRepository) FindByID(id string) (*entities.DeadLetter, error) {
+	ctx := context.Background()
+	var model DeadLetterSchema
+	if err := r.db.WithContext(ctx).First(&model, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return deadLetterSchemaToEntity(&model), nil
+}
+
+func (r *GormDeadLetterRepository) FindByUserID(userID string) ([]*entities.DeadLetter, error) {
+	ctx := context.Background()
+	var models []DeadLetterSchema
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	letters := make([]*entities.DeadLetter, 0, len(models))
+	for i := range models {
+		letters = append(letters, deadLetterSchemaToEntity(&models[i]))
+	}
+	return letters, nil
+}
+
+func (r *GormDeadLetterRepository) Delete(id string) error {
+	ctx := context.Background()
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&DeadLetterSchema{}).Error
+}
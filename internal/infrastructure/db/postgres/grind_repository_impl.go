@@ -2,24 +2,35 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
 	"github.com/daniel0321forever/terriyaki-go/internal/domain/repositories"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
 // GrindSchema is a private struct used only for GORM mapping (decoupling for Grind entity)
 type GrindSchema struct {
 	gorm.Model
-	ID           string               `json:"id" gorm:"primaryKey"`
-	Duration     int32                `json:"duration" gorm:"not null"` // stored in days
-	Participants []entities.User      `json:"participants" gorm:"many2many:participate_records;foreignKey:ID;references:ID"`
-	Budget       int32                `json:"budget" gorm:"not null"`
-	Tasks        []entities.HabitTask `json:"tasks" gorm:"-"` // Excluded from GORM - tasks are managed separately via HabitTaskRepository
-	StartDate    time.Time            `json:"start_date" gorm:"not null"`
-	CreatedAt    time.Time            `json:"created_at" gorm:"not null"`
-	UpdatedAt    time.Time            `json:"updated_at" gorm:"not null"`
+	ID            string               `json:"id" gorm:"primaryKey"`
+	Duration      int32                `json:"duration" gorm:"not null"` // stored in days
+	Participants  []entities.User      `json:"participants" gorm:"many2many:participate_records;foreignKey:ID;references:ID"`
+	Budget        int32                `json:"budget" gorm:"not null"`
+	Tasks         []entities.HabitTask `json:"tasks" gorm:"-"` // Excluded from GORM - tasks are managed separately via HabitTaskRepository
+	StartDate     time.Time            `json:"start_date" gorm:"not null"`
+	CreatedAt     time.Time            `json:"created_at" gorm:"not null"`
+	UpdatedAt     time.Time            `json:"updated_at" gorm:"not null"`
+	PenaltyPolicy PenaltyPolicySchema  `json:"penalty_policy" gorm:"embedded;embeddedPrefix:penalty_"`
+	Schedule      ScheduleSchema       `json:"schedule" gorm:"embedded;embeddedPrefix:schedule_"`
+	Roadmap       RoadmapSchema        `json:"roadmap" gorm:"embedded;embeddedPrefix:roadmap_"`
+	State         string               `json:"state" gorm:"not null;default:draft;index"`
+	PublishedAt   *time.Time           `json:"published_at"`
+	ActivatedAt   *time.Time           `json:"activated_at"`
+	SettlingAt    *time.Time           `json:"settling_at"`
+	SettledAt     *time.Time           `json:"settled_at"`
+	CancelledAt   *time.Time           `json:"cancelled_at"`
 }
 
 // TableName tells GORM which table to use
@@ -27,6 +38,149 @@ func (GrindSchema) TableName() string {
 	return "grinds"
 }
 
+// PenaltyPolicySchema is embedded into GrindSchema as the penalty_* columns
+type PenaltyPolicySchema struct {
+	Kind         string `json:"kind" gorm:"not null;default:flat"`
+	Amount       int    `json:"amount" gorm:"not null;default:0"`
+	Increment    int    `json:"increment" gorm:"not null;default:0"`
+	GraceDays    int    `json:"grace_days" gorm:"not null;default:0"`
+	FreezeTokens int    `json:"freeze_tokens" gorm:"not null;default:0"`
+	// No GORM default here: Create would swap a false CapAtBudget for it. The column
+	// default lives in the migrations.
+	CapAtBudget bool `json:"cap_at_budget" gorm:"not null"`
+}
+
+// ScheduleSchema is embedded into GrindSchema as the schedule_* columns
+type ScheduleSchema struct {
+	Kind         string         `json:"kind" gorm:"not null;default:daily"`
+	TimesPerWeek int            `json:"times_per_week" gorm:"not null;default:0"`
+	Dates        datatypes.JSON `json:"dates"` // ["2026-03-02", ...]; dates schedules only
+}
+
+func scheduleToSchema(schedule entities.Schedule) ScheduleSchema {
+	model := ScheduleSchema{
+		Kind:         string(schedule.Kind),
+		TimesPerWeek: schedule.TimesPerWeek,
+	}
+	if model.Kind == "" {
+		model.Kind = string(entities.ScheduleKindDaily)
+	}
+	if len(schedule.Dates) > 0 {
+		days := make([]string, len(schedule.Dates))
+		for i, date := range schedule.Dates {
+			days[i] = date.Format("2006-01-02")
+		}
+		model.Dates, _ = json.Marshal(days)
+	}
+	return model
+}
+
+func scheduleSchemaToEntity(model ScheduleSchema) entities.Schedule {
+	schedule := entities.Schedule{
+		Kind:         entities.ScheduleKind(model.Kind),
+		TimesPerWeek: model.TimesPerWeek,
+	}
+	var days []string
+	if len(model.Dates) > 0 && json.Unmarshal(model.Dates, &days) == nil {
+		for _, day := range days {
+			if date, err := time.Parse("2006-01-02", day); err == nil {
+				schedule.Dates = append(schedule.Dates, date)
+			}
+		}
+	}
+	return schedule
+}
+
+// RoadmapSchema is embedded into GrindSchema as the roadmap_* columns; an empty name means no roadmap
+type RoadmapSchema struct {
+	Name  string         `json:"name" gorm:"not null;default:''"`
+	Order string         `json:"order" gorm:"not null;default:''"`
+	Tags  datatypes.JSON `json:"tags"`  // tag order only
+	Slugs datatypes.JSON `json:"slugs"` // custom order only
+}
+
+func roadmapToSchema(roadmap *entities.Roadmap) RoadmapSchema {
+	if roadmap == nil {
+		return RoadmapSchema{}
+	}
+	model := RoadmapSchema{Name: roadmap.Name, Order: string(roadmap.Order)}
+	if len(roadmap.Tags) > 0 {
+		model.Tags, _ = json.Marshal(roadmap.Tags)
+	}
+	if len(roadmap.Slugs) > 0 {
+		model.Slugs, _ = json.Marshal(roadmap.Slugs)
+	}
+	return model
+}
+
+func roadmapSchemaToEntity(model RoadmapSchema) *entities.Roadmap {
+	if model.Name == "" {
+		return nil
+	}
+	roadmap := &entities.Roadmap{Name: model.Name, Order: entities.RoadmapOrder(model.Order)}
+	if len(model.Tags) > 0 {
+		_ = json.Unmarshal(model.Tags, &roadmap.Tags)
+	}
+	if len(model.Slugs) > 0 {
+		_ = json.Unmarshal(model.Slugs, &roadmap.Slugs)
+	}
+	return roadmap
+}
+
+func penaltyPolicyToSchema(policy entities.PenaltyPolicy) PenaltyPolicySchema {
+	return PenaltyPolicySchema{
+		Kind:         string(policy.Kind),
+		Amount:       policy.Amount,
+		Increment:    policy.Increment,
+		GraceDays:    policy.GraceDays,
+		FreezeTokens: policy.FreezeTokens,
+		CapAtBudget:  policy.CapAtBudget,
+	}
+}
+
+func grindSchemaToEntity(model *GrindSchema) *entities.Grind {
+	return &entities.Grind{
+		ID:        model.ID,
+		Duration:  model.Duration,
+		Budget:    model.Budget,
+		StartDate: model.StartDate,
+		CreatedAt: model.CreatedAt,
+		UpdatedAt: model.UpdatedAt,
+		PenaltyPolicy: entities.PenaltyPolicy{
+			Kind:         entities.PenaltyKind(model.PenaltyPolicy.Kind),
+			Amount:       model.PenaltyPolicy.Amount,
+			Increment:    model.PenaltyPolicy.Increment,
+			GraceDays:    model.PenaltyPolicy.GraceDays,
+			FreezeTokens: model.PenaltyPolicy.FreezeTokens,
+			CapAtBudget:  model.PenaltyPolicy.CapAtBudget,
+		},
+		Schedule:    scheduleSchemaToEntity(model.Schedule),
+		Roadmap:     roadmapSchemaToEntity(model.Roadmap),
+		State:       entities.GrindState(model.State),
+		PublishedAt: model.PublishedAt,
+		ActivatedAt: model.ActivatedAt,
+		SettlingAt:  model.SettlingAt,
+		SettledAt:   model.SettledAt,
+		CancelledAt: model.CancelledAt,
+	}
+}
+
+func grindModelsToEntities(models []GrindSchema) []*entities.Grind {
+	grinds := make([]*entities.Grind, len(models))
+	for i := range models {
+		grinds[i] = grindSchemaToEntity(&models[i])
+	}
+	return grinds
+}
+
+func grindStatesToStrings(states []entities.GrindState) []string {
+	values := make([]string, len(states))
+	for i, state := range states {
+		values[i] = string(state)
+	}
+	return values
+}
+
 type GormGrindRepository struct {
 	db *gorm.DB
 }
@@ -45,14 +199,23 @@ func (r *GormGrindRepository) Create(grind *entities.Grind) error {
 	ctx := context.Background()
 	// 1. Map Entity -> DB Schema
 	model := GrindSchema{
-		ID:           grind.ID,
-		Duration:     grind.Duration,
-		Participants: grind.Participants,
-		Budget:       grind.Budget,
-		Tasks:        grind.Tasks,
-		StartDate:    grind.StartDate,
-		CreatedAt:    grind.CreatedAt,
-		UpdatedAt:    grind.UpdatedAt,
+		ID:            grind.ID,
+		Duration:      grind.Duration,
+		Participants:  grind.Participants,
+		Budget:        grind.Budget,
+		Tasks:         grind.Tasks,
+		StartDate:     grind.StartDate,
+		CreatedAt:     grind.CreatedAt,
+		UpdatedAt:     grind.UpdatedAt,
+		PenaltyPolicy: penaltyPolicyToSchema(grind.PenaltyPolicy),
+		Schedule:      scheduleToSchema(grind.Schedule),
+		Roadmap:       roadmapToSchema(grind.Roadmap),
+		State:         string(grind.State),
+		PublishedAt:   grind.PublishedAt,
+		ActivatedAt:   grind.ActivatedAt,
+		SettlingAt:    grind.SettlingAt,
+		SettledAt:     grind.SettledAt,
+		CancelledAt:   grind.CancelledAt,
 	}
 
 	// 2. Save to Postgres
@@ -67,14 +230,7 @@ func (r *GormGrindRepository) FindById(id string) (*entities.Grind, error) {
 	}
 
 	// 3. Map DB Model back to Entity
-	return &entities.Grind{
-		ID:        model.ID,
-		Duration:  model.Duration,
-		Budget:    model.Budget,
-		StartDate: model.StartDate,
-		CreatedAt: model.CreatedAt,
-		UpdatedAt: model.UpdatedAt,
-	}, nil
+	return grindSchemaToEntity(&model), nil
 }
 
 func (r *GormGrindRepository) Update(grind *entities.Grind) error {
@@ -82,17 +238,26 @@ func (r *GormGrindRepository) Update(grind *entities.Grind) error {
 	// 1. Map Entity -> DB Model
 	// We update the UpdatedAt timestamp to the current time
 	model := GrindSchema{
-		ID:        grind.ID,
-		Duration:  grind.Duration,
-		Budget:    grind.Budget,
-		StartDate: grind.StartDate,
-		UpdatedAt: time.Now().UTC(),
+		ID:            grind.ID,
+		Duration:      grind.Duration,
+		Budget:        grind.Budget,
+		StartDate:     grind.StartDate,
+		PenaltyPolicy: penaltyPolicyToSchema(grind.PenaltyPolicy),
+		Schedule:      scheduleToSchema(grind.Schedule),
+		UpdatedAt:     time.Now().UTC(),
 	}
 
 	// 2. Save updates to Postgres
 	// .Model(&model) tells GORM which record to find based on the ID
-	// .Updates(model) only updates the fields that are non-zero
-	result := r.db.WithContext(ctx).Model(&model).Updates(model)
+	// .Select(...) also writes zero values, e.g. a budget lowered to 0; the state
+	// columns are only written through UpdateState and the roadmap is fixed at creation
+	result := r.db.WithContext(ctx).Model(&model).
+		Select("duration", "budget", "start_date",
+			"penalty_kind", "penalty_amount", "penalty_increment",
+			"penalty_grace_days", "penalty_freeze_tokens", "penalty_cap_at_budget",
+			"schedule_kind", "schedule_times_per_week", "schedule_dates",
+			"updated_at").
+		Updates(model)
 	if result.Error != nil {
 		return result.Error
 	}
@@ -103,6 +268,42 @@ func (r *GormGrindRepository) Update(grind *entities.Grind) error {
 	return nil
 }
 
+// UpdateState persists grind.State and its timestamps only when the stored state is still
+// from, so two workers racing on the same transition cannot both win. It returns false
+// when the grind has already moved on.
+func (r *GormGrindRepository) UpdateState(grind *entities.Grind, from entities.GrindState) (bool, error) {
+	ctx := context.Background()
+	result := r.db.WithContext(ctx).
+		Model(&GrindSchema{}).
+		Where("id = ? AND state = ?", grind.ID, string(from)).
+		Updates(map[string]interface{}{
+			"state":        string(grind.State),
+			"published_at": grind.PublishedAt,
+			"activated_at": grind.ActivatedAt,
+			"settling_at":  grind.SettlingAt,
+			"settled_at":   grind.SettledAt,
+			"cancelled_at": grind.CancelledAt,
+			"updated_at":   grind.UpdatedAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *GormGrindRepository) FindAllByStates(states []entities.GrindState) ([]*entities.Grind, error) {
+	ctx := context.Background()
+	var models []GrindSchema
+	err := r.db.WithContext(ctx).
+		Where("state IN ?", grindStatesToStrings(states)).
+		Order("start_date ASC").
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+	return grindModelsToEntities(models), nil
+}
+
 func (r *GormGrindRepository) Delete(id string) error {
 	ctx := context.Background()
 	// In GORM, if you pass a struct with a Primary Key to Delete,
@@ -121,33 +322,26 @@ func (r *GormGrindRepository) Delete(id string) error {
 	return nil
 }
 
-func (r *GormGrindRepository) FindAllByUserID(userID string) ([]*entities.Grind, error) {
+// FindAllByUserID returns the user's grinds in any of states, newest first, after the cursor;
+// an empty states matches every state.
+func (r *GormGrindRepository) FindAllByUserID(userID string, states []entities.GrindState, after entities.PageCursor, limit int) ([]*entities.Grind, error) {
 	ctx := context.Background()
 	var models []GrindSchema
 
 	// Join with participation table to find grinds for a user
-	err := r.db.WithContext(ctx).
+	query := r.db.WithContext(ctx).
 		Table("grinds").
 		Joins("INNER JOIN participation ON grinds.id = participation.grind_id").
-		Where("participation.user_id = ?", userID).
-		Find(&models).Error
-
-	if err != nil {
-		return nil, err
+		Where("participation.user_id = ?", userID)
+	if len(states) > 0 {
+		query = query.Where("grinds.state IN ?", grindStatesToStrings(states))
 	}
+	query = pageAfter(query, "grinds", after, limit)
 
-	grinds := make([]*entities.Grind, len(models))
-	for i, model := range models {
-		grinds[i] = &entities.Grind{
-			ID:        model.ID,
-			Duration:  model.Duration,
-			Budget:    model.Budget,
-			StartDate: model.StartDate,
-			CreatedAt: model.CreatedAt,
-			UpdatedAt: model.UpdatedAt,
-		}
+	if err := query.Find(&models).Error; err != nil {
+		return nil, err
 	}
-	return grinds, nil
+	return grindModelsToEntities(models), nil
 }
 
 func (r *GormGrindRepository) FindLatestByUserID(userID string) (*entities.Grind, error) {
@@ -166,14 +360,7 @@ func (r *GormGrindRepository) FindLatestByUserID(userID string) (*entities.Grind
 		return nil, err
 	}
 
-	return &entities.Grind{
-		ID:        model.ID,
-		Duration:  model.Duration,
-		Budget:    model.Budget,
-		StartDate: model.StartDate,
-		CreatedAt: model.CreatedAt,
-		UpdatedAt: model.UpdatedAt,
-	}, nil
+	return grindSchemaToEntity(&model), nil
 }
 
 func (r *GormGrindRepository) DeleteAll() error {
@@ -183,27 +370,80 @@ func (r *GormGrindRepository) DeleteAll() error {
 
 func (r *GormGrindRepository) FindDuedGrinds() ([]*entities.Grind, error) {
 	ctx := context.Background()
-	today := time.Now().UTC().Truncate(24 * time.Hour)
+	now := time.Now().UTC()
 	var models []GrindSchema
 
+	// The end day is a calendar date shared by every participant, but "today" depends on the
+	// participant's timezone (UTC-12 to UTC+14). Return every active grind whose end date has
+	// been reached somewhere, plus every grind already settling; callers decide per
+	// participant with Grind.IsDueOn or Grind.EndDateIn.
 	err := r.db.WithContext(ctx).
 		Table("grinds").
-		Where("(start_date + (duration::text || ' days')::interval)::date = ?", today.Format("2006-01-02")).
+		Where("state = ? OR (state = ? AND (start_date AT TIME ZONE 'UTC')::date + duration <= ?)",
+			string(entities.GrindStateSettling),
+			string(entities.GrindStateActive),
+			now.Add(14*time.Hour).Format("2006-01-02")).
 		Find(&models).Error
 
 	if err != nil {
 		return nil, err
 	}
 
-	grinds := make([]*entities.Grind, len(models))
-	for _, model := range models {
-		grinds = append(grinds, &entities.Grind{
-			ID:        model.ID,
-			Duration:  model.Duration,
-			Budget:    model.Budget,
-			StartDate: model.StartDate,
-		})
+	grinds := grindModelsToEntities(models)
+	if err := r.loadParticipants(ctx, grinds); err != nil {
+		return nil, err
 	}
-
 	return grinds, nil
 }
+
+// grindParticipantRow is a participant joined with the grind they take part in.
+type grindParticipantRow struct {
+	UserSchema
+	GrindID string
+}
+
+// loadParticipants fills in the participants of every grind with a single query.
+func (r *GormGrindRepository) loadParticipants(ctx context.Context, grinds []*entities.Grind) error {
+	if len(grinds) == 0 {
+		return nil
+	}
+	byID := make(map[string]*entities.Grind, len(grinds))
+	ids := make([]string, 0, len(grinds))
+	for _, grind := range grinds {
+		byID[grind.ID] = grind
+		ids = append(ids, grind.ID)
+	}
+
+	var rows []grindParticipantRow
+	err := r.db.WithContext(ctx).Table("users").
+		Select("users.*, participation.grind_id AS grind_id").
+		Joins("INNER JOIN participation ON users.id = participation.user_id").
+		Where("participation.grind_id IN ?", ids).
+		Find(&rows).Error
+	if err != nil {
+		return err
+	}
+	for i := range rows {
+		grind := byID[rows[i].GrindID]
+		grind.Participants = append(grind.Participants, userSchemaToEntity(&rows[i].UserSchema))
+	}
+	return nil
+}
+
+func (r *GormGrindRepository) FindActiveGrinds(day time.Time) ([]*entities.Grind, error) {
+	ctx := context.Background()
+	var models []GrindSchema
+
+	// A grind is active on a calendar day when the day falls in [start day, start day + duration).
+	// Recruiting grinds are included in case the lifecycle job has not activated them yet.
+	date := day.Format("2006-01-02")
+	err := r.db.WithContext(ctx).
+		Table("grinds").
+		Where("state IN ?", []string{string(entities.GrindStateRecruiting), string(entities.GrindStateActive)}).
+		Where("(start_date AT TIME ZONE 'UTC')::date <= ? AND (start_date AT TIME ZONE 'UTC')::date + duration > ?", date, date).
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+	return grindModelsToEntities(models), nil
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
 	"github.com/daniel0321forever/terriyaki-go/internal/domain/repositories"
 	"github.com/daniel0321forever/terriyaki-go/internal/infrastructure/db/postgres"
+	"github.com/daniel0321forever/terriyaki-go/internal/infrastructure/roadmap"
 	"gorm.io/gorm"
 )
 
@@ -76,6 +77,108 @@ func TestGormGrindRepository_FindLatestByUserID(t *testing.T) {
 	}
 }
 
+func TestGormGrindRepository_UpdateStateAndFilterByState(t *testing.T) {
+	resetRepoTables(t)
+
+	userRepo := postgres.NewGormUserRepository(postgres.Db)
+	grindRepo := postgres.NewGormGrindRepository(postgres.Db)
+	participationRepo := postgres.NewGormParticipationRepository(postgres.Db)
+
+	user, err := entities.NewUser("carol", "carol@example.com", "hashed-pass", "")
+	if err != nil {
+		t.Fatalf("failed to create user entity: %v", err)
+	}
+	if err = userRepo.Create(user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	grind, err := entities.NewGrind(7, 10, time.Now().UTC().AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("failed to create grind entity: %v", err)
+	}
+	if err = grindRepo.Create(grind); err != nil {
+		t.Fatalf("failed to persist grind: %v", err)
+	}
+	participation, _ := entities.NewParticipation(user.ID, grind.ID)
+	if err = participationRepo.Create(participation); err != nil {
+		t.Fatalf("failed to create participation: %v", err)
+	}
+
+	if err = grind.TransitionTo(entities.GrindStateRecruiting, time.Now()); err != nil {
+		t.Fatalf("transition failed: %v", err)
+	}
+	moved, err := grindRepo.UpdateState(grind, entities.GrindStateDraft)
+	if err != nil || !moved {
+		t.Fatalf("expected draft -> recruiting to be stored, moved=%v err=%v", moved, err)
+	}
+	// A second writer still holding the draft grind must lose.
+	moved, err = grindRepo.UpdateState(grind, entities.GrindStateDraft)
+	if err != nil || moved {
+		t.Fatalf("expected a stale transition to be rejected, moved=%v err=%v", moved, err)
+	}
+
+	stored, err := grindRepo.FindById(grind.ID)
+	if err != nil {
+		t.Fatalf("find grind failed: %v", err)
+	}
+	if stored.State != entities.GrindStateRecruiting || stored.PublishedAt == nil {
+		t.Fatalf("expected a published recruiting grind, got state %q published at %v", stored.State, stored.PublishedAt)
+	}
+
+	recruiting, err := grindRepo.FindAllByUserID(user.ID, []entities.GrindState{entities.GrindStateRecruiting}, entities.PageCursor{}, 0)
+	if err != nil || len(recruiting) != 1 {
+		t.Fatalf("expected one recruiting grind, got %d (err %v)", len(recruiting), err)
+	}
+	settled, err := grindRepo.FindAllByUserID(user.ID, []entities.GrindState{entities.GrindStateSettled}, entities.PageCursor{}, 0)
+	if err != nil || len(settled) != 0 {
+		t.Fatalf("expected no settled grinds, got %d (err %v)", len(settled), err)
+	}
+}
+
+// TestGormGrindRepository_PenaltyPolicyCapAtBudgetFalse checks that a policy without the
+// budget cap survives a round trip through grinds and stake changes.
+func TestGormGrindRepository_PenaltyPolicyCapAtBudgetFalse(t *testing.T) {
+	resetRepoTables(t)
+
+	grindRepo := postgres.NewGormGrindRepository(postgres.Db)
+	stakeChangeRepo := postgres.NewGormStakeChangeRepository(postgres.Db)
+
+	policy, err := entities.NewPenaltyPolicy(entities.PenaltyKindFlat, 5, 0, 0, 0, false)
+	if err != nil {
+		t.Fatalf("failed to create penalty policy: %v", err)
+	}
+	grind, err := entities.NewGrind(7, 10, time.Now().UTC().AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("failed to create grind entity: %v", err)
+	}
+	grind.PenaltyPolicy = policy
+	if err = grindRepo.Create(grind); err != nil {
+		t.Fatalf("failed to persist grind: %v", err)
+	}
+	stored, err := grindRepo.FindById(grind.ID)
+	if err != nil {
+		t.Fatalf("find grind failed: %v", err)
+	}
+	if stored.PenaltyPolicy.CapAtBudget {
+		t.Fatalf("expected the grind's policy to stay uncapped")
+	}
+
+	change, err := entities.NewStakeChange(grind.ID, "proposer", 20, policy)
+	if err != nil {
+		t.Fatalf("failed to create stake change entity: %v", err)
+	}
+	if err = stakeChangeRepo.Create(change); err != nil {
+		t.Fatalf("failed to persist stake change: %v", err)
+	}
+	storedChange, err := stakeChangeRepo.FindByID(change.ID)
+	if err != nil {
+		t.Fatalf("find stake change failed: %v", err)
+	}
+	if storedChange.PenaltyPolicy.CapAtBudget {
+		t.Fatalf("expected the proposed policy to stay uncapped")
+	}
+}
+
 // TestCreateGroupGrindRollback verifies that if habitTaskRepo.Create fails mid-loop,
 // the grind and participation rows written before the failure are rolled back.
 func TestCreateGroupGrindRollback(t *testing.T) {
@@ -104,6 +207,8 @@ func TestCreateGroupGrindRollback(t *testing.T) {
 		failingHabitTaskRepo,
 		postgres.NewGormParticipationRepository(postgres.Db),
 		postgres.NewGormMessageRepository(postgres.Db),
+		postgres.NewGormStakeChangeRepository(postgres.Db),
+		roadmap.NewEmbeddedRoadmapRepository(),
 	)
 
 	startDate := time.Now().UTC()
@@ -192,6 +297,8 @@ func TestDeleteGrindRollback(t *testing.T) {
 		habitTaskRepo,
 		failingPartRepo,
 		postgres.NewGormMessageRepository(postgres.Db),
+		postgres.NewGormStakeChangeRepository(postgres.Db),
+		roadmap.NewEmbeddedRoadmapRepository(),
 	)
 
 	err := grindService.DeleteGrind(dto.DeleteGrindDTO{GrindID: grind.ID})
@@ -268,6 +375,8 @@ func TestAcceptInvitationRollback(t *testing.T) {
 		habitTaskRepo,
 		participationRepo,
 		postgres.NewGormMessageRepository(postgres.Db),
+		postgres.NewGormStakeChangeRepository(postgres.Db),
+		roadmap.NewEmbeddedRoadmapRepository(),
 	)
 
 	err := grindService.AcceptInvitation(
@@ -313,6 +422,15 @@ func (r *failAfterNHabitTaskRepo) Create(task *entities.HabitTask) error {
 	return r.inner.Create(task)
 }
 
+func (r *failAfterNHabitTaskRepo) CreateBatch(tasks []*entities.HabitTask) error {
+	for _, task := range tasks {
+		if err := r.Create(task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (r *failAfterNHabitTaskRepo) FindByID(id string) (*entities.HabitTask, error) {
 	return r.inner.FindByID(id)
 }
@@ -321,8 +439,36 @@ func (r *failAfterNHabitTaskRepo) FindByGrindIDAndUserID(grindID, userID string)
 	return r.inner.FindByGrindIDAndUserID(grindID, userID)
 }
 
-func (r *failAfterNHabitTaskRepo) FindTodayTask(userID, grindID string) (*entities.HabitTask, error) {
-	return r.inner.FindTodayTask(userID, grindID)
+func (r *failAfterNHabitTaskRepo) FindTodayTask(userID, grindID string, loc *time.Location) (*entities.HabitTask, error) {
+	return r.inner.FindTodayTask(userID, grindID, loc)
+}
+
+func (r *failAfterNHabitTaskRepo) FindTaskOnDay(userID, grindID string, at time.Time, loc *time.Location) (*entities.HabitTask, error) {
+	return r.inner.FindTaskOnDay(userID, grindID, at, loc)
+}
+
+func (r *failAfterNHabitTaskRepo) FindByGrindIDInRange(grindID string, from, to time.Time) ([]*entities.HabitTask, error) {
+	return r.inner.FindByGrindIDInRange(grindID, from, to)
+}
+
+func (r *failAfterNHabitTaskRepo) MarkMissed(taskID string) (bool, error) {
+	return r.inner.MarkMissed(taskID)
+}
+
+func (r *failAfterNHabitTaskRepo) MarkExcused(taskID string) (bool, error) {
+	return r.inner.MarkExcused(taskID)
+}
+
+func (r *failAfterNHabitTaskRepo) Complete(taskID string, finishedAt time.Time) (bool, error) {
+	return r.inner.Complete(taskID, finishedAt)
+}
+
+func (r *failAfterNHabitTaskRepo) RevertCompletion(taskID string, excuse bool) (bool, error) {
+	return r.inner.RevertCompletion(taskID, excuse)
+}
+
+func (r *failAfterNHabitTaskRepo) RebaseDates(userID, fromTimezone, toTimezone string) error {
+	return r.inner.RebaseDates(userID, fromTimezone, toTimezone)
 }
 
 func (r *failAfterNHabitTaskRepo) Update(task *entities.HabitTask) error {
@@ -337,6 +483,10 @@ func (r *failAfterNHabitTaskRepo) DeleteByGrindID(grindID string) error {
 	return r.inner.DeleteByGrindID(grindID)
 }
 
+func (r *failAfterNHabitTaskRepo) DeleteByIDs(ids []string) error {
+	return r.inner.DeleteByIDs(ids)
+}
+
 // failingParticipationRepo always fails on DeleteByGrindID.
 type failingParticipationRepo struct {
 	inner *postgres.GormParticipationRepository
@@ -360,6 +510,14 @@ func (r *failingParticipationRepo) FindByUserAndGrind(userID, grindID string) (*
 	return r.inner.FindByUserAndGrind(userID, grindID)
 }
 
+func (r *failingParticipationRepo) FindByUserAndGrindForUpdate(userID, grindID string) (*entities.Participation, error) {
+	return r.inner.FindByUserAndGrindForUpdate(userID, grindID)
+}
+
+func (r *failingParticipationRepo) FindByGrindID(grindID string) ([]*entities.Participation, error) {
+	return r.inner.FindByGrindID(grindID)
+}
+
 func (r *failingParticipationRepo) Update(p *entities.Participation) error {
 	return r.inner.Update(p)
 }
@@ -387,14 +545,50 @@ func (r *failingMessageRepo) FindByID(id string) (*entities.Message, error) {
 	return r.inner.FindByID(id)
 }
 
-func (r *failingMessageRepo) FindAllForReceiver(receiverID string, offset, limit int) ([]*entities.Message, error) {
-	return r.inner.FindAllForReceiver(receiverID, offset, limit)
+func (r *failingMessageRepo) FindAllForReceiver(receiverID string, filter entities.MessageFilter, after entities.PageCursor, limit int) ([]*entities.Message, error) {
+	return r.inner.FindAllForReceiver(receiverID, filter, after, limit)
+}
+
+func (r *failingMessageRepo) FindAllFromSender(senderID string, filter entities.MessageFilter, after entities.PageCursor, limit int) ([]*entities.Message, error) {
+	return r.inner.FindAllFromSender(senderID, filter, after, limit)
+}
+
+func (r *failingMessageRepo) CountForReceiver(receiverID string, filter entities.MessageFilter) (int64, error) {
+	return r.inner.CountForReceiver(receiverID, filter)
 }
 
-func (r *failingMessageRepo) FindAllFromSender(senderID string, offset, limit int) ([]*entities.Message, error) {
-	return r.inner.FindAllFromSender(senderID, offset, limit)
+func (r *failingMessageRepo) CreateInvitationIfAbsent(m *entities.Message) (bool, error) {
+	return r.inner.CreateInvitationIfAbsent(m)
+}
+
+func (r *failingMessageRepo) FindOpenInvitation(grindID, receiverID string) (*entities.Message, error) {
+	return r.inner.FindOpenInvitation(grindID, receiverID)
 }
 
 func (r *failingMessageRepo) Update(m *entities.Message) error {
 	return errors.New("injected messageRepo.Update failure")
 }
+
+func (r *failingMessageRepo) FindConversations(userID string, after entities.PageCursor, limit int) ([]*entities.Conversation, error) {
+	return r.inner.FindConversations(userID, after, limit)
+}
+
+func (r *failingMessageRepo) FindConversationMessages(userID, counterpartID string, after entities.PageCursor, limit int) ([]*entities.Message, error) {
+	return r.inner.FindConversationMessages(userID, counterpartID, after, limit)
+}
+
+func (r *failingMessageRepo) MarkConversationRead(userID, counterpartID string) (int64, error) {
+	return r.inner.MarkConversationRead(userID, counterpartID)
+}
+
+func (r *failingMessageRepo) FindConversationSetting(userID, counterpartID string) (*entities.ConversationSetting, error) {
+	return r.inner.FindConversationSetting(userID, counterpartID)
+}
+
+func (r *failingMessageRepo) CreateConversationSetting(setting *entities.ConversationSetting) error {
+	return r.inner.CreateConversationSetting(setting)
+}
+
+func (r *failingMessageRepo) UpdateConversationSetting(setting *entities.ConversationSetting) error {
+	return r.inner.UpdateConversationSetting(setting)
+}
@@ -0,0 +1,112 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"gorm.io/gorm"
+)
+
+// participantStatsQuery aggregates a grind's habit tasks per participant in one round-trip.
+// Streaks use the gaps-and-islands trick: within a run of consecutive completed tasks,
+// the task's position among all tasks minus its position among completed tasks is constant.
+// Only decided tasks take part, so open and excused days neither extend nor break a streak.
+const participantStatsQuery = `
+WITH ordered AS (
+    SELECT user_id, completed, missed,
+        ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY date) AS rn,
+        ROW_NUMBER() OVER (PARTITION BY user_id, completed ORDER BY date) AS completed_rn
+    FROM habit_tasks
+    WHERE grind_id = @grind AND deleted_at IS NULL AND (completed OR missed)
+),
+task_stats AS (
+    SELECT user_id,
+        COUNT(*) FILTER (WHERE completed) AS completed_days,
+        COUNT(*) FILTER (WHERE completed OR missed) AS closed_days,
+        COALESCE(MAX(rn) FILTER (WHERE missed), 0) AS last_missed_rn
+    FROM ordered
+    GROUP BY user_id
+),
+current_streaks AS (
+    SELECT o.user_id, COUNT(*) AS current_streak
+    FROM ordered o
+    JOIN task_stats s ON s.user_id = o.user_id
+    WHERE o.completed AND o.rn > s.last_missed_rn
+    GROUP BY o.user_id
+),
+longest_streaks AS (
+    SELECT user_id, MAX(run_length) AS longest_streak
+    FROM (
+        SELECT user_id, COUNT(*) AS run_length
+        FROM ordered
+        WHERE completed
+        GROUP BY user_id, rn - completed_rn
+    ) runs
+    GROUP BY user_id
+)
+SELECT p.user_id,
+    u.username,
+    COALESCE(s.completed_days, 0) AS completed_days,
+    COALESCE(s.closed_days, 0) AS closed_days,
+    COALESCE(c.current_streak, 0) AS current_streak,
+    COALESCE(l.longest_streak, 0) AS longest_streak,
+    p.missed_days,
+    p.frozen_days,
+    p.total_penalty,
+    p.quitted
+FROM participation p
+JOIN users u ON u.id = p.user_id
+LEFT JOIN task_stats s ON s.user_id = p.user_id
+LEFT JOIN current_streaks c ON c.user_id = p.user_id
+LEFT JOIN longest_streaks l ON l.user_id = p.user_id
+WHERE p.grind_id = @grind AND p.deleted_at IS NULL
+`
+
+type participantStatsRow struct {
+	UserID        string
+	Username      string
+	CompletedDays int
+	ClosedDays    int
+	CurrentStreak int
+	LongestStreak int
+	MissedDays    int
+	FrozenDays    int
+	TotalPenalty  int
+	Quitted       bool
+}
+
+type GormGrindStatsRepository struct {
+	db *gorm.DB
+}
+
+func NewGormGrindStatsRepository(db *gorm.DB) *GormGrindStatsRepository {
+	return &GormGrindStatsRepository{db: db}
+}
+
+func (r *GormGrindStatsRepository) FindParticipantStats(grindID string) ([]*entities.ParticipantStats, error) {
+	ctx := context.Background()
+	var rows []participantStatsRow
+	err := r.db.WithContext(ctx).
+		Raw(participantStatsQuery, map[string]interface{}{"grind": grindID}).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]*entities.ParticipantStats, len(rows))
+	for i, row := range rows {
+		stats[i] = &entities.ParticipantStats{
+			UserID:        row.UserID,
+			Username:      row.Username,
+			CompletedDays: row.CompletedDays,
+			ClosedDays:    row.ClosedDays,
+			CurrentStreak: row.CurrentStreak,
+			LongestStreak: row.LongestStreak,
+			MissedDays:    row.MissedDays,
+			FrozenDays:    row.FrozenDays,
+			TotalPenalty:  row.TotalPenalty,
+			Quitted:       row.Quitted,
+		}
+	}
+	return stats, nil
+}
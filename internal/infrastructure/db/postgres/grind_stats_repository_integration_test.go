@@ -0,0 +1,81 @@
+//go:build integration
+// +build integration
+
+package postgres_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/daniel0321forever/terriyaki-go/internal/infrastructure/db/postgres"
+)
+
+func TestGormGrindStatsRepository_FindParticipantStats(t *testing.T) {
+	resetRepoTables(t)
+	resetHabitTasks(t)
+
+	userRepo := postgres.NewGormUserRepository(postgres.Db)
+	grindRepo := postgres.NewGormGrindRepository(postgres.Db)
+	participationRepo := postgres.NewGormParticipationRepository(postgres.Db)
+	habitTaskRepo := postgres.NewGormHabitTaskRepository(postgres.Db)
+	statsRepo := postgres.NewGormGrindStatsRepository(postgres.Db)
+
+	start := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	grind, err := entities.NewGrind(10, 100, start)
+	if err != nil {
+		t.Fatalf("failed to create grind entity: %v", err)
+	}
+	if err = grindRepo.Create(grind); err != nil {
+		t.Fatalf("failed to persist grind: %v", err)
+	}
+
+	amy, _ := entities.NewUser("amy", "amy@example.com", "hashed-pass", "")
+	bob, _ := entities.NewUser("bob", "bob@example.com", "hashed-pass", "")
+	for _, user := range []*entities.User{amy, bob} {
+		if err = userRepo.Create(user); err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+		participation, _ := entities.NewParticipation(user.ID, grind.ID)
+		if err = participationRepo.Create(participation); err != nil {
+			t.Fatalf("failed to create participation: %v", err)
+		}
+	}
+
+	// amy: done, done, missed, done, excused, done, done, then an open day
+	pattern := []string{"done", "done", "missed", "done", "excused", "done", "done", "open"}
+	tasks := make([]*entities.HabitTask, 0, len(pattern))
+	for i, state := range pattern {
+		task, err := entities.NewHabitTask(amy.ID, grind.ID, start.AddDate(0, 0, i))
+		if err != nil {
+			t.Fatalf("failed to create task entity: %v", err)
+		}
+		task.Completed = state == "done"
+		task.Missed = state == "missed"
+		task.Excused = state == "excused"
+		tasks = append(tasks, task)
+	}
+	if err = habitTaskRepo.CreateBatch(tasks); err != nil {
+		t.Fatalf("failed to persist tasks: %v", err)
+	}
+
+	stats, err := statsRepo.FindParticipantStats(grind.ID)
+	if err != nil {
+		t.Fatalf("find stats failed: %v", err)
+	}
+	byUser := map[string]*entities.ParticipantStats{}
+	for _, s := range stats {
+		byUser[s.UserID] = s
+	}
+	if len(byUser) != 2 {
+		t.Fatalf("expected stats for 2 participants, got %d", len(stats))
+	}
+
+	got := byUser[amy.ID]
+	if got.Username != "amy" || got.CompletedDays != 5 || got.ClosedDays != 6 || got.CurrentStreak != 3 || got.LongestStreak != 3 {
+		t.Fatalf("unexpected stats for amy: %+v", got)
+	}
+	if got := byUser[bob.ID]; got.CompletedDays != 0 || got.ClosedDays != 0 || got.CurrentStreak != 0 || got.LongestStreak != 0 {
+		t.Fatalf("expected empty stats for bob, got %+v", got)
+	}
+}
@@ -12,14 +12,17 @@ import (
 
 type HabitTaskSchema struct {
 	gorm.Model
-	ID           string         `json:"id" gorm:"primaryKey"`
-	TaskType     string         `json:"task_type" gorm:"not null"`
-	UserID       string         `json:"user_id" gorm:"not null"`
-	GrindID      string         `json:"grind_id" gorm:"not null"`
-	Date         time.Time      `json:"date" gorm:"not null"`
-	FinishedTime *time.Time     `json:"finished_time"`
-	Completed    bool           `json:"completed" gorm:"default:false"`
-	Metadata     datatypes.JSON `json:"metadata"`
+	ID             string         `json:"id" gorm:"primaryKey"`
+	TaskType       string         `json:"task_type" gorm:"not null"`
+	UserID         string         `json:"user_id" gorm:"not null"`
+	GrindID        string         `json:"grind_id" gorm:"not null"`
+	Date           time.Time      `json:"date" gorm:"not null"`
+	FinishedTime   *time.Time     `json:"finished_time"`
+	Completed      bool           `json:"completed" gorm:"default:false"`
+	Missed         bool           `json:"missed" gorm:"not null;default:false"`
+	Excused        bool           `json:"excused" gorm:"not null;default:false"`
+	RequiredEvents int            `json:"required_events" gorm:"not null;default:1"`
+	Metadata       datatypes.JSON `json:"metadata"`
 }
 
 func (HabitTaskSchema) TableName() string { return "habit_tasks" }
@@ -38,32 +41,57 @@ func (r *GormHabitTaskRepository) WithTx(tx *gorm.DB) repositories.HabitTaskRepo
 
 func habitTaskSchemaToEntity(s *HabitTaskSchema) *entities.HabitTask {
 	return &entities.HabitTask{
-		ID:           s.ID,
-		TaskType:     s.TaskType,
-		UserID:       s.UserID,
-		GrindID:      s.GrindID,
-		Date:         s.Date,
-		FinishedTime: s.FinishedTime,
-		Completed:    s.Completed,
-		Metadata:     s.Metadata,
+		ID:             s.ID,
+		TaskType:       s.TaskType,
+		UserID:         s.UserID,
+		GrindID:        s.GrindID,
+		Date:           s.Date,
+		FinishedTime:   s.FinishedTime,
+		Completed:      s.Completed,
+		Missed:         s.Missed,
+		Excused:        s.Excused,
+		RequiredEvents: s.RequiredEvents,
+		Metadata:       s.Metadata,
+	}
+}
+
+// habitTaskBatchSize keeps each multi-row INSERT well below Postgres' 65535 bind parameter limit.
+const habitTaskBatchSize = 500
+
+func habitTaskEntityToSchema(task *entities.HabitTask) HabitTaskSchema {
+	return HabitTaskSchema{
+		ID:             task.ID,
+		TaskType:       task.TaskType,
+		UserID:         task.UserID,
+		GrindID:        task.GrindID,
+		Date:           task.Date,
+		FinishedTime:   task.FinishedTime,
+		Completed:      task.Completed,
+		Missed:         task.Missed,
+		Excused:        task.Excused,
+		RequiredEvents: task.RequiredEvents,
+		Metadata:       task.Metadata,
 	}
 }
 
 func (r *GormHabitTaskRepository) Create(task *entities.HabitTask) error {
 	ctx := context.Background()
-	model := HabitTaskSchema{
-		ID:           task.ID,
-		TaskType:     task.TaskType,
-		UserID:       task.UserID,
-		GrindID:      task.GrindID,
-		Date:         task.Date,
-		FinishedTime: task.FinishedTime,
-		Completed:    task.Completed,
-		Metadata:     task.Metadata,
-	}
+	model := habitTaskEntityToSchema(task)
 	return r.db.WithContext(ctx).Create(&model).Error
 }
 
+func (r *GormHabitTaskRepository) CreateBatch(tasks []*entities.HabitTask) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	ctx := context.Background()
+	models := make([]HabitTaskSchema, len(tasks))
+	for i, task := range tasks {
+		models[i] = habitTaskEntityToSchema(task)
+	}
+	return r.db.WithContext(ctx).CreateInBatches(&models, habitTaskBatchSize).Error
+}
+
 func (r *GormHabitTaskRepository) FindByID(id string) (*entities.HabitTask, error) {
 	ctx := context.Background()
 	var model HabitTaskSchema
@@ -87,14 +115,18 @@ func (r *GormHabitTaskRepository) FindByGrindIDAndUserID(grindID, userID string)
 	return tasks, nil
 }
 
-func (r *GormHabitTaskRepository) FindTodayTask(userID, grindID string) (*entities.HabitTask, error) {
+func (r *GormHabitTaskRepository) FindTodayTask(userID, grindID string, loc *time.Location) (*entities.HabitTask, error) {
+	return r.FindTaskOnDay(userID, grindID, time.Now(), loc)
+}
+
+func (r *GormHabitTaskRepository) FindTaskOnDay(userID, grindID string, at time.Time, loc *time.Location) (*entities.HabitTask, error) {
 	ctx := context.Background()
 	var model HabitTaskSchema
-	today := time.Now().UTC().Truncate(24 * time.Hour)
-	tomorrow := today.Add(24 * time.Hour)
+	day := entities.LocalDayStart(at, loc)
+	nextDay := day.AddDate(0, 0, 1)
 
 	err := r.db.WithContext(ctx).
-		Where("user_id = ? AND grind_id = ? AND date >= ? AND date < ?", userID, grindID, today, tomorrow).
+		Where("user_id = ? AND grind_id = ? AND date >= ? AND date < ?", userID, grindID, day, nextDay).
 		First(&model).Error
 	if err != nil {
 		return nil, err
@@ -102,18 +134,75 @@ func (r *GormHabitTaskRepository) FindTodayTask(userID, grindID string) (*entiti
 	return habitTaskSchemaToEntity(&model), nil
 }
 
-func (r *GormHabitTaskRepository) Update(task *entities.HabitTask) error {
+func (r *GormHabitTaskRepository) FindByGrindIDInRange(grindID string, from, to time.Time) ([]*entities.HabitTask, error) {
 	ctx := context.Background()
-	model := HabitTaskSchema{
-		ID:           task.ID,
-		TaskType:     task.TaskType,
-		UserID:       task.UserID,
-		GrindID:      task.GrindID,
-		Date:         task.Date,
-		FinishedTime: task.FinishedTime,
-		Completed:    task.Completed,
-		Metadata:     task.Metadata,
+	var models []HabitTaskSchema
+
+	err := r.db.WithContext(ctx).
+		Where("grind_id = ? AND date >= ? AND date < ?", grindID, from, to).
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+	tasks := make([]*entities.HabitTask, len(models))
+	for i := range models {
+		tasks[i] = habitTaskSchemaToEntity(&models[i])
 	}
+	return tasks, nil
+}
+
+func (r *GormHabitTaskRepository) MarkMissed(taskID string) (bool, error) {
+	ctx := context.Background()
+	// Conditional update: only the first caller flips the flag, so reruns never double-count.
+	result := r.db.WithContext(ctx).
+		Model(&HabitTaskSchema{}).
+		Where("id = ? AND completed = ? AND missed = ? AND excused = ?", taskID, false, false, false).
+		Update("missed", true)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *GormHabitTaskRepository) MarkExcused(taskID string) (bool, error) {
+	ctx := context.Background()
+	result := r.db.WithContext(ctx).
+		Model(&HabitTaskSchema{}).
+		Where("id = ? AND completed = ? AND missed = ? AND excused = ?", taskID, false, false, false).
+		Update("excused", true)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *GormHabitTaskRepository) Complete(taskID string, finishedAt time.Time) (bool, error) {
+	ctx := context.Background()
+	result := r.db.WithContext(ctx).
+		Model(&HabitTaskSchema{}).
+		Where("id = ? AND missed = ? AND excused = ?", taskID, false, false).
+		Updates(map[string]interface{}{"completed": true, "finished_time": gorm.Expr("COALESCE(finished_time, ?)", finishedAt.UTC())})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *GormHabitTaskRepository) RevertCompletion(taskID string, excuse bool) (bool, error) {
+	ctx := context.Background()
+	result := r.db.WithContext(ctx).
+		Model(&HabitTaskSchema{}).
+		Where("id = ? AND completed = ?", taskID, true).
+		Updates(map[string]interface{}{"completed": false, "finished_time": nil, "missed": !excuse, "excused": excuse})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *GormHabitTaskRepository) Update(task *entities.HabitTask) error {
+	ctx := context.Background()
+	model := habitTaskEntityToSchema(task)
 	return r.db.WithContext(ctx).Model(&HabitTaskSchema{}).Where("id = ?", task.ID).Updates(&model).Error
 }
 
@@ -133,3 +222,21 @@ func (r *GormHabitTaskRepository) DeleteByGrindID(grindID string) error {
 	ctx := context.Background()
 	return r.db.WithContext(ctx).Where("grind_id = ?", grindID).Delete(&HabitTaskSchema{}).Error
 }
+
+func (r *GormHabitTaskRepository) DeleteByIDs(ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	ctx := context.Background()
+	return r.db.WithContext(ctx).Where("id IN ?", ids).Delete(&HabitTaskSchema{}).Error
+}
+
+func (r *GormHabitTaskRepository) RebaseDates(userID, fromTimezone, toTimezone string) error {
+	ctx := context.Background()
+	// date AT TIME ZONE from -> local wall time; ::date keeps the calendar day;
+	// AT TIME ZONE to -> that day's midnight in the new zone.
+	return r.db.WithContext(ctx).
+		Model(&HabitTaskSchema{}).
+		Where("user_id = ?", userID).
+		Update("date", gorm.Expr("((date AT TIME ZONE ?)::date)::timestamp AT TIME ZONE ?", fromTimezone, toTimezone)).Error
+}
@@ -0,0 +1,130 @@
+//go:build integration
+// +build integration
+
+package postgres_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/daniel0321forever/terriyaki-go/internal/infrastructure/db/postgres"
+)
+
+// yearOfTasks builds one task per day for a 365-day grind, like createParticipantTasks does.
+func yearOfTasks(t testing.TB, userID, grindID string) []*entities.HabitTask {
+	t.Helper()
+	start := time.Now().UTC().Truncate(24 * time.Hour)
+	tasks := make([]*entities.HabitTask, 0, 365)
+	for i := 0; i < 365; i++ {
+		task, err := entities.NewHabitTask(userID, grindID, start.AddDate(0, 0, i))
+		if err != nil {
+			t.Fatalf("failed to create task entity: %v", err)
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
+func resetHabitTasks(t testing.TB) {
+	t.Helper()
+	if err := postgres.Db.Exec("TRUNCATE TABLE habit_tasks CASCADE").Error; err != nil {
+		t.Fatalf("failed to reset habit_tasks: %v", err)
+	}
+}
+
+func TestGormHabitTaskRepository_CreateBatch(t *testing.T) {
+	resetHabitTasks(t)
+
+	repo := postgres.NewGormHabitTaskRepository(postgres.Db)
+	tasks := yearOfTasks(t, "user-batch", "grind-batch")
+
+	if err := repo.CreateBatch(tasks); err != nil {
+		t.Fatalf("create batch failed: %v", err)
+	}
+	if err := repo.CreateBatch(nil); err != nil {
+		t.Fatalf("expected an empty batch to be a no-op, got %v", err)
+	}
+
+	stored, err := repo.FindByGrindIDAndUserID("grind-batch", "user-batch")
+	if err != nil {
+		t.Fatalf("find tasks failed: %v", err)
+	}
+	if len(stored) != len(tasks) {
+		t.Fatalf("expected %d tasks, got %d", len(tasks), len(stored))
+	}
+}
+
+func TestGormHabitTaskRepository_Complete(t *testing.T) {
+	resetHabitTasks(t)
+
+	repo := postgres.NewGormHabitTaskRepository(postgres.Db)
+	tasks := yearOfTasks(t, "user-complete", "grind-complete")[:2]
+	if err := repo.CreateBatch(tasks); err != nil {
+		t.Fatalf("create batch failed: %v", err)
+	}
+	open, missed := tasks[0], tasks[1]
+	if marked, err := repo.MarkMissed(missed.ID); err != nil || !marked {
+		t.Fatalf("expected the task to be marked missed, got %v (%v)", marked, err)
+	}
+
+	first := time.Now().UTC().Truncate(time.Second)
+	if completed, err := repo.Complete(open.ID, first); err != nil || !completed {
+		t.Fatalf("expected the open task to be completed, got %v (%v)", completed, err)
+	}
+	// a later completion keeps the first finish time
+	if completed, err := repo.Complete(open.ID, first.Add(time.Hour)); err != nil || !completed {
+		t.Fatalf("expected the completed task to stay completed, got %v (%v)", completed, err)
+	}
+	stored, err := repo.FindByID(open.ID)
+	if err != nil {
+		t.Fatalf("find task failed: %v", err)
+	}
+	if !stored.Completed || stored.FinishedTime == nil || !stored.FinishedTime.Equal(first) {
+		t.Fatalf("expected the task completed at %v, got %+v", first, stored)
+	}
+
+	if completed, err := repo.Complete(missed.ID, first); err != nil || completed {
+		t.Fatalf("expected the missed task to stay missed, got %v (%v)", completed, err)
+	}
+	stored, err = repo.FindByID(missed.ID)
+	if err != nil {
+		t.Fatalf("find task failed: %v", err)
+	}
+	if stored.Completed || stored.FinishedTime != nil {
+		t.Fatalf("expected the missed task not to be completed, got %+v", stored)
+	}
+}
+
+// BenchmarkHabitTaskInsert compares inserting a 365-day grind's tasks one row at a time
+// (the old per-day loop) with a single CreateBatch call.
+func BenchmarkHabitTaskInsert(b *testing.B) {
+	repo := postgres.NewGormHabitTaskRepository(postgres.Db)
+
+	b.Run("per-row", func(b *testing.B) {
+		resetHabitTasks(b)
+		for n := 0; n < b.N; n++ {
+			b.StopTimer()
+			tasks := yearOfTasks(b, "user-bench", fmt.Sprintf("grind-row-%d", n))
+			b.StartTimer()
+			for _, task := range tasks {
+				if err := repo.Create(task); err != nil {
+					b.Fatalf("create failed: %v", err)
+				}
+			}
+		}
+	})
+
+	b.Run("batch", func(b *testing.B) {
+		resetHabitTasks(b)
+		for n := 0; n < b.N; n++ {
+			b.StopTimer()
+			tasks := yearOfTasks(b, "user-bench", fmt.Sprintf("grind-batch-%d", n))
+			b.StartTimer()
+			if err := repo.CreateBatch(tasks); err != nil {
+				b.Fatalf("create batch failed: %v", err)
+			}
+		}
+	})
+}
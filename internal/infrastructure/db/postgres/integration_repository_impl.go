@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"gorm.io/gorm"
+)
+
+// IntegrationSchema is the GORM mapping for a custom provider integration
+type IntegrationSchema struct {
+	gorm.Model
+	ID              string    `json:"id" gorm:"primaryKey"`
+	Name            string    `json:"name" gorm:"not null"`
+	OwnerID         string    `json:"owner_id" gorm:"not null;index"`
+	GroupID         string    `json:"group_id" gorm:"not null;default:''"`
+	Secret          string    `json:"-" gorm:"not null"`
+	OccurredAtField string    `json:"occurred_at_field" gorm:"not null;default:''"`
+	EvidenceField   string    `json:"evidence_field" gorm:"not null;default:''"`
+	EventIDField    string    `json:"event_id_field" gorm:"not null;default:''"`
+	CreatedAt       time.Time `json:"created_at" gorm:"not null"`
+	UpdatedAt       time.Time `json:"updated_at" gorm:"not null"`
+}
+
+func (IntegrationSchema) TableName() string { return "ingest_integrations" }
+
+type GormIntegrationRepository struct {
+	db *gorm.DB
+}
+
+func NewGormIntegrationRepository(db *gorm.DB) *GormIntegrationRepository {
+	return &GormIntegrationRepository{db: db}
+}
+
+func integrationSchemaToEntity(model *IntegrationSchema) *entities.Integration {
+	return &entities.Integration{
+		ID:      model.ID,
+		Name:    model.Name,
+		OwnerID: model.OwnerID,
+		GroupID: model.GroupID,
+		Secret:  model.Secret,
+		Mapping: entities.IntegrationFieldMapping{
+			OccurredAt: model.OccurredAtField,
+			Evidence:   model.EvidenceField,
+			EventID:    model.EventIDField,
+		},
+		CreatedAt: model.CreatedAt,
+		UpdatedAt: model.UpdatedAt,
+	}
+}
+
+func (r *GormIntegrationRepository) Create(integration *entities.Integration) error {
+	ctx := context.Background()
+	model := IntegrationSchema{
+		ID:              integration.ID,
+		Name:            integration.Name,
+		OwnerID:         integration.OwnerID,
+		GroupID:         integration.GroupID,
+		Secret:          integration.Secret,
+		OccurredAtField: integration.Mapping.OccurredAt,
+		EvidenceField:   integration.Mapping.Evidence,
+		EventIDField:    integration.Mapping.EventID,
+		CreatedAt:       integration.CreatedAt,
+		UpdatedAt:       integration.UpdatedAt,
+	}
+	return r.db.WithContext(ctx).Create(&model).Error
+}
+
+func (r *GormIntegrationRepository) FindByID(id string) (*entities.Integration, error) {
+	ctx := context.Background()
+	var model IntegrationSchema
+	if err := r.db.WithContext(ctx).First(&model, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return integrationSchemaToEntity(&model), nil
+}
+
+func (r *GormIntegrationRepository) FindByOwnerID(ownerID string) ([]*entities.Integration, error) {
+	ctx := context.Background()
+	var models []IntegrationSchema
+	if err := r.db.WithContext(ctx).Where("owner_id = ?", ownerID).Order("created_at ASC").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	integrations := make([]*entities.Integration, 0, len(models))
+	for i := range models {
+		integrations = append(integrations, integrationSchemaToEntity(&models[i]))
+	}
+	return integrations, nil
+}
+
+func (r *GormIntegrationRepository) Delete(id string) error {
+	ctx := context.Background()
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&IntegrationSchema{}).Error
+}
@@ -2,6 +2,8 @@ package postgres
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"time"
 
 	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
@@ -22,10 +24,36 @@ type MessageSchema struct {
 	Read               bool      `json:"read" gorm:"not null;default:false"` // whether the message has been read by the receiver
 	CreatedAt          time.Time `json:"created_at" gorm:"not null"`
 	UpdatedAt          time.Time `json:"updated_at" gorm:"not null"`
+
+	InvitationExpiresAt *time.Time `json:"invitation_expires_at"`
+	InvitationRevokedAt *time.Time `json:"invitation_revoked_at"`
 }
 
 func (MessageSchema) TableName() string { return "message" }
 
+// ConversationSettingSchema is the GORM mapping for what a user chose for their conversation with another user
+type ConversationSettingSchema struct {
+	gorm.Model
+	ID            string    `json:"id" gorm:"primaryKey"`
+	UserID        string    `json:"user_id" gorm:"not null;uniqueIndex:idx_conversation_settings_user_counterpart"`
+	CounterpartID string    `json:"counterpart_id" gorm:"not null;uniqueIndex:idx_conversation_settings_user_counterpart"`
+	Blocked       bool      `json:"blocked" gorm:"not null;default:false"`
+	Muted         bool      `json:"muted" gorm:"not null;default:false"`
+	CreatedAt     time.Time `json:"created_at" gorm:"not null"`
+	UpdatedAt     time.Time `json:"updated_at" gorm:"not null"`
+}
+
+func (ConversationSettingSchema) TableName() string { return "conversation_settings" }
+
+// conversationRow is a conversation's latest message with the reader's unread count and settings
+type conversationRow struct {
+	MessageSchema
+	CounterpartID string
+	UnreadCount   int
+	Muted         bool
+	Blocked       bool
+}
+
 type GormMessageRepository struct {
 	db *gorm.DB
 }
@@ -40,19 +68,7 @@ func (r *GormMessageRepository) WithTx(tx *gorm.DB) repositories.MessageReposito
 
 func (r *GormMessageRepository) Create(message *entities.Message) error {
 	ctx := context.Background()
-	model := MessageSchema{
-		ID:                 message.ID,
-		SenderID:           message.SenderID,
-		ReceiverID:         message.ReceiverID,
-		Content:            message.Content,
-		Type:               message.Type,
-		InvitationGrindID:  message.InvitationGrindID,
-		InvitationAccepted: message.InvitationAccepted,
-		InvitationRejected: message.InvitationRejected,
-		Read:               message.Read,
-		CreatedAt:          message.CreatedAt,
-		UpdatedAt:          message.UpdatedAt,
-	}
+	model := messageEntityToSchema(message)
 	return r.db.WithContext(ctx).Create(&model).Error
 }
 
@@ -62,104 +78,242 @@ func (r *GormMessageRepository) FindByID(id string) (*entities.Message, error) {
 	if err := r.db.WithContext(ctx).First(&model, "id = ?", id).Error; err != nil {
 		return nil, err
 	}
+	return messageSchemaToEntity(&model), nil
+}
 
-	return &entities.Message{
-		ID:                 model.ID,
-		SenderID:           model.SenderID,
-		ReceiverID:         model.ReceiverID,
-		Content:            model.Content,
-		Type:               model.Type,
-		InvitationGrindID:  model.InvitationGrindID,
-		InvitationAccepted: model.InvitationAccepted,
-		InvitationRejected: model.InvitationRejected,
-		Read:               model.Read,
-		CreatedAt:          model.CreatedAt,
-		UpdatedAt:          model.UpdatedAt,
-	}, nil
+// CreateInvitationIfAbsent relies on the partial unique index over open invitations, like
+// GormCompletionEventRepository.CreateIfAbsent.
+func (r *GormMessageRepository) CreateInvitationIfAbsent(invitation *entities.Message) (bool, error) {
+	err := r.Create(invitation)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return false, nil
+	}
+	if strings.Contains(strings.ToLower(err.Error()), "duplicate key") {
+		return false, nil
+	}
+	return false, err
 }
 
-func (r *GormMessageRepository) FindAllForReceiver(receiverID string, offset, limit int) ([]*entities.Message, error) {
+func (r *GormMessageRepository) FindOpenInvitation(grindID, receiverID string) (*entities.Message, error) {
 	ctx := context.Background()
-	var models []MessageSchema
-	query := r.db.WithContext(ctx).Where("receiver_id = ?", receiverID).Order("created_at DESC")
-	if offset > 0 {
-		query = query.Offset(offset)
-	}
-	if limit > 0 {
-		query = query.Limit(limit)
+	var model MessageSchema
+	err := r.db.WithContext(ctx).
+		Where("type = ? AND invitation_grind_id = ? AND receiver_id = ?", "invitation", grindID, receiverID).
+		Where("invitation_accepted IS NOT TRUE AND invitation_rejected IS NOT TRUE AND invitation_revoked_at IS NULL").
+		First(&model).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
 	}
-	err := query.Find(&models).Error
 	if err != nil {
 		return nil, err
 	}
+	return messageSchemaToEntity(&model), nil
+}
+
+func (r *GormMessageRepository) FindAllForReceiver(receiverID string, filter entities.MessageFilter, after entities.PageCursor, limit int) ([]*entities.Message, error) {
+	return r.findPage(r.db.Where("receiver_id = ?", receiverID), filter, after, limit)
+}
+
+func (r *GormMessageRepository) FindAllFromSender(senderID string, filter entities.MessageFilter, after entities.PageCursor, limit int) ([]*entities.Message, error) {
+	return r.findPage(r.db.Where("sender_id = ?", senderID), filter, after, limit)
+}
+
+func (r *GormMessageRepository) CountForReceiver(receiverID string, filter entities.MessageFilter) (int64, error) {
+	ctx := context.Background()
+	var count int64
+	query := filterMessages(r.db.WithContext(ctx).Model(&MessageSchema{}).Where("receiver_id = ?", receiverID), filter)
+	if err := query.Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// findPage lists the messages of query that match filter, newest first, after the cursor
+func (r *GormMessageRepository) findPage(query *gorm.DB, filter entities.MessageFilter, after entities.PageCursor, limit int) ([]*entities.Message, error) {
+	ctx := context.Background()
+	var models []MessageSchema
+	query = pageAfter(filterMessages(query.WithContext(ctx), filter), "message", after, limit)
+	if err := query.Find(&models).Error; err != nil {
+		return nil, err
+	}
 
 	messages := make([]*entities.Message, len(models))
-	for i, model := range models {
-		messages[i] = &entities.Message{
-			ID:                 model.ID,
-			SenderID:           model.SenderID,
-			ReceiverID:         model.ReceiverID,
-			Content:            model.Content,
-			Type:               model.Type,
-			InvitationGrindID:  model.InvitationGrindID,
-			InvitationAccepted: model.InvitationAccepted,
-			InvitationRejected: model.InvitationRejected,
-			Read:               model.Read,
-			CreatedAt:          model.CreatedAt,
-			UpdatedAt:          model.UpdatedAt,
-		}
+	for i := range models {
+		messages[i] = messageSchemaToEntity(&models[i])
 	}
 	return messages, nil
 }
 
-func (r *GormMessageRepository) FindAllFromSender(senderID string, offset, limit int) ([]*entities.Message, error) {
+func filterMessages(query *gorm.DB, filter entities.MessageFilter) *gorm.DB {
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.Read != nil {
+		query = query.Where("read = ?", *filter.Read)
+	}
+	if filter.GrindID != "" {
+		query = query.Where("invitation_grind_id = ?", filter.GrindID)
+	}
+	return query
+}
+
+func (r *GormMessageRepository) Update(message *entities.Message) error {
 	ctx := context.Background()
-	var models []MessageSchema
-	query := r.db.WithContext(ctx).Where("sender_id = ?", senderID).Order("created_at DESC")
-	if offset > 0 {
-		query = query.Offset(offset)
+	model := messageEntityToSchema(message)
+	model.UpdatedAt = time.Now().UTC()
+	return r.db.WithContext(ctx).Model(&MessageSchema{}).Where("id = ?", message.ID).Updates(&model).Error
+}
+
+func messageEntityToSchema(message *entities.Message) MessageSchema {
+	return MessageSchema{
+		ID:                  message.ID,
+		SenderID:            message.SenderID,
+		ReceiverID:          message.ReceiverID,
+		Content:             message.Content,
+		Type:                message.Type,
+		InvitationGrindID:   message.InvitationGrindID,
+		InvitationAccepted:  message.InvitationAccepted,
+		InvitationRejected:  message.InvitationRejected,
+		Read:                message.Read,
+		CreatedAt:           message.CreatedAt,
+		UpdatedAt:           message.UpdatedAt,
+		InvitationExpiresAt: message.InvitationExpiresAt,
+		InvitationRevokedAt: message.InvitationRevokedAt,
+	}
+}
+
+func messageSchemaToEntity(model *MessageSchema) *entities.Message {
+	return &entities.Message{
+		ID:                  model.ID,
+		SenderID:            model.SenderID,
+		ReceiverID:          model.ReceiverID,
+		Content:             model.Content,
+		Type:                model.Type,
+		InvitationGrindID:   model.InvitationGrindID,
+		InvitationAccepted:  model.InvitationAccepted,
+		InvitationRejected:  model.InvitationRejected,
+		Read:                model.Read,
+		CreatedAt:           model.CreatedAt,
+		UpdatedAt:           model.UpdatedAt,
+		InvitationExpiresAt: model.InvitationExpiresAt,
+		InvitationRevokedAt: model.InvitationRevokedAt,
 	}
+}
+
+// conversationsQuery picks the latest general message of each of a user's conversations,
+// counts the counterpart's messages the user has not read, and joins the user's settings.
+// FindConversations adds the cursor, order and limit; like pageAfter, it pages by the
+// (created_at, id) of each conversation's latest message.
+const conversationsQuery = `
+WITH thread AS (
+	SELECT m.*, CASE WHEN m.sender_id = @user THEN m.receiver_id ELSE m.sender_id END AS counterpart_id
+	FROM message m
+	WHERE m.type = 'general' AND m.deleted_at IS NULL AND (m.sender_id = @user OR m.receiver_id = @user)
+), latest AS (
+	SELECT DISTINCT ON (counterpart_id) * FROM thread ORDER BY counterpart_id, created_at DESC, id DESC
+), unread AS (
+	SELECT counterpart_id, COUNT(*) AS unread_count FROM thread
+	WHERE receiver_id = @user AND NOT read
+	GROUP BY counterpart_id
+)
+SELECT latest.*,
+	COALESCE(unread.unread_count, 0) AS unread_count,
+	COALESCE(s.muted, FALSE) AS muted,
+	COALESCE(s.blocked, FALSE) AS blocked
+FROM latest
+LEFT JOIN unread ON unread.counterpart_id = latest.counterpart_id
+LEFT JOIN conversation_settings s
+	ON s.user_id = @user AND s.counterpart_id = latest.counterpart_id AND s.deleted_at IS NULL`
+
+func (r *GormMessageRepository) FindConversations(userID string, after entities.PageCursor, limit int) ([]*entities.Conversation, error) {
+	ctx := context.Background()
+	query := conversationsQuery
+	args := map[string]interface{}{"user": userID}
+	if !after.IsZero() {
+		query += "\nWHERE (latest.created_at, latest.id) < (@after_created_at, @after_id)"
+		args["after_created_at"] = after.CreatedAt
+		args["after_id"] = after.ID
+	}
+	query += "\nORDER BY latest.created_at DESC, latest.id DESC"
 	if limit > 0 {
-		query = query.Limit(limit)
+		query += " LIMIT @limit"
+		args["limit"] = limit
 	}
-	err := query.Find(&models).Error
-	if err != nil {
+
+	var rows []conversationRow
+	if err := r.db.WithContext(ctx).Raw(query, args).Scan(&rows).Error; err != nil {
 		return nil, err
 	}
-
-	messages := make([]*entities.Message, len(models))
-	for i, model := range models {
-		messages[i] = &entities.Message{
-			ID:                 model.ID,
-			SenderID:           model.SenderID,
-			ReceiverID:         model.ReceiverID,
-			Content:            model.Content,
-			Type:               model.Type,
-			InvitationGrindID:  model.InvitationGrindID,
-			InvitationAccepted: model.InvitationAccepted,
-			InvitationRejected: model.InvitationRejected,
-			Read:               model.Read,
-			CreatedAt:          model.CreatedAt,
-			UpdatedAt:          model.UpdatedAt,
+	conversations := make([]*entities.Conversation, len(rows))
+	for i := range rows {
+		conversations[i] = &entities.Conversation{
+			CounterpartID: rows[i].CounterpartID,
+			LastMessage:   messageSchemaToEntity(&rows[i].MessageSchema),
+			UnreadCount:   rows[i].UnreadCount,
+			Muted:         rows[i].Muted,
+			Blocked:       rows[i].Blocked,
 		}
 	}
-	return messages, nil
+	return conversations, nil
 }
 
-func (r *GormMessageRepository) Update(message *entities.Message) error {
+func (r *GormMessageRepository) FindConversationMessages(userID, counterpartID string, after entities.PageCursor, limit int) ([]*entities.Message, error) {
+	query := r.db.Where("((sender_id = ? AND receiver_id = ?) OR (sender_id = ? AND receiver_id = ?))",
+		userID, counterpartID, counterpartID, userID)
+	return r.findPage(query, entities.MessageFilter{Type: "general"}, after, limit)
+}
+
+func (r *GormMessageRepository) MarkConversationRead(userID, counterpartID string) (int64, error) {
+	ctx := context.Background()
+	result := r.db.WithContext(ctx).
+		Model(&MessageSchema{}).
+		Where("type = ? AND sender_id = ? AND receiver_id = ? AND read = ?", "general", counterpartID, userID, false).
+		Updates(map[string]interface{}{"read": true, "updated_at": time.Now().UTC()})
+	return result.RowsAffected, result.Error
+}
+
+func (r *GormMessageRepository) FindConversationSetting(userID, counterpartID string) (*entities.ConversationSetting, error) {
 	ctx := context.Background()
-	model := MessageSchema{
-		ID:                 message.ID,
-		SenderID:           message.SenderID,
-		ReceiverID:         message.ReceiverID,
-		Content:            message.Content,
-		Type:               message.Type,
-		InvitationGrindID:  message.InvitationGrindID,
-		InvitationAccepted: message.InvitationAccepted,
-		InvitationRejected: message.InvitationRejected,
-		Read:               message.Read,
-		CreatedAt:          message.CreatedAt,
-		UpdatedAt:          time.Now().UTC(),
+	var model ConversationSettingSchema
+	err := r.db.WithContext(ctx).First(&model, "user_id = ? AND counterpart_id = ?", userID, counterpartID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
 	}
-	return r.db.WithContext(ctx).Model(&MessageSchema{}).Where("id = ?", message.ID).Updates(&model).Error
+	if err != nil {
+		return nil, err
+	}
+	return &entities.ConversationSetting{
+		ID:            model.ID,
+		UserID:        model.UserID,
+		CounterpartID: model.CounterpartID,
+		Blocked:       model.Blocked,
+		Muted:         model.Muted,
+		CreatedAt:     model.CreatedAt,
+		UpdatedAt:     model.UpdatedAt,
+	}, nil
+}
+
+func (r *GormMessageRepository) CreateConversationSetting(setting *entities.ConversationSetting) error {
+	ctx := context.Background()
+	model := ConversationSettingSchema{
+		ID:            setting.ID,
+		UserID:        setting.UserID,
+		CounterpartID: setting.CounterpartID,
+		Blocked:       setting.Blocked,
+		Muted:         setting.Muted,
+		CreatedAt:     setting.CreatedAt,
+		UpdatedAt:     setting.UpdatedAt,
+	}
+	return r.db.WithContext(ctx).Create(&model).Error
+}
+
+func (r *GormMessageRepository) UpdateConversationSetting(setting *entities.ConversationSetting) error {
+	ctx := context.Background()
+	return r.db.WithContext(ctx).
+		Model(&ConversationSettingSchema{}).
+		Where("id = ?", setting.ID).
+		Updates(map[string]interface{}{"blocked": setting.Blocked, "muted": setting.Muted, "updated_at": time.Now().UTC()}).Error
 }
@@ -0,0 +1,275 @@
+//go:build integration
+// +build integration
+
+package postgres_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/daniel0321forever/terriyaki-go/internal/infrastructure/db/postgres"
+)
+
+func resetMessages(t testing.TB) {
+	t.Helper()
+	if err := postgres.Db.Exec("TRUNCATE TABLE message, conversation_settings CASCADE").Error; err != nil {
+		t.Fatalf("failed to reset messages: %v", err)
+	}
+}
+
+func TestGormMessageRepository_Conversations(t *testing.T) {
+	resetRepoTables(t)
+	resetMessages(t)
+
+	userRepo := postgres.NewGormUserRepository(postgres.Db)
+	repo := postgres.NewGormMessageRepository(postgres.Db)
+
+	amy, _ := entities.NewUser("amy", "amy@example.com", "hashed-pass", "")
+	bob, _ := entities.NewUser("bob", "bob@example.com", "hashed-pass", "")
+	cat, _ := entities.NewUser("cat", "cat@example.com", "hashed-pass", "")
+	for _, user := range []*entities.User{amy, bob, cat} {
+		if err := userRepo.Create(user); err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+	}
+
+	start := time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC)
+	send := func(from, to *entities.User, content, messageType string, minute int) *entities.Message {
+		t.Helper()
+		message, err := entities.NewMessage(from.ID, to.ID, content, messageType, "grind-1", false, false)
+		if err != nil {
+			t.Fatalf("failed to create message entity: %v", err)
+		}
+		message.CreatedAt = start.Add(time.Duration(minute) * time.Minute)
+		if err := repo.Create(message); err != nil {
+			t.Fatalf("failed to persist message: %v", err)
+		}
+		return message
+	}
+	send(bob, amy, "hi amy", "general", 0)
+	send(bob, amy, "are you there?", "general", 1)
+	send(amy, bob, "yes", "general", 2)
+	send(cat, amy, "join my grind", "invitation", 3)
+	latest := send(cat, amy, "hello from cat", "general", 4)
+
+	setting, _ := entities.NewConversationSetting(amy.ID, cat.ID)
+	setting.Muted = true
+	if err := repo.CreateConversationSetting(setting); err != nil {
+		t.Fatalf("failed to persist setting: %v", err)
+	}
+
+	conversations, err := repo.FindConversations(amy.ID, entities.PageCursor{}, 0)
+	if err != nil {
+		t.Fatalf("find conversations failed: %v", err)
+	}
+	if len(conversations) != 2 {
+		t.Fatalf("expected 2 conversations, got %d", len(conversations))
+	}
+	if got := conversations[0]; got.CounterpartID != cat.ID || got.LastMessage.ID != latest.ID || got.UnreadCount != 1 || !got.Muted {
+		t.Fatalf("unexpected conversation with cat: %+v", got)
+	}
+	if got := conversations[1]; got.CounterpartID != bob.ID || got.LastMessage.Content != "yes" || got.UnreadCount != 2 || got.Muted {
+		t.Fatalf("unexpected conversation with bob: %+v", got)
+	}
+
+	thread, err := repo.FindConversationMessages(amy.ID, bob.ID, entities.PageCursor{}, 2)
+	if err != nil {
+		t.Fatalf("find conversation messages failed: %v", err)
+	}
+	if len(thread) != 2 || thread[0].Content != "yes" || thread[1].Content != "are you there?" {
+		t.Fatalf("unexpected thread: %+v", thread)
+	}
+	// a message sent while paging neither shifts nor repeats the next page
+	send(bob, amy, "still there?", "general", 5)
+	thread, err = repo.FindConversationMessages(amy.ID, bob.ID, entities.PageCursor{CreatedAt: thread[1].CreatedAt, ID: thread[1].ID}, 2)
+	if err != nil {
+		t.Fatalf("find conversation messages failed: %v", err)
+	}
+	if len(thread) != 1 || thread[0].Content != "hi amy" {
+		t.Fatalf("unexpected second page: %+v", thread)
+	}
+
+	read, err := repo.MarkConversationRead(amy.ID, bob.ID)
+	if err != nil || read != 3 {
+		t.Fatalf("expected 3 messages marked read, got %d (%v)", read, err)
+	}
+	conversations, _ = repo.FindConversations(amy.ID, entities.PageCursor{}, 1)
+	if len(conversations) != 1 || conversations[0].CounterpartID != bob.ID || conversations[0].UnreadCount != 0 {
+		t.Fatalf("expected only the read conversation with bob, got %+v", conversations)
+	}
+	last := conversations[0].LastMessage
+	conversations, _ = repo.FindConversations(amy.ID, entities.PageCursor{CreatedAt: last.CreatedAt, ID: last.ID}, 1)
+	if len(conversations) != 1 || conversations[0].CounterpartID != cat.ID {
+		t.Fatalf("expected the conversation with cat, got %+v", conversations)
+	}
+}
+
+func TestGormMessageRepository_ConversationSetting(t *testing.T) {
+	resetRepoTables(t)
+	resetMessages(t)
+
+	userRepo := postgres.NewGormUserRepository(postgres.Db)
+	repo := postgres.NewGormMessageRepository(postgres.Db)
+
+	amy, _ := entities.NewUser("amy", "amy@example.com", "hashed-pass", "")
+	bob, _ := entities.NewUser("bob", "bob@example.com", "hashed-pass", "")
+	for _, user := range []*entities.User{amy, bob} {
+		if err := userRepo.Create(user); err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+	}
+
+	found, err := repo.FindConversationSetting(amy.ID, bob.ID)
+	if err != nil || found != nil {
+		t.Fatalf("expected no setting, got %+v (%v)", found, err)
+	}
+
+	setting, _ := entities.NewConversationSetting(amy.ID, bob.ID)
+	setting.Blocked = true
+	if err := repo.CreateConversationSetting(setting); err != nil {
+		t.Fatalf("create setting failed: %v", err)
+	}
+	setting.Blocked = false
+	setting.Muted = true
+	if err := repo.UpdateConversationSetting(setting); err != nil {
+		t.Fatalf("update setting failed: %v", err)
+	}
+
+	found, err = repo.FindConversationSetting(amy.ID, bob.ID)
+	if err != nil || found == nil || found.Blocked || !found.Muted {
+		t.Fatalf("unexpected setting: %+v (%v)", found, err)
+	}
+	if other, _ := repo.FindConversationSetting(bob.ID, amy.ID); other != nil {
+		t.Fatalf("expected settings to be per user, got %+v", other)
+	}
+}
+
+func TestGormMessageRepository_FindAllForReceiverPages(t *testing.T) {
+	resetRepoTables(t)
+	resetMessages(t)
+
+	userRepo := postgres.NewGormUserRepository(postgres.Db)
+	repo := postgres.NewGormMessageRepository(postgres.Db)
+
+	amy, _ := entities.NewUser("amy", "amy@example.com", "hashed-pass", "")
+	bob, _ := entities.NewUser("bob", "bob@example.com", "hashed-pass", "")
+	for _, user := range []*entities.User{amy, bob} {
+		if err := userRepo.Create(user); err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+	}
+
+	start := time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC)
+	send := func(messageType, grindID string, minute int, read bool) *entities.Message {
+		t.Helper()
+		message, err := entities.NewMessage(bob.ID, amy.ID, "hello", messageType, grindID, false, false)
+		if err != nil {
+			t.Fatalf("failed to create message entity: %v", err)
+		}
+		message.CreatedAt = start.Add(time.Duration(minute) * time.Minute)
+		message.Read = read
+		if err := repo.Create(message); err != nil {
+			t.Fatalf("failed to persist message: %v", err)
+		}
+		return message
+	}
+	// two messages share a timestamp, so only the id tells them apart
+	send("general", "", 0, true)
+	send("invitation", "grind-1", 1, false)
+	send("general", "", 1, false)
+	send("invitation", "grind-2", 2, false)
+
+	var seen []string
+	after := entities.PageCursor{}
+	for page := 0; ; page++ {
+		messages, err := repo.FindAllForReceiver(amy.ID, entities.MessageFilter{}, after, 2)
+		if err != nil {
+			t.Fatalf("find page %d failed: %v", page, err)
+		}
+		if len(messages) == 0 {
+			break
+		}
+		for _, message := range messages {
+			seen = append(seen, message.ID)
+		}
+		last := messages[len(messages)-1]
+		after = entities.PageCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+		if page == 0 {
+			// a message arriving mid-listing must not shift the pages that follow
+			send("general", "", 3, false)
+		}
+	}
+	if len(seen) != 4 {
+		t.Fatalf("expected the 4 messages sent before paging, got %d", len(seen))
+	}
+	unique := map[string]bool{}
+	for _, id := range seen {
+		unique[id] = true
+	}
+	if len(unique) != len(seen) {
+		t.Fatalf("expected no message twice, got %v", seen)
+	}
+
+	unread := false
+	invitations, err := repo.FindAllForReceiver(amy.ID, entities.MessageFilter{Type: "invitation", Read: &unread, GrindID: "grind-2"}, entities.PageCursor{}, 0)
+	if err != nil || len(invitations) != 1 || invitations[0].InvitationGrindID != "grind-2" {
+		t.Fatalf("expected the unread invitation to grind-2, got %+v (%v)", invitations, err)
+	}
+	count, err := repo.CountForReceiver(amy.ID, entities.MessageFilter{Read: &unread})
+	if err != nil || count != 4 {
+		t.Fatalf("expected 4 unread messages, got %d (%v)", count, err)
+	}
+	sent, err := repo.FindAllFromSender(bob.ID, entities.MessageFilter{Type: "general"}, entities.PageCursor{}, 0)
+	if err != nil || len(sent) != 3 {
+		t.Fatalf("expected 3 general messages from bob, got %d (%v)", len(sent), err)
+	}
+}
+
+func TestGormMessageRepository_OpenInvitationIsUnique(t *testing.T) {
+	resetRepoTables(t)
+	resetMessages(t)
+
+	userRepo := postgres.NewGormUserRepository(postgres.Db)
+	repo := postgres.NewGormMessageRepository(postgres.Db)
+
+	amy, _ := entities.NewUser("amy", "amy@example.com", "hashed-pass", "")
+	bob, _ := entities.NewUser("bob", "bob@example.com", "hashed-pass", "")
+	for _, user := range []*entities.User{amy, bob} {
+		if err := userRepo.Create(user); err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+	}
+	invite := func() *entities.Message {
+		t.Helper()
+		invitation, err := entities.NewMessage(amy.ID, bob.ID, "join my grind", "invitation", "grind-1", false, false)
+		if err != nil {
+			t.Fatalf("failed to create invitation entity: %v", err)
+		}
+		return invitation
+	}
+
+	first := invite()
+	if created, err := repo.CreateInvitationIfAbsent(first); err != nil || !created {
+		t.Fatalf("expected the first invitation to be created, got %v (%v)", created, err)
+	}
+	if created, err := repo.CreateInvitationIfAbsent(invite()); err != nil || created {
+		t.Fatalf("expected a second open invitation to be refused, got %v (%v)", created, err)
+	}
+
+	open, err := repo.FindOpenInvitation("grind-1", bob.ID)
+	if err != nil || open == nil || open.ID != first.ID || open.InvitationExpiresAt == nil {
+		t.Fatalf("expected the first invitation to be open, got %+v (%v)", open, err)
+	}
+
+	open.RevokeInvitation(time.Now().UTC())
+	if err := repo.Update(open); err != nil {
+		t.Fatalf("failed to revoke invitation: %v", err)
+	}
+	if open, err := repo.FindOpenInvitation("grind-1", bob.ID); err != nil || open != nil {
+		t.Fatalf("expected no open invitation after revoking, got %+v (%v)", open, err)
+	}
+	if created, err := repo.CreateInvitationIfAbsent(invite()); err != nil || !created {
+		t.Fatalf("expected a new invitation once the first was revoked, got %v (%v)", created, err)
+	}
+}
@@ -0,0 +1,92 @@
+//go:build integration
+// +build integration
+
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
+	"github.com/daniel0321forever/terriyaki-go/internal/application/services"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/daniel0321forever/terriyaki-go/internal/infrastructure/db/postgres"
+	"github.com/daniel0321forever/terriyaki-go/internal/infrastructure/notification"
+	"github.com/daniel0321forever/terriyaki-go/internal/infrastructure/roadmap"
+)
+
+// TestAcceptInvitationNotifiesInvitor checks that a message created inside a transaction
+// reaches the stream once the transaction has committed.
+func TestAcceptInvitationNotifiesInvitor(t *testing.T) {
+	resetRepoTables(t)
+
+	userRepo := postgres.NewGormUserRepository(postgres.Db)
+	grindRepo := postgres.NewGormGrindRepository(postgres.Db)
+	participationRepo := postgres.NewGormParticipationRepository(postgres.Db)
+	habitTaskRepo := postgres.NewGormHabitTaskRepository(postgres.Db)
+	gormMessageRepo := postgres.NewGormMessageRepository(postgres.Db)
+
+	broker := notification.NewMemoryBroker(notification.DefaultBacklog)
+	notificationService := services.NewNotificationService(broker, userRepo, grindRepo, gormMessageRepo, habitTaskRepo,
+		participationRepo, postgres.NewGormPartnerGroupRepository(postgres.Db))
+	messageRepo := notificationService.MessageRepository(gormMessageRepo)
+
+	invitor, _ := entities.NewUser("invitor", "invitor@example.com", "hashed-pass", "")
+	accepter, _ := entities.NewUser("accepter", "accepter@example.com", "hashed-pass", "")
+	for _, user := range []*entities.User{invitor, accepter} {
+		if err := userRepo.Create(user); err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+	}
+	grind, _ := entities.NewGrind(2, 50, time.Now().UTC())
+	if err := grindRepo.Create(grind); err != nil {
+		t.Fatalf("failed to create grind: %v", err)
+	}
+	invitorParticipation, _ := entities.NewParticipation(invitor.ID, grind.ID)
+	if err := participationRepo.Create(invitorParticipation); err != nil {
+		t.Fatalf("failed to create invitor participation: %v", err)
+	}
+	inviteMsg, _ := entities.NewMessage(invitor.ID, accepter.ID, "join my grind", "invitation", grind.ID, false, false)
+	if err := gormMessageRepo.Create(inviteMsg); err != nil {
+		t.Fatalf("failed to create invitation message: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := broker.Subscribe(ctx, invitor.ID, "")
+	if err != nil {
+		t.Fatalf("subscribe failed: %v", err)
+	}
+
+	grindService := services.NewGrindService(postgres.Db, grindRepo, userRepo, habitTaskRepo, participationRepo,
+		messageRepo, postgres.NewGormStakeChangeRepository(postgres.Db), roadmap.NewEmbeddedRoadmapRepository())
+	err = grindService.AcceptInvitation(
+		dto.AddParticipationDTO{GrindID: grind.ID, UserID: accepter.ID},
+		dto.UpdateMessageInvitationAcceptedStatusDTO{MessageID: inviteMsg.ID, Accepted: true},
+		dto.CreateInvitationAcceptedMessageDTO{AccepterID: accepter.ID, InvitorID: invitor.ID, GrindID: grind.ID},
+		messageRepo,
+	)
+	if err != nil {
+		t.Fatalf("accept invitation failed: %v", err)
+	}
+
+	var types []entities.NotificationType
+	for len(types) < 2 {
+		select {
+		case received := <-stream:
+			types = append(types, received.Type)
+		case <-time.After(time.Second):
+			t.Fatalf("expected a message and an invitation status, got %v", types)
+		}
+	}
+	if types[0] != entities.NotificationTypeMessage || types[1] != entities.NotificationTypeInvitationStatus {
+		t.Fatalf("unexpected notifications: %v", types)
+	}
+
+	var participations int64
+	postgres.Db.Table("participation").Where("grind_id = ?", grind.ID).Count(&participations)
+	if participations != 2 {
+		t.Fatalf("expected the accepter's participation to be committed, got %d rows", participations)
+	}
+}
@@ -0,0 +1,20 @@
+package postgres
+
+import (
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"gorm.io/gorm"
+)
+
+// pageAfter orders query newest first by table's (created_at, id) and keeps the rows after the
+// cursor. Comparing the pair as a row value stays correct while new rows are inserted, which an
+// offset does not; a limit <= 0 keeps every remaining row.
+func pageAfter(query *gorm.DB, table string, after entities.PageCursor, limit int) *gorm.DB {
+	query = query.Order(table + ".created_at DESC, " + table + ".id DESC")
+	if !after.IsZero() {
+		query = query.Where("("+table+".created_at, "+table+".id) < (?, ?)", after.CreatedAt, after.ID)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	return query
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
 	"github.com/daniel0321forever/terriyaki-go/internal/domain/repositories"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ParticipationSchema struct {
@@ -15,13 +16,31 @@ type ParticipationSchema struct {
 	UserID       string    `json:"user_id" gorm:"not null;constraint:OnDelete:CASCADE;"`
 	GrindID      string    `json:"grind_id" gorm:"not null;constraint:OnDelete:CASCADE;"`
 	MissedDays   int       `json:"missed_days" gorm:"not null;default:0"`
+	FrozenDays   int       `json:"frozen_days" gorm:"not null;default:0"`
 	TotalPenalty int       `json:"total_penalty" gorm:"not null;default:0"`
 	Quitted      bool      `json:"quitted" gorm:"not null;default:false"`
 	QuittedAt    time.Time `json:"quitted_at" gorm:""`
+	Finalized    bool      `json:"finalized" gorm:"not null;default:false"`
+	FinalizedAt  time.Time `json:"finalized_at" gorm:""`
 }
 
 func (ParticipationSchema) TableName() string { return "participation" }
 
+func participationSchemaToEntity(model *ParticipationSchema) *entities.Participation {
+	return &entities.Participation{
+		ID:           model.ID,
+		UserID:       model.UserID,
+		GrindID:      model.GrindID,
+		MissedDays:   model.MissedDays,
+		FrozenDays:   model.FrozenDays,
+		TotalPenalty: model.TotalPenalty,
+		Quitted:      model.Quitted,
+		QuittedAt:    model.QuittedAt,
+		Finalized:    model.Finalized,
+		FinalizedAt:  model.FinalizedAt,
+	}
+}
+
 type GormParticipationRepository struct {
 	db *gorm.DB
 }
@@ -41,9 +60,12 @@ func (r *GormParticipationRepository) Create(participation *entities.Participati
 		UserID:       participation.UserID,
 		GrindID:      participation.GrindID,
 		MissedDays:   participation.MissedDays,
+		FrozenDays:   participation.FrozenDays,
 		TotalPenalty: participation.TotalPenalty,
 		Quitted:      participation.Quitted,
 		QuittedAt:    participation.QuittedAt,
+		Finalized:    participation.Finalized,
+		FinalizedAt:  participation.FinalizedAt,
 	}
 	return r.db.WithContext(ctx).Create(&model).Error
 }
@@ -56,15 +78,7 @@ func (r *GormParticipationRepository) FindByParticipationId(ParticipationID stri
 		return nil, err
 	}
 
-	return &entities.Participation{
-		ID:           model.ID,
-		UserID:       model.UserID,
-		GrindID:      model.GrindID,
-		MissedDays:   model.MissedDays,
-		TotalPenalty: model.TotalPenalty,
-		Quitted:      model.Quitted,
-		QuittedAt:    model.QuittedAt,
-	}, nil
+	return participationSchemaToEntity(&model), nil
 }
 
 func (r *GormParticipationRepository) FindByUserAndGrind(userID string, grindID string) (*entities.Participation, error) {
@@ -74,15 +88,33 @@ func (r *GormParticipationRepository) FindByUserAndGrind(userID string, grindID
 	if err != nil {
 		return nil, err
 	}
-	return &entities.Participation{
-		ID:           model.ID,
-		UserID:       model.UserID,
-		GrindID:      model.GrindID,
-		MissedDays:   model.MissedDays,
-		TotalPenalty: model.TotalPenalty,
-		Quitted:      model.Quitted,
-		QuittedAt:    model.QuittedAt,
-	}, nil
+	return participationSchemaToEntity(&model), nil
+}
+
+func (r *GormParticipationRepository) FindByUserAndGrindForUpdate(userID string, grindID string) (*entities.Participation, error) {
+	ctx := context.Background()
+	var model ParticipationSchema
+	err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND grind_id = ?", userID, grindID).
+		First(&model).Error
+	if err != nil {
+		return nil, err
+	}
+	return participationSchemaToEntity(&model), nil
+}
+
+func (r *GormParticipationRepository) FindByGrindID(grindID string) ([]*entities.Participation, error) {
+	ctx := context.Background()
+	var models []ParticipationSchema
+	if err := r.db.WithContext(ctx).Where("grind_id = ?", grindID).Find(&models).Error; err != nil {
+		return nil, err
+	}
+	participations := make([]*entities.Participation, len(models))
+	for i := range models {
+		participations[i] = participationSchemaToEntity(&models[i])
+	}
+	return participations, nil
 }
 
 func (r *GormParticipationRepository) Update(participation *entities.Participation) error {
@@ -92,9 +124,12 @@ func (r *GormParticipationRepository) Update(participation *entities.Participati
 		UserID:       participation.UserID,
 		GrindID:      participation.GrindID,
 		MissedDays:   participation.MissedDays,
+		FrozenDays:   participation.FrozenDays,
 		TotalPenalty: participation.TotalPenalty,
 		Quitted:      participation.Quitted,
 		QuittedAt:    participation.QuittedAt,
+		Finalized:    participation.Finalized,
+		FinalizedAt:  participation.FinalizedAt,
 	}
 	return r.db.WithContext(ctx).
 		Model(&ParticipationSchema{}).
@@ -0,0 +1,94 @@
+//go:build integration
+// +build integration
+
+package postgres_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
+	"github.com/daniel0321forever/terriyaki-go/internal/application/services"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	domainservices "github.com/daniel0321forever/terriyaki-go/internal/domain/services"
+	"github.com/daniel0321forever/terriyaki-go/internal/infrastructure/db/postgres"
+)
+
+// TestConcurrentMissedDayEvaluations evaluates two missed days of the same participant at
+// once, as two API replicas catching up would, and expects both days to be charged.
+func TestConcurrentMissedDayEvaluations(t *testing.T) {
+	resetRepoTables(t)
+	resetHabitTasks(t)
+
+	userRepo := postgres.NewGormUserRepository(postgres.Db)
+	grindRepo := postgres.NewGormGrindRepository(postgres.Db)
+	participationRepo := postgres.NewGormParticipationRepository(postgres.Db)
+	habitTaskRepo := postgres.NewGormHabitTaskRepository(postgres.Db)
+
+	user, err := entities.NewUser("missedtwice", "missedtwice@example.com", "hashed-pass", "")
+	if err != nil {
+		t.Fatalf("failed to create user entity: %v", err)
+	}
+	if err := userRepo.Create(user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	start := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -5)
+	grind, err := entities.NewGrind(10, 100, start)
+	if err != nil {
+		t.Fatalf("failed to create grind entity: %v", err)
+	}
+	grind.State = entities.GrindStateActive
+	if err := grindRepo.Create(grind); err != nil {
+		t.Fatalf("failed to create grind: %v", err)
+	}
+	participation, err := entities.NewParticipation(user.ID, grind.ID)
+	if err != nil {
+		t.Fatalf("failed to create participation entity: %v", err)
+	}
+	if err := participationRepo.Create(participation); err != nil {
+		t.Fatalf("failed to create participation: %v", err)
+	}
+	tasks := make([]*entities.HabitTask, 0, 2)
+	for i := 0; i < 2; i++ {
+		task, err := entities.NewHabitTask(user.ID, grind.ID, start.AddDate(0, 0, i))
+		if err != nil {
+			t.Fatalf("failed to create task entity: %v", err)
+		}
+		tasks = append(tasks, task)
+	}
+	if err := habitTaskRepo.CreateBatch(tasks); err != nil {
+		t.Fatalf("failed to create tasks: %v", err)
+	}
+
+	svc := services.NewMissedDayService(postgres.Db, grindRepo, userRepo, habitTaskRepo, participationRepo)
+	var wg sync.WaitGroup
+	errs := make([]error, len(tasks))
+	for i := range tasks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = svc.EvaluateDay(dto.EvaluateDayDTO{Day: start.AddDate(0, 0, i)})
+		}(i)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("evaluating day %d failed: %v", i, err)
+		}
+	}
+
+	want := &entities.Participation{}
+	policy := domainservices.NewPenaltyPolicyService()
+	policy.RecordMissedDay(grind, want)
+	policy.RecordMissedDay(grind, want)
+
+	stored, err := participationRepo.FindByUserAndGrind(user.ID, grind.ID)
+	if err != nil {
+		t.Fatalf("failed to find participation: %v", err)
+	}
+	if stored.MissedDays != want.MissedDays || stored.FrozenDays != want.FrozenDays || stored.TotalPenalty != want.TotalPenalty {
+		t.Fatalf("expected missed=%d frozen=%d penalty=%d, got missed=%d frozen=%d penalty=%d",
+			want.MissedDays, want.FrozenDays, want.TotalPenalty, stored.MissedDays, stored.FrozenDays, stored.TotalPenalty)
+	}
+}
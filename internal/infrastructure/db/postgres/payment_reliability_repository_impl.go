@@ -20,6 +20,7 @@ func (PaymentIdempotencySchema) TableName() string { return "payment_idempotency
 type PaymentSettlementSchema struct {
 	gorm.Model
 	UserID            string `json:"user_id" gorm:"not null"`
+	GrindID           string `json:"grind_id" gorm:"index"`
 	Operation         string `json:"operation" gorm:"not null"`
 	IdempotencyKey    string `json:"idempotency_key" gorm:"not null;index:idx_payment_settlement_op_key,unique"`
 	Provider          string `json:"provider" gorm:"not null"`
@@ -88,6 +89,7 @@ func NewGormPaymentSettlementRepository(db *gorm.DB) *GormPaymentSettlementRepos
 func (r *GormPaymentSettlementRepository) Create(settlement *entities.PaymentSettlement) (*entities.PaymentSettlement, error) {
 	model := PaymentSettlementSchema{
 		UserID:            settlement.UserID,
+		GrindID:           settlement.GrindID,
 		Operation:         settlement.Operation,
 		IdempotencyKey:    settlement.IdempotencyKey,
 		Provider:          string(settlement.Provider),
@@ -169,10 +171,24 @@ func (r *GormPaymentSettlementRepository) FindByStatuses(statuses []entities.Set
 	return result, nil
 }
 
+func (r *GormPaymentSettlementRepository) FindByGrindID(grindID string) ([]entities.PaymentSettlement, error) {
+	var models []PaymentSettlementSchema
+	if err := r.db.Where("grind_id = ?", grindID).Order("created_at ASC").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]entities.PaymentSettlement, 0, len(models))
+	for _, model := range models {
+		result = append(result, *mapSettlementSchemaToEntity(model))
+	}
+	return result, nil
+}
+
 func mapSettlementSchemaToEntity(model PaymentSettlementSchema) *entities.PaymentSettlement {
 	return &entities.PaymentSettlement{
 		ID:              model.ID,
 		UserID:          model.UserID,
+		GrindID:         model.GrindID,
 		Operation:       model.Operation,
 		IdempotencyKey:  model.IdempotencyKey,
 		Provider:        entities.PaymentProvider(model.Provider),
@@ -0,0 +1,149 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/repositories"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StakeChangeSchema is the GORM mapping for a proposed change to a grind's budget and penalty policy
+type StakeChangeSchema struct {
+	gorm.Model
+	ID            string              `json:"id" gorm:"primaryKey"`
+	GrindID       string              `json:"grind_id" gorm:"not null;index"`
+	ProposerID    string              `json:"proposer_id" gorm:"not null"`
+	Budget        int32               `json:"budget" gorm:"not null"`
+	PenaltyPolicy PenaltyPolicySchema `json:"penalty_policy" gorm:"embedded;embeddedPrefix:penalty_"`
+	ApprovedBy    datatypes.JSON      `json:"approved_by"` // JSON array of user IDs
+	RejectedBy    string              `json:"rejected_by"`
+	Status        string              `json:"status" gorm:"not null"`
+	CreatedAt     time.Time           `json:"created_at" gorm:"not null"`
+	UpdatedAt     time.Time           `json:"updated_at" gorm:"not null"`
+}
+
+func (StakeChangeSchema) TableName() string { return "grind_stake_changes" }
+
+type GormStakeChangeRepository struct {
+	db *gorm.DB
+}
+
+func NewGormStakeChangeRepository(db *gorm.DB) *GormStakeChangeRepository {
+	return &GormStakeChangeRepository{db: db}
+}
+
+func (r *GormStakeChangeRepository) WithTx(tx *gorm.DB) repositories.StakeChangeRepository {
+	return &GormStakeChangeRepository{db: tx}
+}
+
+func stakeChangeToSchema(change *entities.StakeChange) (StakeChangeSchema, error) {
+	approvedBy, err := json.Marshal(change.ApprovedBy)
+	if err != nil {
+		return StakeChangeSchema{}, err
+	}
+	return StakeChangeSchema{
+		ID:            change.ID,
+		GrindID:       change.GrindID,
+		ProposerID:    change.ProposerID,
+		Budget:        change.Budget,
+		PenaltyPolicy: penaltyPolicyToSchema(change.PenaltyPolicy),
+		ApprovedBy:    datatypes.JSON(approvedBy),
+		RejectedBy:    change.RejectedBy,
+		Status:        string(change.Status),
+		CreatedAt:     change.CreatedAt,
+		UpdatedAt:     change.UpdatedAt,
+	}, nil
+}
+
+func stakeChangeSchemaToEntity(model *StakeChangeSchema) (*entities.StakeChange, error) {
+	var approvedBy []string
+	if len(model.ApprovedBy) > 0 {
+		if err := json.Unmarshal(model.ApprovedBy, &approvedBy); err != nil {
+			return nil, err
+		}
+	}
+	return &entities.StakeChange{
+		ID:         model.ID,
+		GrindID:    model.GrindID,
+		ProposerID: model.ProposerID,
+		Budget:     model.Budget,
+		PenaltyPolicy: entities.PenaltyPolicy{
+			Kind:         entities.PenaltyKind(model.PenaltyPolicy.Kind),
+			Amount:       model.PenaltyPolicy.Amount,
+			Increment:    model.PenaltyPolicy.Increment,
+			GraceDays:    model.PenaltyPolicy.GraceDays,
+			FreezeTokens: model.PenaltyPolicy.FreezeTokens,
+			CapAtBudget:  model.PenaltyPolicy.CapAtBudget,
+		},
+		ApprovedBy: approvedBy,
+		RejectedBy: model.RejectedBy,
+		Status:     entities.StakeChangeStatus(model.Status),
+		CreatedAt:  model.CreatedAt,
+		UpdatedAt:  model.UpdatedAt,
+	}, nil
+}
+
+func (r *GormStakeChangeRepository) Create(change *entities.StakeChange) error {
+	ctx := context.Background()
+	model, err := stakeChangeToSchema(change)
+	if err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Create(&model).Error
+}
+
+func (r *GormStakeChangeRepository) FindByID(id string) (*entities.StakeChange, error) {
+	ctx := context.Background()
+	var model StakeChangeSchema
+	if err := r.db.WithContext(ctx).First(&model, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return stakeChangeSchemaToEntity(&model)
+}
+
+func (r *GormStakeChangeRepository) FindByIDForUpdate(id string) (*entities.StakeChange, error) {
+	ctx := context.Background()
+	var model StakeChangeSchema
+	err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&model, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return stakeChangeSchemaToEntity(&model)
+}
+
+func (r *GormStakeChangeRepository) FindPendingByGrindID(grindID string) (*entities.StakeChange, error) {
+	ctx := context.Background()
+	var model StakeChangeSchema
+	err := r.db.WithContext(ctx).
+		Where("grind_id = ? AND status = ?", grindID, string(entities.StakeChangePending)).
+		Order("created_at DESC").
+		First(&model).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return stakeChangeSchemaToEntity(&model)
+}
+
+func (r *GormStakeChangeRepository) Update(change *entities.StakeChange) error {
+	ctx := context.Background()
+	model, err := stakeChangeToSchema(change)
+	if err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).
+		Model(&StakeChangeSchema{}).
+		Where("id = ? AND status = ?", change.ID, string(entities.StakeChangePending)).
+		Select("approved_by", "rejected_by", "status", "updated_at").
+		Updates(&model).Error
+}
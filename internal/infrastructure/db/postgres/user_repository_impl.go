@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/repositories"
 	"gorm.io/gorm"
 )
 
@@ -17,12 +18,30 @@ type UserSchema struct {
 	Avatar                 string    `json:"avatar" gorm:""`
 	StripeCustomerID       string    `json:"stripe_customer_id" gorm:""`
 	DefaultPaymentMethodID string    `json:"default_payment_method_id" gorm:""`
+	Timezone               string    `json:"timezone" gorm:"not null;default:UTC"`
+	LeetCodeUsername       string    `json:"leetcode_username" gorm:"column:leetcode_username;not null;default:''"`
+	Locale                 string    `json:"locale" gorm:"not null;default:en"`
 	CreatedAt              time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt              time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 }
 
 func (UserSchema) TableName() string { return "users" }
 
+func userSchemaToEntity(model *UserSchema) entities.User {
+	return entities.User{
+		ID:                     model.ID,
+		Email:                  model.Email,
+		Username:               model.Username,
+		Avatar:                 model.Avatar,
+		HashedPassword:         model.Password,
+		StripeCustomerID:       model.StripeCustomerID,
+		DefaultPaymentMethodID: model.DefaultPaymentMethodID,
+		Timezone:               model.Timezone,
+		LeetCodeUsername:       model.LeetCodeUsername,
+		Locale:                 model.Locale,
+	}
+}
+
 type GormUserRepository struct {
 	db *gorm.DB
 }
@@ -31,6 +50,10 @@ func NewGormUserRepository(db *gorm.DB) *GormUserRepository {
 	return &GormUserRepository{db: db}
 }
 
+func (r *GormUserRepository) WithTx(tx *gorm.DB) repositories.UserRepository {
+	return &GormUserRepository{db: tx}
+}
+
 func (r *GormUserRepository) Create(u *entities.User) error {
 	ctx := context.Background()
 	now := time.Now().UTC()
@@ -40,6 +63,8 @@ func (r *GormUserRepository) Create(u *entities.User) error {
 		Email:     u.Email,
 		Password:  u.HashedPassword, // Already hashed by the service
 		Avatar:    u.Avatar,
+		Timezone:  u.Timezone,
+		Locale:    u.Locale,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
@@ -54,11 +79,14 @@ func (r *GormUserRepository) FindByEmail(email string) (*entities.User, error) {
 		return nil, err
 	}
 	return &entities.User{
-		ID:             model.ID,
-		Email:          model.Email,
-		Username:       model.Username,
-		Avatar:         model.Avatar,
-		HashedPassword: model.Password,
+		ID:               model.ID,
+		Email:            model.Email,
+		Username:         model.Username,
+		Avatar:           model.Avatar,
+		HashedPassword:   model.Password,
+		Timezone:         model.Timezone,
+		LeetCodeUsername: model.LeetCodeUsername,
+		Locale:           model.Locale,
 	}, nil
 }
 
@@ -69,11 +97,14 @@ func (r *GormUserRepository) FindById(id string) (*entities.User, error) {
 		return nil, err
 	}
 	return &entities.User{
-		ID:             model.ID,
-		Email:          model.Email,
-		Username:       model.Username,
-		Avatar:         model.Avatar,
-		HashedPassword: model.Password,
+		ID:               model.ID,
+		Email:            model.Email,
+		Username:         model.Username,
+		Avatar:           model.Avatar,
+		HashedPassword:   model.Password,
+		Timezone:         model.Timezone,
+		LeetCodeUsername: model.LeetCodeUsername,
+		Locale:           model.Locale,
 	}, nil
 }
 
@@ -88,16 +119,8 @@ func (r *GormUserRepository) FindByGrindID(grindID string) ([]entities.User, err
 		return nil, err
 	}
 	users := make([]entities.User, len(models))
-	for i, model := range models {
-		users[i] = entities.User{
-			ID:                     model.ID,
-			Email:                  model.Email,
-			Username:               model.Username,
-			Avatar:                 model.Avatar,
-			HashedPassword:         model.Password,
-			StripeCustomerID:       model.StripeCustomerID,
-			DefaultPaymentMethodID: model.DefaultPaymentMethodID,
-		}
+	for i := range models {
+		users[i] = userSchemaToEntity(&models[i])
 	}
 
 	return users, nil
@@ -111,9 +134,15 @@ func (r *GormUserRepository) Delete(id string) error {
 func (r *GormUserRepository) Update(user *entities.User) error {
 	ctx := context.Background()
 	model := UserSchema{
-		ID:       user.ID,
-		Username: user.Username,
-		Avatar:   user.Avatar,
+		ID:               user.ID,
+		Username:         user.Username,
+		Avatar:           user.Avatar,
+		Timezone:         user.Timezone,
+		LeetCodeUsername: user.LeetCodeUsername,
+		Locale:           user.Locale,
 	}
-	return r.db.WithContext(ctx).Model(&UserSchema{}).Where("id = ?", user.ID).Updates(&model).Error
+	// Updates skips zero values, so the LeetCode username is selected explicitly to let users unlink it
+	return r.db.WithContext(ctx).Model(&UserSchema{}).Where("id = ?", user.ID).
+		Select("username", "avatar", "timezone", "leetcode_username", "locale").
+		Updates(&model).Error
 }
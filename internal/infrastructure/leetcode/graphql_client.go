@@ -0,0 +1,115 @@
+package leetcode
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+)
+
+// DefaultEndpoint is LeetCode's public GraphQL API.
+const DefaultEndpoint = "https://leetcode.com/graphql"
+
+const recentAcSubmissionsQuery = `query recentAcSubmissions($username: String!, $limit: Int!) {
+  recentAcSubmissionList(username: $username, limit: $limit) {
+    id
+    title
+    titleSlug
+    timestamp
+  }
+}`
+
+// GraphQLClient reads public profile data from LeetCode's GraphQL API. Tests point it at
+// a local httptest server.
+type GraphQLClient struct {
+	endpoint   string
+	httpClient *http.Client
+}
+
+// NewGraphQLClient queries endpoint, or DefaultEndpoint when it is empty. A nil httpClient
+// uses one with a 10 second timeout.
+func NewGraphQLClient(endpoint string, httpClient *http.Client) *GraphQLClient {
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &GraphQLClient{endpoint: endpoint, httpClient: httpClient}
+}
+
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+type recentAcSubmissionsResponse struct {
+	Data struct {
+		RecentAcSubmissionList []struct {
+			ID        string `json:"id"`
+			Title     string `json:"title"`
+			TitleSlug string `json:"titleSlug"`
+			Timestamp string `json:"timestamp"` // unix seconds
+		} `json:"recentAcSubmissionList"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// RecentAcceptedSubmissions returns up to limit of the user's most recent accepted
+// submissions, newest first. An unknown username is reported as an error by LeetCode.
+func (c *GraphQLClient) RecentAcceptedSubmissions(username string, limit int) ([]entities.LeetCodeSubmission, error) {
+	body, err := json.Marshal(graphQLRequest{
+		Query:     recentAcSubmissionsQuery,
+		Variables: map[string]interface{}{"username": username, "limit": limit},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// LeetCode rejects GraphQL requests without a referer
+	req.Header.Set("Referer", "https://leetcode.com/u/"+username+"/")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("leetcode request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("leetcode responded with status %d", resp.StatusCode)
+	}
+
+	var payload recentAcSubmissionsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("failed to decode leetcode response: %w", err)
+	}
+	if len(payload.Errors) > 0 {
+		return nil, fmt.Errorf("leetcode query failed: %s", payload.Errors[0].Message)
+	}
+
+	submissions := make([]entities.LeetCodeSubmission, 0, len(payload.Data.RecentAcSubmissionList))
+	for _, item := range payload.Data.RecentAcSubmissionList {
+		seconds, err := strconv.ParseInt(item.Timestamp, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid submission timestamp %q: %w", item.Timestamp, err)
+		}
+		submissions = append(submissions, entities.LeetCodeSubmission{
+			ID:          item.ID,
+			Slug:        item.TitleSlug,
+			Title:       item.Title,
+			SubmittedAt: time.Unix(seconds, 0).UTC(),
+		})
+	}
+	return submissions, nil
+}
@@ -0,0 +1,69 @@
+package leetcode
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphQLClient_RecentAcceptedSubmissions(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		var req graphQLRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Contains(t, req.Query, "recentAcSubmissionList")
+		require.Equal(t, "neo", req.Variables["username"])
+		require.EqualValues(t, 20, req.Variables["limit"])
+
+		_, _ = w.Write([]byte(`{"data":{"recentAcSubmissionList":[
+			{"id":"1001","title":"Two Sum","titleSlug":"two-sum","timestamp":"1775822400"},
+			{"id":"1000","title":"Valid Anagram","titleSlug":"valid-anagram","timestamp":"1775736000"}
+		]}}`))
+	}))
+	defer server.Close()
+
+	submissions, err := NewGraphQLClient(server.URL, server.Client()).RecentAcceptedSubmissions("neo", 20)
+	require.NoError(t, err)
+	require.Equal(t, []entities.LeetCodeSubmission{
+		{ID: "1001", Slug: "two-sum", Title: "Two Sum", SubmittedAt: time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC)},
+		{ID: "1000", Slug: "valid-anagram", Title: "Valid Anagram", SubmittedAt: time.Date(2026, 4, 9, 12, 0, 0, 0, time.UTC)},
+	}, submissions)
+}
+
+func TestGraphQLClient_RecentAcceptedSubmissions_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		errContains string
+	}{
+		{name: "graphql error", status: http.StatusOK, body: `{"errors":[{"message":"That user does not exist."}]}`, errContains: "That user does not exist."},
+		{name: "rate limited", status: http.StatusTooManyRequests, body: `{}`, errContains: "status 429"},
+		{name: "bad timestamp", status: http.StatusOK, body: `{"data":{"recentAcSubmissionList":[{"id":"1","titleSlug":"two-sum","timestamp":"soon"}]}}`, errContains: "invalid submission timestamp"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			_, err := NewGraphQLClient(server.URL, server.Client()).RecentAcceptedSubmissions("neo", 20)
+			require.ErrorContains(t, err, tt.errContains)
+		})
+	}
+}
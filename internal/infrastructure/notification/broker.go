@@ -0,0 +1,163 @@
+// Package notification holds the brokers that carry notifications to users' open streams.
+package notification
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultBacklog is how many notifications per user a broker keeps for replay.
+const DefaultBacklog = 200
+
+// subscriberBuffer is how many notifications a stream may fall behind before it is
+// closed; its client reconnects and replays the rest.
+const subscriberBuffer = 64
+
+// Broker delivers published notifications to the user's subscribers. IDs have the form
+// "<milliseconds>-<sequence>" and increase per user, so a client can resume after the
+// last one it received.
+type Broker interface {
+	Publish(notification *entities.Notification) error
+	Subscribe(ctx context.Context, userID, lastEventID string) (<-chan *entities.Notification, error)
+}
+
+// New returns a broker on top of the shared Redis client, which every API replica sees,
+// or an in-process broker when rdb is nil.
+func New(rdb *redis.Client) Broker {
+	if rdb == nil {
+		return NewMemoryBroker(DefaultBacklog)
+	}
+	return NewRedisBroker(rdb, DefaultBacklog)
+}
+
+var validID = regexp.MustCompile(`^\d+-\d+$`)
+
+func validateLastEventID(lastEventID string) error {
+	if lastEventID != "" && !validID.MatchString(lastEventID) {
+		return fmt.Errorf("%w: %q", config.ErrInvalidLastEventID, lastEventID)
+	}
+	return nil
+}
+
+// idAfter reports whether the notification ID a comes after b.
+func idAfter(a, b string) bool {
+	aMillis, aSeq := splitID(a)
+	bMillis, bSeq := splitID(b)
+	if aMillis != bMillis {
+		return aMillis > bMillis
+	}
+	return aSeq > bSeq
+}
+
+func splitID(id string) (uint64, uint64) {
+	millis, seq, _ := strings.Cut(id, "-")
+	m, _ := strconv.ParseUint(millis, 10, 64)
+	s, _ := strconv.ParseUint(seq, 10, 64)
+	return m, s
+}
+
+// hub fans notifications out to the subscribers of this process.
+type hub struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan *entities.Notification]struct{}
+}
+
+func newHub() *hub {
+	return &hub{subscribers: make(map[string]map[chan *entities.Notification]struct{})}
+}
+
+func (h *hub) add(userID string) chan *entities.Notification {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	live := make(chan *entities.Notification, subscriberBuffer)
+	if h.subscribers[userID] == nil {
+		h.subscribers[userID] = make(map[chan *entities.Notification]struct{})
+	}
+	h.subscribers[userID][live] = struct{}{}
+	return live
+}
+
+func (h *hub) remove(userID string, live chan *entities.Notification) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.removeLocked(userID, live)
+}
+
+func (h *hub) removeLocked(userID string, live chan *entities.Notification) {
+	if _, ok := h.subscribers[userID][live]; !ok {
+		return
+	}
+	delete(h.subscribers[userID], live)
+	if len(h.subscribers[userID]) == 0 {
+		delete(h.subscribers, userID)
+	}
+	close(live)
+}
+
+// deliver never blocks: a subscriber whose buffer is full is closed instead.
+func (h *hub) deliver(notification *entities.Notification) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for live := range h.subscribers[notification.UserID] {
+		select {
+		case live <- notification:
+		default:
+			fmt.Println("notification subscriber fell behind, closing it", notification.UserID)
+			h.removeLocked(notification.UserID, live)
+		}
+	}
+}
+
+// stream sends the replayed notifications and then the live ones until ctx is done or
+// live is closed. Live notifications already replayed, or not after lastEventID, are
+// skipped. Live ones are otherwise sent as they arrive: publishers on different replicas
+// can deliver them slightly out of ID order.
+func (h *hub) stream(ctx context.Context, userID, lastEventID string, replayed []*entities.Notification, live chan *entities.Notification) <-chan *entities.Notification {
+	out := make(chan *entities.Notification)
+	go func() {
+		defer close(out)
+		defer h.remove(userID, live)
+
+		send := func(notification *entities.Notification) bool {
+			select {
+			case out <- notification:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		sent := make(map[string]bool, len(replayed))
+		for _, notification := range replayed {
+			if !send(notification) {
+				return
+			}
+			sent[notification.ID] = true
+		}
+		for {
+			select {
+			case notification, ok := <-live:
+				if !ok {
+					return
+				}
+				if sent[notification.ID] || (lastEventID != "" && !idAfter(notification.ID, lastEventID)) {
+					continue
+				}
+				if !send(notification) {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,70 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+)
+
+// MemoryBroker keeps notifications in process. It serves a single API replica, tests and
+// local development without Redis.
+type MemoryBroker struct {
+	hub     *hub
+	backlog int
+
+	mu         sync.Mutex
+	lastMillis int64
+	sequence   uint64
+	history    map[string][]*entities.Notification
+}
+
+// NewMemoryBroker keeps the last backlog notifications of each user for replay.
+func NewMemoryBroker(backlog int) *MemoryBroker {
+	return &MemoryBroker{
+		hub:     newHub(),
+		backlog: backlog,
+		history: make(map[string][]*entities.Notification),
+	}
+}
+
+func (b *MemoryBroker) Publish(notification *entities.Notification) error {
+	b.mu.Lock()
+	millis := time.Now().UnixMilli()
+	if millis > b.lastMillis {
+		b.lastMillis, b.sequence = millis, 0
+	} else {
+		b.sequence++
+	}
+	notification.ID = fmt.Sprintf("%d-%d", b.lastMillis, b.sequence)
+
+	history := append(b.history[notification.UserID], notification)
+	if len(history) > b.backlog {
+		history = history[len(history)-b.backlog:]
+	}
+	b.history[notification.UserID] = history
+	b.hub.deliver(notification)
+	b.mu.Unlock()
+	return nil
+}
+
+func (b *MemoryBroker) Subscribe(ctx context.Context, userID, lastEventID string) (<-chan *entities.Notification, error) {
+	if err := validateLastEventID(lastEventID); err != nil {
+		return nil, err
+	}
+
+	live := b.hub.add(userID)
+	var replayed []*entities.Notification
+	if lastEventID != "" {
+		b.mu.Lock()
+		for _, notification := range b.history[userID] {
+			if idAfter(notification.ID, lastEventID) {
+				replayed = append(replayed, notification)
+			}
+		}
+		b.mu.Unlock()
+	}
+	return b.hub.stream(ctx, userID, lastEventID, replayed, live), nil
+}
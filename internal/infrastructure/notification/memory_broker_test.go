@@ -0,0 +1,119 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func publishTo(t *testing.T, broker Broker, userID string, data string) *entities.Notification {
+	t.Helper()
+	notification, err := entities.NewNotification(userID, entities.NotificationTypeMessage, data)
+	require.NoError(t, err)
+	require.NoError(t, broker.Publish(notification))
+	return notification
+}
+
+func receive(t *testing.T, stream <-chan *entities.Notification) *entities.Notification {
+	t.Helper()
+	select {
+	case notification, ok := <-stream:
+		require.True(t, ok, "stream closed")
+		return notification
+	case <-time.After(time.Second):
+		t.Fatal("no notification received")
+		return nil
+	}
+}
+
+func Test_MemoryBroker_DeliversToTheUsersStreams(t *testing.T) {
+	t.Parallel()
+
+	broker := NewMemoryBroker(DefaultBacklog)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	first, err := broker.Subscribe(ctx, "amy", "")
+	require.NoError(t, err)
+	second, err := broker.Subscribe(ctx, "amy", "")
+	require.NoError(t, err)
+	other, err := broker.Subscribe(ctx, "bob", "")
+	require.NoError(t, err)
+
+	published := publishTo(t, broker, "amy", "hi")
+	assert.Equal(t, published.ID, receive(t, first).ID)
+	assert.Equal(t, published.ID, receive(t, second).ID)
+	assert.JSONEq(t, `"hi"`, string(published.Data))
+	select {
+	case notification := <-other:
+		t.Fatalf("bob received amy's notification %+v", notification)
+	default:
+	}
+
+	cancel()
+	_, ok := <-first
+	assert.False(t, ok, "stream stays open after its context is done")
+}
+
+func Test_MemoryBroker_ReplaysAfterLastEventID(t *testing.T) {
+	t.Parallel()
+
+	broker := NewMemoryBroker(3)
+	var ids []string
+	for _, data := range []string{"one", "two", "three", "four"} {
+		ids = append(ids, publishTo(t, broker, "amy", data).ID)
+	}
+	for i := 1; i < len(ids); i++ {
+		assert.True(t, idAfter(ids[i], ids[i-1]), "%s is not after %s", ids[i], ids[i-1])
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := broker.Subscribe(ctx, "amy", ids[1])
+	require.NoError(t, err)
+	assert.Equal(t, ids[2], receive(t, stream).ID)
+	assert.Equal(t, ids[3], receive(t, stream).ID)
+
+	live := publishTo(t, broker, "amy", "five")
+	assert.Equal(t, live.ID, receive(t, stream).ID)
+
+	// the backlog keeps the last 3, so "one" is gone even for a client that saw nothing newer
+	stream, err = broker.Subscribe(ctx, "amy", "0-0")
+	require.NoError(t, err)
+	assert.Equal(t, ids[2], receive(t, stream).ID)
+}
+
+func Test_MemoryBroker_RejectsMalformedLastEventID(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewMemoryBroker(DefaultBacklog).Subscribe(context.Background(), "amy", "yesterday")
+	assert.True(t, errors.Is(err, config.ErrInvalidLastEventID), "got %v", err)
+}
+
+func Test_MemoryBroker_ClosesSubscribersThatFallBehind(t *testing.T) {
+	t.Parallel()
+
+	broker := NewMemoryBroker(DefaultBacklog)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := broker.Subscribe(ctx, "amy", "")
+	require.NoError(t, err)
+
+	// nobody reads the stream: one notification waits in the forwarder, the buffer fills
+	// up and the next one closes the subscriber
+	for i := 0; i < subscriberBuffer+2; i++ {
+		publishTo(t, broker, "amy", "spam")
+	}
+
+	received := 0
+	for range stream {
+		received++
+	}
+	assert.Less(t, received, subscriberBuffer+2)
+}
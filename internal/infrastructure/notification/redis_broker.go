@@ -0,0 +1,167 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisChannel is the pub/sub channel every replica publishes notifications on.
+const RedisChannel = "notifications"
+
+// streamTTL is how long a user's backlog outlives their last notification.
+const streamTTL = 24 * time.Hour
+
+// RedisBroker lets every API replica serve every user. A published notification is
+// appended to the user's Redis stream, which assigns its ID and keeps the backlog for
+// replay, and then published on RedisChannel. Each replica listens on the channel once
+// and hands notifications to the streams it has open.
+type RedisBroker struct {
+	rdb     *redis.Client
+	backlog int64
+	hub     *hub
+
+	mu        sync.Mutex
+	listening bool
+}
+
+// NewRedisBroker keeps roughly the last backlog notifications of each user for replay.
+func NewRedisBroker(rdb *redis.Client, backlog int) *RedisBroker {
+	return &RedisBroker{rdb: rdb, backlog: int64(backlog), hub: newHub()}
+}
+
+// published is a notification as it travels on RedisChannel.
+type published struct {
+	ID        string          `json:"id"`
+	UserID    string          `json:"userID"`
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+func streamKey(userID string) string {
+	return fmt.Sprintf("%s:%s", RedisChannel, userID)
+}
+
+func (b *RedisBroker) Publish(notification *entities.Notification) error {
+	ctx := context.Background()
+	key := streamKey(notification.UserID)
+	id, err := b.rdb.XAdd(ctx, &redis.XAddArgs{
+		Stream: key,
+		MaxLen: b.backlog,
+		Approx: true,
+		// field pairs rather than a map keep the XADD arguments in a stable order
+		Values: []interface{}{
+			"type", string(notification.Type),
+			"data", string(notification.Data),
+			"created_at", notification.CreatedAt.Format(time.RFC3339Nano),
+		},
+	}).Result()
+	if err != nil {
+		return err
+	}
+	notification.ID = id
+	if err := b.rdb.Expire(ctx, key, streamTTL).Err(); err != nil {
+		fmt.Println("notification stream expiry failed", key, err)
+	}
+
+	message, err := json.Marshal(published{
+		ID:        notification.ID,
+		UserID:    notification.UserID,
+		Type:      string(notification.Type),
+		Data:      notification.Data,
+		CreatedAt: notification.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+	return b.rdb.Publish(ctx, RedisChannel, message).Err()
+}
+
+func (b *RedisBroker) Subscribe(ctx context.Context, userID, lastEventID string) (<-chan *entities.Notification, error) {
+	if err := validateLastEventID(lastEventID); err != nil {
+		return nil, err
+	}
+	if err := b.listen(); err != nil {
+		return nil, err
+	}
+
+	// register before reading the backlog so nothing published in between is missed;
+	// the stream drops what arrives twice
+	live := b.hub.add(userID)
+	var replayed []*entities.Notification
+	if lastEventID != "" {
+		var err error
+		replayed, err = b.replay(ctx, userID, lastEventID)
+		if err != nil {
+			b.hub.remove(userID, live)
+			return nil, err
+		}
+	}
+	return b.hub.stream(ctx, userID, lastEventID, replayed, live), nil
+}
+
+// replay returns the user's notifications after lastEventID that are still in the backlog.
+func (b *RedisBroker) replay(ctx context.Context, userID, lastEventID string) ([]*entities.Notification, error) {
+	messages, err := b.rdb.XRange(ctx, streamKey(userID), "("+lastEventID, "+").Result()
+	if err != nil {
+		return nil, err
+	}
+
+	notifications := make([]*entities.Notification, 0, len(messages))
+	for _, message := range messages {
+		notification := &entities.Notification{ID: message.ID, UserID: userID}
+		if value, ok := message.Values["type"].(string); ok {
+			notification.Type = entities.NotificationType(value)
+		}
+		if value, ok := message.Values["data"].(string); ok {
+			notification.Data = json.RawMessage(value)
+		}
+		if value, ok := message.Values["created_at"].(string); ok {
+			notification.CreatedAt, _ = time.Parse(time.RFC3339Nano, value)
+		}
+		notifications = append(notifications, notification)
+	}
+	return notifications, nil
+}
+
+// listen subscribes this replica to RedisChannel the first time a stream is opened, and
+// again on the next stream if that failed. The client re-subscribes by itself after a
+// lost connection.
+func (b *RedisBroker) listen() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.listening {
+		return nil
+	}
+
+	pubsub := b.rdb.Subscribe(context.Background(), RedisChannel)
+	if _, err := pubsub.Receive(context.Background()); err != nil {
+		pubsub.Close()
+		return err
+	}
+	b.listening = true
+
+	go func() {
+		for message := range pubsub.Channel() {
+			var event published
+			if err := json.Unmarshal([]byte(message.Payload), &event); err != nil {
+				fmt.Println("notification message unreadable", err)
+				continue
+			}
+			b.hub.deliver(&entities.Notification{
+				ID:        event.ID,
+				UserID:    event.UserID,
+				Type:      entities.NotificationType(event.Type),
+				Data:      event.Data,
+				CreatedAt: event.CreatedAt,
+			})
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,75 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/go-redis/redismock/v9"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RedisBroker_Publish(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	t.Cleanup(func() { require.NoError(t, db.Close()) })
+
+	createdAt := time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC)
+	notification := &entities.Notification{
+		UserID:    "amy",
+		Type:      entities.NotificationTypeSettlement,
+		Data:      json.RawMessage(`{"status":"captured"}`),
+		CreatedAt: createdAt,
+	}
+
+	mock.ExpectXAdd(&redis.XAddArgs{
+		Stream: "notifications:amy",
+		MaxLen: 200,
+		Approx: true,
+		Values: []interface{}{
+			"type", "settlement",
+			"data", `{"status":"captured"}`,
+			"created_at", "2026-04-10T12:00:00Z",
+		},
+	}).SetVal("1775822400000-0")
+	mock.ExpectExpire("notifications:amy", streamTTL).SetVal(true)
+	message, _ := json.Marshal(published{
+		ID:        "1775822400000-0",
+		UserID:    "amy",
+		Type:      "settlement",
+		Data:      notification.Data,
+		CreatedAt: createdAt,
+	})
+	mock.ExpectPublish(RedisChannel, message).SetVal(1)
+
+	require.NoError(t, NewRedisBroker(db, DefaultBacklog).Publish(notification))
+	assert.Equal(t, "1775822400000-0", notification.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func Test_RedisBroker_Replay(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	t.Cleanup(func() { require.NoError(t, db.Close()) })
+
+	mock.ExpectXRange("notifications:amy", "(1775822400000-0", "+").SetVal([]redis.XMessage{{
+		ID: "1775822400000-1",
+		Values: map[string]interface{}{
+			"type":       "message",
+			"data":       `{"content":"hi"}`,
+			"created_at": "2026-04-10T12:00:00Z",
+		},
+	}})
+
+	replayed, err := NewRedisBroker(db, DefaultBacklog).replay(context.Background(), "amy", "1775822400000-0")
+	require.NoError(t, err)
+	require.Len(t, replayed, 1)
+	assert.Equal(t, "1775822400000-1", replayed[0].ID)
+	assert.Equal(t, "amy", replayed[0].UserID)
+	assert.Equal(t, entities.NotificationTypeMessage, replayed[0].Type)
+	assert.JSONEq(t, `{"content":"hi"}`, string(replayed[0].Data))
+	assert.Equal(t, time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC), replayed[0].CreatedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,102 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ticketPrefix namespaces stream tickets in Redis.
+const ticketPrefix = "stream-ticket"
+
+// TicketStore keeps the single-use tickets that open notification streams. Take returns
+// the user a ticket was issued to and forgets it, so a ticket works once.
+type TicketStore interface {
+	Put(ticket, userID string, ttl time.Duration) error
+	Take(ticket string) (string, bool, error)
+}
+
+// NewTicketStore returns a store on top of the shared Redis client, so a ticket issued by
+// one API replica opens a stream on any other, or an in-process store when rdb is nil.
+func NewTicketStore(rdb *redis.Client) TicketStore {
+	if rdb == nil {
+		return NewMemoryTicketStore()
+	}
+	return NewRedisTicketStore(rdb)
+}
+
+// RedisTicketStore keeps each ticket as a key that expires with it.
+type RedisTicketStore struct {
+	rdb *redis.Client
+}
+
+func NewRedisTicketStore(rdb *redis.Client) *RedisTicketStore {
+	return &RedisTicketStore{rdb: rdb}
+}
+
+func ticketKey(ticket string) string {
+	return fmt.Sprintf("%s:%s", ticketPrefix, ticket)
+}
+
+func (s *RedisTicketStore) Put(ticket, userID string, ttl time.Duration) error {
+	return s.rdb.Set(context.Background(), ticketKey(ticket), userID, ttl).Err()
+}
+
+func (s *RedisTicketStore) Take(ticket string) (string, bool, error) {
+	// GETDEL reads and removes the ticket at once, so two streams cannot share it
+	userID, err := s.rdb.GetDel(context.Background(), ticketKey(ticket)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return userID, true, nil
+}
+
+// MemoryTicketStore keeps tickets in process, for a single API replica, tests and local
+// development without Redis.
+type MemoryTicketStore struct {
+	mu      sync.Mutex
+	tickets map[string]memoryTicket
+}
+
+type memoryTicket struct {
+	userID    string
+	expiresAt time.Time
+}
+
+func NewMemoryTicketStore() *MemoryTicketStore {
+	return &MemoryTicketStore{tickets: make(map[string]memoryTicket)}
+}
+
+func (s *MemoryTicketStore) Put(ticket, userID string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for key, stored := range s.tickets {
+		if !now.Before(stored.expiresAt) {
+			delete(s.tickets, key)
+		}
+	}
+	s.tickets[ticket] = memoryTicket{userID: userID, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+func (s *MemoryTicketStore) Take(ticket string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.tickets[ticket]
+	if !ok {
+		return "", false, nil
+	}
+	delete(s.tickets, ticket)
+	if !time.Now().Before(stored.expiresAt) {
+		return "", false, nil
+	}
+	return stored.userID, true, nil
+}
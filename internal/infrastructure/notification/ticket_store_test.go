@@ -0,0 +1,50 @@
+package notification
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RedisTicketStore(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	t.Cleanup(func() { require.NoError(t, db.Close()) })
+	store := NewRedisTicketStore(db)
+
+	mock.ExpectSet("stream-ticket:abc", "amy", 30*time.Second).SetVal("OK")
+	require.NoError(t, store.Put("abc", "amy", 30*time.Second))
+
+	mock.ExpectGetDel("stream-ticket:abc").SetVal("amy")
+	userID, ok, err := store.Take("abc")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "amy", userID)
+
+	mock.ExpectGetDel("stream-ticket:abc").RedisNil()
+	_, ok, err = store.Take("abc")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func Test_MemoryTicketStore(t *testing.T) {
+	t.Parallel()
+
+	store := NewMemoryTicketStore()
+	require.NoError(t, store.Put("abc", "amy", time.Minute))
+	require.NoError(t, store.Put("old", "bob", -time.Second))
+
+	userID, ok, err := store.Take("abc")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "amy", userID)
+
+	_, ok, _ = store.Take("abc")
+	assert.False(t, ok, "a ticket works once")
+	_, ok, _ = store.Take("old")
+	assert.False(t, ok, "an expired ticket does not work")
+}
@@ -0,0 +1,83 @@
+// Package roadmap serves the LeetCode study plans embedded in the binary.
+package roadmap
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+
+	"github.com/daniel0321forever/terriyaki-go/assets"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+)
+
+// EmbeddedRoadmapRepository reads study plans from the CSV files in the assets package.
+// Each file has an id,slug,tag header and lists the problems in the plan's own order.
+// Files are parsed once, on first use.
+type EmbeddedRoadmapRepository struct {
+	once     sync.Once
+	problems map[string][]entities.RoadmapProblem
+	err      error
+}
+
+func NewEmbeddedRoadmapRepository() *EmbeddedRoadmapRepository {
+	return &EmbeddedRoadmapRepository{}
+}
+
+func (r *EmbeddedRoadmapRepository) FindProblems(name string) ([]entities.RoadmapProblem, error) {
+	r.once.Do(r.load)
+	if r.err != nil {
+		return nil, r.err
+	}
+	problems, ok := r.problems[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown roadmap %q", name)
+	}
+	return append([]entities.RoadmapProblem(nil), problems...), nil
+}
+
+func (r *EmbeddedRoadmapRepository) load() {
+	file, err := assets.Neetcode250CSV.Open("neetcode250.csv")
+	if err != nil {
+		r.err = fmt.Errorf("opening neetcode250.csv: %w", err)
+		return
+	}
+	defer file.Close()
+
+	problems, err := parseRoadmapCSV(file)
+	if err != nil {
+		r.err = fmt.Errorf("parsing neetcode250.csv: %w", err)
+		return
+	}
+	r.problems = map[string][]entities.RoadmapProblem{entities.RoadmapNeetcode250: problems}
+}
+
+// parseRoadmapCSV reads id,slug,tag rows after a header line.
+func parseRoadmapCSV(reader io.Reader) ([]entities.RoadmapProblem, error) {
+	records, err := csv.NewReader(reader).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("missing header")
+	}
+
+	problems := make([]entities.RoadmapProblem, 0, len(records)-1)
+	seen := make(map[string]bool, len(records)-1)
+	for i, record := range records[1:] {
+		if len(record) != 3 {
+			return nil, fmt.Errorf("line %d: expected id,slug,tag", i+2)
+		}
+		id, err := strconv.Atoi(record[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid id %q", i+2, record[0])
+		}
+		if record[1] == "" || seen[record[1]] {
+			return nil, fmt.Errorf("line %d: missing or duplicate slug %q", i+2, record[1])
+		}
+		seen[record[1]] = true
+		problems = append(problems, entities.RoadmapProblem{ID: id, Slug: record[1], Tag: record[2]})
+	}
+	return problems, nil
+}
@@ -0,0 +1,32 @@
+package roadmap
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddedRoadmapRepository_FindProblems(t *testing.T) {
+	t.Parallel()
+
+	repo := NewEmbeddedRoadmapRepository()
+	problems, err := repo.FindProblems(entities.RoadmapNeetcode250)
+	require.NoError(t, err)
+	require.Len(t, problems, 151)
+	require.Equal(t, entities.RoadmapProblem{ID: 217, Slug: "contains-duplicate", Tag: "Arrays & Hashing"}, problems[0])
+
+	_, err = repo.FindProblems("blind75")
+	require.ErrorContains(t, err, "unknown roadmap")
+}
+
+func TestParseRoadmapCSV_RejectsBadRows(t *testing.T) {
+	t.Parallel()
+
+	_, err := parseRoadmapCSV(strings.NewReader("id,slug,tag\nabc,two-sum,Arrays & Hashing\n"))
+	require.ErrorContains(t, err, "invalid id")
+
+	_, err = parseRoadmapCSV(strings.NewReader("id,slug,tag\n1,two-sum,Arrays\n1,two-sum,Arrays\n"))
+	require.ErrorContains(t, err, "duplicate slug")
+}
@@ -0,0 +1,47 @@
+package verifier
+
+import (
+	"sync"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+)
+
+// FakeVerifier is a deterministic completion verifier for tests and offline development.
+// Unless Verdict is set, a completion with evidence is judged plausible with confidence 0.9
+// and one with metadata only with confidence 0.5. Err, when set, is returned instead of a
+// verdict. Every request is kept for inspection.
+type FakeVerifier struct {
+	Verdict *entities.CompletionVerdict
+	Err     error
+
+	mu       sync.Mutex
+	requests []entities.CompletionVerification
+}
+
+func (f *FakeVerifier) Verify(request entities.CompletionVerification) (*entities.CompletionVerdict, error) {
+	f.mu.Lock()
+	f.requests = append(f.requests, request)
+	f.mu.Unlock()
+
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	if f.Verdict != nil {
+		verdict := *f.Verdict
+		if verdict.Verifier == "" {
+			verdict.Verifier = "fake"
+		}
+		return &verdict, nil
+	}
+	if request.Evidence != nil {
+		return &entities.CompletionVerdict{Plausible: true, Confidence: 0.9, Reason: "evidence attached", Verifier: "fake"}, nil
+	}
+	return &entities.CompletionVerdict{Plausible: true, Confidence: 0.5, Reason: "no evidence attached", Verifier: "fake"}, nil
+}
+
+// Requests returns the completions judged so far, oldest first.
+func (f *FakeVerifier) Requests() []entities.CompletionVerification {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]entities.CompletionVerification(nil), f.requests...)
+}
@@ -0,0 +1,114 @@
+package verifier
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryEvidence map[string][]byte
+
+func (m memoryEvidence) Open(key string) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(m[key])), nil
+}
+
+type generateContentRequest struct {
+	Contents []struct {
+		Parts []struct {
+			Text       string `json:"text"`
+			InlineData *struct {
+				MIMEType string `json:"mimeType"`
+				Data     string `json:"data"`
+			} `json:"inlineData"`
+		} `json:"parts"`
+	} `json:"contents"`
+	GenerationConfig struct {
+		ResponseMIMEType string `json:"responseMimeType"`
+	} `json:"generationConfig"`
+}
+
+// geminiServer answers every generateContent call with text and hands the request to inspect.
+func geminiServer(t *testing.T, text string, inspect func(r *http.Request, body generateContentRequest)) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body generateContentRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		if inspect != nil {
+			inspect(r, body)
+		}
+		answer, _ := json.Marshal(map[string]interface{}{
+			"candidates": []map[string]interface{}{{
+				"content": map[string]interface{}{"role": "model", "parts": []map[string]string{{"text": text}}},
+			}},
+		})
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(answer)
+	}))
+}
+
+func TestGeminiVerifier_Verify(t *testing.T) {
+	t.Parallel()
+
+	screenshot := []byte("\x89PNG\r\n\x1a\nstreak")
+	server := geminiServer(t, `{"plausible":true,"confidence":1.4,"reason":" The screenshot shows a finished lesson. "}`,
+		func(r *http.Request, body generateContentRequest) {
+			require.True(t, strings.HasSuffix(r.URL.Path, "/models/test-model:generateContent"), r.URL.Path)
+			require.Equal(t, "key-1", r.Header.Get("x-goog-api-key"))
+			require.Equal(t, "application/json", body.GenerationConfig.ResponseMIMEType)
+			require.Len(t, body.Contents, 1)
+			parts := body.Contents[0].Parts
+			require.Len(t, parts, 2)
+			require.Contains(t, parts[0].Text, "Habit: Complete a Duolingo lesson")
+			require.Contains(t, parts[0].Text, `{"lesson":"Spanish 1"}`)
+			require.Equal(t, "image/png", parts[1].InlineData.MIMEType)
+			require.Equal(t, base64.StdEncoding.EncodeToString(screenshot), parts[1].InlineData.Data)
+		})
+	defer server.Close()
+
+	v, err := NewGeminiVerifier("key-1", "test-model", server.URL, memoryEvidence{"blob-1": screenshot})
+	require.NoError(t, err)
+
+	verdict, err := v.Verify(entities.CompletionVerification{
+		UserID:     "user-1",
+		Provider:   entities.ProviderDuolingo,
+		Habit:      "Complete a Duolingo lesson",
+		OccurredAt: time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC),
+		Metadata:   []byte(`{"lesson":"Spanish 1"}`),
+		Evidence:   &entities.EvidenceAttachment{Key: "blob-1", FileName: "streak.png", ContentType: "image/png"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, &entities.CompletionVerdict{
+		Plausible:  true,
+		Confidence: 1,
+		Reason:     "The screenshot shows a finished lesson.",
+		Verifier:   "gemini:test-model",
+	}, verdict)
+}
+
+func TestGeminiVerifier_Verify_UnreadableAnswer(t *testing.T) {
+	t.Parallel()
+
+	server := geminiServer(t, "I think so", nil)
+	defer server.Close()
+
+	v, err := NewGeminiVerifier("key-1", "", server.URL, nil)
+	require.NoError(t, err)
+	_, err = v.Verify(entities.CompletionVerification{Habit: "Complete a Duolingo lesson"})
+	require.ErrorContains(t, err, "unreadable verdict")
+}
+
+func TestNewGeminiVerifier_RequiresAPIKey(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewGeminiVerifier("", "", "", nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,35 @@
+// Package verifier holds the completion verifiers ingest can be configured with.
+package verifier
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+)
+
+// Verifier judges whether a completion plausibly satisfies its habit.
+type Verifier interface {
+	Verify(request entities.CompletionVerification) (*entities.CompletionVerdict, error)
+}
+
+// FromEnv builds the verifier COMPLETION_VERIFIER selects: "gemini" calls
+// COMPLETION_VERIFIER_MODEL with GEMINI_API_KEY, "fake" judges offline like FakeVerifier.
+// It returns nil when the variable is unset or "none", so completions are not judged.
+func FromEnv(evidence EvidenceReader) (Verifier, error) {
+	switch kind := os.Getenv(config.COMPLETION_VERIFIER); kind {
+	case "", "none":
+		return nil, nil
+	case "gemini":
+		gemini, err := NewGeminiVerifier(os.Getenv(config.GEMINI_API_KEY), os.Getenv(config.COMPLETION_VERIFIER_MODEL), "", evidence)
+		if err != nil {
+			return nil, err
+		}
+		return gemini, nil
+	case "fake":
+		return &FakeVerifier{}, nil
+	default:
+		return nil, fmt.Errorf("unknown completion verifier %q: want gemini, fake or none", kind)
+	}
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
+	"github.com/daniel0321forever/terriyaki-go/internal/application/mappers"
+	"github.com/daniel0321forever/terriyaki-go/internal/application/services"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// DeadLetterController lets the calling user inspect, re-drive and discard the completion
+// signals ingest could not record.
+type DeadLetterController struct {
+	deadLetterService *services.DeadLetterService
+}
+
+// NewDeadLetterController creates a new DeadLetterController.
+func NewDeadLetterController(deadLetterService *services.DeadLetterService) *DeadLetterController {
+	return &DeadLetterController{deadLetterService: deadLetterService}
+}
+
+// GetDeadLettersAPI handles GET /api/v2/dead-letters.
+func (ctrl *DeadLetterController) GetDeadLettersAPI(c *gin.Context) {
+	userID, err := utils.VerifyUserAccess(c.GetHeader("Authorization"))
+	if err != nil {
+		RespondUnauthorized(c, "unauthorized")
+		return
+	}
+
+	letters, err := ctrl.deadLetterService.GetUserDeadLetters(dto.GetUserDeadLettersDTO{UserID: userID})
+	if err != nil {
+		fmt.Println(err)
+		RespondInternalServerError(c, "internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deadLetters": letters})
+}
+
+// RedriveDeadLetterAPI handles POST /api/v2/dead-letters/:id/redrive. The body may name the
+// grindID or habitTaskID to record the completion for; ingest errors are answered like
+// POST /api/v2/ingest/:provider answers them.
+func (ctrl *DeadLetterController) RedriveDeadLetterAPI(c *gin.Context) {
+	userID, err := utils.VerifyUserAccess(c.GetHeader("Authorization"))
+	if err != nil {
+		RespondUnauthorized(c, "unauthorized")
+		return
+	}
+
+	var request dto.RedriveDeadLetterDTO
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			RespondBadRequest(c, "invalid request body")
+			return
+		}
+	}
+	request.UserID = userID
+	request.DeadLetterID = c.Param("id")
+
+	event, err := ctrl.deadLetterService.RedriveDeadLetter(request)
+	switch {
+	case errors.Is(err, config.ErrDeadLetterNotFound):
+		RespondNotFound(c, "dead letter not found")
+		return
+	case errors.Is(err, config.ErrForbidden):
+		RespondForbidden(c, "the dead letter or the task belongs to another user")
+		return
+	case errors.Is(err, config.ErrInvalidRedrive):
+		RespondBadRequest(c, err.Error())
+		return
+	case errors.Is(err, config.ErrDeadLetterUnreadable), errors.Is(err, config.ErrIntegrationNotFound):
+		RespondUnprocessableEntity(c, err.Error())
+		return
+	case err != nil:
+		respondIngestError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mappers.BuildCompletionEventDTO(event))
+}
+
+// DiscardDeadLetterAPI handles DELETE /api/v2/dead-letters/:id.
+func (ctrl *DeadLetterController) DiscardDeadLetterAPI(c *gin.Context) {
+	userID, err := utils.VerifyUserAccess(c.GetHeader("Authorization"))
+	if err != nil {
+		RespondUnauthorized(c, "unauthorized")
+		return
+	}
+
+	err = ctrl.deadLetterService.DiscardDeadLetter(dto.DiscardDeadLetterDTO{
+		UserID:       userID,
+		DeadLetterID: c.Param("id"),
+	})
+	switch {
+	case errors.Is(err, config.ErrDeadLetterNotFound):
+		RespondNotFound(c, "dead letter not found")
+		return
+	case errors.Is(err, config.ErrForbidden):
+		RespondForbidden(c, "the dead letter belongs to another user")
+		return
+	case err != nil:
+		fmt.Println(err)
+		RespondInternalServerError(c, "internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Dead letter discarded successfully"})
+}
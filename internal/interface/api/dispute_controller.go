@@ -0,0 +1,119 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
+	"github.com/daniel0321forever/terriyaki-go/internal/application/services"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// DisputeController lets grind partners dispute completion events and vote on disputes.
+type DisputeController struct {
+	disputeService *services.DisputeService
+}
+
+// NewDisputeController creates a new DisputeController.
+func NewDisputeController(disputeService *services.DisputeService) *DisputeController {
+	return &DisputeController{disputeService: disputeService}
+}
+
+// FlagCompletionAPI handles POST /api/v2/completion-events/:id/disputes. The body may give
+// a reason.
+func (ctrl *DisputeController) FlagCompletionAPI(c *gin.Context) {
+	userID, err := utils.VerifyUserAccess(c.GetHeader("Authorization"))
+	if err != nil {
+		RespondUnauthorized(c, "unauthorized")
+		return
+	}
+
+	var request dto.FlagCompletionDTO
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			RespondBadRequest(c, "invalid request body")
+			return
+		}
+	}
+	request.UserID = userID
+	request.CompletionEventID = c.Param("id")
+
+	dispute, err := ctrl.disputeService.FlagCompletion(request)
+	if err != nil {
+		respondDisputeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"dispute": dispute})
+}
+
+// VoteOnDisputeAPI handles POST /api/v2/disputes/:id/votes with {"uphold": true|false}.
+func (ctrl *DisputeController) VoteOnDisputeAPI(c *gin.Context) {
+	userID, err := utils.VerifyUserAccess(c.GetHeader("Authorization"))
+	if err != nil {
+		RespondUnauthorized(c, "unauthorized")
+		return
+	}
+
+	var request dto.VoteOnDisputeDTO
+	if err := c.ShouldBindJSON(&request); err != nil {
+		RespondBadRequest(c, "uphold is required")
+		return
+	}
+	request.UserID = userID
+	request.DisputeID = c.Param("id")
+
+	dispute, err := ctrl.disputeService.VoteOnDispute(request)
+	if err != nil {
+		respondDisputeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"dispute": dispute})
+}
+
+// GetGrindDisputesAPI handles GET /api/v2/grinds/:id/disputes.
+func (ctrl *DisputeController) GetGrindDisputesAPI(c *gin.Context) {
+	userID, err := utils.VerifyUserAccess(c.GetHeader("Authorization"))
+	if err != nil {
+		RespondUnauthorized(c, "unauthorized")
+		return
+	}
+
+	disputes, err := ctrl.disputeService.GetGrindDisputes(dto.GetGrindDisputesDTO{
+		UserID:  userID,
+		GrindID: c.Param("id"),
+	})
+	if err != nil {
+		respondDisputeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"disputes": disputes})
+}
+
+func respondDisputeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, config.ErrCompletionEventNotFound):
+		RespondNotFound(c, "completion event not found")
+	case errors.Is(err, config.ErrDisputeNotFound):
+		RespondNotFound(c, "dispute not found")
+	case errors.Is(err, config.ErrUserIsNotParticipant):
+		RespondForbidden(c, "user is not a partner in this grind")
+	case errors.Is(err, config.ErrInvalidDispute):
+		RespondBadRequest(c, err.Error())
+	case errors.Is(err, config.ErrReviewWindowClosed):
+		RespondUnprocessableEntity(c, err.Error())
+	case errors.Is(err, config.ErrDisputeExists),
+		errors.Is(err, config.ErrAlreadyVoted),
+		errors.Is(err, config.ErrDisputeClosed),
+		errors.Is(err, config.ErrParticipationFinalized):
+		RespondConflict(c, err.Error())
+	default:
+		fmt.Println(err)
+		RespondInternalServerError(c, "internal server error")
+	}
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"net/http"
 
+	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
 	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
 	"github.com/gin-gonic/gin"
 )
@@ -13,6 +14,13 @@ type ErrorResponse struct {
 	ErrorCode string `json:"errorCode"`
 }
 
+// ValidationErrorResponse is an ErrorResponse that also names each invalid field
+type ValidationErrorResponse struct {
+	Message   string              `json:"message"`
+	ErrorCode string              `json:"errorCode"`
+	Fields    []dto.FieldErrorDTO `json:"fields"`
+}
+
 // RespondError sends a standardized error response with the given status code and error details
 func RespondError(c *gin.Context, statusCode int, code string, message string) {
 	c.JSON(statusCode, ErrorResponse{
@@ -55,3 +63,17 @@ func RespondForbidden(c *gin.Context, message string) {
 func RespondUnprocessableEntity(c *gin.Context, message string) {
 	RespondError(c, http.StatusUnprocessableEntity, config.ERROR_CODE_UNPROCESSABLE_ENTITY, message)
 }
+
+// RespondInvalidPayload sends a 422 Unprocessable Entity error response listing the invalid fields of a payload
+func RespondInvalidPayload(c *gin.Context, message string, fields []dto.FieldErrorDTO) {
+	c.JSON(http.StatusUnprocessableEntity, ValidationErrorResponse{
+		Message:   message,
+		ErrorCode: config.ERROR_CODE_INVALID_PAYLOAD,
+		Fields:    fields,
+	})
+}
+
+// RespondInvalidGrindState sends a 409 Conflict error response for an operation the grind's lifecycle state does not allow
+func RespondInvalidGrindState(c *gin.Context, message string) {
+	RespondError(c, http.StatusConflict, config.ERROR_CODE_INVALID_GRIND_STATE, message)
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
+	"github.com/daniel0321forever/terriyaki-go/internal/application/services"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// EvidenceController serves the evidence files attached to completion events.
+type EvidenceController struct {
+	evidenceService *services.EvidenceService
+}
+
+// NewEvidenceController creates a new EvidenceController.
+func NewEvidenceController(evidenceService *services.EvidenceService) *EvidenceController {
+	return &EvidenceController{evidenceService: evidenceService}
+}
+
+// GetEvidenceAPI handles GET /api/v2/completion-events/:id/evidence. Only the completion's
+// owner and its reviewers, the grind's participants and partner group members, may
+// download it. The file is always sent as an attachment so an uploaded file is never
+// rendered in the API's origin.
+func (ctrl *EvidenceController) GetEvidenceAPI(c *gin.Context) {
+	userID, err := utils.VerifyUserAccess(c.GetHeader("Authorization"))
+	if err != nil {
+		RespondUnauthorized(c, "unauthorized")
+		return
+	}
+
+	content, evidence, err := ctrl.evidenceService.OpenEvidence(dto.GetEvidenceDTO{
+		UserID:            userID,
+		CompletionEventID: c.Param("id"),
+	})
+	switch {
+	case errors.Is(err, config.ErrCompletionEventNotFound):
+		RespondNotFound(c, "completion event not found")
+		return
+	case errors.Is(err, config.ErrEvidenceNotFound):
+		RespondNotFound(c, "the completion event has no evidence")
+		return
+	case errors.Is(err, config.ErrUserIsNotParticipant):
+		RespondForbidden(c, "user is not a partner in the grind")
+		return
+	case err != nil:
+		fmt.Println(err)
+		RespondInternalServerError(c, "internal server error")
+		return
+	}
+	defer content.Close()
+
+	c.DataFromReader(http.StatusOK, evidence.Size, evidence.ContentType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": evidence.FileName}),
+		"X-Content-Type-Options": "nosniff",
+		"ETag":                   `"` + evidence.SHA256 + `"`,
+	})
+}
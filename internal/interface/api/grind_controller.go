@@ -1,17 +1,19 @@
 package api
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
-	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
 	"github.com/daniel0321forever/terriyaki-go/internal/application/services"
 	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
 	"github.com/daniel0321forever/terriyaki-go/internal/cores/utils"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
 	"github.com/gin-gonic/gin"
 )
 
@@ -93,13 +95,59 @@ func (ctrl *GrindController) CreateGrindAPI(c *gin.Context) {
 		RespondBadRequest(c, "invalid startDate")
 		return
 	}
+
+	// penaltyPolicy is optional; without it the grind gets the default flat policy
+	var penaltyPolicy *dto.PenaltyPolicyDTO
+	if rawPolicy, ok := body["penaltyPolicy"]; ok && rawPolicy != nil {
+		policyBytes, _ := json.Marshal(rawPolicy)
+		penaltyPolicy = &dto.PenaltyPolicyDTO{}
+		if err := json.Unmarshal(policyBytes, penaltyPolicy); err != nil {
+			RespondBadRequest(c, "invalid penaltyPolicy")
+			return
+		}
+	}
+
+	// schedule is optional; without it every day of the grind is a habit day
+	var schedule *dto.ScheduleDTO
+	if rawSchedule, ok := body["schedule"]; ok && rawSchedule != nil {
+		scheduleBytes, _ := json.Marshal(rawSchedule)
+		schedule = &dto.ScheduleDTO{}
+		if err := json.Unmarshal(scheduleBytes, schedule); err != nil {
+			RespondBadRequest(c, "invalid schedule")
+			return
+		}
+	}
+
+	// roadmap is optional; with it every habit day is assigned a LeetCode problem
+	var roadmap *dto.RoadmapDTO
+	if rawRoadmap, ok := body["roadmap"]; ok && rawRoadmap != nil {
+		roadmapBytes, _ := json.Marshal(rawRoadmap)
+		roadmap = &dto.RoadmapDTO{}
+		if err := json.Unmarshal(roadmapBytes, roadmap); err != nil {
+			RespondBadRequest(c, "invalid roadmap")
+			return
+		}
+	}
+
+	// draft is optional; a draft grind stays private until it is published
+	draft, _ := body["draft"].(bool)
+
 	createGrindDTO := dto.CreateGrindDTO{
-		CreatorID: userID,
-		Duration:  duration,
-		Budget:    budget,
-		StartDate: startDate,
+		CreatorID:     userID,
+		Duration:      duration,
+		Budget:        budget,
+		StartDate:     startDate,
+		PenaltyPolicy: penaltyPolicy,
+		Schedule:      schedule,
+		Roadmap:       roadmap,
+		Draft:         draft,
 	}
 	grindDTO, err := ctrl.grindService.CreateGroupGrind(createGrindDTO)
+	if errors.Is(err, config.ErrInvalidPenaltyPolicy) || errors.Is(err, config.ErrInvalidSchedule) ||
+		errors.Is(err, config.ErrInvalidRoadmap) {
+		RespondBadRequest(c, err.Error())
+		return
+	}
 
 	// Convert participant emails to slice of strings
 	participants, _ := body["participants"].([]interface{})
@@ -213,32 +261,38 @@ func (ctrl *GrindController) GetAllUserGrindsAPI(c *gin.Context) {
 		return
 	}
 
+	// ?state=recruiting,active narrows the list to grinds in those lifecycle states
+	var states []string
+	if stateParam := c.Query("state"); stateParam != "" {
+		states = strings.Split(stateParam, ",")
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
 	getGrindsDTO := dto.GetAllUserGrindsDTO{
 		UserID: userID,
+		States: states,
+		Cursor: c.Query("cursor"),
+		Limit:  limit,
 	}
 
-	grindDTOs, err := ctrl.grindService.GetAllUserGrinds(getGrindsDTO)
+	page, err := ctrl.grindService.GetAllUserGrinds(getGrindsDTO)
+	if errors.Is(err, config.ErrInvalidGrindState) || errors.Is(err, config.ErrInvalidCursor) {
+		RespondBadRequest(c, err.Error())
+		return
+	}
 	if err != nil {
 		fmt.Println(err)
 		RespondInternalServerError(c, "internal server error")
 		return
 	}
 
-	ids := make([]string, 0, len(grindDTOs))
-	for id := range grindDTOs {
-		ids = append(ids, id)
-	}
-	// Sort grind IDs in ascending lexicographic order.
-	sort.Strings(ids)
-
-	grinds := make([]*dto.GroupGrindDTO, 0, len(ids))
-	for _, id := range ids {
-		grinds = append(grinds, grindDTOs[id])
-	}
-
-	c.JSON(http.StatusOK, grinds)
+	setNextCursor(c, page.NextCursor)
+	c.JSON(http.StatusOK, page.Grinds)
 }
 
+// UpdateGrindAPI changes a grind that has not started yet. Fields left out of the body
+// keep their current value. Budget and penalty policy changes need the other
+// participants' approval and come back as pendingStakeChange.
+// PATCH /api/v2/grinds/:id
 func (ctrl *GrindController) UpdateGrindAPI(c *gin.Context) {
 	token := c.GetHeader("Authorization")
 	userID, err := utils.VerifyUserAccess(token)
@@ -247,29 +301,150 @@ func (ctrl *GrindController) UpdateGrindAPI(c *gin.Context) {
 		return
 	}
 
-	grindID := c.Param("id")
+	var updateGrindDTO dto.UpdateGrindDTO
+	if err := c.ShouldBindJSON(&updateGrindDTO); err != nil {
+		fmt.Println(err)
+		RespondBadRequest(c, "invalid request body")
+		return
+	}
+	updateGrindDTO.GrindID = c.Param("id")
+	updateGrindDTO.UserID = userID
 
-	duration, _ := strconv.Atoi(c.PostForm("duration"))
-	budget, _ := strconv.Atoi(c.PostForm("budget"))
-	updateGrindDTO := dto.UpdateGrindDTO{
-		GrindID:  grindID,
-		UserID:   userID,
-		Duration: duration,
-		Budget:   budget,
+	result, err := ctrl.grindService.UpdateGrind(updateGrindDTO)
+	if err != nil {
+		fmt.Println(err)
+		if errors.Is(err, config.ErrGrindNotFound) {
+			RespondNotFound(c, "grind not found")
+			return
+		}
+		if errors.Is(err, config.ErrUserIsNotParticipant) {
+			RespondForbidden(c, "user is not a participant of this grind")
+			return
+		}
+		if errors.Is(err, config.ErrInvalidGrindUpdate) || errors.Is(err, config.ErrInvalidPenaltyPolicy) ||
+			errors.Is(err, config.ErrInvalidSchedule) || errors.Is(err, config.ErrInvalidRoadmap) {
+			RespondBadRequest(c, err.Error())
+			return
+		}
+		if respondGrindStateError(c, err) {
+			return
+		}
+		RespondInternalServerError(c, "internal server error")
+		return
 	}
 
-	grind, err := ctrl.grindService.UpdateGrind(updateGrindDTO)
+	c.JSON(http.StatusOK, gin.H{
+		"message":            "Grind updated successfully",
+		"grind":              result.Grind,
+		"pendingStakeChange": result.PendingStakeChange,
+	})
+}
+
+// ApproveStakeChangeAPI approves a pending budget or penalty change; the last approval applies it.
+// POST /api/v2/grinds/:id/stake-changes/:changeId/approve
+func (ctrl *GrindController) ApproveStakeChangeAPI(c *gin.Context) {
+	ctrl.respondStakeChangeAPI(c, ctrl.grindService.ApproveStakeChange)
+}
+
+// RejectStakeChangeAPI rejects a pending budget or penalty change.
+// POST /api/v2/grinds/:id/stake-changes/:changeId/reject
+func (ctrl *GrindController) RejectStakeChangeAPI(c *gin.Context) {
+	ctrl.respondStakeChangeAPI(c, ctrl.grindService.RejectStakeChange)
+}
+
+func (ctrl *GrindController) respondStakeChangeAPI(
+	c *gin.Context,
+	respond func(dto.RespondStakeChangeDTO) (*dto.StakeChangeDTO, error),
+) {
+	token := c.GetHeader("Authorization")
+	userID, err := utils.VerifyUserAccess(token)
+	if err != nil {
+		RespondUnauthorized(c, "unauthorized")
+		return
+	}
+
+	change, err := respond(dto.RespondStakeChangeDTO{
+		GrindID:       c.Param("id"),
+		StakeChangeID: c.Param("changeId"),
+		UserID:        userID,
+	})
 	if err != nil {
 		fmt.Println(err)
+		if errors.Is(err, config.ErrStakeChangeNotFound) || errors.Is(err, config.ErrGrindNotFound) {
+			RespondNotFound(c, "stake change not found")
+			return
+		}
 		if errors.Is(err, config.ErrUserIsNotParticipant) {
 			RespondForbidden(c, "user is not a participant of this grind")
 			return
 		}
+		if errors.Is(err, config.ErrStakeChangeNotPending) {
+			RespondConflict(c, err.Error())
+			return
+		}
+		if respondGrindStateError(c, err) {
+			return
+		}
+		RespondInternalServerError(c, "internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"stakeChange": change})
+}
+
+// PublishGrindAPI opens a draft grind for invitations and joins.
+// POST /api/v2/grinds/:id/publish
+func (ctrl *GrindController) PublishGrindAPI(c *gin.Context) {
+	ctrl.transitionGrindAPI(c, ctrl.grindService.PublishGrind, "Grind published successfully")
+}
+
+// CancelGrindAPI abandons a grind that has not started yet.
+// POST /api/v2/grinds/:id/cancel
+func (ctrl *GrindController) CancelGrindAPI(c *gin.Context) {
+	ctrl.transitionGrindAPI(c, ctrl.grindService.CancelGrind, "Grind cancelled successfully")
+}
+
+func (ctrl *GrindController) transitionGrindAPI(
+	c *gin.Context,
+	transition func(dto.TransitionGrindDTO) (*dto.GroupGrindDTO, error),
+	successMessage string,
+) {
+	token := c.GetHeader("Authorization")
+	userID, err := utils.VerifyUserAccess(token)
+	if err != nil {
+		RespondUnauthorized(c, "unauthorized")
+		return
+	}
+
+	grind, err := transition(dto.TransitionGrindDTO{GrindID: c.Param("id"), UserID: userID})
+	if errors.Is(err, config.ErrGrindNotFound) {
+		RespondNotFound(c, "grind not found")
+		return
+	}
+	if errors.Is(err, config.ErrUserIsNotParticipant) {
+		RespondForbidden(c, "user is not a participant of this grind")
+		return
+	}
+	if err != nil {
+		if respondGrindStateError(c, err) {
+			return
+		}
+		fmt.Println(err)
 		RespondInternalServerError(c, "internal server error")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Grind updated successfully", "grind": grind})
+	c.JSON(http.StatusOK, gin.H{"message": successMessage, "grind": grind})
+}
+
+// respondGrindStateError answers 409 when err is a lifecycle violation and reports whether it did.
+func respondGrindStateError(c *gin.Context, err error) bool {
+	var stateErr *entities.GrindStateError
+	if errors.As(err, &stateErr) || errors.Is(err, config.ErrGrindStateChanged) || errors.Is(err, config.ErrGrindAlreadyStarted) {
+		RespondInvalidGrindState(c, err.Error())
+		return true
+	}
+	return false
 }
 
 func (ctrl *GrindController) DeleteGrindAPI(c *gin.Context) {
@@ -322,6 +497,13 @@ func (ctrl *GrindController) QuitGrindAPI(c *gin.Context) {
 	}
 
 	participationDTO, err := ctrl.grindService.QuitGrind(quitGrindDTO)
+	if errors.Is(err, config.ErrParticipationFinalized) {
+		RespondUnprocessableEntity(c, "grind has already been settled")
+		return
+	}
+	if err != nil && respondGrindStateError(c, err) {
+		return
+	}
 	if err != nil {
 		RespondInternalServerError(c, "internal server error")
 		return
@@ -1,27 +1,49 @@
 package api
 
 import (
+	"bytes"
 	"crypto/hmac"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
 	"net/http"
 	"os"
 	"strings"
 
+	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
 	"github.com/daniel0321forever/terriyaki-go/internal/application/mappers"
 	"github.com/daniel0321forever/terriyaki-go/internal/application/services"
 	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
 	"github.com/daniel0321forever/terriyaki-go/internal/cores/utils"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
 	"github.com/gin-gonic/gin"
 )
 
+// ingestBodyLimit is the largest JSON payload accepted; multipart uploads may add an
+// evidence file of up to services.MaxEvidenceSize.
+const ingestBodyLimit = 1 << 20
+
 // IngestController handles POST /api/v2/ingest/:provider.
 type IngestController struct {
-	ingestService *services.IngestService
+	ingestService      *services.IngestService
+	integrationService *services.IntegrationService
+	evidenceService    *services.EvidenceService
 }
 
 // NewIngestController creates a new IngestController.
-func NewIngestController(ingestService *services.IngestService) *IngestController {
-	return &IngestController{ingestService: ingestService}
+func NewIngestController(
+	ingestService *services.IngestService,
+	integrationService *services.IntegrationService,
+	evidenceService *services.EvidenceService,
+) *IngestController {
+	return &IngestController{
+		ingestService:      ingestService,
+		integrationService: integrationService,
+		evidenceService:    evidenceService,
+	}
 }
 
 // HandleIngest processes an external habit completion signal.
@@ -29,9 +51,24 @@ func NewIngestController(ingestService *services.IngestService) *IngestControlle
 //   - Bearer <jwt>: Chrome-extension user token — userID extracted from JWT.
 //   - ApiKey <key>: B2B webhook token — constant-time compared against INGEST_API_KEY;
 //     userID must be supplied in the request body.
+//
+// The custom provider authenticates with its integration's signature instead, see
+// handleCustomIngest.
+//
+// The body is the JSON payload, or multipart/form-data with the JSON payload in a
+// "payload" part and a file attached as proof in an optional "evidence" part.
 func (ctrl *IngestController) HandleIngest(c *gin.Context) {
 	// Enforce 1 MB body limit before parsing (mitigates T-02-09 DoS via large JSONB payload).
-	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, 1<<20)
+	limit := int64(ingestBodyLimit)
+	if isMultipart(c.GetHeader("Content-Type")) {
+		limit += services.MaxEvidenceSize
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+
+	if c.Param("provider") == string(entities.ProviderCustom) {
+		ctrl.handleCustomIngest(c)
+		return
+	}
 
 	authHeader := c.GetHeader("Authorization")
 	var userID string
@@ -65,9 +102,17 @@ func (ctrl *IngestController) HandleIngest(c *gin.Context) {
 		return
 	}
 
-	var rawBody map[string]interface{}
-	if err := c.ShouldBindJSON(&rawBody); err != nil {
-		RespondBadRequest(c, "invalid request body")
+	provider := c.Param("provider")
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		respondBodyReadError(c, err)
+		return
+	}
+	rawBody, upload, err := parseIngestBody(c.GetHeader("Content-Type"), body)
+	if err != nil {
+		// only a bearer token says whose signal it was
+		ctrl.ingestService.RecordUnreadable(provider, userID, "", body, err)
+		RespondBadRequest(c, "invalid request body: "+err.Error())
 		return
 	}
 
@@ -83,23 +128,217 @@ func (ctrl *IngestController) HandleIngest(c *gin.Context) {
 
 	grindID, ok := rawBody["grindID"].(string)
 	if !ok || grindID == "" {
+		ctrl.ingestService.RecordUnreadable(provider, userID, "", body, errors.New("grindID is required"))
 		RespondBadRequest(c, "grindID is required")
 		return
 	}
 
-	provider := c.Param("provider")
-	event, err := ctrl.ingestService.Ingest(provider, userID, grindID, rawBody)
+	evidence, ok := ctrl.storeEvidence(c, upload)
+	if !ok {
+		return
+	}
+	event, replayed, err := ctrl.ingestService.Ingest(provider, userID, grindID, rawBody, evidence)
+	if evidenceUnused(err, replayed) {
+		ctrl.evidenceService.DiscardEvidence(evidence)
+	}
+	if err != nil {
+		respondIngestError(c, err)
+		return
+	}
+
+	respondIngested(c, event, replayed)
+}
+
+// handleCustomIngest processes a completion sent through a custom integration. The
+// integration is named by X-Integration-ID and the raw body must be signed with its secret
+// together with the time it was sent, X-Signature-Timestamp in unix seconds:
+// X-Signature: sha256=<hex HMAC-SHA256 of "<timestamp>.<body>">. userID in the body is
+// optional and defaults to the integration's owner; group integrations may name any group
+// member.
+func (ctrl *IngestController) handleCustomIngest(c *gin.Context) {
+	integrationID := c.GetHeader("X-Integration-ID")
+	timestamp := c.GetHeader("X-Signature-Timestamp")
+	signature := c.GetHeader("X-Signature")
+	if integrationID == "" || timestamp == "" || signature == "" {
+		RespondUnauthorized(c, "X-Integration-ID, X-Signature-Timestamp and X-Signature are required")
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
 	if err != nil {
-		switch {
-		case errors.Is(err, config.ErrHabitTaskNotFound):
-			RespondNotFound(c, "no habit task found for today")
-		case strings.Contains(err.Error(), "unsupported provider"):
-			RespondBadRequest(c, err.Error())
-		default:
-			RespondInternalServerError(c, "ingestion failed")
+		respondBodyReadError(c, err)
+		return
+	}
+	integration, err := ctrl.integrationService.Authenticate(integrationID, timestamp, body, signature)
+	if err != nil {
+		RespondUnauthorized(c, "invalid or missing credentials")
+		return
+	}
+
+	rawBody, upload, err := parseIngestBody(c.GetHeader("Content-Type"), body)
+	if err != nil {
+		ctrl.ingestService.RecordUnreadable(string(entities.ProviderCustom), integration.OwnerID, integration.ID, body, err)
+		RespondBadRequest(c, "invalid request body: "+err.Error())
+		return
+	}
+	grindID, ok := rawBody["grindID"].(string)
+	if !ok || grindID == "" {
+		ctrl.ingestService.RecordUnreadable(string(entities.ProviderCustom), integration.OwnerID, integration.ID, body,
+			errors.New("grindID is required"))
+		RespondBadRequest(c, "grindID is required")
+		return
+	}
+	claimedUserID, _ := rawBody["userID"].(string)
+	userID, err := ctrl.integrationService.ResolveUser(integration, claimedUserID)
+	if err != nil {
+		RespondForbidden(c, "the integration cannot ingest for this user")
+		return
+	}
+
+	evidence, ok := ctrl.storeEvidence(c, upload)
+	if !ok {
+		return
+	}
+	event, replayed, err := ctrl.ingestService.IngestCustom(integration, userID, grindID, rawBody, evidence)
+	if evidenceUnused(err, replayed) {
+		ctrl.evidenceService.DiscardEvidence(evidence)
+	}
+	if err != nil {
+		respondIngestError(c, err)
+		return
+	}
+
+	respondIngested(c, event, replayed)
+}
+
+// storeEvidence puts an uploaded evidence file in the blob store. It answers the request
+// and returns false when the file is rejected; without an upload it returns nil and true.
+func (ctrl *IngestController) storeEvidence(c *gin.Context, upload *dto.EvidenceUploadDTO) (*entities.EvidenceAttachment, bool) {
+	if upload == nil {
+		return nil, true
+	}
+	evidence, err := ctrl.evidenceService.StoreEvidence(*upload)
+	switch {
+	case errors.Is(err, config.ErrEvidenceTooLarge):
+		RespondError(c, http.StatusRequestEntityTooLarge, config.ERROR_CODE_EVIDENCE_TOO_LARGE, err.Error())
+		return nil, false
+	case errors.Is(err, config.ErrUnsupportedEvidenceType):
+		RespondError(c, http.StatusUnsupportedMediaType, config.ERROR_CODE_UNSUPPORTED_EVIDENCE_TYPE, err.Error())
+		return nil, false
+	case errors.Is(err, config.ErrInvalidEvidence):
+		RespondBadRequest(c, err.Error())
+		return nil, false
+	case err != nil:
+		fmt.Println(err)
+		RespondInternalServerError(c, "failed to store evidence")
+		return nil, false
+	}
+	return evidence, true
+}
+
+func isMultipart(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "multipart/form-data"
+}
+
+// parseIngestBody reads a JSON body, or a multipart/form-data body whose "payload" part is
+// the JSON payload and whose optional "evidence" part is a file attached as proof.
+func parseIngestBody(contentType string, body []byte) (map[string]interface{}, *dto.EvidenceUploadDTO, error) {
+	if !isMultipart(contentType) {
+		var rawBody map[string]interface{}
+		if err := json.Unmarshal(body, &rawBody); err != nil || rawBody == nil {
+			return nil, nil, errors.New("the body is not a JSON object")
 		}
+		return rawBody, nil, nil
+	}
+
+	_, params, _ := mime.ParseMediaType(contentType)
+	reader := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+	var rawBody map[string]interface{}
+	var upload *dto.EvidenceUploadDTO
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, errors.New("the multipart body is malformed")
+		}
+		content, err := io.ReadAll(part)
+		if err != nil {
+			return nil, nil, errors.New("the multipart body is malformed")
+		}
+		switch part.FormName() {
+		case "payload":
+			if err := json.Unmarshal(content, &rawBody); err != nil || rawBody == nil {
+				return nil, nil, errors.New("the payload part is not a JSON object")
+			}
+		case "evidence":
+			if upload != nil {
+				return nil, nil, errors.New("only one evidence file is accepted")
+			}
+			upload = &dto.EvidenceUploadDTO{
+				FileName:    part.FileName(),
+				ContentType: part.Header.Get("Content-Type"),
+				Content:     content,
+			}
+		}
+	}
+	if rawBody == nil {
+		return nil, nil, errors.New("the payload part is missing")
+	}
+	return rawBody, upload, nil
+}
+
+// respondBodyReadError answers a body that could not be read, usually one over the limit.
+func respondBodyReadError(c *gin.Context, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		RespondError(c, http.StatusRequestEntityTooLarge, config.ERROR_CODE_EVIDENCE_TOO_LARGE, "request body is too large")
 		return
 	}
+	RespondBadRequest(c, "invalid request body")
+}
+
+// respondIngested answers 201 with a new event and 200 with the original event of a
+// retried delivery.
+func respondIngested(c *gin.Context, event *entities.CompletionEvent, replayed bool) {
+	status := http.StatusCreated
+	if replayed {
+		status = http.StatusOK
+	}
+	c.JSON(status, mappers.BuildCompletionEventDTO(event))
+}
+
+// evidenceUnused says whether ingest left the uploaded evidence to its caller: the signal
+// was a retry, or it was rejected without being kept as a dead letter, which keeps it.
+func evidenceUnused(err error, replayed bool) bool {
+	if err == nil {
+		return replayed
+	}
+	var deadLettered *services.DeadLetteredError
+	return !errors.As(err, &deadLettered)
+}
 
-	c.JSON(http.StatusCreated, mappers.BuildCompletionEventDTO(event))
+func respondIngestError(c *gin.Context, err error) {
+	var invalid *services.PayloadValidationError
+	switch {
+	case errors.As(err, &invalid):
+		RespondInvalidPayload(c, fmt.Sprintf("the %s payload does not match its schema", invalid.Provider), invalid.Fields)
+	case errors.Is(err, config.ErrHabitTaskNotFound):
+		RespondNotFound(c, "no habit task found for the day the completion occurred on")
+	case errors.Is(err, config.ErrIngestWindowClosed):
+		RespondError(c, http.StatusUnprocessableEntity, config.ERROR_CODE_INGEST_WINDOW_CLOSED, err.Error())
+	case errors.Is(err, config.ErrSubmissionDoesNotMatchTask),
+		errors.Is(err, config.ErrSubmissionNotVerified),
+		errors.Is(err, config.ErrLeetCodeUsernameMissing),
+		errors.Is(err, config.ErrInvalidCustomPayload):
+		RespondUnprocessableEntity(c, err.Error())
+	case errors.Is(err, config.ErrLeetCodeUnavailable):
+		RespondError(c, http.StatusServiceUnavailable, config.ERROR_CODE_LEETCODE_UNAVAILABLE, "could not verify the submission with LeetCode, try again later")
+	case strings.Contains(err.Error(), "unsupported provider"):
+		RespondBadRequest(c, err.Error())
+	default:
+		RespondInternalServerError(c, "ingestion failed")
+	}
 }
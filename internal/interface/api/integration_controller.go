@@ -0,0 +1,110 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
+	"github.com/daniel0321forever/terriyaki-go/internal/application/services"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// IntegrationController manages the custom provider integrations of the calling user.
+type IntegrationController struct {
+	integrationService *services.IntegrationService
+}
+
+// NewIntegrationController creates a new IntegrationController.
+func NewIntegrationController(integrationService *services.IntegrationService) *IntegrationController {
+	return &IntegrationController{integrationService: integrationService}
+}
+
+// CreateIntegrationAPI handles POST /api/v2/integrations. The response carries the
+// integration's secret, which is never shown again.
+func (ctrl *IntegrationController) CreateIntegrationAPI(c *gin.Context) {
+	userID, err := utils.VerifyUserAccess(c.GetHeader("Authorization"))
+	if err != nil {
+		RespondUnauthorized(c, "unauthorized")
+		return
+	}
+
+	var request struct {
+		Name    string                         `json:"name"`
+		GroupID string                         `json:"groupID"`
+		Mapping dto.IntegrationFieldMappingDTO `json:"mapping"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		RespondBadRequest(c, "invalid request body")
+		return
+	}
+
+	integration, err := ctrl.integrationService.CreateIntegration(dto.CreateIntegrationDTO{
+		UserID:  userID,
+		Name:    request.Name,
+		GroupID: request.GroupID,
+		Mapping: request.Mapping,
+	})
+	switch {
+	case errors.Is(err, config.ErrInvalidIntegration):
+		RespondBadRequest(c, err.Error())
+		return
+	case errors.Is(err, config.ErrForbidden):
+		RespondForbidden(c, "only the group owner can add an integration to the group")
+		return
+	case err != nil:
+		fmt.Println(err)
+		RespondInternalServerError(c, "internal server error")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"integration": integration})
+}
+
+// GetIntegrationsAPI handles GET /api/v2/integrations.
+func (ctrl *IntegrationController) GetIntegrationsAPI(c *gin.Context) {
+	userID, err := utils.VerifyUserAccess(c.GetHeader("Authorization"))
+	if err != nil {
+		RespondUnauthorized(c, "unauthorized")
+		return
+	}
+
+	integrations, err := ctrl.integrationService.GetUserIntegrations(dto.GetUserIntegrationsDTO{UserID: userID})
+	if err != nil {
+		fmt.Println(err)
+		RespondInternalServerError(c, "internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"integrations": integrations})
+}
+
+// DeleteIntegrationAPI handles DELETE /api/v2/integrations/:id.
+func (ctrl *IntegrationController) DeleteIntegrationAPI(c *gin.Context) {
+	userID, err := utils.VerifyUserAccess(c.GetHeader("Authorization"))
+	if err != nil {
+		RespondUnauthorized(c, "unauthorized")
+		return
+	}
+
+	err = ctrl.integrationService.DeleteIntegration(dto.DeleteIntegrationDTO{
+		UserID:        userID,
+		IntegrationID: c.Param("id"),
+	})
+	switch {
+	case errors.Is(err, config.ErrIntegrationNotFound):
+		RespondNotFound(c, "integration not found")
+		return
+	case errors.Is(err, config.ErrForbidden):
+		RespondForbidden(c, "only the owner can delete an integration")
+		return
+	case err != nil:
+		fmt.Println(err)
+		RespondInternalServerError(c, "internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Integration deleted successfully"})
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
+	"github.com/daniel0321forever/terriyaki-go/internal/application/services"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// LeaderboardController serves grind leaderboards.
+type LeaderboardController struct {
+	leaderboardService *services.LeaderboardService
+}
+
+// NewLeaderboardController creates a new LeaderboardController.
+func NewLeaderboardController(leaderboardService *services.LeaderboardService) *LeaderboardController {
+	return &LeaderboardController{leaderboardService: leaderboardService}
+}
+
+// GetLeaderboardAPI handles GET /api/v2/grinds/:id/leaderboard?sortBy=completionRate.
+func (ctrl *LeaderboardController) GetLeaderboardAPI(c *gin.Context) {
+	userID, err := utils.VerifyUserAccess(c.GetHeader("Authorization"))
+	if err != nil {
+		RespondUnauthorized(c, "unauthorized")
+		return
+	}
+
+	leaderboard, err := ctrl.leaderboardService.GetLeaderboard(dto.GetLeaderboardDTO{
+		GrindID: c.Param("id"),
+		UserID:  userID,
+		SortBy:  c.Query("sortBy"),
+	})
+	switch {
+	case errors.Is(err, config.ErrInvalidLeaderboardSort):
+		RespondBadRequest(c, err.Error())
+		return
+	case errors.Is(err, config.ErrGrindNotFound):
+		RespondNotFound(c, "grind not found")
+		return
+	case errors.Is(err, config.ErrUserIsNotParticipant):
+		RespondForbidden(c, "user is not a participant of the grind")
+		return
+	case err != nil:
+		fmt.Println(err)
+		RespondInternalServerError(c, "internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"leaderboard": leaderboard})
+}
@@ -1,11 +1,14 @@
 package api
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
 	"github.com/daniel0321forever/terriyaki-go/internal/application/services"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
 	"github.com/daniel0321forever/terriyaki-go/internal/cores/utils"
 	"github.com/gin-gonic/gin"
 )
@@ -45,27 +48,54 @@ func (ctrl *MessageController) GetMessageAPI(c *gin.Context) {
 		return
 	}
 
-	offsetStr := c.DefaultQuery("offset", "0")
-	offset, _ := strconv.Atoi(offsetStr)
-	limitStr := c.Query("limit")
-	limit, _ := strconv.Atoi(limitStr)
+	filter, err := messageFilterQuery(c)
+	if err != nil {
+		RespondBadRequest(c, err.Error())
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
 
 	getMessageDTO := dto.GetAllMessagesForReceiverDTO{
 		ReceiverID: userID,
-		Offset:     offset,
+		Filter:     filter,
+		Cursor:     c.Query("cursor"),
 		Limit:      limit,
 	}
-	messageDTOs, err := ctrl.messageService.GetAllMessagesForReceiver(getMessageDTO)
+	page, err := ctrl.messageService.GetAllMessagesForReceiver(getMessageDTO)
 	if err != nil {
-		RespondInternalServerError(c, "internal server error")
+		respondMessageError(c, err)
 		return
 	}
 
-	// Build response with related data
-	responseData := []*dto.MessageDTO{}
-	responseData = append(responseData, messageDTOs...)
+	setNextCursor(c, page.NextCursor)
+	c.JSON(http.StatusOK, page.Messages)
+}
 
-	c.JSON(http.StatusOK, responseData)
+// GetUnreadMessageCountAPI handles GET /api/v2/messages/unread-count, narrowed by the same
+// type and grindID filters as the inbox.
+func (ctrl *MessageController) GetUnreadMessageCountAPI(c *gin.Context) {
+	userID, err := utils.VerifyUserAccess(c.GetHeader("Authorization"))
+	if err != nil {
+		RespondUnauthorized(c, "unauthorized")
+		return
+	}
+
+	filter, err := messageFilterQuery(c)
+	if err != nil {
+		RespondBadRequest(c, err.Error())
+		return
+	}
+
+	count, err := ctrl.messageService.CountUnreadMessages(dto.CountUnreadMessagesDTO{
+		ReceiverID: userID,
+		Filter:     filter,
+	})
+	if err != nil {
+		respondMessageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"unreadCount": count})
 }
 
 func (ctrl *MessageController) ReadMessageAPI(c *gin.Context) {
@@ -130,7 +160,18 @@ func (ctrl *MessageController) CreateInvitationAPI(c *gin.Context) {
 	}
 	messageDTO, err := ctrl.messageService.CreateInvitationMessage(createMessageDTO)
 	if err != nil {
-		RespondInternalServerError(c, "internal server error")
+		if respondGrindStateError(c, err) || respondInvitationError(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, config.ErrUserNotFound):
+			RespondNotFound(c, "participant not found")
+		case errors.Is(err, config.ErrGrindNotFound):
+			RespondNotFound(c, "grind not found")
+		default:
+			fmt.Println(err)
+			RespondInternalServerError(c, "internal server error")
+		}
 		return
 	}
 
@@ -202,6 +243,9 @@ func (ctrl *MessageController) AcceptInvitationAPI(c *gin.Context) {
 		createAcceptedMsgDTO,
 		ctrl.grindService.MessageRepo(),
 	); err != nil {
+		if respondGrindStateError(c, err) || respondInvitationError(c, err) {
+			return
+		}
 		RespondInternalServerError(c, "internal server error")
 		return
 	}
@@ -275,6 +319,9 @@ func (ctrl *MessageController) RejectInvitationAPI(c *gin.Context) {
 	}
 
 	if err := ctrl.messageService.RejectInvitationTx(updateMessageDTO, createRejectedMsgDTO); err != nil {
+		if respondInvitationError(c, err) {
+			return
+		}
 		RespondInternalServerError(c, "internal server error")
 		return
 	}
@@ -298,6 +345,57 @@ func (ctrl *MessageController) RejectInvitationAPI(c *gin.Context) {
 	})
 }
 
+// RevokeInvitationAPI handles POST /api/v2/messages/:id/invitation/revoke: the sender
+// withdraws an invitation that has not been answered yet.
+func (ctrl *MessageController) RevokeInvitationAPI(c *gin.Context) {
+	userID, err := utils.VerifyUserAccess(c.GetHeader("Authorization"))
+	if err != nil {
+		RespondUnauthorized(c, "unauthorized")
+		return
+	}
+
+	messageDTO, err := ctrl.messageService.RevokeInvitation(dto.RevokeInvitationDTO{
+		MessageID: c.Param("id"),
+		SenderID:  userID,
+	})
+	if err != nil {
+		if respondInvitationError(c, err) {
+			return
+		}
+		fmt.Println(err)
+		RespondInternalServerError(c, "internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Invitation revoked successfully",
+		"data":    messageDTO,
+	})
+}
+
+// respondInvitationError writes the response for an invitation that cannot be created,
+// answered or revoked and reports whether err was one.
+func respondInvitationError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, config.ErrInvitationNotFound):
+		RespondError(c, http.StatusNotFound, config.ERROR_CODE_INVITATION_MESSAGE_NOT_FOUND, err.Error())
+	case errors.Is(err, config.ErrNotInvitationSender),
+		errors.Is(err, config.ErrNotInvitationReceiver):
+		RespondForbidden(c, err.Error())
+	case errors.Is(err, config.ErrInvitationPending):
+		RespondError(c, http.StatusConflict, config.ERROR_CODE_INVITATION_PENDING, err.Error())
+	case errors.Is(err, config.ErrInvitationAnswered):
+		RespondError(c, http.StatusConflict, config.ERROR_CODE_INVITATION_ANSWERED, err.Error())
+	case errors.Is(err, config.ErrInvitationExpired):
+		RespondError(c, http.StatusGone, config.ERROR_CODE_INVITATION_EXPIRED, err.Error())
+	case errors.Is(err, config.ErrInvitationRevoked):
+		RespondError(c, http.StatusGone, config.ERROR_CODE_INVITATION_REVOKED, err.Error())
+	default:
+		return false
+	}
+	return true
+}
+
 /**
  * Get all the messages that the user has sent
  * @param c - the context
@@ -311,16 +409,195 @@ func (ctrl *MessageController) GetSentMessageAPI(c *gin.Context) {
 		return
 	}
 
-	offsetStr := c.DefaultQuery("offset", "0")
-	offset, _ := strconv.Atoi(offsetStr)
-	limitStr := c.DefaultQuery("limit", "10")
-	limit, _ := strconv.Atoi(limitStr)
+	filter, err := messageFilterQuery(c)
+	if err != nil {
+		RespondBadRequest(c, err.Error())
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 
-	messages, err := ctrl.messageService.GetAllMessageFromSender(userID, offset, limit)
+	page, err := ctrl.messageService.GetAllMessagesFromSender(dto.GetAllMessagesFromSenderDTO{
+		SenderID: userID,
+		Filter:   filter,
+		Cursor:   c.Query("cursor"),
+		Limit:    limit,
+	})
 	if err != nil {
-		RespondInternalServerError(c, "internal server error")
+		respondMessageError(c, err)
+		return
+	}
+
+	setNextCursor(c, page.NextCursor)
+	c.JSON(http.StatusOK, page.Messages)
+}
+
+// SendMessageAPI handles POST /api/v2/messages: a general message to the user named by
+// receiverID or receiverEmail.
+func (ctrl *MessageController) SendMessageAPI(c *gin.Context) {
+	userID, err := utils.VerifyUserAccess(c.GetHeader("Authorization"))
+	if err != nil {
+		RespondUnauthorized(c, "unauthorized")
+		return
+	}
+
+	var request dto.SendGeneralMessageDTO
+	if err := c.ShouldBindJSON(&request); err != nil {
+		RespondBadRequest(c, "invalid request body")
+		return
+	}
+	request.SenderID = userID
+
+	messageDTO, err := ctrl.messageService.SendGeneralMessage(request)
+	if err != nil {
+		respondMessageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Message sent successfully",
+		"data":    messageDTO,
+	})
+}
+
+// GetConversationsAPI handles GET /api/v2/messages/conversations?cursor=&limit=.
+func (ctrl *MessageController) GetConversationsAPI(c *gin.Context) {
+	userID, err := utils.VerifyUserAccess(c.GetHeader("Authorization"))
+	if err != nil {
+		RespondUnauthorized(c, "unauthorized")
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	page, err := ctrl.messageService.GetConversations(dto.GetConversationsDTO{
+		UserID: userID,
+		Cursor: c.Query("cursor"),
+		Limit:  limit,
+	})
+	if err != nil {
+		respondMessageError(c, err)
+		return
+	}
+
+	setNextCursor(c, page.NextCursor)
+	c.JSON(http.StatusOK, gin.H{"conversations": page.Conversations})
+}
+
+// GetConversationMessagesAPI handles GET /api/v2/messages/conversations/:userID, the
+// general messages exchanged with that user.
+func (ctrl *MessageController) GetConversationMessagesAPI(c *gin.Context) {
+	userID, err := utils.VerifyUserAccess(c.GetHeader("Authorization"))
+	if err != nil {
+		RespondUnauthorized(c, "unauthorized")
 		return
 	}
 
-	c.JSON(http.StatusOK, messages)
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	page, err := ctrl.messageService.GetConversationMessages(dto.GetConversationMessagesDTO{
+		UserID:        userID,
+		CounterpartID: c.Param("userID"),
+		Cursor:        c.Query("cursor"),
+		Limit:         limit,
+	})
+	if err != nil {
+		respondMessageError(c, err)
+		return
+	}
+
+	setNextCursor(c, page.NextCursor)
+	c.JSON(http.StatusOK, gin.H{"messages": page.Messages})
+}
+
+// ReadConversationAPI handles POST /api/v2/messages/conversations/:userID/read.
+func (ctrl *MessageController) ReadConversationAPI(c *gin.Context) {
+	userID, err := utils.VerifyUserAccess(c.GetHeader("Authorization"))
+	if err != nil {
+		RespondUnauthorized(c, "unauthorized")
+		return
+	}
+
+	read, err := ctrl.messageService.ReadConversation(dto.ReadConversationDTO{
+		UserID:        userID,
+		CounterpartID: c.Param("userID"),
+	})
+	if err != nil {
+		respondMessageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Conversation read successfully",
+		"read":    read,
+	})
+}
+
+// UpdateConversationSettingAPI handles PATCH /api/v2/messages/conversations/:userID/settings
+// with {"blocked": bool, "muted": bool}; either may be left out.
+func (ctrl *MessageController) UpdateConversationSettingAPI(c *gin.Context) {
+	userID, err := utils.VerifyUserAccess(c.GetHeader("Authorization"))
+	if err != nil {
+		RespondUnauthorized(c, "unauthorized")
+		return
+	}
+
+	var request dto.UpdateConversationSettingDTO
+	if err := c.ShouldBindJSON(&request); err != nil {
+		RespondBadRequest(c, "invalid request body")
+		return
+	}
+	request.UserID = userID
+	request.CounterpartID = c.Param("userID")
+
+	setting, err := ctrl.messageService.UpdateConversationSetting(request)
+	if err != nil {
+		respondMessageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"setting": setting})
+}
+
+func respondMessageError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, config.ErrUserNotFound):
+		RespondNotFound(c, "user not found")
+	case errors.Is(err, config.ErrSameRecipientAndSender):
+		RespondError(c, http.StatusBadRequest, config.ERROR_CODE_SAME_RECIPIENT_AND_SENDER, err.Error())
+	case errors.Is(err, config.ErrInvalidMessage),
+		errors.Is(err, config.ErrInvalidConversationSetting),
+		errors.Is(err, config.ErrInvalidMessageFilter),
+		errors.Is(err, config.ErrInvalidCursor):
+		RespondBadRequest(c, err.Error())
+	case errors.Is(err, config.ErrMessagingBlocked):
+		RespondError(c, http.StatusForbidden, config.ERROR_CODE_MESSAGING_BLOCKED, err.Error())
+	default:
+		fmt.Println(err)
+		RespondInternalServerError(c, "internal server error")
+	}
+}
+
+// nextCursorHeader carries the cursor of a listing's next page; it is left out on the last page.
+const nextCursorHeader = "X-Next-Cursor"
+
+func setNextCursor(c *gin.Context, cursor string) {
+	if cursor != "" {
+		c.Header(nextCursorHeader, cursor)
+	}
+}
+
+// messageFilterQuery reads ?type=, ?read=true|false and ?grindID= into a filter.
+func messageFilterQuery(c *gin.Context) (dto.MessageFilterDTO, error) {
+	filter := dto.MessageFilterDTO{
+		Type:    c.Query("type"),
+		GrindID: c.Query("grindID"),
+	}
+	if readParam := c.Query("read"); readParam != "" {
+		read, err := strconv.ParseBool(readParam)
+		if err != nil {
+			return dto.MessageFilterDTO{}, fmt.Errorf("%w: read must be true or false", config.ErrInvalidMessageFilter)
+		}
+		filter.Read = &read
+	}
+	return filter, nil
 }
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
+	"github.com/daniel0321forever/terriyaki-go/internal/application/services"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// notificationHeartbeat is how often an idle stream sends a comment, so proxies keep the
+// connection open and the server notices clients that went away.
+var notificationHeartbeat = 25 * time.Second
+
+// NotificationController serves the users' real-time notification streams.
+type NotificationController struct {
+	notificationService *services.NotificationService
+	streamTicketService *services.StreamTicketService
+}
+
+// NewNotificationController creates a new NotificationController.
+func NewNotificationController(
+	notificationService *services.NotificationService,
+	streamTicketService *services.StreamTicketService,
+) *NotificationController {
+	return &NotificationController{
+		notificationService: notificationService,
+		streamTicketService: streamTicketService,
+	}
+}
+
+// IssueStreamTicketAPI handles POST /api/v2/notifications/stream-ticket. The ticket opens
+// the caller's stream once, within a few seconds.
+func (ctrl *NotificationController) IssueStreamTicketAPI(c *gin.Context) {
+	userID, err := utils.VerifyUserAccess(c.GetHeader("Authorization"))
+	if err != nil {
+		RespondUnauthorized(c, "unauthorized")
+		return
+	}
+
+	ticket, err := ctrl.streamTicketService.IssueTicket(dto.IssueStreamTicketDTO{UserID: userID})
+	if err != nil {
+		fmt.Println(err)
+		RespondInternalServerError(c, "internal server error")
+		return
+	}
+
+	c.JSON(http.StatusCreated, ticket)
+}
+
+// StreamNotificationsAPI handles GET /api/v2/notifications/stream as Server-Sent Events.
+// Browsers' EventSource cannot set headers, so instead of the Authorization header a
+// client may pass a ticket from IssueStreamTicketAPI as the ticket query parameter; the
+// token itself never goes in the URL. A reconnecting client sends the Last-Event-ID
+// header, or the lastEventId query parameter, to replay what it missed.
+func (ctrl *NotificationController) StreamNotificationsAPI(c *gin.Context) {
+	userID, err := ctrl.streamUser(c)
+	switch {
+	case errors.Is(err, config.ErrInvalidStreamTicket):
+		RespondUnauthorized(c, "unauthorized")
+		return
+	case err != nil:
+		fmt.Println(err)
+		RespondInternalServerError(c, "internal server error")
+		return
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("lastEventId")
+	}
+
+	ctx := c.Request.Context()
+	stream, err := ctrl.notificationService.Subscribe(ctx, dto.SubscribeNotificationsDTO{
+		UserID:      userID,
+		LastEventID: lastEventID,
+	})
+	switch {
+	case errors.Is(err, config.ErrInvalidLastEventID):
+		RespondBadRequest(c, err.Error())
+		return
+	case err != nil:
+		fmt.Println(err)
+		RespondInternalServerError(c, "internal server error")
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	fmt.Fprint(c.Writer, ": connected\n\n")
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(notificationHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case notification, ok := <-stream:
+			if !ok {
+				// the subscriber fell behind; the client reconnects with Last-Event-ID
+				return
+			}
+			fmt.Fprintf(c.Writer, "id: %s\nevent: %s\ndata: %s\n\n", notification.ID, notification.Type, notification.Data)
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": ping\n\n")
+		case <-ctx.Done():
+			return
+		}
+		c.Writer.Flush()
+	}
+}
+
+// streamUser authenticates a stream by its Authorization header or, failing that, its
+// ticket. Any rejected credential is ErrInvalidStreamTicket.
+func (ctrl *NotificationController) streamUser(c *gin.Context) (string, error) {
+	if token := c.GetHeader("Authorization"); token != "" {
+		userID, err := utils.VerifyUserAccess(token)
+		if err != nil {
+			return "", config.ErrInvalidStreamTicket
+		}
+		return userID, nil
+	}
+	return ctrl.streamTicketService.RedeemTicket(c.Query("ticket"))
+}
@@ -1,31 +1,36 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
 	"github.com/daniel0321forever/terriyaki-go/internal/application/services"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
 	"github.com/daniel0321forever/terriyaki-go/internal/cores/utils"
 	"github.com/gin-gonic/gin"
 )
 
 type PaymentController struct {
-	userService   *services.UserService
-	stripeService services.StripePaymentService
-	solanaService services.SolanaPaymentService
+	userService       *services.UserService
+	stripeService     services.StripePaymentService
+	solanaService     services.SolanaPaymentService
+	settlementService *services.GrindSettlementService
 }
 
 func NewPaymentController(
 	us *services.UserService,
 	stripeService services.StripePaymentService,
 	solanaService services.SolanaPaymentService,
+	settlementService *services.GrindSettlementService,
 ) *PaymentController {
 	return &PaymentController{
-		userService:   us,
-		stripeService: stripeService,
-		solanaService: solanaService,
+		userService:       us,
+		stripeService:     stripeService,
+		solanaService:     solanaService,
+		settlementService: settlementService,
 	}
 }
 
@@ -213,66 +218,49 @@ func (ctrl *PaymentController) AddPaymentMethodAPI(c *gin.Context) {
 }
 
 /*
-ForceInvestigateDuedPenaltyAPI handles the investigation of dued penalties and charges them.
+ForceInvestigateDuedPenaltyAPI settles every grind that has just ended.
 
 PAYMENT METHOD: Stripe only
 
+Each due grind goes through the settlement orchestrator: participations are frozen,
+losers are charged their penalty and the collected pot is paid out to the participants
+who completed. Settlement is resumable, so calling this again retries failed legs and
+never charges a settled leg twice.
+
 @route  POST   /api/v1/payments/stripe/force-charging
-@desc   Force investigate dued penalties and charge them via Stripe.
+@desc   Settle due grinds via Stripe and return one report per grind.
 @auth   Required
 
 Response [200]:
 
 	{
 		"message": string,
+		"settlements": [GrindSettlementReport],
+		"errors": { grindId: string },
 		"reconciled_settlements": int
 	}
 */
 func (ctrl *PaymentController) ForceInvestigateDuedPenaltyAPI(c *gin.Context) {
 	// authenticate the user
 	token := c.GetHeader("Authorization")
-	userID, err := utils.VerifyUserAccess(token)
-	if err != nil {
+	if _, err := utils.VerifyUserAccess(token); err != nil {
 		RespondUnauthorized(c, "Unauthorized")
 		return
 	}
 
-	idempotencyKey := strings.TrimSpace(c.GetHeader("Idempotency-Key"))
-	if idempotencyKey == "" {
-		RespondBadRequest(c, "Missing required Idempotency-Key header")
-		return
-	}
-
-	if ctrl.stripeService == nil {
+	if ctrl.stripeService == nil || ctrl.settlementService == nil {
 		RespondInternalServerError(c, "Stripe payment service is not configured")
 		return
 	}
 
-	// find dued payments
-	res, err := ctrl.stripeService.FindDuedPayments()
+	// settle the due grinds
+	res, err := ctrl.settlementService.SettleDueGrinds()
 	if err != nil {
 		fmt.Println(err)
 		RespondInternalServerError(c, "Internal Server Error")
 		return
 	}
 
-	// charge the pending payments
-	for i, pendingPayment := range res.PendingPayments {
-		operationKey := fmt.Sprintf("%s:%d:%s", idempotencyKey, i, pendingPayment.PaymentMethodInfo.ProviderPaymentMethodID)
-		chargeReq, err := dto.NewChargeWithIdempotencyDTO(pendingPayment.PaymentMethodInfo, pendingPayment.PaymentAmount, "force_charging", userID)
-		if err != nil {
-			fmt.Println(err)
-			RespondInternalServerError(c, "Internal Server Error")
-			return
-		}
-		_, err = ctrl.stripeService.ChargeWithIdempotency(chargeReq, operationKey)
-		if err != nil {
-			fmt.Println(err)
-			RespondInternalServerError(c, "Internal Server Error")
-			return
-		}
-	}
-
 	reconReq, err := dto.NewReconcileSettlementsDTO(100)
 	if err != nil {
 		fmt.Println(err)
@@ -288,11 +276,63 @@ func (ctrl *PaymentController) ForceInvestigateDuedPenaltyAPI(c *gin.Context) {
 
 	// return the success message
 	c.JSON(200, gin.H{
-		"message":                "Dued penalties charged successfully",
+		"message":                "Due grinds settled",
+		"settlements":            res.Reports,
+		"errors":                 res.Errors,
 		"reconciled_settlements": len(reconciled.UpdatedSettlements),
 	})
 }
 
+/*
+GetGrindSettlementAPI returns the settlement report of a grind.
+
+@route  GET   /api/v2/grinds/:id/settlement
+@desc   Get the charges and payouts of a grind's settlement. Participants only.
+@auth   Required
+
+Response [200]:
+
+	{
+		"settlement": GrindSettlementReport
+	}
+
+Possible Errors:
+- 403 Forbidden: The user is not a participant of the grind.
+- 404 Not Found: The grind does not exist.
+*/
+func (ctrl *PaymentController) GetGrindSettlementAPI(c *gin.Context) {
+	token := c.GetHeader("Authorization")
+	userID, err := utils.VerifyUserAccess(token)
+	if err != nil {
+		RespondUnauthorized(c, "Unauthorized")
+		return
+	}
+
+	if ctrl.settlementService == nil {
+		RespondInternalServerError(c, "Settlement service is not configured")
+		return
+	}
+
+	report, err := ctrl.settlementService.GetSettlementReport(dto.GetGrindSettlementDTO{
+		GrindID: c.Param("id"),
+		UserID:  userID,
+	})
+	switch {
+	case errors.Is(err, config.ErrGrindNotFound):
+		RespondNotFound(c, "grind not found")
+		return
+	case errors.Is(err, config.ErrUserIsNotParticipant):
+		RespondForbidden(c, "user is not a participant of the grind")
+		return
+	case err != nil:
+		fmt.Println(err)
+		RespondInternalServerError(c, "Internal Server Error")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"settlement": report})
+}
+
 /*
 GetAvailablePaymentMethodsAPI handles the retrieval of available payment methods.
 
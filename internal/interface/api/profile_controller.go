@@ -1,10 +1,12 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
 	"github.com/daniel0321forever/terriyaki-go/internal/application/services"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
 	"github.com/daniel0321forever/terriyaki-go/internal/cores/utils"
 	"github.com/gin-gonic/gin"
 )
@@ -21,8 +23,11 @@ func NewProfileController(us *services.UserService) *ProfileController {
 
 func (ctrl *ProfileController) UpdateProfileAPI(c *gin.Context) {
 	Request := struct {
-		Username *string `json:"username"`
-		Avatar   *string `json:"avatar"`
+		Username         *string `json:"username"`
+		Avatar           *string `json:"avatar"`
+		Timezone         *string `json:"timezone"`
+		LeetCodeUsername *string `json:"leetcodeUsername"`
+		Locale           *string `json:"locale"`
 	}{}
 
 	token := c.GetHeader("Authorization")
@@ -38,12 +43,19 @@ func (ctrl *ProfileController) UpdateProfileAPI(c *gin.Context) {
 	}
 
 	updateUserDTO := dto.UpdateUserDTO{
-		UserID:   userID,
-		Username: Request.Username,
-		Avatar:   Request.Avatar,
+		UserID:           userID,
+		Username:         Request.Username,
+		Avatar:           Request.Avatar,
+		Timezone:         Request.Timezone,
+		LeetCodeUsername: Request.LeetCodeUsername,
+		Locale:           Request.Locale,
 	}
 
 	userDTO, err := ctrl.userService.UpdateUser(updateUserDTO)
+	if errors.Is(err, config.ErrInvalidTimezone) || errors.Is(err, config.ErrInvalidLeetCodeUsername) || errors.Is(err, config.ErrInvalidLocale) {
+		RespondBadRequest(c, err.Error())
+		return
+	}
 	if err != nil {
 		RespondInternalServerError(c, err.Error())
 		return
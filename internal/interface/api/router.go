@@ -1,11 +1,20 @@
 package api
 
 import (
+	"context"
+	"os"
 	"time"
 
 	"github.com/daniel0321forever/terriyaki-go/internal/application/services"
+	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
 	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
+	"github.com/daniel0321forever/terriyaki-go/internal/infrastructure/blob"
+	"github.com/daniel0321forever/terriyaki-go/internal/infrastructure/cache"
 	"github.com/daniel0321forever/terriyaki-go/internal/infrastructure/db/postgres"
+	"github.com/daniel0321forever/terriyaki-go/internal/infrastructure/leetcode"
+	"github.com/daniel0321forever/terriyaki-go/internal/infrastructure/notification"
+	"github.com/daniel0321forever/terriyaki-go/internal/infrastructure/roadmap"
+	"github.com/daniel0321forever/terriyaki-go/internal/infrastructure/verifier"
 	"github.com/daniel0321forever/terriyaki-go/internal/interface/api/middleware"
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
@@ -17,20 +26,50 @@ func RegisterRoutes(router *gin.Engine, db *gorm.DB, rdb *redis.Client) {
 	userRepo := postgres.NewGormUserRepository(db)
 	grindRepo := postgres.NewGormGrindRepository(db)
 	participationRepo := postgres.NewGormParticipationRepository(db)
-	messageRepo := postgres.NewGormMessageRepository(db)
 	paymentInfoRepo := postgres.NewGormStripePaymentInfoRepository(db)
 	paymentIdempotencyRepo := postgres.NewGormPaymentIdempotencyRepository(db)
-	paymentSettlementRepo := postgres.NewGormPaymentSettlementRepository(db)
 	habitTaskRepo := postgres.NewGormHabitTaskRepository(db)
-	completionEventRepo := postgres.NewGormCompletionEventRepository(db)
 	partnerGroupRepo := postgres.NewGormPartnerGroupRepository(db)
+	stakeChangeRepo := postgres.NewGormStakeChangeRepository(db)
+	grindStatsRepo := postgres.NewGormGrindStatsRepository(db)
+	integrationRepo := postgres.NewGormIntegrationRepository(db)
+	deadLetterRepo := postgres.NewGormDeadLetterRepository(db)
+	disputeRepo := postgres.NewGormCompletionDisputeRepository(db)
+	roadmapRepo := roadmap.NewEmbeddedRoadmapRepository()
+
+	// Messages, completions and settlement legs written through these repositories are
+	// pushed to the users' notification streams, on every replica through Redis.
+	notificationService := services.NewNotificationService(notification.New(rdb), userRepo, grindRepo,
+		postgres.NewGormMessageRepository(db), habitTaskRepo, participationRepo, partnerGroupRepo)
+	messageRepo := notificationService.MessageRepository(postgres.NewGormMessageRepository(db))
+	completionEventRepo := notificationService.CompletionEventRepository(postgres.NewGormCompletionEventRepository(db))
+	paymentSettlementRepo := notificationService.PaymentSettlementRepository(postgres.NewGormPaymentSettlementRepository(db))
+	streamTicketService := services.NewStreamTicketService(notification.NewTicketStore(rdb))
 
 	// Initialize services
-	userService := services.NewUserService(userRepo)
-	grindService := services.NewGrindService(db, grindRepo, userRepo, habitTaskRepo, participationRepo, messageRepo)
+	userService := services.NewUserService(db, userRepo, habitTaskRepo)
+	grindService := services.NewGrindService(db, grindRepo, userRepo, habitTaskRepo, participationRepo, messageRepo, stakeChangeRepo, roadmapRepo)
 	messageService := services.NewMessageService(db, messageRepo, userRepo, grindRepo)
-	ingestService := services.NewIngestService(habitTaskRepo, completionEventRepo)
+	blobStore, err := blob.NewFilesystemStore(os.Getenv(config.EVIDENCE_DIR))
+	if err != nil {
+		panic(err)
+	}
+	completionVerifier, err := verifier.FromEnv(blobStore)
+	if err != nil {
+		panic(err)
+	}
+	evidenceService := services.NewEvidenceService(blobStore, completionEventRepo, habitTaskRepo, participationRepo, partnerGroupRepo)
+	disputeService := services.NewDisputeService(db, grindRepo, userRepo, habitTaskRepo, participationRepo, partnerGroupRepo,
+		completionEventRepo, disputeRepo, messageRepo)
+	// Settle disputes whose voting window ended in the background; the outcome messages go
+	// through the notifying message repository like every other dispute message.
+	go disputeService.Run(context.Background())
+	ingestService := services.NewIngestService(db, userRepo, habitTaskRepo, completionEventRepo, deadLetterRepo,
+		leetcode.NewGraphQLClient(os.Getenv(config.LEETCODE_GRAPHQL_URL), nil), completionVerifier, disputeService)
 	partnerGroupService := services.NewPartnerGroupService(partnerGroupRepo)
+	integrationService := services.NewIntegrationService(integrationRepo, partnerGroupRepo)
+	deadLetterService := services.NewDeadLetterService(deadLetterRepo, integrationRepo, ingestService, evidenceService)
+	leaderboardService := services.NewLeaderboardService(grindRepo, participationRepo, grindStatsRepo, cache.NewRedisCache(rdb, "leaderboard"))
 	paymentFactory := services.NewPaymentServiceFactory(
 		userRepo,
 		grindRepo,
@@ -55,15 +94,33 @@ func RegisterRoutes(router *gin.Engine, db *gorm.DB, rdb *redis.Client) {
 		panic(err)
 	}
 
+	// Grind settlements charge and pay out through the Stripe rail, like force-charging.
+	settlementService := services.NewGrindSettlementService(
+		db,
+		grindRepo,
+		userRepo,
+		participationRepo,
+		habitTaskRepo,
+		paymentInfoRepo,
+		paymentSettlementRepo,
+		stripePaymentService,
+	)
+
 	// Initialize API handlers with services
 	grindCtrl := NewGrindController(grindService, userService, messageService)
 	userCtrl := NewUserController(grindService, userService)
 	healthCtrl := NewHealthController(db, rdb)
 	messageCtrl := NewMessageController(userService, messageService, grindService)
-	paymentCtrl := NewPaymentController(userService, stripePaymentService, solanaPaymentService)
+	paymentCtrl := NewPaymentController(userService, stripePaymentService, solanaPaymentService, settlementService)
 	profileCtrl := NewProfileController(userService)
-	ingestCtrl := NewIngestController(ingestService)
+	ingestCtrl := NewIngestController(ingestService, integrationService, evidenceService)
+	evidenceCtrl := NewEvidenceController(evidenceService)
+	disputeCtrl := NewDisputeController(disputeService)
+	integrationCtrl := NewIntegrationController(integrationService)
+	deadLetterCtrl := NewDeadLetterController(deadLetterService)
 	partnerGroupCtrl := NewPartnerGroupController(partnerGroupService)
+	leaderboardCtrl := NewLeaderboardController(leaderboardService)
+	notificationCtrl := NewNotificationController(notificationService, streamTicketService)
 
 	// Rate limit middleware: 10 requests per minute per IP (SEC-03)
 	// Fail-open: Redis error allows request through (T-03-06 mitigated).
@@ -83,7 +140,14 @@ func RegisterRoutes(router *gin.Engine, db *gorm.DB, rdb *redis.Client) {
 		v2.GET("grinds", grindCtrl.GetAllUserGrindsAPI)
 		v2.GET("grinds/current", grindCtrl.GetUserCurrentGrindAPI) // static BEFORE grinds/:id
 		v2.GET("grinds/:id", grindCtrl.GetGrindAPI)
+		v2.PATCH("grinds/:id", grindCtrl.UpdateGrindAPI)
 		v2.POST("grinds/:id/quit", grindCtrl.QuitGrindAPI)
+		v2.POST("grinds/:id/stake-changes/:changeId/approve", grindCtrl.ApproveStakeChangeAPI)
+		v2.POST("grinds/:id/stake-changes/:changeId/reject", grindCtrl.RejectStakeChangeAPI)
+		v2.POST("grinds/:id/publish", grindCtrl.PublishGrindAPI)
+		v2.POST("grinds/:id/cancel", grindCtrl.CancelGrindAPI)
+		v2.GET("grinds/:id/settlement", paymentCtrl.GetGrindSettlementAPI)
+		v2.GET("grinds/:id/leaderboard", leaderboardCtrl.GetLeaderboardAPI)
 
 		// User routes — register rate limited (T-03-05)
 		v2.POST("register", rl, userCtrl.RegisterAPI)
@@ -106,12 +170,39 @@ func RegisterRoutes(router *gin.Engine, db *gorm.DB, rdb *redis.Client) {
 		v2.GET("messages", messageCtrl.GetMessageAPI)
 		v2.POST("messages/invitation", messageCtrl.CreateInvitationAPI) // static BEFORE messages/:id
 		v2.GET("messages/sent", messageCtrl.GetSentMessageAPI)          // static BEFORE messages/:id
+		v2.GET("messages/unread-count", messageCtrl.GetUnreadMessageCountAPI)
+		v2.POST("messages", messageCtrl.SendMessageAPI)
+		v2.GET("messages/conversations", messageCtrl.GetConversationsAPI)
+		v2.GET("messages/conversations/:userID", messageCtrl.GetConversationMessagesAPI)
+		v2.POST("messages/conversations/:userID/read", messageCtrl.ReadConversationAPI)
+		v2.PATCH("messages/conversations/:userID/settings", messageCtrl.UpdateConversationSettingAPI)
 		v2.POST("messages/:id/invitation/accept", messageCtrl.AcceptInvitationAPI)
 		v2.POST("messages/:id/invitation/reject", messageCtrl.RejectInvitationAPI)
+		v2.POST("messages/:id/invitation/revoke", messageCtrl.RevokeInvitationAPI)
 		v2.POST("messages/:id/read", messageCtrl.ReadMessageAPI)
 
+		// Real-time notifications (Server-Sent Events)
+		v2.POST("notifications/stream-ticket", notificationCtrl.IssueStreamTicketAPI)
+		v2.GET("notifications/stream", notificationCtrl.StreamNotificationsAPI)
+
 		// Ingest — rate limited (T-03-05)
 		v2.POST("ingest/:provider", rl, ingestCtrl.HandleIngest)
+		v2.GET("completion-events/:id/evidence", evidenceCtrl.GetEvidenceAPI)
+
+		// Peer review of completions
+		v2.POST("completion-events/:id/disputes", disputeCtrl.FlagCompletionAPI)
+		v2.POST("disputes/:id/votes", disputeCtrl.VoteOnDisputeAPI)
+		v2.GET("grinds/:id/disputes", disputeCtrl.GetGrindDisputesAPI)
+
+		// Custom provider integrations
+		v2.POST("integrations", integrationCtrl.CreateIntegrationAPI)
+		v2.GET("integrations", integrationCtrl.GetIntegrationsAPI)
+		v2.DELETE("integrations/:id", integrationCtrl.DeleteIntegrationAPI)
+
+		// Completion signals ingest could not record
+		v2.GET("dead-letters", deadLetterCtrl.GetDeadLettersAPI)
+		v2.POST("dead-letters/:id/redrive", deadLetterCtrl.RedriveDeadLetterAPI)
+		v2.DELETE("dead-letters/:id", deadLetterCtrl.DiscardDeadLetterAPI)
 
 		// Partner groups — register static POST groups/join BEFORE dynamic GET groups/:id
 		v2.POST("groups", partnerGroupCtrl.CreateGroupAPI)
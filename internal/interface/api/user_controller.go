@@ -3,7 +3,6 @@ package api
 import (
 	"fmt"
 	"net/http"
-	"sort"
 	"strings"
 
 	"github.com/daniel0321forever/terriyaki-go/internal/application/dto"
@@ -28,21 +27,6 @@ func NewUserController(
 	}
 }
 
-func sortedGroupGrindList(grindMap map[string]*dto.GroupGrindDTO) []*dto.GroupGrindDTO {
-	ids := make([]string, 0, len(grindMap))
-	for id := range grindMap {
-		ids = append(ids, id)
-	}
-	sort.Strings(ids)
-
-	grinds := make([]*dto.GroupGrindDTO, 0, len(ids))
-	for _, id := range ids {
-		grinds = append(grinds, grindMap[id])
-	}
-
-	return grinds
-}
-
 func (ctrl *UserController) RegisterAPI(c *gin.Context) {
 	var body map[string]string
 	if err := c.ShouldBindJSON(&body); err != nil {
@@ -186,7 +170,7 @@ func (ctrl *UserController) LoginAPIV2(c *gin.Context) {
 	getGrindsDTO := dto.GetAllUserGrindsDTO{
 		UserID: userDTO.ID,
 	}
-	grindsMap, err := ctrl.grindService.GetAllUserGrinds(getGrindsDTO)
+	grindPage, err := ctrl.grindService.GetAllUserGrinds(getGrindsDTO)
 	grinds := make([]*dto.GroupGrindDTO, 0)
 
 	if err != nil {
@@ -196,7 +180,7 @@ func (ctrl *UserController) LoginAPIV2(c *gin.Context) {
 			return
 		}
 	} else {
-		grinds = sortedGroupGrindList(grindsMap)
+		grinds = grindPage.Grinds
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -267,7 +251,7 @@ func (ctrl *UserController) VerifyTokenAPIV2(c *gin.Context) {
 		UserID: userDTO.ID,
 	}
 
-	grindsMap, err := ctrl.grindService.GetAllUserGrinds(getGrindsDTO)
+	grindPage, err := ctrl.grindService.GetAllUserGrinds(getGrindsDTO)
 	grinds := make([]*dto.GroupGrindDTO, 0)
 
 	if err != nil {
@@ -277,7 +261,7 @@ func (ctrl *UserController) VerifyTokenAPIV2(c *gin.Context) {
 			return
 		}
 	} else {
-		grinds = sortedGroupGrindList(grindsMap)
+		grinds = grindPage.Grinds
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -11,6 +11,7 @@ import (
 
 	"github.com/daniel0321forever/terriyaki-go/internal/application/services"
 	"github.com/daniel0321forever/terriyaki-go/internal/cores/config"
+	"github.com/daniel0321forever/terriyaki-go/internal/domain/entities"
 	"github.com/daniel0321forever/terriyaki-go/internal/domain/repositories"
 	mcpgo "github.com/mark3labs/mcp-go/mcp"
 	mcpserver "github.com/mark3labs/mcp-go/server"
@@ -19,23 +20,39 @@ import (
 // HandleIngestCompletionEvent returns a ToolHandlerFunc wired to the real IngestService.
 // It extracts provider, userID, grindID, and payload from the MCP tool call arguments,
 // calls svc.Ingest, and returns the persisted CompletionEvent as JSON text.
+// Custom completions name an integration instead of signing the payload: the MCP server
+// runs locally and is trusted like the API server itself.
 //
 // Error handling:
 //   - ErrHabitTaskNotFound  → mcpgo.NewToolResultError (domain error, not a Go error)
+//   - ErrIngestWindowClosed → mcpgo.NewToolResultError with the reason
+//   - ErrInvalidPayload     → error result whose structured content lists the invalid fields
 //   - other errors           → mcpgo.NewToolResultError with wrapped message
-func HandleIngestCompletionEvent(svc *services.IngestService) mcpserver.ToolHandlerFunc {
+func HandleIngestCompletionEvent(svc *services.IngestService, integrations *services.IntegrationService) mcpserver.ToolHandlerFunc {
 	return func(ctx context.Context, req mcpgo.CallToolRequest) (*mcpgo.CallToolResult, error) {
 		args := req.GetArguments()
 		provider, _ := args["provider"].(string)
 		userID, _ := args["userID"].(string)
 		grindID, _ := args["grindID"].(string)
 		rawPayload, _ := args["payload"].(map[string]interface{})
+		integrationID, _ := args["integrationID"].(string)
 
-		event, err := svc.Ingest(provider, userID, grindID, rawPayload)
+		var event *entities.CompletionEvent
+		var err error
+		if provider == string(entities.ProviderCustom) {
+			event, err = ingestCustom(svc, integrations, integrationID, userID, grindID, rawPayload)
+		} else {
+			event, _, err = svc.Ingest(provider, userID, grindID, rawPayload, nil)
+		}
 		if err != nil {
+			var invalid *services.PayloadValidationError
 			switch {
+			case errors.As(err, &invalid):
+				return invalidPayloadResult(invalid), nil
 			case errors.Is(err, config.ErrHabitTaskNotFound):
-				return mcpgo.NewToolResultError("no habit task found for today"), nil
+				return mcpgo.NewToolResultError("no habit task found for the day the completion occurred on"), nil
+			case errors.Is(err, config.ErrIngestWindowClosed):
+				return mcpgo.NewToolResultError(err.Error()), nil
 			default:
 				return mcpgo.NewToolResultError(fmt.Sprintf("ingestion failed: %v", err)), nil
 			}
@@ -46,6 +63,39 @@ func HandleIngestCompletionEvent(svc *services.IngestService) mcpserver.ToolHand
 	}
 }
 
+// invalidPayloadResult reports a payload that does not match its provider's schema with
+// the same fields POST /api/v2/ingest/:provider answers with.
+func invalidPayloadResult(invalid *services.PayloadValidationError) *mcpgo.CallToolResult {
+	result := mcpgo.NewToolResultStructured(map[string]interface{}{
+		"errorCode": config.ERROR_CODE_INVALID_PAYLOAD,
+		"message":   fmt.Sprintf("the %s payload does not match its schema", invalid.Provider),
+		"fields":    invalid.Fields,
+	}, invalid.Error())
+	result.IsError = true
+	return result
+}
+
+func ingestCustom(
+	svc *services.IngestService,
+	integrations *services.IntegrationService,
+	integrationID, userID, grindID string,
+	rawPayload map[string]interface{},
+) (*entities.CompletionEvent, error) {
+	if integrationID == "" {
+		return nil, config.ErrIntegrationRequired
+	}
+	integration, err := integrations.FindIntegration(integrationID)
+	if err != nil {
+		return nil, err
+	}
+	userID, err = integrations.ResolveUser(integration, userID)
+	if err != nil {
+		return nil, err
+	}
+	event, _, err := svc.IngestCustom(integration, userID, grindID, rawPayload, nil)
+	return event, err
+}
+
 // HandleVerifyHabitTask returns a ToolHandlerFunc that checks whether a given
 // CompletionEvent (by completionEventID) exists among the events associated with
 // a HabitTask (by habitTaskID).
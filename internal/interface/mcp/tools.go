@@ -24,9 +24,12 @@ var IngestCompletionEventTool = mcpgo.NewTool("ingest_completion_event",
 		mcpgo.Required(),
 	),
 	mcpgo.WithObject("payload",
-		mcpgo.Description("Provider-specific completion payload data"),
+		mcpgo.Description("Provider-specific completion payload data; it must match the provider's JSON Schema (LeetCodePayload, DuolingoPayload or CustomPayload in openapi.yaml)"),
 		mcpgo.Required(),
 	),
+	mcpgo.WithString("integrationID",
+		mcpgo.Description("custom only: the integration whose field mapping reads the payload; it must be allowed to ingest for userID"),
+	),
 )
 
 // VerifyHabitTaskTool defines the schema for verifying a completion event against
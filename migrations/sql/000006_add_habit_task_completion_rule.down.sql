@@ -0,0 +1 @@
+ALTER TABLE habit_tasks DROP COLUMN IF EXISTS required_events;
@@ -0,0 +1,3 @@
+-- Number of completion events required before a habit task counts as completed.
+-- Existing rows keep the "first event wins" behaviour.
+ALTER TABLE habit_tasks ADD COLUMN IF NOT EXISTS required_events INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_habit_tasks_grind_id_date;
+ALTER TABLE habit_tasks DROP COLUMN IF EXISTS missed;
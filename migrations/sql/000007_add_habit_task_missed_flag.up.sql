@@ -0,0 +1,5 @@
+-- Set by the daily missed-day evaluator. The flag doubles as the idempotency marker:
+-- a task is only ever counted against Participation.missed_days once.
+ALTER TABLE habit_tasks ADD COLUMN IF NOT EXISTS missed BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_habit_tasks_grind_id_date ON habit_tasks (grind_id, date);
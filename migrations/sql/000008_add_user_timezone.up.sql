@@ -0,0 +1,5 @@
+-- IANA timezone that defines the user's habit day boundaries.
+-- Existing habit_tasks are dated at UTC midnight, which matches the 'UTC' default,
+-- so no task data needs to move here. Later timezone changes rebase the user's tasks
+-- in the application (HabitTaskRepository.RebaseDates).
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
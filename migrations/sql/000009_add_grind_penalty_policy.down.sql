@@ -0,0 +1,8 @@
+ALTER TABLE participation DROP COLUMN IF EXISTS frozen_days;
+
+ALTER TABLE grinds DROP COLUMN IF EXISTS penalty_cap_at_budget;
+ALTER TABLE grinds DROP COLUMN IF EXISTS penalty_freeze_tokens;
+ALTER TABLE grinds DROP COLUMN IF EXISTS penalty_grace_days;
+ALTER TABLE grinds DROP COLUMN IF EXISTS penalty_increment;
+ALTER TABLE grinds DROP COLUMN IF EXISTS penalty_amount;
+ALTER TABLE grinds DROP COLUMN IF EXISTS penalty_kind;
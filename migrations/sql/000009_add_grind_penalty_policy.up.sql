@@ -0,0 +1,13 @@
+-- Per-grind penalty policy (see entities.PenaltyPolicy), stored as penalty_* columns.
+ALTER TABLE grinds ADD COLUMN IF NOT EXISTS penalty_kind VARCHAR(32) NOT NULL DEFAULT 'flat';
+ALTER TABLE grinds ADD COLUMN IF NOT EXISTS penalty_amount INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE grinds ADD COLUMN IF NOT EXISTS penalty_increment INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE grinds ADD COLUMN IF NOT EXISTS penalty_grace_days INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE grinds ADD COLUMN IF NOT EXISTS penalty_freeze_tokens INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE grinds ADD COLUMN IF NOT EXISTS penalty_cap_at_budget BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- Existing grinds get the default policy: the budget spread evenly over the duration.
+UPDATE grinds SET penalty_amount = budget / duration WHERE penalty_amount = 0 AND duration > 0;
+
+-- Missed days covered by a streak-freeze token.
+ALTER TABLE participation ADD COLUMN IF NOT EXISTS frozen_days INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_payment_settlements_grind_id;
+ALTER TABLE payment_settlements DROP COLUMN IF EXISTS grind_id;
+
+ALTER TABLE participation DROP COLUMN IF EXISTS finalized_at;
+ALTER TABLE participation DROP COLUMN IF EXISTS finalized;
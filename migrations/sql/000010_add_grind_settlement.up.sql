@@ -0,0 +1,7 @@
+-- A participation is frozen once its grind has been settled.
+ALTER TABLE participation ADD COLUMN IF NOT EXISTS finalized BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE participation ADD COLUMN IF NOT EXISTS finalized_at TIMESTAMPTZ;
+
+-- Links the charge and payout legs of an end-of-grind settlement to their grind.
+ALTER TABLE payment_settlements ADD COLUMN IF NOT EXISTS grind_id TEXT;
+CREATE INDEX IF NOT EXISTS idx_payment_settlements_grind_id ON payment_settlements (grind_id);
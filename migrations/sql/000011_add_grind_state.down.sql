@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_grinds_state;
+ALTER TABLE grinds DROP COLUMN IF EXISTS cancelled_at;
+ALTER TABLE grinds DROP COLUMN IF EXISTS settled_at;
+ALTER TABLE grinds DROP COLUMN IF EXISTS settling_at;
+ALTER TABLE grinds DROP COLUMN IF EXISTS activated_at;
+ALTER TABLE grinds DROP COLUMN IF EXISTS published_at;
+ALTER TABLE grinds DROP COLUMN IF EXISTS state;
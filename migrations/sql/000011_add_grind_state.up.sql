@@ -0,0 +1,17 @@
+-- Explicit grind lifecycle: draft -> recruiting -> active -> settling -> settled, or cancelled before it starts.
+ALTER TABLE grinds ADD COLUMN IF NOT EXISTS state TEXT NOT NULL DEFAULT 'draft';
+ALTER TABLE grinds ADD COLUMN IF NOT EXISTS published_at TIMESTAMPTZ;
+ALTER TABLE grinds ADD COLUMN IF NOT EXISTS activated_at TIMESTAMPTZ;
+ALTER TABLE grinds ADD COLUMN IF NOT EXISTS settling_at TIMESTAMPTZ;
+ALTER TABLE grinds ADD COLUMN IF NOT EXISTS settled_at TIMESTAMPTZ;
+ALTER TABLE grinds ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS idx_grinds_state ON grinds (state);
+
+-- Existing grinds were all open to invitations. Grinds that ended before yesterday were
+-- never picked up by the end-of-grind settlement, so they are closed out as settled.
+UPDATE grinds SET published_at = created_at, state = 'recruiting'
+    WHERE state = 'draft' AND (start_date AT TIME ZONE 'UTC')::date > CURRENT_DATE;
+UPDATE grinds SET published_at = created_at, activated_at = start_date, state = 'active'
+    WHERE state = 'draft' AND (start_date AT TIME ZONE 'UTC')::date + duration >= CURRENT_DATE - 1;
+UPDATE grinds SET published_at = created_at, activated_at = start_date, settled_at = NOW(), state = 'settled'
+    WHERE state = 'draft';
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS grind_stake_changes;
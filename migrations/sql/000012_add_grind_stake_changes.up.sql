@@ -0,0 +1,23 @@
+-- Proposed budget / penalty policy changes; applied once every active participant has approved.
+CREATE TABLE IF NOT EXISTS grind_stake_changes (
+    id TEXT PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ,
+    grind_id TEXT NOT NULL,
+    proposer_id TEXT NOT NULL,
+    budget INTEGER NOT NULL,
+    penalty_kind TEXT NOT NULL DEFAULT 'flat',
+    penalty_amount INTEGER NOT NULL DEFAULT 0,
+    penalty_increment INTEGER NOT NULL DEFAULT 0,
+    penalty_grace_days INTEGER NOT NULL DEFAULT 0,
+    penalty_freeze_tokens INTEGER NOT NULL DEFAULT 0,
+    penalty_cap_at_budget BOOLEAN NOT NULL DEFAULT TRUE,
+    approved_by JSONB,
+    rejected_by TEXT,
+    status TEXT NOT NULL,
+    CONSTRAINT fk_grind_stake_changes_grind FOREIGN KEY (grind_id) REFERENCES grinds (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_grind_stake_changes_deleted_at ON grind_stake_changes (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_grind_stake_changes_grind_id ON grind_stake_changes (grind_id);
@@ -0,0 +1,5 @@
+ALTER TABLE habit_tasks DROP COLUMN IF EXISTS excused;
+
+ALTER TABLE grinds DROP COLUMN IF EXISTS schedule_dates;
+ALTER TABLE grinds DROP COLUMN IF EXISTS schedule_times_per_week;
+ALTER TABLE grinds DROP COLUMN IF EXISTS schedule_kind;
@@ -0,0 +1,7 @@
+-- Grind schedules: which days inside start_date + duration are habit days.
+ALTER TABLE grinds ADD COLUMN IF NOT EXISTS schedule_kind TEXT NOT NULL DEFAULT 'daily';
+ALTER TABLE grinds ADD COLUMN IF NOT EXISTS schedule_times_per_week INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE grinds ADD COLUMN IF NOT EXISTS schedule_dates JSONB;
+
+-- Weekly-target days beyond the week's target are closed without a penalty.
+ALTER TABLE habit_tasks ADD COLUMN IF NOT EXISTS excused BOOLEAN NOT NULL DEFAULT FALSE;
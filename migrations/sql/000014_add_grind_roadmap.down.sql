@@ -0,0 +1,4 @@
+ALTER TABLE grinds DROP COLUMN IF EXISTS roadmap_slugs;
+ALTER TABLE grinds DROP COLUMN IF EXISTS roadmap_tags;
+ALTER TABLE grinds DROP COLUMN IF EXISTS roadmap_order;
+ALTER TABLE grinds DROP COLUMN IF EXISTS roadmap_name;
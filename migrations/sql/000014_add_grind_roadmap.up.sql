@@ -0,0 +1,5 @@
+-- Roadmap grinds assign every habit day a problem of a LeetCode study plan.
+ALTER TABLE grinds ADD COLUMN IF NOT EXISTS roadmap_name TEXT NOT NULL DEFAULT '';
+ALTER TABLE grinds ADD COLUMN IF NOT EXISTS roadmap_order TEXT NOT NULL DEFAULT '';
+ALTER TABLE grinds ADD COLUMN IF NOT EXISTS roadmap_tags JSONB;
+ALTER TABLE grinds ADD COLUMN IF NOT EXISTS roadmap_slugs JSONB;
@@ -0,0 +1,2 @@
+-- The public LeetCode profile whose accepted submissions verify leetcode completions.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS leetcode_username TEXT NOT NULL DEFAULT '';
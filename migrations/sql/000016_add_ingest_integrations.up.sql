@@ -0,0 +1,17 @@
+-- Custom provider integrations: a shared HMAC secret and where payloads keep occurredAt and the evidence.
+CREATE TABLE IF NOT EXISTS ingest_integrations (
+    id TEXT PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ,
+    name TEXT NOT NULL,
+    owner_id TEXT NOT NULL,
+    group_id TEXT NOT NULL DEFAULT '',
+    secret TEXT NOT NULL,
+    occurred_at_field TEXT NOT NULL DEFAULT '',
+    evidence_field TEXT NOT NULL DEFAULT '',
+    CONSTRAINT fk_ingest_integrations_owner FOREIGN KEY (owner_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_ingest_integrations_deleted_at ON ingest_integrations (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_ingest_integrations_owner_id ON ingest_integrations (owner_id);
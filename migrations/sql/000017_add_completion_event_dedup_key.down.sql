@@ -0,0 +1,3 @@
+ALTER TABLE ingest_integrations DROP COLUMN IF EXISTS event_id_field;
+DROP INDEX IF EXISTS idx_completion_events_user_provider_dedup_key;
+ALTER TABLE completion_events DROP COLUMN IF EXISTS dedup_key;
@@ -0,0 +1,10 @@
+-- Identifies a completion signal at its source (provider event ID or payload hash) so retries are recorded once.
+ALTER TABLE completion_events ADD COLUMN IF NOT EXISTS dedup_key TEXT NOT NULL DEFAULT '';
+
+-- Events recorded before dedup keys existed keep an empty key and stay out of the index.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_completion_events_user_provider_dedup_key
+    ON completion_events (user_id, provider, dedup_key)
+    WHERE dedup_key <> '';
+
+-- Where custom integrations keep the sender's event ID.
+ALTER TABLE ingest_integrations ADD COLUMN IF NOT EXISTS event_id_field TEXT NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+ALTER TABLE completion_events DROP COLUMN IF EXISTS late_by_seconds;
@@ -0,0 +1,2 @@
+-- How long after the end of its day (in the user's timezone) a completion was ingested.
+ALTER TABLE completion_events ADD COLUMN IF NOT EXISTS late_by_seconds BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,18 @@
+-- Completion signals ingest could not record, kept with the reason so they can be re-driven or discarded.
+CREATE TABLE IF NOT EXISTS ingest_dead_letters (
+    id TEXT PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ,
+    user_id TEXT NOT NULL,
+    provider TEXT NOT NULL,
+    integration_id TEXT NOT NULL DEFAULT '',
+    grind_id TEXT NOT NULL DEFAULT '',
+    payload TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    CONSTRAINT fk_ingest_dead_letters_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_ingest_dead_letters_deleted_at ON ingest_dead_letters (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_ingest_dead_letters_user_id ON ingest_dead_letters (user_id, created_at DESC);
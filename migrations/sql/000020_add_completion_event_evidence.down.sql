@@ -0,0 +1,5 @@
+ALTER TABLE completion_events DROP COLUMN IF EXISTS evidence_sha256;
+ALTER TABLE completion_events DROP COLUMN IF EXISTS evidence_size;
+ALTER TABLE completion_events DROP COLUMN IF EXISTS evidence_content_type;
+ALTER TABLE completion_events DROP COLUMN IF EXISTS evidence_name;
+ALTER TABLE completion_events DROP COLUMN IF EXISTS evidence_key;
@@ -0,0 +1,6 @@
+-- A file attached to a completion as proof; the content lives in the blob store under evidence_key.
+ALTER TABLE completion_events ADD COLUMN IF NOT EXISTS evidence_key TEXT NOT NULL DEFAULT '';
+ALTER TABLE completion_events ADD COLUMN IF NOT EXISTS evidence_name TEXT NOT NULL DEFAULT '';
+ALTER TABLE completion_events ADD COLUMN IF NOT EXISTS evidence_content_type TEXT NOT NULL DEFAULT '';
+ALTER TABLE completion_events ADD COLUMN IF NOT EXISTS evidence_size BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE completion_events ADD COLUMN IF NOT EXISTS evidence_sha256 TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,25 @@
+-- Partners' challenges of completion events, decided by a quorum of the grind's reviewers.
+CREATE TABLE IF NOT EXISTS completion_disputes (
+    id TEXT PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ,
+    completion_event_id TEXT NOT NULL,
+    habit_task_id TEXT NOT NULL,
+    grind_id TEXT NOT NULL,
+    subject_id TEXT NOT NULL,
+    flagged_by TEXT NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    quorum INTEGER NOT NULL,
+    revert_votes JSONB,
+    uphold_votes JSONB,
+    status TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    CONSTRAINT fk_completion_disputes_event FOREIGN KEY (completion_event_id) REFERENCES completion_events (id) ON DELETE CASCADE,
+    CONSTRAINT fk_completion_disputes_grind FOREIGN KEY (grind_id) REFERENCES grinds (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_completion_disputes_deleted_at ON completion_disputes (deleted_at);
+-- An event is disputed at most once.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_completion_disputes_completion_event_id ON completion_disputes (completion_event_id);
+CREATE INDEX IF NOT EXISTS idx_completion_disputes_grind_id ON completion_disputes (grind_id, created_at DESC);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_message_general_receiver;
+DROP INDEX IF EXISTS idx_message_general_sender;
+DROP TABLE IF EXISTS conversation_settings;
@@ -0,0 +1,20 @@
+-- What each user chose for their conversation with another user.
+CREATE TABLE IF NOT EXISTS conversation_settings (
+    id TEXT PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ,
+    user_id TEXT NOT NULL,
+    counterpart_id TEXT NOT NULL,
+    blocked BOOLEAN NOT NULL DEFAULT FALSE,
+    muted BOOLEAN NOT NULL DEFAULT FALSE,
+    CONSTRAINT fk_conversation_settings_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_conversation_settings_counterpart FOREIGN KEY (counterpart_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_conversation_settings_deleted_at ON conversation_settings (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_conversation_settings_user_counterpart ON conversation_settings (user_id, counterpart_id);
+
+-- Threads of general messages are read by either participant.
+CREATE INDEX IF NOT EXISTS idx_message_general_sender ON message (sender_id, receiver_id, created_at DESC) WHERE type = 'general';
+CREATE INDEX IF NOT EXISTS idx_message_general_receiver ON message (receiver_id, sender_id, created_at DESC) WHERE type = 'general';
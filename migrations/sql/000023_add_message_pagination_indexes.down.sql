@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_message_sender_page;
+DROP INDEX IF EXISTS idx_message_receiver_page;
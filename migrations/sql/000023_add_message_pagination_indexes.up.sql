@@ -0,0 +1,3 @@
+-- Inboxes and outboxes are paged newest first by (created_at, id).
+CREATE INDEX IF NOT EXISTS idx_message_receiver_page ON message (receiver_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_message_sender_page ON message (sender_id, created_at DESC, id DESC);
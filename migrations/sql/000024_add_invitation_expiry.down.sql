@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_message_open_invitation;
+ALTER TABLE message DROP COLUMN IF EXISTS invitation_revoked_at;
+ALTER TABLE message DROP COLUMN IF EXISTS invitation_expires_at;
@@ -0,0 +1,26 @@
+-- Invitations expire and can be revoked by their sender.
+ALTER TABLE message ADD COLUMN IF NOT EXISTS invitation_expires_at TIMESTAMPTZ;
+ALTER TABLE message ADD COLUMN IF NOT EXISTS invitation_revoked_at TIMESTAMPTZ;
+
+UPDATE message
+SET invitation_expires_at = created_at + INTERVAL '7 days'
+WHERE type = 'invitation' AND invitation_expires_at IS NULL;
+
+-- Keep only the newest open invitation of each receiver to each grind.
+UPDATE message m
+SET invitation_revoked_at = NOW()
+WHERE m.type = 'invitation'
+    AND m.invitation_accepted IS NOT TRUE AND m.invitation_rejected IS NOT TRUE AND m.invitation_revoked_at IS NULL AND m.deleted_at IS NULL
+    AND EXISTS (
+        SELECT 1 FROM message newer
+        WHERE newer.type = 'invitation'
+            AND newer.invitation_grind_id = m.invitation_grind_id
+            AND newer.receiver_id = m.receiver_id
+            AND newer.invitation_accepted IS NOT TRUE AND newer.invitation_rejected IS NOT TRUE AND newer.invitation_revoked_at IS NULL
+            AND newer.deleted_at IS NULL
+            AND (newer.created_at, newer.id) > (m.created_at, m.id)
+    );
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_message_open_invitation ON message (invitation_grind_id, receiver_id)
+    WHERE type = 'invitation' AND invitation_accepted IS NOT TRUE AND invitation_rejected IS NOT TRUE
+        AND invitation_revoked_at IS NULL AND deleted_at IS NULL;
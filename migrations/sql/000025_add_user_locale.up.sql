@@ -0,0 +1,2 @@
+-- BCP 47 locale that system messages sent to the user are rendered in.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locale VARCHAR(35) NOT NULL DEFAULT 'en';
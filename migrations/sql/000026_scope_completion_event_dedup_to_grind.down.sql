@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_completion_events_user_grind_provider_dedup_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_completion_events_user_provider_dedup_key
+    ON completion_events (user_id, provider, dedup_key)
+    WHERE dedup_key <> '';
+ALTER TABLE completion_events DROP COLUMN IF EXISTS grind_id;
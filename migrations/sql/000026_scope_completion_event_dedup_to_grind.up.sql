@@ -0,0 +1,12 @@
+-- A signal may count for a task in each of the user's grinds, so dedup keys are unique per grind.
+ALTER TABLE completion_events ADD COLUMN IF NOT EXISTS grind_id TEXT NOT NULL DEFAULT '';
+
+UPDATE completion_events
+SET grind_id = habit_tasks.grind_id
+FROM habit_tasks
+WHERE habit_tasks.id = completion_events.habit_task_id AND completion_events.grind_id = '';
+
+DROP INDEX IF EXISTS idx_completion_events_user_provider_dedup_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_completion_events_user_grind_provider_dedup_key
+    ON completion_events (user_id, grind_id, provider, dedup_key)
+    WHERE dedup_key <> '';
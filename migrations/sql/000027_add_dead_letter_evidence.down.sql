@@ -0,0 +1,5 @@
+ALTER TABLE ingest_dead_letters DROP COLUMN IF EXISTS evidence_sha256;
+ALTER TABLE ingest_dead_letters DROP COLUMN IF EXISTS evidence_size;
+ALTER TABLE ingest_dead_letters DROP COLUMN IF EXISTS evidence_content_type;
+ALTER TABLE ingest_dead_letters DROP COLUMN IF EXISTS evidence_name;
+ALTER TABLE ingest_dead_letters DROP COLUMN IF EXISTS evidence_key;
@@ -0,0 +1,6 @@
+-- The evidence file sent with a dead-lettered signal, kept in the blob store under evidence_key until the letter is re-driven or discarded.
+ALTER TABLE ingest_dead_letters ADD COLUMN IF NOT EXISTS evidence_key TEXT NOT NULL DEFAULT '';
+ALTER TABLE ingest_dead_letters ADD COLUMN IF NOT EXISTS evidence_name TEXT NOT NULL DEFAULT '';
+ALTER TABLE ingest_dead_letters ADD COLUMN IF NOT EXISTS evidence_content_type TEXT NOT NULL DEFAULT '';
+ALTER TABLE ingest_dead_letters ADD COLUMN IF NOT EXISTS evidence_size BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE ingest_dead_letters ADD COLUMN IF NOT EXISTS evidence_sha256 TEXT NOT NULL DEFAULT '';